@@ -3,14 +3,16 @@ package main
 import (
 	"context"
 	"log/slog"
-	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"time"
+	"syscall"
 
 	"github.com/lmittmann/tint"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/pkg/builder"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/cache"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/cli"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/diagnostics"
 	httpClient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
 )
 
@@ -43,35 +45,76 @@ func main() {
 		os.Exit(1)
 	}
 
-	cacheConfig := cache.CacheConfig{
-		Directory:       cacheDir,
-		DefaultTTLHours: 48,
-		SearchTTLHours:  2,
+	clientConfig := builder.ClientConfig{
+		Cache: cache.CacheConfig{
+			Directory:       cacheDir,
+			Backend:         flags.CacheBackend,
+			DefaultTTLHours: flags.CacheTTLHours,
+			SearchTTLHours:  flags.SearchCacheTTLHours,
+			TTLRules:        flags.CacheTTLRules,
+			Offline:         flags.Offline,
+			RefreshPatterns: flags.RefreshPatterns,
+			DynamicTTL:      true,
+			MemoryEntries:   flags.MemoryCacheEntries,
+			MemoryBytes:     flags.MemoryCacheBytes,
+		},
+		Transport:         flags.Transport,
+		UserAgent:         userAgent(),
+		GitHubToken:       flags.GitHubToken,
+		WagoAPIKey:        flags.WagoAPIKey,
+		HTTPTimeout:       flags.HTTPTimeout,
+		HTTPTimeoutRules:  flags.HTTPTimeoutRules,
+		MaxResponseSize:   flags.MaxResponseSize,
+		MaxBytesPerHost:   flags.MaxBytesPerHost,
+		RecordFixturesDir: flags.RecordFixturesDir,
+		RecordPatterns:    flags.RecordPatterns,
 	}
-
-	// Setup HTTP transport with connection pooling optimized for concurrent scraping
-	transport := &http.Transport{
-		MaxIdleConnsPerHost: 10, // Allow multiple workers to reuse connections to same host
-		MaxIdleConns:        100,
-		IdleConnTimeout:     90 * time.Second,
+	if flags.LockCache {
+		unlock, err := cache.Lock(cacheDir)
+		if err != nil {
+			slog.Error("failed to lock cache", "error", err)
+			os.Exit(1)
+		}
+		// Also released when the process exits. Deferred so the lock file isn't closed by the garbage collector.
+		defer unlock()
+	}
+	if flags.Offline {
+		slog.Info("offline, serving requests from the cache only", "cache-dir", cacheDir)
 	}
 
-	// Setup HTTP client with caching
-	cachingTransport := cache.NewFileCachingTransport(cacheConfig, transport)
-	userAgent := userAgent()
-	client := httpClient.NewRealHTTPClient(cachingTransport, userAgent)
+	// Setup HTTP client with caching, circuit breakers, download budgets and authentication
+	client, err := builder.NewClient(clientConfig)
+	if err != nil {
+		slog.Error("failed to setup HTTP client", "error", err)
+		os.Exit(1)
+	}
+	cachingTransport := client.Cache
 
 	// Create command handler
 	handler := cli.NewCommandHandler()
 	ctx := context.Background()
 
+	if flags.PprofAddr != "" {
+		if _, err := diagnostics.Start(ctx, flags.PprofAddr, diagnostics.LogInterval); err != nil {
+			slog.Error("failed to start pprof", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	// Execute command
 	switch flags.SubCommand {
 	case cli.ScrapeSubCommand:
 		config := flags.ScrapeConfig
-		config.HTTPClient = client
+		client.Configure(&config.Options)
 
-		if err := handler.Scrape(ctx, config); err != nil {
+		err := handler.Scrape(ctx, config)
+		if indexErr := cachingTransport.SaveIndex(); indexErr != nil {
+			slog.Warn("failed to save cache index", "error", indexErr)
+		}
+		if closeErr := cachingTransport.Close(); closeErr != nil {
+			slog.Warn("failed to close cache", "error", closeErr)
+		}
+		if err != nil {
 			slog.Error("scrape command failed", "error", err)
 			os.Exit(1)
 		}
@@ -82,12 +125,107 @@ func main() {
 			os.Exit(1)
 		}
 
+	case cli.ServeSubCommand:
+		serveCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if err := handler.Serve(serveCtx, flags.ServeConfig); err != nil {
+			slog.Error("serve command failed", "error", err)
+			os.Exit(1)
+		}
+
 	case cli.ValidateSubCommand:
-		if err := handler.Validate(ctx, flags.ValidateFile); err != nil {
+		if err := handler.Validate(ctx, flags.ValidateConfig); err != nil {
 			slog.Error("validate command failed", "error", err)
 			os.Exit(1)
 		}
 
+	case cli.SchemaSubCommand:
+		if err := handler.Schema(ctx, flags.SchemaFile); err != nil {
+			slog.Error("schema command failed", "error", err)
+			os.Exit(1)
+		}
+
+	case cli.CacheSubCommand:
+		config := flags.CacheConfig
+		config.Dir = cacheDir
+		config.Store = cachingTransport.Store()
+
+		if err := handler.Cache(ctx, config); err != nil {
+			slog.Error("cache command failed", "error", err)
+			os.Exit(1)
+		}
+
+	case cli.TrendSubCommand:
+		if err := handler.Trend(ctx, flags.TrendConfig); err != nil {
+			slog.Error("trend command failed", "error", err)
+			os.Exit(1)
+		}
+
+	case cli.MergeSubCommand:
+		if err := handler.Merge(ctx, flags.MergeConfig); err != nil {
+			slog.Error("merge command failed", "error", err)
+			os.Exit(1)
+		}
+
+	case cli.SearchSubCommand:
+		if err := handler.Search(ctx, flags.SearchConfig); err != nil {
+			slog.Error("search command failed", "error", err)
+			os.Exit(1)
+		}
+
+	case cli.CheckLinksSubCommand:
+		// Links are checked live, bypassing the cache and the scrape's circuit breakers and download budgets
+		config := flags.CheckLinksConfig
+		headClient := httpClient.NewHeadClient(client.Network, userAgent(), httpClient.Logging())
+		headClient.SetTimeouts(flags.HTTPTimeout, flags.HTTPTimeoutRules)
+		config.HTTPClient = headClient
+		if err := handler.CheckLinks(ctx, config); err != nil {
+			slog.Error("check-links command failed", "error", err)
+			os.Exit(1)
+		}
+
+	case cli.LintSubCommand:
+		if err := handler.Lint(ctx, flags.LintConfig); err != nil {
+			slog.Error("lint command failed", "error", err)
+			os.Exit(1)
+		}
+
+	case cli.ShowSubCommand:
+		if err := handler.Show(ctx, flags.ShowConfig); err != nil {
+			slog.Error("show command failed", "error", err)
+			os.Exit(1)
+		}
+
+	case cli.PublishSubCommand:
+		if err := handler.Publish(ctx, flags.PublishConfig); err != nil {
+			slog.Error("publish command failed", "error", err)
+			os.Exit(1)
+		}
+
+	case cli.DaemonSubCommand:
+		daemonCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		config := flags.DaemonConfig
+		client.Configure(&config.Scrape.Options)
+		// Entries cached by the last scrape age, and hosts get their budgets back, from the start of each
+		config.BeforeScrape = client.StartRun
+		config.AfterScrape = func() {
+			if err := cachingTransport.SaveIndex(); err != nil {
+				slog.Warn("failed to save cache index", "error", err)
+			}
+		}
+
+		err := handler.Daemon(daemonCtx, config)
+		if closeErr := cachingTransport.Close(); closeErr != nil {
+			slog.Warn("failed to close cache", "error", closeErr)
+		}
+		if err != nil {
+			slog.Error("daemon command failed", "error", err)
+			os.Exit(1)
+		}
+
 	default:
 		slog.Error("unknown subcommand", "subcommand", flags.SubCommand)
 		os.Exit(1)
@@ -20,7 +20,7 @@ if test ! "$cmd"; then
     echo "  test.integration    run integration tests against live WoWInterface data"
     echo "  test.verbose        run tests with verbose output"
     echo "  update              scrape data and generate catalogues"
-    echo "  validate <path>     validate a catalogue JSON file"
+    echo "  validate <path>...  validate catalogue JSON files"
     exit 1
 fi
 
@@ -203,19 +203,17 @@ elif test "$cmd" = "update"; then
 elif test "$cmd" = "validate"; then
     if test -z "$rest"; then
         echo "Error: validate command requires a catalogue file path"
-        echo "Usage: ./manage.sh validate <path/to/catalogue.json>"
+        echo "Usage: ./manage.sh validate <path/to/catalogue.json> [more paths or glob patterns]"
         exit 1
     fi
 
-    catalogue_path="$rest"
-
     # Build if needed
     if test ! -f "$app"; then
         ./manage.sh build
     fi
 
     # Run validation
-    ./"$app" validate "$catalogue_path"
+    ./"$app" validate "$@"
     exit $?
 
 # ...
@@ -0,0 +1,70 @@
+// Package builder builds strongbox catalogues from within another Go program, scraping addon sources and writing
+// the catalogues as the scrape command does, without shelling out to the CLI.
+//
+//	opts := builder.DefaultOptions()
+//	opts.CacheDir = "cache"
+//	catalogue, report, err := builder.Scrape(ctx, opts)
+//
+// Scrape writes the same files to the state directory as the scrape command, so the write, publish and serve
+// commands work from them as usual.
+package builder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Scrape scrapes the sources, writes the catalogues and run state to the state directory and returns the full
+// catalogue, with every addon scraped, and the scrape's report. Catalogues limited by Options.Limit or
+// Options.Categories are marked partial and written to the state directory's partial subdirectory, leaving the
+// last full scrape's catalogues for the next scrape to compare and merge with.
+// A scrape that fails once its catalogues are written, such as one with more failures than Options.MaxFailures
+// allows, returns them and its report along with the error.
+func Scrape(ctx context.Context, opts Options) (Catalogue, Report, error) {
+	if err := opts.validate(); err != nil {
+		return Catalogue{}, Report{}, err
+	}
+	closeClient, err := opts.configureClient()
+	if err != nil {
+		return Catalogue{}, Report{}, err
+	}
+	defer closeClient()
+
+	var cat Catalogue
+	rep := Report{StartedAt: time.Now().UTC(), Sources: opts.Sources}
+	err = newScraper().scrape(ctx, opts, &cat, &rep)
+	return cat, rep, err
+}
+
+// configureClient has opts make requests with a client caching responses in opts.CacheDir, unless it has an
+// HTTPClient, returning a func closing the client once the scrape is done
+func (o *Options) configureClient() (func(), error) {
+	if o.HTTPClient != nil {
+		return func() {}, nil
+	}
+	if o.CacheDir == "" {
+		return nil, fmt.Errorf("a cache directory is required without an HTTP client")
+	}
+	if err := os.MkdirAll(o.CacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	clientConfig := DefaultClientConfig(o.CacheDir)
+	clientConfig.Cache.Offline = o.Offline
+	clientConfig.UserAgent = o.UserAgent
+	clientConfig.GitHubToken = o.GitHubToken
+	clientConfig.WagoAPIKey = o.WagoAPIKey
+	client, err := NewClient(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	client.Configure(o)
+	return func() {
+		if err := client.Close(); err != nil {
+			slog.Warn("failed to close cache", "error", err)
+		}
+	}, nil
+}
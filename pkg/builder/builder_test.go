@@ -0,0 +1,84 @@
+package builder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/gate"
+	httpClient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/wowi"
+)
+
+func TestScrape(t *testing.T) {
+	client := httpClient.NewMockHTTPClient()
+	filelist := `[
+		{"id": 25078, "title": "Better Vendor Price", "categoryId": 21, "lastUpdate": 1717200000000, "gameVersions": ["11.0.2"]},
+		{"id": 1, "title": "One", "categoryId": 20, "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]}
+	]`
+	client.SetResponse(wowi.GetAPIFileList(wowi.APIVersionV4), &httpClient.Response{StatusCode: 200, Body: []byte(filelist)})
+	detailPage, err := os.ReadFile("../../src/wowi/test/fixtures/addon-25078.html")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	client.SetResponse(wowi.Host+"/downloads/info25078", &httpClient.Response{StatusCode: 200, Body: detailPage})
+	apiDetail := `[{"id": 25078, "title": "Better Vendor Price", "lastUpdate": 1717200000000}]`
+	client.SetResponse(wowi.GetAPIHost(wowi.APIVersionV4)+"/filedetails/25078.json", &httpClient.Response{StatusCode: 200, Body: []byte(apiDetail)})
+
+	stateDir := t.TempDir()
+	cat, report, err := Scrape(context.Background(), Options{
+		StateDir:   stateDir,
+		MaxWorkers: 1,
+		Categories: []string{"unit mods"},
+		HTTPClient: client,
+	})
+	if err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+	if cat.Total != 1 || cat.AddonSummaryList[0].Label != "Better Vendor Price" {
+		t.Errorf("Scrape() catalogue = %+v, want just Better Vendor Price", cat.AddonSummaryList)
+	}
+	if report.URLsFetched != 3 || report.AddonsPerSource[WowInterface] != 1 || report.GatePassed == nil {
+		t.Errorf("Scrape() report = %+v, want 3 URLs fetched for 1 WowInterface addon and a gate verdict", report)
+	}
+
+	// Written to the state directory like the scrape command, marked partial
+	metadata, err := gate.ReadRunMetadata(filepath.Join(stateDir, "partial", "run-metadata.json"))
+	if err != nil {
+		t.Fatalf("failed to read run metadata: %v", err)
+	}
+	if metadata.Partial == nil || metadata.Partial.String() != `--category "Unit Mods"` {
+		t.Errorf("run metadata partial = %+v, want --category \"Unit Mods\"", metadata.Partial)
+	}
+}
+
+func TestScrape_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{
+		{"no state directory", Options{CacheDir: t.TempDir()}, "state directory is required"},
+		{"no cache directory", Options{StateDir: t.TempDir()}, "cache directory is required"},
+		{"negative limit", Options{StateDir: t.TempDir(), CacheDir: t.TempDir(), Limit: -1}, "must not be negative"},
+		{"unknown category", Options{StateDir: t.TempDir(), CacheDir: t.TempDir(), Categories: []string{"Unit Mod"}}, "unknown WowInterface category"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Scrape(context.Background(), tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Scrape() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScrape_Offline(t *testing.T) {
+	// Nothing is cached, so the file list can't be fetched and the scrape fails without touching the network
+	_, _, err := Scrape(context.Background(), Options{StateDir: t.TempDir(), CacheDir: t.TempDir(), Offline: true})
+	if err == nil {
+		t.Fatal("Scrape() offline with an empty cache expected an error")
+	}
+}
@@ -0,0 +1,125 @@
+package builder
+
+import (
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// Catalogue is a catalogue of addons, as strongbox reads it
+type Catalogue struct {
+	Spec struct {
+		Version int `json:"version"`
+	} `json:"spec"`
+	Datestamp        string  `json:"datestamp"`
+	Total            int     `json:"total"`
+	AddonSummaryList []Addon `json:"addon-summary-list"`
+}
+
+// Addon is an addon in a catalogue. Fields only kept by some options are empty without them, see Options.
+type Addon struct {
+	Archived             bool         `json:"archived,omitempty"`
+	Author               string       `json:"author,omitempty"`    // spec version 3 only
+	Changelog            string       `json:"changelog,omitempty"` // latest changelog, only kept with IncludeChangelogs
+	CreatedDate          *time.Time   `json:"created-date,omitempty"`
+	DependencyList       []Dependency `json:"dependency-list,omitempty"` // only kept with WithDependencies
+	Description          string       `json:"description,omitempty"`
+	DownloadCount        *int         `json:"download-count,omitempty"`
+	FavoriteCount        *int         `json:"favorite-count,omitempty"` // only kept with ExtendedFields
+	FolderList           []string     `json:"folder-list,omitempty"`    // addon folders the download unpacks to, spec version 3 only
+	GameTrackList        []string     `json:"game-track-list"`          // e.g. "retail", "classic"
+	ImageURL             string       `json:"image-url,omitempty"`      // first screenshot, only kept with IncludeImages
+	Label                string       `json:"label"`
+	MonthlyDownloadCount *int         `json:"monthly-download-count,omitempty"` // only kept with ExtendedFields
+	Name                 string       `json:"name"`
+	Popularity           *float64     `json:"popularity,omitempty"`   // percentile of download-count within the source, 0 to 1, only kept with Popularity
+	ReleaseList          []Release    `json:"release-list,omitempty"` // latest release per game track, spec version 3 only
+	SameAs               []AddonRef   `json:"same-as,omitempty"`      // the same addon published to other sources
+	Source               Source       `json:"source"`
+	SourceID             string       `json:"source-id"`
+	TagList              []string     `json:"tag-list,omitempty"`
+	UpdatedDate          time.Time    `json:"updated-date"`
+	URL                  string       `json:"url"`
+}
+
+// AddonRef identifies an addon within a source
+type AddonRef struct {
+	Source   Source `json:"source"`
+	SourceID string `json:"source-id"`
+}
+
+// Release is a downloadable release of an addon
+type Release struct {
+	Checksum      string `json:"checksum,omitempty"` // MD5 hex digest of the download, when the source reports it
+	DownloadURL   string `json:"download-url"`
+	GameTrack     string `json:"game-track,omitempty"`
+	InterfaceList []int  `json:"interface-list,omitempty"` // interface versions supported, e.g. 110005 for 11.0.5
+	Size          int64  `json:"size,omitempty"`           // bytes, when the source reports it
+	Version       string `json:"version,omitempty"`
+}
+
+// Dependency is another file an addon needs, or an optional file it can use
+type Dependency struct {
+	Label    string `json:"label"`
+	Required bool   `json:"required,omitempty"`  // false for optional files
+	SourceID string `json:"source-id,omitempty"` // when the file is another addon in the same source
+	URL      string `json:"url"`
+}
+
+// newCatalogue returns the catalogue the scrape built
+func newCatalogue(cat types.Catalogue) Catalogue {
+	var c Catalogue
+	c.Spec.Version = cat.Spec.Version
+	c.Datestamp = cat.Datestamp
+	c.Total = cat.Total
+	c.AddonSummaryList = make([]Addon, len(cat.AddonSummaryList))
+	for i, addon := range cat.AddonSummaryList {
+		c.AddonSummaryList[i] = newAddon(addon)
+	}
+	return c
+}
+
+// newAddon returns a catalogue's addon
+func newAddon(addon types.Addon) Addon {
+	a := Addon{
+		Archived:             addon.Archived,
+		Author:               addon.Author,
+		Changelog:            addon.Changelog,
+		CreatedDate:          addon.CreatedDate,
+		Description:          addon.Description,
+		DownloadCount:        addon.DownloadCount,
+		FavoriteCount:        addon.FavoriteCount,
+		FolderList:           addon.FolderList,
+		ImageURL:             addon.ImageURL,
+		Label:                addon.Label,
+		MonthlyDownloadCount: addon.MonthlyDownloadCount,
+		Name:                 addon.Name,
+		Popularity:           addon.Popularity,
+		Source:               Source(addon.Source),
+		SourceID:             addon.SourceID,
+		TagList:              addon.TagList,
+		UpdatedDate:          addon.UpdatedDate,
+		URL:                  addon.URL,
+	}
+	for _, dependency := range addon.DependencyList {
+		a.DependencyList = append(a.DependencyList, Dependency(dependency))
+	}
+	a.GameTrackList = make([]string, len(addon.GameTrackList))
+	for i, track := range addon.GameTrackList {
+		a.GameTrackList[i] = string(track)
+	}
+	for _, release := range addon.ReleaseList {
+		a.ReleaseList = append(a.ReleaseList, Release{
+			Checksum:      release.Checksum,
+			DownloadURL:   release.DownloadURL,
+			GameTrack:     string(release.GameTrack),
+			InterfaceList: release.InterfaceList,
+			Size:          release.Size,
+			Version:       release.Version,
+		})
+	}
+	for _, ref := range addon.SameAs {
+		a.SameAs = append(a.SameAs, AddonRef{Source: Source(ref.Source), SourceID: ref.SourceID})
+	}
+	return a
+}
@@ -0,0 +1,153 @@
+package builder
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/cache"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/circuit"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/github"
+	httpClient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/wago"
+)
+
+// DefaultUserAgent identifies requests made by a client without a UserAgent
+const DefaultUserAgent = "strongbox-catalogue-builder (https://github.com/ogri-la/strongbox-catalogue-builder-go)"
+
+// ClientConfig configures the HTTP client a scrape makes its requests with
+type ClientConfig struct {
+	Cache            cache.CacheConfig          // where and for how long responses are cached
+	Transport        httpClient.TransportConfig // proxy, name resolution and connection pooling of requests
+	UserAgent        string                     // DefaultUserAgent if empty
+	GitHubToken      string                     // authenticates GitHub API requests, optional
+	WagoAPIKey       string                     // authenticates Wago Addons API requests, optional
+	HTTPTimeout      time.Duration              // how long a request may take
+	HTTPTimeoutRules []httpClient.TimeoutRule   // how long requests to URLs matching a pattern may take, overriding the above
+	MaxResponseSize  int64                      // responses with larger bodies fail
+	MaxBytesPerHost  int64                      // requests to a host are refused once it has sent this many bytes in a scrape, 0 for no limit
+
+	RecordFixturesDir string   // save responses to URLs matching RecordPatterns here as test fixtures, optional
+	RecordPatterns    []string // URLs whose responses are recorded
+}
+
+// DefaultClientConfig returns the configuration of a client caching responses in cacheDir, as the scrape command
+// does without options
+func DefaultClientConfig(cacheDir string) ClientConfig {
+	return ClientConfig{
+		Cache: cache.CacheConfig{
+			Directory:       cacheDir,
+			DefaultTTLHours: 48,
+			SearchTTLHours:  2,
+			TTLRules:        cache.DefaultTTLRules,
+			DynamicTTL:      true,
+			MemoryEntries:   cache.DefaultMemoryEntries,
+			MemoryBytes:     cache.DefaultMemoryBytes,
+		},
+		Transport:        httpClient.DefaultTransportConfig(),
+		HTTPTimeout:      httpClient.DefaultTimeout,
+		HTTPTimeoutRules: httpClient.DefaultTimeoutRules,
+		MaxResponseSize:  httpClient.DefaultMaxResponseSize,
+	}
+}
+
+// Client is the HTTP client a scrape makes its requests with, along with the transports beneath it that the scrape
+// reports on
+type Client struct {
+	HTTP      *httpClient.RealHTTPClient     // makes the scrape's requests
+	Cache     *cache.FileCachingTransport    // serves cached responses and caches the rest
+	Breaker   *circuit.Transport             // stops requesting from hosts that keep failing
+	Bandwidth *httpClient.BandwidthTransport // counts what each host sends
+	Network   *http.Transport                // beneath the cache, circuit breakers and budgets, for requests that bypass them
+}
+
+// NewClient returns a client that caches responses, stops requesting from hosts that keep failing and authenticates
+// API requests, as configured
+func NewClient(config ClientConfig) (*Client, error) {
+	transport := httpClient.NewTransport(config.Transport)
+	if config.Transport.Proxy != nil {
+		slog.Info("making requests through a proxy", "proxy", config.Transport.Proxy.Redacted())
+	}
+	for host, addr := range config.Transport.Resolve {
+		slog.Info("overriding the address of a host", "host", host, "address", addr)
+	}
+	if config.Transport.DNSServer != "" {
+		slog.Info("resolving host names with a DNS server", "dns-server", config.Transport.DNSServer)
+	}
+
+	// Stop requesting from a host that keeps failing. Sits below the cache so cache hits are still served.
+	breakerTransport := circuit.NewTransport(circuit.DefaultConfig(), transport)
+
+	// Count what each host sends, refusing requests to those that have sent their budget. Below the cache, so only
+	// what comes over the network counts, and above the breaker, so refusals don't open it.
+	bandwidthTransport := httpClient.NewBandwidthTransport(config.MaxBytesPerHost, breakerTransport)
+	if config.MaxBytesPerHost > 0 {
+		slog.Info("limiting the bytes downloaded from each host", "max-bytes-per-host", config.MaxBytesPerHost)
+	}
+
+	// Authenticate API requests. Below the cache, tokens don't change what's cached.
+	authTransport := httpClient.NewTokenTransport(map[string]string{
+		github.APIHost: config.GitHubToken,
+		wago.APIHost:   config.WagoAPIKey,
+	}, bandwidthTransport)
+	if config.GitHubToken != "" {
+		slog.Info("authenticating GitHub API requests")
+	}
+
+	cachingTransport, err := cache.NewCachingTransport(config.Cache, authTransport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache: %w", err)
+	}
+	var clientTransport http.RoundTripper = cachingTransport
+
+	// Record fixtures above the cache, so they can be made from cached responses too
+	if config.RecordFixturesDir != "" {
+		clientTransport, err = httpClient.NewRecordingTransport(config.RecordFixturesDir, config.RecordPatterns, cachingTransport)
+		if err != nil {
+			cachingTransport.Close()
+			return nil, fmt.Errorf("failed to record fixtures: %w", err)
+		}
+		slog.Info("recording fixtures", "dir", config.RecordFixturesDir, "patterns", config.RecordPatterns)
+	}
+
+	userAgent := config.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	client := httpClient.NewRealHTTPClient(clientTransport, userAgent, httpClient.Logging())
+	client.SetTimeouts(config.HTTPTimeout, config.HTTPTimeoutRules)
+	client.SetMaxResponseSize(config.MaxResponseSize)
+
+	return &Client{HTTP: client, Cache: cachingTransport, Breaker: breakerTransport, Bandwidth: bandwidthTransport, Network: transport}, nil
+}
+
+// Configure has a scrape make its requests with the client and report on its cache and hosts
+func (c *Client) Configure(config *Options) {
+	config.HTTPClient = c.HTTP
+	config.UpdateHints = c.Cache
+	config.CacheStats = c.Cache
+	config.CacheChecker = c.Cache
+	config.TraceStats = c.HTTP
+	config.Bandwidth = c.Bandwidth
+}
+
+// StartRun has the client start afresh for another scrape, as the daemon does before each: cached responses age
+// from now, and every host's breaker is closed and its download budget whole again
+func (c *Client) StartRun() {
+	c.Cache.StartRun()
+	c.Breaker.Reset()
+	c.Bandwidth.Reset()
+}
+
+// Close saves the index of the cache and closes it, closing it even if the index can't be saved
+func (c *Client) Close() error {
+	indexErr := c.Cache.SaveIndex()
+	if err := c.Cache.Close(); err != nil {
+		return fmt.Errorf("failed to close cache: %w", err)
+	}
+	if indexErr != nil {
+		return fmt.Errorf("failed to save cache index: %w", indexErr)
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package builder
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/catalogue"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/deadletter"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/gate"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/github"
+	httpClient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/state"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/wowi"
+)
+
+// Source is a site addons are scraped from
+type Source string
+
+const (
+	WowInterface Source = "wowinterface"
+	GitHub       Source = "github"
+	GitLab       Source = "gitlab"
+	Codeberg     Source = "codeberg"
+	Wago         Source = "wago"
+	TownlongYak  Source = "townlong-yak"
+)
+
+// Sources are every source that can be scraped
+var Sources = []Source{WowInterface, GitHub, GitLab, Codeberg, Wago, TownlongYak}
+
+// Profile is how much of each WowInterface addon a scrape fetches
+type Profile string
+
+const (
+	FullProfile    Profile = "full"     // the API's details and the addon's page
+	APIOnlyProfile Profile = "api-only" // the API's details alone, without the tags, created date and the rest only on the page
+)
+
+// KnownProfiles are every profile a scrape can fetch with
+var KnownProfiles = []Profile{FullProfile, APIOnlyProfile}
+
+// UpdateHinter receives the last known update time of the content behind a URL.
+// Implemented by the caching transport to compute dynamic TTLs.
+type UpdateHinter interface {
+	SetUpdatedDate(url string, updated time.Time)
+}
+
+// CacheStatter reports how many requests were served from the cache and how many were fetched.
+// Implemented by the caching transport for the scrape report.
+type CacheStatter interface {
+	CacheStats() (hits, misses int64)
+}
+
+// CacheChecker reports whether a URL would be served from the cache rather than fetched.
+// Implemented by the caching transport for PlanScrape.
+type CacheChecker interface {
+	Fresh(url string) bool
+}
+
+// TraceStatter reports where the requests made over the network spent their time, by host.
+// Implemented by the HTTP client for the scrape report.
+type TraceStatter interface {
+	TraceStats() map[string]httpClient.HostTrace
+}
+
+// BandwidthMeter reports the bytes each host has sent.
+// Implemented by the bandwidth transport for the scrape report.
+type BandwidthMeter interface {
+	BytesRead() map[string]int64
+	Reset() // start counting again, at the start of each scrape
+}
+
+// Options configures a scrape. Start from DefaultOptions: the sources, workers, API version and profile take their
+// defaults when empty, but the zero value of the other fields isn't always theirs, MaxFailures for one.
+type Options struct {
+	// Requests are made with HTTPClient, reporting on it with the optional hooks below. Without one, a client
+	// caching responses in CacheDir is made for the scrape, see Client.
+	HTTPClient   httpClient.HTTPClient
+	UpdateHints  UpdateHinter   // optional
+	CacheStats   CacheStatter   // optional
+	CacheChecker CacheChecker   // optional, PlanScrape counts every URL as fetched without it
+	TraceStats   TraceStatter   // optional
+	Bandwidth    BandwidthMeter // optional
+	CacheDir     string         // directory fetched pages are cached in, required without an HTTPClient
+	Offline      bool           // serve every request from the cache however old, never touching the network
+	UserAgent    string         // DefaultUserAgent if empty
+	GitHubToken  string         // authenticates GitHub API requests for a larger rate limit, optional
+	WagoAPIKey   string         // Wago Addons API key, required to scrape the wago source
+
+	Sources            []Source       // WowInterface if empty
+	MaxWorkers         int            // requests made to each source at once, 5 if 0
+	AutoWorkers        bool           // adapt the number of WowInterface workers to how it copes, up to MaxWorkers
+	SourceWorkers      map[Source]int // workers per source, overriding MaxWorkers
+	WoWIAPIVersion     string         // WowInterface API version, "v3" or "v4", "v4" if empty
+	IncludeArchived    bool           // also crawl WowInterface's archived/legacy sections
+	GitHubReadmes      bool           // fill empty GitHub descriptions from the repository README
+	GitHubTopics       bool           // tag GitHub addons with their repository's topics
+	Summaries          bool           // describe addons with a few sentences rather than their first line
+	StateDir           string         // directory the catalogues and run state are written to, required
+	Blocklist          string         // addons to leave out of the catalogues, optional
+	Overrides          string         // patches to scraped addons, optional
+	SpecVersion        int            // catalogue spec version written, 0 for the default
+	Datestamp          string         // datestamp of the catalogues written, today if empty
+	NoIndent           bool           // write catalogues as compact JSON
+	Timeout            time.Duration  // abandon the scrape after this long, 0 for no limit
+	MaxFailures        int            // URLs that may fail to fetch or parse before the scrape fails, -1 for no limit
+	OnlyIDs            []string       // re-scrape just these WowInterface addons, merged into the last scrape's catalogue
+	OnlyIDsFile        string         // a failed-urls.json listing more WowInterface addons to re-scrape, optional
+	Incremental        bool           // only fetch the details of WowInterface addons updated since the last scrape
+	MinRefreshAge      time.Duration  // don't fetch the details of WowInterface addons not updated since they were fetched within this long, 0 to always fetch them
+	DeadLetterCooldown time.Duration  // how long WowInterface addon pages missing in several scrapes are skipped for, 0 to never skip them
+	Limit              int            // only scrape the first this many addons of the WowInterface file list, 0 for all
+	Categories         []string       // only scrape WowInterface addons in these categories, by name, all if empty
+
+	ShortCutoff       string            // addons updated after this are kept in the short catalogue, a date (YYYY-MM-DD) or a period such as "2 years", the release of Dragonflight if empty
+	ShortMinDownloads int               // also keep addons downloaded more than this in the short catalogue however old, 0 to go by date only
+	MergeStrategies   map[string]string // how fields are merged when the files describing an addon disagree, by field, overriding the defaults
+
+	CollapseDuplicates   bool          // keep only one of each addon found in more than one source in the short catalogue
+	IncludeChangelogs    bool          // keep changelogs in the full catalogue and write them to changelogs.json
+	Releases             bool          // write each addon's latest release per game track to releases.json
+	IncludeImages        bool          // include each addon's first screenshot in the catalogues
+	ExtendedFields       bool          // include each addon's favorite and monthly download counts in the catalogues
+	Popularity           bool          // include each addon's download count percentile within its source in the catalogues
+	WithDependencies     bool          // include each addon's dependencies and optional files in the catalogues
+	Authors              bool          // fetch WowInterface author pages and write each author's addons to authors.json
+	UnknownGameTracks    bool          // leave WowInterface addons without a detected game track unclassified instead of retail
+	Manifest             bool          // write the sha256 of each catalogue to catalogue.sha256
+	SignKey              string        // minisign secret key to sign each catalogue (and the manifest) with, optional
+	Progress             bool          // draw a progress bar when stderr is a terminal instead of logging progress
+	GitHubReadmeInterval time.Duration // minimum delay between README requests
+	GitHubTopicsInterval time.Duration // minimum delay between topics requests
+	Profile              Profile       // how much of each WowInterface addon is fetched, FullProfile if empty
+}
+
+// defaultMaxWorkers is the number of requests made to each source at once without Options.MaxWorkers
+const defaultMaxWorkers = 5
+
+// DefaultOptions returns the options of the scrape command without flags, less its HTTP client and cache directory
+func DefaultOptions() Options {
+	return Options{
+		Sources:              []Source{WowInterface},
+		MaxWorkers:           defaultMaxWorkers,
+		WoWIAPIVersion:       string(wowi.APIVersionV4),
+		StateDir:             state.DefaultDir,
+		Blocklist:            "blocklist.json",
+		Overrides:            "overrides.json",
+		SpecVersion:          types.DefaultSpecVersion,
+		MaxFailures:          -1,
+		DeadLetterCooldown:   deadletter.DefaultCooldown,
+		ShortCutoff:          catalogue.DefaultShortCutoff.String(),
+		GitHubReadmeInterval: github.DefaultReadmeInterval,
+		GitHubTopicsInterval: github.DefaultTopicsInterval,
+		Profile:              FullProfile,
+	}
+}
+
+// SourceWorkerBudget returns the number of workers a source is scraped with
+func (o Options) SourceWorkerBudget(source Source) int {
+	if workers, ok := o.SourceWorkers[source]; ok {
+		return workers
+	}
+	return o.MaxWorkers
+}
+
+// validate checks the options make sense before anything is fetched, filling in the defaults of empty fields and
+// naming the categories as WowInterface does
+func (o *Options) validate() error {
+	if o.StateDir == "" {
+		return fmt.Errorf("a state directory is required")
+	}
+	if len(o.Sources) == 0 {
+		o.Sources = []Source{WowInterface}
+	}
+	for _, source := range o.Sources {
+		if !slices.Contains(Sources, source) {
+			return fmt.Errorf("unknown source: %s", source)
+		}
+	}
+	if o.MaxWorkers == 0 {
+		o.MaxWorkers = defaultMaxWorkers
+	}
+	if o.MaxWorkers < 0 {
+		return fmt.Errorf("workers must not be negative: %d", o.MaxWorkers)
+	}
+	if o.WoWIAPIVersion == "" {
+		o.WoWIAPIVersion = string(wowi.APIVersionV4)
+	}
+	if o.apiVersion() != wowi.APIVersionV3 && o.apiVersion() != wowi.APIVersionV4 {
+		return fmt.Errorf("unknown WowInterface API version: %s", o.WoWIAPIVersion)
+	}
+	if o.Profile != "" && !slices.Contains(KnownProfiles, o.Profile) {
+		return fmt.Errorf("unknown profile: %s", o.Profile)
+	}
+	if o.Limit < 0 {
+		return fmt.Errorf("limit must not be negative: %d", o.Limit)
+	}
+	if _, err := o.shortPolicy(); err != nil {
+		return err
+	}
+
+	categories := make([]string, 0, len(o.Categories))
+	for _, name := range o.Categories {
+		id, ok := wowi.CategoryID(name)
+		if !ok {
+			return fmt.Errorf("unknown WowInterface category: %s", name)
+		}
+		name, _ = wowi.CategoryName(id)
+		categories = append(categories, name)
+	}
+	if len(categories) > 0 {
+		o.Categories = categories
+	}
+	return nil
+}
+
+// sources returns the sources to scrape
+func (o Options) sources() []types.Source {
+	sources := make([]types.Source, len(o.Sources))
+	for i, source := range o.Sources {
+		sources[i] = types.Source(source)
+	}
+	return sources
+}
+
+// apiVersion returns the WowInterface API version to scrape
+func (o Options) apiVersion() wowi.APIVersion {
+	return wowi.APIVersion(o.WoWIAPIVersion)
+}
+
+// shortPolicy returns which addons are maintained enough for the short catalogue
+func (o Options) shortPolicy() (catalogue.ShortPolicy, error) {
+	policy := catalogue.ShortPolicy{Cutoff: catalogue.DefaultShortCutoff, MinDownloads: o.ShortMinDownloads}
+	if o.ShortCutoff != "" {
+		cutoff, err := catalogue.ParseCutoff(o.ShortCutoff)
+		if err != nil {
+			return policy, err
+		}
+		policy.Cutoff = cutoff
+	}
+	if policy.MinDownloads < 0 {
+		return policy, fmt.Errorf("short catalogue minimum downloads must not be negative: %d", policy.MinDownloads)
+	}
+	return policy, nil
+}
+
+// scope returns the part of the WowInterface file list the scrape is limited to, nil if it isn't
+func (o Options) scope() *gate.Scope {
+	if o.Limit == 0 && len(o.Categories) == 0 {
+		return nil
+	}
+	return &gate.Scope{Limit: o.Limit, Categories: o.Categories}
+}
@@ -0,0 +1,140 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/refresh"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/report"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/state"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/wowi"
+)
+
+// assumedFetchTime is how long fetching a URL is assumed to take a worker, without a last scrape to go by
+const assumedFetchTime = time.Second
+
+// Plan is what a WowInterface scrape would fetch, found by PlanScrape
+type Plan struct {
+	Discovered   int           // addon URLs the file list leads to, or those of Options.OnlyIDs
+	DeadLettered int           // skipped because they kept failing in earlier scrapes
+	Cached       int           // fresh in the cache, served without a request
+	ToFetch      int           // fetched from WowInterface
+	PerFetch     time.Duration // how much each URL fetched is expected to add to the scrape
+	FromLastRun  bool          // PerFetch is what each URL fetched added to the last scrape rather than assumed
+}
+
+// Estimate returns how long the scrape is expected to take
+func (p Plan) Estimate() time.Duration {
+	return time.Duration(p.ToFetch) * p.PerFetch
+}
+
+// PlanScrape discovers the WowInterface addons a scrape would fetch and counts how many of their URLs are cached and
+// how long fetching the rest should take, without fetching them or writing anything to the state directory
+func PlanScrape(ctx context.Context, opts Options) (Plan, error) {
+	if err := opts.validate(); err != nil {
+		return Plan{}, err
+	}
+	if !slices.Contains(opts.Sources, WowInterface) {
+		return Plan{}, fmt.Errorf("only WowInterface scrapes can be planned")
+	}
+	for _, source := range opts.Sources {
+		if source != WowInterface {
+			slog.Warn("only the WowInterface scrape is planned, leaving out a source", "source", source)
+		}
+	}
+	if opts.IncludeArchived {
+		slog.Warn("planning doesn't crawl the archived sections, their addons aren't counted")
+	}
+	if opts.OnlyIDsFile != "" {
+		ids, err := readOnlyIDsFile(opts.OnlyIDsFile)
+		if err != nil {
+			return Plan{}, err
+		}
+		opts.OnlyIDs = append(opts.OnlyIDs, ids...)
+	}
+	closeClient, err := opts.configureClient()
+	if err != nil {
+		return Plan{}, err
+	}
+	defer closeClient()
+
+	return planWowInterface(ctx, opts)
+}
+
+// planWowInterface fetches the WowInterface file list, from the cache if it's fresh, and sorts the URLs it leads to
+// into those that would be skipped, served from the cache or fetched
+func planWowInterface(ctx context.Context, config Options) (Plan, error) {
+	var plan Plan
+	parser := newWoWIParser(config)
+	deadLetters, err := readDeadLetters(filepath.Join(config.StateDir, state.DeadLettersFile), config)
+	if err != nil {
+		return plan, err
+	}
+	refreshed, err := refresh.Read(filepath.Join(config.StateDir, state.RefreshedFile))
+	if err != nil {
+		return plan, err
+	}
+	incremental := newIncrementalScrapeFor(config, refreshed)
+	scope := newScrapeScope(config)
+
+	var urls []string
+	if len(config.OnlyIDs) > 0 {
+		for _, sourceID := range config.OnlyIDs {
+			urls = append(urls, parser.AddonURLs(config.apiVersion(), sourceID)...)
+		}
+	} else {
+		for _, url := range wowi.StartingURLs(config.apiVersion()) {
+			resp, err := config.HTTPClient.Get(ctx, url)
+			if err != nil {
+				return plan, fmt.Errorf("failed to download %s: %w", url, err)
+			}
+			if resp.StatusCode != 200 {
+				return plan, fmt.Errorf("non-200 status code %d for %s", resp.StatusCode, url)
+			}
+			result, err := parser.Parse(url, resp.Body)
+			if err != nil {
+				return plan, fmt.Errorf("failed to parse %s: %w", url, err)
+			}
+			if scope != nil {
+				scope.filter(result)
+			}
+			if incremental != nil {
+				incremental.filter(result)
+			}
+			// Update dates shorten the TTL of pages cached before the addon was updated
+			if config.UpdateHints != nil {
+				for updatedURL, updated := range result.UpdatedDates {
+					config.UpdateHints.SetUpdatedDate(updatedURL, updated)
+				}
+			}
+			urls = append(urls, result.DownloadURLs...)
+		}
+	}
+	slices.Sort(urls)
+	urls = slices.Compact(urls)
+
+	now := time.Now()
+	for _, url := range urls {
+		plan.Discovered++
+		switch {
+		case wowi.SourceIDFromURL(url) != "" && deadLetters.Skip(url, now):
+			plan.DeadLettered++
+		case config.CacheChecker != nil && config.CacheChecker.Fresh(url):
+			plan.Cached++
+		default:
+			plan.ToFetch++
+		}
+	}
+
+	plan.PerFetch = assumedFetchTime / time.Duration(max(config.SourceWorkerBudget(WowInterface), 1))
+	lastRun, err := report.Read(filepath.Join(config.StateDir, state.ScrapeReportFile))
+	if err == nil && lastRun.Cache != nil && lastRun.Cache.Misses > 0 && lastRun.DurationSeconds > 0 {
+		plan.PerFetch = time.Duration(lastRun.DurationSeconds / float64(lastRun.Cache.Misses) * float64(time.Second))
+		plan.FromLastRun = true
+	}
+	return plan, nil
+}
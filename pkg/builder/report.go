@@ -0,0 +1,81 @@
+package builder
+
+import (
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/report"
+)
+
+// Report is what a scrape fetched, what failed and what was skipped, and how its catalogue compares to the last
+type Report struct {
+	StartedAt          time.Time
+	FinishedAt         time.Time
+	Duration           time.Duration
+	Sources            []Source
+	URLsFetched        int64
+	DeadLetterSkips    int64          // URLs not fetched because they kept failing in earlier scrapes
+	HTTPErrors         map[string]int // status code or error kind -> count
+	FetchFailures      []Failure
+	ParseFailures      []Failure
+	SkippedAddons      []SkippedAddon
+	AddonsPerSource    map[Source]int
+	UnclassifiedAddons map[Source]int // addons without a game track, see Options.UnknownGameTracks
+	AppliedOverrides   []string       // source/source-id
+	StaleOverrides     []string       // addon gone or the source now agrees with the override
+
+	Added, Updated, Removed int   // addons changed since the last scrape's catalogue, 0 without one
+	GatePassed              *bool // whether the catalogue passed the publish gate, nil if the scrape failed before it was run
+}
+
+// Failure is a URL that couldn't be fetched or parsed
+type Failure struct {
+	URL   string
+	Error string
+}
+
+// SkippedAddon is an addon that was found but left out of the catalogue
+type SkippedAddon struct {
+	Source   Source
+	SourceID string
+	Reason   string // e.g. "blocklisted"
+}
+
+// newReport returns the report of a scrape from the one written to the state directory
+func newReport(r report.ScrapeReport) Report {
+	rep := Report{
+		StartedAt:          r.StartedAt,
+		FinishedAt:         r.FinishedAt,
+		Duration:           r.FinishedAt.Sub(r.StartedAt),
+		URLsFetched:        r.URLsFetched,
+		DeadLetterSkips:    r.DeadLetterSkips,
+		HTTPErrors:         r.HTTPErrors,
+		FetchFailures:      newFailures(r.FetchFailures),
+		ParseFailures:      newFailures(r.ParseFailures),
+		AddonsPerSource:    make(map[Source]int, len(r.AddonsPerSource)),
+		UnclassifiedAddons: make(map[Source]int, len(r.UnclassifiedAddons)),
+		AppliedOverrides:   r.AppliedOverrides,
+		StaleOverrides:     r.StaleOverrides,
+	}
+	for _, source := range r.Sources {
+		rep.Sources = append(rep.Sources, Source(source))
+	}
+	for _, skipped := range r.SkippedAddons {
+		rep.SkippedAddons = append(rep.SkippedAddons, SkippedAddon{Source: Source(skipped.Source), SourceID: skipped.SourceID, Reason: skipped.Reason})
+	}
+	for source, n := range r.AddonsPerSource {
+		rep.AddonsPerSource[Source(source)] = n
+	}
+	for source, n := range r.UnclassifiedAddons {
+		rep.UnclassifiedAddons[Source(source)] = n
+	}
+	return rep
+}
+
+// newFailures returns the URLs of a report that failed
+func newFailures(failures []report.Failure) []Failure {
+	var list []Failure
+	for _, failure := range failures {
+		list = append(list, Failure(failure))
+	}
+	return list
+}
@@ -0,0 +1,100 @@
+package builder
+
+import (
+	"context"
+	"hash/maphash"
+	"sync"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// urlShards is the number of locks processed URLs are spread over
+const urlShards = 64
+
+// urlShard is a part of the processed URLs, by the hash of the URL
+type urlShard struct {
+	mu   sync.Mutex
+	urls map[string]bool
+}
+
+// scrapeResults gathers what the workers of a WowInterface scrape parse.
+// Workers hand their results to a single collector goroutine and check URLs against sharded sets, rather than all
+// taking the same lock on every URL, so adding workers doesn't just add contention.
+type scrapeResults struct {
+	seed      maphash.Seed
+	processed [urlShards]urlShard // every URL taken by a worker
+	results   chan *types.ParseResult
+	done      chan struct{}
+
+	// Owned by the collector goroutine, only read once close has returned
+	addonData  map[string][]types.AddonData // sourceID -> []AddonData
+	authorData []types.AuthorData
+}
+
+// newScrapeResults starts a collector, buffering up to buffer results before workers wait on it
+func newScrapeResults(buffer int) *scrapeResults {
+	r := &scrapeResults{
+		seed:      maphash.MakeSeed(),
+		results:   make(chan *types.ParseResult, buffer),
+		done:      make(chan struct{}),
+		addonData: make(map[string][]types.AddonData),
+	}
+	for i := range r.processed {
+		r.processed[i].urls = make(map[string]bool)
+	}
+	go r.collect()
+	return r
+}
+
+func (r *scrapeResults) collect() {
+	defer close(r.done)
+	for result := range r.results {
+		for _, addonData := range result.AddonData {
+			if addonData.SourceID != "" {
+				r.addonData[addonData.SourceID] = append(r.addonData[addonData.SourceID], addonData)
+			}
+		}
+		r.authorData = append(r.authorData, result.AuthorData...)
+	}
+}
+
+// claim returns true if url hasn't been claimed before, the caller is then the only one to process it
+func (r *scrapeResults) claim(url string) bool {
+	shard := r.shard(url)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if shard.urls[url] {
+		return false
+	}
+	shard.urls[url] = true
+	return true
+}
+
+// isProcessed returns true if url has been claimed
+func (r *scrapeResults) isProcessed(url string) bool {
+	shard := r.shard(url)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	return shard.urls[url]
+}
+
+func (r *scrapeResults) shard(url string) *urlShard {
+	return &r.processed[maphash.String(r.seed, url)%urlShards]
+}
+
+// add hands result to the collector, blocking until it's taken or ctx is done
+func (r *scrapeResults) add(ctx context.Context, result *types.ParseResult) error {
+	select {
+	case r.results <- result:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close waits for the collector to take every result added.
+// add must not be called again.
+func (r *scrapeResults) close() {
+	close(r.results)
+	<-r.done
+}
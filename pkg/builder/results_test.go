@@ -0,0 +1,143 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestScrapeResults(t *testing.T) {
+	results := newScrapeResults(1)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	var claimed sync.Map
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				url := fmt.Sprintf("https://example.org/%d", i)
+				if !results.claim(url) {
+					continue
+				}
+				if _, ok := claimed.LoadOrStore(url, worker); ok {
+					t.Errorf("claim(%s) = true for a second worker", url)
+				}
+				result := &types.ParseResult{
+					AddonData:  []types.AddonData{{SourceID: fmt.Sprint(i % 10)}, {}},
+					AuthorData: []types.AuthorData{{}},
+				}
+				if err := results.add(ctx, result); err != nil {
+					t.Errorf("add() unexpected error: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	results.close()
+
+	if !results.isProcessed("https://example.org/0") || results.isProcessed("https://example.org/100") {
+		t.Error("isProcessed() doesn't match the URLs claimed")
+	}
+	if len(results.addonData) != 10 {
+		t.Errorf("addon data for %d addons, want 10", len(results.addonData))
+	}
+	for sourceID, dataList := range results.addonData {
+		if len(dataList) != 10 {
+			t.Errorf("addon %s has %d data, want 10", sourceID, len(dataList))
+		}
+	}
+	if len(results.authorData) != 100 {
+		t.Errorf("%d author data, want 100", len(results.authorData))
+	}
+}
+
+func TestScrapeResults_Cancelled(t *testing.T) {
+	results := newScrapeResults(0)
+	defer results.close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// The collector may take it regardless, it mustn't block
+	if err := results.add(ctx, &types.ParseResult{}); err != nil && err != context.Canceled {
+		t.Errorf("add() error = %v, want nil or %v", err, context.Canceled)
+	}
+}
+
+// lockedResults are results gathered under a single lock, as they were before scrapeResults
+type lockedResults struct {
+	mu         sync.Mutex
+	processed  map[string]bool
+	addonData  map[string][]types.AddonData
+	authorData []types.AuthorData
+}
+
+func (r *lockedResults) claim(url string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.processed[url] {
+		return false
+	}
+	r.processed[url] = true
+	return true
+}
+
+func (r *lockedResults) add(result *types.ParseResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, addonData := range result.AddonData {
+		r.addonData[addonData.SourceID] = append(r.addonData[addonData.SourceID], addonData)
+	}
+	r.authorData = append(r.authorData, result.AuthorData...)
+	for _, url := range result.DownloadURLs {
+		_ = r.processed[url]
+	}
+}
+
+// benchmarkResult is what an addon's detail page yields, with the URLs it links to
+func benchmarkResult(i int) *types.ParseResult {
+	sourceID := fmt.Sprint(i % 8000)
+	return &types.ParseResult{
+		AddonData:    []types.AddonData{{SourceID: sourceID, Kind: types.WebDetailData}},
+		DownloadURLs: []string{"https://example.org/api/" + sourceID, "https://example.org/page/" + sourceID},
+	}
+}
+
+// BenchmarkScrapeResults compares the workers of a scrape sharing results through the collector and through a single lock,
+// run with e.g. -cpu 1,8,64
+func BenchmarkScrapeResults(b *testing.B) {
+	ctx := context.Background()
+
+	b.Run("collector", func(b *testing.B) {
+		results := newScrapeResults(64)
+		var next atomic.Int64
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				i := int(next.Add(1))
+				results.claim(fmt.Sprint("https://example.org/", i))
+				result := benchmarkResult(i)
+				_ = results.add(ctx, result)
+				for _, url := range result.DownloadURLs {
+					results.isProcessed(url)
+				}
+			}
+		})
+		results.close()
+	})
+
+	b.Run("lock", func(b *testing.B) {
+		results := &lockedResults{processed: map[string]bool{}, addonData: map[string][]types.AddonData{}}
+		var next atomic.Int64
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				i := int(next.Add(1))
+				results.claim(fmt.Sprint("https://example.org/", i))
+				results.add(benchmarkResult(i))
+			}
+		})
+	})
+}
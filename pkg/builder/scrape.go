@@ -0,0 +1,1157 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/adaptive"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/addondata"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/catalogue"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/codeberg"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/deadletter"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/description"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/gate"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/github"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/gitlab"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/history"
+	httpClient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/progress"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/refresh"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/report"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/retry"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/signing"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/state"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/townlongyak"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/wago"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/wowi"
+	"golang.org/x/sync/errgroup"
+)
+
+// scraper scrapes the sources and writes the catalogues of a single scrape
+type scraper struct {
+	builder *catalogue.Builder
+}
+
+// newScraper returns a scraper with a fresh catalogue builder, so no blocklist or overrides carry over between scrapes
+func newScraper() *scraper {
+	return &scraper{builder: catalogue.NewBuilder()}
+}
+
+// scrape scrapes the sources and writes the catalogues, filling in the catalogue and report as it goes
+func (s *scraper) scrape(ctx context.Context, config Options, cat *Catalogue, rep *Report) error {
+	slog.Info("starting scrape command", "sources", config.Sources)
+	startedAt := rep.StartedAt
+	sources := config.sources()
+	shortPolicy, err := config.shortPolicy()
+	if err != nil {
+		return err
+	}
+	scope := config.scope()
+	if scope != nil {
+		slog.Warn("partial scrape, the catalogue will be missing addons and can't be published", "scope", scope.String())
+	}
+	collector := report.NewCollector()
+	if config.Bandwidth != nil {
+		// Each scrape of the daemon has the whole budget
+		config.Bandwidth.Reset()
+	}
+
+	if err := s.builder.SetSpecVersion(config.SpecVersion); err != nil {
+		return err
+	}
+	if err := s.builder.SetDatestamp(config.Datestamp); err != nil {
+		return err
+	}
+	s.builder.SetUnknownGameTracks(config.UnknownGameTracks)
+	s.builder.SetPopularity(config.Popularity)
+	for field, strategy := range config.MergeStrategies {
+		if err := s.builder.SetMergeStrategy(field, catalogue.MergeStrategy(strategy)); err != nil {
+			return err
+		}
+	}
+	if err := s.builder.LoadBlocklist(config.Blocklist); err != nil {
+		return err
+	}
+	if err := s.builder.LoadOverrides(config.Overrides); err != nil {
+		return err
+	}
+	// Read the key now rather than finding it's unusable after a long scrape
+	signKey, err := state.ReadSignKey(config.SignKey)
+	if err != nil {
+		return err
+	}
+
+	if config.OnlyIDsFile != "" {
+		ids, err := readOnlyIDsFile(config.OnlyIDsFile)
+		if err != nil {
+			return err
+		}
+		config.OnlyIDs = append(config.OnlyIDs, ids...)
+	}
+
+	if config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+	}
+
+	// Sources are on different hosts, scrape them all at once.
+	// The first to fail cancels the others.
+	type sourceResult struct {
+		source types.Source
+		addons []types.Addon
+	}
+	results := make(chan sourceResult, len(sources))
+	group, groupCtx := errgroup.WithContext(ctx)
+	for _, source := range sources {
+		sourceConfig := config
+		sourceConfig.MaxWorkers = config.SourceWorkerBudget(Source(source))
+		if _, ok := config.SourceWorkers[Source(source)]; ok {
+			sourceConfig.AutoWorkers = false
+		}
+		group.Go(func() error {
+			addons, err := s.scrapeSource(groupCtx, sourceConfig, source, collector)
+			if err != nil {
+				return err
+			}
+			results <- sourceResult{source: source, addons: addons}
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return err
+	}
+	close(results)
+
+	// Combined in the order the sources were given, so the result doesn't depend on which finished first
+	addonsBySource := make(map[types.Source][]types.Addon, len(sources))
+	for result := range results {
+		addonsBySource[result.source] = result.addons
+	}
+	var allAddons []types.Addon
+	for _, source := range sources {
+		allAddons = append(allAddons, addonsBySource[source]...)
+	}
+
+	if !config.IncludeImages {
+		for i := range allAddons {
+			allAddons[i].ImageURL = ""
+		}
+	}
+	if !config.ExtendedFields {
+		for i := range allAddons {
+			allAddons[i].FavoriteCount = nil
+			allAddons[i].MonthlyDownloadCount = nil
+		}
+	}
+	if !config.WithDependencies {
+		for i := range allAddons {
+			allAddons[i].DependencyList = nil
+		}
+	}
+	if !config.Popularity {
+		for i := range allAddons {
+			allAddons[i].Popularity = nil // kept from the last scrape by an incremental scrape
+		}
+	}
+
+	// Link addons published to more than one source
+	if linked := s.builder.LinkDuplicates(allAddons); linked > 0 {
+		slog.Info("linked addons found in more than one source", "addons", linked)
+	}
+
+	for _, addon := range allAddons {
+		if s.builder.Excluded(addon) {
+			collector.Skipped(addon.Source, addon.SourceID, report.Blocklisted)
+		}
+	}
+
+	appliedOverrides, staleOverrides := s.builder.CheckOverrides(allAddons)
+	for _, key := range staleOverrides {
+		slog.Warn("stale override, addon is gone or no longer needs it", "override", key)
+	}
+
+	// Build full catalogue with all sources
+	fullCatalogue := s.builder.BuildCatalogue(allAddons, sources)
+	slog.Info("built catalogue", "total-addons", fullCatalogue.Total)
+
+	// Changelogs are only ever kept in the full catalogue, the others are published as-is
+	if !config.IncludeChangelogs {
+		fullCatalogue = s.builder.StripChangelogs(fullCatalogue)
+	}
+	publishedCatalogue := s.builder.StripChangelogs(fullCatalogue)
+	*cat = newCatalogue(fullCatalogue)
+
+	// Create state directory, a partial catalogue goes to its own so the last full one is kept
+	stateDir := config.StateDir
+	if scope != nil {
+		stateDir = filepath.Join(config.StateDir, state.PartialDir)
+		slog.Info("partial scrape, writing the catalogues apart from the last full scrape's", "dir", stateDir)
+	}
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	// Write source-specific catalogues
+	var catalogueFiles []string
+	for _, source := range sources {
+		sourceCatalogue := s.builder.FilterCatalogue(publishedCatalogue, func(addon types.Addon) bool {
+			return addon.Source == source
+		})
+
+		filename, ok := state.SourceCatalogueFiles[source]
+		if !ok {
+			continue
+		}
+
+		outputPath := filepath.Join(stateDir, filename)
+		if err := state.WriteCatalogue(sourceCatalogue, outputPath, config.NoIndent); err != nil {
+			return err
+		}
+		catalogueFiles = append(catalogueFiles, outputPath)
+	}
+
+	// Write full catalogue (all sources)
+	fullPath := filepath.Join(stateDir, state.FullCatalogueFile)
+	previousCatalogue := state.ReadPreviousCatalogue(fullPath)
+	if previousCatalogue != nil {
+		for _, change := range s.builder.DiffCatalogues(*previousCatalogue, fullCatalogue) {
+			switch change.Change {
+			case catalogue.AddonAdded:
+				rep.Added++
+			case catalogue.AddonUpdated:
+				rep.Updated++
+			case catalogue.AddonRemoved:
+				rep.Removed++
+			}
+		}
+	}
+	if err := state.WriteCatalogue(fullCatalogue, fullPath, config.NoIndent); err != nil {
+		return err
+	}
+	catalogueFiles = append(catalogueFiles, fullPath)
+
+	if config.IncludeChangelogs {
+		changelogs := s.builder.ExtractChangelogs(fullCatalogue)
+		if err := catalogue.WriteChangelogs(changelogs, filepath.Join(stateDir, state.ChangelogsFile)); err != nil {
+			return err
+		}
+		slog.Info("wrote changelogs", "file", filepath.Join(stateDir, state.ChangelogsFile), "addons", changelogs.Total)
+	}
+
+	if config.Releases {
+		releasesPath := filepath.Join(stateDir, state.ReleasesFile)
+		releases := s.builder.ExtractReleases(fullCatalogue, allAddons)
+		// Addons kept from the last scrape's catalogue only have releases with spec version 3
+		if config.Incremental || config.MinRefreshAge > 0 || len(config.OnlyIDs) > 0 {
+			if previous, err := catalogue.ReadReleases(releasesPath); err == nil {
+				releases.Keep(previous, fullCatalogue)
+			}
+		}
+		if err := catalogue.WriteReleases(releases, releasesPath); err != nil {
+			return err
+		}
+		slog.Info("wrote releases", "file", releasesPath, "addons", releases.Total)
+	}
+
+	// Addons outside a partial scrape would look removed to the download history and changes feed
+	if scope == nil {
+		if err := s.recordDownloadHistory(fullCatalogue, filepath.Join(stateDir, history.Dir), startedAt); err != nil {
+			return err
+		}
+
+		changesPath := filepath.Join(stateDir, state.ChangesFile)
+		if err := state.UpdateChangesFeed(s.builder, previousCatalogue, fullCatalogue, changesPath); err != nil {
+			return err
+		}
+	} else {
+		slog.Info("partial scrape, not recording download history or updating the changes feed")
+	}
+
+	// Write short catalogue (maintained addons only)
+	shortCatalogue := s.builder.ShortenCatalogue(publishedCatalogue, shortPolicy)
+	if config.CollapseDuplicates {
+		shortCatalogue = s.builder.CollapseDuplicates(shortCatalogue)
+	}
+	slog.Info("shortened catalogue", "original", fullCatalogue.Total, "maintained", shortCatalogue.Total,
+		"cutoff", shortPolicy.CutoffDate(publishedCatalogue).Format(catalogue.DatestampFormat), "min-downloads", shortPolicy.MinDownloads)
+
+	shortPath := filepath.Join(stateDir, state.ShortCatalogueFile)
+	if err := state.WriteCatalogue(shortCatalogue, shortPath, config.NoIndent); err != nil {
+		return err
+	}
+	catalogueFiles = append(catalogueFiles, shortPath)
+
+	if err := state.SealCatalogues(catalogueFiles, filepath.Join(stateDir, signing.ManifestFile), config.Manifest, signKey); err != nil {
+		return err
+	}
+
+	// Decide whether the catalogue is fit to publish, comparing it to the last catalogue that passed
+	passedPath := filepath.Join(config.StateDir, state.PassedCatalogueFile)
+	verdict, err := gate.Run(fullPath, s.readGateBaseline(passedPath, stateDir, previousCatalogue), gate.DefaultThresholds())
+	if err != nil {
+		return fmt.Errorf("failed to run publish gate: %w", err)
+	}
+	logVerdict(verdict)
+	rep.GatePassed = &verdict.Passed
+
+	// A failed or partial catalogue mustn't become the baseline, the next broken scrape would pass against it
+	if verdict.Passed && scope == nil {
+		if err := state.WriteCatalogue(fullCatalogue, passedPath, config.NoIndent); err != nil {
+			return err
+		}
+	}
+
+	metadata := gate.RunMetadata{
+		StartedAt:  startedAt,
+		FinishedAt: time.Now().UTC(),
+		Sources:    sources,
+		Gate:       &verdict,
+		Partial:    scope,
+	}
+	if err := gate.WriteRunMetadata(metadata, filepath.Join(stateDir, state.RunMetadataFile)); err != nil {
+		return err
+	}
+
+	scrapeReport := collector.Report(fullCatalogue.AddonSummaryList)
+	scrapeReport.StartedAt = startedAt
+	scrapeReport.FinishedAt = metadata.FinishedAt
+	scrapeReport.DurationSeconds = metadata.FinishedAt.Sub(startedAt).Seconds()
+	scrapeReport.Sources = sources
+	scrapeReport.AppliedOverrides = appliedOverrides
+	scrapeReport.StaleOverrides = staleOverrides
+	if config.CacheStats != nil {
+		scrapeReport.Cache = report.NewCacheSummary(config.CacheStats.CacheStats())
+	}
+	if config.TraceStats != nil || config.Bandwidth != nil {
+		var traces map[string]httpClient.HostTrace
+		var bytes map[string]int64
+		if config.TraceStats != nil {
+			traces = config.TraceStats.TraceStats()
+		}
+		if config.Bandwidth != nil {
+			bytes = config.Bandwidth.BytesRead()
+		}
+		scrapeReport.Hosts = report.NewHostSummaries(traces, bytes)
+		for _, host := range slices.Sorted(maps.Keys(bytes)) {
+			slog.Info("downloaded from host", "host", host, "bytes", report.FormatBytes(bytes[host]))
+		}
+	}
+	slog.Info("scrape report",
+		"urls-fetched", scrapeReport.URLsFetched,
+		"http-errors", scrapeReport.HTTPErrors,
+		"parse-failures", len(scrapeReport.ParseFailures),
+		"skipped-addons", len(scrapeReport.SkippedAddons),
+		"unclassified-addons", scrapeReport.UnclassifiedAddons)
+	added, updated, removed, gatePassed := rep.Added, rep.Updated, rep.Removed, rep.GatePassed
+	*rep = newReport(scrapeReport)
+	rep.Added, rep.Updated, rep.Removed, rep.GatePassed = added, updated, removed, gatePassed
+	if err := report.Write(scrapeReport, filepath.Join(stateDir, state.ScrapeReportFile)); err != nil {
+		return err
+	}
+
+	failures := collector.Failures()
+	failedURLsPath := filepath.Join(stateDir, state.FailedURLsFile)
+	if err := report.WriteFailures(failures, failedURLsPath); err != nil {
+		return err
+	}
+	if config.MaxFailures >= 0 && len(failures) > config.MaxFailures {
+		return fmt.Errorf("%d URLs failed to fetch or parse, more than the %d allowed by --max-failures, see %s", len(failures), config.MaxFailures, failedURLsPath)
+	}
+	if refused := scrapeReport.HTTPErrors[report.BudgetExceeded]; refused > 0 {
+		return fmt.Errorf("%d requests refused, their hosts had sent the bytes allowed by --max-bytes-per-host, see %s", refused, failedURLsPath)
+	}
+	return nil
+}
+
+// sourceMiddlewares are request policies particular to a source, applied to each attempt of a retried request
+var sourceMiddlewares = map[types.Source][]httpClient.Middleware{
+	// A small personal site, go easy on it
+	types.TownlongYakSource: {httpClient.RateLimit(500 * time.Millisecond)},
+}
+
+// sourceClient returns client with the request policies of source: retries, then any of its sourceMiddlewares and
+// then middlewares
+func sourceClient(client httpClient.HTTPClient, source types.Source, middlewares ...httpClient.Middleware) httpClient.HTTPClient {
+	middlewares = slices.Concat([]httpClient.Middleware{retry.Middleware(retry.DefaultConfig())}, sourceMiddlewares[source], middlewares)
+	return httpClient.Chain(client, middlewares...)
+}
+
+// scrapeSource scrapes the addons of a single source
+func (s *scraper) scrapeSource(ctx context.Context, config Options, source types.Source, collector *report.Collector) ([]types.Addon, error) {
+	// WowInterface workers take turns, adapted to each attempt of a request with --workers auto
+	workers := adaptive.NewLimiter(adaptive.Fixed(config.MaxWorkers))
+	if config.AutoWorkers && source == types.WowInterfaceSource {
+		workers = adaptive.NewLimiter(adaptive.DefaultConfig(config.MaxWorkers))
+		config.HTTPClient = sourceClient(config.HTTPClient, source, workers.Middleware())
+	} else {
+		config.HTTPClient = sourceClient(config.HTTPClient, source)
+	}
+
+	// Outermost, so each URL is counted once however many attempts it took
+	stats := report.NewSourceStats(source)
+	config.HTTPClient = httpClient.Chain(config.HTTPClient, stats.Middleware(urlKind(source)))
+	addons, err := s.scrapeSourceAddons(ctx, config, source, workers, collector, stats)
+	if err != nil {
+		return nil, err
+	}
+	stats.Log(len(addons))
+	return addons, nil
+}
+
+// urlKind returns how the URLs of source are told apart in its stats: WowInterface URLs by type, others by host
+func urlKind(source types.Source) func(url string) string {
+	if source == types.WowInterfaceSource {
+		classifier := wowi.NewURLClassifier()
+		return func(url string) string {
+			return classifier.ClassifyURL(url).String()
+		}
+	}
+	return func(rawURL string) string {
+		if u, err := neturl.Parse(rawURL); err == nil && u.Host != "" {
+			return u.Host
+		}
+		return "unknown"
+	}
+}
+
+// scrapeSourceAddons scrapes the addons of source with the client and workers of scrapeSource
+func (s *scraper) scrapeSourceAddons(ctx context.Context, config Options, source types.Source, workers *adaptive.Limiter, collector *report.Collector, stats *report.SourceStats) ([]types.Addon, error) {
+	switch source {
+	case types.WowInterfaceSource:
+		addons, err := s.scrapeWowInterface(ctx, config, workers, collector, stats)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scrape WowInterface: %w", err)
+		}
+		return addons, nil
+
+	case types.GitHubSource:
+		addons, err := s.scrapeGitHub(ctx, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scrape GitHub: %w", err)
+		}
+		return addons, nil
+
+	case types.GitLabSource:
+		slog.Info("scraping GitLab projects", "topics", gitlab.Topics)
+		addons, err := gitlab.NewParser().BuildCatalogue(ctx, config.HTTPClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scrape GitLab: %w", err)
+		}
+		slog.Info("completed GitLab scraping", "addons", len(addons))
+		return addons, nil
+
+	case types.CodebergSource:
+		slog.Info("scraping Codeberg repositories", "topics", codeberg.Topics)
+		addons, err := codeberg.NewParser().BuildCatalogue(ctx, config.HTTPClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scrape Codeberg: %w", err)
+		}
+		slog.Info("completed Codeberg scraping", "addons", len(addons))
+		return addons, nil
+
+	case types.WagoSource:
+		slog.Info("scraping Wago addons")
+		addons, err := wago.NewParser().BuildCatalogue(ctx, config.HTTPClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scrape Wago: %w", err)
+		}
+		slog.Info("completed Wago scraping", "addons", len(addons))
+		return addons, nil
+
+	case types.TownlongYakSource:
+		slog.Info("scraping Townlong Yak addons", "url", townlongyak.IndexURL)
+		addonDataList, err := townlongyak.NewParser().Scrape(ctx, config.HTTPClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scrape Townlong Yak: %w", err)
+		}
+
+		var addons []types.Addon
+		addonDataMap := make(map[string][]types.AddonData, len(addonDataList))
+		provenances := make(map[string]catalogue.Provenance, len(addonDataList))
+		for _, addonData := range addonDataList {
+			addonDataMap[addonData.SourceID] = append(addonDataMap[addonData.SourceID], addonData)
+			addon, provenance, err := s.builder.MergeAddonDataProvenance([]types.AddonData{addonData})
+			provenances[addonData.SourceID] = provenance
+			if err != nil {
+				slog.Warn("failed to build Townlong Yak addon", "source-id", addonData.SourceID, "error", err)
+				continue
+			}
+			if addon != nil {
+				addons = append(addons, *addon)
+			}
+		}
+		if err := writeAddonData(config.StateDir, types.TownlongYakSource, addonDataMap, provenances, addons, true); err != nil {
+			return nil, err
+		}
+		slog.Info("completed Townlong Yak scraping", "addons", len(addons))
+		return addons, nil
+
+	default:
+		slog.Warn("unsupported source", "source", source)
+		return nil, nil
+	}
+}
+
+// recordDownloadHistory writes a snapshot of the catalogue's download counts and prunes old snapshots
+func (s *scraper) recordDownloadHistory(cat types.Catalogue, dir string, takenAt time.Time) error {
+	path, err := history.Write(history.NewSnapshot(cat.AddonSummaryList, takenAt), dir)
+	if err != nil {
+		return err
+	}
+	slog.Info("wrote download history", "file", path)
+
+	removed, err := history.Prune(dir, takenAt.Add(-history.DefaultRetention))
+	if err != nil {
+		return err
+	}
+	if removed > 0 {
+		slog.Info("pruned download history", "removed", removed)
+	}
+	return nil
+}
+
+// logVerdict logs the publish gate's verdict and the problems behind any failed checks
+func logVerdict(verdict gate.Verdict) {
+	if verdict.Passed {
+		slog.Info("catalogue passed publish gate", "catalogue", verdict.Catalogue, "etag", verdict.ETag)
+		return
+	}
+
+	for _, check := range verdict.CheckList {
+		for _, problem := range check.Problems {
+			slog.Warn("publish gate problem", "check", check.Name, "problem", problem)
+		}
+	}
+	slog.Error("catalogue failed publish gate, write will refuse to release it", "catalogue", verdict.Catalogue, "failed-checks", verdict.Failures())
+}
+
+// readGateBaseline returns the catalogue the publish gate compares a scrape's catalogue to: the last that passed the
+// gate, read from path. State directories from before it was kept fall back to previous, the last scrape's catalogue,
+// if that scrape passed the gate and wasn't partial. Nil if there's no such catalogue.
+func (s *scraper) readGateBaseline(path string, stateDir string, previous *types.Catalogue) *types.Catalogue {
+	passed, err := catalogue.ReadCatalogue(path)
+	if err == nil {
+		return &passed
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("failed to read the last catalogue to pass the publish gate, anomalies won't be checked", "file", path, "error", err)
+		return nil
+	}
+	metadata, err := gate.ReadRunMetadata(filepath.Join(stateDir, state.RunMetadataFile))
+	if err != nil || metadata.Gate == nil || !metadata.Gate.Passed || metadata.Partial != nil {
+		return nil
+	}
+	return previous
+}
+
+// scrapeWowInterface handles WowInterface-specific scraping logic.
+// Up to config.MaxWorkers workers process URLs, each with a turn taken from workers.
+func (s *scraper) scrapeWowInterface(ctx context.Context, config Options, workers *adaptive.Limiter, collector *report.Collector, stats *report.SourceStats) ([]types.Addon, error) {
+	mode := "API + HTML detail pages"
+	if config.Profile == APIOnlyProfile {
+		mode = "API only"
+	}
+	slog.Info("scraping WowInterface", "mode", mode, "api_version", config.apiVersion(), "include_archived", config.IncludeArchived, "only_ids", len(config.OnlyIDs))
+
+	client := config.HTTPClient
+	maxWorkers := config.MaxWorkers
+
+	parser := newWoWIParser(config)
+	deadLettersPath := filepath.Join(config.StateDir, state.DeadLettersFile)
+	deadLetters, err := readDeadLetters(deadLettersPath, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Recorded on every scrape, so --min-refresh-age can be used from the next
+	refreshedPath := filepath.Join(config.StateDir, state.RefreshedFile)
+	refreshed, err := refresh.Read(refreshedPath)
+	if err != nil {
+		return nil, err
+	}
+	incremental := newIncrementalScrapeFor(config, refreshed)
+	scope := newScrapeScope(config)
+
+	// Track processed URLs and addon data
+	results := newScrapeResults(maxWorkers)
+
+	var wg sync.WaitGroup
+	var inFlight atomic.Int32 // Track URLs currently being processed
+
+	// Create worker pool with larger buffer to handle API file list
+	// v3 API has ~7971 addons, each generating 2 URLs = ~16k URLs
+	urlChan := make(chan string, 20000)
+
+	// Report progress until all workers have finished
+	tracker := progress.NewTracker(func() int { return len(urlChan) + int(inFlight.Load()) })
+	var renderer progress.Renderer = progress.LogRenderer{}
+	if config.Progress && progress.IsTerminal(os.Stderr) {
+		renderer = progress.NewBarRenderer(os.Stderr)
+	}
+	stopProgress := make(chan struct{})
+	progressDone := make(chan struct{})
+	go func() {
+		progress.Report(stopProgress, tracker, 2*time.Second, renderer)
+		close(progressDone)
+	}()
+
+	// Start workers
+	for i := 0; i < maxWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				// Take a turn before a URL, so URLs aren't held by workers over the limit
+				if err := workers.Acquire(ctx); err != nil {
+					return
+				}
+				var url string
+				select {
+				case <-ctx.Done():
+					workers.Release()
+					return
+				case next, ok := <-urlChan:
+					if !ok {
+						workers.Release()
+						return
+					}
+					url = next
+				}
+
+				inFlight.Add(1)
+				err := s.processURL(ctx, client, config.UpdateHints, collector, stats, parser, incremental, scope, deadLetters, refreshed, url, results, urlChan)
+				if err != nil && ctx.Err() == nil {
+					slog.Error("failed to process URL", "url", url, "error", err)
+				}
+				tracker.Done(err)
+				inFlight.Add(-1)
+				workers.Release()
+			}
+		}()
+	}
+
+	// Start with initial URL (API filelist only - HTML detail pages discovered from there)
+	startingURLs := wowi.StartingURLs(config.apiVersion())
+
+	// Archived sections aren't in the API filelist and must be crawled via their listing pages
+	if config.IncludeArchived {
+		startingURLs = append(startingURLs, wowi.ArchivedStartingURLs()...)
+	}
+
+	// A targeted re-scrape skips discovery and goes straight to the addons asked for
+	if len(config.OnlyIDs) > 0 {
+		startingURLs = nil
+		for _, sourceID := range config.OnlyIDs {
+			startingURLs = append(startingURLs, parser.AddonURLs(config.apiVersion(), sourceID)...)
+		}
+	}
+	for _, url := range startingURLs {
+		if err := enqueueURL(ctx, urlChan, url); err != nil {
+			break // workers have stopped too
+		}
+	}
+
+	// Monitor queue and close when all work is done
+	go func() {
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return // workers stop on their own
+			case <-ticker.C:
+			}
+			queueDepth := len(urlChan)
+			processing := inFlight.Load()
+
+			// We're done when queue is empty AND nothing is being processed
+			if queueDepth == 0 && processing == 0 {
+				slog.Info("all URLs processed, finishing scrape")
+				close(urlChan)
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	results.close()
+	if config.AutoWorkers {
+		slog.Info("finished with workers", "workers", workers.Limit())
+	}
+	close(stopProgress)
+	<-progressDone
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("WowInterface scrape abandoned with %d URLs left: %w", len(urlChan), err)
+	}
+
+	if err := os.MkdirAll(config.StateDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := deadLetters.Write(deadLettersPath); err != nil {
+		return nil, err
+	}
+
+	// Convert addon data to final addons
+	var addons []types.Addon
+	addonDataMap, authorData := results.addonData, results.authorData
+	provenances := make(map[string]catalogue.Provenance, len(addonDataMap))
+	var complete, partial int
+	for sourceID, dataList := range addonDataMap {
+		addon, provenance, err := s.builder.MergeAddonDataProvenance(dataList)
+		provenances[sourceID] = provenance
+		switch {
+		case err != nil:
+			slog.Error("failed to merge addon data", "source-id", sourceID, "error", err)
+		case addon == nil:
+			collector.Skipped(types.WowInterfaceSource, sourceID, report.SkipReason(dataList))
+		default:
+			addons = append(addons, *addon)
+			if isComplete(dataList, config.Profile) {
+				complete++
+			} else {
+				partial++
+			}
+		}
+	}
+	stats.Graded(complete, partial)
+
+	if incremental != nil {
+		unchanged := incremental.unchangedAddons()
+		slog.Info("kept addons unchanged since the last scrape", "unchanged", len(unchanged), "updated", len(addons))
+		addons = append(addons, unchanged...)
+	}
+
+	if len(config.OnlyIDs) > 0 {
+		merged, err := mergeIntoLastScrape(filepath.Join(config.StateDir, state.FullCatalogueFile), types.WowInterfaceSource, addons)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("merged re-scraped addons into the last scrape", "rescraped", len(addons), "addons", len(merged))
+		addons = merged
+	}
+
+	// Addons outside a partial scrape aren't gone, keep what's known of them
+	scoped := config.scope() != nil
+	if !scoped {
+		sourceIDs := make([]string, len(addons))
+		for i, addon := range addons {
+			sourceIDs[i] = addon.SourceID
+		}
+		refreshed.Retain(sourceIDs)
+	}
+	if err := refreshed.Write(refreshedPath); err != nil {
+		return nil, err
+	}
+	if err := writeAddonData(config.StateDir, types.WowInterfaceSource, addonDataMap, provenances, addons, !scoped); err != nil {
+		return nil, err
+	}
+
+	if config.Authors {
+		// Authors are only known for the addons whose pages were fetched
+		if incremental != nil || len(config.OnlyIDs) > 0 {
+			slog.Warn("authors only list the addons fetched by this scrape", "file", state.AuthorsFile)
+		}
+		authors := s.builder.BuildAuthors(types.WowInterfaceSource, authorData, addons)
+		authorsPath := filepath.Join(config.StateDir, state.AuthorsFile)
+		if scoped {
+			authorsPath = filepath.Join(config.StateDir, state.PartialDir, state.AuthorsFile)
+			if err := os.MkdirAll(filepath.Dir(authorsPath), 0755); err != nil {
+				return nil, fmt.Errorf("failed to create state directory: %w", err)
+			}
+		}
+		if err := catalogue.WriteAuthors(authors, authorsPath); err != nil {
+			return nil, err
+		}
+		slog.Info("wrote authors", "file", authorsPath, "authors", authors.Total)
+	}
+
+	slog.Info("completed WowInterface scraping", "addons", len(addons))
+	return addons, nil
+}
+
+// newWoWIParser returns a WowInterface parser configured for the scrape
+func newWoWIParser(config Options) *wowi.Parser {
+	parser := wowi.NewParser()
+	if config.Summaries {
+		parser.SetDescriptionMode(description.SummaryMode)
+	}
+	parser.SetFollowAuthorPages(config.Authors)
+	parser.SetUnknownGameTracks(config.UnknownGameTracks)
+	parser.SetAPIOnly(config.Profile == APIOnlyProfile)
+	return parser
+}
+
+// readDeadLetters reads the WowInterface addon pages that kept failing in earlier scrapes
+func readDeadLetters(path string, config Options) (*deadletter.Queue, error) {
+	cooldown := config.DeadLetterCooldown
+	if len(config.OnlyIDs) > 0 {
+		cooldown = 0 // asked for by name, try them regardless
+	}
+	return deadletter.Read(path, cooldown)
+}
+
+// newIncrementalScrapeFor returns the incremental scrape the config asks for, nil for a full scrape
+func newIncrementalScrapeFor(config Options, refreshed *refresh.Times) *incrementalScrape {
+	if !config.Incremental && config.MinRefreshAge <= 0 {
+		return nil
+	}
+	return newIncrementalScrape(filepath.Join(config.StateDir, state.FullCatalogueFile), refreshed, config.MinRefreshAge)
+}
+
+// writeAddonData keeps the data each addon of source was merged from, and where its merged fields came from, in the
+// state directory for the show command. With prune, the data of addons neither scraped nor in the catalogue any more
+// is removed.
+func writeAddonData(stateDir string, source types.Source, addonDataMap map[string][]types.AddonData, provenances map[string]catalogue.Provenance, catalogued []types.Addon, prune bool) error {
+	dir := filepath.Join(stateDir, addondata.Dir)
+	keep := make([]string, 0, len(addonDataMap)+len(catalogued))
+	for sourceID, dataList := range addonDataMap {
+		if err := addondata.Write(dir, source, sourceID, dataList, provenances[sourceID]); err != nil {
+			return err
+		}
+		keep = append(keep, sourceID)
+	}
+	if !prune {
+		slog.Info("wrote addon data", "dir", dir, "source", source, "addons", len(addonDataMap))
+		return nil
+	}
+	for _, addon := range catalogued {
+		keep = append(keep, addon.SourceID)
+	}
+
+	removed, err := addondata.Retain(dir, source, keep)
+	if err != nil {
+		return err
+	}
+	slog.Info("wrote addon data", "dir", dir, "source", source, "addons", len(addonDataMap), "removed", removed)
+	return nil
+}
+
+// mergeIntoLastScrape returns the addons of source in the catalogue at path with those in rescraped replacing them.
+// Addons that couldn't be re-scraped keep their previous entry.
+func mergeIntoLastScrape(path string, source types.Source, rescraped []types.Addon) ([]types.Addon, error) {
+	previous, err := catalogue.ReadCatalogue(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the last scrape to merge re-scraped addons into: %w", err)
+	}
+
+	replaced := make(map[string]bool, len(rescraped))
+	for _, addon := range rescraped {
+		replaced[addon.SourceID] = true
+	}
+
+	var addons []types.Addon
+	for _, addon := range previous.AddonSummaryList {
+		if addon.Source == source && !replaced[addon.SourceID] {
+			addons = append(addons, addon)
+		}
+	}
+	return append(addons, rescraped...), nil
+}
+
+// incrementalScrape skips fetching the details of WowInterface addons that haven't been updated since the last scrape,
+// keeping their previous entry instead. Safe for concurrent use.
+type incrementalScrape struct {
+	previous      map[string]types.Addon // source-id -> addon of the last scrape
+	refreshed     *refresh.Times
+	minRefreshAge time.Duration // only skip addons fetched within this long, 0 to skip any not updated
+
+	mu        sync.Mutex
+	unchanged []types.Addon
+}
+
+// newIncrementalScrape reads the WowInterface addons of the last scrape from the catalogue at path.
+// Without a last scrape every addon is fetched, as in a full scrape.
+// With a minRefreshAge, addons not updated are still fetched if they weren't within that long, going by refreshed.
+func newIncrementalScrape(path string, refreshed *refresh.Times, minRefreshAge time.Duration) *incrementalScrape {
+	s := &incrementalScrape{previous: make(map[string]types.Addon), refreshed: refreshed, minRefreshAge: minRefreshAge}
+	previous, err := catalogue.ReadCatalogue(path)
+	if err != nil {
+		slog.Warn("no last scrape to compare against, fetching every addon", "file", path, "error", err)
+		return s
+	}
+	for _, addon := range previous.AddonSummaryList {
+		if addon.Source == types.WowInterfaceSource {
+			s.previous[addon.SourceID] = addon
+		}
+	}
+	return s
+}
+
+// filter removes the addons of a filelist result not updated since the last scrape, along with their detail URLs
+func (s *incrementalScrape) filter(result *types.ParseResult) {
+	now := time.Now()
+	unchanged := make(map[string]bool)
+	var addonData []types.AddonData
+	for _, data := range result.AddonData {
+		previous, ok := s.previous[data.SourceID]
+		if ok && data.UpdatedDate != nil && !data.UpdatedDate.After(previous.UpdatedDate) &&
+			(s.minRefreshAge == 0 || s.refreshed.Fresh(data.SourceID, s.minRefreshAge, now)) {
+			unchanged[data.SourceID] = true
+			continue
+		}
+		addonData = append(addonData, data)
+	}
+
+	var downloadURLs []string
+	for _, downloadURL := range result.DownloadURLs {
+		if !unchanged[wowi.SourceIDFromURL(downloadURL)] {
+			downloadURLs = append(downloadURLs, downloadURL)
+		}
+	}
+	result.AddonData, result.DownloadURLs = addonData, downloadURLs
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sourceID := range unchanged {
+		s.unchanged = append(s.unchanged, s.previous[sourceID])
+	}
+}
+
+// unchangedAddons returns the last scrape's entry of each addon found not to have been updated since
+func (s *incrementalScrape) unchangedAddons() []types.Addon {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]types.Addon{}, s.unchanged...)
+}
+
+// scrapeScope limits a WowInterface scrape to part of the file list, see Options.Limit and Options.Categories
+type scrapeScope struct {
+	limit       int             // 0 for no limit
+	categoryIDs map[string]bool // empty for every category
+}
+
+// newScrapeScope returns the part of the file list the config limits the scrape to, nil if it isn't limited
+func newScrapeScope(config Options) *scrapeScope {
+	scope := config.scope()
+	if scope == nil {
+		return nil
+	}
+	s := &scrapeScope{limit: scope.Limit, categoryIDs: make(map[string]bool)}
+	for _, name := range scope.Categories {
+		if id, ok := wowi.CategoryID(name); ok {
+			s.categoryIDs[id] = true
+		}
+	}
+	return s
+}
+
+// filter removes the addons of a filelist result outside the scope, along with their detail URLs: those not in one
+// of its categories and, in the order of the file list, those after the first limit addons
+func (s *scrapeScope) filter(result *types.ParseResult) {
+	kept := make(map[string]bool)
+	var addonData []types.AddonData
+	for _, data := range result.AddonData {
+		if len(s.categoryIDs) > 0 && !s.categoryIDs[wowi.FileListCategoryID(data)] {
+			continue
+		}
+		if s.limit > 0 && len(addonData) == s.limit {
+			break
+		}
+		kept[data.SourceID] = true
+		addonData = append(addonData, data)
+	}
+
+	var downloadURLs []string
+	for _, downloadURL := range result.DownloadURLs {
+		if kept[wowi.SourceIDFromURL(downloadURL)] {
+			downloadURLs = append(downloadURLs, downloadURL)
+		}
+	}
+	slog.Info("limited the scrape to part of the file list", "addons", len(addonData), "of", len(result.AddonData))
+	result.AddonData, result.DownloadURLs = addonData, downloadURLs
+}
+
+// readOnlyIDsFile returns the WowInterface addons listed in a failed-urls.json, each once
+func readOnlyIDsFile(path string) ([]string, error) {
+	failures, err := report.ReadFailures(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, failure := range failures {
+		if id := wowi.SourceIDFromURL(failure.URL); id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no WowInterface addons to re-scrape in %s", path)
+	}
+	return ids, nil
+}
+
+// scrapeGitHub handles GitHub-specific scraping logic
+func (s *scraper) scrapeGitHub(ctx context.Context, config Options) ([]types.Addon, error) {
+	slog.Info("scraping GitHub catalogue")
+
+	parser := github.NewParser()
+	if config.Summaries {
+		parser.SetDescriptionMode(description.SummaryMode)
+	}
+	addons, err := parser.BuildCatalogue()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build GitHub catalogue: %w", err)
+	}
+
+	// Keep source-ids stable against the previously published catalogue
+	publishedPath := filepath.Join(config.StateDir, state.SourceCatalogueFiles[types.GitHubSource])
+	if published := state.ReadPreviousCatalogue(publishedPath); published != nil {
+		parser.ApplyMigrations(addons, github.NewSourceIDMigrations(published.AddonSummaryList))
+	}
+
+	if config.GitHubReadmes {
+		slog.Info("filling empty GitHub descriptions from READMEs")
+		missingPath := filepath.Join(config.StateDir, state.MissingReadmesFile)
+		missing, err := github.ReadMissingReadmes(missingPath)
+		if err != nil {
+			return nil, err
+		}
+		filled := parser.FillReadmeDescriptions(ctx, config.HTTPClient, addons, missing, config.GitHubReadmeInterval)
+		slog.Info("filled GitHub descriptions", "addons", filled, "without-readme", len(missing))
+		if err := os.MkdirAll(config.StateDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create state directory: %w", err)
+		}
+		if err := missing.Write(missingPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.GitHubTopics {
+		slog.Info("tagging GitHub addons with repository topics")
+		tagged := parser.FillTopicTags(ctx, config.HTTPClient, addons, config.GitHubTopicsInterval)
+		slog.Info("tagged GitHub addons", "addons", tagged)
+	}
+
+	slog.Info("completed GitHub scraping", "addons", len(addons))
+	return addons, nil
+}
+
+// isComplete returns true if an addon's data has all a scrape with profile fetches: the API's details and, unless
+// the profile is api-only, the addon's page
+func isComplete(dataList []types.AddonData, profile Profile) bool {
+	kinds := make(map[types.DataKind]bool, len(dataList))
+	for _, data := range dataList {
+		kinds[data.Kind] = true
+	}
+	return kinds[types.APIDetailData] && (kinds[types.WebDetailData] || profile == APIOnlyProfile)
+}
+
+// processURL processes a single URL and adds results to the data structures
+func (s *scraper) processURL(
+	ctx context.Context,
+	client httpClient.HTTPClient,
+	hints UpdateHinter,
+	collector *report.Collector,
+	stats *report.SourceStats,
+	parser *wowi.Parser,
+	incremental *incrementalScrape, // nil for a full scrape
+	scope *scrapeScope, // nil to scrape every addon of the file list
+	deadLetters *deadletter.Queue,
+	refreshed *refresh.Times,
+	url string,
+	results *scrapeResults,
+	urlChan chan<- string,
+) error {
+	// Check if already processed
+	if !results.claim(url) {
+		return nil
+	}
+
+	// Addons removed for good keep failing, don't spend time on them again until the cooldown is over. Only pages
+	// missing in several scrapes are skipped, network errors and server errors are never dead-lettered.
+	isAddonPage := wowi.SourceIDFromURL(url) != ""
+	if isAddonPage && deadLetters.Skip(url, time.Now()) {
+		slog.Debug("skipping dead-lettered URL", "url", url)
+		collector.DeadLetterSkipped()
+		return nil
+	}
+
+	slog.Debug("processing URL", "url", url)
+	collector.Fetched()
+
+	// Download content, retried by the client's middlewares
+	resp, err := client.Get(ctx, url)
+	if err != nil {
+		collector.FetchFailed(url, 0, err)
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+
+	if resp.StatusCode != 200 {
+		collector.FetchFailed(url, resp.StatusCode, nil)
+		if isAddonPage && deadletter.Permanent(resp.StatusCode) {
+			deadLetters.Add(url, resp.StatusCode, nil, time.Now().UTC())
+		}
+		return fmt.Errorf("non-200 status code %d for %s", resp.StatusCode, url)
+	}
+	deadLetters.Remove(url)
+
+	// Parse content
+	started := time.Now()
+	result, err := parser.Parse(url, resp.Body)
+	stats.Parsed(wowi.NewURLClassifier().ClassifyURL(url).String(), time.Since(started))
+	if err != nil {
+		collector.ParseFailed(url, err)
+		return fmt.Errorf("failed to parse %s: %w", url, err)
+	}
+	if isAddonPage {
+		refreshed.Set(wowi.SourceIDFromURL(url), time.Now().UTC())
+	}
+
+	if wowi.NewURLClassifier().ClassifyURL(url) == wowi.URLTypeAPIFileList {
+		if scope != nil {
+			scope.filter(result)
+		}
+		if incremental != nil {
+			incremental.filter(result)
+		}
+	}
+
+	// Hints must be registered before the URLs they describe are enqueued
+	if hints != nil {
+		for updatedURL, updated := range result.UpdatedDates {
+			hints.SetUpdatedDate(updatedURL, updated)
+		}
+	}
+
+	// Store addon data
+	if err := results.add(ctx, result); err != nil {
+		return err
+	}
+
+	// Add new URLs to process (both API and HTML detail pages)
+	for _, newURL := range result.DownloadURLs {
+		if results.isProcessed(newURL) {
+			continue
+		}
+		if err := enqueueURL(ctx, urlChan, newURL); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// enqueueURL blocks until url is queued, we don't want to skip URLs, or ctx is done
+func enqueueURL(ctx context.Context, urlChan chan<- string, url string) error {
+	select {
+	case urlChan <- url:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,733 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/addondata"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/catalogue"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/deadletter"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/gate"
+	httpClient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/refresh"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/report"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/state"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/wowi"
+)
+
+// stallingClient answers nothing until the request is cancelled
+type stallingClient struct{}
+
+func (stallingClient) Get(ctx context.Context, url string) (*httpClient.Response, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestScrape_Timeout(t *testing.T) {
+	config := Options{
+		HTTPClient: stallingClient{},
+		Sources:    []Source{WowInterface},
+		MaxWorkers: 2,
+		StateDir:   t.TempDir(),
+		Timeout:    50 * time.Millisecond,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := Scrape(context.Background(), config)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Scrape() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Scrape() didn't stop after its timeout")
+	}
+}
+
+func TestEnqueueURL_Cancelled(t *testing.T) {
+	urlChan := make(chan string, 1)
+	urlChan <- "https://example.org/queued"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := enqueueURL(ctx, urlChan, "https://example.org/full"); !errors.Is(err, context.Canceled) {
+		t.Errorf("enqueueURL() on a full queue = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestScrape_MaxFailures(t *testing.T) {
+	stateDir := t.TempDir()
+	client := httpClient.NewMockHTTPClient()
+	filelistURL := wowi.GetAPIFileList(wowi.APIVersionV4)
+	filelist := `[{"id": 25078, "title": "Addon", "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]}]`
+	client.SetResponse(filelistURL, &httpClient.Response{StatusCode: 200, Body: []byte(filelist)})
+	detailPage, err := os.ReadFile("../../src/wowi/test/fixtures/addon-25078.html")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	client.SetResponse(wowi.Host+"/downloads/info25078", &httpClient.Response{StatusCode: 200, Body: detailPage})
+	apiDetailURL := wowi.GetAPIHost(wowi.APIVersionV4) + "/filedetails/25078.json"
+	client.SetResponse(apiDetailURL, &httpClient.Response{StatusCode: 404})
+
+	config := Options{
+		HTTPClient:  client,
+		Sources:     []Source{WowInterface},
+		MaxWorkers:  1,
+		StateDir:    stateDir,
+		MaxFailures: 0,
+	}
+	if _, _, err := Scrape(context.Background(), config); err == nil {
+		t.Error("Scrape() expected an error with more failures than --max-failures")
+	}
+
+	// Catalogues and the failed URLs are still written
+	if _, err := os.Stat(filepath.Join(stateDir, state.FullCatalogueFile)); err != nil {
+		t.Errorf("full catalogue not written: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(stateDir, state.FailedURLsFile))
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", state.FailedURLsFile, err)
+	}
+	var failures []report.Failure
+	if err := json.Unmarshal(data, &failures); err != nil {
+		t.Fatalf("failed to parse %s: %v", state.FailedURLsFile, err)
+	}
+	want := []report.Failure{{URL: apiDetailURL, Error: "status 404"}}
+	if !reflect.DeepEqual(failures, want) {
+		t.Errorf("%s = %v, want %v", state.FailedURLsFile, failures, want)
+	}
+
+	config.MaxFailures = 1
+	if _, _, err := Scrape(context.Background(), config); err != nil {
+		t.Errorf("Scrape() unexpected error within --max-failures: %v", err)
+	}
+}
+
+// fakeBandwidth is a BandwidthMeter with fixed counts
+type fakeBandwidth struct {
+	bytes  map[string]int64
+	resets int
+}
+
+func (f *fakeBandwidth) BytesRead() map[string]int64 { return f.bytes }
+func (f *fakeBandwidth) Reset()                      { f.resets++ }
+
+func TestScrape_MaxBytesPerHost(t *testing.T) {
+	stateDir := t.TempDir()
+	client := httpClient.NewMockHTTPClient()
+	filelistURL := wowi.GetAPIFileList(wowi.APIVersionV4)
+	filelist := `[{"id": 25078, "title": "Addon", "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]}]`
+	client.SetResponse(filelistURL, &httpClient.Response{StatusCode: 200, Body: []byte(filelist)})
+	detailPage, err := os.ReadFile("../../src/wowi/test/fixtures/addon-25078.html")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	client.SetResponse(wowi.Host+"/downloads/info25078", &httpClient.Response{StatusCode: 200, Body: detailPage})
+	client.SetError(wowi.GetAPIHost(wowi.APIVersionV4)+"/filedetails/25078.json", fmt.Errorf("failed to fetch: %w", httpClient.ErrBudgetExceeded))
+	bandwidth := &fakeBandwidth{bytes: map[string]int64{"www.wowinterface.com": 1 << 20}}
+
+	config := Options{
+		HTTPClient:  client,
+		Bandwidth:   bandwidth,
+		Sources:     []Source{WowInterface},
+		MaxWorkers:  1,
+		StateDir:    stateDir,
+		MaxFailures: -1,
+	}
+	if _, _, err := Scrape(context.Background(), config); err == nil {
+		t.Error("Scrape() expected an error with requests refused by --max-bytes-per-host")
+	}
+	if bandwidth.resets != 1 {
+		t.Errorf("Reset() called %d times, want once at the start of the scrape", bandwidth.resets)
+	}
+
+	scrapeReport, err := report.Read(filepath.Join(stateDir, state.ScrapeReportFile))
+	if err != nil {
+		t.Fatalf("report.Read() unexpected error: %v", err)
+	}
+	if got := scrapeReport.Hosts["www.wowinterface.com"].Bytes; got != 1<<20 {
+		t.Errorf("Hosts[www.wowinterface.com].Bytes = %d, want %d", got, 1<<20)
+	}
+	if got := scrapeReport.HTTPErrors[report.BudgetExceeded]; got == 0 {
+		t.Errorf("HTTPErrors = %v, want the refused requests counted as %s", scrapeReport.HTTPErrors, report.BudgetExceeded)
+	}
+
+	// Refused requests say nothing about their URLs, they aren't dead-lettered
+	deadLetters, err := deadletter.Read(filepath.Join(stateDir, state.DeadLettersFile), time.Hour)
+	if err != nil {
+		t.Fatalf("deadletter.Read() unexpected error: %v", err)
+	}
+	if entries := deadLetters.Entries(); len(entries) != 0 {
+		t.Errorf("dead letters = %+v, want none", entries)
+	}
+}
+
+func TestScrape_AutoWorkers(t *testing.T) {
+	client := httpClient.NewMockHTTPClient()
+	filelist := `[{"id": 25078, "title": "Addon", "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]}]`
+	client.SetResponse(wowi.GetAPIFileList(wowi.APIVersionV4), &httpClient.Response{StatusCode: 200, Body: []byte(filelist)})
+	serveAddon25078(t, client)
+
+	config := Options{
+		HTTPClient:  client,
+		Sources:     []Source{WowInterface},
+		MaxWorkers:  50,
+		AutoWorkers: true,
+		StateDir:    t.TempDir(),
+	}
+	if _, _, err := Scrape(context.Background(), config); err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+	if labels := scrapedLabels(t, config.StateDir); labels["25078"] != "Better Vendor Price" {
+		t.Errorf("scraped labels = %v, want addon 25078", labels)
+	}
+}
+
+func TestScrape_APIOnlyProfile(t *testing.T) {
+	client := httpClient.NewMockHTTPClient()
+	filelist := `[{"id": 25078, "title": "Addon", "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]}]`
+	client.SetResponse(wowi.GetAPIFileList(wowi.APIVersionV4), &httpClient.Response{StatusCode: 200, Body: []byte(filelist)})
+	serveAddon25078(t, client)
+
+	config := Options{
+		HTTPClient:  client,
+		Sources:     []Source{WowInterface},
+		MaxWorkers:  1,
+		StateDir:    t.TempDir(),
+		MaxFailures: 0,
+		Profile:     APIOnlyProfile,
+	}
+	if _, _, err := Scrape(context.Background(), config); err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+	if labels := scrapedLabels(t, config.StateDir); labels["25078"] != "Better Vendor Price" {
+		t.Errorf("scraped labels = %v, want addon 25078 from its API detail", labels)
+	}
+	for _, call := range client.GetCalls() {
+		if wowi.NewURLClassifier().ClassifyURL(call) == wowi.URLTypeAddonDetail {
+			t.Errorf("fetched addon page %s with --profile api-only", call)
+		}
+	}
+}
+
+func TestScrape_Releases(t *testing.T) {
+	client := httpClient.NewMockHTTPClient()
+	filelist := `[{"id": 25078, "title": "Addon", "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]}]`
+	client.SetResponse(wowi.GetAPIFileList(wowi.APIVersionV4), &httpClient.Response{StatusCode: 200, Body: []byte(filelist)})
+	apiDetail := `[{"id": 25078, "title": "Better Vendor Price", "lastUpdate": 1717200000000, "version": "1.2.3", "checksum": "abc123",
+		"downloadUri": "https://cdn.wowinterface.com/downloads/getfile.php?id=25078"}]`
+	client.SetResponse(wowi.GetAPIHost(wowi.APIVersionV4)+"/filedetails/25078.json", &httpClient.Response{StatusCode: 200, Body: []byte(apiDetail)})
+
+	config := Options{
+		HTTPClient:  client,
+		Sources:     []Source{WowInterface},
+		MaxWorkers:  1,
+		StateDir:    t.TempDir(),
+		MaxFailures: 0,
+		Profile:     APIOnlyProfile,
+		Releases:    true,
+	}
+	if _, _, err := Scrape(context.Background(), config); err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+
+	releases, err := catalogue.ReadReleases(filepath.Join(config.StateDir, state.ReleasesFile))
+	if err != nil {
+		t.Fatalf("ReadReleases() unexpected error: %v", err)
+	}
+	want := types.Release{DownloadURL: "https://cdn.wowinterface.com/downloads/getfile.php?id=25078", GameTrack: types.RetailTrack, Version: "1.2.3", Checksum: "abc123"}
+	got := releases.ReleaseMap[types.WowInterfaceSource]["25078"][types.RetailTrack]
+	got.InterfaceList = nil
+	if releases.Total != 1 || !reflect.DeepEqual(got, want) {
+		t.Errorf("releases = %+v, want the API's release of 25078 as its retail release", releases)
+	}
+}
+
+func TestIsComplete(t *testing.T) {
+	filelist := types.AddonData{Kind: types.APIFileListData}
+	apiDetail := types.AddonData{Kind: types.APIDetailData}
+	page := types.AddonData{Kind: types.WebDetailData}
+	tests := []struct {
+		name     string
+		dataList []types.AddonData
+		profile  Profile
+		want     bool
+	}{
+		{"api and page", []types.AddonData{filelist, apiDetail, page}, FullProfile, true},
+		{"page failed", []types.AddonData{filelist, apiDetail}, FullProfile, false},
+		{"api detail failed", []types.AddonData{filelist, page}, FullProfile, false},
+		{"api only", []types.AddonData{filelist, apiDetail}, APIOnlyProfile, true},
+		{"default profile", []types.AddonData{apiDetail}, "", false},
+	}
+	for _, tt := range tests {
+		if got := isComplete(tt.dataList, tt.profile); got != tt.want {
+			t.Errorf("isComplete(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// writeLastScrape writes the full catalogue of a previous scrape with WowInterface addons 1 and 25078, both updated 2024-01-01
+func writeLastScrape(t *testing.T, stateDir string) {
+	t.Helper()
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	kept := types.Addon{
+		Source:        types.WowInterfaceSource,
+		SourceID:      "1",
+		Name:          "one",
+		Label:         "One",
+		URL:           "https://www.wowinterface.com/downloads/info1",
+		UpdatedDate:   updated,
+		GameTrackList: []types.GameTrack{types.RetailTrack},
+		TagList:       []string{},
+	}
+	stale := kept
+	stale.SourceID, stale.Name, stale.Label, stale.URL = "25078", "stale", "Stale", "https://www.wowinterface.com/downloads/info25078"
+	last := types.Catalogue{Datestamp: "2024-01-02", Total: 2, AddonSummaryList: []types.Addon{kept, stale}}
+	last.Spec.Version = 2
+	if err := state.WriteCatalogue(last, filepath.Join(stateDir, state.FullCatalogueFile), false); err != nil {
+		t.Fatalf("WriteCatalogue() unexpected error: %v", err)
+	}
+}
+
+// serveAddon25078 serves the detail page and API detail of WowInterface addon 25078, updated 2024-06-01
+func serveAddon25078(t *testing.T, client *httpClient.MockHTTPClient) {
+	t.Helper()
+	detailPage, err := os.ReadFile("../../src/wowi/test/fixtures/addon-25078.html")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	client.SetResponse(wowi.Host+"/downloads/info25078", &httpClient.Response{StatusCode: 200, Body: detailPage})
+	apiDetail := `[{"id": 25078, "title": "Better Vendor Price", "lastUpdate": 1717200000000}]`
+	client.SetResponse(wowi.GetAPIHost(wowi.APIVersionV4)+"/filedetails/25078.json", &httpClient.Response{StatusCode: 200, Body: []byte(apiDetail)})
+}
+
+// scrapedLabels returns the label of each addon in the full catalogue by source-id
+func scrapedLabels(t *testing.T, stateDir string) map[string]string {
+	t.Helper()
+	full, err := catalogue.ReadCatalogue(filepath.Join(stateDir, state.FullCatalogueFile))
+	if err != nil {
+		t.Fatalf("ReadCatalogue() unexpected error: %v", err)
+	}
+	labels := make(map[string]string)
+	for _, addon := range full.AddonSummaryList {
+		labels[addon.SourceID] = addon.Label
+	}
+	return labels
+}
+
+func TestScrape_OnlyIDs(t *testing.T) {
+	stateDir := t.TempDir()
+	writeLastScrape(t, stateDir)
+
+	client := httpClient.NewMockHTTPClient()
+	serveAddon25078(t, client)
+
+	config := Options{
+		HTTPClient:  client,
+		Sources:     []Source{WowInterface},
+		MaxWorkers:  1,
+		StateDir:    stateDir,
+		MaxFailures: 0,
+		OnlyIDs:     []string{"25078"},
+	}
+	if _, _, err := Scrape(context.Background(), config); err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+
+	// The filelist is never fetched
+	for _, call := range client.GetCalls() {
+		if call == wowi.GetAPIFileList(wowi.APIVersionV4) {
+			t.Errorf("Scrape() fetched the filelist with --only-ids")
+		}
+	}
+
+	want := map[string]string{"1": "One", "25078": "Better Vendor Price"}
+	if labels := scrapedLabels(t, stateDir); !reflect.DeepEqual(labels, want) {
+		t.Errorf("full catalogue labels = %v, want %v", labels, want)
+	}
+
+	// The data of each addon fetched is kept with where its merged fields came from, for the show command
+	dir := filepath.Join(stateDir, addondata.Dir)
+	provenance, err := addondata.ReadProvenance(dir, types.WowInterfaceSource, "25078")
+	if err != nil || !reflect.DeepEqual(provenance["updated-date"], []string{"api-detail-v4.json"}) {
+		t.Errorf("ReadProvenance() = %v, %v, want the updated-date from api-detail-v4.json", provenance, err)
+	}
+	if dataList, err := addondata.Read(dir, types.WowInterfaceSource, "1"); err != nil || len(dataList) != 0 {
+		t.Errorf("addondata.Read() of an addon kept from the last scrape = %v, %v, want no data", dataList, err)
+	}
+}
+
+func TestScrape_Incremental(t *testing.T) {
+	stateDir := t.TempDir()
+	writeLastScrape(t, stateDir)
+
+	// Addon 1 is as it was at the last scrape, 25078 has been updated since
+	client := httpClient.NewMockHTTPClient()
+	filelist := `[
+		{"id": 1, "title": "One", "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]},
+		{"id": 25078, "title": "Better Vendor Price", "lastUpdate": 1717200000000, "gameVersions": ["11.0.2"]}
+	]`
+	client.SetResponse(wowi.GetAPIFileList(wowi.APIVersionV4), &httpClient.Response{StatusCode: 200, Body: []byte(filelist)})
+	serveAddon25078(t, client)
+
+	config := Options{
+		HTTPClient:  client,
+		Sources:     []Source{WowInterface},
+		MaxWorkers:  1,
+		StateDir:    stateDir,
+		MaxFailures: 0,
+		Incremental: true,
+	}
+	if _, _, err := Scrape(context.Background(), config); err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+
+	for _, call := range client.GetCalls() {
+		if wowi.SourceIDFromURL(call) == "1" {
+			t.Errorf("Scrape() fetched %s of an addon unchanged since the last scrape", call)
+		}
+	}
+	want := map[string]string{"1": "One", "25078": "Better Vendor Price"}
+	if labels := scrapedLabels(t, stateDir); !reflect.DeepEqual(labels, want) {
+		t.Errorf("full catalogue labels = %v, want %v", labels, want)
+	}
+}
+
+func TestScrape_Scope(t *testing.T) {
+	tests := []struct {
+		name       string
+		filelist   string
+		limit      int
+		categories []string
+	}{
+		{"limit", `[
+			{"id": 25078, "title": "Better Vendor Price", "lastUpdate": 1717200000000, "gameVersions": ["11.0.2"]},
+			{"id": 1, "title": "One", "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]}
+		]`, 1, nil},
+		{"category", `[
+			{"id": 1, "title": "One", "categoryId": 20, "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]},
+			{"id": 25078, "title": "Better Vendor Price", "categoryId": 21, "lastUpdate": 1717200000000, "gameVersions": ["11.0.2"]}
+		]`, 0, []string{"Unit Mods"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := httpClient.NewMockHTTPClient()
+			client.SetResponse(wowi.GetAPIFileList(wowi.APIVersionV4), &httpClient.Response{StatusCode: 200, Body: []byte(tt.filelist)})
+			serveAddon25078(t, client)
+
+			stateDir := t.TempDir()
+			config := Options{
+				HTTPClient: client,
+				Sources:    []Source{WowInterface},
+				MaxWorkers: 1,
+				StateDir:   stateDir,
+				Limit:      tt.limit,
+				Categories: tt.categories,
+			}
+			if _, _, err := Scrape(context.Background(), config); err != nil {
+				t.Fatalf("Scrape() unexpected error: %v", err)
+			}
+
+			for _, call := range client.GetCalls() {
+				if wowi.SourceIDFromURL(call) == "1" {
+					t.Errorf("Scrape() fetched %s of an addon outside the scope", call)
+				}
+			}
+			partialDir := filepath.Join(stateDir, state.PartialDir)
+			want := map[string]string{"25078": "Better Vendor Price"}
+			if labels := scrapedLabels(t, partialDir); !reflect.DeepEqual(labels, want) {
+				t.Errorf("partial catalogue labels = %v, want %v", labels, want)
+			}
+			if _, err := os.Stat(filepath.Join(stateDir, state.FullCatalogueFile)); !os.IsNotExist(err) {
+				t.Errorf("partial scrape wrote the full catalogue, stat error = %v", err)
+			}
+
+			metadata, err := gate.ReadRunMetadata(filepath.Join(partialDir, state.RunMetadataFile))
+			if err != nil {
+				t.Fatalf("failed to read run metadata: %v", err)
+			}
+			if want := (&gate.Scope{Limit: tt.limit, Categories: tt.categories}); !reflect.DeepEqual(metadata.Partial, want) {
+				t.Errorf("run metadata partial = %+v, want %+v", metadata.Partial, want)
+			}
+			if _, err := os.Stat(filepath.Join(stateDir, state.ChangesFile)); !os.IsNotExist(err) {
+				t.Errorf("partial scrape updated the changes feed, stat error = %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(stateDir, state.PassedCatalogueFile)); !os.IsNotExist(err) {
+				t.Errorf("partial scrape became the publish gate's baseline, stat error = %v", err)
+			}
+		})
+	}
+}
+
+func TestScrape_PartialThenFull(t *testing.T) {
+	client := httpClient.NewMockHTTPClient()
+	filelist := `[
+		{"id": 25078, "title": "Better Vendor Price", "lastUpdate": 1717200000000, "gameVersions": ["11.0.2"]},
+		{"id": 1, "title": "One", "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]}
+	]`
+	client.SetResponse(wowi.GetAPIFileList(wowi.APIVersionV4), &httpClient.Response{StatusCode: 200, Body: []byte(filelist)})
+	serveAddon25078(t, client)
+	client.SetResponse(wowi.GetAPIHost(wowi.APIVersionV4)+"/filedetails/1.json", &httpClient.Response{StatusCode: 200, Body: []byte(`[{"id": 1, "title": "One", "lastUpdate": 1704067200000}]`)})
+
+	stateDir := t.TempDir()
+	scrape := func(limit int) Report {
+		t.Helper()
+		config := Options{
+			HTTPClient: client,
+			Sources:    []Source{WowInterface},
+			MaxWorkers: 1,
+			StateDir:   stateDir,
+			Profile:    APIOnlyProfile,
+			Limit:      limit,
+		}
+		_, rep, err := Scrape(context.Background(), config)
+		if err != nil {
+			t.Fatalf("Scrape() unexpected error: %v", err)
+		}
+		return rep
+	}
+
+	scrape(0)
+	scrape(1)
+	if labels := scrapedLabels(t, stateDir); len(labels) != 2 {
+		t.Errorf("full catalogue labels after a partial scrape = %v, want both addons of the last full scrape", labels)
+	}
+	// Compared to the last full scrape, the addons outside the limit aren't new
+	if rep := scrape(0); rep.Added != 0 || rep.Removed != 0 {
+		t.Errorf("Scrape() after a partial scrape added %d and removed %d addons, want 0 and 0", rep.Added, rep.Removed)
+	}
+}
+
+func TestScrape_GateBaseline(t *testing.T) {
+	client := httpClient.NewMockHTTPClient()
+	filelist := `[{"id": 25078, "title": "Better Vendor Price", "lastUpdate": 1717200000000, "gameVersions": ["11.0.2"]}]`
+	client.SetResponse(wowi.GetAPIFileList(wowi.APIVersionV4), &httpClient.Response{StatusCode: 200, Body: []byte(filelist)})
+	serveAddon25078(t, client)
+	scrape := func(stateDir string) gate.RunMetadata {
+		t.Helper()
+		config := Options{
+			HTTPClient:  client,
+			Sources:     []Source{WowInterface},
+			MaxWorkers:  1,
+			StateDir:    stateDir,
+			MaxFailures: -1,
+		}
+		if _, _, err := Scrape(context.Background(), config); err != nil {
+			t.Fatalf("Scrape() unexpected error: %v", err)
+		}
+		metadata, err := gate.ReadRunMetadata(filepath.Join(stateDir, state.RunMetadataFile))
+		if err != nil {
+			t.Fatalf("failed to read run metadata: %v", err)
+		}
+		return metadata
+	}
+	passedTotal := func(stateDir string) int {
+		t.Helper()
+		passed, err := catalogue.ReadCatalogue(filepath.Join(stateDir, state.PassedCatalogueFile))
+		if err != nil {
+			t.Fatalf("failed to read the passed catalogue: %v", err)
+		}
+		return passed.Total
+	}
+
+	// A passing scrape becomes the baseline
+	stateDir := t.TempDir()
+	if metadata := scrape(stateDir); !metadata.Gate.Passed {
+		t.Fatalf("Scrape() gate failed: %v", metadata.Gate.Failures())
+	}
+	if got := passedTotal(stateDir); got != 1 {
+		t.Errorf("passed catalogue total = %d, want 1", got)
+	}
+
+	// Shrinking from the last catalogue that passed fails the gate, again and again rather than only the first time
+	stateDir = t.TempDir()
+	var addons []types.Addon
+	for i := range 20 {
+		id := strconv.Itoa(i + 1)
+		addons = append(addons, types.Addon{
+			Source:        types.WowInterfaceSource,
+			SourceID:      id,
+			Name:          "addon-" + id,
+			Label:         "Addon " + id,
+			URL:           "https://www.wowinterface.com/downloads/info" + id,
+			UpdatedDate:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			GameTrackList: []types.GameTrack{types.RetailTrack},
+			TagList:       []string{},
+		})
+	}
+	baseline := types.Catalogue{Datestamp: "2024-01-02", Total: len(addons), AddonSummaryList: addons}
+	baseline.Spec.Version = 2
+	if err := state.WriteCatalogue(baseline, filepath.Join(stateDir, state.PassedCatalogueFile), false); err != nil {
+		t.Fatalf("WriteCatalogue() unexpected error: %v", err)
+	}
+	for run := range 2 {
+		if metadata := scrape(stateDir); metadata.Gate.Passed {
+			t.Errorf("Scrape() %d gate passed, want a failure against the last catalogue that passed", run+1)
+		}
+	}
+	if got := passedTotal(stateDir); got != len(addons) {
+		t.Errorf("passed catalogue total = %d after failed scrapes, want %d", got, len(addons))
+	}
+}
+
+func TestScrape_MinRefreshAge(t *testing.T) {
+	stateDir := t.TempDir()
+	writeLastScrape(t, stateDir)
+
+	// Neither addon has been updated since the last scrape, only 1 was fetched recently
+	now := time.Now().UTC()
+	refreshed := refresh.NewTimes()
+	refreshed.Set("1", now.Add(-time.Hour))
+	refreshed.Set("25078", now.Add(-30*24*time.Hour))
+	if err := refreshed.Write(filepath.Join(stateDir, state.RefreshedFile)); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+
+	client := httpClient.NewMockHTTPClient()
+	filelist := `[
+		{"id": 1, "title": "One", "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]},
+		{"id": 25078, "title": "Better Vendor Price", "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]}
+	]`
+	client.SetResponse(wowi.GetAPIFileList(wowi.APIVersionV4), &httpClient.Response{StatusCode: 200, Body: []byte(filelist)})
+	serveAddon25078(t, client)
+
+	config := Options{
+		HTTPClient:    client,
+		Sources:       []Source{WowInterface},
+		MaxWorkers:    1,
+		StateDir:      stateDir,
+		MaxFailures:   0,
+		MinRefreshAge: 7 * 24 * time.Hour,
+	}
+	if _, _, err := Scrape(context.Background(), config); err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+
+	for _, call := range client.GetCalls() {
+		if wowi.SourceIDFromURL(call) == "1" {
+			t.Errorf("Scrape() fetched %s of an addon refreshed within --min-refresh-age", call)
+		}
+	}
+	want := map[string]string{"1": "One", "25078": "Better Vendor Price"}
+	if labels := scrapedLabels(t, stateDir); !reflect.DeepEqual(labels, want) {
+		t.Errorf("full catalogue labels = %v, want %v", labels, want)
+	}
+
+	// The refreshed addon is fresh until the next week
+	refreshed, err := refresh.Read(filepath.Join(stateDir, state.RefreshedFile))
+	if err != nil {
+		t.Fatalf("Read() unexpected error: %v", err)
+	}
+	if !refreshed.Fresh("25078", time.Hour, time.Now()) || refreshed.Fresh("1", time.Minute, time.Now()) {
+		t.Errorf("refresh times = %+v, want 25078 fetched just now and 1 an hour ago", refreshed)
+	}
+}
+
+func TestReadOnlyIDsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), state.FailedURLsFile)
+	failures := []report.Failure{
+		{URL: "https://www.wowinterface.com/downloads/info8149", Error: "status 404"},
+		{URL: wowi.GetAPIHost(wowi.APIVersionV4) + "/filedetails/8149.json", Error: "status 404"},
+		{URL: wowi.GetAPIHost(wowi.APIVersionV4) + "/filedetails/23145.json", Error: "bad json"},
+		{URL: wowi.GetAPIFileList(wowi.APIVersionV4), Error: "status 503"},
+	}
+	if err := report.WriteFailures(failures, path); err != nil {
+		t.Fatalf("WriteFailures() unexpected error: %v", err)
+	}
+
+	ids, err := readOnlyIDsFile(path)
+	if err != nil {
+		t.Fatalf("readOnlyIDsFile() unexpected error: %v", err)
+	}
+	if want := []string{"8149", "23145"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("readOnlyIDsFile() = %v, want %v", ids, want)
+	}
+
+	if err := report.WriteFailures(failures[3:], path); err != nil {
+		t.Fatalf("WriteFailures() unexpected error: %v", err)
+	}
+	if _, err := readOnlyIDsFile(path); err == nil {
+		t.Error("readOnlyIDsFile() expected an error without any addons to re-scrape")
+	}
+}
+
+func TestScrape_DeadLetters(t *testing.T) {
+	stateDir := t.TempDir()
+	client := httpClient.NewMockHTTPClient()
+	filelist := `[{"id": 25078, "title": "Addon", "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]}]`
+	client.SetResponse(wowi.GetAPIFileList(wowi.APIVersionV4), &httpClient.Response{StatusCode: 200, Body: []byte(filelist)})
+	serveAddon25078(t, client)
+	goneURL := wowi.Host + "/downloads/info25078"
+	client.SetResponse(goneURL, &httpClient.Response{StatusCode: 404})
+
+	config := Options{
+		HTTPClient:         client,
+		Sources:            []Source{WowInterface},
+		MaxWorkers:         1,
+		StateDir:           stateDir,
+		MaxFailures:        -1,
+		DeadLetterCooldown: time.Hour,
+	}
+	fetched := func() int {
+		count := 0
+		for _, call := range client.GetCalls() {
+			if call == goneURL {
+				count++
+			}
+		}
+		return count
+	}
+
+	// A server error isn't held against the page
+	client.SetResponse(goneURL, &httpClient.Response{StatusCode: 503})
+	if _, _, err := Scrape(context.Background(), config); err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+	deadLetters, err := deadletter.Read(filepath.Join(stateDir, state.DeadLettersFile), time.Hour)
+	if err != nil {
+		t.Fatalf("deadletter.Read() unexpected error: %v", err)
+	}
+	if entries := deadLetters.Entries(); len(entries) != 0 {
+		t.Errorf("dead letters after a 503 = %+v, want none", entries)
+	}
+
+	// Missing in enough scrapes, the page is dead-lettered and the next scrape skips it
+	retried := fetched()
+	client.SetResponse(goneURL, &httpClient.Response{StatusCode: 404})
+	for range deadletter.MinFailures + 1 {
+		if _, _, err := Scrape(context.Background(), config); err != nil {
+			t.Fatalf("Scrape() unexpected error: %v", err)
+		}
+	}
+	if got, want := fetched()-retried, deadletter.MinFailures; got != want {
+		t.Errorf("dead-lettered page fetched %d times, want %d", got, want)
+	}
+	scrapeReport, err := report.Read(filepath.Join(stateDir, state.ScrapeReportFile))
+	if err != nil {
+		t.Fatalf("report.Read() unexpected error: %v", err)
+	}
+	if scrapeReport.DeadLetterSkips != 1 {
+		t.Errorf("DeadLetterSkips = %d, want 1", scrapeReport.DeadLetterSkips)
+	}
+
+	// Asking for the addon by id tries it again
+	config.OnlyIDs = []string{"25078"}
+	if _, _, err := Scrape(context.Background(), config); err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+	if got, want := fetched()-retried, deadletter.MinFailures+1; got != want {
+		t.Errorf("dead-lettered page fetched %d times with --only-ids, want %d", got, want)
+	}
+}
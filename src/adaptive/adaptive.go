@@ -0,0 +1,163 @@
+// Package adaptive limits how many requests are made at once to a source, following how well the source copes.
+//
+// The limit is halved when too many responses in a window are throttled (429), fail (5xx) or time out
+// and grows by one after a window without any, so a source that starts throttling midway through a scrape is
+// backed off from instead of failing every request made to it.
+package adaptive
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+)
+
+// Config bounds the limit and sets how quickly it adapts
+type Config struct {
+	Min          int     // the limit is never lowered below this
+	Max          int     // the limit is never raised above this
+	Initial      int     // the limit to start with
+	Window       int     // responses looked at before the limit is changed
+	MaxErrorRate float64 // fraction of the window's responses that may be throttled or fail before the limit is halved
+}
+
+// DefaultConfig adapts between 1 and max, starting at 5, the default number of workers
+func DefaultConfig(max int) Config {
+	return Config{
+		Min:          1,
+		Max:          max,
+		Initial:      min(5, max),
+		Window:       20,
+		MaxErrorRate: 0.1,
+	}
+}
+
+// Fixed never changes from n
+func Fixed(n int) Config {
+	return Config{Min: n, Max: n, Initial: n, Window: 1}
+}
+
+// Limiter bounds how many callers hold a turn at once.
+// Safe for concurrent use.
+type Limiter struct {
+	config Config
+
+	mu      sync.Mutex
+	limit   int
+	inUse   int
+	total   int           // responses seen in the current window
+	errors  int           // responses in the current window that were throttled or failed
+	changed chan struct{} // closed when a turn may have become free
+}
+
+// NewLimiter returns a limiter starting at config.Initial
+func NewLimiter(config Config) *Limiter {
+	config.Min = max(config.Min, 1)
+	config.Max = max(config.Max, config.Min)
+	config.Window = max(config.Window, 1)
+	return &Limiter{
+		config:  config,
+		limit:   min(max(config.Initial, config.Min), config.Max),
+		changed: make(chan struct{}),
+	}
+}
+
+// Limit returns how many callers may hold a turn at once
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// Acquire blocks until the caller may take a turn or ctx is done. Every turn taken must be given back with Release.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inUse < l.limit {
+			l.inUse++
+			l.mu.Unlock()
+			return nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release gives back a turn taken with Acquire
+func (l *Limiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inUse--
+	l.notify()
+}
+
+// Observe records the outcome of a request, changing the limit at the end of each window.
+// Errors other than timeouts, such as cancellation or a cache miss when offline, say nothing about the source and are ignored.
+func (l *Limiter) Observe(statusCode int, err error) {
+	if err != nil && !isTimeout(err) {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.total++
+	if err != nil || statusCode == 429 || statusCode >= 500 {
+		l.errors++
+	}
+	if l.total < l.config.Window {
+		return
+	}
+
+	rate := float64(l.errors) / float64(l.total)
+	previous := l.limit
+	switch {
+	case rate > l.config.MaxErrorRate:
+		l.limit = max(l.limit/2, l.config.Min)
+	case l.errors == 0:
+		l.limit = min(l.limit+1, l.config.Max)
+	}
+	l.total, l.errors = 0, 0
+
+	if l.limit < previous {
+		slog.Warn("lowering workers, too many requests throttled or failed", "workers", l.limit, "error-rate", rate)
+	} else if l.limit > previous {
+		slog.Debug("raising workers", "workers", l.limit)
+		l.notify()
+	}
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// notify wakes the callers waiting in Acquire. Called with mu held.
+func (l *Limiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// Middleware observes the outcome of each request made through a client.
+// It should sit below retries, so each attempt is seen rather than just the last.
+func (l *Limiter) Middleware() http.Middleware {
+	return func(next http.HTTPClient) http.HTTPClient {
+		return http.ClientFunc(func(ctx context.Context, url string) (*http.Response, error) {
+			resp, err := next.Get(ctx, url)
+			statusCode := 0
+			if resp != nil {
+				statusCode = resp.StatusCode
+			}
+			l.Observe(statusCode, err)
+			return resp, err
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package adaptive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+)
+
+// observe records count responses with statusCode
+func observe(l *Limiter, count, statusCode int) {
+	for i := 0; i < count; i++ {
+		l.Observe(statusCode, nil)
+	}
+}
+
+func TestLimiter_Adapts(t *testing.T) {
+	l := NewLimiter(DefaultConfig(8))
+	if l.Limit() != 5 {
+		t.Fatalf("Limit() = %d, want 5 to start with", l.Limit())
+	}
+
+	steps := []struct {
+		name      string
+		healthy   int
+		throttled int
+		want      int
+	}{
+		{"healthy window raises", 20, 0, 6},
+		{"up to the max", 60, 0, 8},
+		{"few errors keep it", 18, 2, 8},
+		{"throttling halves", 15, 5, 4},
+		{"server errors halve", 10, 10, 2},
+		{"down to the min", 0, 40, 1},
+		{"recovers", 40, 0, 3},
+	}
+	for _, step := range steps {
+		observe(l, step.healthy, 200)
+		statusCode := 429
+		if step.name == "server errors halve" {
+			statusCode = 503
+		}
+		observe(l, step.throttled, statusCode)
+		if l.Limit() != step.want {
+			t.Errorf("%s: Limit() = %d, want %d", step.name, l.Limit(), step.want)
+		}
+	}
+}
+
+func TestLimiter_Observe_Errors(t *testing.T) {
+	l := NewLimiter(Config{Min: 1, Max: 10, Initial: 4, Window: 2, MaxErrorRate: 0.1})
+	l.Observe(0, context.Canceled)
+	l.Observe(0, errors.New("not cached"))
+	if l.Limit() != 4 {
+		t.Errorf("Limit() = %d after errors that say nothing about the source, want 4", l.Limit())
+	}
+	l.Observe(0, fmt.Errorf("failed to fetch: %w", context.DeadlineExceeded))
+	l.Observe(200, nil)
+	if l.Limit() != 2 {
+		t.Errorf("Limit() = %d after a timeout, want 2", l.Limit())
+	}
+}
+
+func TestLimiter_Fixed(t *testing.T) {
+	l := NewLimiter(Fixed(3))
+	observe(l, 10, 429)
+	observe(l, 10, 200)
+	if l.Limit() != 3 {
+		t.Errorf("Limit() = %d, want 3", l.Limit())
+	}
+}
+
+func TestLimiter_Acquire(t *testing.T) {
+	l := NewLimiter(Config{Min: 1, Max: 2, Initial: 1, Window: 1})
+	ctx := context.Background()
+	if err := l.Acquire(ctx); err != nil {
+		t.Fatalf("Acquire() unexpected error: %v", err)
+	}
+
+	// Over the limit until it's raised
+	acquired := make(chan error)
+	go func() { acquired <- l.Acquire(ctx) }()
+	select {
+	case <-acquired:
+		t.Fatal("Acquire() took a turn over the limit")
+	case <-time.After(20 * time.Millisecond):
+	}
+	l.Observe(200, nil)
+	if err := <-acquired; err != nil {
+		t.Fatalf("Acquire() unexpected error: %v", err)
+	}
+
+	// Waits for a turn to be released
+	go func() { acquired <- l.Acquire(ctx) }()
+	l.Release()
+	if err := <-acquired; err != nil {
+		t.Fatalf("Acquire() unexpected error: %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.Acquire(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Acquire() of a cancelled context = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestLimiter_Middleware(t *testing.T) {
+	mock := http.NewMockHTTPClient()
+	mock.SetResponse("https://example.org/throttled", &http.Response{StatusCode: 429})
+	l := NewLimiter(Config{Min: 1, Max: 10, Initial: 4, Window: 1, MaxErrorRate: 0.1})
+	client := http.Chain(mock, l.Middleware())
+
+	resp, err := client.Get(context.Background(), "https://example.org/throttled")
+	if err != nil || resp.StatusCode != 429 {
+		t.Fatalf("Get() = %v, %v, want the response passed through", resp, err)
+	}
+	if l.Limit() != 2 {
+		t.Errorf("Limit() = %d, want 2", l.Limit())
+	}
+}
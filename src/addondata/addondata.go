@@ -0,0 +1,147 @@
+// Package addondata keeps the data each page or API response gave about an addon, the AddonData merged into its
+// catalogue entry, so a wrong entry can be traced back to the file it came from.
+package addondata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// Dir is the directory in the state directory addon data is written to, as Dir/SOURCE/SOURCE-ID/FILENAME
+const Dir = "addon-data"
+
+// ProvenanceFile is written beside the data of an addon, recording the files each field of the merged addon came from
+const ProvenanceFile = "provenance.json"
+
+// addonDir returns the directory holding the data of an addon. Source-ids such as GitHub's OWNER/NAME are escaped.
+func addonDir(dir string, source types.Source, sourceID string) string {
+	return filepath.Join(dir, string(source), url.PathEscape(sourceID))
+}
+
+// Write replaces the data kept about an addon with dataList, a file per AddonData named after its Filename(),
+// and the provenance of its merged fields, if any
+func Write(dir string, source types.Source, sourceID string, dataList []types.AddonData, provenance map[string][]string) error {
+	addonDir := addonDir(dir, source, sourceID)
+	if err := os.RemoveAll(addonDir); err != nil {
+		return fmt.Errorf("failed to remove addon data %s: %w", addonDir, err)
+	}
+	if err := os.MkdirAll(addonDir, 0755); err != nil {
+		return fmt.Errorf("failed to create addon data directory: %w", err)
+	}
+
+	for _, data := range dataList {
+		encoded, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal addon data %s: %w", data.Filename(), err)
+		}
+		path := filepath.Join(addonDir, filepath.Base(data.Filename()))
+		if err := os.WriteFile(path, encoded, 0644); err != nil {
+			return fmt.Errorf("failed to write addon data %s: %w", path, err)
+		}
+	}
+
+	if provenance == nil {
+		return nil
+	}
+	encoded, err := json.MarshalIndent(provenance, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal addon provenance: %w", err)
+	}
+	path := filepath.Join(addonDir, ProvenanceFile)
+	if err := os.WriteFile(path, encoded, 0644); err != nil {
+		return fmt.Errorf("failed to write addon provenance %s: %w", path, err)
+	}
+	return nil
+}
+
+// Path returns the file the data of an addon named filename is written to
+func Path(dir string, source types.Source, sourceID string, filename string) string {
+	return filepath.Join(addonDir(dir, source, sourceID), filepath.Base(filename))
+}
+
+// Read reads the data kept about an addon, ordered by filename. An addon without data has none.
+func Read(dir string, source types.Source, sourceID string) ([]types.AddonData, error) {
+	addonDir := addonDir(dir, source, sourceID)
+	files, err := os.ReadDir(addonDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read addon data directory %s: %w", addonDir, err)
+	}
+
+	var dataList []types.AddonData
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" || file.Name() == ProvenanceFile {
+			continue
+		}
+		path := filepath.Join(addonDir, file.Name())
+		encoded, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read addon data %s: %w", path, err)
+		}
+		var data types.AddonData
+		if err := json.Unmarshal(encoded, &data); err != nil {
+			return nil, fmt.Errorf("failed to parse addon data %s: %w", path, err)
+		}
+		dataList = append(dataList, data)
+	}
+	sort.Slice(dataList, func(i, j int) bool {
+		return dataList[i].Filename() < dataList[j].Filename()
+	})
+	return dataList, nil
+}
+
+// ReadProvenance reads the provenance of the fields of an addon written by Write, nil if there is none
+func ReadProvenance(dir string, source types.Source, sourceID string) (map[string][]string, error) {
+	path := filepath.Join(addonDir(dir, source, sourceID), ProvenanceFile)
+	encoded, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read addon provenance %s: %w", path, err)
+	}
+	var provenance map[string][]string
+	if err := json.Unmarshal(encoded, &provenance); err != nil {
+		return nil, fmt.Errorf("failed to parse addon provenance %s: %w", path, err)
+	}
+	return provenance, nil
+}
+
+// Retain removes the data kept about addons of source but those with the given source-ids, e.g. those no longer
+// in the catalogue, returning how many addons were removed
+func Retain(dir string, source types.Source, sourceIDs []string) (int, error) {
+	keep := make(map[string]bool, len(sourceIDs))
+	for _, sourceID := range sourceIDs {
+		keep[url.PathEscape(sourceID)] = true
+	}
+
+	sourceDir := filepath.Join(dir, string(source))
+	entries, err := os.ReadDir(sourceDir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read addon data directory %s: %w", sourceDir, err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if !entry.IsDir() || keep[entry.Name()] {
+			continue
+		}
+		path := filepath.Join(sourceDir, entry.Name())
+		if err := os.RemoveAll(path); err != nil {
+			return removed, fmt.Errorf("failed to remove addon data %s: %w", path, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,89 @@
+package addondata
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestWriteRead(t *testing.T) {
+	dir := t.TempDir()
+
+	// No data
+	dataList, err := Read(dir, types.WowInterfaceSource, "4815")
+	if err != nil || dataList != nil {
+		t.Fatalf("Read() of an unknown addon = %v, %v, want nothing", dataList, err)
+	}
+
+	downloads := 900000
+	written := []types.AddonData{
+		{Source: types.WowInterfaceSource, SourceID: "4815", Kind: types.WebDetailData, Description: "Single window inventory"},
+		{Source: types.WowInterfaceSource, SourceID: "4815", Kind: types.APIDetailData, APIVersion: "v4", DownloadCount: &downloads},
+	}
+	provenance := map[string][]string{"description": {"web-detail.json"}, "download-count": {"api-detail-v4.json"}}
+	if err := Write(dir, types.WowInterfaceSource, "4815", written, provenance); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+	dataList, err = Read(dir, types.WowInterfaceSource, "4815")
+	if err != nil {
+		t.Fatalf("Read() unexpected error: %v", err)
+	}
+	if want := []types.AddonData{written[1], written[0]}; !reflect.DeepEqual(dataList, want) {
+		t.Errorf("Read() = %+v, want %+v", dataList, want)
+	}
+	if got, err := ReadProvenance(dir, types.WowInterfaceSource, "4815"); err != nil || !reflect.DeepEqual(got, provenance) {
+		t.Errorf("ReadProvenance() = %v, %v, want %v", got, err, provenance)
+	}
+
+	// Writing again replaces the addon's files
+	if err := Write(dir, types.WowInterfaceSource, "4815", written[:1], nil); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+	if dataList, _ := Read(dir, types.WowInterfaceSource, "4815"); !reflect.DeepEqual(dataList, written[:1]) {
+		t.Errorf("Read() after a rewrite = %+v, want %+v", dataList, written[:1])
+	}
+	if got, err := ReadProvenance(dir, types.WowInterfaceSource, "4815"); err != nil || got != nil {
+		t.Errorf("ReadProvenance() after a rewrite without provenance = %v, %v, want none", got, err)
+	}
+}
+
+func TestWrite_EscapesSourceID(t *testing.T) {
+	dir := t.TempDir()
+	written := []types.AddonData{{Source: types.GitHubSource, SourceID: "owner/name", Kind: types.WebDetailData}}
+	if err := Write(dir, types.GitHubSource, "owner/name", written, nil); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(Path(dir, types.GitHubSource, "owner/name", "web-detail.json")); err != nil {
+		t.Errorf("Write() didn't write to Path(): %v", err)
+	}
+	if dataList, _ := Read(dir, types.GitHubSource, "owner/name"); !reflect.DeepEqual(dataList, written) {
+		t.Errorf("Read() = %+v, want %+v", dataList, written)
+	}
+}
+
+func TestRetain(t *testing.T) {
+	dir := t.TempDir()
+	for _, sourceID := range []string{"1", "2", "3"} {
+		data := []types.AddonData{{Source: types.WowInterfaceSource, SourceID: sourceID, Kind: types.ListingData}}
+		if err := Write(dir, types.WowInterfaceSource, sourceID, data, nil); err != nil {
+			t.Fatalf("Write() unexpected error: %v", err)
+		}
+	}
+
+	removed, err := Retain(dir, types.WowInterfaceSource, []string{"1", "3"})
+	if err != nil || removed != 1 {
+		t.Fatalf("Retain() = %d, %v, want 1 removed", removed, err)
+	}
+	for sourceID, want := range map[string]bool{"1": true, "2": false, "3": true} {
+		if dataList, _ := Read(dir, types.WowInterfaceSource, sourceID); (dataList != nil) != want {
+			t.Errorf("addon %s kept = %v, want %v", sourceID, dataList != nil, want)
+		}
+	}
+
+	// Other sources are left alone
+	if removed, err := Retain(dir, types.GitHubSource, nil); err != nil || removed != 0 {
+		t.Errorf("Retain() of a source without data = %d, %v, want none removed", removed, err)
+	}
+}
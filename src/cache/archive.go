@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Export writes every entry in store, their metadata and the index of the cache directory to w as a zstd compressed
+// tar archive, for Import to restore in another cache. Each entry is a file named after its key, dated when it was
+// cached. Returns the number of entries written, not counting their metadata.
+func Export(w io.Writer, dir string, store Store) (int, error) {
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write cache archive: %w", err)
+	}
+	defer zw.Close()
+	archive := tar.NewWriter(zw)
+
+	entries := 0
+	err = store.Entries(func(key string, info EntryInfo) error {
+		data, err := store.Read(key)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil // removed since it was listed
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read cache entry %s: %w", key, err)
+		}
+		if err := writeArchiveFile(archive, key, data, info.CachedAt); err != nil {
+			return err
+		}
+		if !IsMetaKey(key) {
+			entries++
+		}
+		return nil
+	})
+	if err != nil {
+		return entries, err
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if err == nil {
+		err = writeArchiveFile(archive, IndexFile, index, time.Now())
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
+	if err != nil {
+		return entries, fmt.Errorf("failed to export cache index: %w", err)
+	}
+
+	if err := archive.Close(); err != nil {
+		return entries, fmt.Errorf("failed to write cache archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return entries, fmt.Errorf("failed to write cache archive: %w", err)
+	}
+	return entries, nil
+}
+
+// writeArchiveFile writes a file named name to archive
+func writeArchiveFile(archive *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{Typeflag: tar.TypeReg, Name: name, Size: int64(len(data)), Mode: 0644, ModTime: modTime}
+	if err := archive.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s to cache archive: %w", name, err)
+	}
+	if _, err := archive.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s to cache archive: %w", name, err)
+	}
+	return nil
+}
+
+// Import restores the entries in an archive written by Export to store, keeping when they were cached, and adds the
+// archive's index entries to the index of the cache directory. Entries the store already has, cached more recently
+// than the archive's, are kept. Returns the number of entries restored, not counting their metadata.
+func Import(r io.Reader, dir string, store Store) (int, error) {
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read cache archive: %w", err)
+	}
+	defer zr.Close()
+	archive := tar.NewReader(zr)
+
+	entries := 0
+	var imported *Index
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return entries, fmt.Errorf("failed to read cache archive: %w", err)
+		}
+		name := header.Name
+		if header.Typeflag != tar.TypeReg || filepath.Base(name) != name || strings.HasPrefix(name, ".") || name == SQLiteFile {
+			return entries, fmt.Errorf("unexpected file %q in cache archive", name)
+		}
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			return entries, fmt.Errorf("failed to read %s from cache archive: %w", name, err)
+		}
+
+		if name == IndexFile {
+			imported = &Index{}
+			if err := json.Unmarshal(data, imported); err != nil {
+				return entries, fmt.Errorf("failed to parse cache index in cache archive: %w", err)
+			}
+			continue
+		}
+
+		if stat, err := store.Stat(name); err == nil && stat.CachedAt.After(header.ModTime) {
+			continue
+		}
+		if err := store.Restore(name, data, header.ModTime); err != nil {
+			return entries, err
+		}
+		if !IsMetaKey(name) {
+			entries++
+		}
+	}
+
+	if imported != nil {
+		index, err := ReadIndex(dir)
+		if err != nil {
+			return entries, err
+		}
+		for key, entry := range imported.Entries {
+			if current, ok := index.Entries[key]; !ok || !current.CachedAt.After(entry.CachedAt) {
+				index.Entries[key] = entry
+			}
+		}
+		if err := WriteIndex(dir, index); err != nil {
+			return entries, err
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExportImport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("page " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	transport := NewFileCachingTransport(CacheConfig{Directory: dir, DefaultTTLHours: 48}, http.DefaultTransport)
+	client := &http.Client{Transport: transport}
+	for _, path := range []string{"/a", "/b"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Get(%s) unexpected error: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+	if err := transport.SaveIndex(); err != nil {
+		t.Fatalf("SaveIndex() unexpected error: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/a", nil)
+	cacheKey := transport.makeCacheKey(req)
+	cachedAt := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	if err := transport.Store().Restore(cacheKey, mustRead(t, transport.Store(), cacheKey), cachedAt); err != nil {
+		t.Fatalf("Restore() unexpected error: %v", err)
+	}
+
+	var archive bytes.Buffer
+	if entries, err := Export(&archive, dir, transport.Store()); err != nil || entries != 2 {
+		t.Fatalf("Export() = %d, %v, want 2 entries", entries, err)
+	}
+	if zstdMagic := []byte{0x28, 0xb5, 0x2f, 0xfd}; !bytes.HasPrefix(archive.Bytes(), zstdMagic) {
+		t.Error("Expected cache archive to be zstd compressed")
+	}
+
+	// Restored into an SQLite cache, the entries keep their age and URLs
+	importDir := t.TempDir()
+	store, err := OpenStore(SQLiteBackend, importDir)
+	if err != nil {
+		t.Fatalf("OpenStore() unexpected error: %v", err)
+	}
+	defer store.Close()
+	if entries, err := Import(bytes.NewReader(archive.Bytes()), importDir, store); err != nil || entries != 2 {
+		t.Fatalf("Import() = %d, %v, want 2 entries", entries, err)
+	}
+	if stat, err := store.Stat(cacheKey); err != nil || !stat.CachedAt.Equal(cachedAt) {
+		t.Errorf("Stat() = %v, %v, want cached at %v", stat.CachedAt, err, cachedAt)
+	}
+	if entries, err := List(importDir, store, nil); err != nil || len(entries) != 2 || entries[0].URL != server.URL+"/a" {
+		t.Errorf("List() = %+v, %v, want /a and /b", entries, err)
+	}
+
+	imported := newCachingTransport(CacheConfig{Directory: importDir, Offline: true}, store, http.DefaultTransport)
+	resp, err := (&http.Client{Transport: imported}).Get(server.URL + "/b")
+	if err != nil {
+		t.Fatalf("Get(/b) unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "page /b" {
+		t.Errorf("Get(/b) body = %q, want %q", body, "page /b")
+	}
+
+	// Entries cached more recently than the archive's are kept
+	if err := store.Restore(cacheKey, []byte("newer"), time.Now()); err != nil {
+		t.Fatalf("Restore() unexpected error: %v", err)
+	}
+	if entries, err := Import(bytes.NewReader(archive.Bytes()), importDir, store); err != nil || entries != 1 {
+		t.Errorf("Import() again = %d, %v, want 1 entry", entries, err)
+	}
+	if data := mustRead(t, store, cacheKey); string(data) != "newer" {
+		t.Errorf("Read() = %q, want the newer entry kept", data)
+	}
+
+	if _, err := Import(bytes.NewReader([]byte("not an archive")), importDir, store); err == nil {
+		t.Error("Import(not an archive) expected an error")
+	}
+}
+
+func mustRead(t *testing.T, store Store, key string) []byte {
+	t.Helper()
+	data, err := store.Read(key)
+	if err != nil {
+		t.Fatalf("Read(%s) unexpected error: %v", key, err)
+	}
+	return data
+}
@@ -3,53 +3,185 @@ package cache
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httputil"
-	"os"
+	"net/url"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // CacheConfig holds cache configuration
 type CacheConfig struct {
 	Directory       string
+	Backend         Backend // where entries are stored, files if empty
 	DefaultTTLHours int
 	SearchTTLHours  int
+	TTLRules        []TTLRule // the first rule matching a URL overrides the default and search TTLs
+	DynamicTTL      bool      // scale TTLs by how long ago the content last changed, see SetUpdatedDate
+	Offline         bool      // never make requests: serve expired entries and fail with ErrNotCached for missing ones
+	RefreshPatterns []string  // entries for URLs matching these patterns (see MatchURL) cached before this run are expired
+	MemoryEntries   int       // most entries also held in memory, see DefaultMemoryEntries. 0 to hold none
+	MemoryBytes     int64     // most bytes of decompressed entries held in memory, see DefaultMemoryBytes. 0 to hold none
 }
 
-// FileCachingTransport implements http.RoundTripper with file-based caching
+// ErrNotCached is returned in offline mode for requests that aren't in the cache
+var ErrNotCached = errors.New("not in cache")
+
+// updateAgeTTL maps how long ago content last changed to how long it may be cached
+type updateAgeTTL struct {
+	MaxAge time.Duration
+	TTL    time.Duration
+}
+
+// updateAgeTTLs is ordered by MaxAge; content older than the last tier uses the last tier's TTL.
+// Addons that haven't changed in years don't need re-fetching every couple of days.
+var updateAgeTTLs = []updateAgeTTL{
+	{MaxAge: 7 * 24 * time.Hour, TTL: 0}, // recently updated: use the default TTL
+	{MaxAge: 90 * 24 * time.Hour, TTL: 7 * 24 * time.Hour},
+	{MaxAge: 365 * 24 * time.Hour, TTL: 14 * 24 * time.Hour},
+	{MaxAge: 0, TTL: 30 * 24 * time.Hour},
+}
+
+// FileCachingTransport implements http.RoundTripper with caching in a Store
 type FileCachingTransport struct {
 	config    CacheConfig
+	store     Store
+	memory    *memoryCache // recently used entries, nil if disabled
 	transport http.RoundTripper
 	runStart  time.Time
+
+	mu           sync.RWMutex
+	updatedDates map[string]time.Time  // cache key -> last known update of the content
+	indexed      map[string]IndexEntry // cache key -> entries written this run, see SaveIndex
+	hostStats    map[string]HostStats
+
+	hits   atomic.Int64
+	misses atomic.Int64
 }
 
-// NewFileCachingTransport creates a new caching transport
+// NewFileCachingTransport creates a new caching transport storing a file per entry in the cache directory
 func NewFileCachingTransport(config CacheConfig, transport http.RoundTripper) *FileCachingTransport {
+	return newCachingTransport(config, &fileStore{dir: config.Directory}, transport)
+}
+
+// NewCachingTransport creates a new caching transport storing entries in the configured backend.
+// Close it when done.
+func NewCachingTransport(config CacheConfig, transport http.RoundTripper) (*FileCachingTransport, error) {
+	store, err := OpenStore(config.Backend, config.Directory)
+	if err != nil {
+		return nil, err
+	}
+	return newCachingTransport(config, store, transport), nil
+}
+
+func newCachingTransport(config CacheConfig, store Store, transport http.RoundTripper) *FileCachingTransport {
 	return &FileCachingTransport{
-		config:    config,
-		transport: transport,
-		runStart:  time.Now(),
+		config:       config,
+		store:        store,
+		memory:       newMemoryCache(config.MemoryEntries, config.MemoryBytes),
+		transport:    transport,
+		runStart:     time.Now(),
+		updatedDates: make(map[string]time.Time),
+		indexed:      make(map[string]IndexEntry),
+		hostStats:    make(map[string]HostStats),
+	}
+}
+
+// Store returns where the transport's entries are stored
+func (t *FileCachingTransport) Store() Store {
+	return t.store
+}
+
+// Close closes the transport's store
+func (t *FileCachingTransport) Close() error {
+	return t.store.Close()
+}
+
+// SetUpdatedDate records when the content behind a URL was last known to change.
+// With DynamicTTL enabled, entries for content that changed long ago are kept longer
+// and entries cached before the content changed are always treated as expired.
+func (t *FileCachingTransport) SetUpdatedDate(rawURL string, updated time.Time) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return
 	}
+	cacheKey := t.makeCacheKey(req)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.updatedDates[cacheKey] = updated
+}
+
+// StartRun starts a new run, such as each scheduled scrape of the daemon: entries age, and RefreshPatterns expire
+// them, from now, and the update dates, hits and misses and index entries of the last run are forgotten. Call
+// SaveIndex first to keep them.
+func (t *FileCachingTransport) StartRun() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.runStart = time.Now()
+	t.updatedDates = make(map[string]time.Time)
+	t.indexed = make(map[string]IndexEntry)
+	t.hostStats = make(map[string]HostStats)
+	t.hits.Store(0)
+	t.misses.Store(0)
+}
+
+// CacheStats returns the number of requests served from the cache and the number fetched since the run started
+func (t *FileCachingTransport) CacheStats() (hits, misses int64) {
+	return t.hits.Load(), t.misses.Load()
+}
+
+// Fresh returns true if a GET of rawURL would be served from the cache rather than fetched
+func (t *FileCachingTransport) Fresh(rawURL string) bool {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return false
+	}
+	cacheKey := t.makeCacheKey(req)
+	if t.config.Offline {
+		_, err := t.cachedAt(cacheKey)
+		return err == nil
+	}
+	return !t.cacheExpired(req, cacheKey)
 }
 
 // RoundTrip implements http.RoundTripper with caching
 func (t *FileCachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	cacheKey := t.makeCacheKey(req)
-	cachePath := t.cachePath(cacheKey)
+
+	// Offline the cache is all there is, however old
+	if t.config.Offline {
+		cachedResp, err := t.readCacheEntry(cacheKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrNotCached, req.URL.String())
+		}
+		slog.Info("cache hit", "url", req.URL.String(), "offline", true)
+		t.hits.Add(1)
+		t.countRequest(req.URL.Host, true)
+		return cachedResp, nil
+	}
 
 	// Try to read from cache first
-	if cachedResp, err := t.readCacheEntry(cacheKey); err == nil && !t.cacheExpired(cachePath) {
+	if cachedResp, err := t.readCacheEntry(cacheKey); err == nil && !t.cacheExpired(req, cacheKey) {
 		slog.Info("cache hit", "url", req.URL.String())
+		t.hits.Add(1)
+		t.countRequest(req.URL.Host, true)
 		return cachedResp, nil
 	}
 
 	// Not in cache or expired, make real request
 	slog.Info("fetching", "url", req.URL.String())
+	t.misses.Add(1)
+	t.countRequest(req.URL.Host, false)
 	resp, err := t.transport.RoundTrip(req)
 	if err != nil {
 		return resp, err
@@ -57,7 +189,13 @@ func (t *FileCachingTransport) RoundTrip(req *http.Request) (*http.Response, err
 
 	// Cache successful responses
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		t.writeCacheEntry(cacheKey, resp)
+		if size, err := t.writeCacheEntry(cacheKey, resp); err == nil {
+			t.indexEntry(cacheKey, req.URL)
+			meta := Meta{URL: req.URL.String(), Category: CategoryOf(req.URL), StatusCode: resp.StatusCode, Size: size, FetchedAt: time.Now().UTC()}
+			if err := writeMeta(t.store, cacheKey, meta); err != nil {
+				slog.Warn("failed to write cache entry metadata", "url", req.URL.String(), "error", err)
+			}
+		}
 	}
 
 	// Return a fresh response from cache to avoid body consumption issues
@@ -68,77 +206,195 @@ func (t *FileCachingTransport) RoundTrip(req *http.Request) (*http.Response, err
 	return resp, nil
 }
 
-// makeCacheKey creates a cache key from the request
+// Category is the kind of response a URL is for, it decides the suffix of its cache key and whether it's cached
+// for the search TTL
+type Category string
+
+const (
+	PageCategory     Category = "page"
+	SearchCategory   Category = "search"
+	ZipCategory      Category = "zip"
+	FileListCategory Category = "filelist"
+)
+
+// CategoryOf returns the category of a URL
+func CategoryOf(u *url.URL) Category {
+	switch {
+	case u.Path == "/search":
+		return SearchCategory
+	case filepath.Ext(u.Path) == ".zip":
+		return ZipCategory
+	case filepath.Base(u.Path) == "filelist.json":
+		return FileListCategory
+	default:
+		return PageCategory
+	}
+}
+
+// makeCacheKey creates a cache key from the request: a hash of its URL suffixed with its category, pages aren't
 func (t *FileCachingTransport) makeCacheKey(req *http.Request) string {
-	key := req.URL.String()
-	md5sum := md5.Sum([]byte(key))
+	md5sum := md5.Sum([]byte(req.URL.String()))
 	cacheKey := hex.EncodeToString(md5sum[:])
+	if category := CategoryOf(req.URL); category != PageCategory {
+		return cacheKey + "-" + string(category)
+	}
+	return cacheKey
+}
 
-	// Add suffix based on URL type
-	if req.URL.Path == "/search" {
-		return cacheKey + "-search"
+// baseTTL returns how long a response may be cached before any dynamic scaling:
+// the TTL of the first rule matching its URL, otherwise the search or default TTL by the URL's category
+func (t *FileCachingTransport) baseTTL(req *http.Request) time.Duration {
+	for _, rule := range t.config.TTLRules {
+		if rule.Matches(req.URL) {
+			return rule.TTL
+		}
 	}
-	if filepath.Ext(req.URL.Path) == ".zip" {
-		return cacheKey + "-zip"
+	if CategoryOf(req.URL) == SearchCategory {
+		return time.Duration(t.config.SearchTTLHours) * time.Hour
 	}
-	if filepath.Base(req.URL.Path) == "filelist.json" {
-		return cacheKey + "-filelist"
+	return time.Duration(t.config.DefaultTTLHours) * time.Hour
+}
+
+// cacheExpired checks if a cache entry has expired
+func (t *FileCachingTransport) cacheExpired(req *http.Request, cacheKey string) bool {
+	cachedAt, err := t.cachedAt(cacheKey)
+	if err != nil {
+		return true // Entry doesn't exist or can't be read
 	}
 
-	return cacheKey
-}
+	t.mu.RLock()
+	runStart := t.runStart
+	t.mu.RUnlock()
+
+	// Forced refresh, but only once: entries written this run are fresh
+	if cachedAt.Before(runStart) {
+		for _, pattern := range t.config.RefreshPatterns {
+			if MatchURL(pattern, req.URL) {
+				return true
+			}
+		}
+	}
+
+	ttl := t.baseTTL(req)
+
+	if t.config.DynamicTTL {
+		t.mu.RLock()
+		updated, known := t.updatedDates[cacheKey]
+		t.mu.RUnlock()
+
+		if known {
+			// Content changed after it was cached
+			if cachedAt.Before(updated) {
+				return true
+			}
+			ttl = dynamicTTL(ttl, updated, runStart)
+		}
+	}
 
-// cachePath returns the file path for a cache key
-func (t *FileCachingTransport) cachePath(cacheKey string) string {
-	return filepath.Join(t.config.Directory, cacheKey)
+	age := runStart.Sub(cachedAt)
+	return age >= ttl
 }
 
-// cacheExpired checks if a cache file has expired
-func (t *FileCachingTransport) cacheExpired(path string) bool {
-	stat, err := os.Stat(path)
+// cachedAt returns when an entry was cached, from memory if it's held there rather than the store
+func (t *FileCachingTransport) cachedAt(cacheKey string) (time.Time, error) {
+	if entry, ok := t.memory.get(cacheKey); ok {
+		return entry.cachedAt, nil
+	}
+	stat, err := t.store.Stat(cacheKey)
 	if err != nil {
-		return true // File doesn't exist or can't be read
+		return time.Time{}, err
 	}
+	return stat.CachedAt, nil
+}
+
+// dynamicTTL returns a TTL scaled by how long ago the content was last updated.
+// The result is never shorter than the given default TTL.
+func dynamicTTL(defaultTTL time.Duration, updated time.Time, now time.Time) time.Duration {
+	updateAge := now.Sub(updated)
 
-	// Determine TTL based on cache key suffix
-	ttlHours := t.config.DefaultTTLHours
-	base := filepath.Base(path)
-	if base == "-search" || filepath.Ext(base) == "-search" {
-		ttlHours = t.config.SearchTTLHours
+	ttl := updateAgeTTLs[len(updateAgeTTLs)-1].TTL
+	for _, tier := range updateAgeTTLs {
+		if tier.MaxAge > 0 && updateAge < tier.MaxAge {
+			ttl = tier.TTL
+			break
+		}
 	}
 
-	age := t.runStart.Sub(stat.ModTime())
-	return age >= time.Duration(ttlHours)*time.Hour
+	if ttl < defaultTTL {
+		return defaultTTL
+	}
+	return ttl
 }
 
-// readCacheEntry reads a cached HTTP response
+// gzipMagic prefixes gzip compressed cache entries.
+// Uncompressed entries written by earlier versions start with "HTTP/" and are read as-is.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// readCacheEntry reads a cached HTTP response, from memory if it's held there, otherwise from the store,
+// decompressing it if needed and holding it in memory
 func (t *FileCachingTransport) readCacheEntry(cacheKey string) (*http.Response, error) {
-	path := t.cachePath(cacheKey)
-	data, err := os.ReadFile(path)
+	if entry, ok := t.memory.get(cacheKey); ok {
+		return http.ReadResponse(bufio.NewReader(bytes.NewReader(entry.data)), nil)
+	}
+
+	data, err := t.store.Read(cacheKey)
 	if err != nil {
 		return nil, err
 	}
 
+	if bytes.HasPrefix(data, gzipMagic) {
+		data, err = gunzip(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress cache entry %s: %w", cacheKey, err)
+		}
+	}
+
+	if t.memory != nil {
+		if stat, err := t.store.Stat(cacheKey); err == nil {
+			t.memory.put(cacheKey, data, stat.CachedAt)
+		}
+	}
+
 	return http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
 }
 
-// writeCacheEntry writes an HTTP response to cache
-func (t *FileCachingTransport) writeCacheEntry(cacheKey string, resp *http.Response) error {
-	path := t.cachePath(cacheKey)
+// gunzip decompresses a gzip compressed cache entry
+func gunzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
 
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("failed to create cache directory: %w", err)
+// writeCacheEntry writes an HTTP response to cache, returning the size of its body
+func (t *FileCachingTransport) writeCacheEntry(cacheKey string, resp *http.Response) (int64, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	dumpedBytes, err := httputil.DumpResponse(resp, true)
 	if err != nil {
-		return fmt.Errorf("failed to dump response: %w", err)
+		return 0, fmt.Errorf("failed to dump response: %w", err)
 	}
 
-	if err := os.WriteFile(path, dumpedBytes, 0644); err != nil {
-		return fmt.Errorf("failed to write cache file: %w", err)
+	// Detail pages are large HTML blobs that compress well
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(dumpedBytes); err != nil {
+		return 0, fmt.Errorf("failed to compress response: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return 0, fmt.Errorf("failed to compress response: %w", err)
 	}
 
-	return nil
+	if err := t.store.Write(cacheKey, compressed.Bytes()); err != nil {
+		return 0, err
+	}
+	t.memory.put(cacheKey, dumpedBytes, time.Now())
+	return int64(len(body)), nil
 }
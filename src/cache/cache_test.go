@@ -0,0 +1,334 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDynamicTTL(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	defaultTTL := 48 * time.Hour
+
+	tests := []struct {
+		name    string
+		updated time.Time
+		want    time.Duration
+	}{
+		{"updated yesterday", now.Add(-24 * time.Hour), defaultTTL},
+		{"updated last month", now.Add(-30 * 24 * time.Hour), 7 * 24 * time.Hour},
+		{"updated last half-year", now.Add(-180 * 24 * time.Hour), 14 * 24 * time.Hour},
+		{"updated years ago", now.Add(-5 * 365 * 24 * time.Hour), 30 * 24 * time.Hour},
+		{"updated in the future", now.Add(24 * time.Hour), defaultTTL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dynamicTTL(defaultTTL, tt.updated, now); got != tt.want {
+				t.Errorf("dynamicTTL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCategoryOf(t *testing.T) {
+	transport := NewFileCachingTransport(CacheConfig{Directory: t.TempDir()}, http.DefaultTransport)
+
+	tests := []struct {
+		url        string
+		want       Category
+		wantSuffix string
+	}{
+		{"https://www.wowinterface.com/downloads/info12345", PageCategory, ""},
+		{"https://www.wowinterface.com/search?q=bags", SearchCategory, "-search"},
+		{"https://cdn.wowinterface.com/downloads/file12345/Addon.zip", ZipCategory, "-zip"},
+		{"https://api.mmoui.com/v4/game/WOW/filelist.json", FileListCategory, "-filelist"},
+		{"https://www.wowinterface.com/search/more", PageCategory, ""},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+		if got := CategoryOf(req.URL); got != tt.want {
+			t.Errorf("CategoryOf(%s) = %s, want %s", tt.url, got, tt.want)
+		}
+		if key := transport.makeCacheKey(req); len(key) != 32+len(tt.wantSuffix) || !strings.HasSuffix(key, tt.wantSuffix) {
+			t.Errorf("makeCacheKey(%s) = %s, want a hash suffixed %q", tt.url, key, tt.wantSuffix)
+		}
+	}
+}
+
+func TestCacheExpired_DynamicTTL(t *testing.T) {
+	dir := t.TempDir()
+	config := CacheConfig{Directory: dir, DefaultTTLHours: 48, SearchTTLHours: 2, DynamicTTL: true}
+	transport := NewFileCachingTransport(config, http.DefaultTransport)
+
+	url := "https://www.wowinterface.com/downloads/info12345"
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	cacheKey := transport.makeCacheKey(req)
+	path := filepath.Join(config.Directory, cacheKey)
+
+	// Entry cached five days ago
+	if err := os.WriteFile(path, []byte("cached"), 0644); err != nil {
+		t.Fatalf("failed to write cache entry: %v", err)
+	}
+	cachedAt := transport.runStart.Add(-5 * 24 * time.Hour)
+	if err := os.Chtimes(path, cachedAt, cachedAt); err != nil {
+		t.Fatalf("failed to set cache entry time: %v", err)
+	}
+
+	// No hint: default 48h TTL applies
+	if !transport.cacheExpired(req, cacheKey) {
+		t.Error("Expected entry to expire without an update hint")
+	}
+
+	// Addon untouched for years: entry is still fresh
+	transport.SetUpdatedDate(url, transport.runStart.Add(-3*365*24*time.Hour))
+	if transport.cacheExpired(req, cacheKey) {
+		t.Error("Expected entry for a long-stable addon to be fresh")
+	}
+
+	// Addon updated after the entry was cached: always stale
+	transport.SetUpdatedDate(url, cachedAt.Add(time.Hour))
+	if !transport.cacheExpired(req, cacheKey) {
+		t.Error("Expected entry cached before the addon's last update to expire")
+	}
+
+	// Dynamic TTLs disabled: hints are ignored
+	transport.config.DynamicTTL = false
+	transport.SetUpdatedDate(url, transport.runStart.Add(-3*365*24*time.Hour))
+	if !transport.cacheExpired(req, cacheKey) {
+		t.Error("Expected default TTL when dynamic TTLs are disabled")
+	}
+}
+
+func TestCacheEntry_Compression(t *testing.T) {
+	dir := t.TempDir()
+	transport := NewFileCachingTransport(CacheConfig{Directory: dir, DefaultTTLHours: 48}, http.DefaultTransport)
+
+	body := strings.Repeat("<div>addon detail</div>", 100)
+	resp := &http.Response{
+		StatusCode:    200,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"text/html"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+
+	size, err := transport.writeCacheEntry("compressed", resp)
+	if err != nil {
+		t.Fatalf("writeCacheEntry() unexpected error: %v", err)
+	}
+	if size != int64(len(body)) {
+		t.Errorf("writeCacheEntry() size = %d, want %d", size, len(body))
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "compressed"))
+	if err != nil {
+		t.Fatalf("failed to read cache entry: %v", err)
+	}
+	if !bytes.HasPrefix(raw, gzipMagic) {
+		t.Error("Expected cache entry to be gzip compressed")
+	}
+	if len(raw) >= len(body) {
+		t.Errorf("Expected compressed entry (%d bytes) to be smaller than the body (%d bytes)", len(raw), len(body))
+	}
+
+	// Uncompressed entries from earlier versions are still readable
+	legacy := "HTTP/1.1 200 OK\r\nContent-Length: 6\r\n\r\nlegacy"
+	if err := os.WriteFile(filepath.Join(dir, "legacy"), []byte(legacy), 0644); err != nil {
+		t.Fatalf("failed to write legacy entry: %v", err)
+	}
+
+	for key, want := range map[string]string{"compressed": body, "legacy": "legacy"} {
+		cached, err := transport.readCacheEntry(key)
+		if err != nil {
+			t.Fatalf("readCacheEntry(%s) unexpected error: %v", key, err)
+		}
+		got, _ := io.ReadAll(cached.Body)
+		if string(got) != want {
+			t.Errorf("readCacheEntry(%s) body = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestRoundTrip_Offline(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("fresh"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	online := NewFileCachingTransport(CacheConfig{Directory: dir, DefaultTTLHours: 48}, http.DefaultTransport)
+	resp, err := (&http.Client{Transport: online}).Get(server.URL + "/cached")
+	if err != nil {
+		t.Fatalf("Get(/cached) unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	// A TTL of zero expires everything, offline it's served anyway
+	offline := NewFileCachingTransport(CacheConfig{Directory: dir, DefaultTTLHours: 0, Offline: true}, http.DefaultTransport)
+	client := &http.Client{Transport: offline}
+
+	resp, err = client.Get(server.URL + "/cached")
+	if err != nil {
+		t.Fatalf("Get(/cached) offline unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "fresh" {
+		t.Errorf("Get(/cached) offline body = %q, want %q", body, "fresh")
+	}
+
+	if _, err := client.Get(server.URL + "/missing"); !errors.Is(err, ErrNotCached) {
+		t.Errorf("Get(/missing) offline error = %v, want ErrNotCached", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("server received %d requests, want 1 (none offline)", requests)
+	}
+	if hits, misses := offline.CacheStats(); hits != 1 || misses != 0 {
+		t.Errorf("CacheStats() = %d, %d, want 1, 0", hits, misses)
+	}
+}
+
+func TestRoundTrip_Memory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fresh"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := CacheConfig{Directory: dir, DefaultTTLHours: 48, MemoryEntries: DefaultMemoryEntries, MemoryBytes: DefaultMemoryBytes}
+	transport := NewFileCachingTransport(config, http.DefaultTransport)
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL + "/filelist.json")
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	// Held in memory, the entry is served without the store
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/filelist.json", nil)
+	if err := os.Remove(filepath.Join(dir, transport.makeCacheKey(req))); err != nil {
+		t.Fatalf("failed to remove cache entry: %v", err)
+	}
+	for range 2 {
+		resp, err := client.Get(server.URL + "/filelist.json")
+		if err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "fresh" {
+			t.Errorf("Get() body = %q, want %q", body, "fresh")
+		}
+	}
+	if hits, misses := transport.CacheStats(); hits != 2 || misses != 1 {
+		t.Errorf("CacheStats() = %d, %d, want 2, 1", hits, misses)
+	}
+}
+
+func TestFresh(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	transport := NewFileCachingTransport(CacheConfig{Directory: dir, DefaultTTLHours: 48, SearchTTLHours: 0}, http.DefaultTransport)
+	for _, path := range []string{"/page", "/search"} {
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Get(%s) unexpected error: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	// Entries written by this run are fresh until the next, whatever their TTL
+	later := NewFileCachingTransport(CacheConfig{Directory: dir, DefaultTTLHours: 48, SearchTTLHours: 0}, http.DefaultTransport)
+	for path, want := range map[string]bool{"/page": true, "/search": false, "/missing": false} {
+		if got := later.Fresh(server.URL + path); got != want {
+			t.Errorf("Fresh(%s) = %v, want %v", path, got, want)
+		}
+	}
+
+	// Offline anything cached is served
+	offline := NewFileCachingTransport(CacheConfig{Directory: dir, Offline: true}, http.DefaultTransport)
+	for path, want := range map[string]bool{"/page": true, "/search": true, "/missing": false} {
+		if got := offline.Fresh(server.URL + path); got != want {
+			t.Errorf("offline Fresh(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	transport := NewFileCachingTransport(CacheConfig{Directory: t.TempDir(), DefaultTTLHours: 48}, http.DefaultTransport)
+	client := &http.Client{Transport: transport}
+
+	for _, path := range []string{"/a", "/a", "/a", "/b"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Get(%s) unexpected error: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	hits, misses := transport.CacheStats()
+	if hits != 2 || misses != 2 {
+		t.Errorf("CacheStats() = %d, %d, want 2, 2", hits, misses)
+	}
+}
+
+func TestStartRun(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	config := CacheConfig{Directory: t.TempDir(), TTLRules: []TTLRule{{Pattern: "filelist.json", TTL: 50 * time.Millisecond}}}
+	transport := NewFileCachingTransport(config, http.DefaultTransport)
+	client := &http.Client{Transport: transport}
+	get := func() {
+		t.Helper()
+		resp, err := client.Get(server.URL + "/filelist.json")
+		if err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// Entries written this run are fresh for the rest of it, however long it takes
+	get()
+	time.Sleep(100 * time.Millisecond)
+	get()
+	if requests != 1 {
+		t.Errorf("fetched %d times within a run, want 1", requests)
+	}
+
+	// A later run, more than the TTL after the entry was cached, fetches it again
+	transport.StartRun()
+	get()
+	if requests != 2 {
+		t.Errorf("fetched %d times after StartRun(), want 2", requests)
+	}
+	if hits, misses := transport.CacheStats(); hits != 0 || misses != 1 {
+		t.Errorf("CacheStats() = %d, %d, want 0, 1 for the new run", hits, misses)
+	}
+}
@@ -0,0 +1,255 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// IndexFile maps cache keys back to the URLs they were fetched from. It lives in the cache directory.
+const IndexFile = "index.json"
+
+// unindexedHost groups cache entries written before the index existed
+const unindexedHost = "(unindexed)"
+
+// IndexEntry describes a cached response
+type IndexEntry struct {
+	URL      string    `json:"url"`
+	Category Category  `json:"category,omitempty"` // empty for entries indexed before categories were recorded
+	Size     int64     `json:"size"`               // bytes on disk, compressed
+	CachedAt time.Time `json:"cached-at"`
+}
+
+// Host returns the host the entry was fetched from
+func (e IndexEntry) Host() string {
+	if u, err := url.Parse(e.URL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return unindexedHost
+}
+
+// HostStats counts requests to a host served from the cache and fetched
+type HostStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// RunStats records how the cache performed during the last run that saved the index
+type RunStats struct {
+	StartedAt time.Time            `json:"started-at"`
+	Hosts     map[string]HostStats `json:"hosts"`
+}
+
+// Index of the cache directory
+type Index struct {
+	Entries map[string]IndexEntry `json:"entries"` // cache key -> entry
+	LastRun *RunStats             `json:"last-run,omitempty"`
+}
+
+// ReadIndex reads the index of a cache directory, returning an empty index if there isn't one
+func ReadIndex(dir string) (Index, error) {
+	index := Index{Entries: make(map[string]IndexEntry)}
+
+	path := filepath.Join(dir, IndexFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return index, fmt.Errorf("failed to read cache index %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &index); err != nil {
+		return index, fmt.Errorf("failed to parse cache index %s: %w", path, err)
+	}
+	if index.Entries == nil {
+		index.Entries = make(map[string]IndexEntry)
+	}
+	return index, nil
+}
+
+// WriteIndex writes the index of a cache directory
+func WriteIndex(dir string, index Index) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache index: %w", err)
+	}
+
+	path := filepath.Join(dir, IndexFile)
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write cache index %s: %w", path, err)
+	}
+	return nil
+}
+
+// indexEntry records a cache entry written during this run
+func (t *FileCachingTransport) indexEntry(cacheKey string, u *url.URL) {
+	stat, err := t.store.Stat(cacheKey)
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.indexed[cacheKey] = IndexEntry{URL: u.String(), Category: CategoryOf(u), Size: stat.Size, CachedAt: stat.CachedAt.UTC()}
+}
+
+// countRequest records a request to host being served from the cache or fetched
+func (t *FileCachingTransport) countRequest(host string, hit bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := t.hostStats[host]
+	if hit {
+		stats.Hits++
+	} else {
+		stats.Misses++
+	}
+	t.hostStats[host] = stats
+}
+
+// SaveIndex adds the entries written during this run to the cache directory's index,
+// along with this run's hits and misses per host
+func (t *FileCachingTransport) SaveIndex() error {
+	index, err := ReadIndex(t.config.Directory)
+	if err != nil {
+		return err
+	}
+
+	t.mu.RLock()
+	for cacheKey, entry := range t.indexed {
+		index.Entries[cacheKey] = entry
+	}
+	lastRun := &RunStats{StartedAt: t.runStart.UTC(), Hosts: make(map[string]HostStats, len(t.hostStats))}
+	for host, stats := range t.hostStats {
+		lastRun.Hosts[host] = stats
+	}
+	t.mu.RUnlock()
+
+	index.LastRun = lastRun
+	return WriteIndex(t.config.Directory, index)
+}
+
+// HostSummary totals the cache entries of a host
+type HostSummary struct {
+	Host    string
+	Entries int
+	Bytes   int64
+	HostStats
+}
+
+// Summary totals a cache directory's entries by host
+type Summary struct {
+	Entries   int
+	Bytes     int64
+	HostList  []HostSummary // ordered by bytes, largest first
+	LastRunAt *time.Time    // nil if no run has saved the index
+}
+
+// Summarise totals the entries in a store by host, using the index in the cache directory or the entry's metadata to
+// find each entry's host. Entries in neither are grouped as unindexed.
+func Summarise(dir string, store Store) (Summary, error) {
+	var summary Summary
+
+	index, err := ReadIndex(dir)
+	if err != nil {
+		return summary, err
+	}
+
+	hosts := make(map[string]*HostSummary)
+	hostSummary := func(host string) *HostSummary {
+		if hosts[host] == nil {
+			hosts[host] = &HostSummary{Host: host}
+		}
+		return hosts[host]
+	}
+
+	err = store.Entries(func(key string, info EntryInfo) error {
+		if IsMetaKey(key) {
+			return nil
+		}
+		host := unindexedHost
+		if entry, ok := indexedEntry(store, index, key, info); ok {
+			host = entry.Host()
+		}
+		hs := hostSummary(host)
+		hs.Entries++
+		hs.Bytes += info.Size
+		summary.Entries++
+		summary.Bytes += info.Size
+		return nil
+	})
+	if err != nil {
+		return summary, err
+	}
+
+	if index.LastRun != nil {
+		startedAt := index.LastRun.StartedAt
+		summary.LastRunAt = &startedAt
+		for host, stats := range index.LastRun.Hosts {
+			hostSummary(host).HostStats = stats
+		}
+	}
+
+	for _, hs := range hosts {
+		summary.HostList = append(summary.HostList, *hs)
+	}
+	sort.Slice(summary.HostList, func(i, j int) bool {
+		if summary.HostList[i].Bytes != summary.HostList[j].Bytes {
+			return summary.HostList[i].Bytes > summary.HostList[j].Bytes
+		}
+		return summary.HostList[i].Host < summary.HostList[j].Host
+	})
+
+	return summary, nil
+}
+
+// List returns the entries in the store, indexed or with metadata, whose host is one of hosts (all if empty),
+// ordered by URL
+func List(dir string, store Store, hosts []string) ([]IndexEntry, error) {
+	index, err := ReadIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		wanted[host] = true
+	}
+
+	var entries []IndexEntry
+	err = store.Entries(func(key string, info EntryInfo) error {
+		if IsMetaKey(key) {
+			return nil
+		}
+		entry, ok := indexedEntry(store, index, key, info)
+		if ok && (len(wanted) == 0 || wanted[entry.Host()]) {
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].URL < entries[j].URL
+	})
+	return entries, nil
+}
+
+// indexedEntry returns the index's entry for the stored entry with key, or one from the entry's metadata if it
+// isn't indexed, e.g. because the run that wrote it never saved the index. False if neither describe it.
+func indexedEntry(store Store, index Index, key string, info EntryInfo) (IndexEntry, bool) {
+	if entry, ok := index.Entries[key]; ok {
+		return entry, true
+	}
+	meta, err := ReadMeta(store, key)
+	if err != nil {
+		return IndexEntry{}, false
+	}
+	return IndexEntry{URL: meta.URL, Category: meta.Category, Size: info.Size, CachedAt: info.CachedAt.UTC()}, true
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	host := mustParseURL(t, server.URL).Host
+
+	dir := t.TempDir()
+	transport := NewFileCachingTransport(CacheConfig{Directory: dir, DefaultTTLHours: 48}, http.DefaultTransport)
+	client := &http.Client{Transport: transport}
+
+	for _, path := range []string{"/a", "/a", "/a", "/b"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Get(%s) unexpected error: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	// An entry cached before the index existed
+	if err := os.WriteFile(filepath.Join(dir, "unindexed"), []byte("old"), 0644); err != nil {
+		t.Fatalf("failed to write cache entry: %v", err)
+	}
+
+	if err := transport.SaveIndex(); err != nil {
+		t.Fatalf("SaveIndex() unexpected error: %v", err)
+	}
+
+	entries, err := List(dir, transport.Store(), nil)
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].URL != server.URL+"/a" || entries[1].URL != server.URL+"/b" || entries[0].Category != PageCategory {
+		t.Errorf("List() = %+v, want page entries for /a and /b", entries)
+	}
+	if entries, _ := List(dir, transport.Store(), []string{"www.wowinterface.com"}); len(entries) != 0 {
+		t.Errorf("List(other host) = %+v, want none", entries)
+	}
+
+	summary, err := Summarise(dir, transport.Store())
+	if err != nil {
+		t.Fatalf("Summarise() unexpected error: %v", err)
+	}
+	if summary.Entries != 3 || summary.LastRunAt == nil {
+		t.Errorf("Summarise() = %+v, want 3 entries and a last run", summary)
+	}
+
+	hosts := make(map[string]HostSummary)
+	for _, hs := range summary.HostList {
+		hosts[hs.Host] = hs
+	}
+	if got := hosts[host]; got.Entries != 2 || got.Hits != 2 || got.Misses != 2 || got.Bytes != entries[0].Size+entries[1].Size {
+		t.Errorf("host summary = %+v, want 2 entries, 2 hits and 2 misses", got)
+	}
+	if got := hosts[unindexedHost]; got.Entries != 1 || got.Bytes != 3 {
+		t.Errorf("unindexed summary = %+v, want 1 entry of 3 bytes", got)
+	}
+
+	// A later run's entries are added to the index
+	transport = NewFileCachingTransport(CacheConfig{Directory: dir, DefaultTTLHours: 48}, http.DefaultTransport)
+	client = &http.Client{Transport: transport}
+	resp, err := client.Get(server.URL + "/c")
+	if err != nil {
+		t.Fatalf("Get(/c) unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if err := transport.SaveIndex(); err != nil {
+		t.Fatalf("SaveIndex() unexpected error: %v", err)
+	}
+	if entries, _ := List(dir, transport.Store(), nil); len(entries) != 3 {
+		t.Errorf("List() = %d entries, want 3", len(entries))
+	}
+}
+
+func mustParseURL(t *testing.T, s string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(s)
+	if err != nil {
+		t.Fatalf("failed to parse URL %s: %v", s, err)
+	}
+	return u
+}
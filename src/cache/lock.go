@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LockFile is locked in the cache directory by Lock
+const LockFile = ".lock"
+
+// ErrLocked is returned by Lock when another process holds the cache directory's lock
+var ErrLocked = errors.New("cache directory is locked by another process")
+
+// Lock takes an exclusive lock on the cache directory, failing with ErrLocked rather than waiting
+// if another builder instance holds it. The lock is released by calling unlock or when the process exits.
+// Where file locking isn't supported the lock always succeeds.
+func Lock(dir string) (unlock func() error, err error) {
+	path := filepath.Join(dir, LockFile)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache lock %s: %w", path, err)
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		if errors.Is(err, ErrLocked) {
+			return nil, fmt.Errorf("%w: %s", ErrLocked, dir)
+		}
+		return nil, fmt.Errorf("failed to lock cache directory %s: %w", dir, err)
+	}
+
+	return file.Close, nil // closing the file releases the lock
+}
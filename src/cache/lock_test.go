@@ -0,0 +1,31 @@
+//go:build unix
+
+package cache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLock(t *testing.T) {
+	dir := t.TempDir()
+
+	unlock, err := Lock(dir)
+	if err != nil {
+		t.Fatalf("Lock() unexpected error: %v", err)
+	}
+
+	// flock locks are per open file, so a second open in the same process conflicts like another process would
+	if _, err := Lock(dir); !errors.Is(err, ErrLocked) {
+		t.Errorf("Lock() on a locked directory error = %v, want ErrLocked", err)
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatalf("unlock() unexpected error: %v", err)
+	}
+	unlock, err = Lock(dir)
+	if err != nil {
+		t.Fatalf("Lock() after unlock unexpected error: %v", err)
+	}
+	unlock()
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Defaults for how many responses, and how many bytes of them, are also held in memory
+const (
+	DefaultMemoryEntries = 256
+	DefaultMemoryBytes   = 64 << 20
+)
+
+// memoryEntry is a decompressed cache entry held in memory
+type memoryEntry struct {
+	key      string
+	data     []byte // the dumped response, as written to the store before compression
+	cachedAt time.Time
+}
+
+// memoryCache is a bounded least-recently-used cache of entries in front of a Store, so a response requested again
+// in the same run isn't read from disk and decompressed again. Safe for concurrent use.
+type memoryCache struct {
+	maxEntries int
+	maxBytes   int64
+
+	mu      sync.Mutex
+	order   *list.List // most recently used first
+	entries map[string]*list.Element
+	size    int64 // bytes of data held
+}
+
+// newMemoryCache returns a cache holding at most maxEntries entries and maxBytes bytes of them,
+// or nil if either is 0. A nil cache holds nothing.
+func newMemoryCache(maxEntries int, maxBytes int64) *memoryCache {
+	if maxEntries <= 0 || maxBytes <= 0 {
+		return nil
+	}
+	return &memoryCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// get returns the entry for key, marking it the most recently used
+func (c *memoryCache) get(key string) (memoryEntry, bool) {
+	if c == nil {
+		return memoryEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return memoryEntry{}, false
+	}
+	c.order.MoveToFront(element)
+	return *element.Value.(*memoryEntry), true
+}
+
+// put holds data for key, replacing any entry it had and evicting the least recently used entries over the limits.
+// Data larger than the byte limit isn't held, and any older entry for key is dropped.
+func (c *memoryCache) put(key string, data []byte, cachedAt time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	if int64(len(data)) > c.maxBytes {
+		return
+	}
+	c.entries[key] = c.order.PushFront(&memoryEntry{key: key, data: data, cachedAt: cachedAt})
+	c.size += int64(len(data))
+	for c.order.Len() > c.maxEntries || c.size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove drops element, the caller holds the lock
+func (c *memoryCache) remove(element *list.Element) {
+	entry := c.order.Remove(element).(*memoryEntry)
+	delete(c.entries, entry.key)
+	c.size -= int64(len(entry.data))
+}
+
+// len returns the number of entries and bytes held
+func (c *memoryCache) len() (entries int, bytes int64) {
+	if c == nil {
+		return 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len(), c.size
+}
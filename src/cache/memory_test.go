@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	cachedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newMemoryCache(3, 10)
+
+	cache.put("a", []byte("aaa"), cachedAt)
+	cache.put("b", []byte("bbb"), cachedAt)
+	cache.put("c", []byte("ccc"), cachedAt)
+	if _, ok := cache.get("a"); !ok {
+		t.Fatal("get(a) = not held, want held")
+	}
+
+	// Over the entry limit, b is the least recently used
+	cache.put("d", []byte("d"), cachedAt)
+	if _, ok := cache.get("b"); ok {
+		t.Error("get(b) = held, want evicted as the least recently used")
+	}
+
+	// Over the byte limit, c is the least recently used
+	cache.put("e", []byte("eeeeee"), cachedAt)
+	for key, want := range map[string]bool{"a": true, "c": false, "d": true, "e": true} {
+		if _, ok := cache.get(key); ok != want {
+			t.Errorf("get(%s) held = %v, want %v", key, ok, want)
+		}
+	}
+	if entries, size := cache.len(); entries != 3 || size != 10 {
+		t.Errorf("len() = %d, %d, want 3, 10", entries, size)
+	}
+
+	// Replacing an entry replaces its size, data over the byte limit isn't held and drops the older entry
+	cache.put("a", []byte("a"), cachedAt.Add(time.Hour))
+	if entry, ok := cache.get("a"); !ok || string(entry.data) != "a" || !entry.cachedAt.Equal(cachedAt.Add(time.Hour)) {
+		t.Errorf("get(a) = %q %v, want the replacement", entry.data, entry.cachedAt)
+	}
+	cache.put("e", []byte(strings.Repeat("e", 11)), cachedAt)
+	if _, ok := cache.get("e"); ok {
+		t.Error("get(e) = held, want data over the byte limit not held")
+	}
+	if entries, size := cache.len(); entries != 2 || size != 2 {
+		t.Errorf("len() = %d, %d, want 2, 2", entries, size)
+	}
+
+	disabled := newMemoryCache(0, 10)
+	disabled.put("a", []byte("a"), cachedAt)
+	if _, ok := disabled.get("a"); ok {
+		t.Error("get(a) on a disabled cache = held, want nothing held")
+	}
+}
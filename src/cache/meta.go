@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MetaSuffix suffixes the key of the metadata stored beside each cache entry, see Meta
+const MetaSuffix = ".meta.json"
+
+// Meta describes the response held by a cache entry. It's stored beside the entry, under the entry's key suffixed
+// with MetaSuffix, so what an entry holds is known without the index: the index is only saved at the end of a run,
+// and the key is a hash of the URL that can't be reversed if the key scheme ever changes.
+type Meta struct {
+	URL        string    `json:"url"`
+	Category   Category  `json:"category"`
+	StatusCode int       `json:"status-code"`
+	Size       int64     `json:"size"` // bytes of the response body, uncompressed
+	FetchedAt  time.Time `json:"fetched-at"`
+}
+
+// IsMetaKey returns true if key is the key of an entry's metadata rather than of an entry
+func IsMetaKey(key string) bool {
+	return strings.HasSuffix(key, MetaSuffix)
+}
+
+// ReadMeta reads the metadata of the entry with cacheKey.
+// Returns an error satisfying errors.Is(err, os.ErrNotExist) for entries written before metadata was.
+func ReadMeta(store Store, cacheKey string) (Meta, error) {
+	var meta Meta
+	data, err := store.Read(cacheKey + MetaSuffix)
+	if err != nil {
+		return meta, err
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, fmt.Errorf("failed to parse metadata of cache entry %s: %w", cacheKey, err)
+	}
+	return meta, nil
+}
+
+// writeMeta writes the metadata of the entry with cacheKey
+func writeMeta(store Store, cacheKey string, meta Meta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata of cache entry %s: %w", cacheKey, err)
+	}
+	return store.Write(cacheKey+MetaSuffix, data)
+}
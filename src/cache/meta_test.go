@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMeta(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("addon list"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	transport := NewFileCachingTransport(CacheConfig{Directory: dir, DefaultTTLHours: 48}, http.DefaultTransport)
+	url := server.URL + "/v4/game/WOW/filelist.json"
+	resp, err := (&http.Client{Transport: transport}).Get(url)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	cacheKey := transport.makeCacheKey(req)
+	meta, err := ReadMeta(transport.Store(), cacheKey)
+	if err != nil {
+		t.Fatalf("ReadMeta() unexpected error: %v", err)
+	}
+	if meta.URL != url || meta.Category != FileListCategory || meta.StatusCode != 200 || meta.Size != 10 || time.Since(meta.FetchedAt) > time.Minute {
+		t.Errorf("ReadMeta() = %+v, want the URL, category, status and size of the response", meta)
+	}
+	if _, err := os.Stat(filepath.Join(dir, cacheKey+MetaSuffix)); err != nil {
+		t.Errorf("metadata file unexpected error: %v", err)
+	}
+
+	// The index was never saved, the entry is listed from its metadata
+	entries, err := List(dir, transport.Store(), nil)
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].URL != url || entries[0].Category != FileListCategory {
+		t.Errorf("List() = %+v, want the entry described by its metadata", entries)
+	}
+	summary, err := Summarise(dir, transport.Store())
+	if err != nil {
+		t.Fatalf("Summarise() unexpected error: %v", err)
+	}
+	if summary.Entries != 1 || len(summary.HostList) != 1 || summary.HostList[0].Host != mustParseURL(t, server.URL).Host {
+		t.Errorf("Summarise() = %+v, want the entry under its host", summary)
+	}
+
+	if _, err := ReadMeta(transport.Store(), "missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadMeta(missing) error = %v, want os.ErrNotExist", err)
+	}
+}
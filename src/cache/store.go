@@ -0,0 +1,256 @@
+package cache
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // pure Go driver, keeps CGO_ENABLED=0 builds working
+)
+
+// Backend is where cache entries are stored
+type Backend string
+
+const (
+	FilesBackend  Backend = "files"  // a file per entry in the cache directory
+	SQLiteBackend Backend = "sqlite" // a single SQLite database in the cache directory, see SQLiteFile
+)
+
+var KnownBackends = []Backend{FilesBackend, SQLiteBackend}
+
+// SQLiteFile is the database the SQLite backend stores entries in, in the cache directory
+const SQLiteFile = "cache.db"
+
+// EntryInfo describes a stored cache entry
+type EntryInfo struct {
+	Size     int64
+	CachedAt time.Time
+}
+
+// Store holds cache entries by cache key.
+// Read and Stat return an error satisfying errors.Is(err, os.ErrNotExist) for missing entries.
+type Store interface {
+	Read(key string) ([]byte, error)
+	Write(key string, data []byte) error
+	Restore(key string, data []byte, cachedAt time.Time) error // Write, keeping when the entry was originally cached
+	Stat(key string) (EntryInfo, error)
+	Entries(fn func(key string, info EntryInfo) error) error // every entry, in no particular order
+	Close() error
+}
+
+// OpenStore opens the store for a backend in the cache directory
+func OpenStore(backend Backend, dir string) (Store, error) {
+	switch backend {
+	case FilesBackend, "":
+		return &fileStore{dir: dir}, nil
+	case SQLiteBackend:
+		return openSQLiteStore(filepath.Join(dir, SQLiteFile))
+	default:
+		return nil, fmt.Errorf("unknown cache backend: %s", backend)
+	}
+}
+
+// fileStore stores each entry in a file named after its cache key
+type fileStore struct {
+	dir string
+}
+
+func (s *fileStore) path(key string) string {
+	return filepath.Join(s.dir, key)
+}
+
+func (s *fileStore) Read(key string) ([]byte, error) {
+	return os.ReadFile(s.path(key))
+}
+
+func (s *fileStore) Write(key string, data []byte) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := writeFileAtomic(s.path(key), data); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return nil
+}
+
+func (s *fileStore) Restore(key string, data []byte, cachedAt time.Time) error {
+	if err := s.Write(key, data); err != nil {
+		return err
+	}
+	if err := os.Chtimes(s.path(key), cachedAt, cachedAt); err != nil {
+		return fmt.Errorf("failed to set cache file time: %w", err)
+	}
+	return nil
+}
+
+// tempPrefix prefixes entries being written, see writeFileAtomic
+const tempPrefix = ".tmp-"
+
+// writeFileAtomic writes data to a temporary file beside path and renames it into place,
+// so a crash mid-write never leaves a truncated file and concurrent writers never interleave
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), tempPrefix+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *fileStore) Stat(key string) (EntryInfo, error) {
+	stat, err := os.Stat(s.path(key))
+	if err != nil {
+		return EntryInfo{}, err
+	}
+	return EntryInfo{Size: stat.Size(), CachedAt: stat.ModTime()}, nil
+}
+
+func (s *fileStore) Entries(fn func(key string, info EntryInfo) error) error {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory %s: %w", s.dir, err)
+	}
+
+	for _, file := range files {
+		// Skips temporary files and the lock file too
+		if file.IsDir() || file.Name() == IndexFile || file.Name() == SQLiteFile || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		stat, err := file.Info()
+		if err != nil {
+			continue // removed since the directory was read
+		}
+		if err := fn(file.Name(), EntryInfo{Size: stat.Size(), CachedAt: stat.ModTime()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fileStore) Close() error {
+	return nil
+}
+
+const sqliteStoreSchema = `
+CREATE TABLE IF NOT EXISTS entry (
+	key       TEXT PRIMARY KEY,
+	data      BLOB NOT NULL,
+	cached_at INTEGER NOT NULL -- unix nanoseconds
+);
+`
+
+// sqliteStore stores entries as rows of a single database,
+// for filesystems that struggle with hundreds of thousands of small files
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func openSQLiteStore(path string) (*sqliteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache database %s: %w", path, err)
+	}
+	// Workers write concurrently, SQLite allows a single writer
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteStoreSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create cache database schema %s: %w", path, err)
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) Read(key string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM entry WHERE key = ?`, key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("cache entry %s: %w", key, os.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache entry %s: %w", key, err)
+	}
+	return data, nil
+}
+
+func (s *sqliteStore) Write(key string, data []byte) error {
+	return s.Restore(key, data, time.Now())
+}
+
+func (s *sqliteStore) Restore(key string, data []byte, cachedAt time.Time) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO entry (key, data, cached_at) VALUES (?, ?, ?)`, key, data, cachedAt.UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) Stat(key string) (EntryInfo, error) {
+	var size, cachedAt int64
+	err := s.db.QueryRow(`SELECT length(data), cached_at FROM entry WHERE key = ?`, key).Scan(&size, &cachedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return EntryInfo{}, fmt.Errorf("cache entry %s: %w", key, os.ErrNotExist)
+	}
+	if err != nil {
+		return EntryInfo{}, fmt.Errorf("failed to read cache entry %s: %w", key, err)
+	}
+	return EntryInfo{Size: size, CachedAt: time.Unix(0, cachedAt)}, nil
+}
+
+func (s *sqliteStore) Entries(fn func(key string, info EntryInfo) error) error {
+	rows, err := s.db.Query(`SELECT key, length(data), cached_at FROM entry`)
+	if err != nil {
+		return fmt.Errorf("failed to list cache entries: %w", err)
+	}
+	defer rows.Close()
+
+	type entry struct {
+		key  string
+		info EntryInfo
+	}
+	// Collected first, the single connection is busy until rows is closed
+	var entries []entry
+	for rows.Next() {
+		var e entry
+		var cachedAt int64
+		if err := rows.Scan(&e.key, &e.info.Size, &cachedAt); err != nil {
+			return fmt.Errorf("failed to list cache entries: %w", err)
+		}
+		e.info.CachedAt = time.Unix(0, cachedAt)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list cache entries: %w", err)
+	}
+	rows.Close()
+
+	for _, e := range entries {
+		if err := fn(e.key, e.info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	for _, backend := range KnownBackends {
+		t.Run(string(backend), func(t *testing.T) {
+			store, err := OpenStore(backend, t.TempDir())
+			if err != nil {
+				t.Fatalf("OpenStore() unexpected error: %v", err)
+			}
+			defer store.Close()
+
+			if _, err := store.Read("missing"); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("Read(missing) error = %v, want os.ErrNotExist", err)
+			}
+			if _, err := store.Stat("missing"); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("Stat(missing) error = %v, want os.ErrNotExist", err)
+			}
+
+			before := time.Now().Add(-time.Second)
+			for _, data := range []string{"first", "second"} {
+				if err := store.Write("key", []byte(data)); err != nil {
+					t.Fatalf("Write() unexpected error: %v", err)
+				}
+			}
+
+			data, err := store.Read("key")
+			if err != nil || string(data) != "second" {
+				t.Errorf("Read() = %q, %v, want \"second\"", data, err)
+			}
+			info, err := store.Stat("key")
+			if err != nil || info.Size != 6 || info.CachedAt.Before(before) {
+				t.Errorf("Stat() = %+v, %v, want 6 bytes cached after %v", info, err, before)
+			}
+
+			var keys []string
+			err = store.Entries(func(key string, info EntryInfo) error {
+				keys = append(keys, key)
+				return nil
+			})
+			if err != nil || len(keys) != 1 || keys[0] != "key" {
+				t.Errorf("Entries() = %v, %v, want [key]", keys, err)
+			}
+		})
+	}
+}
+
+func TestNewCachingTransport_SQLite(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := CacheConfig{Directory: dir, Backend: SQLiteBackend, DefaultTTLHours: 48}
+	transport, err := NewCachingTransport(config, http.DefaultTransport)
+	if err != nil {
+		t.Fatalf("NewCachingTransport() unexpected error: %v", err)
+	}
+	defer transport.Close()
+	client := &http.Client{Transport: transport}
+
+	for range 2 {
+		resp, err := client.Get(server.URL + "/a")
+		if err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "ok" {
+			t.Errorf("Get() body = %q, want \"ok\"", body)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("server requests = %d, want 1", requests)
+	}
+
+	// Everything is in the database, nothing is written beside it
+	files, _ := os.ReadDir(dir)
+	for _, file := range files {
+		if name := file.Name(); name != SQLiteFile && filepath.Ext(name) != ".db-wal" && filepath.Ext(name) != ".db-shm" {
+			t.Errorf("unexpected file in cache directory: %s", name)
+		}
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "entry")
+
+	for _, data := range []string{"first", "second"} {
+		if err := writeFileAtomic(path, []byte(data)); err != nil {
+			t.Fatalf("writeFileAtomic() unexpected error: %v", err)
+		}
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != "second" {
+		t.Errorf("entry = %q, want \"second\"", data)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("cache directory has %d files, want 1 (temporary files left behind)", len(files))
+	}
+
+	// A temporary file left by a crash isn't an entry
+	if err := os.WriteFile(filepath.Join(dir, tempPrefix+"entry-123"), []byte("trunc"), 0644); err != nil {
+		t.Fatalf("failed to write temporary file: %v", err)
+	}
+	var keys []string
+	(&fileStore{dir: dir}).Entries(func(key string, info EntryInfo) error {
+		keys = append(keys, key)
+		return nil
+	})
+	if len(keys) != 1 || keys[0] != "entry" {
+		t.Errorf("Entries() = %v, want [entry]", keys)
+	}
+}
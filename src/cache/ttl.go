@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TTLRule caches responses for URLs matching Pattern for TTL instead of the default TTL.
+//
+// Pattern is a path.Match glob compared against the last segments of the URL's host and path,
+// one segment per segment of the pattern. So "filelist.json" matches any filelist,
+// "filedetails/*.json" any API detail and "www.wowinterface.com/downloads/info*" only WowInterface detail pages.
+// The query string isn't matched.
+type TTLRule struct {
+	Pattern string
+	TTL     time.Duration
+}
+
+// DefaultTTLRules cache the expensive detail pages longer than the fast-changing file lists.
+// Detail pages are still re-fetched as soon as a file list shows the addon was updated, see SetUpdatedDate.
+var DefaultTTLRules = []TTLRule{
+	{Pattern: "filelist.json", TTL: time.Hour},
+	{Pattern: "filedetails/*.json", TTL: 24 * time.Hour},
+	{Pattern: "downloads/info*", TTL: 7 * 24 * time.Hour},
+}
+
+// String returns the rule as PATTERN=TTL, as parsed by ParseTTLRule
+func (r TTLRule) String() string {
+	var ttl string
+	switch {
+	case r.TTL > 0 && r.TTL%(24*time.Hour) == 0:
+		ttl = strconv.Itoa(int(r.TTL/(24*time.Hour))) + "d"
+	case r.TTL > 0 && r.TTL%time.Hour == 0:
+		ttl = strconv.Itoa(int(r.TTL/time.Hour)) + "h"
+	default:
+		ttl = r.TTL.String()
+	}
+	return r.Pattern + "=" + ttl
+}
+
+// Matches returns true if the URL matches the rule's pattern
+func (r TTLRule) Matches(u *url.URL) bool {
+	return MatchURL(r.Pattern, u)
+}
+
+// MatchURL returns true if the URL matches pattern, a path.Match glob compared against the last segments
+// of the URL's host and path as described for TTLRule
+func MatchURL(pattern string, u *url.URL) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	urlSegments := strings.Split(strings.Trim(u.Host+u.Path, "/"), "/")
+	if len(patternSegments) > len(urlSegments) {
+		return false
+	}
+
+	urlSegments = urlSegments[len(urlSegments)-len(patternSegments):]
+	for i, pattern := range patternSegments {
+		if matched, err := path.Match(pattern, urlSegments[i]); err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidatePattern returns an error if pattern isn't a valid URL pattern
+func ValidatePattern(pattern string) error {
+	if strings.Trim(pattern, "/") == "" {
+		return fmt.Errorf("empty URL pattern")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid URL pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// ParseTTL parses a Go duration, e.g. "90m", or a whole number of days, e.g. "7d"
+func ParseTTL(s string) (time.Duration, error) {
+	var ttl time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q", s)
+		}
+		ttl = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if ttl, err = time.ParseDuration(s); err != nil {
+			return 0, err
+		}
+	}
+	if ttl < 0 {
+		return 0, fmt.Errorf("%s must not be negative", s)
+	}
+	return ttl, nil
+}
+
+// ParseTTLRule parses a rule written as PATTERN=TTL, e.g. "filelist.json=1h" or "downloads/info*=7d".
+// The TTL is a Go duration or a whole number of days.
+func ParseTTLRule(s string) (TTLRule, error) {
+	pattern, ttlStr, found := strings.Cut(s, "=")
+	pattern = strings.TrimSpace(pattern)
+	ttlStr = strings.TrimSpace(ttlStr)
+	if !found || pattern == "" || ttlStr == "" {
+		return TTLRule{}, fmt.Errorf("invalid cache TTL rule %q, expected PATTERN=TTL", s)
+	}
+	if err := ValidatePattern(pattern); err != nil {
+		return TTLRule{}, fmt.Errorf("invalid cache TTL rule %q: %w", s, err)
+	}
+
+	ttl, err := ParseTTL(ttlStr)
+	if err != nil {
+		return TTLRule{}, fmt.Errorf("invalid TTL in cache TTL rule %q: %w", s, err)
+	}
+
+	return TTLRule{Pattern: pattern, TTL: ttl}, nil
+}
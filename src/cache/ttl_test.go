@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseTTLRule(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    TTLRule
+		wantErr bool
+	}{
+		{"filelist.json=1h", TTLRule{Pattern: "filelist.json", TTL: time.Hour}, false},
+		{"downloads/info*=7d", TTLRule{Pattern: "downloads/info*", TTL: 7 * 24 * time.Hour}, false},
+		{" filedetails/*.json = 90m ", TTLRule{Pattern: "filedetails/*.json", TTL: 90 * time.Minute}, false},
+		{"filelist.json", TTLRule{}, true},
+		{"=1h", TTLRule{}, true},
+		{"filelist.json=soon", TTLRule{}, true},
+		{"filelist.json=1.5d", TTLRule{}, true},
+		{"filelist.json=-1h", TTLRule{}, true},
+		{"[=1h", TTLRule{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTTLRule(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTTLRule(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTTLRule(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTTLRule_String(t *testing.T) {
+	for _, rule := range DefaultTTLRules {
+		parsed, err := ParseTTLRule(rule.String())
+		if err != nil || parsed != rule {
+			t.Errorf("ParseTTLRule(%q) = %+v, %v, want %+v", rule.String(), parsed, err, rule)
+		}
+	}
+}
+
+func TestTTLRule_Matches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{"filelist.json", "https://api.mmoui.com/v4/game/WOW/filelist.json", true},
+		{"filelist.json", "https://api.mmoui.com/v4/game/WOW/filedetails/123.json", false},
+		{"filedetails/*.json", "https://api.mmoui.com/v4/game/WOW/filedetails/123.json", true},
+		{"downloads/info*", "https://www.wowinterface.com/downloads/info25078", true},
+		{"downloads/info*", "https://www.wowinterface.com/downloads/index.php?cid=100&page=2", false},
+		{"www.wowinterface.com/downloads/info*", "https://www.wowinterface.com/downloads/info25078", true},
+		{"github.com/downloads/info*", "https://www.wowinterface.com/downloads/info25078", false},
+		{"a/b/c/d/e", "https://example.org/c/d/e", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.url, func(t *testing.T) {
+			u, _ := url.Parse(tt.url)
+			if got := (TTLRule{Pattern: tt.pattern}).Matches(u); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheExpired_TTLRules(t *testing.T) {
+	config := CacheConfig{Directory: t.TempDir(), DefaultTTLHours: 48, SearchTTLHours: 2, TTLRules: DefaultTTLRules}
+	transport := NewFileCachingTransport(config, http.DefaultTransport)
+
+	// Every entry was cached three hours ago
+	tests := []struct {
+		url         string
+		wantExpired bool
+	}{
+		{"https://api.mmoui.com/v4/game/WOW/filelist.json", true},
+		{"https://api.mmoui.com/v4/game/WOW/filedetails/123.json", false},
+		{"https://www.wowinterface.com/downloads/info123", false},
+		{"https://www.wowinterface.com/downloads/index.php?cid=100", false},
+		{"https://www.wowinterface.com/search?q=bags", true}, // search TTL
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			cacheKey := transport.makeCacheKey(req)
+			path := filepath.Join(config.Directory, cacheKey)
+			if err := os.WriteFile(path, []byte("cached"), 0644); err != nil {
+				t.Fatalf("failed to write cache entry: %v", err)
+			}
+			cachedAt := transport.runStart.Add(-3 * time.Hour)
+			if err := os.Chtimes(path, cachedAt, cachedAt); err != nil {
+				t.Fatalf("failed to set cache entry time: %v", err)
+			}
+
+			if got := transport.cacheExpired(req, cacheKey); got != tt.wantExpired {
+				t.Errorf("cacheExpired() = %v, want %v", got, tt.wantExpired)
+			}
+		})
+	}
+}
+
+func TestCacheExpired_RefreshPatterns(t *testing.T) {
+	config := CacheConfig{Directory: t.TempDir(), DefaultTTLHours: 48, RefreshPatterns: []string{"filedetails/*.json"}}
+	transport := NewFileCachingTransport(config, http.DefaultTransport)
+
+	tests := []struct {
+		name        string
+		url         string
+		cachedAt    time.Time
+		wantExpired bool
+	}{
+		{"matching entry from an earlier run", "https://api.mmoui.com/v4/game/WOW/filedetails/123.json", transport.runStart.Add(-time.Hour), true},
+		{"matching entry from this run", "https://api.mmoui.com/v4/game/WOW/filedetails/456.json", transport.runStart.Add(time.Second), false},
+		{"other entry", "https://www.wowinterface.com/downloads/info123", transport.runStart.Add(-time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			cacheKey := transport.makeCacheKey(req)
+			path := filepath.Join(config.Directory, cacheKey)
+			if err := os.WriteFile(path, []byte("cached"), 0644); err != nil {
+				t.Fatalf("failed to write cache entry: %v", err)
+			}
+			if err := os.Chtimes(path, tt.cachedAt, tt.cachedAt); err != nil {
+				t.Fatalf("failed to set cache entry time: %v", err)
+			}
+
+			if got := transport.cacheExpired(req, cacheKey); got != tt.wantExpired {
+				t.Errorf("cacheExpired() = %v, want %v", got, tt.wantExpired)
+			}
+		})
+	}
+}
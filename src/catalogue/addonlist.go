@@ -0,0 +1,72 @@
+package catalogue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// AddonList selects addons by source-id or name, per source. It's used as a blocklist to keep known-malicious,
+// spam or duplicate addons out of catalogues and as an allowlist to build small curated catalogues.
+//
+//	{
+//	  "wowinterface": {"source-id-list": ["12345"], "name-list": ["free-gold-*"]},
+//	  "github": {"source-id-list": ["someone/spam-addon"]}
+//	}
+//
+// Name patterns are matched against the addon's name using path.Match syntax.
+type AddonList map[types.Source]AddonListEntry
+
+// AddonListEntry lists the addons selected within a single source
+type AddonListEntry struct {
+	SourceIDList []string `json:"source-id-list,omitempty"`
+	NameList     []string `json:"name-list,omitempty"`
+}
+
+// Contains returns true if the addon's source-id or name is listed
+func (l AddonList) Contains(addon types.Addon) bool {
+	entry, ok := l[addon.Source]
+	if !ok {
+		return false
+	}
+
+	if slices.Contains(entry.SourceIDList, addon.SourceID) {
+		return true
+	}
+	for _, pattern := range entry.NameList {
+		if matched, _ := path.Match(pattern, addon.Name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadAddonList reads an addon list JSON file, rejecting unknown sources and malformed name patterns
+func ReadAddonList(filePath string) (AddonList, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read addon list %s: %w", filePath, err)
+	}
+
+	var list AddonList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse addon list %s: %w", filePath, err)
+	}
+
+	for source, entry := range list {
+		if !slices.Contains(types.AllSources, source) {
+			return nil, fmt.Errorf("unknown source in addon list %s: %s", filePath, source)
+		}
+		for _, pattern := range entry.NameList {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("bad name pattern in addon list %s: %q: %w", filePath, pattern, err)
+			}
+		}
+	}
+
+	return list, nil
+}
@@ -0,0 +1,109 @@
+package catalogue
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func writeTestAddonList(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "blocklist.json")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write addon list: %v", err)
+	}
+	return path
+}
+
+func TestAddonList_Contains(t *testing.T) {
+	list := AddonList{
+		types.WowInterfaceSource: {
+			SourceIDList: []string{"12345"},
+			NameList:     []string{"free-gold-*"},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		addon types.Addon
+		want  bool
+	}{
+		{"listed source-id", types.Addon{Source: types.WowInterfaceSource, SourceID: "12345", Name: "fine"}, true},
+		{"matching name", types.Addon{Source: types.WowInterfaceSource, SourceID: "1", Name: "free-gold-now"}, true},
+		{"unlisted", types.Addon{Source: types.WowInterfaceSource, SourceID: "1", Name: "fine"}, false},
+		{"other source", types.Addon{Source: types.GitHubSource, SourceID: "12345", Name: "free-gold-now"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := list.Contains(tt.addon); got != tt.want {
+				t.Errorf("Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadAddonList(t *testing.T) {
+	path := writeTestAddonList(t, `{"github": {"source-id-list": ["someone/spam"], "name-list": ["spam-*"]}}`)
+	list, err := ReadAddonList(path)
+	if err != nil {
+		t.Fatalf("ReadAddonList() unexpected error: %v", err)
+	}
+	if !list.Contains(types.Addon{Source: types.GitHubSource, SourceID: "someone/spam"}) {
+		t.Error("Expected listed GitHub addon to be contained")
+	}
+
+	errorTests := []struct {
+		contents string
+		wantErr  string
+	}{
+		{`{"curseforge": {"source-id-list": ["1"]}}`, "unknown source"},
+		{`{"github": {"name-list": ["[unclosed"]}}`, "bad name pattern"},
+		{`[]`, "failed to parse"},
+	}
+	for _, tt := range errorTests {
+		_, err := ReadAddonList(writeTestAddonList(t, tt.contents))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ReadAddonList(%s) error = %v, want error containing %q", tt.contents, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBuilder_BuildCatalogue_AddonLists(t *testing.T) {
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	addons := []types.Addon{
+		{Source: types.WowInterfaceSource, SourceID: "1", Name: "good", UpdatedDate: updated},
+		{Source: types.WowInterfaceSource, SourceID: "2", Name: "free-gold-now", UpdatedDate: updated},
+		{Source: types.GitHubSource, SourceID: "someone/curated", Name: "curated", UpdatedDate: updated},
+	}
+
+	builder := NewBuilder()
+	if err := builder.LoadBlocklist(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Fatalf("LoadBlocklist() with a missing file unexpected error: %v", err)
+	}
+	if err := builder.LoadBlocklist(writeTestAddonList(t, `{"wowinterface": {"name-list": ["free-gold-*"]}}`)); err != nil {
+		t.Fatalf("LoadBlocklist() unexpected error: %v", err)
+	}
+
+	cat := builder.BuildCatalogue(addons, nil)
+	if cat.Total != 2 {
+		t.Errorf("Total with blocklist = %d, want 2", cat.Total)
+	}
+
+	if err := builder.LoadAllowlist(writeTestAddonList(t, `{"github": {"source-id-list": ["someone/curated"]}}`)); err != nil {
+		t.Fatalf("LoadAllowlist() unexpected error: %v", err)
+	}
+
+	cat = builder.BuildCatalogue(addons, nil)
+	if cat.Total != 1 || cat.AddonSummaryList[0].SourceID != "someone/curated" {
+		t.Errorf("BuildCatalogue() with allowlist = %v, want only someone/curated", cat.AddonSummaryList)
+	}
+
+	if err := builder.LoadAllowlist(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadAllowlist() with a missing file expected an error")
+	}
+}
@@ -0,0 +1,89 @@
+package catalogue
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// AuthorEntry is an author and the addons they've published to a source
+type AuthorEntry struct {
+	AuthorID     string       `json:"author-id"`
+	Name         string       `json:"name,omitempty"`
+	Source       types.Source `json:"source"`
+	SourceIDList []string     `json:"source-id-list"`
+	URL          string       `json:"url"`
+}
+
+// Authors lists the addons of each author, for "more from this author" views without bloating the catalogues themselves.
+type Authors struct {
+	Datestamp  string        `json:"datestamp"`
+	Total      int           `json:"total"`
+	AuthorList []AuthorEntry `json:"author-list"`
+}
+
+// BuildAuthors merges what pages said about the authors of source's addons into an entry per author.
+// Only addons in addons that aren't blocklisted are listed, authors without any are left out.
+// Authors are sorted by name then ID, their addons by source-id.
+func (b *Builder) BuildAuthors(source types.Source, authorData []types.AuthorData, addons []types.Addon) Authors {
+	listed := make(map[string]bool, len(addons))
+	for _, addon := range addons {
+		if addon.Source == source && !b.Excluded(addon) {
+			listed[addon.SourceID] = true
+		}
+	}
+
+	entries := make(map[string]*AuthorEntry)
+	for _, data := range authorData {
+		entry, ok := entries[data.AuthorID]
+		if !ok {
+			entry = &AuthorEntry{AuthorID: data.AuthorID, Source: source, URL: data.URL}
+			entries[data.AuthorID] = entry
+		}
+		if entry.Name == "" {
+			entry.Name = data.Name
+		}
+		for _, sourceID := range data.SourceIDs {
+			if listed[sourceID] && !slices.Contains(entry.SourceIDList, sourceID) {
+				entry.SourceIDList = append(entry.SourceIDList, sourceID)
+			}
+		}
+	}
+
+	authors := Authors{Datestamp: b.currentDateStamp(), AuthorList: []AuthorEntry{}}
+	for _, entry := range entries {
+		if len(entry.SourceIDList) == 0 {
+			continue
+		}
+		slices.SortFunc(entry.SourceIDList, compareSourceIDs)
+		authors.AuthorList = append(authors.AuthorList, *entry)
+	}
+	slices.SortFunc(authors.AuthorList, func(a, b AuthorEntry) int {
+		return cmp.Or(strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)), compareSourceIDs(a.AuthorID, b.AuthorID))
+	})
+	authors.Total = len(authors.AuthorList)
+	return authors
+}
+
+// compareSourceIDs orders numeric IDs by value, shorter IDs first
+func compareSourceIDs(a, b string) int {
+	return cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b))
+}
+
+// WriteAuthors writes authors as indented JSON
+func WriteAuthors(authors Authors, path string) error {
+	data, err := json.MarshalIndent(authors, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal authors: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write authors to %s: %w", path, err)
+	}
+	return nil
+}
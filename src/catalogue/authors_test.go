@@ -0,0 +1,48 @@
+package catalogue
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestBuilder_BuildAuthors(t *testing.T) {
+	builder := NewBuilder()
+	if err := builder.SetDatestamp("2024-01-02"); err != nil {
+		t.Fatalf("SetDatestamp() unexpected error: %v", err)
+	}
+	if err := builder.LoadBlocklist(writeTestAddonList(t, `{"wowinterface": {"source-id-list": ["4"]}}`)); err != nil {
+		t.Fatalf("LoadBlocklist() unexpected error: %v", err)
+	}
+
+	addons := []types.Addon{
+		{Source: types.WowInterfaceSource, SourceID: "10"},
+		{Source: types.WowInterfaceSource, SourceID: "9"},
+		{Source: types.WowInterfaceSource, SourceID: "4"},
+		{Source: types.GitHubSource, SourceID: "3"},
+	}
+	authorData := []types.AuthorData{
+		// from detail pages
+		{AuthorID: "200", Name: "Zed", SourceIDs: []string{"10"}, URL: "https://www.wowinterface.com/downloads/author-200.html"},
+		{AuthorID: "100", Name: "amy", SourceIDs: []string{"10"}, URL: "https://www.wowinterface.com/downloads/author-100.html"},
+		// from an author page, listing an addon that's been removed since
+		{AuthorID: "200", SourceIDs: []string{"10", "9", "404"}, URL: "https://www.wowinterface.com/downloads/author-200.html"},
+		// only blocklisted and other source's addons
+		{AuthorID: "300", Name: "Spammer", SourceIDs: []string{"4", "3"}, URL: "https://www.wowinterface.com/downloads/author-300.html"},
+	}
+
+	authors := builder.BuildAuthors(types.WowInterfaceSource, authorData, addons)
+
+	want := Authors{
+		Datestamp: "2024-01-02",
+		Total:     2,
+		AuthorList: []AuthorEntry{
+			{AuthorID: "100", Name: "amy", Source: types.WowInterfaceSource, SourceIDList: []string{"10"}, URL: "https://www.wowinterface.com/downloads/author-100.html"},
+			{AuthorID: "200", Name: "Zed", Source: types.WowInterfaceSource, SourceIDList: []string{"9", "10"}, URL: "https://www.wowinterface.com/downloads/author-200.html"},
+		},
+	}
+	if !reflect.DeepEqual(authors, want) {
+		t.Errorf("BuildAuthors() = %+v, want %+v", authors, want)
+	}
+}
@@ -1,31 +1,140 @@
 package catalogue
 
 import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
 	"sort"
 	"time"
 
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/tags"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
 )
 
 // Builder handles building catalogues from addon data
-type Builder struct{}
+type Builder struct {
+	blocklist AddonList
+	allowlist AddonList // nil includes every addon not blocklisted
+	overrides Overrides
+
+	specVersion int    // of the catalogues built, see SetSpecVersion
+	datestamp   string // of the catalogues built, today if empty, see SetDatestamp
+
+	unknownGameTracks bool // leave addons without a detected game track unclassified, see SetUnknownGameTracks
+	popularity        bool // rank addons by download count within their source, see SetPopularity
+
+	mergeStrategies map[string]MergeStrategy // by field, see SetMergeStrategy
+}
 
 // NewBuilder creates a new catalogue builder
 func NewBuilder() *Builder {
-	return &Builder{}
+	return &Builder{specVersion: types.DefaultSpecVersion, mergeStrategies: maps.Clone(DefaultMergeStrategies)}
+}
+
+// SetSpecVersion selects the catalogue spec version catalogues are built in, 0 for the default.
+// Fields added by later versions are left out of catalogues built in earlier ones.
+func (b *Builder) SetSpecVersion(version int) error {
+	if version == 0 {
+		version = types.DefaultSpecVersion
+	}
+	if !slices.Contains(types.KnownSpecVersions, version) {
+		return fmt.Errorf("unknown catalogue spec version: %d", version)
+	}
+	b.specVersion = version
+	return nil
+}
+
+// SetUnknownGameTracks sets whether addons no game track was detected for are left with an empty game-track-list,
+// meaning unclassified, rather than assumed to be retail
+func (b *Builder) SetUnknownGameTracks(unknown bool) {
+	b.unknownGameTracks = unknown
+}
+
+// SetPopularity sets whether catalogues built give each addon with a download count a popularity,
+// the percentile of its download count within its source
+func (b *Builder) SetPopularity(popularity bool) {
+	b.popularity = popularity
+}
+
+// LoadBlocklist excludes the addons listed in the file at path from catalogues.
+// A missing file is not an error, there is simply nothing to exclude.
+func (b *Builder) LoadBlocklist(path string) error {
+	list, err := ReadAddonList(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	b.blocklist = list
+	return nil
+}
+
+// LoadAllowlist excludes every addon not listed in the file at path from catalogues.
+// The blocklist still applies to allowlisted addons.
+func (b *Builder) LoadAllowlist(path string) error {
+	list, err := ReadAddonList(path)
+	if err != nil {
+		return err
+	}
+	b.allowlist = list
+	return nil
+}
+
+// LoadOverrides patches the addons listed in the file at path when catalogues are built.
+// A missing file is not an error, there is simply nothing to patch.
+func (b *Builder) LoadOverrides(path string) error {
+	overrides, err := ReadOverrides(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	b.overrides = overrides
+	return nil
+}
+
+// CheckOverrides returns the keys of the loaded overrides that change an addon in addons and those that are stale.
+// See Overrides.Check.
+func (b *Builder) CheckOverrides(addons []types.Addon) (applied []string, stale []string) {
+	return b.overrides.Check(addons)
+}
+
+// Excluded returns true if the addon is blocklisted or, with an allowlist loaded, not allowlisted
+func (b *Builder) Excluded(addon types.Addon) bool {
+	if b.blocklist.Contains(addon) {
+		return true
+	}
+	return b.allowlist != nil && !b.allowlist.Contains(addon)
 }
 
 // MergeAddonData merges multiple AddonData items for the same addon into a single Addon
 // This is a pure function that follows the merge strategy from the Clojure version
 func (b *Builder) MergeAddonData(addonDataList []types.AddonData) (*types.Addon, error) {
+	return b.merge(addonDataList, nil)
+}
+
+// MergeAddonDataProvenance merges addonDataList like MergeAddonData, also returning the file each field of the
+// merged addon came from
+func (b *Builder) MergeAddonDataProvenance(addonDataList []types.AddonData) (*types.Addon, Provenance, error) {
+	provenance := make(Provenance)
+	merged, err := b.merge(addonDataList, provenance)
+	if merged == nil || err != nil {
+		return merged, nil, err
+	}
+	return merged, provenance, nil
+}
+
+// merge merges addonDataList into a single Addon, recording where its fields came from in provenance unless nil
+func (b *Builder) merge(addonDataList []types.AddonData, provenance Provenance) (*types.Addon, error) {
 	if len(addonDataList) == 0 {
 		return nil, nil
 	}
 
-	// Sort by filename priority: listing < web-detail < api-detail
-	sort.Slice(addonDataList, func(i, j int) bool {
-		return b.getFilePriority(addonDataList[i].Filename) < b.getFilePriority(addonDataList[j].Filename)
-	})
+	addonDataList = b.MergeOrder(addonDataList)
 
 	// Start with empty addon and merge data in priority order
 	merged := &types.Addon{
@@ -33,94 +142,261 @@ func (b *Builder) MergeAddonData(addonDataList []types.AddonData) (*types.Addon,
 		SourceID: addonDataList[0].SourceID,
 	}
 
+	// Removed and pending addons can't be installed, abandoned addons can but are archived
+	switch MergedStatus(addonDataList) {
+	case types.RemovedStatus, types.PendingStatus:
+		return nil, nil
+	case types.AbandonedStatus:
+		merged.Archived = true
+	}
+
 	gameTrackSet := make(map[types.GameTrack]bool)
 	tagSet := make(map[string]bool)
+	interfaceVersions := make(map[types.GameTrack]map[int]bool)
+
+	// Text fields whose strategy can be set
+	texts := []struct {
+		field  string
+		merged *string
+		value  func(types.AddonData) string
+	}{
+		{"name", &merged.Name, func(d types.AddonData) string { return d.Name }},
+		{"label", &merged.Label, func(d types.AddonData) string { return d.Label }},
+		{"author", &merged.Author, func(d types.AddonData) string { return d.Author }},
+		{"description", &merged.Description, func(d types.AddonData) string { return d.Description }},
+		{"changelog", &merged.Changelog, func(d types.AddonData) string { return d.Changelog }},
+	}
 
 	for _, data := range addonDataList {
-		// Merge basic fields (later entries override earlier ones)
-		if data.Name != "" {
-			merged.Name = data.Name
-		}
-		if data.Label != "" {
-			merged.Label = data.Label
+		filename := data.Filename() // named in the provenance
+		// Merge basic fields (later entries override earlier ones, unless the field's strategy says otherwise)
+		for _, text := range texts {
+			if value := text.value(data); value != "" && keepText(b.mergeStrategy(text.field), *text.merged, value) {
+				*text.merged = value
+				provenance.set(text.field, filename)
+			}
 		}
-		if data.Description != "" {
-			merged.Description = data.Description
+		if len(data.ImageList) > 0 {
+			merged.ImageURL = data.ImageList[0].URL
+			provenance.set("image-url", filename)
 		}
 		if data.URL != "" {
 			merged.URL = data.URL
+			provenance.set("url", filename)
+		}
+		if len(data.LatestReleaseSet) > 0 {
+			merged.ReleaseList = data.LatestReleaseSet
+			provenance.set("release-list", filename)
+		}
+		if len(data.FolderList) > 0 {
+			merged.FolderList = data.FolderList
+			provenance.set("folder-list", filename)
+		}
+		if len(data.DependencyList) > 0 {
+			merged.DependencyList = data.DependencyList
+			provenance.set("dependency-list", filename)
 		}
 
 		// Merge dates (prefer non-zero values)
-		if data.UpdatedDate != nil && !data.UpdatedDate.IsZero() {
+		if data.UpdatedDate != nil && !data.UpdatedDate.IsZero() && keepDate(b.mergeStrategy("updated-date"), merged.UpdatedDate, *data.UpdatedDate) {
 			merged.UpdatedDate = *data.UpdatedDate
+			provenance.set("updated-date", filename)
 		}
-		if data.CreatedDate != nil && !data.CreatedDate.IsZero() {
+		if data.CreatedDate != nil && !data.CreatedDate.IsZero() && (merged.CreatedDate == nil || keepDate(b.mergeStrategy("created-date"), *merged.CreatedDate, *data.CreatedDate)) {
 			merged.CreatedDate = data.CreatedDate
+			provenance.set("created-date", filename)
+		}
+
+		// An addon seen in any archived section stays archived
+		if data.Archived || data.Status == types.AbandonedStatus {
+			merged.Archived = true
+			provenance.add("archived", filename)
 		}
 
 		// Merge download count (prefer non-zero values)
-		if data.DownloadCount != nil && *data.DownloadCount > 0 {
+		if data.DownloadCount != nil && *data.DownloadCount > 0 && keepCount(b.mergeStrategy("download-count"), merged.DownloadCount, *data.DownloadCount) {
 			merged.DownloadCount = data.DownloadCount
+			provenance.set("download-count", filename)
+		}
+		if data.FavoriteCount != nil && keepCount(b.mergeStrategy("favorite-count"), merged.FavoriteCount, *data.FavoriteCount) {
+			merged.FavoriteCount = data.FavoriteCount
+			provenance.set("favorite-count", filename)
+		}
+		if data.MonthlyDownloadCount != nil && keepCount(b.mergeStrategy("monthly-download-count"), merged.MonthlyDownloadCount, *data.MonthlyDownloadCount) {
+			merged.MonthlyDownloadCount = data.MonthlyDownloadCount
+			provenance.set("monthly-download-count", filename)
 		}
 
-		// Accumulate game tracks
+		// Accumulate game tracks, or take the last file's
+		if len(data.GameTrackSet) > 0 && b.mergeStrategy("game-track-list") == PreferLast {
+			clear(gameTrackSet)
+			delete(provenance, "game-track-list")
+		}
 		for track := range data.GameTrackSet {
 			gameTrackSet[track] = true
+			provenance.add("game-track-list", filename)
 		}
 
-		// Accumulate tags
+		// Accumulate tags, or take the last file's
+		if len(data.TagSet) > 0 && b.mergeStrategy("tag-list") == PreferLast {
+			clear(tagSet)
+			delete(provenance, "tag-list")
+		}
 		for tag := range data.TagSet {
 			tagSet[tag] = true
+			provenance.add("tag-list", filename)
+		}
+
+		// Accumulate interface versions
+		for track, versions := range data.InterfaceVersions {
+			if interfaceVersions[track] == nil {
+				interfaceVersions[track] = make(map[int]bool)
+			}
+			for _, version := range versions {
+				interfaceVersions[track][version] = true
+				provenance.add("interface-list", filename)
+			}
 		}
 	}
 
+	merged.ReleaseList = withInterfaceVersions(merged.ReleaseList, interfaceVersions)
+
 	// Convert sets to sorted slices
 	merged.GameTrackList = b.gameTrackSetToSortedSlice(gameTrackSet)
-	merged.TagList = b.stringSetToSortedSlice(tagSet)
+	merged.TagList = tags.Normalise(merged.Source, b.stringSetToSortedSlice(tagSet))
 
 	// Apply defaults and validation
 	if merged.UpdatedDate.IsZero() {
 		return nil, nil // Invalid addon without update date
 	}
 
-	if len(merged.GameTrackList) == 0 {
+	if len(merged.GameTrackList) == 0 && !b.unknownGameTracks {
 		merged.GameTrackList = []types.GameTrack{types.RetailTrack} // Default to retail
 	}
 
 	return merged, nil
 }
 
-// BuildCatalogue creates a catalogue from a list of addons
+// MergeOrder returns a copy of addonDataList in the order MergeAddonData merges it, by the priority of its kind:
+// listing < web-detail < api-filelist < api-detail. Later files override the single-valued fields of earlier ones.
+func (b *Builder) MergeOrder(addonDataList []types.AddonData) []types.AddonData {
+	ordered := slices.Clone(addonDataList)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return b.getDataPriority(ordered[i].Kind) < b.getDataPriority(ordered[j].Kind)
+	})
+	return ordered
+}
+
+// withInterfaceVersions returns releases with the interface versions supported by their game track.
+// A release without a game track is the addon's only download, supporting every interface version, or its retail download.
+func withInterfaceVersions(releases []types.Release, interfaceVersions map[types.GameTrack]map[int]bool) []types.Release {
+	if len(releases) == 0 || len(interfaceVersions) == 0 {
+		return releases
+	}
+
+	updated := make([]types.Release, len(releases))
+	for i, release := range releases {
+		versionSet := interfaceVersions[release.GameTrack]
+		if release.GameTrack == "" {
+			versionSet = interfaceVersions[types.RetailTrack]
+			if len(releases) == 1 {
+				versionSet = make(map[int]bool)
+				for _, versions := range interfaceVersions {
+					for version := range versions {
+						versionSet[version] = true
+					}
+				}
+			}
+		}
+
+		release.InterfaceList = nil
+		for version := range versionSet {
+			release.InterfaceList = append(release.InterfaceList, version)
+		}
+		sort.Ints(release.InterfaceList)
+		updated[i] = release
+	}
+	return updated
+}
+
+// statusSeverity orders addon statuses, the most severe status any source reports wins
+var statusSeverity = map[types.AddonStatus]int{
+	types.ActiveStatus:    0,
+	types.AbandonedStatus: 1,
+	types.PendingStatus:   2,
+	types.RemovedStatus:   3,
+}
+
+// MergedStatus returns the status of an addon from the status reported for it by each file.
+// An empty status is taken as active.
+func MergedStatus(addonDataList []types.AddonData) types.AddonStatus {
+	status := types.ActiveStatus
+	for _, data := range addonDataList {
+		if statusSeverity[data.Status] > statusSeverity[status] {
+			status = data.Status
+		}
+	}
+	return status
+}
+
+// SetDatestamp fixes the datestamp of the catalogues built, so rebuilding from the same addons gives the same output.
+// An empty datestamp uses today's date.
+func (b *Builder) SetDatestamp(datestamp string) error {
+	if datestamp != "" {
+		if _, err := time.Parse(DatestampFormat, datestamp); err != nil {
+			return fmt.Errorf("invalid datestamp %q, expected YYYY-MM-DD: %w", datestamp, err)
+		}
+	}
+	b.datestamp = datestamp
+	return nil
+}
+
+// BuildCatalogue creates a catalogue from a list of addons.
+// Overrides are applied and then addons excluded by the blocklist or allowlist are left out.
 func (b *Builder) BuildCatalogue(addons []types.Addon, sources []types.Source) types.Catalogue {
 	var filteredAddons []types.Addon
 
 	// Filter by sources if specified
-	if len(sources) > 0 {
-		sourceMap := make(map[types.Source]bool)
-		for _, source := range sources {
-			sourceMap[source] = true
-		}
+	sourceMap := make(map[types.Source]bool)
+	for _, source := range sources {
+		sourceMap[source] = true
+	}
 
-		for _, addon := range addons {
-			if sourceMap[addon.Source] {
-				filteredAddons = append(filteredAddons, addon)
-			}
+	for _, addon := range addons {
+		if len(sources) > 0 && !sourceMap[addon.Source] {
+			continue
+		}
+		addon, _ = b.overrides.Apply(addon)
+		if b.Excluded(addon) {
+			continue
+		}
+		if b.specVersion < types.SpecVersion3 {
+			addon.Author = ""
+			addon.FolderList = nil
+			addon.ReleaseList = nil
 		}
-	} else {
-		filteredAddons = addons
+		filteredAddons = append(filteredAddons, addon)
+	}
+
+	// Ranked against the addons actually in the catalogue
+	if b.popularity {
+		rankPopularity(filteredAddons)
 	}
 
 	// Sort addons by source-id for stable, deterministic output
-	// source-id changes less frequently than name (which can vary with slugification)
+	// source-id changes less frequently than name (which can vary with slugification).
+	// Sources scrape concurrently, so ids shared by two sources are ordered by source.
 	sort.Slice(filteredAddons, func(i, j int) bool {
-		return filteredAddons[i].SourceID < filteredAddons[j].SourceID
+		if filteredAddons[i].SourceID != filteredAddons[j].SourceID {
+			return filteredAddons[i].SourceID < filteredAddons[j].SourceID
+		}
+		return filteredAddons[i].Source < filteredAddons[j].Source
 	})
 
 	return types.Catalogue{
 		Spec: struct {
 			Version int `json:"version"`
-		}{Version: 2},
+		}{Version: b.specVersion},
 		Datestamp:        b.currentDateStamp(),
 		Total:            len(filteredAddons),
 		AddonSummaryList: filteredAddons,
@@ -128,11 +404,17 @@ func (b *Builder) BuildCatalogue(addons []types.Addon, sources []types.Source) t
 }
 
 // ShortenCatalogue filters out unmaintained addons (similar to Clojure version)
-func (b *Builder) ShortenCatalogue(catalogue types.Catalogue, cutoffDate time.Time) types.Catalogue {
+// Archived addons are always excluded, regardless of their updated date.
+// A relative cutoff is relative to the catalogue's datestamp.
+func (b *Builder) ShortenCatalogue(catalogue types.Catalogue, policy ShortPolicy) types.Catalogue {
 	var maintainedAddons []types.Addon
+	cutoffDate := policy.CutoffDate(catalogue)
 
 	for _, addon := range catalogue.AddonSummaryList {
-		if addon.UpdatedDate.After(cutoffDate) {
+		if addon.Archived {
+			continue
+		}
+		if policy.keeps(addon, cutoffDate) {
 			maintainedAddons = append(maintainedAddons, addon)
 		}
 	}
@@ -165,20 +447,19 @@ func (b *Builder) FilterCatalogue(catalogue types.Catalogue, predicate func(type
 
 // Private helper methods
 
-// getFilePriority returns priority for merge order (lower = higher priority)
-func (b *Builder) getFilePriority(filename string) int {
-	switch {
-	case filename == "listing.json":
-		return 0 // lowest priority
-	case filename == "web-detail.json":
-		return 1 // medium priority
-	case filename == "api-detail.json":
-		return 2 // highest priority
-	case filename == "api-filelist.json":
-		return 2 // same as api-detail
-	default:
-		return 0 // default to lowest priority
-	}
+// dataPriorities are the merge priorities of each kind of addon data. Data with a higher priority is merged later,
+// overriding data with a lower one. An addon's API detail is more specific than its filelist entry.
+var dataPriorities = map[types.DataKind]int{
+	types.ListingData:     0,
+	types.WebDetailData:   1,
+	types.APIFileListData: 2,
+	types.APIDetailData:   3,
+}
+
+// getDataPriority returns priority for merge order (lower = merged first, overridden by higher).
+// Unknown kinds have the lowest priority.
+func (b *Builder) getDataPriority(kind types.DataKind) int {
+	return dataPriorities[kind]
 }
 
 // gameTrackSetToSortedSlice converts a set to a sorted slice
@@ -211,7 +492,10 @@ func (b *Builder) stringSetToSortedSlice(stringSet map[string]bool) []string {
 	return strings
 }
 
-// currentDateStamp returns current date in YYYY-MM-DD format
+// currentDateStamp returns the datestamp set with SetDatestamp, or the current date, in YYYY-MM-DD format
 func (b *Builder) currentDateStamp() string {
-	return time.Now().Format("2006-01-02")
+	if b.datestamp != "" {
+		return b.datestamp
+	}
+	return time.Now().Format(DatestampFormat)
 }
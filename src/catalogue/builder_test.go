@@ -1,6 +1,7 @@
 package catalogue
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
@@ -14,7 +15,7 @@ func TestBuilder_MergeAddonData(t *testing.T) {
 	listingData := types.AddonData{
 		Source:        types.WowInterfaceSource,
 		SourceID:      "12345",
-		Filename:      "listing.json",
+		Kind:          types.ListingData,
 		Name:          "test-addon",
 		Label:         "Test Addon",
 		DownloadCount: intPtr(100),
@@ -26,9 +27,12 @@ func TestBuilder_MergeAddonData(t *testing.T) {
 	webDetailData := types.AddonData{
 		Source:      types.WowInterfaceSource,
 		SourceID:    "12345",
-		Filename:    "web-detail.json",
+		Kind:        types.WebDetailData,
 		Description: "A test addon for unit testing",
 		URL:         "https://www.wowinterface.com/downloads/info12345",
+		DependencyList: []types.Dependency{
+			{Label: "LibStub", Required: true, SourceID: "5547", URL: "https://www.wowinterface.com/downloads/info5547-LibStub.html"},
+		},
 		GameTrackSet: map[types.GameTrack]bool{
 			types.RetailTrack:  true,
 			types.ClassicTrack: true,
@@ -36,10 +40,12 @@ func TestBuilder_MergeAddonData(t *testing.T) {
 	}
 
 	apiDetailData := types.AddonData{
-		Source:      types.WowInterfaceSource,
-		SourceID:    "12345",
-		Filename:    "api-detail.json",
-		UpdatedDate: timePtr(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)),
+		Source:               types.WowInterfaceSource,
+		SourceID:             "12345",
+		Kind:                 types.APIDetailData,
+		UpdatedDate:          timePtr(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)),
+		FavoriteCount:        intPtr(0),
+		MonthlyDownloadCount: intPtr(33),
 		TagSet: map[string]bool{
 			"bags":      true,
 			"inventory": true,
@@ -87,6 +93,15 @@ func TestBuilder_MergeAddonData(t *testing.T) {
 				if *addon.DownloadCount != 100 {
 					t.Errorf("DownloadCount = %d, want 100", *addon.DownloadCount)
 				}
+				if addon.FavoriteCount == nil || *addon.FavoriteCount != 0 {
+					t.Errorf("FavoriteCount = %v, want 0", addon.FavoriteCount)
+				}
+				if addon.MonthlyDownloadCount == nil || *addon.MonthlyDownloadCount != 33 {
+					t.Errorf("MonthlyDownloadCount = %v, want 33", addon.MonthlyDownloadCount)
+				}
+				if len(addon.DependencyList) != 1 || addon.DependencyList[0].SourceID != "5547" {
+					t.Errorf("DependencyList = %+v, want LibStub", addon.DependencyList)
+				}
 				if len(addon.GameTrackList) != 2 {
 					t.Errorf("GameTrackList length = %d, want 2", len(addon.GameTrackList))
 				}
@@ -122,6 +137,79 @@ func TestBuilder_MergeAddonData(t *testing.T) {
 	}
 }
 
+func TestBuilder_MergeAddonDataProvenance(t *testing.T) {
+	builder := NewBuilder()
+	updated := timePtr(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	addonData := []types.AddonData{
+		{Source: types.WowInterfaceSource, SourceID: "12345", Kind: types.APIDetailData, Label: "Test Addon", UpdatedDate: updated,
+			TagSet: map[string]bool{"bags": true}},
+		{Source: types.WowInterfaceSource, SourceID: "12345", Kind: types.ListingData, Name: "test-addon", Label: "Test",
+			DownloadCount: intPtr(100), GameTrackSet: map[types.GameTrack]bool{types.RetailTrack: true}},
+		{Source: types.WowInterfaceSource, SourceID: "12345", Kind: types.WebDetailData, Description: "A test addon",
+			GameTrackSet: map[types.GameTrack]bool{types.ClassicTrack: true}, TagSet: map[string]bool{"inventory": true}},
+	}
+
+	addon, provenance, err := builder.MergeAddonDataProvenance(addonData)
+	if err != nil || addon == nil {
+		t.Fatalf("MergeAddonDataProvenance() = %v, %v", addon, err)
+	}
+	want := Provenance{
+		"name":            {"listing.json"},
+		"label":           {"api-detail.json"}, // overriding listing.json
+		"description":     {"web-detail.json"},
+		"updated-date":    {"api-detail.json"},
+		"download-count":  {"listing.json"},
+		"game-track-list": {"listing.json", "web-detail.json"},
+		"tag-list":        {"web-detail.json", "api-detail.json"},
+	}
+	if !reflect.DeepEqual(provenance, want) {
+		t.Errorf("Provenance = %v, want %v", provenance, want)
+	}
+
+	// The addon is the same either way
+	if plain, _ := builder.MergeAddonData(addonData); !reflect.DeepEqual(plain, addon) {
+		t.Errorf("MergeAddonData() = %+v, want %+v", plain, addon)
+	}
+
+	// Nothing came from anywhere when the addon is left out
+	if addon, provenance, err := builder.MergeAddonDataProvenance(addonData[1:]); addon != nil || provenance != nil || err != nil {
+		t.Errorf("MergeAddonDataProvenance() without an updated date = %v, %v, %v, want nothing", addon, provenance, err)
+	}
+}
+
+func TestBuilder_MergeOrder(t *testing.T) {
+	addonData := []types.AddonData{
+		{Source: types.WowInterfaceSource, SourceID: "1", Kind: types.APIDetailData, APIVersion: "v4"},
+		{Source: types.WowInterfaceSource, SourceID: "1", Kind: types.APIFileListData, APIVersion: "v3"},
+		{Source: types.WowInterfaceSource, SourceID: "1", Kind: types.WebDetailData},
+		{Source: types.WowInterfaceSource, SourceID: "1", Kind: "unknown"},
+		{Source: types.WowInterfaceSource, SourceID: "1", Kind: types.ListingData},
+		{Source: types.WowInterfaceSource, SourceID: "1", Kind: types.APIDetailData},
+	}
+
+	var got []string
+	for _, data := range NewBuilder().MergeOrder(addonData) {
+		got = append(got, data.Filename())
+	}
+	// Data of the same kind keeps its order
+	want := []string{"unknown.json", "listing.json", "web-detail.json", "api-filelist-v3.json", "api-detail-v4.json", "api-detail.json"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeOrder() = %v, want %v", got, want)
+	}
+	if addonData[0].Kind != types.APIDetailData {
+		t.Error("MergeOrder() reordered the addon data given")
+	}
+}
+
+func TestDataPriorities(t *testing.T) {
+	// A kind without a priority would silently be merged first
+	for _, kind := range types.KnownDataKinds {
+		if _, ok := dataPriorities[kind]; !ok {
+			t.Errorf("data kind %s has no merge priority", kind)
+		}
+	}
+}
+
 func TestBuilder_BuildCatalogue(t *testing.T) {
 	builder := NewBuilder()
 
@@ -236,7 +324,7 @@ func TestBuilder_ShortenCatalogue(t *testing.T) {
 
 	cutoffDate := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
 
-	result := builder.ShortenCatalogue(catalogue, cutoffDate)
+	result := builder.ShortenCatalogue(catalogue, ShortPolicy{Cutoff: Cutoff{date: cutoffDate}})
 
 	if result.Total != 1 {
 		t.Errorf("Shortened catalogue total = %d, want 1", result.Total)
@@ -251,6 +339,221 @@ func TestBuilder_ShortenCatalogue(t *testing.T) {
 	}
 }
 
+func TestBuilder_ShortenCatalogue_ExcludesArchived(t *testing.T) {
+	builder := NewBuilder()
+
+	archivedAddon := types.Addon{
+		Source:      types.WowInterfaceSource,
+		SourceID:    "12345",
+		Name:        "archived-addon",
+		Archived:    true,
+		UpdatedDate: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	activeAddon := types.Addon{
+		Source:      types.WowInterfaceSource,
+		SourceID:    "67890",
+		Name:        "active-addon",
+		UpdatedDate: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	catalogue := builder.BuildCatalogue([]types.Addon{archivedAddon, activeAddon}, nil)
+	result := builder.ShortenCatalogue(catalogue, ShortPolicy{Cutoff: Cutoff{date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}})
+
+	if result.Total != 1 {
+		t.Fatalf("Shortened catalogue total = %d, want 1", result.Total)
+	}
+
+	if result.AddonSummaryList[0].Name != "active-addon" {
+		t.Errorf("Remaining addon name = %s, want active-addon", result.AddonSummaryList[0].Name)
+	}
+}
+
+func TestBuilder_MergeAddonData_UnknownGameTracks(t *testing.T) {
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := []types.AddonData{{Source: types.WowInterfaceSource, SourceID: "1", Label: "Addon", UpdatedDate: &updated}}
+
+	tests := []struct {
+		unknown bool
+		want    []types.GameTrack
+	}{
+		{false, []types.GameTrack{types.RetailTrack}},
+		{true, []types.GameTrack{}},
+	}
+
+	for _, tt := range tests {
+		builder := NewBuilder()
+		builder.SetUnknownGameTracks(tt.unknown)
+		addon, err := builder.MergeAddonData(data)
+		if err != nil {
+			t.Fatalf("MergeAddonData() unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(addon.GameTrackList, tt.want) {
+			t.Errorf("SetUnknownGameTracks(%v) GameTrackList = %v, want %v", tt.unknown, addon.GameTrackList, tt.want)
+		}
+	}
+}
+
+func TestBuilder_MergeAddonData_Archived(t *testing.T) {
+	builder := NewBuilder()
+
+	addon, err := builder.MergeAddonData([]types.AddonData{
+		{
+			Source:   types.WowInterfaceSource,
+			SourceID: "12345",
+			Kind:     types.ListingData,
+			Label:    "Old Addon",
+			Name:     "old-addon",
+			Archived: true,
+		},
+		{
+			Source:      types.WowInterfaceSource,
+			SourceID:    "12345",
+			Kind:        types.WebDetailData,
+			UpdatedDate: timePtr(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("MergeAddonData() unexpected error: %v", err)
+	}
+
+	if addon == nil || !addon.Archived {
+		t.Errorf("Expected merged addon to be archived, got %+v", addon)
+	}
+}
+
+func TestBuilder_MergeAddonData_Status(t *testing.T) {
+	builder := NewBuilder()
+	updated := timePtr(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name         string
+		status       types.AddonStatus
+		wantIncluded bool
+		wantArchived bool
+	}{
+		{"unknown", "", true, false},
+		{"active", types.ActiveStatus, true, false},
+		{"abandoned", types.AbandonedStatus, true, true},
+		{"pending", types.PendingStatus, false, false},
+		{"removed", types.RemovedStatus, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The API still describes the addon, the page says what became of it
+			addon, err := builder.MergeAddonData([]types.AddonData{
+				{Source: types.WowInterfaceSource, SourceID: "12345", Kind: types.WebDetailData, Status: tt.status},
+				{Source: types.WowInterfaceSource, SourceID: "12345", Kind: types.APIDetailData, UpdatedDate: updated},
+			})
+			if err != nil {
+				t.Fatalf("MergeAddonData() unexpected error: %v", err)
+			}
+			if (addon != nil) != tt.wantIncluded {
+				t.Fatalf("MergeAddonData() = %+v, want included %v", addon, tt.wantIncluded)
+			}
+			if addon != nil && addon.Archived != tt.wantArchived {
+				t.Errorf("Archived = %v, want %v", addon.Archived, tt.wantArchived)
+			}
+		})
+	}
+}
+
+func TestBuilder_MergeAddonData_InterfaceVersions(t *testing.T) {
+	builder := NewBuilder()
+	updated := timePtr(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	filelist := types.AddonData{
+		Source:   types.WowInterfaceSource,
+		SourceID: "12345",
+		Kind:     types.APIFileListData, APIVersion: "v4",
+		InterfaceVersions: map[types.GameTrack][]int{
+			types.RetailTrack:     {110005, 110002},
+			types.ClassicTBCTrack: {20504},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		releases []types.Release
+		want     [][]int
+	}{
+		{"only release", []types.Release{{DownloadURL: "https://example.org/1"}}, [][]int{{20504, 110002, 110005}}},
+		{"release per game track", []types.Release{
+			{DownloadURL: "https://example.org/1"},
+			{DownloadURL: "https://example.org/2", GameTrack: types.ClassicTBCTrack},
+			{DownloadURL: "https://example.org/3", GameTrack: types.ClassicWotLKTrack},
+		}, [][]int{{110002, 110005}, {20504}, nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detail := types.AddonData{Source: types.WowInterfaceSource, SourceID: "12345", Kind: types.WebDetailData, UpdatedDate: updated, LatestReleaseSet: tt.releases}
+			addon, err := builder.MergeAddonData([]types.AddonData{filelist, detail})
+			if err != nil || addon == nil {
+				t.Fatalf("MergeAddonData() = %v, %v", addon, err)
+			}
+			for i, release := range addon.ReleaseList {
+				if !reflect.DeepEqual(release.InterfaceList, tt.want[i]) {
+					t.Errorf("ReleaseList[%d].InterfaceList = %v, want %v", i, release.InterfaceList, tt.want[i])
+				}
+			}
+			if tt.releases[0].InterfaceList != nil {
+				t.Error("MergeAddonData() modified the parsed releases")
+			}
+		})
+	}
+}
+
+func TestMergedStatus(t *testing.T) {
+	tests := []struct {
+		statuses []types.AddonStatus
+		want     types.AddonStatus
+	}{
+		{nil, types.ActiveStatus},
+		{[]types.AddonStatus{"", types.ActiveStatus}, types.ActiveStatus},
+		{[]types.AddonStatus{types.AbandonedStatus, ""}, types.AbandonedStatus},
+		{[]types.AddonStatus{types.AbandonedStatus, types.PendingStatus}, types.PendingStatus},
+		{[]types.AddonStatus{types.RemovedStatus, types.PendingStatus}, types.RemovedStatus},
+	}
+
+	for _, tt := range tests {
+		var addonDataList []types.AddonData
+		for _, status := range tt.statuses {
+			addonDataList = append(addonDataList, types.AddonData{Status: status})
+		}
+		if got := MergedStatus(addonDataList); got != tt.want {
+			t.Errorf("MergedStatus(%v) = %q, want %q", tt.statuses, got, tt.want)
+		}
+	}
+}
+
+func TestBuilder_MergeAddonData_ImageURL(t *testing.T) {
+	builder := NewBuilder()
+
+	addon, err := builder.MergeAddonData([]types.AddonData{
+		{
+			Source:      types.WowInterfaceSource,
+			SourceID:    "12345",
+			Kind:        types.WebDetailData,
+			UpdatedDate: timePtr(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+			ImageList:   []types.Image{{URL: "https://example.org/web.png"}},
+		},
+		{
+			Source:    types.WowInterfaceSource,
+			SourceID:  "12345",
+			Kind:      types.APIDetailData,
+			ImageList: []types.Image{{URL: "https://example.org/first.png"}, {URL: "https://example.org/second.png"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("MergeAddonData() unexpected error: %v", err)
+	}
+
+	if addon == nil || addon.ImageURL != "https://example.org/first.png" {
+		t.Errorf("Expected the first API image, got %+v", addon)
+	}
+}
+
 func TestBuilder_FilterCatalogue(t *testing.T) {
 	builder := NewBuilder()
 
@@ -310,3 +613,50 @@ func intPtr(i int) *int {
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
+
+func TestBuilder_SetSpecVersion(t *testing.T) {
+	updated := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	merged, err := NewBuilder().MergeAddonData([]types.AddonData{
+		{Source: types.WowInterfaceSource, SourceID: "1", Kind: types.WebDetailData, Label: "One", Author: "Someone", UpdatedDate: &updated},
+		{Source: types.WowInterfaceSource, SourceID: "1", Kind: types.APIDetailData,
+			LatestReleaseSet: []types.Release{{DownloadURL: "https://cdn.wowinterface.com/downloads/getfile.php?id=1", Checksum: "abc123"}}},
+		{Source: types.WowInterfaceSource, SourceID: "1", Kind: types.APIFileListData, APIVersion: "v3", FolderList: []string{"One", "One_Options"}},
+	})
+	if err != nil || merged == nil {
+		t.Fatalf("MergeAddonData() = %v, %v", merged, err)
+	}
+
+	tests := []struct {
+		version      int
+		wantVersion  int
+		wantAuthor   string
+		wantReleases int
+		wantFolders  int
+	}{
+		{0, types.SpecVersion2, "", 0, 0},
+		{types.SpecVersion2, types.SpecVersion2, "", 0, 0},
+		{types.SpecVersion3, types.SpecVersion3, "Someone", 1, 2},
+	}
+
+	for _, tt := range tests {
+		builder := NewBuilder()
+		if err := builder.SetSpecVersion(tt.version); err != nil {
+			t.Fatalf("SetSpecVersion(%d) unexpected error: %v", tt.version, err)
+		}
+		cat := builder.BuildCatalogue([]types.Addon{*merged}, nil)
+		if cat.Spec.Version != tt.wantVersion {
+			t.Errorf("SetSpecVersion(%d) spec version = %d, want %d", tt.version, cat.Spec.Version, tt.wantVersion)
+		}
+		addon := cat.AddonSummaryList[0]
+		if addon.Author != tt.wantAuthor || len(addon.ReleaseList) != tt.wantReleases {
+			t.Errorf("SetSpecVersion(%d) author = %q, releases = %d, want %q, %d", tt.version, addon.Author, len(addon.ReleaseList), tt.wantAuthor, tt.wantReleases)
+		}
+		if len(addon.FolderList) != tt.wantFolders {
+			t.Errorf("SetSpecVersion(%d) folders = %v, want %d", tt.version, addon.FolderList, tt.wantFolders)
+		}
+	}
+
+	if err := NewBuilder().SetSpecVersion(4); err == nil {
+		t.Error("SetSpecVersion(4) expected an error")
+	}
+}
@@ -0,0 +1,70 @@
+package catalogue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// ChangelogEntry is the latest changelog of an addon
+type ChangelogEntry struct {
+	Changelog   string       `json:"changelog"`
+	Label       string       `json:"label"`
+	Source      types.Source `json:"source"`
+	SourceID    string       `json:"source-id"`
+	UpdatedDate time.Time    `json:"updated-date"`
+}
+
+// Changelogs lists the latest changelog of each addon in a catalogue that has one,
+// for "what changed" views without bloating the catalogues themselves.
+type Changelogs struct {
+	Datestamp     string           `json:"datestamp"`
+	Total         int              `json:"total"`
+	ChangelogList []ChangelogEntry `json:"changelog-list"`
+}
+
+// ExtractChangelogs returns the changelogs of the catalogue's addons, in catalogue order
+func (b *Builder) ExtractChangelogs(catalogue types.Catalogue) Changelogs {
+	changelogs := Changelogs{Datestamp: catalogue.Datestamp, ChangelogList: []ChangelogEntry{}}
+	for _, addon := range catalogue.AddonSummaryList {
+		if addon.Changelog == "" {
+			continue
+		}
+		changelogs.ChangelogList = append(changelogs.ChangelogList, ChangelogEntry{
+			Changelog:   addon.Changelog,
+			Label:       addon.Label,
+			Source:      addon.Source,
+			SourceID:    addon.SourceID,
+			UpdatedDate: addon.UpdatedDate,
+		})
+	}
+	changelogs.Total = len(changelogs.ChangelogList)
+	return changelogs
+}
+
+// StripChangelogs returns a copy of the catalogue without addon changelogs
+func (b *Builder) StripChangelogs(catalogue types.Catalogue) types.Catalogue {
+	addons := make([]types.Addon, len(catalogue.AddonSummaryList))
+	for i, addon := range catalogue.AddonSummaryList {
+		addon.Changelog = ""
+		addons[i] = addon
+	}
+	catalogue.AddonSummaryList = addons
+	return catalogue
+}
+
+// WriteChangelogs writes changelogs as indented JSON
+func WriteChangelogs(changelogs Changelogs, path string) error {
+	data, err := json.MarshalIndent(changelogs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal changelogs: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write changelogs to %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package catalogue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestBuilder_ExtractChangelogs(t *testing.T) {
+	builder := NewBuilder()
+
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	withChangelog := types.Addon{Source: types.WowInterfaceSource, SourceID: "1", Label: "Addon", Changelog: "v1.1\n- fixes", UpdatedDate: updated}
+	withoutChangelog := types.Addon{Source: types.WowInterfaceSource, SourceID: "2", Label: "Other"}
+	catalogue := changesTestCatalogue("2024-01-02", withChangelog, withoutChangelog)
+
+	changelogs := builder.ExtractChangelogs(catalogue)
+
+	if changelogs.Datestamp != "2024-01-02" {
+		t.Errorf("Datestamp = %s, want 2024-01-02", changelogs.Datestamp)
+	}
+	if changelogs.Total != 1 || len(changelogs.ChangelogList) != 1 {
+		t.Fatalf("Total = %d, want 1: %+v", changelogs.Total, changelogs.ChangelogList)
+	}
+	expected := ChangelogEntry{Changelog: "v1.1\n- fixes", Label: "Addon", Source: types.WowInterfaceSource, SourceID: "1", UpdatedDate: updated}
+	if changelogs.ChangelogList[0] != expected {
+		t.Errorf("ChangelogList[0] = %+v, want %+v", changelogs.ChangelogList[0], expected)
+	}
+}
+
+func TestBuilder_StripChangelogs(t *testing.T) {
+	builder := NewBuilder()
+
+	catalogue := changesTestCatalogue("2024-01-02", types.Addon{SourceID: "1", Changelog: "v1.1"})
+	stripped := builder.StripChangelogs(catalogue)
+
+	if stripped.AddonSummaryList[0].Changelog != "" {
+		t.Errorf("Changelog = %q, want it stripped", stripped.AddonSummaryList[0].Changelog)
+	}
+	if catalogue.AddonSummaryList[0].Changelog != "v1.1" {
+		t.Error("StripChangelogs modified the original catalogue")
+	}
+	if stripped.Total != catalogue.Total || stripped.Datestamp != catalogue.Datestamp {
+		t.Errorf("StripChangelogs changed catalogue metadata: %+v", stripped)
+	}
+}
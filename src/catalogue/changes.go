@@ -0,0 +1,184 @@
+package catalogue
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// ChangeKind describes how an addon changed between two catalogues
+type ChangeKind string
+
+const (
+	AddonAdded   ChangeKind = "added"
+	AddonUpdated ChangeKind = "updated"
+	AddonRemoved ChangeKind = "removed"
+)
+
+// DefaultChangesRetention is how long changes are kept in a feed before being pruned
+const DefaultChangesRetention = 30 * 24 * time.Hour
+
+// Change is a single addon change detected between two catalogues
+type Change struct {
+	Change      ChangeKind   `json:"change"`
+	Datestamp   string       `json:"datestamp"` // datestamp of the catalogue the change was detected in
+	Label       string       `json:"label"`
+	Name        string       `json:"name"`
+	Source      types.Source `json:"source"`
+	SourceID    string       `json:"source-id"`
+	UpdatedDate *time.Time   `json:"updated-date,omitempty"`
+}
+
+// ChangesFeed lists addon changes across successive catalogues, newest first.
+// ETag identifies the catalogue content the feed is current with, so consumers
+// (and the next run) can tell whether anything changed without diffing catalogues.
+type ChangesFeed struct {
+	Datestamp  string   `json:"datestamp"`
+	ETag       string   `json:"etag"`
+	Total      int      `json:"total"`
+	ChangeList []Change `json:"change-list"`
+}
+
+// CatalogueETag returns a strong HTTP entity tag for the catalogue's addons.
+// The datestamp is excluded so an otherwise identical catalogue keeps its ETag across days.
+func CatalogueETag(catalogue types.Catalogue) (string, error) {
+	data, err := json.Marshal(catalogue.AddonSummaryList)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal addons: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`, nil
+}
+
+// DiffCatalogues returns the addons added, updated and removed going from previous to current.
+// An addon is updated when its updated-date changed. Changes are ordered by source and source-id.
+func (b *Builder) DiffCatalogues(previous, current types.Catalogue) []Change {
+	type addonKey struct {
+		source   types.Source
+		sourceID string
+	}
+
+	previousAddons := make(map[addonKey]types.Addon, len(previous.AddonSummaryList))
+	for _, addon := range previous.AddonSummaryList {
+		previousAddons[addonKey{addon.Source, addon.SourceID}] = addon
+	}
+
+	var changes []Change
+	for _, addon := range current.AddonSummaryList {
+		key := addonKey{addon.Source, addon.SourceID}
+		previousAddon, existed := previousAddons[key]
+		delete(previousAddons, key)
+
+		switch {
+		case !existed:
+			changes = append(changes, newChange(AddonAdded, current.Datestamp, addon))
+		case !previousAddon.UpdatedDate.Equal(addon.UpdatedDate):
+			changes = append(changes, newChange(AddonUpdated, current.Datestamp, addon))
+		}
+	}
+
+	// Whatever is left was removed
+	for _, addon := range previousAddons {
+		changes = append(changes, newChange(AddonRemoved, current.Datestamp, addon))
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Source != changes[j].Source {
+			return changes[i].Source < changes[j].Source
+		}
+		return changes[i].SourceID < changes[j].SourceID
+	})
+
+	return changes
+}
+
+// UpdateChangesFeed prepends the changes from previous to current onto the feed and prunes
+// changes older than retention. The feed is returned unmodified if its ETag matches current.
+// A nil previous catalogue (first run) records the ETag without listing every addon as added.
+func (b *Builder) UpdateChangesFeed(feed ChangesFeed, previous *types.Catalogue, current types.Catalogue, retention time.Duration) (ChangesFeed, error) {
+	etag, err := CatalogueETag(current)
+	if err != nil {
+		return feed, err
+	}
+
+	if feed.ETag == etag {
+		return feed, nil
+	}
+
+	var changes []Change
+	if previous != nil {
+		changes = b.DiffCatalogues(*previous, current)
+	}
+
+	// Keep recent changes from earlier runs so consumers polling less often than we publish don't miss any
+	cutoff := ""
+	if currentDate, err := time.Parse("2006-01-02", current.Datestamp); err == nil {
+		cutoff = currentDate.Add(-retention).Format("2006-01-02")
+	}
+	for _, change := range feed.ChangeList {
+		if change.Datestamp >= cutoff {
+			changes = append(changes, change)
+		}
+	}
+
+	if changes == nil {
+		changes = []Change{}
+	}
+
+	return ChangesFeed{
+		Datestamp:  current.Datestamp,
+		ETag:       etag,
+		Total:      len(changes),
+		ChangeList: changes,
+	}, nil
+}
+
+// ReadChangesFeed reads a changes feed, returning an empty feed if the file doesn't exist
+func ReadChangesFeed(path string) (ChangesFeed, error) {
+	var feed ChangesFeed
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return feed, nil
+	}
+	if err != nil {
+		return feed, fmt.Errorf("failed to read changes feed %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return feed, fmt.Errorf("failed to parse changes feed %s: %w", path, err)
+	}
+	return feed, nil
+}
+
+// WriteChangesFeed writes a changes feed as indented JSON
+func WriteChangesFeed(feed ChangesFeed, path string) error {
+	data, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal changes feed: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write changes feed to %s: %w", path, err)
+	}
+	return nil
+}
+
+func newChange(kind ChangeKind, datestamp string, addon types.Addon) Change {
+	updatedDate := addon.UpdatedDate
+	return Change{
+		Change:      kind,
+		Datestamp:   datestamp,
+		Label:       addon.Label,
+		Name:        addon.Name,
+		Source:      addon.Source,
+		SourceID:    addon.SourceID,
+		UpdatedDate: &updatedDate,
+	}
+}
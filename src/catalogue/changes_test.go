@@ -0,0 +1,120 @@
+package catalogue
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func changesTestCatalogue(datestamp string, addons ...types.Addon) types.Catalogue {
+	catalogue := NewBuilder().BuildCatalogue(addons, nil)
+	catalogue.Datestamp = datestamp
+	return catalogue
+}
+
+func TestBuilder_DiffCatalogues(t *testing.T) {
+	builder := NewBuilder()
+
+	unchanged := types.Addon{Source: types.WowInterfaceSource, SourceID: "1", Name: "unchanged", UpdatedDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	updatedBefore := types.Addon{Source: types.WowInterfaceSource, SourceID: "2", Name: "updated", UpdatedDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	updatedAfter := updatedBefore
+	updatedAfter.UpdatedDate = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	removed := types.Addon{Source: types.WowInterfaceSource, SourceID: "3", Name: "removed", UpdatedDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	added := types.Addon{Source: types.GitHubSource, SourceID: "owner/added", Name: "added", UpdatedDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	previous := changesTestCatalogue("2024-01-01", unchanged, updatedBefore, removed)
+	current := changesTestCatalogue("2024-02-01", unchanged, updatedAfter, added)
+
+	changes := builder.DiffCatalogues(previous, current)
+
+	expected := []struct {
+		sourceID string
+		kind     ChangeKind
+	}{
+		{"owner/added", AddonAdded},
+		{"2", AddonUpdated},
+		{"3", AddonRemoved},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("DiffCatalogues() returned %d changes, want %d: %+v", len(changes), len(expected), changes)
+	}
+
+	for i, want := range expected {
+		if changes[i].SourceID != want.sourceID || changes[i].Change != want.kind {
+			t.Errorf("change %d = %s %s, want %s %s", i, changes[i].SourceID, changes[i].Change, want.sourceID, want.kind)
+		}
+		if changes[i].Datestamp != "2024-02-01" {
+			t.Errorf("change %d datestamp = %s, want 2024-02-01", i, changes[i].Datestamp)
+		}
+	}
+}
+
+func TestBuilder_UpdateChangesFeed(t *testing.T) {
+	builder := NewBuilder()
+
+	addon := types.Addon{Source: types.WowInterfaceSource, SourceID: "1", Name: "addon", UpdatedDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	newAddon := types.Addon{Source: types.WowInterfaceSource, SourceID: "2", Name: "new-addon", UpdatedDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	first := changesTestCatalogue("2024-01-01", addon)
+
+	// First run: no previous catalogue, nothing listed but the ETag is recorded
+	feed, err := builder.UpdateChangesFeed(ChangesFeed{}, nil, first, DefaultChangesRetention)
+	if err != nil {
+		t.Fatalf("UpdateChangesFeed() unexpected error: %v", err)
+	}
+	if feed.Total != 0 || feed.ETag == "" {
+		t.Fatalf("first feed = %+v, want no changes and an etag", feed)
+	}
+
+	// Same content on a later day: ETag matches, feed untouched
+	sameContent := changesTestCatalogue("2024-01-02", addon)
+	unchangedFeed, err := builder.UpdateChangesFeed(feed, &first, sameContent, DefaultChangesRetention)
+	if err != nil {
+		t.Fatalf("UpdateChangesFeed() unexpected error: %v", err)
+	}
+	if unchangedFeed.Datestamp != "2024-01-01" {
+		t.Errorf("feed datestamp = %s, want unchanged 2024-01-01", unchangedFeed.Datestamp)
+	}
+
+	// New addon: one change
+	second := changesTestCatalogue("2024-01-08", addon, newAddon)
+	feed, err = builder.UpdateChangesFeed(feed, &first, second, DefaultChangesRetention)
+	if err != nil {
+		t.Fatalf("UpdateChangesFeed() unexpected error: %v", err)
+	}
+	if feed.Total != 1 || feed.ChangeList[0].Change != AddonAdded {
+		t.Fatalf("second feed = %+v, want one added change", feed)
+	}
+
+	// Removal two months later: earlier change is pruned by retention
+	third := changesTestCatalogue("2024-03-08", addon)
+	feed, err = builder.UpdateChangesFeed(feed, &second, third, DefaultChangesRetention)
+	if err != nil {
+		t.Fatalf("UpdateChangesFeed() unexpected error: %v", err)
+	}
+	if feed.Total != 1 || feed.ChangeList[0].Change != AddonRemoved {
+		t.Fatalf("third feed = %+v, want only the removed change", feed)
+	}
+
+	// Round trip through disk
+	path := filepath.Join(t.TempDir(), "changes.json")
+	if err := WriteChangesFeed(feed, path); err != nil {
+		t.Fatalf("WriteChangesFeed() unexpected error: %v", err)
+	}
+	readFeed, err := ReadChangesFeed(path)
+	if err != nil {
+		t.Fatalf("ReadChangesFeed() unexpected error: %v", err)
+	}
+	if readFeed.ETag != feed.ETag || readFeed.Total != feed.Total {
+		t.Errorf("ReadChangesFeed() = %+v, want %+v", readFeed, feed)
+	}
+
+	// Missing file is an empty feed
+	emptyFeed, err := ReadChangesFeed(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || emptyFeed.ETag != "" {
+		t.Errorf("ReadChangesFeed(missing) = %+v, %v, want empty feed", emptyFeed, err)
+	}
+}
@@ -0,0 +1,30 @@
+package catalogue
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DatestampFormat is the format of a catalogue's datestamp
+const DatestampFormat = "2006-01-02"
+
+// SourceDateEpochEnvVar fixes the build time of reproducible builds, see https://reproducible-builds.org/specs/source-date-epoch/
+const SourceDateEpochEnvVar = "SOURCE_DATE_EPOCH"
+
+// Datestamp returns datestamp if given, otherwise the UTC date of $SOURCE_DATE_EPOCH if set, otherwise an empty string
+func Datestamp(datestamp string) (string, error) {
+	if datestamp != "" {
+		return datestamp, nil
+	}
+	epoch := os.Getenv(SourceDateEpochEnvVar)
+	if epoch == "" {
+		return "", nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid $%s %q, expected seconds since the epoch: %w", SourceDateEpochEnvVar, epoch, err)
+	}
+	return time.Unix(seconds, 0).UTC().Format(DatestampFormat), nil
+}
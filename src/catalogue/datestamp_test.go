@@ -0,0 +1,48 @@
+package catalogue
+
+import (
+	"testing"
+)
+
+func TestDatestamp(t *testing.T) {
+	tests := []struct {
+		name      string
+		datestamp string
+		epoch     string
+		want      string
+		wantErr   bool
+	}{
+		{"neither", "", "", "", false},
+		{"given", "2024-03-01", "", "2024-03-01", false},
+		{"given wins over epoch", "2024-03-01", "1700000000", "2024-03-01", false},
+		{"epoch", "", "1700000000", "2023-11-14", false},
+		{"invalid epoch", "", "yesterday", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(SourceDateEpochEnvVar, tt.epoch)
+			got, err := Datestamp(tt.datestamp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Datestamp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Datestamp() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_SetDatestamp(t *testing.T) {
+	builder := NewBuilder()
+	if err := builder.SetDatestamp("2024-03-01"); err != nil {
+		t.Fatalf("SetDatestamp() unexpected error: %v", err)
+	}
+	if cat := builder.BuildCatalogue(nil, nil); cat.Datestamp != "2024-03-01" {
+		t.Errorf("BuildCatalogue() datestamp = %s, want 2024-03-01", cat.Datestamp)
+	}
+
+	if err := builder.SetDatestamp("01/03/2024"); err == nil {
+		t.Error("SetDatestamp(01/03/2024) expected an error")
+	}
+}
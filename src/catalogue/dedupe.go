@@ -0,0 +1,166 @@
+package catalogue
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// LinkDuplicates records addons published to more than one source in each addon's same-as list.
+// Addons are linked when one already refers to the other (e.g. a GitHub repository's WowInterface ID)
+// or when exactly one addon in each source shares a normalised name. Existing same-as entries that
+// don't refer to another addon in addons are dropped. Returns the number of addons linked.
+func (b *Builder) LinkDuplicates(addons []types.Addon) int {
+	index := make(map[types.AddonRef]int, len(addons))
+	for i, addon := range addons {
+		index[addonRef(addon)] = i
+	}
+
+	links := make(map[int]map[int]bool)
+	link := func(i, j int) {
+		if i == j || addons[i].Source == addons[j].Source {
+			return
+		}
+		if links[i] == nil {
+			links[i] = make(map[int]bool)
+		}
+		if links[j] == nil {
+			links[j] = make(map[int]bool)
+		}
+		links[i][j] = true
+		links[j][i] = true
+	}
+
+	// Explicit references
+	for i, addon := range addons {
+		for _, ref := range addon.SameAs {
+			if j, ok := index[ref]; ok {
+				link(i, j)
+			}
+		}
+	}
+
+	// Name heuristic, only where the match is unambiguous within each source
+	candidates := make(map[string]map[types.Source][]int)
+	for i, addon := range addons {
+		for _, key := range duplicateKeys(addon) {
+			if candidates[key] == nil {
+				candidates[key] = make(map[types.Source][]int)
+			}
+			candidates[key][addon.Source] = append(candidates[key][addon.Source], i)
+		}
+	}
+	for _, bySource := range candidates {
+		if len(bySource) < 2 {
+			continue
+		}
+		var unique []int
+		for _, indices := range bySource {
+			if len(indices) == 1 {
+				unique = append(unique, indices[0])
+			}
+		}
+		if len(unique) != len(bySource) {
+			continue
+		}
+		for _, i := range unique {
+			for _, j := range unique {
+				link(i, j)
+			}
+		}
+	}
+
+	for i := range addons {
+		addons[i].SameAs = nil
+		for j := range links[i] {
+			addons[i].SameAs = append(addons[i].SameAs, addonRef(addons[j]))
+		}
+		sort.Slice(addons[i].SameAs, func(x, y int) bool {
+			if addons[i].SameAs[x].Source != addons[i].SameAs[y].Source {
+				return addons[i].SameAs[x].Source < addons[i].SameAs[y].Source
+			}
+			return addons[i].SameAs[x].SourceID < addons[i].SameAs[y].SourceID
+		})
+	}
+
+	return len(links)
+}
+
+// CollapseDuplicates keeps only the most recently updated addon of each group of linked addons.
+// The addon kept still lists the others in its same-as list.
+func (b *Builder) CollapseDuplicates(catalogue types.Catalogue) types.Catalogue {
+	index := make(map[types.AddonRef]types.Addon, len(catalogue.AddonSummaryList))
+	for _, addon := range catalogue.AddonSummaryList {
+		index[addonRef(addon)] = addon
+	}
+
+	return b.FilterCatalogue(catalogue, func(addon types.Addon) bool {
+		for _, ref := range addon.SameAs {
+			other, ok := index[ref]
+			if ok && preferredDuplicate(other, addon) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// preferredDuplicate returns true if a should be kept over b.
+// The most recently updated wins, ties go to the first source alphabetically for stable output.
+func preferredDuplicate(a, b types.Addon) bool {
+	if !a.UpdatedDate.Equal(b.UpdatedDate) {
+		return a.UpdatedDate.After(b.UpdatedDate)
+	}
+	if a.Source != b.Source {
+		return a.Source < b.Source
+	}
+	return a.SourceID < b.SourceID
+}
+
+// duplicateKeys returns the normalised names an addon may be known by in other sources
+func duplicateKeys(addon types.Addon) []string {
+	var keys []string
+	for _, name := range []string{addon.Name, addon.Label} {
+		if key := normaliseName(name); key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	// Repositories are often named after the addon even when the label differs
+	if addon.Source == types.GitHubSource || addon.Source == types.GitLabSource || addon.Source == types.CodebergSource {
+		if _, repo, ok := strings.Cut(addon.SourceID, "/"); ok {
+			if key := normaliseName(repo); key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// minDuplicateKeyLength ignores names too short to identify an addon on their own
+const minDuplicateKeyLength = 4
+
+// normaliseName lowercases and strips everything but letters and digits,
+// so "Better Vendor Price", "better-vendor-price" and "BetterVendorPrice" compare equal.
+// Names shorter than minDuplicateKeyLength once normalised return an empty string.
+func normaliseName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	if sb.Len() < minDuplicateKeyLength {
+		return ""
+	}
+	return sb.String()
+}
+
+func addonRef(addon types.Addon) types.AddonRef {
+	return types.AddonRef{Source: addon.Source, SourceID: addon.SourceID}
+}
@@ -0,0 +1,83 @@
+package catalogue
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestBuilder_LinkDuplicates(t *testing.T) {
+	builder := NewBuilder()
+
+	addons := []types.Addon{
+		// linked by the GitHub addon's WowInterface ID, despite different names
+		{Source: types.WowInterfaceSource, SourceID: "49965", Name: "arena-leave-confirmer", Label: "Arena Leave Confirmer"},
+		{Source: types.GitHubSource, SourceID: "AlexFolland/ALC", Name: "alc", Label: "ALC",
+			SameAs: []types.AddonRef{{Source: types.WowInterfaceSource, SourceID: "49965"}}},
+
+		// linked by name
+		{Source: types.WowInterfaceSource, SourceID: "25078", Name: "better-vendor-price", Label: "Better Vendor Price"},
+		{Source: types.GitHubSource, SourceID: "mooreatv/BetterVendorPrice", Name: "bettervendorprice", Label: "BetterVendorPrice"},
+
+		// ambiguous, two WowInterface addons share the name
+		{Source: types.WowInterfaceSource, SourceID: "1", Name: "chat-cleaner", Label: "Chat Cleaner"},
+		{Source: types.WowInterfaceSource, SourceID: "2", Name: "chatcleaner", Label: "ChatCleaner"},
+		{Source: types.GitHubSource, SourceID: "owner/ChatCleaner", Name: "chatcleaner", Label: "ChatCleaner"},
+
+		// refers to an addon that isn't present
+		{Source: types.GitHubSource, SourceID: "owner/Gone", Name: "gone", Label: "Gone",
+			SameAs: []types.AddonRef{{Source: types.WowInterfaceSource, SourceID: "99999"}}},
+	}
+
+	linked := builder.LinkDuplicates(addons)
+	if linked != 4 {
+		t.Errorf("LinkDuplicates() = %d, want 4", linked)
+	}
+
+	expected := map[string][]types.AddonRef{
+		"49965":                      {{Source: types.GitHubSource, SourceID: "AlexFolland/ALC"}},
+		"AlexFolland/ALC":            {{Source: types.WowInterfaceSource, SourceID: "49965"}},
+		"25078":                      {{Source: types.GitHubSource, SourceID: "mooreatv/BetterVendorPrice"}},
+		"mooreatv/BetterVendorPrice": {{Source: types.WowInterfaceSource, SourceID: "25078"}},
+		"1":                          nil,
+		"2":                          nil,
+		"owner/ChatCleaner":          nil,
+		"owner/Gone":                 nil,
+	}
+	for _, addon := range addons {
+		if !reflect.DeepEqual(addon.SameAs, expected[addon.SourceID]) {
+			t.Errorf("%s SameAs = %v, want %v", addon.SourceID, addon.SameAs, expected[addon.SourceID])
+		}
+	}
+}
+
+func TestBuilder_CollapseDuplicates(t *testing.T) {
+	builder := NewBuilder()
+
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	addons := []types.Addon{
+		{Source: types.WowInterfaceSource, SourceID: "25078", Name: "better-vendor-price", UpdatedDate: older},
+		{Source: types.GitHubSource, SourceID: "mooreatv/BetterVendorPrice", Name: "bettervendorprice", UpdatedDate: newer},
+		{Source: types.WowInterfaceSource, SourceID: "1", Name: "unrelated-addon", UpdatedDate: older},
+	}
+	builder.LinkDuplicates(addons)
+	catalogue := builder.BuildCatalogue(addons, nil)
+
+	collapsed := builder.CollapseDuplicates(catalogue)
+	if collapsed.Total != 2 {
+		t.Fatalf("Total = %d, want 2", collapsed.Total)
+	}
+
+	for _, addon := range collapsed.AddonSummaryList {
+		if addon.SourceID == "25078" {
+			t.Errorf("older duplicate 25078 kept, want mooreatv/BetterVendorPrice")
+		}
+		if addon.SourceID == "mooreatv/BetterVendorPrice" && len(addon.SameAs) != 1 {
+			t.Errorf("kept duplicate SameAs = %v, want the collapsed addon listed", addon.SameAs)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package catalogue
+
+import (
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// MergeCatalogues combines the addons of several catalogues into one, such as this builder's and the legacy builder's.
+// An addon found in more than one catalogue, by source and source-id, is kept once: the most recently updated copy,
+// or the copy from the earliest catalogue given if they were updated at the same time.
+// Returns the merged catalogue and the number of duplicates left out.
+func (b *Builder) MergeCatalogues(catalogues []types.Catalogue) (types.Catalogue, int) {
+	type addonKey struct {
+		source   types.Source
+		sourceID string
+	}
+
+	var addons []types.Addon
+	index := make(map[addonKey]int) // position in addons
+	duplicates := 0
+
+	for _, cat := range catalogues {
+		for _, addon := range cat.AddonSummaryList {
+			key := addonKey{addon.Source, addon.SourceID}
+			i, seen := index[key]
+			if !seen {
+				index[key] = len(addons)
+				addons = append(addons, addon)
+				continue
+			}
+
+			duplicates++
+			if addon.UpdatedDate.After(addons[i].UpdatedDate) {
+				addons[i] = addon
+			}
+		}
+	}
+
+	return b.BuildCatalogue(addons, nil), duplicates
+}
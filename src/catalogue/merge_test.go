@@ -0,0 +1,52 @@
+package catalogue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestBuilder_MergeCatalogues(t *testing.T) {
+	builder := NewBuilder()
+
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	legacy := types.Catalogue{Datestamp: "2024-01-02", Total: 3, AddonSummaryList: []types.Addon{
+		{Source: types.WowInterfaceSource, SourceID: "1", Name: "one", Label: "legacy", UpdatedDate: newer},
+		{Source: types.WowInterfaceSource, SourceID: "2", Name: "two", Label: "legacy", UpdatedDate: older},
+		{Source: types.WowInterfaceSource, SourceID: "3", Name: "three", Label: "legacy", UpdatedDate: older},
+	}}
+	current := types.Catalogue{Datestamp: "2024-06-02", Total: 4, AddonSummaryList: []types.Addon{
+		{Source: types.WowInterfaceSource, SourceID: "1", Name: "one", Label: "current", UpdatedDate: older},
+		{Source: types.WowInterfaceSource, SourceID: "2", Name: "two", Label: "current", UpdatedDate: newer},
+		{Source: types.WowInterfaceSource, SourceID: "3", Name: "three", Label: "current", UpdatedDate: older},
+		{Source: types.GitHubSource, SourceID: "1", Name: "one", Label: "current", UpdatedDate: older}, // same id, another source
+	}}
+
+	merged, duplicates := builder.MergeCatalogues([]types.Catalogue{legacy, current})
+	if duplicates != 3 {
+		t.Errorf("MergeCatalogues() duplicates = %d, want 3", duplicates)
+	}
+	if merged.Total != 4 || len(merged.AddonSummaryList) != 4 {
+		t.Fatalf("MergeCatalogues() total = %d (%d addons), want 4", merged.Total, len(merged.AddonSummaryList))
+	}
+	if merged.Datestamp != builder.currentDateStamp() {
+		t.Errorf("MergeCatalogues() datestamp = %s, want %s", merged.Datestamp, builder.currentDateStamp())
+	}
+
+	// Newest updated-date wins, the earliest catalogue wins ties
+	expected := map[string]string{
+		"wowinterface/1": "legacy",
+		"wowinterface/2": "current",
+		"wowinterface/3": "legacy",
+		"github/1":       "current",
+	}
+	for _, addon := range merged.AddonSummaryList {
+		key := string(addon.Source) + "/" + addon.SourceID
+		if addon.Label != expected[key] {
+			t.Errorf("%s label = %s, want %s", key, addon.Label, expected[key])
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package catalogue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// Override patches fields of a single addon after it has been scraped, e.g. to fix a wrong game track or label.
+// Fields left out are not changed.
+type Override struct {
+	Description   *string           `json:"description,omitempty"`
+	GameTrackList []types.GameTrack `json:"game-track-list,omitempty"` // replaces the addon's game tracks
+	Label         *string           `json:"label,omitempty"`
+	Name          *string           `json:"name,omitempty"`
+	AddTagList    []string          `json:"add-tag-list,omitempty"` // added to the addon's tags
+	TagList       []string          `json:"tag-list,omitempty"`     // replaces the addon's tags
+	URL           *string           `json:"url,omitempty"`
+}
+
+// Overrides are keyed by source and source-id, e.g. "wowinterface/12345" or "github/owner/repo"
+type Overrides map[string]Override
+
+// overrideKey returns the key an addon's override is found under
+func overrideKey(addon types.Addon) string {
+	return string(addon.Source) + "/" + addon.SourceID
+}
+
+// Apply returns the addon with its override applied and true if that changed anything
+func (o Overrides) Apply(addon types.Addon) (types.Addon, bool) {
+	override, ok := o[overrideKey(addon)]
+	if !ok {
+		return addon, false
+	}
+
+	patched := addon
+	if override.Description != nil {
+		patched.Description = *override.Description
+	}
+	if override.Label != nil {
+		patched.Label = *override.Label
+	}
+	if override.Name != nil {
+		patched.Name = *override.Name
+	}
+	if override.URL != nil {
+		patched.URL = *override.URL
+	}
+	if override.GameTrackList != nil {
+		patched.GameTrackList = slices.Clone(override.GameTrackList)
+	}
+	if override.TagList != nil || override.AddTagList != nil {
+		tags := patched.TagList
+		if override.TagList != nil {
+			tags = override.TagList
+		}
+		tags = append(slices.Clone(tags), override.AddTagList...)
+		sort.Strings(tags)
+		patched.TagList = slices.Compact(tags)
+	}
+
+	changed := patched.Description != addon.Description ||
+		patched.Label != addon.Label ||
+		patched.Name != addon.Name ||
+		patched.URL != addon.URL ||
+		!slices.Equal(patched.GameTrackList, addon.GameTrackList) ||
+		!slices.Equal(patched.TagList, addon.TagList)
+	return patched, changed
+}
+
+// Check returns the keys of the overrides that change an addon in addons and those that are stale,
+// either because the addon is gone or because the source now agrees with the override.
+func (o Overrides) Check(addons []types.Addon) (applied []string, stale []string) {
+	found := make(map[string]bool, len(o))
+	for _, addon := range addons {
+		key := overrideKey(addon)
+		if _, ok := o[key]; !ok {
+			continue
+		}
+		found[key] = true
+		if _, changed := o.Apply(addon); changed {
+			applied = append(applied, key)
+		} else {
+			stale = append(stale, key)
+		}
+	}
+
+	for key := range o {
+		if !found[key] {
+			stale = append(stale, key)
+		}
+	}
+
+	sort.Strings(applied)
+	sort.Strings(stale)
+	return applied, stale
+}
+
+// ReadOverrides reads an overrides JSON file, rejecting malformed keys and unknown game tracks
+func ReadOverrides(path string) (Overrides, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides %s: %w", path, err)
+	}
+
+	var overrides Overrides
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse overrides %s: %w", path, err)
+	}
+
+	for key, override := range overrides {
+		source, sourceID, _ := strings.Cut(key, "/")
+		if !slices.Contains(types.AllSources, types.Source(source)) || sourceID == "" {
+			return nil, fmt.Errorf("bad key in overrides %s: %q, expected source/source-id", path, key)
+		}
+		for _, track := range override.GameTrackList {
+			if !slices.Contains(types.AllGameTracks, track) {
+				return nil, fmt.Errorf("unknown game track in overrides %s: %s: %s", path, key, track)
+			}
+		}
+	}
+
+	return overrides, nil
+}
@@ -0,0 +1,107 @@
+package catalogue
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func overridesTestAddon(sourceID string) types.Addon {
+	return types.Addon{
+		GameTrackList: []types.GameTrack{types.RetailTrack},
+		Label:         "Addon " + sourceID,
+		Name:          "addon-" + sourceID,
+		Source:        types.WowInterfaceSource,
+		SourceID:      sourceID,
+		TagList:       []string{"bags"},
+		UpdatedDate:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestOverrides_Apply(t *testing.T) {
+	label := "Fixed Label"
+	overrides := Overrides{
+		"wowinterface/1": {
+			Label:         &label,
+			GameTrackList: []types.GameTrack{types.RetailTrack, types.ClassicTrack},
+			AddTagList:    []string{"inventory", "bags"},
+		},
+		"wowinterface/2": {TagList: []string{"ui"}},
+	}
+
+	addon := overridesTestAddon("1")
+	patched, changed := overrides.Apply(addon)
+	if !changed {
+		t.Error("Apply() changed = false, want true")
+	}
+	if patched.Label != label {
+		t.Errorf("Label = %q, want %q", patched.Label, label)
+	}
+	if want := []types.GameTrack{types.RetailTrack, types.ClassicTrack}; !reflect.DeepEqual(patched.GameTrackList, want) {
+		t.Errorf("GameTrackList = %v, want %v", patched.GameTrackList, want)
+	}
+	if want := []string{"bags", "inventory"}; !reflect.DeepEqual(patched.TagList, want) {
+		t.Errorf("TagList = %v, want %v", patched.TagList, want)
+	}
+	if addon.Label != "Addon 1" || len(addon.TagList) != 1 {
+		t.Errorf("Apply() modified the original addon: %+v", addon)
+	}
+
+	patched, _ = overrides.Apply(overridesTestAddon("2"))
+	if want := []string{"ui"}; !reflect.DeepEqual(patched.TagList, want) {
+		t.Errorf("TagList = %v, want %v", patched.TagList, want)
+	}
+
+	if _, changed := overrides.Apply(overridesTestAddon("3")); changed {
+		t.Error("Apply() without an override changed = true, want false")
+	}
+}
+
+func TestOverrides_Check(t *testing.T) {
+	label := "Addon 2"
+	overrides := Overrides{
+		"wowinterface/1":   {AddTagList: []string{"inventory"}},
+		"wowinterface/2":   {Label: &label}, // source already agrees
+		"wowinterface/404": {Label: &label}, // addon gone
+	}
+
+	applied, stale := overrides.Check([]types.Addon{overridesTestAddon("1"), overridesTestAddon("2")})
+	if want := []string{"wowinterface/1"}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied = %v, want %v", applied, want)
+	}
+	if want := []string{"wowinterface/2", "wowinterface/404"}; !reflect.DeepEqual(stale, want) {
+		t.Errorf("stale = %v, want %v", stale, want)
+	}
+}
+
+func TestReadOverrides(t *testing.T) {
+	tests := []struct {
+		contents string
+		wantErr  string
+	}{
+		{`{"github/owner/repo": {"label": "Repo"}}`, ""},
+		{`{"12345": {"label": "No Source"}}`, "bad key"},
+		{`{"curseforge/1": {"label": "Unknown Source"}}`, "bad key"},
+		{`{"wowinterface/1": {"game-track-list": ["vanilla"]}}`, "unknown game track"},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "overrides.json")
+		if err := os.WriteFile(path, []byte(tt.contents), 0644); err != nil {
+			t.Fatalf("failed to write overrides: %v", err)
+		}
+
+		_, err := ReadOverrides(path)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("ReadOverrides(%s) unexpected error: %v", tt.contents, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("ReadOverrides(%s) error = %v, want error containing %q", tt.contents, err, tt.wantErr)
+		}
+	}
+}
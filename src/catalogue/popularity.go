@@ -0,0 +1,39 @@
+package catalogue
+
+import (
+	"math"
+	"slices"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// rankPopularity sets the popularity of each addon with a download count to the fraction of the other addons of its
+// source with a download count that were downloaded fewer times, rounded to 4 decimal places.
+// The most downloaded addon of a source has a popularity of 1 and the least downloaded 0.
+// Addons without a download count, e.g. those on GitHub, have none.
+func rankPopularity(addons []types.Addon) {
+	counts := make(map[types.Source][]int)
+	for _, addon := range addons {
+		if addon.DownloadCount != nil {
+			counts[addon.Source] = append(counts[addon.Source], *addon.DownloadCount)
+		}
+	}
+	for _, sourceCounts := range counts {
+		slices.Sort(sourceCounts)
+	}
+
+	for i := range addons {
+		addon := &addons[i]
+		addon.Popularity = nil
+		if addon.DownloadCount == nil {
+			continue
+		}
+		sourceCounts := counts[addon.Source]
+		fewer, _ := slices.BinarySearch(sourceCounts, *addon.DownloadCount)
+		popularity := 1.0
+		if len(sourceCounts) > 1 {
+			popularity = math.Round(float64(fewer)/float64(len(sourceCounts)-1)*10000) / 10000
+		}
+		addon.Popularity = &popularity
+	}
+}
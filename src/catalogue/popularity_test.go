@@ -0,0 +1,43 @@
+package catalogue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestBuilder_BuildCatalogue_Popularity(t *testing.T) {
+	downloads := func(n int) *int { return &n }
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	addon := func(source types.Source, sourceID string, count *int) types.Addon {
+		return types.Addon{Source: source, SourceID: sourceID, Name: sourceID, UpdatedDate: updated, DownloadCount: count}
+	}
+	addons := []types.Addon{
+		addon(types.WowInterfaceSource, "1", downloads(10)),
+		addon(types.WowInterfaceSource, "2", downloads(500)),
+		addon(types.WowInterfaceSource, "3", downloads(500)),
+		addon(types.WowInterfaceSource, "4", downloads(90000)),
+		addon(types.WowInterfaceSource, "5", downloads(20)),
+		addon(types.WagoSource, "6", downloads(3)), // ranked within its own source
+		addon(types.GitHubSource, "a/b", nil),
+	}
+
+	builder := NewBuilder()
+	if catalogue := builder.BuildCatalogue(addons, nil); catalogue.AddonSummaryList[0].Popularity != nil {
+		t.Errorf("BuildCatalogue() without popularity gave popularity %v", *catalogue.AddonSummaryList[0].Popularity)
+	}
+
+	builder.SetPopularity(true)
+	catalogue := builder.BuildCatalogue(addons, nil)
+	want := map[string]float64{"1": 0, "5": 0.25, "2": 0.5, "3": 0.5, "4": 1, "6": 1}
+	for _, addon := range catalogue.AddonSummaryList {
+		expected, ok := want[addon.SourceID]
+		switch {
+		case !ok && addon.Popularity != nil:
+			t.Errorf("%s popularity = %v, want none without a download count", addon.SourceID, *addon.Popularity)
+		case ok && (addon.Popularity == nil || *addon.Popularity != expected):
+			t.Errorf("%s popularity = %v, want %v", addon.SourceID, addon.Popularity, expected)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package catalogue
+
+import "slices"
+
+// Provenance is the files the fields of a merged addon came from, by the field's name in the catalogue.
+// Single-valued fields list the one file whose value was kept, fields combined from several files
+// (game-track-list, tag-list, archived and interface-list, the interface versions of the release-list)
+// list each file contributing, in merge order. Fields no file set are missing.
+type Provenance map[string][]string
+
+// set records filename as the file field came from, replacing any earlier file. Does nothing to a nil Provenance.
+func (p Provenance) set(field, filename string) {
+	if p != nil {
+		p[field] = []string{filename}
+	}
+}
+
+// add records filename as one of the files field came from. Does nothing to a nil Provenance.
+func (p Provenance) add(field, filename string) {
+	if p != nil && !slices.Contains(p[field], filename) {
+		p[field] = append(p[field], filename)
+	}
+}
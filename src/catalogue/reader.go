@@ -0,0 +1,25 @@
+package catalogue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// ReadCatalogue reads a catalogue JSON file, such as one previously written by scrape
+func ReadCatalogue(path string) (types.Catalogue, error) {
+	var catalogue types.Catalogue
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return catalogue, fmt.Errorf("failed to read catalogue %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &catalogue); err != nil {
+		return catalogue, fmt.Errorf("failed to parse catalogue %s: %w", path, err)
+	}
+
+	return catalogue, nil
+}
@@ -0,0 +1,116 @@
+package catalogue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// Releases maps each addon in a catalogue to its latest release for each of its game tracks,
+// so addons can be downloaded straight from the catalogue whatever spec version it was written in.
+type Releases struct {
+	Datestamp string `json:"datestamp"`
+	Total     int    `json:"total"` // addons with releases
+	// source -> source-id -> game track -> latest release
+	ReleaseMap map[types.Source]map[string]map[types.GameTrack]types.Release `json:"release-map"`
+}
+
+// ExtractReleases returns the latest releases of the catalogue's addons, taken from addons as catalogues written
+// before spec version 3 leave them out. A release the source didn't give a game track is the release of each of
+// the addon's game tracks without one of its own, or retail if the addon has none.
+func (b *Builder) ExtractReleases(catalogue types.Catalogue, addons []types.Addon) Releases {
+	releaseLists := make(map[types.AddonRef][]types.Release, len(addons))
+	for _, addon := range addons {
+		releaseLists[addonRef(addon)] = addon.ReleaseList
+	}
+
+	releases := Releases{Datestamp: catalogue.Datestamp, ReleaseMap: make(map[types.Source]map[string]map[types.GameTrack]types.Release)}
+	for _, addon := range catalogue.AddonSummaryList {
+		tracks := latestReleases(releaseLists[addonRef(addon)], addon.GameTrackList)
+		if len(tracks) == 0 {
+			continue
+		}
+		if releases.ReleaseMap[addon.Source] == nil {
+			releases.ReleaseMap[addon.Source] = make(map[string]map[types.GameTrack]types.Release)
+		}
+		releases.ReleaseMap[addon.Source][addon.SourceID] = tracks
+		releases.Total++
+	}
+	return releases
+}
+
+// Keep gives the catalogue's addons without releases their releases in previous, e.g. addons an incremental scrape
+// didn't fetch again and so only knows from the last scrape's catalogue
+func (r *Releases) Keep(previous Releases, catalogue types.Catalogue) {
+	for _, addon := range catalogue.AddonSummaryList {
+		tracks, ok := previous.ReleaseMap[addon.Source][addon.SourceID]
+		if !ok || r.ReleaseMap[addon.Source][addon.SourceID] != nil {
+			continue
+		}
+		if r.ReleaseMap[addon.Source] == nil {
+			r.ReleaseMap[addon.Source] = make(map[string]map[types.GameTrack]types.Release)
+		}
+		r.ReleaseMap[addon.Source][addon.SourceID] = tracks
+		r.Total++
+	}
+}
+
+// latestReleases keys releases by game track, giving releases without one to each of gameTracks they don't cover
+func latestReleases(releases []types.Release, gameTracks []types.GameTrack) map[types.GameTrack]types.Release {
+	if len(releases) == 0 {
+		return nil
+	}
+	if len(gameTracks) == 0 {
+		gameTracks = []types.GameTrack{types.RetailTrack}
+	}
+
+	tracks := make(map[types.GameTrack]types.Release)
+	var untracked []types.Release
+	for _, release := range releases {
+		if release.GameTrack == "" {
+			untracked = append(untracked, release)
+			continue
+		}
+		if _, ok := tracks[release.GameTrack]; !ok {
+			tracks[release.GameTrack] = release
+		}
+	}
+	if len(untracked) > 0 {
+		for _, track := range gameTracks {
+			if _, ok := tracks[track]; !ok {
+				release := untracked[0]
+				release.GameTrack = track
+				tracks[track] = release
+			}
+		}
+	}
+	return tracks
+}
+
+// WriteReleases writes releases as indented JSON
+func WriteReleases(releases Releases, path string) error {
+	data, err := json.MarshalIndent(releases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal releases: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write releases to %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadReleases reads releases written by WriteReleases
+func ReadReleases(path string) (Releases, error) {
+	var releases Releases
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return releases, fmt.Errorf("failed to read releases: %w", err)
+	}
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return releases, fmt.Errorf("failed to parse releases %s: %w", path, err)
+	}
+	return releases, nil
+}
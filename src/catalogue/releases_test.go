@@ -0,0 +1,90 @@
+package catalogue
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestBuilder_ExtractReleases(t *testing.T) {
+	builder := NewBuilder()
+
+	apiRelease := types.Release{DownloadURL: "https://cdn.wowinterface.com/downloads/getfile.php?id=1", Version: "1.2", Checksum: "abc123"}
+	classicRelease := types.Release{DownloadURL: "https://www.wowinterface.com/downloads/download1-classic", GameTrack: types.ClassicTrack, Version: "1.2-classic"}
+	addons := []types.Addon{
+		{Source: types.WowInterfaceSource, SourceID: "1", GameTrackList: []types.GameTrack{types.RetailTrack, types.ClassicTrack}, ReleaseList: []types.Release{apiRelease, classicRelease}},
+		{Source: types.WowInterfaceSource, SourceID: "2", ReleaseList: []types.Release{apiRelease}},
+		{Source: types.WowInterfaceSource, SourceID: "3"},
+		{Source: types.GitHubSource, SourceID: "1", GameTrackList: []types.GameTrack{types.ClassicTrack}, ReleaseList: []types.Release{classicRelease}},
+		{Source: types.WowInterfaceSource, SourceID: "4", ReleaseList: []types.Release{apiRelease}}, // not in the catalogue
+	}
+	catalogue := changesTestCatalogue("2024-01-02", addons[:4]...) // spec version 2, without releases
+
+	releases := builder.ExtractReleases(catalogue, addons)
+
+	if releases.Datestamp != "2024-01-02" || releases.Total != 3 {
+		t.Errorf("Datestamp, Total = %s, %d, want 2024-01-02, 3", releases.Datestamp, releases.Total)
+	}
+	retailRelease := apiRelease
+	retailRelease.GameTrack = types.RetailTrack
+	expected := map[types.Source]map[string]map[types.GameTrack]types.Release{
+		types.WowInterfaceSource: {
+			// the API's release is the retail release, the page gave the classic release
+			"1": {types.RetailTrack: retailRelease, types.ClassicTrack: classicRelease},
+			// without game tracks the addon is taken as retail
+			"2": {types.RetailTrack: retailRelease},
+		},
+		types.GitHubSource: {"1": {types.ClassicTrack: classicRelease}},
+	}
+	if !reflect.DeepEqual(releases.ReleaseMap, expected) {
+		t.Errorf("ReleaseMap = %+v, want %+v", releases.ReleaseMap, expected)
+	}
+}
+
+func TestReleases_Keep(t *testing.T) {
+	current := types.Release{DownloadURL: "https://example.org/current.zip", GameTrack: types.RetailTrack}
+	old := types.Release{DownloadURL: "https://example.org/old.zip", GameTrack: types.RetailTrack}
+	releases := Releases{Total: 1, ReleaseMap: map[types.Source]map[string]map[types.GameTrack]types.Release{
+		types.WowInterfaceSource: {"1": {types.RetailTrack: current}},
+	}}
+	previous := Releases{Total: 3, ReleaseMap: map[types.Source]map[string]map[types.GameTrack]types.Release{
+		types.WowInterfaceSource: {"1": {types.RetailTrack: old}, "2": {types.RetailTrack: old}, "3": {types.RetailTrack: old}},
+	}}
+	catalogue := changesTestCatalogue("2024-01-02",
+		types.Addon{Source: types.WowInterfaceSource, SourceID: "1"},
+		types.Addon{Source: types.WowInterfaceSource, SourceID: "2"})
+
+	releases.Keep(previous, catalogue)
+
+	expected := map[string]map[types.GameTrack]types.Release{
+		"1": {types.RetailTrack: current}, // scraped again
+		"2": {types.RetailTrack: old},
+		// 3 is no longer in the catalogue
+	}
+	if releases.Total != 2 || !reflect.DeepEqual(releases.ReleaseMap[types.WowInterfaceSource], expected) {
+		t.Errorf("Keep() = %d, %+v, want 2, %+v", releases.Total, releases.ReleaseMap[types.WowInterfaceSource], expected)
+	}
+}
+
+func TestWriteReleases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "releases.json")
+	releases := Releases{Datestamp: "2024-01-02", Total: 1, ReleaseMap: map[types.Source]map[string]map[types.GameTrack]types.Release{
+		types.WowInterfaceSource: {"1": {types.RetailTrack: {DownloadURL: "https://example.org/1.zip", GameTrack: types.RetailTrack, Version: "1.0", Checksum: "abc"}}},
+	}}
+	if err := WriteReleases(releases, path); err != nil {
+		t.Fatalf("WriteReleases() unexpected error: %v", err)
+	}
+	read, err := ReadReleases(path)
+	if err != nil {
+		t.Fatalf("ReadReleases() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(read, releases) {
+		t.Errorf("ReadReleases() = %+v, want %+v", read, releases)
+	}
+
+	if _, err := ReadReleases(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("ReadReleases() of a missing file, expected an error")
+	}
+}
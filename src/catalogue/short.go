@@ -0,0 +1,100 @@
+package catalogue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// DefaultShortCutoff is the release of Dragonflight, addons not updated since are considered unmaintained
+var DefaultShortCutoff = Cutoff{date: time.Date(2022, 11, 28, 0, 0, 0, 0, time.UTC)}
+
+// Cutoff is the date addons must have been updated after to be kept in the short catalogue,
+// either a fixed date or a period before the catalogue's datestamp.
+type Cutoff struct {
+	date                time.Time
+	years, months, days int
+}
+
+// cutoffUnits are the units of a relative cutoff, singular or plural
+var cutoffUnits = map[string]func(c *Cutoff, n int){
+	"day":   func(c *Cutoff, n int) { c.days = n },
+	"week":  func(c *Cutoff, n int) { c.days = 7 * n },
+	"month": func(c *Cutoff, n int) { c.months = n },
+	"year":  func(c *Cutoff, n int) { c.years = n },
+}
+
+// ParseCutoff parses a date as YYYY-MM-DD, e.g. "2022-11-28", or a period such as "2 years", "18 months" or "90 days"
+func ParseCutoff(s string) (Cutoff, error) {
+	s = strings.TrimSpace(s)
+	if date, err := time.Parse(DatestampFormat, s); err == nil {
+		return Cutoff{date: date}, nil
+	}
+
+	nStr, unit, ok := strings.Cut(s, " ")
+	n, err := strconv.Atoi(nStr)
+	set, known := cutoffUnits[strings.TrimSuffix(strings.TrimSpace(unit), "s")]
+	if !ok || err != nil || n < 1 || !known {
+		return Cutoff{}, fmt.Errorf("invalid cutoff %q, expected YYYY-MM-DD or a period such as \"2 years\"", s)
+	}
+	var c Cutoff
+	set(&c, n)
+	return c, nil
+}
+
+// IsZero reports whether the cutoff is unset
+func (c Cutoff) IsZero() bool {
+	return c == Cutoff{}
+}
+
+// Time returns the cutoff date of a catalogue dated now
+func (c Cutoff) Time(now time.Time) time.Time {
+	if !c.date.IsZero() {
+		return c.date
+	}
+	return now.AddDate(-c.years, -c.months, -c.days)
+}
+
+// String returns the cutoff as it was parsed, for logging
+func (c Cutoff) String() string {
+	switch {
+	case !c.date.IsZero():
+		return c.date.Format(DatestampFormat)
+	case c.years > 0:
+		return strconv.Itoa(c.years) + " years"
+	case c.months > 0:
+		return strconv.Itoa(c.months) + " months"
+	default:
+		return strconv.Itoa(c.days) + " days"
+	}
+}
+
+// ShortPolicy decides which addons are maintained enough for the short catalogue
+type ShortPolicy struct {
+	Cutoff       Cutoff // keep addons updated after this, DefaultShortCutoff if zero
+	MinDownloads int    // also keep addons downloaded more than this however old, stable addons may never need an update. 0 to go by date only
+}
+
+// keeps reports whether the policy keeps addon in a catalogue with the cutoff date cutoffDate
+func (p ShortPolicy) keeps(addon types.Addon, cutoffDate time.Time) bool {
+	if addon.UpdatedDate.After(cutoffDate) {
+		return true
+	}
+	return p.MinDownloads > 0 && addon.DownloadCount != nil && *addon.DownloadCount > p.MinDownloads
+}
+
+// CutoffDate returns the date addons in catalogue must have been updated after to be kept by their age
+func (p ShortPolicy) CutoffDate(catalogue types.Catalogue) time.Time {
+	cutoff := p.Cutoff
+	if cutoff.IsZero() {
+		cutoff = DefaultShortCutoff
+	}
+	now, err := time.Parse(DatestampFormat, catalogue.Datestamp)
+	if err != nil {
+		now = time.Now().UTC()
+	}
+	return cutoff.Time(now)
+}
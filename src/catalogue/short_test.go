@@ -0,0 +1,83 @@
+package catalogue
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestParseCutoff(t *testing.T) {
+	now := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		cutoff string
+		want   time.Time
+	}{
+		{"2022-11-28", time.Date(2022, 11, 28, 0, 0, 0, 0, time.UTC)},
+		{"2 years", time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)},
+		{"1 year", time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)},
+		{"18 months", time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC)},
+		{"2 weeks", time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"90 days", time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		cutoff, err := ParseCutoff(tt.cutoff)
+		if err != nil {
+			t.Errorf("ParseCutoff(%q) unexpected error: %v", tt.cutoff, err)
+			continue
+		}
+		if got := cutoff.Time(now); !got.Equal(tt.want) {
+			t.Errorf("ParseCutoff(%q).Time(%s) = %s, want %s", tt.cutoff, now, got, tt.want)
+		}
+	}
+
+	for _, cutoff := range []string{"", "2 fortnights", "years", "0 years", "-1 years", "2022-13-01"} {
+		if _, err := ParseCutoff(cutoff); err == nil {
+			t.Errorf("ParseCutoff(%q) expected an error", cutoff)
+		}
+	}
+}
+
+func TestBuilder_ShortenCatalogue_Policy(t *testing.T) {
+	downloads := func(n int) *int { return &n }
+	addons := []types.Addon{
+		{Source: types.WowInterfaceSource, SourceID: "1", Name: "recent", UpdatedDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), DownloadCount: downloads(10)},
+		{Source: types.WowInterfaceSource, SourceID: "2", Name: "stable", UpdatedDate: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), DownloadCount: downloads(500000)},
+		{Source: types.WowInterfaceSource, SourceID: "3", Name: "abandoned", UpdatedDate: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), DownloadCount: downloads(100)},
+		{Source: types.GitHubSource, SourceID: "a/b", Name: "uncounted", UpdatedDate: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	catalogue := types.Catalogue{Datestamp: "2025-06-15", Total: len(addons), AddonSummaryList: addons}
+	twoYears, err := ParseCutoff("2 years")
+	if err != nil {
+		t.Fatalf("ParseCutoff() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		policy ShortPolicy
+		want   []string
+	}{
+		{"default cutoff", ShortPolicy{}, []string{"recent"}},
+		{"relative to the datestamp", ShortPolicy{Cutoff: twoYears}, []string{"recent"}},
+		{"popular addons kept however old", ShortPolicy{MinDownloads: 1000}, []string{"recent", "stable"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewBuilder().ShortenCatalogue(catalogue, tt.policy)
+			var names []string
+			for _, addon := range result.AddonSummaryList {
+				names = append(names, addon.Name)
+			}
+			if !slices.Equal(names, tt.want) || result.Total != len(tt.want) {
+				t.Errorf("ShortenCatalogue() = %v (total %d), want %v", names, result.Total, tt.want)
+			}
+		})
+	}
+
+	// A relative cutoff moves with the datestamp
+	catalogue.Datestamp = "2021-06-15"
+	if got, want := (ShortPolicy{Cutoff: twoYears}).CutoffDate(catalogue), time.Date(2019, 6, 15, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("CutoffDate() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,160 @@
+package catalogue
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+	_ "modernc.org/sqlite" // pure Go driver, keeps CGO_ENABLED=0 builds working
+)
+
+// sqliteSchema creates one row per addon with tags and game tracks normalised into their own tables.
+// Addons are keyed by (source, source_id), the same pair strongbox uses to identify an addon.
+// Authors and releases are only filled in for spec version 3 catalogues.
+const sqliteSchema = `
+CREATE TABLE catalogue (
+	spec_version INTEGER NOT NULL,
+	datestamp    TEXT NOT NULL,
+	total        INTEGER NOT NULL
+);
+
+CREATE TABLE addon (
+	source         TEXT NOT NULL,
+	source_id      TEXT NOT NULL,
+	name           TEXT NOT NULL,
+	label          TEXT NOT NULL,
+	author         TEXT,
+	description    TEXT,
+	url            TEXT NOT NULL,
+	created_date   TEXT,
+	updated_date   TEXT NOT NULL,
+	download_count INTEGER,
+	archived       INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (source, source_id)
+);
+
+CREATE TABLE addon_tag (
+	source    TEXT NOT NULL,
+	source_id TEXT NOT NULL,
+	tag       TEXT NOT NULL,
+	PRIMARY KEY (source, source_id, tag),
+	FOREIGN KEY (source, source_id) REFERENCES addon (source, source_id)
+);
+
+CREATE TABLE addon_game_track (
+	source     TEXT NOT NULL,
+	source_id  TEXT NOT NULL,
+	game_track TEXT NOT NULL,
+	PRIMARY KEY (source, source_id, game_track),
+	FOREIGN KEY (source, source_id) REFERENCES addon (source, source_id)
+);
+
+CREATE TABLE release (
+	source       TEXT NOT NULL,
+	source_id    TEXT NOT NULL,
+	game_track   TEXT,
+	version      TEXT,
+	download_url TEXT NOT NULL,
+	size         INTEGER,
+	checksum     TEXT,
+	FOREIGN KEY (source, source_id) REFERENCES addon (source, source_id)
+);
+
+CREATE INDEX addon_name_idx ON addon (name);
+CREATE INDEX addon_tag_tag_idx ON addon_tag (tag);
+`
+
+// WriteSQLite writes a catalogue into a fresh SQLite database at path.
+// Any existing file at path is replaced.
+func WriteSQLite(catalogue types.Catalogue, path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove existing database %s: %w", path, err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	if _, err := tx.Exec(`INSERT INTO catalogue (spec_version, datestamp, total) VALUES (?, ?, ?)`,
+		catalogue.Spec.Version, catalogue.Datestamp, catalogue.Total); err != nil {
+		return fmt.Errorf("failed to insert catalogue: %w", err)
+	}
+
+	for _, addon := range catalogue.AddonSummaryList {
+		if err := insertAddon(tx, addon); err != nil {
+			return fmt.Errorf("failed to insert addon %s/%s: %w", addon.Source, addon.SourceID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// insertAddon inserts a single addon and its tags, game tracks and releases
+func insertAddon(tx *sql.Tx, addon types.Addon) error {
+	var createdDate *string
+	if addon.CreatedDate != nil {
+		formatted := addon.CreatedDate.Format(time.RFC3339)
+		createdDate = &formatted
+	}
+
+	_, err := tx.Exec(`INSERT INTO addon
+		(source, source_id, name, label, author, description, url, created_date, updated_date, download_count, archived)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		addon.Source, addon.SourceID, addon.Name, addon.Label, nullString(addon.Author), addon.Description, addon.URL,
+		createdDate, addon.UpdatedDate.Format(time.RFC3339), addon.DownloadCount, addon.Archived)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range addon.TagList {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO addon_tag (source, source_id, tag) VALUES (?, ?, ?)`,
+			addon.Source, addon.SourceID, tag); err != nil {
+			return err
+		}
+	}
+
+	for _, track := range addon.GameTrackList {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO addon_game_track (source, source_id, game_track) VALUES (?, ?, ?)`,
+			addon.Source, addon.SourceID, track); err != nil {
+			return err
+		}
+	}
+
+	for _, release := range addon.ReleaseList {
+		var size *int64
+		if release.Size > 0 {
+			size = &release.Size
+		}
+		if _, err := tx.Exec(`INSERT INTO release (source, source_id, game_track, version, download_url, size, checksum) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			addon.Source, addon.SourceID, nullString(string(release.GameTrack)), nullString(release.Version), release.DownloadURL, size, nullString(release.Checksum)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,86 @@
+package catalogue
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestWriteSQLite(t *testing.T) {
+	builder := NewBuilder()
+	if err := builder.SetSpecVersion(types.SpecVersion3); err != nil {
+		t.Fatalf("SetSpecVersion() unexpected error: %v", err)
+	}
+
+	addons := []types.Addon{
+		{
+			Source:        types.WowInterfaceSource,
+			SourceID:      "12345",
+			Name:          "adibags",
+			Label:         "AdiBags",
+			URL:           "https://www.wowinterface.com/downloads/info12345",
+			UpdatedDate:   time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			CreatedDate:   timePtr(time.Date(2010, 1, 1, 12, 0, 0, 0, time.UTC)),
+			DownloadCount: intPtr(100),
+			GameTrackList: []types.GameTrack{types.RetailTrack, types.ClassicTrack},
+			TagList:       []string{"bags", "inventory"},
+			Author:        "Someone",
+			ReleaseList:   []types.Release{{DownloadURL: "https://cdn.wowinterface.com/downloads/getfile.php?id=12345", Version: "1.0", Checksum: "77429fa58f1a4e5201e82d2d04afb4bc"}},
+		},
+		{
+			Source:        types.GitHubSource,
+			SourceID:      "owner/repo",
+			Name:          "repo",
+			Label:         "Repo",
+			URL:           "https://github.com/owner/repo",
+			UpdatedDate:   time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC),
+			GameTrackList: []types.GameTrack{},
+		},
+	}
+	catalogue := builder.BuildCatalogue(addons, nil)
+
+	path := filepath.Join(t.TempDir(), "catalogue.db")
+
+	// Writing twice replaces the database rather than failing on existing tables
+	for i := 0; i < 2; i++ {
+		if err := WriteSQLite(catalogue, path); err != nil {
+			t.Fatalf("WriteSQLite() unexpected error: %v", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	counts := map[string]int{
+		"SELECT COUNT(*) FROM addon":            2,
+		"SELECT COUNT(*) FROM addon_tag":        2,
+		"SELECT COUNT(*) FROM addon_game_track": 2,
+		"SELECT total FROM catalogue":           2,
+		"SELECT COUNT(*) FROM release":          1,
+		"SELECT spec_version FROM catalogue":    3,
+	}
+	for query, want := range counts {
+		var got int
+		if err := db.QueryRow(query).Scan(&got); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		if got != want {
+			t.Errorf("%s = %d, want %d", query, got, want)
+		}
+	}
+
+	var label string
+	err = db.QueryRow(`SELECT a.label FROM addon a JOIN addon_tag t USING (source, source_id) WHERE t.tag = 'bags'`).Scan(&label)
+	if err != nil {
+		t.Fatalf("failed to query addon by tag: %v", err)
+	}
+	if label != "AdiBags" {
+		t.Errorf("label = %s, want AdiBags", label)
+	}
+}
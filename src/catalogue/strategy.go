@@ -0,0 +1,130 @@
+package catalogue
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MergeStrategy decides which value of a field is kept when the files describing an addon disagree.
+// Files are merged in MergeOrder.
+type MergeStrategy string
+
+const (
+	PreferLast    MergeStrategy = "prefer-last"    // the value of the last file setting the field
+	PreferLongest MergeStrategy = "prefer-longest" // the longest text, the last file's if several are as long
+	PreferMax     MergeStrategy = "prefer-max"     // the largest count
+	PreferNewest  MergeStrategy = "prefer-newest"  // the latest date
+	Union         MergeStrategy = "union"          // every value of every file
+)
+
+// mergeStrategies are the strategies each field whose strategy can be chosen may be merged with, by the field's name
+// in the catalogue. Other fields are always merged with PreferLast.
+var mergeStrategies = map[string][]MergeStrategy{
+	"name":                   {PreferLast, PreferLongest},
+	"label":                  {PreferLast, PreferLongest},
+	"author":                 {PreferLast, PreferLongest},
+	"description":            {PreferLast, PreferLongest},
+	"changelog":              {PreferLast, PreferLongest},
+	"download-count":         {PreferLast, PreferMax},
+	"favorite-count":         {PreferLast, PreferMax},
+	"monthly-download-count": {PreferLast, PreferMax},
+	"updated-date":           {PreferLast, PreferNewest},
+	"created-date":           {PreferLast, PreferNewest},
+	"game-track-list":        {Union, PreferLast},
+	"tag-list":               {Union, PreferLast},
+}
+
+// DefaultMergeStrategies are the strategies fields are merged with unless set otherwise, PreferLast for any other.
+// A page's description is usually more complete than the API's, which is cut short, and counts and dates only grow.
+var DefaultMergeStrategies = map[string]MergeStrategy{
+	"description":     PreferLongest,
+	"download-count":  PreferMax,
+	"updated-date":    PreferNewest,
+	"game-track-list": Union,
+	"tag-list":        Union,
+}
+
+// ParseMergeStrategy parses the merge strategy of a field as FIELD=STRATEGY, e.g. "description=prefer-last"
+func ParseMergeStrategy(s string) (string, MergeStrategy, error) {
+	field, strategyStr, ok := strings.Cut(s, "=")
+	strategy := MergeStrategy(strategyStr)
+	if !ok {
+		return "", "", fmt.Errorf("invalid merge strategy %q, expected FIELD=STRATEGY", s)
+	}
+	if err := checkMergeStrategy(field, strategy); err != nil {
+		return "", "", err
+	}
+	return field, strategy, nil
+}
+
+// checkMergeStrategy returns an error if field can't be merged with strategy
+func checkMergeStrategy(field string, strategy MergeStrategy) error {
+	strategies, ok := mergeStrategies[field]
+	if !ok {
+		var fields []string
+		for field := range mergeStrategies {
+			fields = append(fields, field)
+		}
+		slices.Sort(fields)
+		return fmt.Errorf("unknown merge strategy field %q, must be one of: %s", field, strings.Join(fields, ", "))
+	}
+	if !slices.Contains(strategies, strategy) {
+		return fmt.Errorf("%s can't be merged with %q, must be one of: %s", field, strategy, joinStrategies(strategies))
+	}
+	return nil
+}
+
+func joinStrategies(strategies []MergeStrategy) string {
+	strs := make([]string, len(strategies))
+	for i, strategy := range strategies {
+		strs[i] = string(strategy)
+	}
+	return strings.Join(strs, ", ")
+}
+
+// SetMergeStrategy sets the strategy field is merged with when the files describing an addon disagree
+func (b *Builder) SetMergeStrategy(field string, strategy MergeStrategy) error {
+	if err := checkMergeStrategy(field, strategy); err != nil {
+		return err
+	}
+	b.mergeStrategies[field] = strategy
+	return nil
+}
+
+// mergeStrategy returns the strategy field is merged with
+func (b *Builder) mergeStrategy(field string) MergeStrategy {
+	if strategy, ok := b.mergeStrategies[field]; ok {
+		return strategy
+	}
+	if len(mergeStrategies[field]) > 0 {
+		return mergeStrategies[field][0]
+	}
+	return PreferLast
+}
+
+// keepText reports whether next replaces current, the value of a text field so far
+func keepText(strategy MergeStrategy, current, next string) bool {
+	if strategy == PreferLongest {
+		return utf8.RuneCountInString(next) >= utf8.RuneCountInString(current)
+	}
+	return true
+}
+
+// keepCount reports whether next replaces current, the value of a count field so far, nil if unset
+func keepCount(strategy MergeStrategy, current *int, next int) bool {
+	if strategy == PreferMax && current != nil {
+		return next >= *current
+	}
+	return true
+}
+
+// keepDate reports whether next replaces current, the value of a date field so far, zero if unset
+func keepDate(strategy MergeStrategy, current, next time.Time) bool {
+	if strategy == PreferNewest {
+		return !next.Before(current)
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package catalogue
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// strategyAddonData are two files disagreeing about every field, the API's merged last
+func strategyAddonData() []types.AddonData {
+	return []types.AddonData{
+		{
+			Source: types.WowInterfaceSource, SourceID: "1", Kind: types.WebDetailData,
+			Description:   "Sorts your bags into sections, with a search and filters for every item category",
+			UpdatedDate:   timePtr(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)),
+			DownloadCount: intPtr(5000),
+			GameTrackSet:  map[types.GameTrack]bool{types.RetailTrack: true},
+			TagSet:        map[string]bool{"bags": true},
+		},
+		{
+			Source: types.WowInterfaceSource, SourceID: "1", Kind: types.APIDetailData,
+			Description:   "[b]Sorts your bags[/b]",
+			UpdatedDate:   timePtr(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)),
+			DownloadCount: intPtr(4900),
+			GameTrackSet:  map[types.GameTrack]bool{types.ClassicTrack: true},
+			TagSet:        map[string]bool{"inventory": true},
+		},
+	}
+}
+
+func TestBuilder_MergeStrategies(t *testing.T) {
+	tests := []struct {
+		field    string
+		strategy MergeStrategy
+		check    func(addon *types.Addon) bool
+	}{
+		{"description", PreferLongest, func(a *types.Addon) bool { return strings.HasPrefix(a.Description, "Sorts your bags into sections") }},
+		{"description", PreferLast, func(a *types.Addon) bool { return a.Description == "[b]Sorts your bags[/b]" }},
+		{"download-count", PreferMax, func(a *types.Addon) bool { return *a.DownloadCount == 5000 }},
+		{"download-count", PreferLast, func(a *types.Addon) bool { return *a.DownloadCount == 4900 }},
+		{"updated-date", PreferNewest, func(a *types.Addon) bool { return a.UpdatedDate.Month() == time.June }},
+		{"updated-date", PreferLast, func(a *types.Addon) bool { return a.UpdatedDate.Month() == time.May }},
+		{"game-track-list", Union, func(a *types.Addon) bool {
+			return reflect.DeepEqual(a.GameTrackList, []types.GameTrack{types.RetailTrack, types.ClassicTrack})
+		}},
+		{"game-track-list", PreferLast, func(a *types.Addon) bool {
+			return reflect.DeepEqual(a.GameTrackList, []types.GameTrack{types.ClassicTrack})
+		}},
+		{"tag-list", Union, func(a *types.Addon) bool { return reflect.DeepEqual(a.TagList, []string{"bags", "inventory"}) }},
+		{"tag-list", PreferLast, func(a *types.Addon) bool { return reflect.DeepEqual(a.TagList, []string{"inventory"}) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field+"="+string(tt.strategy), func(t *testing.T) {
+			builder := NewBuilder()
+			if err := builder.SetMergeStrategy(tt.field, tt.strategy); err != nil {
+				t.Fatalf("SetMergeStrategy() unexpected error: %v", err)
+			}
+			addon, provenance, err := builder.MergeAddonDataProvenance(strategyAddonData())
+			if err != nil || addon == nil {
+				t.Fatalf("MergeAddonDataProvenance() = %v, %v", addon, err)
+			}
+			if !tt.check(addon) {
+				t.Errorf("merged addon = %+v, not merged with %s", addon, tt.strategy)
+			}
+			if tt.strategy == PreferLast && !reflect.DeepEqual(provenance[tt.field], []string{"api-detail.json"}) {
+				t.Errorf("Provenance[%s] = %v, want the last file", tt.field, provenance[tt.field])
+			}
+		})
+	}
+}
+
+func TestBuilder_MergeStrategies_Defaults(t *testing.T) {
+	addon, provenance, err := NewBuilder().MergeAddonDataProvenance(strategyAddonData())
+	if err != nil || addon == nil {
+		t.Fatalf("MergeAddonDataProvenance() = %v, %v", addon, err)
+	}
+	// The page's longer description, larger count and later date win over the API merged after it
+	for _, field := range []string{"description", "download-count", "updated-date"} {
+		if !reflect.DeepEqual(provenance[field], []string{"web-detail.json"}) {
+			t.Errorf("Provenance[%s] = %v, want web-detail.json", field, provenance[field])
+		}
+	}
+	if len(addon.GameTrackList) != 2 || len(addon.TagList) != 2 {
+		t.Errorf("merged addon = %+v, want the game tracks and tags of both files", addon)
+	}
+}
+
+func TestBuilder_MergeStrategies_EqualValues(t *testing.T) {
+	// The last file wins ties, as with PreferLast
+	addonData := strategyAddonData()
+	addonData[1].Description = strings.Repeat("x", len(addonData[0].Description))
+	addonData[1].DownloadCount = intPtr(5000)
+	addonData[1].UpdatedDate = addonData[0].UpdatedDate
+	_, provenance, _ := NewBuilder().MergeAddonDataProvenance(addonData)
+	for _, field := range []string{"description", "download-count", "updated-date"} {
+		if !reflect.DeepEqual(provenance[field], []string{"api-detail.json"}) {
+			t.Errorf("Provenance[%s] = %v, want api-detail.json", field, provenance[field])
+		}
+	}
+}
+
+func TestParseMergeStrategy(t *testing.T) {
+	tests := []struct {
+		input        string
+		wantField    string
+		wantStrategy MergeStrategy
+		wantErr      bool
+	}{
+		{"description=prefer-last", "description", PreferLast, false},
+		{"favorite-count=prefer-max", "favorite-count", PreferMax, false},
+		{"tag-list=union", "tag-list", Union, false},
+		{"description", "", "", true},
+		{"description=prefer-max", "", "", true}, // not a count
+		{"url=prefer-longest", "", "", true},     // always the last file's
+		{"description=", "", "", true},
+	}
+	for _, tt := range tests {
+		field, strategy, err := ParseMergeStrategy(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMergeStrategy(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if field != tt.wantField || strategy != tt.wantStrategy {
+			t.Errorf("ParseMergeStrategy(%q) = %s, %s, want %s, %s", tt.input, field, strategy, tt.wantField, tt.wantStrategy)
+		}
+	}
+
+	if err := NewBuilder().SetMergeStrategy("updated-date", Union); err == nil {
+		t.Error("SetMergeStrategy(updated-date, union) expected an error")
+	}
+}
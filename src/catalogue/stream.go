@@ -0,0 +1,93 @@
+package catalogue
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// WriteJSON writes a catalogue as indented JSON, one addon at a time.
+// The output is identical to json.MarshalIndent(catalogue, "", "  ") without holding the
+// whole encoded catalogue in memory.
+func WriteJSON(w io.Writer, catalogue types.Catalogue) error {
+	return writeJSON(w, catalogue, true)
+}
+
+// WriteCompactJSON writes a catalogue as JSON without whitespace, one addon at a time.
+// The output is identical to json.Marshal(catalogue).
+func WriteCompactJSON(w io.Writer, catalogue types.Catalogue) error {
+	return writeJSON(w, catalogue, false)
+}
+
+func writeJSON(w io.Writer, catalogue types.Catalogue, indent bool) error {
+	bw := bufio.NewWriter(w)
+
+	// Whitespace between tokens, empty when compact
+	nl, in1, in2, sp := "\n", "  ", "    ", " "
+	if !indent {
+		nl, in1, in2, sp = "", "", "", ""
+	}
+
+	fmt.Fprintf(bw, "{%s%s\"spec\":%s{%s%s\"version\":%s%d%s%s},%s", nl, in1, sp, nl, in2, sp, catalogue.Spec.Version, nl, in1, nl)
+
+	datestamp, err := json.Marshal(catalogue.Datestamp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal datestamp: %w", err)
+	}
+	fmt.Fprintf(bw, "%s\"datestamp\":%s%s,%s%s\"total\":%s%d,%s%s\"addon-summary-list\":%s", in1, sp, datestamp, nl, in1, sp, catalogue.Total, nl, in1, sp)
+
+	switch {
+	case catalogue.AddonSummaryList == nil:
+		bw.WriteString("null")
+	case len(catalogue.AddonSummaryList) == 0:
+		bw.WriteString("[]")
+	default:
+		bw.WriteString("[" + nl)
+		for i, addon := range catalogue.AddonSummaryList {
+			var data []byte
+			var err error
+			if indent {
+				data, err = json.MarshalIndent(addon, in2, "  ")
+			} else {
+				data, err = json.Marshal(addon)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to marshal addon %s/%s: %w", addon.Source, addon.SourceID, err)
+			}
+			bw.WriteString(in2)
+			bw.Write(data)
+			if i < len(catalogue.AddonSummaryList)-1 {
+				bw.WriteString(",")
+			}
+			bw.WriteString(nl)
+		}
+		bw.WriteString(in1 + "]")
+	}
+	bw.WriteString(nl + "}")
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write catalogue: %w", err)
+	}
+	return nil
+}
+
+// WriteNDJSON writes a catalogue's addons as newline delimited JSON, one addon per line.
+// Catalogue metadata (spec, datestamp, total) is not part of the output.
+func WriteNDJSON(w io.Writer, catalogue types.Catalogue) error {
+	bw := bufio.NewWriter(w)
+
+	encoder := json.NewEncoder(bw)
+	for _, addon := range catalogue.AddonSummaryList {
+		if err := encoder.Encode(addon); err != nil {
+			return fmt.Errorf("failed to write addon %s/%s: %w", addon.Source, addon.SourceID, err)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write catalogue: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package catalogue
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func streamTestCatalogue() types.Catalogue {
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	downloads := 123
+	addons := []types.Addon{
+		{
+			CreatedDate:   &created,
+			Description:   `Quotes "and" <html> & unicode ✓`,
+			DownloadCount: &downloads,
+			GameTrackList: []types.GameTrack{types.RetailTrack, types.ClassicTrack},
+			Label:         "Addon One",
+			Name:          "addon-one",
+			Source:        types.WowInterfaceSource,
+			SourceID:      "1",
+			TagList:       []string{"ui"},
+			URL:           "https://www.wowinterface.com/downloads/info1",
+			UpdatedDate:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			GameTrackList: []types.GameTrack{},
+			Label:         "Addon Two",
+			Name:          "addon-two",
+			SameAs:        []types.AddonRef{{Source: types.WowInterfaceSource, SourceID: "1"}},
+			Source:        types.GitHubSource,
+			SourceID:      "owner/two",
+			URL:           "https://github.com/owner/two",
+			UpdatedDate:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	cat := NewBuilder().BuildCatalogue(addons, nil)
+	cat.Datestamp = "2024-03-01"
+	return cat
+}
+
+func TestWriteJSON_MatchesMarshalIndent(t *testing.T) {
+	empty := streamTestCatalogue()
+	empty.AddonSummaryList = []types.Addon{}
+	empty.Total = 0
+
+	null := streamTestCatalogue()
+	null.AddonSummaryList = nil
+	null.Total = 0
+
+	tests := []struct {
+		name      string
+		catalogue types.Catalogue
+	}{
+		{"addons", streamTestCatalogue()},
+		{"empty addon list", empty},
+		{"nil addon list", null},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := json.MarshalIndent(tt.catalogue, "", "  ")
+			if err != nil {
+				t.Fatalf("MarshalIndent() error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := WriteJSON(&buf, tt.catalogue); err != nil {
+				t.Fatalf("WriteJSON() error: %v", err)
+			}
+
+			if buf.String() != string(expected) {
+				t.Errorf("WriteJSON() =\n%s\nwant\n%s", buf.String(), expected)
+			}
+		})
+	}
+}
+
+func TestWriteCompactJSON_MatchesMarshal(t *testing.T) {
+	empty := streamTestCatalogue()
+	empty.AddonSummaryList = []types.Addon{}
+	empty.Total = 0
+
+	for _, cat := range []types.Catalogue{streamTestCatalogue(), empty} {
+		expected, err := json.Marshal(cat)
+		if err != nil {
+			t.Fatalf("Marshal() error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := WriteCompactJSON(&buf, cat); err != nil {
+			t.Fatalf("WriteCompactJSON() error: %v", err)
+		}
+
+		if buf.String() != string(expected) {
+			t.Errorf("WriteCompactJSON() =\n%s\nwant\n%s", buf.String(), expected)
+		}
+	}
+}
+
+// Nested objects are written with their keys in alphabetical order, like the maps encoding/json sorts,
+// so equal catalogues are byte-identical and diff cleanly
+func TestWriteJSON_CanonicalKeyOrder(t *testing.T) {
+	for _, v := range []any{types.Addon{}, types.AddonRef{}, types.Release{}} {
+		typ := reflect.TypeOf(v)
+		var names []string
+		for i := 0; i < typ.NumField(); i++ {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			names = append(names, name)
+		}
+		if !slices.IsSorted(names) {
+			t.Errorf("%s JSON fields = %v, want them in alphabetical order", typ.Name(), names)
+		}
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	cat := streamTestCatalogue()
+
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, cat); err != nil {
+		t.Fatalf("WriteNDJSON() error: %v", err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	var lines int
+	for scanner.Scan() {
+		var addon types.Addon
+		if err := json.Unmarshal(scanner.Bytes(), &addon); err != nil {
+			t.Fatalf("line %d is not an addon: %v", lines+1, err)
+		}
+		if addon.SourceID != cat.AddonSummaryList[lines].SourceID {
+			t.Errorf("line %d SourceID = %s, want %s", lines+1, addon.SourceID, cat.AddonSummaryList[lines].SourceID)
+		}
+		lines++
+	}
+
+	if lines != cat.Total {
+		t.Errorf("lines = %d, want %d", lines, cat.Total)
+	}
+}
@@ -0,0 +1,168 @@
+// Package circuit stops requests to an upstream host that keeps failing.
+//
+// A breaker per host opens after a number of consecutive failures and rejects requests
+// immediately until a cool-down has passed. The next request is then let through as a
+// trial: success closes the breaker, failure opens it for another cool-down.
+package circuit
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned for requests rejected by an open breaker
+var ErrOpen = errors.New("circuit breaker open")
+
+// State of a single host's breaker
+type State string
+
+const (
+	Closed   State = "closed"    // requests flow normally
+	Open     State = "open"      // requests are rejected until the cool-down has passed
+	HalfOpen State = "half-open" // a single trial request is in flight
+)
+
+// Config holds circuit breaker configuration
+type Config struct {
+	FailureThreshold int           // consecutive failures before the breaker opens
+	CoolDown         time.Duration // how long the breaker stays open before a trial request
+}
+
+// DefaultConfig returns defaults tolerant of the odd failure but quick to back off a struggling host
+func DefaultConfig() Config {
+	return Config{
+		FailureThreshold: 5,
+		CoolDown:         30 * time.Second,
+	}
+}
+
+// breaker tracks the state of a single host
+type breaker struct {
+	state    State
+	failures int
+	openedAt time.Time
+}
+
+// Transport implements http.RoundTripper with a circuit breaker per host.
+// It is safe for concurrent use, breakers are shared by every request made through it.
+type Transport struct {
+	config    Config
+	transport http.RoundTripper
+	now       func() time.Time
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+// NewTransport creates a new circuit breaking transport wrapping transport
+func NewTransport(config Config, transport http.RoundTripper) *Transport {
+	return &Transport{
+		config:    config,
+		transport: transport,
+		now:       time.Now,
+		breakers:  make(map[string]*breaker),
+	}
+}
+
+// RoundTrip implements http.RoundTripper, failing fast with ErrOpen while the host's breaker is open
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+
+	if err := t.allow(host); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	t.record(host, err == nil && resp.StatusCode < 500)
+	return resp, err
+}
+
+// State returns the current state of the host's breaker
+func (t *Transport) State(host string) State {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if b, ok := t.breakers[host]; ok {
+		return b.state
+	}
+	return Closed
+}
+
+// Reset closes every breaker, e.g. at the start of each scrape of the daemon, so hosts that failed during the last
+// one are tried again
+func (t *Transport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.breakers = make(map[string]*breaker)
+}
+
+// allow returns an error if a request to host should be rejected
+func (t *Transport) allow(host string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := t.breaker(host)
+	switch b.state {
+	case Open:
+		if t.now().Sub(b.openedAt) < t.config.CoolDown {
+			return fmt.Errorf("%w for %s", ErrOpen, host)
+		}
+		t.transition(host, b, HalfOpen)
+		return nil
+	case HalfOpen:
+		// only the trial request goes through
+		return fmt.Errorf("%w for %s", ErrOpen, host)
+	default:
+		return nil
+	}
+}
+
+// record updates the host's breaker with the outcome of a request
+func (t *Transport) record(host string, success bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := t.breaker(host)
+	if success {
+		b.failures = 0
+		if b.state != Closed {
+			t.transition(host, b, Closed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == HalfOpen || (b.state == Closed && b.failures >= t.config.FailureThreshold) {
+		b.openedAt = t.now()
+		t.transition(host, b, Open)
+	}
+}
+
+// breaker returns the host's breaker, creating it if necessary. Callers must hold mu.
+func (t *Transport) breaker(host string) *breaker {
+	b, ok := t.breakers[host]
+	if !ok {
+		b = &breaker{state: Closed}
+		t.breakers[host] = b
+	}
+	return b
+}
+
+// transition changes a breaker's state and logs it. Callers must hold mu.
+func (t *Transport) transition(host string, b *breaker, to State) {
+	from := b.state
+	b.state = to
+
+	switch to {
+	case Open:
+		slog.Warn("circuit breaker opened", "host", host, "from", from, "failures", b.failures, "cool-down", t.config.CoolDown)
+	case HalfOpen:
+		slog.Info("circuit breaker half-open, sending trial request", "host", host)
+	case Closed:
+		slog.Info("circuit breaker closed", "host", host, "from", from)
+	}
+}
@@ -0,0 +1,132 @@
+package circuit
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// stubTransport returns the configured status code (or error) for every request
+type stubTransport struct {
+	status int
+	err    error
+	calls  int
+}
+
+func (s *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &http.Response{StatusCode: s.status, Body: http.NoBody, Request: req}, nil
+}
+
+func get(t *testing.T, transport http.RoundTripper, rawURL string) error {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error: %v", err)
+	}
+	_, err = transport.RoundTrip(req)
+	return err
+}
+
+func TestTransport_OpensAfterThreshold(t *testing.T) {
+	stub := &stubTransport{status: 503}
+	transport := NewTransport(Config{FailureThreshold: 3, CoolDown: time.Minute}, stub)
+
+	for i := 0; i < 3; i++ {
+		if err := get(t, transport, "https://example.org/"); err != nil {
+			t.Fatalf("request %d unexpected error: %v", i, err)
+		}
+	}
+
+	if state := transport.State("example.org"); state != Open {
+		t.Errorf("State() = %v, want %v", state, Open)
+	}
+
+	err := get(t, transport, "https://example.org/other")
+	if !errors.Is(err, ErrOpen) {
+		t.Errorf("error = %v, want %v", err, ErrOpen)
+	}
+	if stub.calls != 3 {
+		t.Errorf("upstream calls = %d, want 3", stub.calls)
+	}
+
+	// other hosts are unaffected
+	if err := get(t, transport, "https://example.com/"); err != nil {
+		t.Errorf("request to other host unexpected error: %v", err)
+	}
+}
+
+func TestTransport_SuccessResetsFailures(t *testing.T) {
+	stub := &stubTransport{status: 500}
+	transport := NewTransport(Config{FailureThreshold: 2, CoolDown: time.Minute}, stub)
+
+	get(t, transport, "https://example.org/")
+	stub.status = 200
+	get(t, transport, "https://example.org/")
+	stub.status = 500
+	get(t, transport, "https://example.org/")
+
+	if state := transport.State("example.org"); state != Closed {
+		t.Errorf("State() = %v, want %v", state, Closed)
+	}
+}
+
+func TestTransport_HalfOpen(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	stub := &stubTransport{err: errors.New("connection refused")}
+	transport := NewTransport(Config{FailureThreshold: 1, CoolDown: time.Minute}, stub)
+	transport.now = func() time.Time { return now }
+
+	get(t, transport, "https://example.org/")
+	if state := transport.State("example.org"); state != Open {
+		t.Fatalf("State() = %v, want %v", state, Open)
+	}
+
+	// failed trial after the cool-down re-opens
+	now = now.Add(time.Minute)
+	if err := get(t, transport, "https://example.org/"); errors.Is(err, ErrOpen) {
+		t.Errorf("trial request rejected, want it sent upstream")
+	}
+	if state := transport.State("example.org"); state != Open {
+		t.Errorf("State() after failed trial = %v, want %v", state, Open)
+	}
+
+	// still cooling down from the failed trial
+	now = now.Add(30 * time.Second)
+	if err := get(t, transport, "https://example.org/"); !errors.Is(err, ErrOpen) {
+		t.Errorf("error = %v, want %v", err, ErrOpen)
+	}
+
+	// successful trial closes
+	now = now.Add(30 * time.Second)
+	stub.err = nil
+	stub.status = 200
+	if err := get(t, transport, "https://example.org/"); err != nil {
+		t.Errorf("trial request unexpected error: %v", err)
+	}
+	if state := transport.State("example.org"); state != Closed {
+		t.Errorf("State() after successful trial = %v, want %v", state, Closed)
+	}
+}
+
+func TestTransport_Reset(t *testing.T) {
+	stub := &stubTransport{status: 503}
+	transport := NewTransport(Config{FailureThreshold: 1, CoolDown: time.Hour}, stub)
+
+	get(t, transport, "https://example.org/")
+	if state := transport.State("example.org"); state != Open {
+		t.Fatalf("State() = %v, want %v", state, Open)
+	}
+
+	transport.Reset()
+	if err := get(t, transport, "https://example.org/"); err != nil {
+		t.Errorf("request after Reset() unexpected error: %v", err)
+	}
+	if stub.calls != 2 {
+		t.Errorf("upstream calls = %d, want 2", stub.calls)
+	}
+}
@@ -1,42 +1,243 @@
 package cli
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
-	"sync/atomic"
+	"text/tabwriter"
 	"time"
 
+	"github.com/ogri-la/strongbox-catalogue-builder-go/pkg/builder"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/addondata"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/cache"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/catalogue"
-	"github.com/ogri-la/strongbox-catalogue-builder-go/src/github"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/daemon"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/gate"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/history"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/linkcheck"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/lint"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/notify"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/publish"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/report"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/retry"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/search"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/server"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/signing"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/state"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/townlongyak"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/validation"
-	"github.com/ogri-la/strongbox-catalogue-builder-go/src/wowi"
+	"golang.org/x/sync/errgroup"
 )
 
-// ScrapeConfig holds configuration for scraping
+// ScrapeConfig holds configuration for scraping: the options of the scrape, and what the command does around it
 type ScrapeConfig struct {
-	HTTPClient     http.HTTPClient
-	Sources        []types.Source
-	MaxWorkers     int
-	WoWIAPIVersion wowi.APIVersion
+	builder.Options
+	NotifyURL    string        // webhook posted a summary when the scrape finishes or fails, optional
+	NotifyFormat notify.Format // body of the webhook request
+	DryRun       bool          // only discover what would be fetched and print an estimate of how long it would take
+	Out          io.Writer     // where DryRun prints, stdout if nil
 }
 
+// DefaultScrapeConfig returns the configuration of the scrape command without options, less its HTTP client
+func DefaultScrapeConfig() ScrapeConfig {
+	return ScrapeConfig{
+		Options:      builder.DefaultOptions(),
+		NotifyFormat: notify.JSONFormat,
+	}
+}
+
+// OutputFormat is the file format catalogues are written in
+type OutputFormat string
+
+const (
+	JSONFormat   OutputFormat = "json"
+	SQLiteFormat OutputFormat = "sqlite"
+	NDJSONFormat OutputFormat = "ndjson" // one addon per line, no catalogue metadata
+)
+
+var KnownOutputFormats = []OutputFormat{JSONFormat, SQLiteFormat, NDJSONFormat}
+
+// defaultStateDir is where scrape writes catalogues and write reads them back from
+const defaultStateDir = state.DefaultDir
+
+// defaultBlocklist lists addons to leave out of catalogues, if it exists
+const defaultBlocklist = "blocklist.json"
+
+// defaultOverrides patches fields of scraped addons, if it exists
+const defaultOverrides = "overrides.json"
+
 // WriteConfig holds configuration for writing catalogues
 type WriteConfig struct {
 	Sources     []types.Source
 	OutputFiles []string
+	Format      OutputFormat
+	ChangesFile string // optional changes feed, diffed against the first output file's previous contents
+	StateDir    string
+	Blocklist   string // addons to leave out of the catalogue, optional
+	Overrides   string // patches to scraped addons, optional
+	Allowlist   string // only include these addons, optional
+	SpecVersion int    // catalogue spec version written, 0 for the default
+	Datestamp   string // datestamp of the catalogue written, today if empty
+	NoIndent    bool   // write the catalogue as compact JSON
+	Manifest    bool   // write the sha256 of each output file to catalogue.sha256 beside the first
+	SignKey     string // minisign secret key to sign each output file (and the manifest) with, optional
+}
+
+// ServeConfig holds configuration for serving catalogues
+type ServeConfig struct {
+	Addr        string
+	StateDir    string
+	CacheMaxAge time.Duration
+}
+
+// DaemonConfig holds configuration for scraping on a schedule in a long-lived process
+type DaemonConfig struct {
+	Scrape       ScrapeConfig
+	Schedule     daemon.Schedule
+	Addr         string
+	CacheMaxAge  time.Duration
+	BeforeScrape func() // called before each scrape, e.g. to start the HTTP client afresh, optional
+	AfterScrape  func() // called after each scrape, e.g. to save the cache index, optional
+}
+
+// ReportFormat is the format validation results are reported in
+type ReportFormat string
+
+const (
+	TextReport  ReportFormat = "text" // log messages
+	JSONReport  ReportFormat = "json"
+	SARIFReport ReportFormat = "sarif"
+)
+
+var KnownReportFormats = []ReportFormat{TextReport, JSONReport, SARIFReport}
+
+// ValidateConfig holds configuration for validating catalogues
+type ValidateConfig struct {
+	Paths           []string // files or glob patterns
+	Quiet           bool     // only log failures and totals
+	MaxErrors       int      // problems logged per file with the text format, 0 for all
+	Format          ReportFormat
+	Strict          bool   // also reject duplicates, unknown tags, mismatched URLs and the like
+	VerifySignature bool   // also check each catalogue's .minisig signature against PublicKey
+	PublicKey       string // minisign public key, or the file holding it
+	MaxWorkers      int
+}
+
+// PublishConfig holds configuration for publishing catalogues
+type PublishConfig struct {
+	StateDir     string
+	Repo         string         // GitHub repository, as owner/name, whose release the catalogues are attached to
+	Tag          string         // tag of the release
+	Token        string         // authenticates with GitHub, never logged
+	S3URL        string         // s3://bucket/prefix to publish to instead of a GitHub release, optional
+	S3Endpoint   string         // URL of an S3-compatible store, AWS if empty
+	S3Region     string         // region S3 requests are signed for
+	CacheControl string         // Cache-Control of objects published to S3
+	Target       publish.Target // where the catalogues are published, built from the above if nil
+}
+
+// CacheAction is what the cache command does
+type CacheAction string
+
+const (
+	CacheStatsAction  CacheAction = "stats"  // totals per host and hit rates from the last run
+	CacheListAction   CacheAction = "ls"     // cached URLs
+	CacheExportAction CacheAction = "export" // pack the cache into an archive
+	CacheImportAction CacheAction = "import" // restore an archive into the cache
+)
+
+var KnownCacheActions = []CacheAction{CacheStatsAction, CacheListAction, CacheExportAction, CacheImportAction}
+
+// sourceHosts are the hosts each source's pages are fetched from
+var sourceHosts = map[types.Source][]string{
+	types.WowInterfaceSource: {"www.wowinterface.com", "api.mmoui.com", "cdn-wow.mmoui.com"},
+	types.GitHubSource:       {"github.com", "raw.githubusercontent.com", "api.github.com"},
+	types.GitLabSource:       {"gitlab.com"},
+	types.CodebergSource:     {"codeberg.org"},
+	types.WagoSource:         {"addons.wago.io"},
+	types.TownlongYakSource:  {townlongyak.Host},
+}
+
+// CacheCommandConfig holds configuration for inspecting the HTTP cache
+type CacheCommandConfig struct {
+	Action  CacheAction
+	Sources []types.Source // only list pages fetched for these sources, all if empty
+	Archive string         // .tar.zst file to export the cache to or import it from
+	Dir     string         // cache directory, holding the index
+	Store   cache.Store    // cache entries
+	Out     io.Writer      // stdout if nil
+}
+
+// TrendConfig holds configuration for reporting download count trends
+type TrendConfig struct {
+	StateDir string
+	Period   time.Duration // compare the latest snapshot with one taken at least this long before
+	Limit    int           // fastest growing addons reported, 0 for all
+	Out      io.Writer     // stdout if nil
+}
+
+// SearchConfig holds configuration for searching a catalogue
+type SearchConfig struct {
+	Query     string
+	Catalogue string         // catalogue file, or a directory holding a full-catalogue.json
+	Sources   []types.Source // only search addons of these sources, all if empty
+	Limit     int            // matches printed, 0 for all
+	Out       io.Writer      // stdout if nil
+}
+
+// LintConfig holds configuration for linting a catalogue
+type LintConfig struct {
+	Path       string                 // catalogue file, or a directory holding a full-catalogue.json
+	Thresholds map[lint.Check]float64 // percent of addons that may fail each check
+	Limit      int                    // addons printed for each check, 0 for all
+	Out        io.Writer              // stdout if nil
+}
+
+// CheckLinksConfig holds configuration for checking release download URLs
+type CheckLinksConfig struct {
+	Releases   string          // releases manifest, or a directory holding a releases.json
+	Sources    []types.Source  // only check the links of these sources, all if empty
+	Sample     int             // links checked, picked at random, 0 for all
+	Seed       uint64          // picks the sample, random if 0
+	Interval   time.Duration   // minimum delay between requests
+	MaxWorkers int             // concurrent requests
+	HTTPClient http.HTTPClient // makes HEAD requests without following redirects, see http.NewHeadClient
+	Out        io.Writer       // stdout if nil
+}
+
+// ShowConfig holds configuration for showing everything known about an addon
+type ShowConfig struct {
+	Source   types.Source
+	SourceID string
+	StateDir string    // directory the last scrape wrote its catalogues and addon data to
+	Out      io.Writer // stdout if nil
+}
+
+// MergeConfig holds configuration for merging catalogue files
+type MergeConfig struct {
+	Paths       []string // catalogues to merge, earlier files win ties
+	OutputFile  string   // stdout if empty
+	SpecVersion int      // catalogue spec version written, 0 for the default
+	Datestamp   string   // datestamp of the merged catalogue, today if empty
+	NoIndent    bool     // write the merged catalogue as compact JSON
 }
 
 // CommandHandler handles CLI commands
 type CommandHandler struct {
-	builder *catalogue.Builder
+	builder  *catalogue.Builder
+	noIndent bool // write catalogues as compact JSON, set by the command
 }
 
 // NewCommandHandler creates a new command handler
@@ -46,323 +247,894 @@ func NewCommandHandler() *CommandHandler {
 	}
 }
 
-// Scrape executes the scrape command
+// Scrape executes the scrape command, posting a summary to the notification webhook, if any, however it ends
 func (h *CommandHandler) Scrape(ctx context.Context, config ScrapeConfig) error {
-	slog.Info("starting scrape command", "sources", config.Sources)
-
-	var allAddons []types.Addon
-	var mu sync.Mutex
+	if config.DryRun {
+		return h.dryRun(ctx, config)
+	}
+	_, err := h.scrapeAndNotify(ctx, config)
+	return err
+}
 
-	// Process each source
+// scrapeAndNotify scrapes, posts the summary to the notification webhook, if any, and returns it
+func (h *CommandHandler) scrapeAndNotify(ctx context.Context, config ScrapeConfig) (notify.Summary, error) {
+	summary := notify.Summary{StartedAt: time.Now().UTC()}
+	cat, rep, err := builder.Scrape(ctx, config.Options)
+	summary.FinishedAt = time.Now().UTC()
 	for _, source := range config.Sources {
-		switch source {
-		case types.WowInterfaceSource:
-			addons, err := h.scrapeWowInterface(ctx, config.HTTPClient, config.MaxWorkers, config.WoWIAPIVersion)
-			if err != nil {
-				return fmt.Errorf("failed to scrape WowInterface: %w", err)
-			}
-
-			mu.Lock()
-			allAddons = append(allAddons, addons...)
-			mu.Unlock()
+		summary.Sources = append(summary.Sources, types.Source(source))
+	}
+	summary.Total = cat.Total
+	if cat.Total > 0 {
+		summary.SourceTotals = make(map[types.Source]int)
+		for _, addon := range cat.AddonSummaryList {
+			summary.SourceTotals[types.Source(addon.Source)]++
+		}
+	}
+	summary.Added, summary.Updated, summary.Removed = rep.Added, rep.Updated, rep.Removed
+	summary.Failures = len(rep.FetchFailures) + len(rep.ParseFailures)
+	summary.GatePassed = rep.GatePassed
+	summary.Status = notify.Finished
+	if err != nil {
+		summary.Status, summary.Error = notify.Failed, err.Error()
+	}
+	if config.NotifyURL == "" {
+		return summary, err
+	}
 
-		case types.GitHubSource:
-			addons, err := h.scrapeGitHub(ctx)
-			if err != nil {
-				return fmt.Errorf("failed to scrape GitHub: %w", err)
-			}
+	// The scrape may have ended because ctx did
+	notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+	defer cancel()
+	if notifyErr := notify.New(config.NotifyURL, config.NotifyFormat).Notify(notifyCtx, summary); notifyErr != nil {
+		slog.Warn("failed to send notification", "error", notifyErr)
+	} else {
+		slog.Info("sent notification", "status", summary.Status, "format", config.NotifyFormat)
+	}
+	return summary, err
+}
 
-			mu.Lock()
-			allAddons = append(allAddons, addons...)
-			mu.Unlock()
+// Write executes the write command (reads from state files)
+func (h *CommandHandler) Write(ctx context.Context, config WriteConfig) error {
+	slog.Info("starting write command", "sources", config.Sources, "format", config.Format)
 
-		default:
-			slog.Warn("unsupported source", "source", source)
+	if err := h.builder.SetSpecVersion(config.SpecVersion); err != nil {
+		return err
+	}
+	if err := h.builder.SetDatestamp(config.Datestamp); err != nil {
+		return err
+	}
+	h.noIndent = config.NoIndent
+	if err := h.builder.LoadBlocklist(config.Blocklist); err != nil {
+		return err
+	}
+	if err := h.builder.LoadOverrides(config.Overrides); err != nil {
+		return err
+	}
+	if config.Allowlist != "" {
+		if err := h.builder.LoadAllowlist(config.Allowlist); err != nil {
+			return err
 		}
 	}
 
-	// Build full catalogue with all sources
-	fullCatalogue := h.builder.BuildCatalogue(allAddons, config.Sources)
-	slog.Info("built catalogue", "total-addons", fullCatalogue.Total)
+	// Read addons from the full catalogue written by the last scrape
+	var addons []types.Addon
+	statePath := filepath.Join(config.StateDir, state.FullCatalogueFile)
+	fullCatalogue, err := catalogue.ReadCatalogue(statePath)
+	if err == nil {
+		addons = fullCatalogue.AddonSummaryList
+	} else if errors.Is(err, os.ErrNotExist) {
+		slog.Warn("no scraped state found, writing an empty catalogue", "file", statePath)
+	} else {
+		return err
+	}
 
-	// Create state directory
-	stateDir := "state"
-	if err := os.MkdirAll(stateDir, 0755); err != nil {
-		return fmt.Errorf("failed to create state directory: %w", err)
+	// Catalogues written to files are the ones that get released, refuse unless the scrape passed the gate
+	if len(config.OutputFiles) > 0 {
+		metadata, err := gate.ReadRunMetadata(filepath.Join(config.StateDir, state.RunMetadataFile))
+		if err != nil {
+			return err
+		}
+		if err := gate.RequirePassed(metadata, fullCatalogue); err != nil {
+			return err
+		}
 	}
 
-	// Cutoff date for "short" catalogue: Dragonflight expansion (2022-11-28)
-	cutoffDate := time.Date(2022, 11, 28, 0, 0, 0, 0, time.UTC)
+	signKey, err := state.ReadSignKey(config.SignKey)
+	if err != nil {
+		return err
+	}
 
-	// Write source-specific catalogues
-	for _, source := range config.Sources {
-		sourceCatalogue := h.builder.FilterCatalogue(fullCatalogue, func(addon types.Addon) bool {
-			return addon.Source == source
-		})
+	cat := h.builder.StripChangelogs(h.builder.BuildCatalogue(addons, config.Sources))
 
-		var filename string
-		switch source {
-		case types.WowInterfaceSource:
-			filename = "wowinterface-catalogue.json"
-		case types.GitHubSource:
-			filename = "github-catalogue.json"
-		default:
-			continue
+	if len(config.OutputFiles) == 0 {
+		if config.Format == SQLiteFormat {
+			return fmt.Errorf("the sqlite format requires an output file (--out)")
 		}
+		return h.writeCatalogueFormat(cat, "", config.Format)
+	}
 
-		outputPath := filepath.Join(stateDir, filename)
-		if err := h.writeCatalogue(sourceCatalogue, outputPath); err != nil {
+	// The first output file holds the previously published catalogue
+	var previousCatalogue *types.Catalogue
+	if config.ChangesFile != "" && config.Format == JSONFormat {
+		previousCatalogue = state.ReadPreviousCatalogue(config.OutputFiles[0])
+	}
+
+	for _, outputFile := range config.OutputFiles {
+		if err := h.writeCatalogueFormat(cat, outputFile, config.Format); err != nil {
 			return err
 		}
 	}
 
-	// Write full catalogue (all sources)
-	fullPath := filepath.Join(stateDir, "full-catalogue.json")
-	if err := h.writeCatalogue(fullCatalogue, fullPath); err != nil {
+	manifestPath := filepath.Join(filepath.Dir(config.OutputFiles[0]), signing.ManifestFile)
+	if err := state.SealCatalogues(config.OutputFiles, manifestPath, config.Manifest, signKey); err != nil {
 		return err
 	}
 
-	// Write short catalogue (maintained addons only)
-	shortCatalogue := h.builder.ShortenCatalogue(fullCatalogue, cutoffDate)
-	slog.Info("shortened catalogue", "original", fullCatalogue.Total, "maintained", shortCatalogue.Total, "cutoff", cutoffDate.Format("2006-01-02"))
-
-	shortPath := filepath.Join(stateDir, "short-catalogue.json")
-	if err := h.writeCatalogue(shortCatalogue, shortPath); err != nil {
-		return err
+	if config.ChangesFile != "" {
+		return state.UpdateChangesFeed(h.builder, previousCatalogue, cat, config.ChangesFile)
 	}
 
 	return nil
 }
 
-// Write executes the write command (reads from state files)
-func (h *CommandHandler) Write(ctx context.Context, config WriteConfig) error {
-	slog.Info("starting write command", "sources", config.Sources)
-
-	// For now, just create an empty catalogue since we don't have state file reading implemented
-	// In a full implementation, this would read addon data from state files
-	catalogue := h.builder.BuildCatalogue([]types.Addon{}, config.Sources)
+// Publish uploads the catalogues of the last scrape, with its manifest and signatures if any,
+// refusing unless the scrape passed the publish gate
+func (h *CommandHandler) Publish(ctx context.Context, config PublishConfig) error {
+	fullPath := filepath.Join(config.StateDir, state.FullCatalogueFile)
+	fullCatalogue, err := catalogue.ReadCatalogue(fullPath)
+	if err != nil {
+		return err
+	}
+	metadata, err := gate.ReadRunMetadata(filepath.Join(config.StateDir, state.RunMetadataFile))
+	if err != nil {
+		return err
+	}
+	if err := gate.RequirePassed(metadata, fullCatalogue); err != nil {
+		return err
+	}
+	if h.builder.ExtractChangelogs(fullCatalogue).Total > 0 {
+		return fmt.Errorf("%s holds changelogs, which are never published, scrape without --include-changelogs", fullPath)
+	}
 
-	if len(config.OutputFiles) == 0 {
-		return h.writeCatalogue(catalogue, "")
+	files, err := publishFiles(config.StateDir, metadata.Sources)
+	if err != nil {
+		return err
 	}
 
-	for _, outputFile := range config.OutputFiles {
-		if err := h.writeCatalogue(catalogue, outputFile); err != nil {
+	target := config.Target
+	switch {
+	case target != nil:
+	case config.S3URL != "":
+		bucket, prefix, err := publish.ParseS3URL(config.S3URL)
+		if err != nil {
 			return err
 		}
+		s3 := publish.NewS3(bucket, prefix, publish.S3CredentialsFromEnv())
+		s3.Endpoint, s3.Region, s3.CacheControl = config.S3Endpoint, config.S3Region, config.CacheControl
+		target = s3
+	default:
+		release := publish.NewGitHubRelease(config.Repo, config.Tag, config.Token)
+		release.Name = "Catalogue " + fullCatalogue.Datestamp
+		target = release
 	}
-
-	return nil
+	slog.Info("publishing catalogues", "files", len(files))
+	return target.Publish(ctx, files)
 }
 
-// scrapeWowInterface handles WowInterface-specific scraping logic
-func (h *CommandHandler) scrapeWowInterface(ctx context.Context, client http.HTTPClient, maxWorkers int, apiVersion wowi.APIVersion) ([]types.Addon, error) {
-	slog.Info("scraping WowInterface", "mode", "API + HTML detail pages", "api_version", apiVersion)
+// publishFiles returns the catalogues the last scrape of sources wrote to stateDir, followed by
+// its manifest and signatures. Manifests and signatures older than the full catalogue are left out,
+// they're from an earlier scrape.
+func publishFiles(stateDir string, sources []types.Source) ([]string, error) {
+	files := []string{filepath.Join(stateDir, state.FullCatalogueFile), filepath.Join(stateDir, state.ShortCatalogueFile)}
+	for _, source := range sources {
+		if filename, ok := state.SourceCatalogueFiles[source]; ok {
+			files = append(files, filepath.Join(stateDir, filename))
+		}
+	}
+	var full os.FileInfo
+	for i, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find catalogue to publish: %w", err)
+		}
+		if i == 0 {
+			full = info
+		}
+	}
+	current := func(path string) bool {
+		info, err := os.Stat(path)
+		return err == nil && !info.ModTime().Before(full.ModTime())
+	}
 
-	parser := wowi.NewParser()
+	if manifestPath := filepath.Join(stateDir, signing.ManifestFile); current(manifestPath) {
+		files = append(files, manifestPath)
+	}
+	for _, file := range slices.Clone(files) {
+		if current(file + signing.SignatureExt) {
+			files = append(files, file+signing.SignatureExt)
+		}
+	}
+	return files, nil
+}
 
-	// Track processed URLs and addon data
-	processedURLs := make(map[string]bool)
-	addonDataMap := make(map[string][]types.AddonData) // sourceID -> []AddonData
+// writeCatalogueFormat writes a catalogue to a file (or stdout, except sqlite) in the given format
+func (h *CommandHandler) writeCatalogueFormat(cat types.Catalogue, outputFile string, format OutputFormat) error {
+	switch format {
+	case SQLiteFormat:
+		if err := catalogue.WriteSQLite(cat, outputFile); err != nil {
+			return fmt.Errorf("failed to write catalogue to %s: %w", outputFile, err)
+		}
+		slog.Info("wrote catalogue", "file", outputFile, "format", format, "addons", cat.Total)
+		return nil
+	case NDJSONFormat:
+		if outputFile == "" {
+			return catalogue.WriteNDJSON(os.Stdout, cat)
+		}
+		if err := state.WriteFile(outputFile, func(w io.Writer) error { return catalogue.WriteNDJSON(w, cat) }); err != nil {
+			return err
+		}
+		slog.Info("wrote catalogue", "file", outputFile, "format", format, "addons", cat.Total)
+		return nil
+	default:
+		return h.writeCatalogue(cat, outputFile)
+	}
+}
 
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-	var inFlight atomic.Int32 // Track URLs currently being processed
-
-	// Create worker pool with larger buffer to handle API file list
-	// v3 API has ~7971 addons, each generating 2 URLs = ~16k URLs
-	urlChan := make(chan string, 20000)
-
-	// Start periodic queue status logger
-	stopLogger := make(chan bool)
-	go func() {
-		ticker := time.NewTicker(2 * time.Second)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				queueDepth := len(urlChan)
-				processing := inFlight.Load()
-				if queueDepth > 0 || processing > 0 {
-					slog.Info("queue status", "pending_urls", queueDepth, "processing", processing, "workers", maxWorkers)
-				}
-			case <-stopLogger:
-				return
-			}
+// Validate executes the validate command, validating every file before reporting failures
+func (h *CommandHandler) Validate(ctx context.Context, config ValidateConfig) error {
+	files, err := expandPaths(config.Paths)
+	if err != nil {
+		return err
+	}
+
+	var publicKey *signing.PublicKey
+	if config.VerifySignature {
+		key, err := signing.ReadPublicKey(config.PublicKey)
+		if err != nil {
+			return err
 		}
-	}()
+		publicKey = &key
+	}
 
-	// Start workers
-	for i := 0; i < maxWorkers; i++ {
+	errs := make([]error, len(files))
+	sem := make(chan struct{}, max(config.MaxWorkers, 1))
+	var wg sync.WaitGroup
+	for i, file := range files {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-
-			for url := range urlChan {
-				inFlight.Add(1)
-				if err := h.processURL(ctx, client, parser, url, &mu, processedURLs, addonDataMap, urlChan); err != nil {
-					slog.Error("failed to process URL", "url", url, "error", err)
-				}
-				inFlight.Add(-1)
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if config.Strict {
+				errs[i] = validation.StrictValidateCatalogueFile(file)
+			} else {
+				errs[i] = validation.ValidateCatalogueFile(file)
+			}
+			if errs[i] == nil && publicKey != nil {
+				errs[i] = publicKey.VerifyFile(file)
 			}
 		}()
 	}
+	wg.Wait()
+
+	failed := 0
+	results := make([]validation.FileResult, len(files))
+	for i, file := range files {
+		results[i] = validation.NewFileResult(file, errs[i])
+		if errs[i] != nil {
+			failed++
+		}
+
+		if config.Format != TextReport {
+			continue
+		}
+		if errs[i] != nil {
+			logValidationFailure(file, errs[i], config.MaxErrors)
+		} else if !config.Quiet {
+			slog.Info("validation successful", "file", file)
+		}
+	}
+
+	switch config.Format {
+	case JSONReport:
+		if err := validation.NewReport(results).WriteJSON(os.Stdout); err != nil {
+			return err
+		}
+	case SARIFReport:
+		if err := validation.NewReport(results).WriteSARIF(os.Stdout); err != nil {
+			return err
+		}
+	}
 
-	// Start with initial URL (API filelist only - HTML detail pages discovered from there)
-	for _, url := range wowi.StartingURLs(apiVersion) {
-		urlChan <- url
+	slog.Info("validated catalogues", "total", len(files), "passed", len(files)-failed, "failed", failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d catalogues failed validation", failed, len(files))
 	}
+	return nil
+}
 
-	// Monitor queue and close when all work is done
-	go func() {
-		ticker := time.NewTicker(500 * time.Millisecond)
-		defer ticker.Stop()
+// Schema writes the JSON Schema of the catalogue format to outputFile, or stdout if empty
+func (h *CommandHandler) Schema(ctx context.Context, outputFile string) error {
+	if outputFile == "" {
+		_, err := os.Stdout.Write(validation.CatalogueJSONSchema)
+		return err
+	}
 
-		for {
-			<-ticker.C
-			queueDepth := len(urlChan)
-			processing := inFlight.Load()
+	if err := os.WriteFile(outputFile, validation.CatalogueJSONSchema, 0644); err != nil {
+		return fmt.Errorf("failed to write schema to %s: %w", outputFile, err)
+	}
+	slog.Info("wrote catalogue schema", "file", outputFile)
+	return nil
+}
 
-			// We're done when queue is empty AND nothing is being processed
-			if queueDepth == 0 && processing == 0 {
-				slog.Info("all URLs processed, finishing scrape")
-				close(urlChan)
-				return
-			}
+// logValidationFailure logs each problem found in a file, up to maxErrors (0 for all)
+func logValidationFailure(file string, err error, maxErrors int) {
+	var validationErr *validation.ValidationError
+	if !errors.As(err, &validationErr) {
+		slog.Error("validation failed", "file", file, "error", err)
+		return
+	}
+
+	violations := validationErr.Violations
+	slog.Error("validation failed", "file", file, "problems", len(violations))
+	if maxErrors > 0 && len(violations) > maxErrors {
+		defer slog.Error("more problems not shown", "file", file, "problems", len(violations)-maxErrors, "max-errors", maxErrors)
+		violations = violations[:maxErrors]
+	}
+	for _, violation := range violations {
+		slog.Error("validation problem", "file", file, "problem", violation.Error())
+	}
+}
+
+// expandPaths expands glob patterns, returning each file once in the order given.
+// Files known not to be catalogues, such as the run metadata, are skipped so "state/*.json" can be validated
+// whether or not the shell expanded the pattern. Paths without glob characters are returned as-is,
+// even if the file doesn't exist, so they fail validation.
+func expandPaths(paths []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(file string) {
+		if slices.Contains(state.Files, filepath.Base(file)) {
+			slog.Info("skipping non-catalogue state file", "file", file)
+			return
+		}
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
 		}
-	}()
+	}
 
-	wg.Wait()
-	close(stopLogger)
+	for _, path := range paths {
+		if !strings.ContainsAny(path, "*?[") {
+			add(path)
+			continue
+		}
 
-	// Convert addon data to final addons
-	var addons []types.Addon
-	mu.Lock()
-	for sourceID, dataList := range addonDataMap {
-		if addon, err := h.builder.MergeAddonData(dataList); err == nil && addon != nil {
-			addons = append(addons, *addon)
-		} else if err != nil {
-			slog.Error("failed to merge addon data", "source-id", sourceID, "error", err)
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("bad glob pattern %q: %w", path, err)
 		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %q", path)
+		}
+		for _, match := range matches {
+			add(match)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no catalogues to validate")
+	}
+	return files, nil
+}
+
+// Serve executes the serve command, serving catalogues until ctx is cancelled
+func (h *CommandHandler) Serve(ctx context.Context, config ServeConfig) error {
+	slog.Info("serving catalogues", "addr", config.Addr, "state-dir", config.StateDir)
+
+	handler := server.NewHandler(config.StateDir, config.CacheMaxAge)
+	if err := server.ListenAndServe(ctx, config.Addr, handler); err != nil {
+		return err
+	}
+
+	slog.Info("stopped serving catalogues")
+	return nil
+}
+
+// Daemon executes the daemon command, scraping on a schedule and serving the latest catalogues with metrics until ctx is cancelled
+func (h *CommandHandler) Daemon(ctx context.Context, config DaemonConfig) error {
+	slog.Info("starting daemon", "schedule", config.Schedule, "addr", config.Addr, "state-dir", config.Scrape.StateDir)
+
+	metrics := daemon.NewMetrics()
+	if config.Scrape.TraceStats != nil {
+		metrics.SetHostTraces(config.Scrape.TraceStats.TraceStats)
+	}
+	handler := daemon.Handler(server.NewHandler(config.Scrape.StateDir, config.CacheMaxAge), metrics)
+	scrape := func(ctx context.Context) (notify.Summary, error) {
+		// A fresh handler each time, so nothing is left over from the last scrape
+		if config.BeforeScrape != nil {
+			config.BeforeScrape()
+		}
+		summary, err := NewCommandHandler().scrapeAndNotify(ctx, config.Scrape)
+		if config.AfterScrape != nil {
+			config.AfterScrape()
+		}
+		return summary, err
+	}
+
+	group, ctx := errgroup.WithContext(ctx)
+	group.Go(func() error {
+		return server.ListenAndServe(ctx, config.Addr, handler)
+	})
+	group.Go(func() error {
+		return daemon.Run(ctx, config.Schedule, scrape, metrics)
+	})
+	if err := group.Wait(); err != nil {
+		return err
 	}
-	mu.Unlock()
 
-	slog.Info("completed WowInterface scraping", "addons", len(addons))
-	return addons, nil
+	slog.Info("stopped daemon")
+	return nil
 }
 
-// scrapeGitHub handles GitHub-specific scraping logic
-func (h *CommandHandler) scrapeGitHub(ctx context.Context) ([]types.Addon, error) {
-	slog.Info("scraping GitHub catalogue")
+// Cache executes the cache command, printing a summary of the HTTP cache or the URLs in it
+func (h *CommandHandler) Cache(ctx context.Context, config CacheCommandConfig) error {
+	out := config.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	switch config.Action {
+	case CacheStatsAction:
+		summary, err := cache.Summarise(config.Dir, config.Store)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "entries\t%d\n", summary.Entries)
+		fmt.Fprintf(w, "size\t%s\n", report.FormatBytes(summary.Bytes))
+		if summary.LastRunAt != nil {
+			fmt.Fprintf(w, "last run\t%s\n", summary.LastRunAt.Format(time.RFC3339))
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "HOST\tENTRIES\tSIZE\tHITS\tMISSES\tHIT RATE")
+		for _, host := range summary.HostList {
+			hitRate := "-"
+			if requests := host.Hits + host.Misses; requests > 0 {
+				hitRate = fmt.Sprintf("%.1f%%", float64(host.Hits)/float64(requests)*100)
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%d\t%s\n", host.Host, host.Entries, report.FormatBytes(host.Bytes), host.Hits, host.Misses, hitRate)
+		}
+
+	case CacheListAction:
+		var hosts []string
+		for _, source := range config.Sources {
+			hosts = append(hosts, sourceHosts[source]...)
+		}
+
+		entries, err := cache.List(config.Dir, config.Store, hosts)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(w, "CACHED-AT\tCATEGORY\tSIZE\tURL")
+		for _, entry := range entries {
+			category := cmp.Or(string(entry.Category), "-")
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.CachedAt.Format(time.RFC3339), category, report.FormatBytes(entry.Size), entry.URL)
+		}
+
+	case CacheExportAction:
+		return exportCache(config)
+
+	case CacheImportAction:
+		file, err := os.Open(config.Archive)
+		if err != nil {
+			return fmt.Errorf("failed to open cache archive: %w", err)
+		}
+		defer file.Close()
+		entries, err := cache.Import(file, config.Dir, config.Store)
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", config.Archive, err)
+		}
+		slog.Info("imported cache", "archive", config.Archive, "entries", entries)
+
+	default:
+		return fmt.Errorf("unknown cache action: %s", config.Action)
+	}
+
+	return w.Flush()
+}
 
-	parser := github.NewParser()
-	addons, err := parser.BuildCatalogue()
+// exportCache writes the cache to config.Archive, removing the archive if it can't be written whole
+func exportCache(config CacheCommandConfig) error {
+	file, err := os.Create(config.Archive)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build GitHub catalogue: %w", err)
-	}
-
-	slog.Info("completed GitHub scraping", "addons", len(addons))
-	return addons, nil
-}
-
-// processURL processes a single URL and adds results to the data structures
-func (h *CommandHandler) processURL(
-	ctx context.Context,
-	client http.HTTPClient,
-	parser *wowi.Parser,
-	url string,
-	mu *sync.Mutex,
-	processedURLs map[string]bool,
-	addonDataMap map[string][]types.AddonData,
-	urlChan chan<- string,
-) error {
-	// Check if already processed
-	mu.Lock()
-	if processedURLs[url] {
-		mu.Unlock()
-		return nil
+		return fmt.Errorf("failed to create cache archive: %w", err)
+	}
+	entries, err := cache.Export(file, config.Dir, config.Store)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write cache archive: %w", closeErr)
 	}
-	processedURLs[url] = true
-	mu.Unlock()
+	if err != nil {
+		os.Remove(config.Archive)
+		return fmt.Errorf("failed to export to %s: %w", config.Archive, err)
+	}
+	slog.Info("exported cache", "archive", config.Archive, "entries", entries)
+	return nil
+}
 
-	slog.Debug("processing URL", "url", url)
+// Trend executes the trend command, reporting how download counts changed across scrapes
+func (h *CommandHandler) Trend(ctx context.Context, config TrendConfig) error {
+	out := config.Out
+	if out == nil {
+		out = os.Stdout
+	}
 
-	// Download content with retry logic
-	retryConfig := retry.DefaultConfig()
-	resp, err := retry.WithRetry(ctx, client, url, retryConfig)
+	snapshots, err := history.Read(filepath.Join(config.StateDir, history.Dir))
 	if err != nil {
-		return fmt.Errorf("failed to download %s: %w", url, err)
+		return err
+	}
+	baseline, err := history.Baseline(snapshots, config.Period)
+	if err != nil {
+		return err
+	}
+	trend := history.Compare(baseline, snapshots[len(snapshots)-1])
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "from\t%s\n", trend.From.Format(time.RFC3339))
+	fmt.Fprintf(w, "to\t%s\n", trend.To.Format(time.RFC3339))
+	fmt.Fprintf(w, "growing\t%d\n", len(trend.Growing))
+	fmt.Fprintf(w, "dropped\t%d\n", len(trend.Dropped))
+	fmt.Fprintf(w, "unchanged\t%d\n", trend.Unchanged)
+
+	growing := trend.Growing
+	if config.Limit > 0 && len(growing) > config.Limit {
+		growing = growing[:config.Limit]
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "FASTEST GROWING\tSOURCE\tDOWNLOADS\tGAIN\tPER DAY")
+	for _, change := range growing {
+		fmt.Fprintf(w, "%s\t%s\t%d\t+%d\t%.1f\n", change.Name, change.Key(), change.DownloadCount, change.Delta, change.PerDay)
+	}
+
+	// Always all of them, they're what needs looking into
+	if len(trend.Dropped) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "DROPPED\tSOURCE\tDOWNLOADS\tPREVIOUSLY\tDROP")
+		for _, change := range trend.Dropped {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\n", change.Name, change.Key(), change.DownloadCount, change.Previous, change.Delta)
+		}
+		slog.Warn("download counts dropped, check the source data", "addons", len(trend.Dropped))
 	}
 
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("non-200 status code %d for %s", resp.StatusCode, url)
+	return w.Flush()
+}
+
+// Search executes the search command, printing the addons in a catalogue matching a query, best matches first
+func (h *CommandHandler) Search(ctx context.Context, config SearchConfig) error {
+	out := config.Out
+	if out == nil {
+		out = os.Stdout
 	}
 
-	// Parse content
-	result, err := parser.Parse(url, resp.Body)
+	path := config.Catalogue
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, state.FullCatalogueFile)
+	}
+	cat, err := catalogue.ReadCatalogue(path)
 	if err != nil {
-		return fmt.Errorf("failed to parse %s: %w", url, err)
+		return err
 	}
 
-	mu.Lock()
-	defer mu.Unlock()
+	addons := cat.AddonSummaryList
+	if len(config.Sources) > 0 {
+		addons = slices.DeleteFunc(slices.Clone(addons), func(addon types.Addon) bool {
+			return !slices.Contains(config.Sources, addon.Source)
+		})
+	}
 
-	// Add new URLs to process (both API and HTML detail pages)
-	for _, newURL := range result.DownloadURLs {
-		if !processedURLs[newURL] {
-			// Block until we can send - we don't want to skip URLs
-			urlChan <- newURL
+	matches := search.Search(addons, config.Query)
+	if len(matches) == 0 {
+		return fmt.Errorf("no addons in %s match %q", path, config.Query)
+	}
+	slog.Debug("searched catalogue", "path", path, "addons", len(addons), "matches", len(matches))
+	if config.Limit > 0 && len(matches) > config.Limit {
+		matches = matches[:config.Limit]
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LABEL\tSOURCE\tSOURCE-ID\tUPDATED\tDOWNLOADS\tMATCHED\tURL")
+	for _, match := range matches {
+		addon := match.Addon
+		downloads := "-"
+		if addon.DownloadCount != nil {
+			downloads = fmt.Sprint(*addon.DownloadCount)
 		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", addon.Label, addon.Source, addon.SourceID,
+			addon.UpdatedDate.Format(catalogue.DatestampFormat), downloads, strings.Join(match.Fields, ","), addon.URL)
 	}
+	return w.Flush()
+}
 
-	// Store addon data
-	for _, addonData := range result.AddonData {
-		if addonData.SourceID != "" {
-			addonDataMap[addonData.SourceID] = append(addonDataMap[addonData.SourceID], addonData)
+// Lint executes the lint command, printing how many addons in a catalogue fail each check and which they are.
+// Returns an error naming the checks failed by more addons than their thresholds allow.
+func (h *CommandHandler) Lint(ctx context.Context, config LintConfig) error {
+	out := config.Out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	path := config.Path
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, state.FullCatalogueFile)
+	}
+	cat, err := catalogue.ReadCatalogue(path)
+	if err != nil {
+		return err
+	}
+	report := lint.Lint(cat.AddonSummaryList)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tADDONS\tPERCENT\tTHRESHOLD")
+	for _, check := range lint.KnownChecks {
+		threshold := "-"
+		if percent, ok := config.Thresholds[check]; ok {
+			threshold = fmt.Sprintf("%.1f%%", percent)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.1f%%\t%s\n", check, report.Counts[check], report.Percent(check), threshold)
+	}
+
+	if len(report.Findings) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "CHECK\tSOURCE\tSOURCE-ID\tLABEL\tDETAIL")
+		printed := make(map[lint.Check]int)
+		for _, finding := range report.Findings {
+			if config.Limit > 0 && printed[finding.Check] >= config.Limit {
+				continue
+			}
+			printed[finding.Check]++
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", finding.Check, finding.Source, finding.SourceID, finding.Label, truncate(finding.Detail, 60))
 		}
 	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
 
+	exceeded := report.Exceeded(config.Thresholds)
+	slog.Debug("linted catalogue", "path", path, "addons", report.Addons, "findings", len(report.Findings), "exceeded", len(exceeded))
+	if len(exceeded) > 0 {
+		names := make([]string, len(exceeded))
+		for i, check := range exceeded {
+			names[i] = fmt.Sprintf("%s (%.1f%%, threshold %.1f%%)", check, report.Percent(check), config.Thresholds[check])
+		}
+		return fmt.Errorf("%s has too many addons failing: %s", path, strings.Join(names, ", "))
+	}
 	return nil
 }
 
-// Validate executes the validate command
-func (h *CommandHandler) Validate(ctx context.Context, cataloguePath string) error {
-	slog.Info("validating catalogue", "file", cataloguePath)
+// truncate shortens s to a single line of at most n characters for printing in a table
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n-3]) + "..."
+	}
+	return s
+}
 
-	if err := validation.ValidateCatalogueFile(cataloguePath); err != nil {
-		slog.Error("validation failed", "file", cataloguePath, "error", err)
+// CheckLinks executes the check-links command, requesting a sample of the release download URLs in a releases
+// manifest and printing those that are dead, failing or redirected. Returns an error if any are dead.
+func (h *CommandHandler) CheckLinks(ctx context.Context, config CheckLinksConfig) error {
+	out := config.Out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	path := config.Releases
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, state.ReleasesFile)
+	}
+	releases, err := catalogue.ReadReleases(path)
+	if err != nil {
+		return err
+	}
+
+	links := linkcheck.Links(releases)
+	if len(config.Sources) > 0 {
+		links = slices.DeleteFunc(links, func(link linkcheck.Link) bool {
+			return !slices.Contains(config.Sources, link.Source)
+		})
+	}
+	seed := config.Seed
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+	sample := linkcheck.Sample(links, config.Sample, seed)
+	slog.Info("checking links", "path", path, "links", len(links), "sample", len(sample), "seed", seed)
+
+	client := http.Chain(config.HTTPClient, retry.Middleware(retry.DefaultConfig()), http.RateLimit(config.Interval))
+	results := linkcheck.Check(ctx, client, sample, config.MaxWorkers)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tCODE\tSOURCE\tSOURCE-ID\tGAME-TRACK\tURL\tTARGET")
+	for _, status := range linkcheck.KnownStatuses {
+		if status == linkcheck.Alive {
+			continue
+		}
+		for _, result := range results {
+			if result.Status != status {
+				continue
+			}
+			target := cmp.Or(result.Target, result.Error, "-")
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", result.Status, result.StatusCode, result.Source, result.SourceID, result.GameTrack, result.URL, target)
+		}
+	}
+	if err := w.Flush(); err != nil {
 		return err
 	}
 
-	slog.Info("validation successful", "file", cataloguePath)
+	counts := linkcheck.Counts(results)
+	slog.Info("checked links", "links", len(results), "alive", counts[linkcheck.Alive], "redirected", counts[linkcheck.Redirected],
+		"dead", counts[linkcheck.Dead], "failed", counts[linkcheck.Failed])
+	if counts[linkcheck.Failed] > 0 {
+		slog.Warn("links failed with server or network errors, they may be alive once their hosts recover", "links", counts[linkcheck.Failed])
+	}
+	if dead := counts[linkcheck.Dead]; dead > 0 {
+		return fmt.Errorf("%d of %d links checked are dead", dead, len(results))
+	}
 	return nil
 }
 
-// writeCatalogue writes a catalogue to a file or stdout
-func (h *CommandHandler) writeCatalogue(catalogue types.Catalogue, outputFile string) error {
-	jsonData, err := json.MarshalIndent(catalogue, "", "  ")
+// Show executes the show command, printing the data each file gave about an addon, the addon merged from them,
+// which file each field came from and the addon's entry in the last scrape's catalogue
+func (h *CommandHandler) Show(ctx context.Context, config ShowConfig) error {
+	out := config.Out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	dir := filepath.Join(config.StateDir, addondata.Dir)
+	dataList, err := addondata.Read(dir, config.Source, config.SourceID)
 	if err != nil {
-		return fmt.Errorf("failed to marshal catalogue: %w", err)
+		return err
 	}
+	dataList = h.builder.MergeOrder(dataList)
 
-	if outputFile == "" {
-		// Write to stdout
-		fmt.Println(string(jsonData))
+	catalogueFile := filepath.Join(config.StateDir, state.FullCatalogueFile)
+	var entry *types.Addon
+	if _, err := os.Stat(catalogueFile); err == nil {
+		cat, err := catalogue.ReadCatalogue(catalogueFile)
+		if err != nil {
+			return err
+		}
+		for _, addon := range cat.AddonSummaryList {
+			if addon.Source == config.Source && addon.SourceID == config.SourceID {
+				entry = &addon
+				break
+			}
+		}
+	}
+
+	if len(dataList) == 0 && entry == nil {
+		return fmt.Errorf("nothing is known about %s/%s in %s", config.Source, config.SourceID, config.StateDir)
+	}
+
+	fmt.Fprintf(out, "%s/%s\n", config.Source, config.SourceID)
+
+	for _, data := range dataList {
+		fmt.Fprintf(out, "\n== %s\n", addondata.Path(dir, config.Source, config.SourceID, data.Filename()))
+		if err := printJSON(out, data); err != nil {
+			return err
+		}
+	}
+
+	if len(dataList) > 0 {
+		fmt.Fprintln(out, "\n== merged")
+		merged, provenance, err := h.builder.MergeAddonDataProvenance(dataList)
+		if err != nil {
+			return err
+		}
+		if merged == nil {
+			fmt.Fprintf(out, "left out of the catalogue: %s\n", report.SkipReason(dataList))
+		} else {
+			// Where the fields came from when the addon was scraped, which may differ from merging now
+			if recorded, err := addondata.ReadProvenance(dir, config.Source, config.SourceID); err != nil {
+				return err
+			} else if recorded != nil {
+				provenance = recorded
+			}
+			if err := printProvenance(out, provenance); err != nil {
+				return err
+			}
+			fmt.Fprintln(out)
+			if err := printJSON(out, merged); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "\n== %s\n", catalogueFile)
+	if entry == nil {
+		fmt.Fprintln(out, "not in the catalogue")
 		return nil
 	}
+	return printJSON(out, entry)
+}
 
-	// Write to file
-	if err := os.WriteFile(outputFile, jsonData, 0644); err != nil {
-		return fmt.Errorf("failed to write catalogue to %s: %w", outputFile, err)
+// printProvenance prints the files each field of a merged addon came from, a field per line
+func printProvenance(out io.Writer, provenance catalogue.Provenance) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FIELD\tFROM")
+	for _, field := range slices.Sorted(maps.Keys(provenance)) {
+		fmt.Fprintf(w, "%s\t%s\n", field, strings.Join(provenance[field], ", "))
 	}
-	slog.Info("wrote catalogue", "file", outputFile, "addons", catalogue.Total)
+	return w.Flush()
+}
 
-	// Validate the catalogue after writing
-	if err := validation.ValidateCatalogueFile(outputFile); err != nil {
-		slog.Error("catalogue validation failed after write", "file", outputFile, "error", err)
-		return fmt.Errorf("catalogue validation failed: %w", err)
+// printJSON prints v as indented JSON
+func printJSON(out io.Writer, v any) error {
+	encoded, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %T: %w", v, err)
 	}
-	slog.Info("catalogue validated", "file", outputFile)
+	_, err = fmt.Fprintln(out, string(encoded))
+	return err
+}
 
-	return nil
+// Merge executes the merge command, combining catalogue files such as the legacy builder's into one
+func (h *CommandHandler) Merge(ctx context.Context, config MergeConfig) error {
+	if err := h.builder.SetSpecVersion(config.SpecVersion); err != nil {
+		return err
+	}
+	if err := h.builder.SetDatestamp(config.Datestamp); err != nil {
+		return err
+	}
+	h.noIndent = config.NoIndent
+
+	var catalogues []types.Catalogue
+	for _, path := range config.Paths {
+		cat, err := catalogue.ReadCatalogue(path)
+		if err != nil {
+			return err
+		}
+		slog.Info("read catalogue", "file", path, "addons", len(cat.AddonSummaryList))
+		catalogues = append(catalogues, cat)
+	}
+
+	merged, duplicates := h.builder.MergeCatalogues(catalogues)
+	slog.Info("merged catalogues", "files", len(config.Paths), "addons", merged.Total, "duplicates", duplicates)
+
+	// Catalogues written to files are validated once written, validate stdout's before it goes out
+	if config.OutputFile == "" {
+		data, err := json.Marshal(merged)
+		if err != nil {
+			return fmt.Errorf("failed to marshal merged catalogue: %w", err)
+		}
+		if err := validation.ValidateCatalogueJSON(data); err != nil {
+			return fmt.Errorf("merged catalogue validation failed: %w", err)
+		}
+	}
+	return h.writeCatalogue(merged, config.OutputFile)
+}
+
+// writeCatalogue writes a catalogue to a file or stdout
+func (h *CommandHandler) writeCatalogue(cat types.Catalogue, outputFile string) error {
+	writeJSON := catalogue.WriteJSON
+	if h.noIndent {
+		writeJSON = catalogue.WriteCompactJSON
+	}
+
+	if outputFile == "" {
+		// Write to stdout
+		if err := writeJSON(os.Stdout, cat); err != nil {
+			return err
+		}
+		fmt.Println()
+		return nil
+	}
+
+	return state.WriteCatalogue(cat, outputFile, h.noIndent)
 }
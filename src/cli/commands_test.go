@@ -0,0 +1,544 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/pkg/builder"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/addondata"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/cache"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/catalogue"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/gate"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/history"
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/lint"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/notify"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/signing"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/state"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/wowi"
+)
+
+func TestExpandPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"full-catalogue.json", "short-catalogue.json", state.RunMetadataFile, state.ScrapeReportFile, "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	full := filepath.Join(dir, "full-catalogue.json")
+	short := filepath.Join(dir, "short-catalogue.json")
+
+	tests := []struct {
+		name    string
+		paths   []string
+		want    []string
+		wantErr bool
+	}{
+		{"glob skips state files", []string{filepath.Join(dir, "*.json")}, []string{full, short}, false},
+		{"duplicates removed", []string{short, filepath.Join(dir, "*.json")}, []string{short, full}, false},
+		{"missing file kept", []string{filepath.Join(dir, "missing.json")}, []string{filepath.Join(dir, "missing.json")}, false},
+		{"expanded state file skipped", []string{full, filepath.Join(dir, state.RunMetadataFile)}, []string{full}, false},
+		{"no glob matches", []string{filepath.Join(dir, "*.csv")}, nil, true},
+		{"nothing left", []string{filepath.Join(dir, state.ScrapeReportFile)}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandPaths(tt.paths)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandPaths() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrend(t *testing.T) {
+	stateDir := t.TempDir()
+	handler := NewCommandHandler()
+	config := TrendConfig{StateDir: stateDir, Period: 7 * 24 * time.Hour, Limit: 1}
+
+	if err := handler.Trend(context.Background(), config); err == nil {
+		t.Error("Trend() without history, expected an error")
+	}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for day, counts := range [][]int{{100, 100, 100}, {150, 500, 90}} {
+		addons := []types.Addon{
+			{Source: types.WowInterfaceSource, SourceID: "1", Name: "steady", DownloadCount: &counts[0]},
+			{Source: types.WowInterfaceSource, SourceID: "2", Name: "rocket", DownloadCount: &counts[1]},
+			{Source: types.WowInterfaceSource, SourceID: "3", Name: "broken", DownloadCount: &counts[2]},
+		}
+		if _, err := history.Write(history.NewSnapshot(addons, start.AddDate(0, 0, day)), filepath.Join(stateDir, history.Dir)); err != nil {
+			t.Fatalf("history.Write() unexpected error: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	config.Out = &out
+	if err := handler.Trend(context.Background(), config); err != nil {
+		t.Fatalf("Trend() unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"rocket", "+400", "broken", "-10"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Trend() output missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "steady") {
+		t.Errorf("Trend() output includes growing addons beyond the limit:\n%s", out.String())
+	}
+}
+
+func TestSearch(t *testing.T) {
+	stateDir := t.TempDir()
+	handler := NewCommandHandler()
+
+	downloads := 1000
+	addons := []types.Addon{
+		{Source: types.WowInterfaceSource, SourceID: "4815", Name: "bagnon", Label: "Bagnon", URL: "https://www.wowinterface.com/downloads/info4815", DownloadCount: &downloads},
+		{Source: types.GitHubSource, SourceID: "owner/bagnon-facade", Name: "bagnon-facade", Label: "Bagnon Facade", URL: "https://github.com/owner/bagnon-facade"},
+		{Source: types.WowInterfaceSource, SourceID: "999", Name: "details", Label: "Details! Damage Meter", URL: "https://www.wowinterface.com/downloads/info999"},
+	}
+	for i := range addons {
+		addons[i].UpdatedDate = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		addons[i].GameTrackList = []types.GameTrack{types.RetailTrack}
+		addons[i].TagList = []string{}
+	}
+	cat := types.Catalogue{Datestamp: "2024-01-02", Total: len(addons), AddonSummaryList: addons}
+	cat.Spec.Version = 2
+	if err := handler.writeCatalogue(cat, filepath.Join(stateDir, "full-catalogue.json")); err != nil {
+		t.Fatalf("writeCatalogue() unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := handler.Search(context.Background(), SearchConfig{Query: "bagnno", Catalogue: stateDir, Out: &out}); err != nil {
+		t.Fatalf("Search() unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "Bagnon ") || !strings.Contains(lines[1], "https://www.wowinterface.com/downloads/info4815") || !strings.Contains(lines[2], "github") {
+		t.Errorf("Search() output = \n%s\nwant Bagnon then Bagnon Facade", out.String())
+	}
+
+	out.Reset()
+	config := SearchConfig{Query: "bagnon", Catalogue: filepath.Join(stateDir, "full-catalogue.json"), Sources: []types.Source{types.GitHubSource}, Out: &out}
+	if err := handler.Search(context.Background(), config); err != nil {
+		t.Fatalf("Search() unexpected error: %v", err)
+	}
+	if strings.Contains(out.String(), "info4815") || !strings.Contains(out.String(), "Bagnon Facade") {
+		t.Errorf("Search() of the github source output = \n%s\nwant Bagnon Facade only", out.String())
+	}
+
+	if err := handler.Search(context.Background(), SearchConfig{Query: "weakauras", Catalogue: stateDir, Out: &out}); err == nil {
+		t.Error("Search() without matches, expected an error")
+	}
+}
+
+func TestLint(t *testing.T) {
+	stateDir := t.TempDir()
+	handler := NewCommandHandler()
+
+	addons := []types.Addon{
+		{Source: types.WowInterfaceSource, SourceID: "4815", Name: "bagnon", Label: "Bagnon", Description: "Puts all of your bags into a single frame", TagList: []string{"bags"}},
+		{Source: types.WowInterfaceSource, SourceID: "999", Name: "details", Label: "Details! Damage Meter", TagList: []string{}},
+	}
+	for i := range addons {
+		addons[i].URL = "https://www.wowinterface.com/downloads/info" + addons[i].SourceID
+		addons[i].UpdatedDate = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		addons[i].GameTrackList = []types.GameTrack{types.RetailTrack}
+	}
+	cat := types.Catalogue{Datestamp: "2024-01-02", Total: len(addons), AddonSummaryList: addons}
+	cat.Spec.Version = 2
+	if err := handler.writeCatalogue(cat, filepath.Join(stateDir, "full-catalogue.json")); err != nil {
+		t.Fatalf("writeCatalogue() unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	err := handler.Lint(context.Background(), LintConfig{Path: stateDir, Thresholds: lint.DefaultThresholds, Out: &out})
+	// half the addons fail both, only empty-description is above its default threshold
+	if err == nil || !strings.Contains(err.Error(), string(lint.EmptyDescription)) || strings.Contains(err.Error(), string(lint.MissingTags)) {
+		t.Errorf("Lint() error = %v, want empty-description over its threshold", err)
+	}
+	if !strings.Contains(out.String(), "50.0%") || !strings.Contains(out.String(), "Details! Damage Meter") {
+		t.Errorf("Lint() output = \n%s\nwant the counts and Details! Damage Meter", out.String())
+	}
+
+	out.Reset()
+	thresholds := map[lint.Check]float64{lint.EmptyDescription: 50, lint.MissingTags: 50}
+	if err := handler.Lint(context.Background(), LintConfig{Path: stateDir, Thresholds: thresholds, Out: &out}); err != nil {
+		t.Errorf("Lint() unexpected error at the thresholds: %v", err)
+	}
+}
+
+func TestCheckLinks(t *testing.T) {
+	stateDir := t.TempDir()
+	releases := catalogue.Releases{Datestamp: "2024-01-02", Total: 3, ReleaseMap: map[types.Source]map[string]map[types.GameTrack]types.Release{
+		types.WowInterfaceSource: {
+			"1": {types.RetailTrack: {DownloadURL: "https://cdn.wowinterface.com/downloads/getfile.php?id=1"}},
+			"2": {types.RetailTrack: {DownloadURL: "https://cdn.wowinterface.com/downloads/getfile.php?id=2"}},
+		},
+		types.GitHubSource: {"owner/repo": {types.RetailTrack: {DownloadURL: "https://github.com/owner/repo/releases/download/1.0/repo.zip"}}},
+	}}
+	if err := catalogue.WriteReleases(releases, filepath.Join(stateDir, state.ReleasesFile)); err != nil {
+		t.Fatalf("WriteReleases() unexpected error: %v", err)
+	}
+
+	client := httpclient.NewMockHTTPClient()
+	client.SetResponse("https://cdn.wowinterface.com/downloads/getfile.php?id=1", &httpclient.Response{StatusCode: 302, Headers: map[string]string{"Location": "/files/1.zip"}})
+	client.SetResponse("https://cdn.wowinterface.com/files/1.zip", &httpclient.Response{StatusCode: 200})
+	client.SetResponse("https://cdn.wowinterface.com/downloads/getfile.php?id=2", &httpclient.Response{StatusCode: 404})
+
+	var out bytes.Buffer
+	config := CheckLinksConfig{Releases: stateDir, Sources: []types.Source{types.WowInterfaceSource}, MaxWorkers: 1, HTTPClient: client, Out: &out}
+	err := NewCommandHandler().CheckLinks(context.Background(), config)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 links") {
+		t.Errorf("CheckLinks() error = %v, want 1 of the 2 wowinterface links dead", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "dead") || !strings.Contains(lines[2], "https://cdn.wowinterface.com/files/1.zip") {
+		t.Errorf("CheckLinks() output = \n%s\nwant the dead link then the redirected one", out.String())
+	}
+	if slices.Contains(client.GetCalls(), "https://github.com/owner/repo/releases/download/1.0/repo.zip") {
+		t.Error("CheckLinks() checked a github link, want wowinterface links only")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	handler := NewCommandHandler()
+
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	addon := types.Addon{
+		Source:        types.WowInterfaceSource,
+		SourceID:      "1",
+		Name:          "one",
+		Label:         "One",
+		URL:           "https://www.wowinterface.com/downloads/info1",
+		UpdatedDate:   updated,
+		GameTrackList: []types.GameTrack{types.RetailTrack},
+		TagList:       []string{},
+	}
+	other := addon
+	other.SourceID, other.Name, other.URL = "2", "two", "https://www.wowinterface.com/downloads/info2"
+
+	var paths []string
+	for i, addons := range [][]types.Addon{{addon}, {addon, other}} {
+		path := filepath.Join(dir, fmt.Sprintf("catalogue-%d.json", i))
+		cat := types.Catalogue{Datestamp: "2024-01-02", Total: len(addons), AddonSummaryList: addons}
+		cat.Spec.Version = 2
+		if err := handler.writeCatalogue(cat, path); err != nil {
+			t.Fatalf("writeCatalogue() unexpected error: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	out := filepath.Join(dir, "full.json")
+	if err := handler.Merge(context.Background(), MergeConfig{Paths: paths, OutputFile: out}); err != nil {
+		t.Fatalf("Merge() unexpected error: %v", err)
+	}
+	merged, err := catalogue.ReadCatalogue(out)
+	if err != nil {
+		t.Fatalf("ReadCatalogue() unexpected error: %v", err)
+	}
+	if merged.Total != 2 || len(merged.AddonSummaryList) != 2 {
+		t.Errorf("Merge() total = %d (%d addons), want 2", merged.Total, len(merged.AddonSummaryList))
+	}
+
+	if err := handler.Merge(context.Background(), MergeConfig{Paths: []string{paths[0], filepath.Join(dir, "missing.json")}, OutputFile: out}); err == nil {
+		t.Error("Merge() with a missing file, expected an error")
+	}
+
+	// The same inputs with a fixed datestamp give byte-identical output
+	var outputs []string
+	for i := 0; i < 2; i++ {
+		out := filepath.Join(dir, fmt.Sprintf("reproducible-%d.json", i))
+		config := MergeConfig{Paths: paths, OutputFile: out, Datestamp: "2024-03-01", NoIndent: true}
+		if err := NewCommandHandler().Merge(context.Background(), config); err != nil {
+			t.Fatalf("Merge() unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatalf("failed to read merged catalogue: %v", err)
+		}
+		outputs = append(outputs, string(data))
+	}
+	if outputs[0] != outputs[1] {
+		t.Errorf("Merge() output differs between runs:\n%s\n%s", outputs[0], outputs[1])
+	}
+	if strings.Contains(outputs[0], "\n") || !strings.Contains(outputs[0], `"datestamp":"2024-03-01"`) {
+		t.Errorf("Merge() output = %s, want compact JSON datestamped 2024-03-01", outputs[0])
+	}
+}
+
+// wowiAddon returns a WowInterface addon of the full catalogue, updated 2024-01-01
+func wowiAddon(sourceID, label string) types.Addon {
+	return types.Addon{
+		Source:        types.WowInterfaceSource,
+		SourceID:      sourceID,
+		Name:          strings.ToLower(label),
+		Label:         label,
+		URL:           "https://www.wowinterface.com/downloads/info" + sourceID,
+		UpdatedDate:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		GameTrackList: []types.GameTrack{types.RetailTrack},
+		TagList:       []string{},
+	}
+}
+
+// writeFullCatalogue writes the full catalogue of a scrape of addons to the state directory
+func writeFullCatalogue(t *testing.T, stateDir string, addons ...types.Addon) {
+	t.Helper()
+	cat := types.Catalogue{Datestamp: "2024-01-02", Total: len(addons), AddonSummaryList: addons}
+	cat.Spec.Version = 2
+	if err := state.WriteCatalogue(cat, filepath.Join(stateDir, state.FullCatalogueFile), false); err != nil {
+		t.Fatalf("WriteCatalogue() unexpected error: %v", err)
+	}
+}
+
+func TestShow(t *testing.T) {
+	stateDir := t.TempDir()
+	handler := NewCommandHandler()
+	writeFullCatalogue(t, stateDir, wowiAddon("1", "One"), wowiAddon("25078", "Better Vendor Price"))
+
+	updated := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	page := types.AddonData{Source: types.WowInterfaceSource, SourceID: "25078", Kind: types.WebDetailData, Name: "better-vendor-price", Label: "Better Vendor Price (page)", URL: "https://www.wowinterface.com/downloads/info25078", UpdatedDate: &updated, GameTrackSet: map[types.GameTrack]bool{types.RetailTrack: true}}
+	api := types.AddonData{Source: types.WowInterfaceSource, SourceID: "25078", Kind: types.APIDetailData, APIVersion: "v4", Label: "Better Vendor Price", UpdatedDate: &updated}
+	provenance := map[string][]string{"label": {"api-detail-v4.json"}}
+	if err := addondata.Write(filepath.Join(stateDir, addondata.Dir), types.WowInterfaceSource, "25078", []types.AddonData{page, api}, provenance); err != nil {
+		t.Fatalf("addondata.Write() unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := handler.Show(context.Background(), ShowConfig{Source: types.WowInterfaceSource, SourceID: "25078", StateDir: stateDir, Out: &out}); err != nil {
+		t.Fatalf("Show() unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		filepath.Join(stateDir, addondata.Dir, "wowinterface", "25078", "web-detail.json"),
+		filepath.Join(stateDir, addondata.Dir, "wowinterface", "25078", "api-detail-v4.json"),
+		"== merged",
+		"== " + filepath.Join(stateDir, "full-catalogue.json"),
+		`"label": "Better Vendor Price"`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Show() output missing %q:\n%s", want, out.String())
+		}
+	}
+	if !regexp.MustCompile(`(?m)^label +api-detail-v4\.json$`).MatchString(out.String()) {
+		t.Errorf("Show() output doesn't say the label came from api-detail-v4.json:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := handler.Show(context.Background(), ShowConfig{Source: types.WowInterfaceSource, SourceID: "1", StateDir: stateDir, Out: &out}); err != nil {
+		t.Fatalf("Show() unexpected error: %v", err)
+	}
+	if strings.Contains(out.String(), "== merged") || !strings.Contains(out.String(), `"label": "One"`) {
+		t.Errorf("Show() of an addon without addon data output = \n%s\nwant its catalogue entry only", out.String())
+	}
+
+	if err := handler.Show(context.Background(), ShowConfig{Source: types.WowInterfaceSource, SourceID: "999", StateDir: stateDir, Out: &out}); err == nil {
+		t.Error("Show() of an unknown addon, expected an error")
+	}
+}
+
+// recordingTarget records the files it's asked to publish
+type recordingTarget struct {
+	files []string
+}
+
+func (r *recordingTarget) Publish(ctx context.Context, files []string) error {
+	r.files = files
+	return nil
+}
+
+func TestPublish(t *testing.T) {
+	stateDir := t.TempDir()
+	handler := NewCommandHandler()
+	writeFullCatalogue(t, stateDir, wowiAddon("1", "One"), wowiAddon("25078", "Stale"))
+	full := filepath.Join(stateDir, "full-catalogue.json")
+	short := filepath.Join(stateDir, "short-catalogue.json")
+	wowiPath := filepath.Join(stateDir, "wowinterface-catalogue.json")
+	data, err := os.ReadFile(full)
+	if err != nil {
+		t.Fatalf("failed to read full catalogue: %v", err)
+	}
+	for _, path := range []string{short, wowiPath} {
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	target := &recordingTarget{}
+	config := PublishConfig{StateDir: stateDir, Target: target}
+	if err := handler.Publish(context.Background(), config); !errors.Is(err, gate.ErrNotPassed) {
+		t.Fatalf("Publish() before the gate ran error = %v, want %v", err, gate.ErrNotPassed)
+	}
+
+	verdict, err := gate.Run(full, nil, gate.DefaultThresholds())
+	if err != nil || !verdict.Passed {
+		t.Fatalf("gate.Run() = %+v, %v, want a pass", verdict, err)
+	}
+	metadata := gate.RunMetadata{Sources: []types.Source{types.WowInterfaceSource}, Gate: &verdict}
+	if err := gate.WriteRunMetadata(metadata, filepath.Join(stateDir, state.RunMetadataFile)); err != nil {
+		t.Fatalf("WriteRunMetadata() unexpected error: %v", err)
+	}
+
+	if err := handler.Publish(context.Background(), config); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+	if want := []string{full, short, wowiPath}; !reflect.DeepEqual(target.files, want) {
+		t.Errorf("Publish() files = %v, want %v", target.files, want)
+	}
+
+	// The manifest and signatures are published along with the catalogues, unless they're left over from an earlier scrape
+	manifest := filepath.Join(stateDir, signing.ManifestFile)
+	for _, path := range []string{manifest, manifest + signing.SignatureExt, full + signing.SignatureExt, short + signing.SignatureExt} {
+		if err := os.WriteFile(path, []byte("-"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+	earlier := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(short+signing.SignatureExt, earlier, earlier); err != nil {
+		t.Fatalf("failed to age signature: %v", err)
+	}
+	if err := handler.Publish(context.Background(), config); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+	if want := []string{full, short, wowiPath, manifest, full + signing.SignatureExt, manifest + signing.SignatureExt}; !reflect.DeepEqual(target.files, want) {
+		t.Errorf("Publish() files = %v, want %v", target.files, want)
+	}
+}
+
+func TestScrape_Notify(t *testing.T) {
+	summaries := make(chan notify.Summary, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var summary notify.Summary
+		if err := json.NewDecoder(r.Body).Decode(&summary); err != nil {
+			t.Errorf("failed to decode notification: %v", err)
+		}
+		summaries <- summary
+	}))
+	defer server.Close()
+
+	// A finished scrape reports its counts
+	client := httpclient.NewMockHTTPClient()
+	filelist := `[{"id": 25078, "title": "Addon", "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]}]`
+	client.SetResponse(wowi.GetAPIFileList(wowi.APIVersionV4), &httpclient.Response{StatusCode: 200, Body: []byte(filelist)})
+	detailPage, err := os.ReadFile("../wowi/test/fixtures/addon-25078.html")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	client.SetResponse(wowi.Host+"/downloads/info25078", &httpclient.Response{StatusCode: 200, Body: detailPage})
+	client.SetResponse(wowi.GetAPIHost(wowi.APIVersionV4)+"/filedetails/25078.json", &httpclient.Response{StatusCode: 404})
+
+	config := ScrapeConfig{
+		Options: builder.Options{
+			HTTPClient:  client,
+			Sources:     []builder.Source{builder.WowInterface},
+			MaxWorkers:  1,
+			StateDir:    t.TempDir(),
+			MaxFailures: -1,
+		},
+		NotifyURL:    server.URL,
+		NotifyFormat: notify.JSONFormat,
+	}
+	if err := NewCommandHandler().Scrape(context.Background(), config); err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+	summary := <-summaries
+	if summary.Status != notify.Finished || summary.Total != 1 || summary.SourceTotals[types.WowInterfaceSource] != 1 || summary.Failures != 1 || summary.GatePassed == nil {
+		t.Errorf("notification = %+v, want a finished scrape of 1 addon with 1 failure", summary)
+	}
+
+	// A failed scrape reports why
+	config.HTTPClient = httpclient.NewMockHTTPClient()
+	err = NewCommandHandler().Scrape(context.Background(), config)
+	if err == nil {
+		t.Fatal("Scrape() without a file list expected an error")
+	}
+	summary = <-summaries
+	if summary.Status != notify.Failed || summary.Error != err.Error() {
+		t.Errorf("notification = %+v, want a scrape failed with %q", summary, err)
+	}
+}
+
+// every fires a fixed time after any time
+type every time.Duration
+
+func (e every) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
+
+// localClient makes the requests of client to the same path on a test server instead of the host asked for
+type localClient struct {
+	client httpclient.HTTPClient
+	base   string
+}
+
+func (c localClient) Get(ctx context.Context, rawURL string) (*httpclient.Response, error) {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.Get(ctx, c.base+u.Path)
+}
+
+func TestDaemon_StartsEachScrapeAfresh(t *testing.T) {
+	var filelists atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/filelist.json") {
+			filelists.Add(1)
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	// Scrapes further apart than the file list's TTL
+	clientConfig := builder.DefaultClientConfig(t.TempDir())
+	clientConfig.Cache.TTLRules = []cache.TTLRule{{Pattern: "filelist.json", TTL: 50 * time.Millisecond}}
+	client, err := builder.NewClient(clientConfig)
+	if err != nil {
+		t.Fatalf("NewClient() unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	scrapes := 0
+	config := DaemonConfig{
+		Scrape:       ScrapeConfig{Options: builder.Options{Sources: []builder.Source{builder.WowInterface}, MaxWorkers: 1, StateDir: t.TempDir(), MaxFailures: -1}},
+		Schedule:     every(100 * time.Millisecond),
+		Addr:         "127.0.0.1:0",
+		BeforeScrape: client.StartRun,
+		AfterScrape: func() {
+			if scrapes++; scrapes == 2 {
+				cancel()
+			}
+		},
+	}
+	client.Configure(&config.Scrape.Options)
+	config.Scrape.HTTPClient = localClient{client: client.HTTP, base: server.URL}
+
+	if err := NewCommandHandler().Daemon(ctx, config); err != nil {
+		t.Fatalf("Daemon() unexpected error: %v", err)
+	}
+	if got := filelists.Load(); scrapes != 2 || got != 2 {
+		t.Errorf("Daemon() fetched the file list %d times in %d scrapes, want once per scrape once its TTL is over", got, scrapes)
+	}
+}
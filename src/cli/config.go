@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+	flag "github.com/spf13/pflag"
+)
+
+// configFlagName is the flag naming the config file, it can't itself be set from the file
+const configFlagName = "config"
+
+// unconfigurableFlags only make sense on the command line
+var unconfigurableFlags = []string{configFlagName, "help", "version"}
+
+// applyConfigFile sets flags from a TOML config file. Keys are flag names without the leading dashes.
+// Top-level keys set global options and a table named after a subcommand sets that subcommand's options:
+//
+//	log-level = "debug"
+//	workers = 10
+//
+//	[scrape]
+//	source = ["wowinterface", "github"]
+//	state-dir = "/var/lib/strongbox/state"
+//
+// Flags given on the command line are left alone so they override the file.
+// Tables for other subcommands are ignored, unknown keys are an error.
+func applyConfigFile(path string, subcommand string, flagset *flag.FlagSet, global *flag.FlagSet) error {
+	var config map[string]any
+	if _, err := toml.DecodeFile(path, &config); err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	for _, key := range sortedKeys(config) {
+		value := config[key]
+
+		table, isTable := value.(map[string]any)
+		if !isTable {
+			if global.Lookup(key) == nil {
+				return fmt.Errorf("unknown option in config file %s: %s", path, key)
+			}
+			if err := setConfigFlag(flagset, key, value); err != nil {
+				return fmt.Errorf("failed to apply config file %s: %w", path, err)
+			}
+			continue
+		}
+
+		if !slices.Contains(KnownSubCommands, SubCommand(key)) {
+			return fmt.Errorf("unknown section in config file %s: [%s]", path, key)
+		}
+		if key != subcommand {
+			continue
+		}
+		for _, name := range sortedKeys(table) {
+			if flagset.Lookup(name) == nil || global.Lookup(name) != nil {
+				return fmt.Errorf("unknown option in config file %s: [%s] %s", path, key, name)
+			}
+			if err := setConfigFlag(flagset, name, table[name]); err != nil {
+				return fmt.Errorf("failed to apply config file %s: %w", path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// setConfigFlag sets a flag from a config file value unless it was given on the command line.
+// Lists set the flag once per element.
+func setConfigFlag(flagset *flag.FlagSet, name string, value any) error {
+	if slices.Contains(unconfigurableFlags, name) {
+		return fmt.Errorf("option can't be set from a config file: %s", name)
+	}
+
+	f := flagset.Lookup(name)
+	if f.Changed {
+		return nil
+	}
+
+	values, isList := value.([]any)
+	if !isList {
+		values = []any{value}
+	}
+	for _, v := range values {
+		if _, isTable := v.(map[string]any); isTable {
+			return fmt.Errorf("invalid value for %s: expected a value or list of values", name)
+		}
+		if err := flagset.Set(name, fmt.Sprint(v)); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/pkg/builder"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/cache"
+)
+
+func writeTestConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "strongbox-cb.toml")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestParseFlags_ConfigFile(t *testing.T) {
+	path := writeTestConfig(t, `
+log-level = "debug"
+workers = 10
+
+[scrape]
+source = ["wowinterface", "github"]
+state-dir = "/tmp/state"
+github-readme-interval = "1s"
+cache-ttl-hours = 24
+cache-ttl = ["filelist.json=30m", "downloads/info*=14d"]
+
+[write]
+out = ["ignored.json"]
+`)
+
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--config", path, "--workers", "3"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+
+	if flags.MaxWorkers != 3 {
+		t.Errorf("MaxWorkers = %d, want 3 (command line overrides config file)", flags.MaxWorkers)
+	}
+	if flags.LogLevel.String() != "DEBUG" {
+		t.Errorf("LogLevel = %v, want DEBUG", flags.LogLevel)
+	}
+	if flags.CacheTTLHours != 24 {
+		t.Errorf("CacheTTLHours = %d, want 24", flags.CacheTTLHours)
+	}
+	if flags.SearchCacheTTLHours != 2 {
+		t.Errorf("SearchCacheTTLHours = %d, want 2", flags.SearchCacheTTLHours)
+	}
+	wantRules := []cache.TTLRule{{Pattern: "filelist.json", TTL: 30 * time.Minute}, {Pattern: "downloads/info*", TTL: 14 * 24 * time.Hour}}
+	if !reflect.DeepEqual(flags.CacheTTLRules, wantRules) {
+		t.Errorf("CacheTTLRules = %v, want %v (config file replaces the defaults)", flags.CacheTTLRules, wantRules)
+	}
+
+	config := flags.ScrapeConfig
+	wantSources := []builder.Source{builder.WowInterface, builder.GitHub}
+	if !reflect.DeepEqual(config.Sources, wantSources) {
+		t.Errorf("Sources = %v, want %v", config.Sources, wantSources)
+	}
+	if config.StateDir != "/tmp/state" {
+		t.Errorf("StateDir = %q, want /tmp/state", config.StateDir)
+	}
+	if config.GitHubReadmeInterval != time.Second {
+		t.Errorf("GitHubReadmeInterval = %v, want 1s", config.GitHubReadmeInterval)
+	}
+	if config.MaxWorkers != 3 {
+		t.Errorf("ScrapeConfig.MaxWorkers = %d, want 3", config.MaxWorkers)
+	}
+}
+
+func TestParseFlags_ConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		wantErr  string
+	}{
+		{"unknown option", `colour = "red"`, "unknown option"},
+		{"unknown section", "[deploy]\nout = \"x\"", "unknown section"},
+		{"unknown subcommand option", "[scrape]\nout = \"x\"", "unknown option"},
+		{"global option in section", "[scrape]\nworkers = 2", "unknown option"},
+		{"invalid value", `workers = "lots"`, "invalid value for workers"},
+		{"config flag", `config = "other.toml"`, "can't be set"},
+		{"malformed", `workers = `, "failed to read config file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestConfig(t, tt.contents)
+			_, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--config", path}, "test")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFlags() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/pkg/builder"
+)
+
+// dryRun discovers the WowInterface addons a scrape would fetch and prints how many of their URLs are cached and how
+// long fetching the rest should take, without fetching them or writing anything to the state directory
+func (h *CommandHandler) dryRun(ctx context.Context, config ScrapeConfig) error {
+	plan, err := builder.PlanScrape(ctx, config.Options)
+	if err != nil {
+		return fmt.Errorf("--dry-run: %w", err)
+	}
+
+	out := config.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	return printScrapePlan(out, plan)
+}
+
+// printScrapePlan prints what a scrape would fetch and how long it should take
+func printScrapePlan(out io.Writer, plan builder.Plan) error {
+	basis := fmt.Sprintf("%s per URL fetched, assumed", plan.PerFetch.Round(time.Millisecond))
+	if plan.FromLastRun {
+		basis = fmt.Sprintf("%s per URL fetched by the last scrape", plan.PerFetch.Round(time.Millisecond))
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "discovered URLs\t%d\n", plan.Discovered)
+	fmt.Fprintf(w, "dead-lettered\t%d\n", plan.DeadLettered)
+	fmt.Fprintf(w, "cached and fresh\t%d\n", plan.Cached)
+	fmt.Fprintf(w, "to fetch\t%d\n", plan.ToFetch)
+	fmt.Fprintf(w, "estimated time\t%s (%s)\n", plan.Estimate().Round(time.Second), basis)
+	return w.Flush()
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/pkg/builder"
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/report"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/state"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/wowi"
+)
+
+// freshURLs is a CacheChecker of the URLs it holds
+type freshURLs map[string]bool
+
+func (f freshURLs) Fresh(url string) bool {
+	return f[url]
+}
+
+func TestScrape_DryRun(t *testing.T) {
+	client := httpclient.NewMockHTTPClient()
+	filelistURL := wowi.GetAPIFileList(wowi.APIVersionV4)
+	filelist := `[
+		{"id": 1, "title": "One", "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]},
+		{"id": 2, "title": "Two", "lastUpdate": 1704067200000, "gameVersions": ["11.0.2"]}
+	]`
+	client.SetResponse(filelistURL, &httpclient.Response{StatusCode: 200, Body: []byte(filelist)})
+
+	var out bytes.Buffer
+	config := ScrapeConfig{
+		Options: builder.Options{
+			HTTPClient:   client,
+			CacheChecker: freshURLs{wowi.APIDetailURL(wowi.APIVersionV4, "1"): true},
+			Sources:      []builder.Source{builder.WowInterface},
+			MaxWorkers:   4,
+			StateDir:     t.TempDir(),
+		},
+		DryRun: true,
+		Out:    &out,
+	}
+	if err := NewCommandHandler().Scrape(context.Background(), config); err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+	if calls := client.GetCalls(); len(calls) != 1 || calls[0] != filelistURL {
+		t.Errorf("Scrape() fetched %v, want only the filelist", calls)
+	}
+	if files, _ := os.ReadDir(config.StateDir); len(files) != 0 {
+		t.Errorf("Scrape() wrote %d files to the state directory, want none", len(files))
+	}
+	// A page and API detail per addon, one cached, each fetch assumed to take a second of one of four workers
+	for _, want := range []string{"discovered URLs   4", "cached and fresh  1", "to fetch          3", "estimated time    1s (250ms per URL fetched, assumed)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Scrape() printed %q, want it to contain %q", out.String(), want)
+		}
+	}
+
+	// Estimated from how long the last scrape took for each URL it fetched
+	lastRun := report.ScrapeReport{DurationSeconds: 20, Cache: report.NewCacheSummary(0, 10)}
+	if err := report.Write(lastRun, filepath.Join(config.StateDir, state.ScrapeReportFile)); err != nil {
+		t.Fatalf("failed to write the last scrape report: %v", err)
+	}
+	out.Reset()
+	if err := NewCommandHandler().Scrape(context.Background(), config); err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+	if want := "estimated time    6s (2s per URL fetched by the last scrape)"; !strings.Contains(out.String(), want) {
+		t.Errorf("Scrape() printed %q, want it to contain %q", out.String(), want)
+	}
+
+	config.Sources = []builder.Source{builder.GitHub}
+	if err := NewCommandHandler().Scrape(context.Background(), config); err == nil {
+		t.Error("Scrape() dry run without WowInterface expected an error")
+	}
+}
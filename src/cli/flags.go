@@ -1,12 +1,32 @@
 package cli
 
 import (
+	"cmp"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
+	"runtime"
 	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ogri-la/strongbox-catalogue-builder-go/pkg/builder"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/cache"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/catalogue"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/deadletter"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/github"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/lint"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/notify"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/publish"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/schedule"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/server"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/signing"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/state"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/wago"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/wowi"
 	flag "github.com/spf13/pflag"
 )
@@ -18,26 +38,115 @@ const (
 	ScrapeSubCommand   SubCommand = "scrape"
 	WriteSubCommand    SubCommand = "write"
 	ValidateSubCommand SubCommand = "validate"
+	ServeSubCommand    SubCommand = "serve"
+	SchemaSubCommand   SubCommand = "schema"
+	CacheSubCommand    SubCommand = "cache"
+	TrendSubCommand    SubCommand = "trend"
+	MergeSubCommand    SubCommand = "merge"
+	PublishSubCommand  SubCommand = "publish"
+	DaemonSubCommand   SubCommand = "daemon"
+	SearchSubCommand   SubCommand = "search"
+	ShowSubCommand     SubCommand = "show"
+	LintSubCommand     SubCommand = "lint"
+
+	CheckLinksSubCommand SubCommand = "check-links"
 )
 
-var KnownSubCommands = []SubCommand{ScrapeSubCommand, WriteSubCommand, ValidateSubCommand}
+var KnownSubCommands = []SubCommand{ScrapeSubCommand, WriteSubCommand, ValidateSubCommand, ServeSubCommand, SchemaSubCommand, CacheSubCommand, TrendSubCommand, MergeSubCommand, PublishSubCommand, DaemonSubCommand, SearchSubCommand, ShowSubCommand, LintSubCommand, CheckLinksSubCommand}
 
 // Flags holds all CLI flags and configuration
 type Flags struct {
-	SubCommand   SubCommand
-	LogLevel     slog.Level
-	ScrapeConfig ScrapeConfig
-	WriteConfig  WriteConfig
-	ValidateFile string
-	ShowHelp     bool
-	ShowVersion  bool
-	MaxWorkers   int
+	SubCommand     SubCommand
+	LogLevel       slog.Level
+	ScrapeConfig   ScrapeConfig
+	WriteConfig    WriteConfig
+	ServeConfig    ServeConfig
+	ValidateConfig ValidateConfig
+	CacheConfig    CacheCommandConfig
+	TrendConfig    TrendConfig
+	MergeConfig    MergeConfig
+	PublishConfig  PublishConfig
+	DaemonConfig   DaemonConfig
+	SearchConfig   SearchConfig
+	ShowConfig     ShowConfig
+	LintConfig     LintConfig
+	SchemaFile     string // where to write the JSON Schema, stdout if empty
+	ShowHelp       bool
+	ShowVersion    bool
+	MaxWorkers     int
+	AutoWorkers    bool // adapt the number of scrape workers to how the source copes, up to MaxWorkers
+	ConfigFile     string
+
+	CacheBackend        cache.Backend   // where fetched pages are cached
+	CacheTTLHours       int             // how long fetched pages are cached
+	SearchCacheTTLHours int             // how long search results are cached
+	CacheTTLRules       []cache.TTLRule // how long pages matching a pattern are cached, overriding the above
+	Offline             bool            // only serve requests from the cache
+	MemoryCacheEntries  int             // most cached pages also held in memory, 0 for none
+	MemoryCacheBytes    int64           // most bytes of cached pages held in memory, 0 for none
+	LockCache           bool            // fail if another instance is using the cache directory
+	GitHubToken         string          // authenticates GitHub API requests, never logged
+	WagoAPIKey          string          // authenticates Wago Addons API requests, never logged
+	RefreshPatterns     []string        // re-fetch pages matching these patterns regardless of the cache
+	RecordFixturesDir   string          // save responses to URLs matching RecordPatterns here as test fixtures, optional
+	RecordPatterns      []string        // URLs whose responses are recorded
+
+	Transport        http.TransportConfig // proxy, name resolution and connection pooling of requests
+	HTTPTimeout      time.Duration        // how long a request may take
+	HTTPTimeoutRules []http.TimeoutRule   // how long requests to URLs matching a pattern may take, overriding the above
+	MaxBytesPerHost  int64                // requests to a host are refused once it has sent this many bytes in a scrape, 0 for no limit
+	MaxResponseSize  int64                // responses with larger bodies fail
+	PprofAddr        string               // serve net/http/pprof here and log runtime statistics, optional
+
+	CheckLinksConfig CheckLinksConfig
+}
+
+// maxAutoWorkers is the most workers --workers auto raises the number of workers to
+const maxAutoWorkers = 50
+
+// workersValue is the --workers flag, a number of workers or "auto"
+type workersValue struct {
+	workers *int
+	auto    *bool
+}
+
+func (v workersValue) String() string {
+	if *v.auto {
+		return "auto"
+	}
+	return strconv.Itoa(*v.workers)
+}
+
+func (v workersValue) Set(s string) error {
+	if s == "auto" {
+		*v.workers, *v.auto = maxAutoWorkers, true
+		return nil
+	}
+	workers, err := strconv.Atoi(s)
+	if err != nil || workers < 1 {
+		return fmt.Errorf("must be a number of at least 1 or auto")
+	}
+	*v.workers, *v.auto = workers, false
+	return nil
+}
+
+func (v workersValue) Type() string {
+	return "int|auto"
 }
 
 // ParseFlags parses command line arguments and returns configuration
 func ParseFlags(args []string, version string) (*Flags, error) {
 	flags := &Flags{
-		MaxWorkers: 5, // Default number of workers
+		MaxWorkers:          5, // Default number of workers
+		CacheTTLHours:       48,
+		SearchCacheTTLHours: 2,
+		CacheTTLRules:       cache.DefaultTTLRules,
+		Transport:           http.DefaultTransportConfig(),
+		HTTPTimeout:         http.DefaultTimeout,
+		HTTPTimeoutRules:    http.DefaultTimeoutRules,
+		MaxResponseSize:     http.DefaultMaxResponseSize,
+		MemoryCacheEntries:  cache.DefaultMemoryEntries,
+		MemoryCacheBytes:    cache.DefaultMemoryBytes,
 	}
 
 	// Global flags
@@ -47,7 +156,8 @@ func ParseFlags(args []string, version string) (*Flags, error) {
 
 	var logLevelStr string
 	defaults.StringVar(&logLevelStr, "log-level", "info", "verbosity level. one of: debug, info, warn, error")
-	defaults.IntVar(&flags.MaxWorkers, "workers", 5, "number of concurrent workers")
+	defaults.Var(workersValue{&flags.MaxWorkers, &flags.AutoWorkers}, "workers", fmt.Sprintf("number of concurrent workers, or auto to scrape WowInterface with between 1 and %d workers, fewer while requests are throttled or failing and more while they aren't", maxAutoWorkers))
+	defaults.StringVar(&flags.ConfigFile, configFlagName, "", "read options from a TOML file. options given on the command line take precedence")
 
 	// Determine subcommand
 	var subcommand string
@@ -58,25 +168,233 @@ func ParseFlags(args []string, version string) (*Flags, error) {
 	var flagset *flag.FlagSet
 	scrapeConfig := ScrapeConfig{}
 	writeConfig := WriteConfig{}
+	serveConfig := ServeConfig{}
+	validateConfig := ValidateConfig{}
+	cacheConfig := CacheCommandConfig{}
+	trendConfig := TrendConfig{}
+	mergeConfig := MergeConfig{}
+	publishConfig := PublishConfig{}
+	daemonConfig := DaemonConfig{}
+	searchConfig := SearchConfig{}
+	showConfig := ShowConfig{}
+	lintConfig := LintConfig{}
+	checkLinksConfig := CheckLinksConfig{}
+	var thresholdsStr []string
+	var scheduleStr string
+	minRefreshAgeStr := "0"
+	shortCutoffStr := catalogue.DefaultShortCutoff.String()
+	trendDays := 7
 	apiVersionStr := "v4" // default
+	formatStr := string(JSONFormat)
+	reportFormatStr := string(TextReport)
+	notifyFormatStr := string(notify.JSONFormat)
+	profileStr := string(builder.FullProfile)
+	cacheBackendStr := string(cache.FilesBackend)
+	specVersion := types.DefaultSpecVersion
+	specVersionUsage := "catalogue spec version to write. one of: 2, 3 (adds authors, releases with checksums and addon folders)"
+	var datestamp string
+	var noIndent bool
+	datestampUsage := "datestamp of the catalogues written, as YYYY-MM-DD, for reproducible output (default: today, or the date of $" + catalogue.SourceDateEpochEnvVar + ")"
+	noIndentUsage := "write catalogues as compact JSON, without indentation"
+	manifestUsage := "write the sha256 of each catalogue to " + signing.ManifestFile + ", checked with `sha256sum -c`"
+	signKeyUsage := "sign each catalogue, and the manifest, with this unencrypted minisign secret key (`minisign -G -W`), writing FILE" + signing.SignatureExt
+	var cacheTTLStrs []string
+	for _, rule := range flags.CacheTTLRules {
+		cacheTTLStrs = append(cacheTTLStrs, rule.String())
+	}
+
+	var timeoutRuleStrs []string
+	for _, rule := range flags.HTTPTimeoutRules {
+		timeoutRuleStrs = append(timeoutRuleStrs, rule.String())
+	}
 
 	var sourcesStr []string
+	var sourceWorkersStrs []string
+	var mergeStrategyStrs []string
+	var proxyStr, dnsServerStr, maxBytesPerHostStr string
+	maxResponseSizeStr := "64M"
+	memoryCacheSizeStr := "64M"
+	var resolveStrs []string
+
+	// The daemon runs scrapes, with the same options
+	scrapes := subcommand == string(ScrapeSubCommand) || subcommand == string(DaemonSubCommand)
 
 	switch subcommand {
-	case string(ScrapeSubCommand):
-		flagset = flag.NewFlagSet("scrape", flag.ExitOnError)
+	case string(ScrapeSubCommand), string(DaemonSubCommand):
+		flagset = flag.NewFlagSet(subcommand, flag.ExitOnError)
 		flagset.StringVar(&apiVersionStr, "wowi-api-version", "v4", "WowInterface API version (v3 or v4). v3 has more addons and UIDir data")
-		flagset.StringArrayVar(&sourcesStr, "source", []string{"wowinterface"}, "sources to scrape")
+		flagset.StringArrayVar(&sourcesStr, "source", []string{"wowinterface"}, "sources to scrape. any of: wowinterface, github, gitlab, codeberg, wago, townlong-yak")
+		flagset.StringVar(&profileStr, "profile", profileStr, "how much of each WowInterface addon to fetch. one of: full (its API details and its page), api-only (its API details only, much quicker but without tags, created dates and other fields only on the page)")
+		flagset.BoolVar(&scrapeConfig.IncludeArchived, "include-archived", false, "also scrape WowInterface archived/legacy sections (excluded from the short catalogue)")
+		flagset.BoolVar(&scrapeConfig.IncludeImages, "include-images", false, "include the URL of each addon's first screenshot in the catalogues (image-url)")
+		flagset.BoolVar(&scrapeConfig.Summaries, "description-summaries", false, "describe WowInterface addons and GitHub READMEs with up to a few sentences of their first paragraph rather than its first line")
+		flagset.BoolVar(&scrapeConfig.ExtendedFields, "extended-fields", false, "include WowInterface favorite and monthly download counts in the catalogues (favorite-count, monthly-download-count)")
+		flagset.BoolVar(&scrapeConfig.Popularity, "popularity", false, "include the percentile of each addon's download count among the addons of its source in the catalogues, from 0 for the least downloaded to 1 for the most (popularity)")
+		flagset.BoolVar(&scrapeConfig.WithDependencies, "with-dependencies", false, "include the dependencies and optional files listed on WowInterface addon pages in the catalogues (dependency-list)")
+		flagset.BoolVar(&scrapeConfig.Authors, "authors", false, "fetch the page of each WowInterface addon author and write the addons of each author to authors.json")
+		flagset.BoolVar(&scrapeConfig.UnknownGameTracks, "unknown-game-tracks", false, "leave WowInterface addons whose game tracks can't be detected with an empty game-track-list instead of assuming retail. The scrape report counts them (unclassified-addons)")
+		flagset.StringVar(&scrapeConfig.NotifyURL, "notify-url", "", "post a summary (addon counts, changes since the last scrape, failures) to this webhook when the scrape finishes or fails")
+		flagset.StringVar(&notifyFormatStr, "notify-format", string(notify.JSONFormat), "body of the --notify-url request. one of: json, discord, matrix (a hookshot generic webhook)")
+		flagset.BoolVar(&scrapeConfig.Manifest, "manifest", false, manifestUsage)
+		flagset.StringVar(&scrapeConfig.SignKey, "sign-key", "", signKeyUsage)
+		flagset.BoolVar(&scrapeConfig.IncludeChangelogs, "include-changelogs", false, "keep WowInterface changelogs in the full catalogue and write them to changelogs.json (never in the published catalogues)")
+		flagset.BoolVar(&scrapeConfig.Releases, "releases", false, "write the latest release of each addon per game track, with its download URL, version and checksum, to releases.json")
+		flagset.BoolVar(&scrapeConfig.GitHubReadmes, "github-readme-descriptions", false, "fill empty GitHub addon descriptions from the repository README (slow, one request per addon)")
+		flagset.StringVar(&shortCutoffStr, "short-cutoff", shortCutoffStr, "leave addons not updated since this date out of the short catalogue, as YYYY-MM-DD or a period before the catalogue's datestamp such as '2 years' or '18 months' (default: the release of Dragonflight)")
+		flagset.IntVar(&scrapeConfig.ShortMinDownloads, "short-min-downloads", 0, "also keep addons downloaded more than this many times in the short catalogue however long ago they were updated, stable addons may never need an update. 0 to go by --short-cutoff only")
+		flagset.BoolVar(&scrapeConfig.CollapseDuplicates, "collapse-duplicates", false, "keep only the most recently updated copy of addons found in more than one source in the short catalogue")
+		flagset.BoolVar(&scrapeConfig.Progress, "progress", false, "show a progress bar with ETA when stderr is a terminal (progress is logged otherwise)")
+		flagset.StringVar(&flags.GitHubToken, "github-token", "", "authenticate GitHub API requests for a larger rate limit (default: $"+github.TokenEnvVar+")")
+		flagset.StringVar(&flags.WagoAPIKey, "wago-api-key", "", "Wago Addons API key, required to scrape the wago source (default: $"+wago.APIKeyEnvVar+")")
+		flagset.DurationVar(&scrapeConfig.GitHubReadmeInterval, "github-readme-interval", github.DefaultReadmeInterval, "minimum delay between README requests")
+		flagset.BoolVar(&scrapeConfig.GitHubTopics, "github-topics", false, "tag GitHub addons with their repository's topics (slow, one API request per addon, use --github-token)")
+		flagset.DurationVar(&scrapeConfig.GitHubTopicsInterval, "github-topics-interval", github.DefaultTopicsInterval, "minimum delay between topics requests")
+		flagset.StringVar(&scrapeConfig.StateDir, "state-dir", defaultStateDir, "directory to write catalogues and run state to")
+		flagset.StringVar(&scrapeConfig.Blocklist, "blocklist", defaultBlocklist, "JSON file of addons to leave out of the catalogues, per source, by source-id or name pattern. ignored if missing")
+		flagset.StringVar(&scrapeConfig.Overrides, "overrides", defaultOverrides, "JSON file of fields to patch on addons, keyed by source/source-id. ignored if missing")
+		flagset.IntVar(&specVersion, "spec-version", specVersion, specVersionUsage)
+		flagset.StringVar(&datestamp, "datestamp", "", datestampUsage)
+		flagset.BoolVar(&noIndent, "no-indent", false, noIndentUsage)
+		flagset.StringSliceVar(&scrapeConfig.OnlyIDs, "only-ids", nil, "re-scrape just these WowInterface addons (e.g. 8149,23145), skipping discovery, and merge them into the catalogues of the last scrape")
+		flagset.StringVar(&scrapeConfig.OnlyIDsFile, "only-ids-file", "", "like --only-ids, re-scraping the WowInterface addons listed in a "+state.FailedURLsFile+" from an earlier scrape")
+		flagset.BoolVar(&scrapeConfig.Incremental, "incremental", false, "only fetch the details of WowInterface addons the filelist says were updated since the last scrape, keeping the last scrape's entry of the others (their download counts aren't refreshed)")
+		flagset.StringVar(&minRefreshAgeStr, "min-refresh-age", minRefreshAgeStr, "don't fetch the details of WowInterface addons fetched within this long (e.g. 7d) that the filelist says weren't updated since, keeping the last scrape's entry. unlike the HTTP cache, each addon is still refreshed once this long has passed, and as soon as it's updated. 0 to always fetch them")
+		flagset.DurationVar(&scrapeConfig.DeadLetterCooldown, "dead-letter-cooldown", deadletter.DefaultCooldown, fmt.Sprintf("skip WowInterface addon pages that were missing (404 or 410) in %d scrapes for this long since they were last missing (recorded in %s). server and network errors never count. 0 to always fetch them", deadletter.MinFailures, state.DeadLettersFile))
+		flagset.IntVar(&scrapeConfig.MaxFailures, "max-failures", -1, "fail the scrape if more than this many URLs can't be fetched or parsed, after writing the catalogues and "+state.FailedURLsFile+". -1 for no limit")
+		flagset.DurationVar(&scrapeConfig.Timeout, "timeout", 0, "abandon the scrape if it hasn't finished after this long (e.g. 2h), nothing is written. 0 for no limit")
+		flagset.StringArrayVar(&mergeStrategyStrs, "merge-strategy", nil, "merge FIELD with STRATEGY (e.g. description=prefer-last) when the pages and API responses describing a WowInterface addon disagree. strategies: prefer-last, prefer-longest (text), prefer-max (counts), prefer-newest (dates), union (game-track-list, tag-list). default: description=prefer-longest, download-count=prefer-max, updated-date=prefer-newest, prefer-last for other fields")
+		flagset.StringArrayVar(&sourceWorkersStrs, "source-workers", nil, "scrape SOURCE with N workers (e.g. wowinterface=10) instead of --workers. sources are scraped at the same time, each with its own workers")
+		flagset.StringVar(&cacheBackendStr, "cache-backend", cacheBackendStr, "where to cache fetched pages. one of: files (a file per page), sqlite (a single cache.db, for filesystems that struggle with many small files)")
+		flagset.IntVar(&flags.CacheTTLHours, "cache-ttl-hours", flags.CacheTTLHours, "hours to cache fetched pages for")
+		flagset.IntVar(&flags.SearchCacheTTLHours, "search-cache-ttl-hours", flags.SearchCacheTTLHours, "hours to cache search results for")
+		flagset.IntVar(&flags.MemoryCacheEntries, "memory-cache-entries", flags.MemoryCacheEntries, "also hold this many of the most recently used cached pages in memory, so pages requested again in a run aren't re-read from disk. 0 to hold none")
+		flagset.StringVar(&memoryCacheSizeStr, "memory-cache-size", memoryCacheSizeStr, "hold at most this much (e.g. 128M) of cached pages in memory. 0 to hold none")
+		flagset.BoolVar(&flags.Offline, "offline", false, "never touch the network: serve everything from the cache however old, URLs missing from the cache fail and are counted in the scrape report")
+		flagset.StringVar(&proxyStr, "proxy", "", "make requests through this proxy (http://, https:// or socks5:// URL) (default: $HTTPS_PROXY or $HTTP_PROXY, except hosts in $NO_PROXY)")
+		flagset.StringArrayVar(&resolveStrs, "resolve", nil, "connect to IP for requests to HOST (e.g. api.mmoui.com=203.0.113.7) rather than to the addresses its name resolves to")
+		flagset.StringVar(&dnsServerStr, "dns-server", "", "resolve host names with the DNS server at IP[:PORT] rather than the system's resolver")
+		flagset.DurationVar(&flags.HTTPTimeout, "http-timeout", flags.HTTPTimeout, "give up on a request, including reading its response, after this long (it may be retried)")
+		flagset.StringArrayVar(&timeoutRuleStrs, "http-timeout-rule", timeoutRuleStrs, "give requests to URLs matching PATTERN (e.g. filelist.json=5m) this long instead of --http-timeout. the first matching pattern wins, giving this option replaces the defaults")
+		flagset.IntVar(&flags.Transport.MaxIdleConns, "max-idle-conns", flags.Transport.MaxIdleConns, "idle connections kept open across all hosts. 0 for no limit")
+		flagset.IntVar(&flags.Transport.MaxIdleConnsPerHost, "max-idle-conns-per-host", flags.Transport.MaxIdleConnsPerHost, "idle connections kept open to each host, raise it with many workers")
+		flagset.DurationVar(&flags.Transport.IdleConnTimeout, "idle-conn-timeout", flags.Transport.IdleConnTimeout, "close connections idle for this long. 0 to keep them open")
+		flagset.StringVar(&maxBytesPerHostStr, "max-bytes-per-host", "", "refuse further requests to a host once it has sent this much in a scrape (e.g. 500M), failing the scrape. cached pages don't count (default: no limit)")
+		flagset.StringVar(&maxResponseSizeStr, "max-response-size", maxResponseSizeStr, "fail requests for responses larger than this (e.g. 100M) rather than reading them")
+		flagset.StringVar(&flags.PprofAddr, "pprof-addr", "", "serve net/http/pprof on this address (e.g. localhost:6060) and log goroutines, heap usage and GC stats every minute, to inspect a scrape that seems to have stalled")
+		flagset.BoolVar(&flags.LockCache, "lock-cache", false, "lock the cache directory for the run, failing straight away if another builder is using it")
+		flagset.StringArrayVar(&flags.RefreshPatterns, "refresh", nil, "re-fetch pages matching PATTERN (e.g. 'filedetails/*.json') even if they're cached, once per run")
+		flagset.StringVar(&flags.RecordFixturesDir, "record-fixtures", "", "save a sanitised copy of each response to URLs matching --record-pattern to DIR, for use as test fixtures")
+		flagset.StringArrayVar(&flags.RecordPatterns, "record-pattern", []string{"*"}, "record responses to URLs matching PATTERN (e.g. 'downloads/info*') with --record-fixtures")
+		flagset.StringArrayVar(&cacheTTLStrs, "cache-ttl", cacheTTLStrs, "cache pages matching PATTERN for TTL (e.g. filelist.json=1h, downloads/info*=7d) instead of --cache-ttl-hours. the first matching pattern wins, giving this option replaces the defaults")
+		if subcommand == string(ScrapeSubCommand) {
+			flagset.IntVar(&scrapeConfig.Limit, "limit", 0, "only scrape the first this many addons of the WowInterface file list, for quicker runs while developing. the catalogues are marked partial and written to "+state.PartialDir+"/ in the state directory, apart from the last full scrape's. 0 for all")
+			flagset.StringArrayVar(&scrapeConfig.Categories, "category", nil, "only scrape WowInterface addons in this category (e.g. 'Unit Mods'), marking the catalogues partial like --limit. may be given more than once")
+			flagset.BoolVar(&scrapeConfig.DryRun, "dry-run", false, "fetch the WowInterface file list (or take it from the cache) and print how many addon URLs would be fetched, how many are cached and fresh, and roughly how long the scrape would take, without fetching them or writing anything")
+		}
+		if subcommand == string(DaemonSubCommand) {
+			flagset.StringVar(&scheduleStr, "schedule", "", "when to scrape, as a cron expression in local time (e.g. '0 3 * * 0' for 03:00 on Sundays) or @daily, @weekly and the like")
+			flagset.StringVar(&daemonConfig.Addr, "addr", ":8080", "address to serve the catalogues, /healthz and /metrics on")
+			flagset.DurationVar(&daemonConfig.CacheMaxAge, "max-age", server.DefaultCacheMaxAge, "Cache-Control max-age for served catalogues")
+		}
 		flagset.AddFlagSet(defaults)
 
 	case string(WriteSubCommand):
 		flagset = flag.NewFlagSet("write", flag.ExitOnError)
-		flagset.StringArrayVar(&writeConfig.OutputFiles, "out", []string{}, "write results to file (default: stdout)")
+		flagset.StringArrayVar(&writeConfig.OutputFiles, "out", []string{}, "write results to file (default: stdout). requires the last scrape to have passed the publish gate")
 		flagset.StringArrayVar(&sourcesStr, "source", []string{"wowinterface"}, "sources to include")
+		flagset.StringVar(&formatStr, "format", string(JSONFormat), "output format. one of: json, sqlite, ndjson")
+		flagset.StringVar(&writeConfig.ChangesFile, "changes", "", "update a changes feed listing addons added, updated or removed since the catalogue previously at the first --out file")
+		flagset.StringVar(&writeConfig.StateDir, "state-dir", defaultStateDir, "directory the last scrape wrote its catalogues to")
+		flagset.StringVar(&writeConfig.Blocklist, "blocklist", defaultBlocklist, "JSON file of addons to leave out of the catalogue, per source, by source-id or name pattern. ignored if missing")
+		flagset.StringVar(&writeConfig.Overrides, "overrides", defaultOverrides, "JSON file of fields to patch on addons, keyed by source/source-id. ignored if missing")
+		flagset.StringVar(&writeConfig.Allowlist, "allowlist", "", "JSON file of the only addons to include, in the same format as --blocklist")
+		flagset.IntVar(&specVersion, "spec-version", specVersion, specVersionUsage+". version 3 needs the last scrape to have used it too")
+		flagset.StringVar(&datestamp, "datestamp", "", datestampUsage)
+		flagset.BoolVar(&noIndent, "no-indent", false, noIndentUsage+". json format only")
+		flagset.BoolVar(&writeConfig.Manifest, "manifest", false, manifestUsage+", beside the first --out file")
+		flagset.StringVar(&writeConfig.SignKey, "sign-key", "", signKeyUsage)
 		flagset.AddFlagSet(defaults)
 
 	case string(ValidateSubCommand):
 		flagset = flag.NewFlagSet("validate", flag.ExitOnError)
+		flagset.BoolVarP(&validateConfig.Quiet, "quiet", "q", false, "only report failures and totals")
+		flagset.BoolVar(&validateConfig.Strict, "strict", false, "also reject duplicate addons and names, replacement characters in descriptions, unknown tags, URLs not matching the source and future updated-dates")
+		flagset.BoolVar(&validateConfig.VerifySignature, "verify-signature", false, "also check each catalogue's minisign signature, FILE"+signing.SignatureExt+", was made with --public-key")
+		flagset.StringVar(&validateConfig.PublicKey, "public-key", "", "minisign public key, or the file holding it, to verify signatures with")
+		flagset.IntVar(&validateConfig.MaxErrors, "max-errors", 20, "maximum number of problems logged per file, 0 for all")
+		flagset.StringVar(&reportFormatStr, "format", string(TextReport), "report format. one of: text, json, sarif. json and sarif reports are written to stdout")
+		flagset.AddFlagSet(defaults)
+
+	case string(ServeSubCommand):
+		flagset = flag.NewFlagSet("serve", flag.ExitOnError)
+		flagset.StringVar(&serveConfig.Addr, "addr", ":8080", "address to listen on")
+		flagset.StringVar(&serveConfig.StateDir, "state-dir", defaultStateDir, "directory of catalogues to serve")
+		flagset.DurationVar(&serveConfig.CacheMaxAge, "max-age", server.DefaultCacheMaxAge, "Cache-Control max-age for served catalogues")
+		flagset.AddFlagSet(defaults)
+
+	case string(SchemaSubCommand):
+		flagset = flag.NewFlagSet("schema", flag.ExitOnError)
+		flagset.StringVar(&flags.SchemaFile, "out", "", "write the schema to file (default: stdout)")
+		flagset.AddFlagSet(defaults)
+
+	case string(CacheSubCommand):
+		flagset = flag.NewFlagSet("cache", flag.ExitOnError)
+		flagset.StringArrayVar(&sourcesStr, "source", nil, "only list pages fetched for these sources (default: all)")
+		flagset.StringVar(&cacheBackendStr, "cache-backend", cacheBackendStr, "where fetched pages are cached. one of: files, sqlite")
+		flagset.AddFlagSet(defaults)
+
+	case string(TrendSubCommand):
+		flagset = flag.NewFlagSet("trend", flag.ExitOnError)
+		flagset.StringVar(&trendConfig.StateDir, "state-dir", defaultStateDir, "directory the download history was written to")
+		flagset.IntVar(&trendDays, "days", trendDays, "compare the latest download counts with those from at least this many days earlier, or the earliest available")
+		flagset.IntVar(&trendConfig.Limit, "limit", 20, "number of fastest growing addons to report, 0 for all")
+		flagset.AddFlagSet(defaults)
+
+	case string(MergeSubCommand):
+		flagset = flag.NewFlagSet("merge", flag.ExitOnError)
+		flagset.StringVar(&mergeConfig.OutputFile, "out", "", "write the merged catalogue to file (default: stdout)")
+		flagset.IntVar(&specVersion, "spec-version", specVersion, specVersionUsage)
+		flagset.StringVar(&datestamp, "datestamp", "", datestampUsage)
+		flagset.BoolVar(&noIndent, "no-indent", false, noIndentUsage)
+		flagset.AddFlagSet(defaults)
+
+	case string(PublishSubCommand):
+		flagset = flag.NewFlagSet("publish", flag.ExitOnError)
+		flagset.StringVar(&publishConfig.StateDir, "state-dir", defaultStateDir, "directory the last scrape wrote its catalogues to")
+		flagset.StringVar(&publishConfig.Repo, "repo", "", "GitHub repository, as OWNER/NAME, to publish a release of the catalogues in")
+		flagset.StringVar(&publishConfig.Tag, "tag", "catalogue", "tag of the release, created if missing. its catalogues are replaced on each publish")
+		flagset.StringVar(&publishConfig.Token, "github-token", "", "GitHub token allowed to write to --repo's releases (default: $"+github.TokenEnvVar+")")
+		flagset.StringVar(&publishConfig.S3URL, "target", "", "publish to s3://BUCKET/PREFIX instead of a GitHub release, authenticating with $AWS_ACCESS_KEY_ID and $AWS_SECRET_ACCESS_KEY")
+		flagset.StringVar(&publishConfig.S3Endpoint, "s3-endpoint", "", "URL of an S3-compatible object store to publish to (default: AWS)")
+		flagset.StringVar(&publishConfig.S3Region, "s3-region", cmp.Or(os.Getenv("AWS_REGION"), publish.DefaultS3Region), "region of the S3 bucket")
+		flagset.StringVar(&publishConfig.CacheControl, "cache-control", fmt.Sprintf("public, max-age=%d", int(server.DefaultCacheMaxAge.Seconds())), "Cache-Control of catalogues published to S3")
+		flagset.AddFlagSet(defaults)
+
+	case string(SearchSubCommand):
+		flagset = flag.NewFlagSet("search", flag.ExitOnError)
+		flagset.StringVar(&searchConfig.Catalogue, "catalogue", defaultStateDir, "catalogue file to search, or a directory holding a full-catalogue.json")
+		flagset.StringArrayVar(&sourcesStr, "source", nil, "only search addons of these sources (default: all)")
+		flagset.IntVar(&searchConfig.Limit, "limit", 20, "number of matches to print, 0 for all")
+		flagset.AddFlagSet(defaults)
+
+	case string(ShowSubCommand):
+		flagset = flag.NewFlagSet("show", flag.ExitOnError)
+		flagset.StringVar(&showConfig.StateDir, "state-dir", defaultStateDir, "directory the last scrape wrote its catalogues and addon data to")
+		flagset.AddFlagSet(defaults)
+
+	case string(LintSubCommand):
+		flagset = flag.NewFlagSet("lint", flag.ExitOnError)
+		flagset.StringArrayVar(&thresholdsStr, "threshold", nil, "fail when more than PERCENT of addons fail a check, as CHECK=PERCENT, repeatable")
+		flagset.IntVar(&lintConfig.Limit, "limit", 20, "number of addons printed for each check, 0 for all")
+		flagset.AddFlagSet(defaults)
+
+	case string(CheckLinksSubCommand):
+		flagset = flag.NewFlagSet("check-links", flag.ExitOnError)
+		flagset.StringVar(&checkLinksConfig.Releases, "releases", defaultStateDir, "releases manifest written by scrape --releases, or a directory holding a releases.json")
+		flagset.StringArrayVar(&sourcesStr, "source", nil, "only check the links of these sources (default: all)")
+		flagset.IntVar(&checkLinksConfig.Sample, "sample", 100, "number of links to check, picked at random, 0 to check every link")
+		flagset.Uint64Var(&checkLinksConfig.Seed, "seed", 0, "seed picking the sample, to check the same links again (default: random)")
+		flagset.DurationVar(&checkLinksConfig.Interval, "interval", 250*time.Millisecond, "minimum delay between requests, however many workers")
 		flagset.AddFlagSet(defaults)
 
 	default:
@@ -106,6 +424,13 @@ func ParseFlags(args []string, version string) (*Flags, error) {
 		return nil, fmt.Errorf("unknown subcommand: %s", subcommand)
 	}
 
+	// Fill in options not given on the command line from the config file
+	if flags.ConfigFile != "" {
+		if err := applyConfigFile(flags.ConfigFile, subcommand, flagset, defaults); err != nil {
+			return nil, err
+		}
+	}
+
 	// Parse log level
 	logLevelMap := map[string]slog.Level{
 		"debug": slog.LevelDebug,
@@ -119,56 +444,431 @@ func ParseFlags(args []string, version string) (*Flags, error) {
 		return nil, fmt.Errorf("unknown log level: %s", logLevelStr)
 	}
 
-	// Parse API version for scrape command
-	if subcommand == string(ScrapeSubCommand) {
+	// Parse cache backend for commands using the cache
+	if !slices.Contains(cache.KnownBackends, cache.Backend(cacheBackendStr)) {
+		return nil, fmt.Errorf("unknown cache backend: %s (must be files or sqlite)", cacheBackendStr)
+	}
+	flags.CacheBackend = cache.Backend(cacheBackendStr)
+
+	// Parse spec version for commands writing catalogues
+	if !slices.Contains(types.KnownSpecVersions, specVersion) {
+		return nil, fmt.Errorf("unknown spec version: %d (must be 2 or 3)", specVersion)
+	}
+	scrapeConfig.SpecVersion = specVersion
+	writeConfig.SpecVersion = specVersion
+	mergeConfig.SpecVersion = specVersion
+
+	// Parse datestamp for commands writing catalogues, falling back to $SOURCE_DATE_EPOCH
+	datestamp, err := catalogue.Datestamp(datestamp)
+	if err != nil {
+		return nil, err
+	}
+	if datestamp != "" {
+		if _, err := time.Parse(catalogue.DatestampFormat, datestamp); err != nil {
+			return nil, fmt.Errorf("invalid --datestamp %q (must be YYYY-MM-DD)", datestamp)
+		}
+	}
+	scrapeConfig.Datestamp, scrapeConfig.NoIndent = datestamp, noIndent
+	writeConfig.Datestamp, writeConfig.NoIndent = datestamp, noIndent
+	mergeConfig.Datestamp, mergeConfig.NoIndent = datestamp, noIndent
+
+	// Parse API version and cache TTL rules for commands scraping
+	if scrapes {
+		flags.CacheTTLRules = nil
+		for _, ruleStr := range cacheTTLStrs {
+			rule, err := cache.ParseTTLRule(ruleStr)
+			if err != nil {
+				return nil, err
+			}
+			flags.CacheTTLRules = append(flags.CacheTTLRules, rule)
+		}
+
+		if proxyStr != "" {
+			flags.Transport.Proxy, err = http.ParseProxy(proxyStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --proxy: %w", err)
+			}
+		}
+		for _, resolveStr := range resolveStrs {
+			host, addr, err := http.ParseResolve(resolveStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --resolve: %w", err)
+			}
+			if flags.Transport.Resolve == nil {
+				flags.Transport.Resolve = make(map[string]string)
+			}
+			flags.Transport.Resolve[host] = addr
+		}
+		if flags.HTTPTimeout <= 0 {
+			return nil, fmt.Errorf("--http-timeout must be positive: %s", flags.HTTPTimeout)
+		}
+		flags.HTTPTimeoutRules = nil
+		for _, ruleStr := range timeoutRuleStrs {
+			rule, err := http.ParseTimeoutRule(ruleStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --http-timeout-rule: %w", err)
+			}
+			flags.HTTPTimeoutRules = append(flags.HTTPTimeoutRules, rule)
+		}
+		if flags.Transport.MaxIdleConns < 0 || flags.Transport.MaxIdleConnsPerHost < 1 || flags.Transport.IdleConnTimeout < 0 {
+			return nil, fmt.Errorf("--max-idle-conns and --idle-conn-timeout must not be negative and --max-idle-conns-per-host must be at least 1")
+		}
+		if dnsServerStr != "" {
+			flags.Transport.DNSServer, err = http.ParseDNSServer(dnsServerStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --dns-server: %w", err)
+			}
+		}
+		if maxBytesPerHostStr != "" {
+			flags.MaxBytesPerHost, err = http.ParseBytes(maxBytesPerHostStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --max-bytes-per-host: %w", err)
+			}
+		}
+		flags.MaxResponseSize, err = http.ParseBytes(maxResponseSizeStr)
+		if err != nil || flags.MaxResponseSize == 0 {
+			return nil, fmt.Errorf("invalid --max-response-size, expected a size of at least 1 byte such as 64M: %s", maxResponseSizeStr)
+		}
+		flags.MemoryCacheBytes, err = http.ParseBytes(memoryCacheSizeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --memory-cache-size, expected a size such as 64M: %s", memoryCacheSizeStr)
+		}
+		if flags.MemoryCacheEntries < 0 {
+			return nil, fmt.Errorf("--memory-cache-entries must not be negative")
+		}
+
+		flags.GitHubToken = github.Token(flags.GitHubToken)
+		flags.WagoAPIKey = wago.APIKey(flags.WagoAPIKey)
+
+		for _, pattern := range flags.RefreshPatterns {
+			if err := cache.ValidatePattern(pattern); err != nil {
+				return nil, fmt.Errorf("invalid --refresh pattern: %w", err)
+			}
+		}
+		if flags.Offline && len(flags.RefreshPatterns) > 0 {
+			return nil, fmt.Errorf("--refresh can't be used with --offline")
+		}
+		for _, pattern := range flags.RecordPatterns {
+			if err := cache.ValidatePattern(pattern); err != nil {
+				return nil, fmt.Errorf("invalid --record-pattern: %w", err)
+			}
+		}
+
 		switch apiVersionStr {
 		case "v3":
-			scrapeConfig.WoWIAPIVersion = wowi.APIVersionV3
+			scrapeConfig.WoWIAPIVersion = string(wowi.APIVersionV3)
 		case "v4":
-			scrapeConfig.WoWIAPIVersion = wowi.APIVersionV4
+			scrapeConfig.WoWIAPIVersion = string(wowi.APIVersionV4)
 		default:
 			return nil, fmt.Errorf("unknown API version: %s (must be v3 or v4)", apiVersionStr)
 		}
 	}
 
+	// Parse output format for write command
+	if subcommand == string(WriteSubCommand) {
+		if !slices.Contains(KnownOutputFormats, OutputFormat(formatStr)) {
+			return nil, fmt.Errorf("unknown output format: %s (must be json, sqlite or ndjson)", formatStr)
+		}
+		writeConfig.Format = OutputFormat(formatStr)
+
+		if writeConfig.ChangesFile != "" && len(writeConfig.OutputFiles) == 0 {
+			return nil, fmt.Errorf("--changes requires an output file (--out)")
+		}
+		if writeConfig.NoIndent && writeConfig.Format != JSONFormat {
+			return nil, fmt.Errorf("--no-indent can only be used with the json format")
+		}
+		if (writeConfig.Manifest || writeConfig.SignKey != "") && len(writeConfig.OutputFiles) == 0 {
+			return nil, fmt.Errorf("--manifest and --sign-key require an output file (--out)")
+		}
+	}
+
 	// Parse sources after flags are parsed
-	if len(sourcesStr) > 0 {
-		for _, sourceStr := range sourcesStr {
-			switch sourceStr {
-			case "wowinterface":
-				if subcommand == string(ScrapeSubCommand) {
-					scrapeConfig.Sources = append(scrapeConfig.Sources, types.WowInterfaceSource)
-				} else if subcommand == string(WriteSubCommand) {
-					writeConfig.Sources = append(writeConfig.Sources, types.WowInterfaceSource)
-				}
-			case "github":
-				if subcommand == string(ScrapeSubCommand) {
-					scrapeConfig.Sources = append(scrapeConfig.Sources, types.GitHubSource)
-				} else if subcommand == string(WriteSubCommand) {
-					writeConfig.Sources = append(writeConfig.Sources, types.GitHubSource)
-				}
-			default:
-				return nil, fmt.Errorf("unknown source: %s", sourceStr)
+	for _, sourceStr := range sourcesStr {
+		source := types.Source(sourceStr)
+		if !slices.Contains(types.AllSources, source) {
+			return nil, fmt.Errorf("unknown source: %s", sourceStr)
+		}
+
+		switch subcommand {
+		case string(ScrapeSubCommand), string(DaemonSubCommand):
+			scrapeConfig.Sources = append(scrapeConfig.Sources, builder.Source(source))
+		case string(WriteSubCommand):
+			writeConfig.Sources = append(writeConfig.Sources, source)
+		case string(CacheSubCommand):
+			cacheConfig.Sources = append(cacheConfig.Sources, source)
+		case string(SearchSubCommand):
+			searchConfig.Sources = append(searchConfig.Sources, source)
+		case string(CheckLinksSubCommand):
+			checkLinksConfig.Sources = append(checkLinksConfig.Sources, source)
+		}
+	}
+	if len(scrapeConfig.OnlyIDs) > 0 || scrapeConfig.OnlyIDsFile != "" {
+		for _, id := range scrapeConfig.OnlyIDs {
+			if _, err := strconv.Atoi(id); err != nil {
+				return nil, fmt.Errorf("invalid WowInterface addon id in --only-ids: %s", id)
+			}
+		}
+		if !slices.Equal(scrapeConfig.Sources, []builder.Source{builder.WowInterface}) {
+			return nil, fmt.Errorf("--only-ids and --only-ids-file can only be used with --source wowinterface")
+		}
+		if scrapeConfig.IncludeArchived {
+			return nil, fmt.Errorf("--only-ids and --only-ids-file can't be used with --include-archived")
+		}
+		if scrapeConfig.Incremental {
+			return nil, fmt.Errorf("--only-ids and --only-ids-file can't be used with --incremental")
+		}
+	}
+	if scrapeConfig.Limit < 0 {
+		return nil, fmt.Errorf("--limit must not be negative")
+	}
+	for i, name := range scrapeConfig.Categories {
+		id, ok := wowi.CategoryID(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown WowInterface category in --category: %s", name)
+		}
+		scrapeConfig.Categories[i], _ = wowi.CategoryName(id)
+	}
+	if scrapeConfig.Limit > 0 || len(scrapeConfig.Categories) > 0 {
+		if !slices.Contains(scrapeConfig.Sources, builder.WowInterface) {
+			return nil, fmt.Errorf("--limit and --category can only be used with --source wowinterface")
+		}
+		if len(scrapeConfig.OnlyIDs) > 0 || scrapeConfig.OnlyIDsFile != "" || scrapeConfig.IncludeArchived {
+			return nil, fmt.Errorf("--limit and --category can't be used with --only-ids, --only-ids-file or --include-archived")
+		}
+	}
+	if scrapes {
+		if !slices.Contains(notify.KnownFormats, notify.Format(notifyFormatStr)) {
+			return nil, fmt.Errorf("unknown notification format: %s (must be json, discord or matrix)", notifyFormatStr)
+		}
+		scrapeConfig.NotifyFormat = notify.Format(notifyFormatStr)
+
+		if !slices.Contains(builder.KnownProfiles, builder.Profile(profileStr)) {
+			return nil, fmt.Errorf("unknown profile: %s (must be full or api-only)", profileStr)
+		}
+		scrapeConfig.Profile = builder.Profile(profileStr)
+		if scrapeConfig.Profile == builder.APIOnlyProfile && (scrapeConfig.Authors || scrapeConfig.IncludeArchived) {
+			return nil, fmt.Errorf("--profile api-only can't be used with --authors or --include-archived, which need addon and listing pages")
+		}
+	}
+	if scrapes {
+		scrapeConfig.MinRefreshAge, err = cache.ParseTTL(minRefreshAgeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --min-refresh-age: %w", err)
+		}
+		if scrapeConfig.MinRefreshAge > 0 && scrapeConfig.Incremental {
+			return nil, fmt.Errorf("--min-refresh-age can't be used with --incremental, which never refreshes addons that weren't updated")
+		}
+		if _, err := catalogue.ParseCutoff(shortCutoffStr); err != nil {
+			return nil, fmt.Errorf("invalid --short-cutoff: %w", err)
+		}
+		scrapeConfig.ShortCutoff = shortCutoffStr
+		if scrapeConfig.ShortMinDownloads < 0 {
+			return nil, fmt.Errorf("--short-min-downloads must not be negative: %d", scrapeConfig.ShortMinDownloads)
+		}
+		if scrapeConfig.MinRefreshAge > 0 && (len(scrapeConfig.OnlyIDs) > 0 || scrapeConfig.OnlyIDsFile != "") {
+			return nil, fmt.Errorf("--only-ids and --only-ids-file can't be used with --min-refresh-age")
+		}
+		for _, strategyStr := range mergeStrategyStrs {
+			field, strategy, err := catalogue.ParseMergeStrategy(strategyStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --merge-strategy: %w", err)
+			}
+			if scrapeConfig.MergeStrategies == nil {
+				scrapeConfig.MergeStrategies = make(map[string]string)
 			}
+			scrapeConfig.MergeStrategies[field] = string(strategy)
 		}
 	}
+	if scrapeConfig.DeadLetterCooldown < 0 {
+		return nil, fmt.Errorf("--dead-letter-cooldown must not be negative: %s", scrapeConfig.DeadLetterCooldown)
+	}
+	if scrapeConfig.Timeout < 0 {
+		return nil, fmt.Errorf("--timeout must not be negative: %s", scrapeConfig.Timeout)
+	}
+	if slices.Contains(scrapeConfig.Sources, builder.Wago) && flags.WagoAPIKey == "" {
+		return nil, fmt.Errorf("--source wago requires --wago-api-key or $%s", wago.APIKeyEnvVar)
+	}
 
 	// Assign parsed values
 	flags.SubCommand = SubCommand(subcommand)
 	flags.LogLevel = logLevel
 	flags.ScrapeConfig = scrapeConfig
 	flags.WriteConfig = writeConfig
+	flags.ServeConfig = serveConfig
 
 	// Set max workers in configs
 	flags.ScrapeConfig.MaxWorkers = flags.MaxWorkers
+	flags.ScrapeConfig.AutoWorkers = flags.AutoWorkers
+	for _, sourceWorkersStr := range sourceWorkersStrs {
+		sourceStr, workersStr, ok := strings.Cut(sourceWorkersStr, "=")
+		workers, err := strconv.Atoi(workersStr)
+		if !ok || err != nil || workers < 1 {
+			return nil, fmt.Errorf("invalid --source-workers %q (must be SOURCE=N, N at least 1)", sourceWorkersStr)
+		}
+		if !slices.Contains(types.AllSources, types.Source(sourceStr)) {
+			return nil, fmt.Errorf("unknown source: %s", sourceStr)
+		}
+		if flags.ScrapeConfig.SourceWorkers == nil {
+			flags.ScrapeConfig.SourceWorkers = make(map[builder.Source]int)
+		}
+		flags.ScrapeConfig.SourceWorkers[builder.Source(sourceStr)] = workers
+	}
 
-	// Parse validate file from remaining args
+	if subcommand == string(DaemonSubCommand) {
+		if len(scrapeConfig.OnlyIDs) > 0 || scrapeConfig.OnlyIDsFile != "" {
+			return nil, fmt.Errorf("--only-ids and --only-ids-file can't be used with the daemon command")
+		}
+		if scheduleStr == "" {
+			return nil, fmt.Errorf("daemon command requires --schedule")
+		}
+		daemonConfig.Schedule, err = schedule.Parse(scheduleStr)
+		if err != nil {
+			return nil, err
+		}
+		if daemonConfig.Schedule.Next(time.Now()).IsZero() {
+			return nil, fmt.Errorf("--schedule %q never fires", scheduleStr)
+		}
+		daemonConfig.Scrape = flags.ScrapeConfig
+		flags.DaemonConfig = daemonConfig
+	}
+
+	// Parse validate files from remaining args
 	if subcommand == string(ValidateSubCommand) {
 		remainingArgs := flagset.Args()
 		if len(remainingArgs) < 1 {
 			return nil, fmt.Errorf("validate command requires a catalogue file path")
 		}
-		flags.ValidateFile = remainingArgs[0]
+		if !slices.Contains(KnownReportFormats, ReportFormat(reportFormatStr)) {
+			return nil, fmt.Errorf("unknown report format: %s (must be text, json or sarif)", reportFormatStr)
+		}
+		validateConfig.Format = ReportFormat(reportFormatStr)
+		if validateConfig.VerifySignature && validateConfig.PublicKey == "" {
+			return nil, fmt.Errorf("--verify-signature requires --public-key")
+		}
+
+		flags.ValidateConfig = validateConfig
+		flags.ValidateConfig.Paths = remainingArgs
+		flags.ValidateConfig.MaxWorkers = flags.MaxWorkers
+		if flags.AutoWorkers {
+			flags.ValidateConfig.MaxWorkers = runtime.NumCPU() // nothing to throttle
+		}
+	}
+
+	// Parse the cache action from remaining args
+	if subcommand == string(CacheSubCommand) {
+		remainingArgs := flagset.Args()
+		if len(remainingArgs) == 0 || !slices.Contains(KnownCacheActions, CacheAction(remainingArgs[0])) {
+			return nil, fmt.Errorf("cache command requires one action: stats, ls, export FILE or import FILE")
+		}
+		cacheConfig.Action = CacheAction(remainingArgs[0])
+		switch cacheConfig.Action {
+		case CacheExportAction, CacheImportAction:
+			if len(remainingArgs) != 2 {
+				return nil, fmt.Errorf("cache %s requires one archive file", cacheConfig.Action)
+			}
+			cacheConfig.Archive = remainingArgs[1]
+		default:
+			if len(remainingArgs) != 1 {
+				return nil, fmt.Errorf("cache %s takes no arguments", cacheConfig.Action)
+			}
+		}
+		if cacheConfig.Action != CacheListAction && len(cacheConfig.Sources) > 0 {
+			return nil, fmt.Errorf("--source can only be used with cache ls")
+		}
+		flags.CacheConfig = cacheConfig
+	}
+
+	if subcommand == string(TrendSubCommand) {
+		if trendDays < 1 {
+			return nil, fmt.Errorf("--days must be at least 1")
+		}
+		trendConfig.Period = time.Duration(trendDays) * 24 * time.Hour
+		flags.TrendConfig = trendConfig
+	}
+
+	if subcommand == string(PublishSubCommand) {
+		if publishConfig.S3URL != "" {
+			if _, _, err := publish.ParseS3URL(publishConfig.S3URL); err != nil {
+				return nil, err
+			}
+		} else {
+			if owner, name, ok := strings.Cut(publishConfig.Repo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+				return nil, fmt.Errorf("publish command requires --repo OWNER/NAME or --target s3://BUCKET/PREFIX")
+			}
+			if publishConfig.Tag == "" {
+				return nil, fmt.Errorf("--tag must not be empty")
+			}
+			publishConfig.Token = github.Token(publishConfig.Token)
+			if publishConfig.Token == "" {
+				return nil, fmt.Errorf("publish command requires --github-token or $%s", github.TokenEnvVar)
+			}
+		}
+		flags.PublishConfig = publishConfig
+	}
+
+	if subcommand == string(MergeSubCommand) {
+		mergeConfig.Paths = flagset.Args()
+		if len(mergeConfig.Paths) < 2 {
+			return nil, fmt.Errorf("merge command requires at least two catalogue files")
+		}
+		flags.MergeConfig = mergeConfig
+	}
+
+	if subcommand == string(SearchSubCommand) {
+		searchConfig.Query = strings.TrimSpace(strings.Join(flagset.Args(), " "))
+		if searchConfig.Query == "" {
+			return nil, fmt.Errorf("search command requires a query")
+		}
+		if searchConfig.Limit < 0 {
+			return nil, fmt.Errorf("--limit must not be negative: %d", searchConfig.Limit)
+		}
+		flags.SearchConfig = searchConfig
+	}
+
+	if subcommand == string(ShowSubCommand) {
+		remainingArgs := flagset.Args()
+		if len(remainingArgs) != 2 {
+			return nil, fmt.Errorf("show command requires a source and a source-id, e.g. show wowinterface 4815")
+		}
+		showConfig.Source, showConfig.SourceID = types.Source(remainingArgs[0]), remainingArgs[1]
+		if !slices.Contains(types.AllSources, showConfig.Source) {
+			return nil, fmt.Errorf("unknown source: %s", showConfig.Source)
+		}
+		flags.ShowConfig = showConfig
+	}
+
+	if subcommand == string(LintSubCommand) {
+		remainingArgs := flagset.Args()
+		if len(remainingArgs) != 1 {
+			return nil, fmt.Errorf("lint command requires a catalogue file, or a directory holding a full-catalogue.json")
+		}
+		lintConfig.Path = remainingArgs[0]
+		lintConfig.Thresholds = maps.Clone(lint.DefaultThresholds)
+		for _, thresholdStr := range thresholdsStr {
+			check, percent, err := lint.ParseThreshold(thresholdStr)
+			if err != nil {
+				return nil, err
+			}
+			lintConfig.Thresholds[check] = percent
+		}
+		if lintConfig.Limit < 0 {
+			return nil, fmt.Errorf("--limit must not be negative: %d", lintConfig.Limit)
+		}
+		flags.LintConfig = lintConfig
+	}
+
+	if subcommand == string(CheckLinksSubCommand) {
+		if len(flagset.Args()) > 0 {
+			return nil, fmt.Errorf("check-links command takes no arguments, the releases manifest is given with --releases")
+		}
+		if checkLinksConfig.Sample < 0 {
+			return nil, fmt.Errorf("--sample must not be negative: %d", checkLinksConfig.Sample)
+		}
+		if checkLinksConfig.Interval < 0 {
+			return nil, fmt.Errorf("--interval must not be negative: %s", checkLinksConfig.Interval)
+		}
+		checkLinksConfig.MaxWorkers = flags.MaxWorkers
+		flags.CheckLinksConfig = checkLinksConfig
 	}
 
 	return flags, nil
@@ -176,12 +876,25 @@ func ParseFlags(args []string, version string) (*Flags, error) {
 
 // printUsage prints usage information
 func printUsage(flagset *flag.FlagSet) {
-	fmt.Println("usage: strongbox-catalogue-builder <scrape|write|validate> [options]")
+	fmt.Println("usage: strongbox-catalogue-builder <scrape|write|validate|serve|schema|cache|trend|merge|publish|daemon|search|show|lint|check-links> [options]")
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Println("  scrape           Scrape addon data and write catalogues to state/ directory")
-	fmt.Println("  write            Generate catalogues from existing state files")
-	fmt.Println("  validate <file>  Validate a catalogue JSON file")
+	fmt.Println("  write            Generate catalogues from the last scrape's state files")
+	fmt.Println("  validate <file>  Validate catalogue JSON files, accepts several files and glob patterns")
+	fmt.Println("  serve            Serve the catalogues in the state/ directory over HTTP")
+	fmt.Println("  schema           Print the JSON Schema of the catalogue format")
+	fmt.Println("  cache <stats|ls> Summarise the HTTP cache by host, or list the cached URLs")
+	fmt.Println("  cache <export|import> <file>")
+	fmt.Println("                   Pack the HTTP cache into a .tar.zst archive, or restore one, to share a scrape's pages")
+	fmt.Println("  trend            Report the fastest growing addons and addons whose download counts dropped")
+	fmt.Println("  merge <file>...  Merge catalogue files, keeping the newest copy of addons found in more than one")
+	fmt.Println("  publish          Upload the last scrape's catalogues to a GitHub release or S3, once they've passed the publish gate")
+	fmt.Println("  daemon           Scrape on a schedule, serving the latest catalogues over HTTP with health and metrics")
+	fmt.Println("  search <query>   Find addons in a catalogue by name, label, description or tags, forgiving typos")
+	fmt.Println("  show <src> <id>  Print the data each file gave about an addon, how it was merged and its catalogue entry")
+	fmt.Println("  lint <file>      Report addons with poor data, failing when too many fail a check")
+	fmt.Println("  check-links      Check the release download URLs written by scrape --releases are alive, reporting redirects")
 	fmt.Println()
 	fmt.Println("Options:")
 	flagset.PrintDefaults()
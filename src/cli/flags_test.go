@@ -0,0 +1,715 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/pkg/builder"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/cache"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/catalogue"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/github"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/lint"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/server"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/wago"
+)
+
+func TestParseFlags_Refresh(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--refresh", "filedetails/*.json", "--refresh", "filelist.json"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if want := []string{"filedetails/*.json", "filelist.json"}; !reflect.DeepEqual(flags.RefreshPatterns, want) {
+		t.Errorf("RefreshPatterns = %v, want %v", flags.RefreshPatterns, want)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"invalid pattern", []string{"--refresh", "info["}, "invalid --refresh pattern"},
+		{"offline", []string{"--refresh", "filelist.json", "--offline"}, "can't be used with --offline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "scrape"}, tt.args...), "test")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFlags() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFlags_Cache(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "cache", "ls", "--source", "wowinterface"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if flags.CacheConfig.Action != CacheListAction || !reflect.DeepEqual(flags.CacheConfig.Sources, []types.Source{types.WowInterfaceSource}) {
+		t.Errorf("CacheConfig = %+v, want ls of wowinterface", flags.CacheConfig)
+	}
+	flags, err = ParseFlags([]string{"strongbox-catalogue-builder", "cache", "export", "snapshot.tar.zst"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if flags.CacheConfig.Action != CacheExportAction || flags.CacheConfig.Archive != "snapshot.tar.zst" {
+		t.Errorf("CacheConfig = %+v, want export to snapshot.tar.zst", flags.CacheConfig)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"no action", nil, "requires one action"},
+		{"unknown action", []string{"rm"}, "requires one action"},
+		{"stats with source", []string{"stats", "--source", "github"}, "only be used with cache ls"},
+		{"import with source", []string{"import", "snapshot.tar.zst", "--source", "github"}, "only be used with cache ls"},
+		{"export without archive", []string{"export"}, "requires one archive file"},
+		{"ls with argument", []string{"ls", "extra"}, "takes no arguments"},
+		{"unknown cache backend", []string{"stats", "--cache-backend", "bolt"}, "unknown cache backend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "cache"}, tt.args...), "test")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFlags() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFlags_WagoAPIKey(t *testing.T) {
+	t.Setenv(wago.APIKeyEnvVar, "")
+	if _, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--source", "wago"}, "test"); err == nil || !strings.Contains(err.Error(), "--wago-api-key") {
+		t.Errorf("ParseFlags() without an API key error = %v, want it to ask for --wago-api-key", err)
+	}
+
+	t.Setenv(wago.APIKeyEnvVar, "from-env")
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--source", "wago"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if flags.WagoAPIKey != "from-env" {
+		t.Errorf("WagoAPIKey = %q, want from-env", flags.WagoAPIKey)
+	}
+}
+
+func TestParseFlags_Merge(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "merge", "a.json", "b.json", "--out", "full.json"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	want := MergeConfig{Paths: []string{"a.json", "b.json"}, OutputFile: "full.json", SpecVersion: types.DefaultSpecVersion}
+	if !reflect.DeepEqual(flags.MergeConfig, want) {
+		t.Errorf("MergeConfig = %+v, want %+v", flags.MergeConfig, want)
+	}
+
+	if _, err := ParseFlags([]string{"strongbox-catalogue-builder", "merge", "a.json"}, "test"); err == nil || !strings.Contains(err.Error(), "at least two") {
+		t.Errorf("ParseFlags() with one file error = %v, want it to ask for two", err)
+	}
+}
+
+func TestParseFlags_SpecVersion(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "write", "--spec-version", "3"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if flags.WriteConfig.SpecVersion != types.SpecVersion3 {
+		t.Errorf("WriteConfig.SpecVersion = %d, want %d", flags.WriteConfig.SpecVersion, types.SpecVersion3)
+	}
+
+	if _, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--spec-version", "4"}, "test"); err == nil || !strings.Contains(err.Error(), "unknown spec version") {
+		t.Errorf("ParseFlags() error = %v, want an unknown spec version", err)
+	}
+}
+
+func TestParseFlags_Datestamp(t *testing.T) {
+	t.Setenv(catalogue.SourceDateEpochEnvVar, "1700000000")
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "merge", "a.json", "b.json", "--no-indent"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if flags.MergeConfig.Datestamp != "2023-11-14" || !flags.MergeConfig.NoIndent {
+		t.Errorf("MergeConfig = %+v, want the datestamp of $%s and no indent", flags.MergeConfig, catalogue.SourceDateEpochEnvVar)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"invalid datestamp", []string{"write", "--datestamp", "01/03/2024"}, "invalid --datestamp"},
+		{"no indent with ndjson", []string{"write", "--format", "ndjson", "--no-indent"}, "only be used with the json format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFlags(append([]string{"strongbox-catalogue-builder"}, tt.args...), "test")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFlags() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFlags_SourceWorkers(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--workers", "3", "--source-workers", "wowinterface=10"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	for source, want := range map[builder.Source]int{builder.WowInterface: 10, builder.GitHub: 3} {
+		if got := flags.ScrapeConfig.SourceWorkerBudget(source); got != want {
+			t.Errorf("SourceWorkerBudget(%s) = %d, want %d", source, got, want)
+		}
+	}
+
+	for _, arg := range []string{"wowinterface", "wowinterface=0", "curseforge=2"} {
+		if _, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--source-workers", arg}, "test"); err == nil {
+			t.Errorf("ParseFlags(--source-workers %s) expected an error", arg)
+		}
+	}
+}
+
+func TestParseFlags_Workers(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantMax  int
+		wantAuto bool
+	}{
+		{nil, 5, false},
+		{[]string{"--workers", "8"}, 8, false},
+		{[]string{"--workers", "auto"}, maxAutoWorkers, true},
+		{[]string{"--workers", "auto", "--workers", "2"}, 2, false},
+	}
+	for _, tt := range tests {
+		flags, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "scrape"}, tt.args...), "test")
+		if err != nil {
+			t.Fatalf("ParseFlags(%v) unexpected error: %v", tt.args, err)
+		}
+		if flags.ScrapeConfig.MaxWorkers != tt.wantMax || flags.ScrapeConfig.AutoWorkers != tt.wantAuto {
+			t.Errorf("ParseFlags(%v) workers = %d, auto %v, want %d, auto %v", tt.args, flags.ScrapeConfig.MaxWorkers, flags.ScrapeConfig.AutoWorkers, tt.wantMax, tt.wantAuto)
+		}
+	}
+
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "validate", "--workers", "auto", "catalogue.json"}, "test")
+	if err != nil || flags.ValidateConfig.MaxWorkers < 1 || flags.ValidateConfig.MaxWorkers == maxAutoWorkers {
+		t.Errorf("ParseFlags(validate --workers auto) = %v, %v, want a worker per CPU", flags, err)
+	}
+
+	// Invalid values exit from the command line, set them as the config file does
+	var workers int
+	var auto bool
+	for _, arg := range []string{"0", "-1", "some"} {
+		if err := (workersValue{&workers, &auto}).Set(arg); err == nil {
+			t.Errorf("--workers %s expected an error", arg)
+		}
+	}
+}
+
+func TestParseFlags_Transport(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--proxy", "socks5://127.0.0.1:1080", "--resolve", "api.mmoui.com=203.0.113.7", "--dns-server", "1.1.1.1"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if flags.Transport.Proxy == nil || flags.Transport.Proxy.String() != "socks5://127.0.0.1:1080" {
+		t.Errorf("Transport.Proxy = %v, want socks5://127.0.0.1:1080", flags.Transport.Proxy)
+	}
+	if want := map[string]string{"api.mmoui.com": "203.0.113.7"}; !reflect.DeepEqual(flags.Transport.Resolve, want) {
+		t.Errorf("Transport.Resolve = %v, want %v", flags.Transport.Resolve, want)
+	}
+	if flags.Transport.DNSServer != "1.1.1.1:53" {
+		t.Errorf("Transport.DNSServer = %q, want 1.1.1.1:53", flags.Transport.DNSServer)
+	}
+
+	for _, args := range [][]string{{"--proxy", "proxy.example.org"}, {"--resolve", "api.mmoui.com"}, {"--dns-server", "dns.example.org"}} {
+		if _, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "scrape"}, args...), "test"); err == nil {
+			t.Errorf("ParseFlags(%v) expected an error", args)
+		}
+	}
+}
+
+func TestParseFlags_HTTPTimeouts(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if flags.HTTPTimeout != http.DefaultTimeout || !reflect.DeepEqual(flags.HTTPTimeoutRules, http.DefaultTimeoutRules) || !reflect.DeepEqual(flags.Transport, http.DefaultTransportConfig()) {
+		t.Errorf("ParseFlags() = %v, %v, %+v, want the defaults", flags.HTTPTimeout, flags.HTTPTimeoutRules, flags.Transport)
+	}
+
+	flags, err = ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--http-timeout", "10s", "--http-timeout-rule", "filedetails/*.json=5s",
+		"--max-idle-conns", "0", "--max-idle-conns-per-host", "50", "--idle-conn-timeout", "30s"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if flags.HTTPTimeout != 10*time.Second {
+		t.Errorf("HTTPTimeout = %v, want 10s", flags.HTTPTimeout)
+	}
+	if want := []http.TimeoutRule{{Pattern: "filedetails/*.json", Timeout: 5 * time.Second}}; !reflect.DeepEqual(flags.HTTPTimeoutRules, want) {
+		t.Errorf("HTTPTimeoutRules = %v, want %v (replacing the defaults)", flags.HTTPTimeoutRules, want)
+	}
+	if flags.Transport.MaxIdleConns != 0 || flags.Transport.MaxIdleConnsPerHost != 50 || flags.Transport.IdleConnTimeout != 30*time.Second {
+		t.Errorf("Transport = %+v, want the pool set by the flags", flags.Transport)
+	}
+
+	for _, args := range [][]string{{"--http-timeout", "0s"}, {"--http-timeout-rule", "filelist.json"}, {"--max-idle-conns-per-host", "0"}, {"--idle-conn-timeout", "-1s"}} {
+		if _, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "scrape"}, args...), "test"); err == nil {
+			t.Errorf("ParseFlags(%v) expected an error", args)
+		}
+	}
+}
+
+func TestParseFlags_MaxBytesPerHost(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    int64
+		wantErr bool
+	}{
+		{nil, 0, false},
+		{[]string{"--max-bytes-per-host", "500M"}, 500 << 20, false},
+		{[]string{"--max-bytes-per-host", "1048576"}, 1 << 20, false},
+		{[]string{"--max-bytes-per-host", "lots"}, 0, true},
+	}
+	for _, tt := range tests {
+		flags, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "scrape"}, tt.args...), "test")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFlags(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if err == nil && flags.MaxBytesPerHost != tt.want {
+			t.Errorf("ParseFlags(%v).MaxBytesPerHost = %d, want %d", tt.args, flags.MaxBytesPerHost, tt.want)
+		}
+	}
+}
+
+func TestParseFlags_MaxResponseSize(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    int64
+		wantErr bool
+	}{
+		{nil, http.DefaultMaxResponseSize, false},
+		{[]string{"--max-response-size", "100M"}, 100 << 20, false},
+		{[]string{"--max-response-size", "0"}, 0, true},
+		{[]string{"--max-response-size", "big"}, 0, true},
+	}
+	for _, tt := range tests {
+		flags, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "scrape"}, tt.args...), "test")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFlags(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if err == nil && flags.MaxResponseSize != tt.want {
+			t.Errorf("ParseFlags(%v).MaxResponseSize = %d, want %d", tt.args, flags.MaxResponseSize, tt.want)
+		}
+	}
+}
+
+func TestParseFlags_DryRun(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--dry-run"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if !flags.ScrapeConfig.DryRun {
+		t.Error("ParseFlags(--dry-run).ScrapeConfig.DryRun = false, want true")
+	}
+}
+
+func TestDefaultScrapeConfig(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if got := DefaultScrapeConfig(); !reflect.DeepEqual(got, flags.ScrapeConfig) {
+		t.Errorf("DefaultScrapeConfig() = %+v, want the scrape command's defaults %+v", got, flags.ScrapeConfig)
+	}
+}
+
+func TestParseFlags_ScrapeScope(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--limit", "200", "--category", "unit mods"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if flags.ScrapeConfig.Limit != 200 || !reflect.DeepEqual(flags.ScrapeConfig.Categories, []string{"Unit Mods"}) {
+		t.Errorf("ScrapeConfig limit, categories = %d, %v, want 200, [Unit Mods]", flags.ScrapeConfig.Limit, flags.ScrapeConfig.Categories)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"negative limit", []string{"--limit", "-1"}, "--limit must not be negative"},
+		{"unknown category", []string{"--category", "Unit Mod"}, "unknown WowInterface category"},
+		{"without wowinterface", []string{"--limit", "10", "--source", "github"}, "wowinterface"},
+		{"only ids", []string{"--limit", "10", "--only-ids", "1"}, "--only-ids"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "scrape"}, tt.args...), "test")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFlags() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFlags_MemoryCache(t *testing.T) {
+	tests := []struct {
+		args        []string
+		wantEntries int
+		wantBytes   int64
+		wantErr     bool
+	}{
+		{nil, cache.DefaultMemoryEntries, cache.DefaultMemoryBytes, false},
+		{[]string{"--memory-cache-entries", "10", "--memory-cache-size", "1M"}, 10, 1 << 20, false},
+		{[]string{"--memory-cache-size", "0"}, cache.DefaultMemoryEntries, 0, false},
+		{[]string{"--memory-cache-entries", "-1"}, 0, 0, true},
+		{[]string{"--memory-cache-size", "lots"}, 0, 0, true},
+	}
+	for _, tt := range tests {
+		flags, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "scrape"}, tt.args...), "test")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFlags(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (flags.MemoryCacheEntries != tt.wantEntries || flags.MemoryCacheBytes != tt.wantBytes) {
+			t.Errorf("ParseFlags(%v) memory cache = %d, %d, want %d, %d", tt.args, flags.MemoryCacheEntries, flags.MemoryCacheBytes, tt.wantEntries, tt.wantBytes)
+		}
+	}
+}
+
+func TestParseFlags_Profile(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    builder.Profile
+		wantErr bool
+	}{
+		{nil, builder.FullProfile, false},
+		{[]string{"--profile", "api-only"}, builder.APIOnlyProfile, false},
+		{[]string{"--profile", "full", "--authors"}, builder.FullProfile, false},
+		{[]string{"--profile", "quick"}, "", true},
+		{[]string{"--profile", "api-only", "--authors"}, "", true},
+		{[]string{"--profile", "api-only", "--include-archived"}, "", true},
+	}
+	for _, tt := range tests {
+		flags, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "scrape"}, tt.args...), "test")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFlags(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if err == nil && flags.ScrapeConfig.Profile != tt.want {
+			t.Errorf("ParseFlags(%v).ScrapeConfig.Profile = %s, want %s", tt.args, flags.ScrapeConfig.Profile, tt.want)
+		}
+	}
+}
+
+func TestParseFlags_Timeout(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--timeout", "2h"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if flags.ScrapeConfig.Timeout != 2*time.Hour {
+		t.Errorf("ScrapeConfig.Timeout = %v, want %v", flags.ScrapeConfig.Timeout, 2*time.Hour)
+	}
+
+	if _, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--timeout", "-1m"}, "test"); err == nil {
+		t.Error("ParseFlags(--timeout -1m) expected an error")
+	}
+}
+
+func TestParseFlags_OnlyIDs(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--only-ids", "8149,23145"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if want := []string{"8149", "23145"}; !reflect.DeepEqual(flags.ScrapeConfig.OnlyIDs, want) {
+		t.Errorf("ScrapeConfig.OnlyIDs = %v, want %v", flags.ScrapeConfig.OnlyIDs, want)
+	}
+
+	for _, args := range [][]string{
+		{"--only-ids", "8149,adibags"},
+		{"--only-ids", "8149", "--source", "github"},
+		{"--only-ids-file", "failed-urls.json", "--include-archived"},
+		{"--only-ids", "8149", "--incremental"},
+		{"--only-ids", "8149", "--min-refresh-age", "7d"},
+	} {
+		if _, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "scrape"}, args...), "test"); err == nil {
+			t.Errorf("ParseFlags(%v) expected an error", args)
+		}
+	}
+}
+
+func TestParseFlags_MinRefreshAge(t *testing.T) {
+	for arg, want := range map[string]time.Duration{"7d": 7 * 24 * time.Hour, "36h": 36 * time.Hour, "0": 0} {
+		flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--min-refresh-age", arg}, "test")
+		if err != nil {
+			t.Fatalf("ParseFlags(--min-refresh-age %s) unexpected error: %v", arg, err)
+		}
+		if flags.ScrapeConfig.MinRefreshAge != want {
+			t.Errorf("ScrapeConfig.MinRefreshAge = %s, want %s", flags.ScrapeConfig.MinRefreshAge, want)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"--min-refresh-age", "a week"},
+		{"--min-refresh-age", "-1d"},
+		{"--min-refresh-age", "7d", "--incremental"},
+	} {
+		if _, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "scrape"}, args...), "test"); err == nil {
+			t.Errorf("ParseFlags(%v) expected an error", args)
+		}
+	}
+}
+
+func TestParseFlags_ShortPolicy(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if want := catalogue.DefaultShortCutoff.String(); flags.ScrapeConfig.ShortCutoff != want || flags.ScrapeConfig.ShortMinDownloads != 0 {
+		t.Errorf("ScrapeConfig.ShortCutoff = %q, ShortMinDownloads = %d, want %q and 0", flags.ScrapeConfig.ShortCutoff, flags.ScrapeConfig.ShortMinDownloads, want)
+	}
+
+	flags, err = ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--short-cutoff", "2 years", "--short-min-downloads", "100000"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if config := flags.ScrapeConfig; config.ShortCutoff != "2 years" || config.ShortMinDownloads != 100000 {
+		t.Errorf("ScrapeConfig.ShortCutoff = %q, ShortMinDownloads = %d, want a 2 year cutoff keeping addons with over 100000 downloads", config.ShortCutoff, config.ShortMinDownloads)
+	}
+
+	for _, args := range [][]string{
+		{"--short-cutoff", "last year"},
+		{"--short-min-downloads", "-1"},
+	} {
+		if _, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "scrape"}, args...), "test"); err == nil {
+			t.Errorf("ParseFlags(%v) expected an error", args)
+		}
+	}
+}
+
+func TestParseFlags_MergeStrategy(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--merge-strategy", "description=prefer-last", "--merge-strategy", "tag-list=prefer-last"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	want := map[string]string{"description": "prefer-last", "tag-list": "prefer-last"}
+	if !reflect.DeepEqual(flags.ScrapeConfig.MergeStrategies, want) {
+		t.Errorf("ScrapeConfig.MergeStrategies = %v, want %v", flags.ScrapeConfig.MergeStrategies, want)
+	}
+
+	for _, strategy := range []string{"description", "description=prefer-max", "url=prefer-last"} {
+		if _, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--merge-strategy", strategy}, "test"); err == nil {
+			t.Errorf("ParseFlags(--merge-strategy %s) expected an error", strategy)
+		}
+	}
+}
+
+func TestParseFlags_RecordFixtures(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--record-fixtures", "fixtures", "--record-pattern", "downloads/info*"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if flags.RecordFixturesDir != "fixtures" || !reflect.DeepEqual(flags.RecordPatterns, []string{"downloads/info*"}) {
+		t.Errorf("ParseFlags() = %s %v, want fixtures [downloads/info*]", flags.RecordFixturesDir, flags.RecordPatterns)
+	}
+
+	if _, err := ParseFlags([]string{"strongbox-catalogue-builder", "scrape", "--record-pattern", "[bad"}, "test"); err == nil {
+		t.Error("ParseFlags(--record-pattern [bad) expected an error")
+	}
+}
+
+func TestParseFlags_Signing(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "validate", "--verify-signature", "--public-key", "key.pub", "full-catalogue.json"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if !flags.ValidateConfig.VerifySignature || flags.ValidateConfig.PublicKey != "key.pub" {
+		t.Errorf("ValidateConfig = %+v, want signatures verified with key.pub", flags.ValidateConfig)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"verify without a key", []string{"validate", "--verify-signature", "full-catalogue.json"}, "requires --public-key"},
+		{"manifest to stdout", []string{"write", "--manifest"}, "require an output file"},
+		{"signing stdout", []string{"write", "--sign-key", "secret.key"}, "require an output file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFlags(append([]string{"strongbox-catalogue-builder"}, tt.args...), "test")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFlags() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFlags_Daemon(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "daemon", "--schedule", "0 3 * * 0", "--source", "github", "--addr", ":9090"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	config := flags.DaemonConfig
+	if fmt.Sprint(config.Schedule) != "0 3 * * 0" || config.Addr != ":9090" || config.CacheMaxAge != server.DefaultCacheMaxAge {
+		t.Errorf("DaemonConfig = %+v, want schedule 0 3 * * 0 on :9090", config)
+	}
+	// Scrape options are shared
+	if !reflect.DeepEqual(config.Scrape.Sources, []builder.Source{builder.GitHub}) || config.Scrape.StateDir != defaultStateDir || config.Scrape.MaxWorkers != 5 {
+		t.Errorf("DaemonConfig.Scrape = %+v, want the github source with the scrape defaults", config.Scrape)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"no schedule", nil, "requires --schedule"},
+		{"invalid schedule", []string{"--schedule", "0 3 * *"}, "invalid schedule"},
+		{"schedule never fires", []string{"--schedule", "0 0 30 2 *"}, "never fires"},
+		{"only ids", []string{"--schedule", "@weekly", "--only-ids", "8149"}, "--only-ids"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "daemon"}, tt.args...), "test")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFlags() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFlags_Search(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "search", "damage", "meter", "--source", "github", "--limit", "5"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	config := flags.SearchConfig
+	if config.Query != "damage meter" || config.Catalogue != defaultStateDir || config.Limit != 5 || !reflect.DeepEqual(config.Sources, []types.Source{types.GitHubSource}) {
+		t.Errorf("SearchConfig = %+v, want a search of the state dir for 'damage meter' in github", config)
+	}
+
+	for _, args := range [][]string{{}, {"bagnon", "--limit", "-1"}} {
+		if _, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "search"}, args...), "test"); err == nil {
+			t.Errorf("ParseFlags(search %v) expected an error", args)
+		}
+	}
+}
+
+func TestParseFlags_Show(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "show", "wowinterface", "4815", "--state-dir", "other"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if want := (ShowConfig{Source: types.WowInterfaceSource, SourceID: "4815", StateDir: "other"}); flags.ShowConfig != want {
+		t.Errorf("ShowConfig = %+v, want %+v", flags.ShowConfig, want)
+	}
+
+	for _, args := range [][]string{{}, {"wowinterface"}, {"curseforge", "4815"}, {"wowinterface", "4815", "23145"}} {
+		if _, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "show"}, args...), "test"); err == nil {
+			t.Errorf("ParseFlags(show %v) expected an error", args)
+		}
+	}
+}
+
+func TestParseFlags_Lint(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "lint", "full-catalogue.json", "--threshold", "missing-tags=5", "--threshold", "encoding-error=1%"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	config := flags.LintConfig
+	if config.Path != "full-catalogue.json" || config.Limit != 20 {
+		t.Errorf("LintConfig = %+v, want a lint of full-catalogue.json", config)
+	}
+	want := maps.Clone(lint.DefaultThresholds)
+	want[lint.MissingTags], want[lint.EncodingError] = 5, 1
+	if !reflect.DeepEqual(config.Thresholds, want) {
+		t.Errorf("Thresholds = %v, want %v", config.Thresholds, want)
+	}
+	if lint.DefaultThresholds[lint.MissingTags] == 5 {
+		t.Error("--threshold changed the default thresholds")
+	}
+
+	for _, args := range [][]string{{}, {"a.json", "b.json"}, {"a.json", "--threshold", "spelling=5"}, {"a.json", "--limit", "-1"}} {
+		if _, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "lint"}, args...), "test"); err == nil {
+			t.Errorf("ParseFlags(lint %v) expected an error", args)
+		}
+	}
+}
+
+func TestParseFlags_CheckLinks(t *testing.T) {
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "check-links", "--sample", "0", "--seed", "42", "--source", "wowinterface", "--workers", "2"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	want := CheckLinksConfig{Releases: defaultStateDir, Sources: []types.Source{types.WowInterfaceSource}, Sample: 0, Seed: 42, Interval: 250 * time.Millisecond, MaxWorkers: 2}
+	if !reflect.DeepEqual(flags.CheckLinksConfig, want) {
+		t.Errorf("CheckLinksConfig = %+v, want %+v", flags.CheckLinksConfig, want)
+	}
+
+	for _, args := range [][]string{{"releases.json"}, {"--sample", "-1"}, {"--interval", "-1s"}, {"--source", "curseforge"}} {
+		if _, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "check-links"}, args...), "test"); err == nil {
+			t.Errorf("ParseFlags(check-links %v) expected an error", args)
+		}
+	}
+}
+
+func TestParseFlags_Publish(t *testing.T) {
+	t.Setenv(github.TokenEnvVar, "token")
+	t.Setenv("AWS_REGION", "eu-west-2")
+	flags, err := ParseFlags([]string{"strongbox-catalogue-builder", "publish", "--repo", "ogri-la/strongbox-catalogue"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	want := PublishConfig{StateDir: defaultStateDir, Repo: "ogri-la/strongbox-catalogue", Tag: "catalogue", Token: "token", S3Region: "eu-west-2", CacheControl: "public, max-age=300"}
+	if !reflect.DeepEqual(flags.PublishConfig, want) {
+		t.Errorf("PublishConfig = %+v, want %+v", flags.PublishConfig, want)
+	}
+
+	// Publishing to S3 needs neither a repository nor a GitHub token
+	t.Setenv(github.TokenEnvVar, "")
+	flags, err = ParseFlags([]string{"strongbox-catalogue-builder", "publish", "--target", "s3://catalogues/strongbox"}, "test")
+	if err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+	if flags.PublishConfig.S3URL != "s3://catalogues/strongbox" {
+		t.Errorf("PublishConfig.S3URL = %s, want s3://catalogues/strongbox", flags.PublishConfig.S3URL)
+	}
+	t.Setenv(github.TokenEnvVar, "token")
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"no repo", nil, "requires --repo"},
+		{"repo without an owner", []string{"--repo", "strongbox-catalogue"}, "requires --repo"},
+		{"empty tag", []string{"--repo", "ogri-la/strongbox-catalogue", "--tag", ""}, "--tag"},
+		{"s3 target without a bucket", []string{"--target", "s3://"}, "invalid S3 target"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFlags(append([]string{"strongbox-catalogue-builder", "publish"}, tt.args...), "test")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFlags() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Setenv(github.TokenEnvVar, "")
+	if _, err := ParseFlags([]string{"strongbox-catalogue-builder", "publish", "--repo", "ogri-la/strongbox-catalogue"}, "test"); err == nil || !strings.Contains(err.Error(), "--github-token") {
+		t.Errorf("ParseFlags() without a token error = %v, want it to ask for --github-token", err)
+	}
+}
@@ -0,0 +1,225 @@
+// Package codeberg discovers addons published as Codeberg repositories tagged with a WoW addon topic
+// whose latest release has addon assets. Codeberg runs Forgejo, so this speaks the Gitea API.
+package codeberg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gosimple/slug"
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/normalise"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/release"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+const (
+	// APIURL is the Codeberg (Gitea) REST API
+	APIURL = "https://codeberg.org/api/v1"
+
+	pageSize = 50 // the API's maximum
+)
+
+// Topics mark a repository as a WoW addon
+var Topics = []string{"world-of-warcraft-addon", "wow-addon"}
+
+// Repo is the part of a Codeberg repository used to build an addon
+type Repo struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	FullName    string    `json:"full_name"`
+	Description string    `json:"description"`
+	HTMLURL     string    `json:"html_url"`
+	CreatedAt   time.Time `json:"created_at"`
+	Archived    bool      `json:"archived"`
+}
+
+// searchResult is a page of repository search results
+type searchResult struct {
+	OK   bool   `json:"ok"`
+	Data []Repo `json:"data"`
+}
+
+// Release is the part of a Codeberg release used to build an addon
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []struct {
+		Name               string `json:"name"`
+		DownloadCount      int    `json:"download_count"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// ReleaseAssets returns the release's attachments
+func (r Release) ReleaseAssets() []release.Asset {
+	var assets []release.Asset
+	for _, asset := range r.Assets {
+		assets = append(assets, release.Asset{Name: asset.Name, URL: asset.BrowserDownloadURL, DownloadCount: asset.DownloadCount})
+	}
+	return assets
+}
+
+type Parser struct{}
+
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// searchURL returns a page of the repositories tagged with topic, oldest first so pages are stable
+func searchURL(topic string, page int) string {
+	query := url.Values{
+		"q":     {topic},
+		"topic": {"true"},
+		"sort":  {"created"},
+		"order": {"asc"},
+		"limit": {fmt.Sprint(pageSize)},
+		"page":  {fmt.Sprint(page)},
+	}
+	return APIURL + "/repos/search?" + query.Encode()
+}
+
+// latestReleaseURL returns the repository's most recent release
+func latestReleaseURL(fullName string) string {
+	return APIURL + "/repos/" + fullName + "/releases/latest"
+}
+
+// ParseSearch parses a page of repository search results
+func ParseSearch(data []byte) ([]Repo, error) {
+	var result searchResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Codeberg search results: %w", err)
+	}
+	if !result.OK {
+		return nil, fmt.Errorf("Codeberg search failed")
+	}
+	return result.Data, nil
+}
+
+// ParseRelease parses a release
+func ParseRelease(data []byte) (Release, error) {
+	var latest Release
+	if err := json.Unmarshal(data, &latest); err != nil {
+		return latest, fmt.Errorf("failed to parse Codeberg release: %w", err)
+	}
+	return latest, nil
+}
+
+// ToAddon maps a repository and its latest release to an addon
+func ToAddon(repo Repo, latest Release, gameTracks []types.GameTrack) types.Addon {
+	label := normalise.Text(repo.Name)
+	createdDate := repo.CreatedAt
+	downloadCount := release.DownloadCount(latest.ReleaseAssets())
+	return types.Addon{
+		Archived:      repo.Archived,
+		CreatedDate:   &createdDate,
+		Description:   normalise.Text(repo.Description),
+		DownloadCount: &downloadCount,
+		GameTrackList: gameTracks,
+		Label:         label,
+		Name:          strings.ReplaceAll(slug.Make(label), "_", "-"),
+		Source:        types.CodebergSource,
+		SourceID:      repo.FullName,
+		TagList:       []string{},
+		URL:           repo.HTMLURL,
+		UpdatedDate:   latest.PublishedAt,
+	}
+}
+
+// BuildCatalogue discovers repositories tagged with any of Topics and returns those with an addon release as addons.
+// Requests go through client, and so its cache.
+func (p *Parser) BuildCatalogue(ctx context.Context, client httpclient.HTTPClient) ([]types.Addon, error) {
+	repos, err := p.discoverRepos(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	var addons []types.Addon
+	for _, repo := range repos {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		addon, ok, err := p.buildAddon(ctx, client, repo)
+		if err != nil {
+			slog.Warn("skipping Codeberg repository", "repo", repo.FullName, "error", err)
+			continue
+		}
+		if !ok {
+			slog.Debug("Codeberg repository has no addon release", "repo", repo.FullName)
+			continue
+		}
+		addons = append(addons, addon)
+	}
+	return addons, nil
+}
+
+// discoverRepos returns the repositories tagged with any of Topics, each once
+func (p *Parser) discoverRepos(ctx context.Context, client httpclient.HTTPClient) ([]Repo, error) {
+	var repos []Repo
+	seen := make(map[int]bool)
+
+	for _, topic := range Topics {
+		for page := 1; ; page++ {
+			pageURL := searchURL(topic, page)
+			resp, err := client.Get(ctx, pageURL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to search Codeberg repositories: %w", err)
+			}
+			if resp.StatusCode != 200 {
+				return nil, fmt.Errorf("failed to search Codeberg repositories %s: status %d", pageURL, resp.StatusCode)
+			}
+
+			pageRepos, err := ParseSearch(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			for _, repo := range pageRepos {
+				if !seen[repo.ID] {
+					seen[repo.ID] = true
+					repos = append(repos, repo)
+				}
+			}
+
+			if len(pageRepos) < pageSize {
+				break
+			}
+		}
+	}
+	return repos, nil
+}
+
+// buildAddon returns the repository as an addon, or false if it has no release with addon assets
+func (p *Parser) buildAddon(ctx context.Context, client httpclient.HTTPClient, repo Repo) (types.Addon, bool, error) {
+	resp, err := client.Get(ctx, latestReleaseURL(repo.FullName))
+	if err != nil {
+		return types.Addon{}, false, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	if resp.StatusCode == 404 {
+		return types.Addon{}, false, nil // no releases
+	}
+	if resp.StatusCode != 200 {
+		return types.Addon{}, false, fmt.Errorf("failed to fetch latest release: status %d", resp.StatusCode)
+	}
+
+	latest, err := ParseRelease(resp.Body)
+	if err != nil {
+		return types.Addon{}, false, err
+	}
+
+	assets := latest.ReleaseAssets()
+	if !release.IsAddonRelease(assets) {
+		return types.Addon{}, false, nil
+	}
+
+	gameTracks, err := release.GameTracks(ctx, client, assets)
+	if err != nil {
+		return types.Addon{}, false, err
+	}
+	return ToAddon(repo, latest, gameTracks), true, nil
+}
@@ -0,0 +1,159 @@
+package codeberg
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// search returns a successful search result of repositories with IDs first to last
+func search(first, last int) *httpclient.Response {
+	result := searchResult{OK: true, Data: []Repo{}}
+	for id := first; id <= last; id++ {
+		result.Data = append(result.Data, Repo{ID: id, FullName: "someone/repo"})
+	}
+	body, _ := json.Marshal(result)
+	return &httpclient.Response{StatusCode: 200, Body: body}
+}
+
+func TestBuildCatalogue(t *testing.T) {
+	searchBody, err := os.ReadFile("test/fixtures/search.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	releaseBody, err := os.ReadFile("test/fixtures/release--questlog-plus.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := httpclient.NewMockHTTPClient()
+	client.SetResponse(searchURL(Topics[0], 1), &httpclient.Response{StatusCode: 200, Body: searchBody})
+	client.SetResponse(searchURL(Topics[1], 1), search(1, 0))
+	client.SetResponse(latestReleaseURL("someone/QuestLog_Plus"), &httpclient.Response{StatusCode: 200, Body: releaseBody})
+	client.SetResponse(latestReleaseURL("someone/notes"), &httpclient.Response{StatusCode: 404})
+
+	addons, err := NewParser().BuildCatalogue(context.Background(), client)
+	if err != nil {
+		t.Fatalf("BuildCatalogue() unexpected error: %v", err)
+	}
+	if len(addons) != 1 {
+		t.Fatalf("BuildCatalogue() = %d addons, want 1", len(addons))
+	}
+
+	createdDate := time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)
+	downloadCount := 150
+	want := types.Addon{
+		CreatedDate:   &createdDate,
+		Description:   "A better quest log",
+		DownloadCount: &downloadCount,
+		GameTrackList: []types.GameTrack{types.ClassicTrack, types.RetailTrack},
+		Label:         "QuestLog_Plus",
+		Name:          "questlog-plus",
+		Source:        types.CodebergSource,
+		SourceID:      "someone/QuestLog_Plus",
+		TagList:       []string{},
+		URL:           "https://codeberg.org/someone/QuestLog_Plus",
+		UpdatedDate:   time.Date(2025, 8, 9, 10, 11, 12, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(addons[0], want) {
+		t.Errorf("BuildCatalogue() = %+v, want %+v", addons[0], want)
+	}
+}
+
+func TestDiscoverRepos(t *testing.T) {
+	cases := []struct {
+		name      string
+		responses map[string]*httpclient.Response
+		wantRepos int
+		wantErr   bool
+	}{
+		{
+			name: "short page is the last",
+			responses: map[string]*httpclient.Response{
+				searchURL(Topics[0], 1): search(1, pageSize),
+				searchURL(Topics[0], 2): search(pageSize+1, pageSize+3),
+				searchURL(Topics[1], 1): search(1, 0),
+			},
+			wantRepos: pageSize + 3,
+		},
+		{
+			name: "repos under both topics",
+			responses: map[string]*httpclient.Response{
+				searchURL(Topics[0], 1): search(1, 10),
+				searchURL(Topics[1], 1): search(5, 15),
+			},
+			wantRepos: 15,
+		},
+		{
+			name: "failed page",
+			responses: map[string]*httpclient.Response{
+				searchURL(Topics[0], 1): search(1, pageSize),
+				searchURL(Topics[0], 2): {StatusCode: 500},
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed search",
+			responses: map[string]*httpclient.Response{
+				searchURL(Topics[0], 1): {StatusCode: 200, Body: []byte(`{"ok": false, "data": []}`)},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := httpclient.NewMockHTTPClient()
+			for url, resp := range tc.responses {
+				client.SetResponse(url, resp)
+			}
+
+			repos, err := NewParser().discoverRepos(context.Background(), client)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("discoverRepos() = %d repos, want an error", len(repos))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("discoverRepos() unexpected error: %v", err)
+			}
+			if len(repos) != tc.wantRepos {
+				t.Errorf("discoverRepos() = %d repos, want %d", len(repos), tc.wantRepos)
+			}
+			// Every page fetched was configured, so pagination stopped at the short page
+			if got, want := len(client.GetCalls()), len(tc.responses); got != want {
+				t.Errorf("discoverRepos() fetched %d pages %v, want %d", got, client.GetCalls(), want)
+			}
+		})
+	}
+}
+
+func TestBuildAddon_NoAddonRelease(t *testing.T) {
+	repo := Repo{ID: 1, FullName: "someone/notes"}
+	for name, resp := range map[string]*httpclient.Response{
+		"no releases":     {StatusCode: 404},
+		"no assets":       {StatusCode: 200, Body: []byte(`{"tag_name": "v1", "assets": []}`)},
+		"no addon assets": {StatusCode: 200, Body: []byte(`{"tag_name": "v1", "assets": [{"name": "notes.txt"}]}`)},
+	} {
+		client := httpclient.NewMockHTTPClient()
+		client.SetResponse(latestReleaseURL(repo.FullName), resp)
+
+		_, ok, err := NewParser().buildAddon(context.Background(), client, repo)
+		if err != nil || ok {
+			t.Errorf("buildAddon() with %s = %v, %v, want false, nil", name, ok, err)
+		}
+	}
+
+	// Any other failure is an error, so the repository is skipped with a warning
+	client := httpclient.NewMockHTTPClient()
+	client.SetResponse(latestReleaseURL(repo.FullName), &httpclient.Response{StatusCode: 500})
+	if _, _, err := NewParser().buildAddon(context.Background(), client, repo); err == nil {
+		t.Error("buildAddon() with a failed release, expected an error")
+	}
+}
@@ -0,0 +1,211 @@
+// Package daemon runs scrapes on a schedule in a long-lived process, reporting how they went as Prometheus metrics.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/notify"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// Schedule says when the next scrape is due
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// ScrapeFunc runs a scrape, returning what's known about it however it ends
+type ScrapeFunc func(ctx context.Context) (notify.Summary, error)
+
+// Run scrapes whenever the schedule is due until ctx is cancelled. A scrape still running when the next one is due
+// delays it to the following time the schedule fires. Failed scrapes are logged and recorded, they don't stop the daemon.
+func Run(ctx context.Context, schedule Schedule, scrape ScrapeFunc, metrics *Metrics) error {
+	for {
+		next := schedule.Next(time.Now())
+		if next.IsZero() {
+			return errors.New("schedule never fires")
+		}
+		metrics.scheduled(next)
+		slog.Info("next scrape scheduled", "at", next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		metrics.started()
+		startedAt := time.Now()
+		summary, err := scrape(ctx)
+		if ctx.Err() != nil {
+			slog.Info("scheduled scrape interrupted")
+			return nil
+		}
+		metrics.finished(summary, err, time.Since(startedAt))
+		if err != nil {
+			slog.Error("scheduled scrape failed", "error", err)
+		}
+	}
+}
+
+// Metrics records the scheduled scrapes of a daemon and serves them in the Prometheus text format
+type Metrics struct {
+	mu           sync.Mutex
+	running      bool
+	finishedRuns int
+	failedRuns   int
+	next         time.Time
+	lastRun      time.Time // when the last scrape ended
+	lastSuccess  time.Time
+	lastDuration time.Duration
+	lastFailures int
+	sourceTotals map[types.Source]int // addons in the full catalogue of the last successful scrape
+
+	hostTraces func() map[string]httpclient.HostTrace // optional
+}
+
+// NewMetrics returns metrics of a daemon that hasn't scraped yet
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+// SetHostTraces serves where the requests made over the network spent their time by host, as totalled by traces
+func (m *Metrics) SetHostTraces(traces func() map[string]httpclient.HostTrace) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hostTraces = traces
+}
+
+func (m *Metrics) scheduled(next time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.next = next
+}
+
+func (m *Metrics) started() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running = true
+}
+
+func (m *Metrics) finished(summary notify.Summary, err error, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running = false
+	m.lastRun = time.Now()
+	m.lastDuration = duration
+	m.lastFailures = summary.Failures
+	if err != nil {
+		m.failedRuns++
+		return
+	}
+	m.finishedRuns++
+	m.lastSuccess = m.lastRun
+	m.sourceTotals = summary.SourceTotals
+}
+
+// ServeHTTP implements http.Handler
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	timestamp := func(t time.Time) float64 {
+		if t.IsZero() {
+			return 0
+		}
+		return float64(t.UnixMilli()) / 1000
+	}
+
+	metric("strongbox_scrapes_total", "counter", "Scheduled scrapes run, by how they ended.")
+	fmt.Fprintf(w, "strongbox_scrapes_total{status=%q} %d\n", notify.Finished, m.finishedRuns)
+	fmt.Fprintf(w, "strongbox_scrapes_total{status=%q} %d\n", notify.Failed, m.failedRuns)
+
+	running := 0
+	if m.running {
+		running = 1
+	}
+	metric("strongbox_scrape_running", "gauge", "Whether a scheduled scrape is running.")
+	fmt.Fprintf(w, "strongbox_scrape_running %d\n", running)
+
+	metric("strongbox_next_scrape_timestamp_seconds", "gauge", "When the next scrape is due.")
+	fmt.Fprintf(w, "strongbox_next_scrape_timestamp_seconds %g\n", timestamp(m.next))
+	metric("strongbox_last_scrape_timestamp_seconds", "gauge", "When the last scrape ended, 0 if none has.")
+	fmt.Fprintf(w, "strongbox_last_scrape_timestamp_seconds %g\n", timestamp(m.lastRun))
+	metric("strongbox_last_success_timestamp_seconds", "gauge", "When the last successful scrape ended, 0 if none has.")
+	fmt.Fprintf(w, "strongbox_last_success_timestamp_seconds %g\n", timestamp(m.lastSuccess))
+	metric("strongbox_last_scrape_duration_seconds", "gauge", "How long the last scrape took.")
+	fmt.Fprintf(w, "strongbox_last_scrape_duration_seconds %g\n", m.lastDuration.Seconds())
+	metric("strongbox_last_scrape_failed_urls", "gauge", "URLs the last scrape couldn't fetch or parse.")
+	fmt.Fprintf(w, "strongbox_last_scrape_failed_urls %d\n", m.lastFailures)
+
+	sources := make([]string, 0, len(m.sourceTotals))
+	for source := range m.sourceTotals {
+		sources = append(sources, string(source))
+	}
+	sort.Strings(sources)
+	metric("strongbox_catalogue_addons", "gauge", "Addons in the full catalogue of the last successful scrape, by source.")
+	for _, source := range sources {
+		fmt.Fprintf(w, "strongbox_catalogue_addons{source=%q} %d\n", source, m.sourceTotals[types.Source(source)])
+	}
+
+	if m.hostTraces != nil {
+		writeHostTraces(w, m.hostTraces(), metric)
+	}
+}
+
+// writeHostTraces writes the totals of the requests made to each host since the daemon started
+func writeHostTraces(w http.ResponseWriter, traces map[string]httpclient.HostTrace, metric func(name, kind, help string)) {
+	hosts := make([]string, 0, len(traces))
+	for host := range traces {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	metric("strongbox_http_requests_total", "counter", "Requests made over a connection, by host and whether the connection was reused.")
+	for _, host := range hosts {
+		trace := traces[host]
+		fmt.Fprintf(w, "strongbox_http_requests_total{host=%q,reused=\"true\"} %d\n", host, trace.ReusedConns)
+		fmt.Fprintf(w, "strongbox_http_requests_total{host=%q,reused=\"false\"} %d\n", host, trace.Requests-trace.ReusedConns)
+	}
+
+	phases := []struct {
+		name, help string
+		total      func(httpclient.HostTrace) (time.Duration, int64)
+	}{
+		{"strongbox_http_dns_seconds", "Time spent looking up the addresses of a host.", func(t httpclient.HostTrace) (time.Duration, int64) { return t.DNS, t.DNSLookups }},
+		{"strongbox_http_connect_seconds", "Time spent connecting to a host.", func(t httpclient.HostTrace) (time.Duration, int64) { return t.Connect, t.Connects }},
+		{"strongbox_http_tls_seconds", "Time spent on TLS handshakes with a host.", func(t httpclient.HostTrace) (time.Duration, int64) { return t.TLS, t.TLSHandshakes }},
+		{"strongbox_http_time_to_first_byte_seconds", "Time from asking for a connection to a host to the first byte of its response.", func(t httpclient.HostTrace) (time.Duration, int64) { return t.TimeToFirstByte, t.Responses }},
+	}
+	for _, phase := range phases {
+		metric(phase.name, "summary", phase.help)
+		for _, host := range hosts {
+			total, count := phase.total(traces[host])
+			fmt.Fprintf(w, "%s_sum{host=%q} %g\n", phase.name, host, total.Seconds())
+			fmt.Fprintf(w, "%s_count{host=%q} %d\n", phase.name, host, count)
+		}
+	}
+}
+
+// Handler serves metrics at /metrics and everything else, the catalogues and /healthz, with files
+func Handler(files http.Handler, metrics *Metrics) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	mux.Handle("/", files)
+	return mux
+}
@@ -0,0 +1,141 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/notify"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// soon fires a few milliseconds after any time
+type soon struct{}
+
+func (soon) Next(after time.Time) time.Time {
+	return after.Add(5 * time.Millisecond)
+}
+
+// never doesn't fire
+type never struct{}
+
+func (never) Next(after time.Time) time.Time {
+	return time.Time{}
+}
+
+func scrapeMetrics(t *testing.T, metrics *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+	scrape := func(ctx context.Context) (notify.Summary, error) {
+		runs++
+		switch runs {
+		case 1:
+			return notify.Summary{Total: 3, SourceTotals: map[types.Source]int{types.WowInterfaceSource: 2, types.GitHubSource: 1}}, nil
+		case 2:
+			return notify.Summary{Failures: 4}, errors.New("too many failures")
+		default:
+			cancel()
+			return notify.Summary{}, ctx.Err()
+		}
+	}
+
+	metrics := NewMetrics()
+	if err := Run(ctx, soon{}, scrape, metrics); err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+	if runs != 3 {
+		t.Errorf("Run() scraped %d times, want 3", runs)
+	}
+
+	// The interrupted scrape isn't recorded
+	body := scrapeMetrics(t, metrics)
+	for _, want := range []string{
+		`strongbox_scrapes_total{status="finished"} 1`,
+		`strongbox_scrapes_total{status="failed"} 1`,
+		"strongbox_scrape_running 1",
+		"strongbox_last_scrape_failed_urls 4",
+		`strongbox_catalogue_addons{source="github"} 1`,
+		`strongbox_catalogue_addons{source="wowinterface"} 2`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "strongbox_last_success_timestamp_seconds 0\n") {
+		t.Errorf("metrics have no last success, want the first scrape's:\n%s", body)
+	}
+}
+
+func TestMetrics_HostTraces(t *testing.T) {
+	metrics := NewMetrics()
+	metrics.SetHostTraces(func() map[string]httpclient.HostTrace {
+		return map[string]httpclient.HostTrace{
+			"api.mmoui.com": {Requests: 10, ReusedConns: 8, DNSLookups: 2, DNS: 40 * time.Millisecond, Connects: 2, Connect: time.Second, Responses: 9, TimeToFirstByte: 1500 * time.Millisecond},
+		}
+	})
+
+	body := scrapeMetrics(t, metrics)
+	for _, want := range []string{
+		`strongbox_http_requests_total{host="api.mmoui.com",reused="true"} 8`,
+		`strongbox_http_requests_total{host="api.mmoui.com",reused="false"} 2`,
+		`strongbox_http_dns_seconds_sum{host="api.mmoui.com"} 0.04`,
+		`strongbox_http_dns_seconds_count{host="api.mmoui.com"} 2`,
+		`strongbox_http_connect_seconds_sum{host="api.mmoui.com"} 1`,
+		`strongbox_http_tls_seconds_count{host="api.mmoui.com"} 0`,
+		`strongbox_http_time_to_first_byte_seconds_sum{host="api.mmoui.com"} 1.5`,
+		`strongbox_http_time_to_first_byte_seconds_count{host="api.mmoui.com"} 9`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+
+	if body := scrapeMetrics(t, NewMetrics()); strings.Contains(body, "strongbox_http_") {
+		t.Errorf("metrics without host traces = %s, want no HTTP metrics", body)
+	}
+}
+
+func TestRun_NeverFires(t *testing.T) {
+	scrape := func(ctx context.Context) (notify.Summary, error) {
+		t.Error("scraped on a schedule that never fires")
+		return notify.Summary{}, nil
+	}
+	if err := Run(context.Background(), never{}, scrape, NewMetrics()); err == nil {
+		t.Error("Run() with a schedule that never fires, expected an error")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	files := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "file "+r.URL.Path)
+	})
+	handler := Handler(files, NewMetrics())
+
+	for path, want := range map[string]string{
+		"/metrics":              "strongbox_scrapes_total",
+		"/short-catalogue.json": "file /short-catalogue.json",
+		"/healthz":              "file /healthz",
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("GET %s = %q, want %q", path, rec.Body.String(), want)
+		}
+	}
+}
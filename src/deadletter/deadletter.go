@@ -0,0 +1,130 @@
+// Package deadletter remembers URLs that were missing after being retried, so once they've been missing in several
+// scrapes later scrapes can skip them for a while instead of spending time on addons that have been removed for good.
+package deadletter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultCooldown is how long a dead-lettered URL is skipped for since it last failed
+const DefaultCooldown = 7 * 24 * time.Hour
+
+// MinFailures is how many scrapes a URL must have failed in before it's skipped, so one bad run doesn't drop it
+const MinFailures = 3
+
+// Permanent returns true if a response with statusCode says the URL is gone for good rather than failing for now.
+// Server errors, rate limits and network errors are never permanent, an outage would dead-letter every URL.
+func Permanent(statusCode int) bool {
+	return statusCode == 404 || statusCode == 410
+}
+
+// Entry is a URL that was missing after being retried
+type Entry struct {
+	URL         string    `json:"url"`
+	StatusCode  int       `json:"status-code,omitempty"` // last status, 0 for a network error
+	Error       string    `json:"error"`                 // last error
+	Failures    int       `json:"failures"`              // runs the URL has failed in
+	FirstFailed time.Time `json:"first-failed"`
+	LastFailed  time.Time `json:"last-failed"`
+}
+
+// Queue is the set of dead-lettered URLs. Safe for concurrent use.
+type Queue struct {
+	mu       sync.Mutex
+	entries  map[string]Entry // url -> entry
+	cooldown time.Duration
+}
+
+// NewQueue creates an empty queue skipping URLs for cooldown after they last failed
+func NewQueue(cooldown time.Duration) *Queue {
+	return &Queue{entries: make(map[string]Entry), cooldown: cooldown}
+}
+
+// Read reads the queue written to path by Write. A missing file is an empty queue.
+func Read(path string, cooldown time.Duration) (*Queue, error) {
+	q := NewQueue(cooldown)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letters %s: %w", path, err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse dead letters %s: %w", path, err)
+	}
+	for _, entry := range entries {
+		q.entries[entry.URL] = entry
+	}
+	return q, nil
+}
+
+// Add records url failing at with statusCode, or with err. Called at most once per URL a scrape, so Failures counts
+// the scrapes it failed in.
+func (q *Queue) Add(url string, statusCode int, err error, at time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, ok := q.entries[url]
+	if !ok {
+		entry = Entry{URL: url, FirstFailed: at}
+	}
+	entry.StatusCode = statusCode
+	entry.Error = fmt.Sprintf("status %d", statusCode)
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	entry.Failures++
+	entry.LastFailed = at
+	q.entries[url] = entry
+}
+
+// Remove forgets url, it has been fetched successfully
+func (q *Queue) Remove(url string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.entries, url)
+}
+
+// Skip returns true if url failed permanently in at least MinFailures scrapes, the last within the cooldown before now
+func (q *Queue) Skip(url string, now time.Time) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entry, ok := q.entries[url]
+	return ok && Permanent(entry.StatusCode) && entry.Failures >= MinFailures && now.Sub(entry.LastFailed) < q.cooldown
+}
+
+// Entries returns every dead-lettered URL, sorted by URL
+func (q *Queue) Entries() []Entry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries := make([]Entry, 0, len(q.entries))
+	for _, entry := range q.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].URL < entries[j].URL
+	})
+	return entries
+}
+
+// Write writes the queue to path as indented JSON
+func (q *Queue) Write(path string) error {
+	data, err := json.MarshalIndent(q.Entries(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letters: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write dead letters to %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package deadletter
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQueue_Skip(t *testing.T) {
+	q := NewQueue(24 * time.Hour)
+	failed := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := range MinFailures {
+		q.Add("https://example.org/gone", 404, nil, failed.Add(time.Duration(i-MinFailures+1)*24*time.Hour))
+		q.Add("https://example.org/down", 503, nil, failed)
+		q.Add("https://example.org/reset", 0, errors.New("connection reset"), failed)
+	}
+	q.Add("https://example.org/missing-once", 410, nil, failed)
+
+	tests := []struct {
+		url  string
+		now  time.Time
+		want bool
+	}{
+		{"https://example.org/gone", failed.Add(time.Hour), true},
+		{"https://example.org/gone", failed.Add(24 * time.Hour), false}, // cooled down, worth another try
+		{"https://example.org/down", failed.Add(time.Hour), false},      // server errors aren't permanent
+		{"https://example.org/reset", failed.Add(time.Hour), false},     // nor are network errors
+		{"https://example.org/missing-once", failed.Add(time.Hour), false},
+		{"https://example.org/fine", failed.Add(time.Hour), false},
+	}
+	for _, tt := range tests {
+		if got := q.Skip(tt.url, tt.now); got != tt.want {
+			t.Errorf("Skip(%s, %v) = %v, want %v", tt.url, tt.now, got, tt.want)
+		}
+	}
+
+	q.Remove("https://example.org/gone")
+	if q.Skip("https://example.org/gone", failed) {
+		t.Error("Skip() = true after Remove()")
+	}
+}
+
+func TestQueue_WriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-letters.json")
+
+	// Missing file
+	q, err := Read(path, DefaultCooldown)
+	if err != nil {
+		t.Fatalf("Read() of a missing file unexpected error: %v", err)
+	}
+	if len(q.Entries()) != 0 {
+		t.Errorf("Read() of a missing file = %v, want no entries", q.Entries())
+	}
+
+	first := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	second := first.Add(7 * 24 * time.Hour)
+	q.Add("https://example.org/b", 0, errors.New("connection reset"), first)
+	q.Add("https://example.org/a", 404, nil, first)
+	q.Add("https://example.org/a", 410, nil, second)
+	if err := q.Write(path); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+
+	read, err := Read(path, DefaultCooldown)
+	if err != nil {
+		t.Fatalf("Read() unexpected error: %v", err)
+	}
+	want := []Entry{
+		{URL: "https://example.org/a", StatusCode: 410, Error: "status 410", Failures: 2, FirstFailed: first, LastFailed: second},
+		{URL: "https://example.org/b", Error: "connection reset", Failures: 1, FirstFailed: first, LastFailed: first},
+	}
+	if got := read.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %+v, want %+v", got, want)
+	}
+}
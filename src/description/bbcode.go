@@ -0,0 +1,44 @@
+package description
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	bbListItem = regexp.MustCompile(`\[\*\]\s*`)
+	bbTag      = regexp.MustCompile(`(?i)\[/?(b|i|u|s|size|color|font|url|email|img|list|quote|code|center|left|right|indent|highlight)(=[^\]]*)?\]`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// MaxChangelogLength is the longest changelog kept, in bytes. Some addons paste their entire history.
+const MaxChangelogLength = 5000
+
+// StripBBCode reduces BBCode (as used by WoWInterface) to plain text.
+// Tags are removed keeping their content, so links keep their text and list items become "- item" lines.
+func StripBBCode(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = bbListItem.ReplaceAllString(text, "- ")
+	return bbTag.ReplaceAllString(text, "")
+}
+
+// Changelog tidies changelog text: trailing whitespace and runs of blank lines are removed
+// and the text is cut to MaxChangelogLength without splitting a character.
+func Changelog(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	text = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	text = strings.TrimSpace(text)
+
+	if len(text) > MaxChangelogLength {
+		cut := MaxChangelogLength
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = strings.TrimSpace(text[:cut])
+	}
+	return text
+}
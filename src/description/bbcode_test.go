@@ -0,0 +1,60 @@
+package description
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestStripBBCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"size removed", "[size=5]Better Vendor Price[/size]", "Better Vendor Price"},
+		{"link keeps text", "[url=https://example.org]v1.2[/url] (2025-08-06)", "v1.2 (2025-08-06)"},
+		{"list items", "[list]\r\n[*]first\r\n[*]second\r\n[/list]", "\n- first\n- second\n"},
+		{"emphasis removed", "[b]bold[/b] and [I]italic[/I]", "bold and italic"},
+		{"other brackets kept", "fixed [combat] errors", "fixed [combat] errors"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripBBCode(tt.input); got != tt.expected {
+				t.Errorf("StripBBCode(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestChangelog(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"empty", "  \n ", ""},
+		{"trimmed", "\n v1.1  \r\n- fix  \n", "v1.1\n- fix"},
+		{"blank lines collapsed", "v1.1\n\n\n\nv1.0", "v1.1\n\nv1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Changelog(tt.input); got != tt.expected {
+				t.Errorf("Changelog(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestChangelog_Truncated(t *testing.T) {
+	long := strings.Repeat("é", MaxChangelogLength)
+	got := Changelog(long)
+	if len(got) > MaxChangelogLength {
+		t.Errorf("len(Changelog) = %d, want <= %d", len(got), MaxChangelogLength)
+	}
+	if !strings.HasPrefix(long, got) || !utf8.ValidString(got) {
+		t.Errorf("Changelog split a character")
+	}
+}
@@ -0,0 +1,217 @@
+// Package description extracts short, human readable addon descriptions from
+// longer free-form text such as WowInterface descriptions and READMEs.
+package description
+
+import (
+	"strings"
+)
+
+// Clean processes description text to extract a meaningful first line.
+// Matches Clojure implementation: splits into lines, removes decorative lines,
+// skips common leading header words, returns first high-quality line.
+// Falls back to first non-decorative line if no high-quality line found.
+func Clean(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	// Split into lines
+	lines := strings.Split(text, "\n")
+
+	// First pass: find a high-quality description line
+	var fallback string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+
+		// Skip empty lines
+		if line == "" {
+			continue
+		}
+
+		// Skip decorative lines (matches Clojure's pure-non-alpha-numeric?)
+		if IsPureNonAlphanumeric(line) {
+			continue
+		}
+
+		// Skip common leading header words that add no value
+		if ShouldSkipLeadingLine(line) {
+			continue
+		}
+
+		// Remember first non-decorative line as fallback
+		if fallback == "" {
+			fallback = line
+		}
+
+		// Skip low-quality descriptions (version numbers, single words, etc.)
+		if IsLowQuality(line) {
+			continue
+		}
+
+		// Found a good quality line - limit to reasonable length
+		const maxLength = 1000
+		if len(line) > maxLength {
+			return line[:maxLength]
+		}
+		return line
+	}
+
+	// No high-quality line found, use fallback (something is better than nothing)
+	// BUT: don't use fallback if it's a known junk word
+	if fallback != "" {
+		fallbackLower := strings.ToLower(fallback)
+		// Don't return known junk as description
+		junkWords := []string{"null", "undefined", "n/a", "none", "unknown"}
+		isJunk := false
+		for _, junk := range junkWords {
+			if fallbackLower == junk {
+				isJunk = true
+				break
+			}
+		}
+
+		if !isJunk {
+			const maxLength = 1000
+			if len(fallback) > maxLength {
+				return fallback[:maxLength]
+			}
+			return fallback
+		}
+	}
+
+	return ""
+}
+
+// IsLowQuality returns true if the description is too short,
+// contains only version numbers, dates, or other non-descriptive content.
+func IsLowQuality(s string) bool {
+	// Minimum length threshold
+	if len(s) < 15 {
+		return true
+	}
+
+	// Must contain at least one space (multiple words)
+	if !strings.Contains(s, " ") {
+		return true
+	}
+
+	lower := strings.ToLower(s)
+
+	// Exact match low-quality words (these should never be returned in Go)
+	exactBadWords := []string{
+		"null", "undefined", "n/a", "none", "unknown",
+	}
+	for _, word := range exactBadWords {
+		if lower == word {
+			return true
+		}
+	}
+
+	// Check for "AddonName by AuthorName" pattern (common lazy description)
+	// e.g., "BigWigs by Funkydude"
+	if strings.Contains(lower, " by ") {
+		// Typically 3 words: "Name by Author"
+		words := strings.Fields(s)
+		if len(words) == 3 {
+			return true
+		}
+	}
+
+	// Prefix-based low-quality patterns
+	lowQualityPrefixes := []string{
+		"update:", "updated:", "new:", "news:",
+	}
+	for _, pattern := range lowQualityPrefixes {
+		if strings.HasPrefix(lower, pattern) {
+			return true
+		}
+	}
+
+	// Check if it starts with version number patterns
+	// e.g., "1.0", "10.1.5 UPDATE:", "0.8.2", "v1.2.3"
+	if len(s) > 0 && (s[0] >= '0' && s[0] <= '9' || s[0] == 'v' || s[0] == 'V') {
+		// Simple version pattern: starts with digit or v, contains dots
+		if strings.Contains(s[:min(10, len(s))], ".") {
+			return true
+		}
+	}
+
+	// Check for date patterns: MM/DD/YYYY or YYYY-MM-DD
+	if len(s) >= 10 {
+		prefix := s[:10]
+		// MM/DD/YYYY
+		if len(prefix) == 10 && prefix[2] == '/' && prefix[5] == '/' {
+			return true
+		}
+		// YYYY-MM-DD
+		if len(prefix) == 10 && prefix[4] == '-' && prefix[7] == '-' {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsPureNonAlphanumeric returns true if string contains only non-alphanumeric characters.
+// Matches Clojure's pure-non-alpha-numeric? function with regex ^[\W_]*$
+func IsPureNonAlphanumeric(s string) bool {
+	if s == "" {
+		return true
+	}
+	// \W matches non-word characters (opposite of \w which is [a-zA-Z0-9_])
+	// So [\W_] matches anything that's not alphanumeric
+	for _, r := range s {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// ShouldSkipLeadingLine returns true if the line starts with common header words
+// that add no value (user's TODO list of words to filter).
+func ShouldSkipLeadingLine(line string) bool {
+	lower := strings.ToLower(line)
+
+	// List of prefixes to skip (from user's TODO)
+	skipPrefixes := []string{
+		// Heading words
+		"about", "description", "general description", "general", "what", "info",
+		"information", "credits", "features", "intro", "introduction", "note",
+		"overview", "preamble", "purpose", "synopsis", "summary",
+
+		// Donation/support
+		"donate", "donation", "paypal", "support", "patreon",
+
+		// Meta/status words
+		"discontinued", "important", "news", "update", "updated", "urgent", "warning",
+
+		// Locale
+		"english", "engb", "enus",
+
+		// Greetings
+		"hello", "hey", "hi",
+
+		// Special phrases
+		"special thanks", "special note",
+		"what is it", "what does it do", "what is", "what it is", "what's this",
+	}
+
+	for _, prefix := range skipPrefixes {
+		// Check if line starts with prefix (possibly followed by punctuation/whitespace)
+		if strings.HasPrefix(lower, prefix) {
+			// Make sure it's actually a prefix, not part of a word
+			// e.g., "about this addon" should match, "aboutface" should not
+			if len(line) == len(prefix) {
+				return true
+			}
+			nextChar := lower[len(prefix)]
+			// Allow any non-alphanumeric character after prefix
+			if !((nextChar >= 'a' && nextChar <= 'z') || (nextChar >= '0' && nextChar <= '9')) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
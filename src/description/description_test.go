@@ -0,0 +1,366 @@
+package description
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Empty string",
+			input:    "",
+			expected: "",
+		},
+		{
+			name:     "Simple description",
+			input:    "A simple addon that does cool things.",
+			expected: "A simple addon that does cool things.",
+		},
+		{
+			name:     "Multi-line with decorative separator",
+			input:    "==========\nThis addon helps you.\nMore details here.",
+			expected: "This addon helps you.",
+		},
+		{
+			name:     "Skip 'About' prefix",
+			input:    "About: This is an awesome addon.\nIt does things.",
+			expected: "It does things.",
+		},
+		{
+			name:     "Skip 'Description' prefix",
+			input:    "Description\nHelps manage your inventory.",
+			expected: "Helps manage your inventory.",
+		},
+		{
+			name:     "Skip 'What is it' phrase",
+			input:    "What is it?\nA utility addon for tracking quests.",
+			expected: "A utility addon for tracking quests.",
+		},
+		{
+			name:     "Skip donation message",
+			input:    "Donate via PayPal!\nThis addon improves your UI.",
+			expected: "This addon improves your UI.",
+		},
+		{
+			name:     "Skip greeting",
+			input:    "Hello!\nWelcome to my addon.",
+			expected: "Welcome to my addon.",
+		},
+		{
+			name:     "Skip 'Discontinued' warning",
+			input:    "Discontinued: No longer maintained\nShows damage meters.",
+			expected: "Shows damage meters.",
+		},
+		{
+			name:     "Skip multiple leading lines",
+			input:    "===\nIntro:\nHello there!\nThis addon tracks achievements.",
+			expected: "This addon tracks achievements.",
+		},
+		{
+			name:     "Truncate long description",
+			input:    strings.Repeat("A", 1500),
+			expected: strings.Repeat("A", 1000),
+		},
+		{
+			name:     "Skip 'Overview:' line",
+			input:    "Overview:\nManage your bags efficiently.",
+			expected: "Manage your bags efficiently.",
+		},
+		{
+			name:     "Don't skip 'aboutface' (not a prefix match)",
+			input:    "aboutface is a military command.",
+			expected: "aboutface is a military command.",
+		},
+		{
+			name:     "All lines are decorative",
+			input:    "===\n---\n***",
+			expected: "",
+		},
+		{
+			name:     "Skip 'Important' message",
+			input:    "Important: Read the documentation\nProvides DPS tracking.",
+			expected: "Provides DPS tracking.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Clean(tt.input)
+			if result != tt.expected {
+				t.Errorf("Clean() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsPureNonAlphanumeric(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "Empty string",
+			input:    "",
+			expected: true,
+		},
+		{
+			name:     "Only equals signs",
+			input:    "========",
+			expected: true,
+		},
+		{
+			name:     "Only dashes",
+			input:    "--------",
+			expected: true,
+		},
+		{
+			name:     "Mixed punctuation",
+			input:    "=== *** ---",
+			expected: true,
+		},
+		{
+			name:     "Contains letters",
+			input:    "=== text ===",
+			expected: false,
+		},
+		{
+			name:     "Contains numbers",
+			input:    "--- 123 ---",
+			expected: false,
+		},
+		{
+			name:     "Single letter",
+			input:    "a",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := IsPureNonAlphanumeric(tt.input)
+			if result != tt.expected {
+				t.Errorf("IsPureNonAlphanumeric(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsLowQuality(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "Too short - single word",
+			input:    "null",
+			expected: true,
+		},
+		{
+			name:     "Too short - few chars",
+			input:    "1.3",
+			expected: true,
+		},
+		{
+			name:     "Version number with update",
+			input:    "10.1.5 UPDATE:",
+			expected: true,
+		},
+		{
+			name:     "Version number simple",
+			input:    "1.0 release",
+			expected: true,
+		},
+		{
+			name:     "Version with v prefix",
+			input:    "v1.2.3 fixes",
+			expected: true,
+		},
+		{
+			name:     "Date MM/DD/YYYY",
+			input:    "05/03/2025 - Updated",
+			expected: true,
+		},
+		{
+			name:     "Date YYYY-MM-DD",
+			input:    "2025-05-03 - New version",
+			expected: true,
+		},
+		{
+			name:     "Null exact match",
+			input:    "null",
+			expected: true,
+		},
+		{
+			name:     "Update prefix",
+			input:    "UPDATE: Fixed bugs",
+			expected: true,
+		},
+		{
+			name:     "No spaces (single word)",
+			input:    "SomeLongSingleWord",
+			expected: true,
+		},
+		{
+			name:     "Good quality - normal description",
+			input:    "This addon helps you manage your inventory efficiently.",
+			expected: false,
+		},
+		{
+			name:     "Good quality - minimum length with spaces",
+			input:    "Tracks your DPS",
+			expected: false,
+		},
+		{
+			name:     "Good quality - sentence",
+			input:    "A simple raid frame addon.",
+			expected: false,
+		},
+		{
+			name:     "Number in middle is ok",
+			input:    "This is version 2.0 of the addon.",
+			expected: false,
+		},
+		{
+			name:     "AddonName by AuthorName pattern",
+			input:    "BigWigs by Funkydude",
+			expected: true,
+		},
+		{
+			name:     "'by' in longer description is ok",
+			input:    "This addon was created by the author to solve problems.",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := IsLowQuality(tt.input)
+			if result != tt.expected {
+				t.Errorf("IsLowQuality(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCleanWithQualityFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Skip version, use next line",
+			input:    "1.3\nThis addon provides raid utilities.",
+			expected: "This addon provides raid utilities.",
+		},
+		{
+			name:     "Skip multiple bad lines",
+			input:    "null\n10.1.5 UPDATE:\nManages your action bars effectively.",
+			expected: "Manages your action bars effectively.",
+		},
+		{
+			name:     "Skip date, use description",
+			input:    "05/03/2025 - V3.1\nProvides enhanced tooltips.",
+			expected: "Provides enhanced tooltips.",
+		},
+		{
+			name:     "Fallback to short line if nothing better",
+			input:    "hud",
+			expected: "hud",
+		},
+		{
+			name:     "Don't use 'null' as fallback",
+			input:    "null",
+			expected: "",
+		},
+		{
+			name:     "Don't use 'undefined' as fallback",
+			input:    "===\nundefined",
+			expected: "",
+		},
+		{
+			name:     "Use good line even if bad lines exist later",
+			input:    "Tracks gathering nodes on your map.\n1.0\nv2.3",
+			expected: "Tracks gathering nodes on your map.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Clean(tt.input)
+			if result != tt.expected {
+				t.Errorf("Clean() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShouldSkipLeadingLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "About prefix",
+			input:    "About this addon",
+			expected: true,
+		},
+		{
+			name:     "Description prefix",
+			input:    "Description: Tracks DPS",
+			expected: true,
+		},
+		{
+			name:     "Donate message",
+			input:    "Donate to support development",
+			expected: true,
+		},
+		{
+			name:     "Hello greeting",
+			input:    "Hello, welcome!",
+			expected: true,
+		},
+		{
+			name:     "What is it question",
+			input:    "What is it?",
+			expected: true,
+		},
+		{
+			name:     "Discontinued notice",
+			input:    "Discontinued - no updates",
+			expected: true,
+		},
+		{
+			name:     "Not a prefix (word boundary)",
+			input:    "aboutface command",
+			expected: false,
+		},
+		{
+			name:     "Normal content",
+			input:    "This addon helps you manage inventory",
+			expected: false,
+		},
+		{
+			name:     "Contains but doesn't start with skip word",
+			input:    "An overview of features",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ShouldSkipLeadingLine(tt.input)
+			if result != tt.expected {
+				t.Errorf("ShouldSkipLeadingLine(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package description
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	mdFencedCode = regexp.MustCompile("(?s)```.*?```")
+	mdHTMLTag    = regexp.MustCompile(`<[^>]+>`)
+	mdImage      = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	mdLink       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	mdRefLinkDef = regexp.MustCompile(`(?m)^\s*\[[^\]]+\]:\s*\S+.*$`)
+	mdHeading    = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}(?:\s.*)?$`)
+	mdSetextRule = regexp.MustCompile(`(?m)^\s*[=-]{3,}\s*$`)
+	mdListMarker = regexp.MustCompile(`(?m)^\s*(?:[-*+]|\d+\.)\s+`)
+	mdBlockquote = regexp.MustCompile(`(?m)^\s*>\s?`)
+	mdEmphasis   = regexp.MustCompile(`(\*\*|__|\*|_|~~|` + "`" + `)([^*_~` + "`" + `\n]+?)(\*\*|__|\*|_|~~|` + "`" + `)`)
+)
+
+// StripMarkdown reduces Markdown (such as a README) to plain text lines suitable for Clean.
+// Headings, images, badges, code blocks and HTML are dropped (a README's first heading is
+// usually just the addon name), links keep their text and list and emphasis markers are removed.
+func StripMarkdown(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = mdFencedCode.ReplaceAllString(text, "")
+	text = mdHTMLTag.ReplaceAllString(text, "")
+	text = mdImage.ReplaceAllString(text, "") // before links, badges are usually linked images
+	text = mdLink.ReplaceAllString(text, "$1")
+	text = mdRefLinkDef.ReplaceAllString(text, "")
+	text = mdSetextRule.ReplaceAllString(text, "")
+	text = mdHeading.ReplaceAllString(text, "")
+	text = mdListMarker.ReplaceAllString(text, "")
+	text = mdBlockquote.ReplaceAllString(text, "")
+	text = mdEmphasis.ReplaceAllString(text, "$2")
+	return text
+}
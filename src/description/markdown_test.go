@@ -0,0 +1,45 @@
+package description
+
+import "testing"
+
+func TestStripMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"heading dropped", "# MyAddon\nDoes things", "\nDoes things"},
+		{"link keeps text", "See [the wiki](https://example.org) for help", "See the wiki for help"},
+		{"image dropped", "![screenshot](shot.png)", ""},
+		{"badge dropped", "[![CI](https://example.org/ci.svg)](https://example.org/ci)", ""},
+		{"emphasis removed", "A **bold** and _quiet_ `addon`", "A bold and quiet addon"},
+		{"list marker removed", "- first item", "first item"},
+		{"html removed", "<p align=\"center\">Centered</p>", "Centered"},
+		{"code block removed", "```lua\nprint('hi')\n```", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripMarkdown(tt.input); got != tt.expected {
+				t.Errorf("StripMarkdown(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClean_Readme(t *testing.T) {
+	readme := `# Questie
+
+[![Downloads](https://img.shields.io/badge/downloads-1M-blue)](https://example.org)
+
+## About
+
+The **ultimate** WoW Classic quest helper, showing quests on your map.
+
+## Installation
+`
+	expected := "The ultimate WoW Classic quest helper, showing quests on your map."
+	if got := Clean(StripMarkdown(readme)); got != expected {
+		t.Errorf("Clean(StripMarkdown(readme)) = %q, want %q", got, expected)
+	}
+}
@@ -0,0 +1,61 @@
+package description
+
+import (
+	"strings"
+)
+
+// MaxSummaryLength is the length a summary grows to, in bytes. The first line is kept whole however long it is.
+const MaxSummaryLength = 300
+
+// Mode is how a description is taken from longer text
+type Mode int
+
+const (
+	FirstLineMode Mode = iota // the first good line, see Clean
+	SummaryMode               // the first good lines of the first paragraph, see Summarise
+)
+
+// Extract returns the description of text in mode
+func Extract(text string, mode Mode) string {
+	if mode == SummaryMode {
+		return Summarise(text)
+	}
+	return Clean(text)
+}
+
+// Summarise extracts a description of a few sentences from text that may contain BBCode or Markdown.
+// Starting from the line Clean would pick, the lines following it in the same paragraph are joined on
+// while they are good quality and the summary stays within MaxSummaryLength.
+// Falls back to Clean when no good line is found.
+func Summarise(text string) string {
+	text = StripMarkdown(StripBBCode(text))
+	first := Clean(text)
+	if first == "" {
+		return ""
+	}
+
+	lines := strings.Split(text, "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == first {
+			start = i
+			break
+		}
+	}
+	if start == -1 || IsLowQuality(first) {
+		return first // truncated, or the fallback
+	}
+
+	summary := first
+	for _, line := range lines[start+1:] {
+		line = strings.TrimSpace(line)
+		if line == "" || IsPureNonAlphanumeric(line) || IsLowQuality(line) {
+			break // end of the paragraph, or of the prose
+		}
+		if len(summary)+1+len(line) > MaxSummaryLength {
+			break
+		}
+		summary += " " + line
+	}
+	return summary
+}
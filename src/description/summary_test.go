@@ -0,0 +1,63 @@
+package description
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummarise(t *testing.T) {
+	long := strings.Repeat("This sentence keeps going on about the addon. ", 6)
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"empty", "", ""},
+		{"single line", "Tracks your reputation gains across all characters.", "Tracks your reputation gains across all characters."},
+		{
+			"joins the first paragraph",
+			"Features:\nTracks your reputation gains across all characters.\nShows them in a sortable window.\n\nType /rep to open it.",
+			"Tracks your reputation gains across all characters. Shows them in a sortable window.",
+		},
+		{
+			"stops at a low quality line",
+			"Tracks your reputation gains across all characters.\nv1.2.3\nShows them in a sortable window.",
+			"Tracks your reputation gains across all characters.",
+		},
+		{
+			"stays within the maximum length",
+			"Tracks your reputation gains across all characters.\n" + long,
+			"Tracks your reputation gains across all characters.",
+		},
+		{
+			"strips BBCode",
+			"[SIZE=\"4\"][B]Better Vendor Price[/B] shows vendor prices in tooltips[/SIZE]\n[COLOR=red]Works in Classic and Retail.[/COLOR]",
+			"Better Vendor Price shows vendor prices in tooltips Works in Classic and Retail.",
+		},
+		{
+			"strips Markdown",
+			"# Reputation\n\nTracks your **reputation** gains across all characters.\n- Shows them in a [sortable](https://example.org) window.",
+			"Tracks your reputation gains across all characters. Shows them in a sortable window.",
+		},
+		{"falls back to the first line", "v1.2.3", "v1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Summarise(tt.text); got != tt.want {
+				t.Errorf("Summarise() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	text := "Tracks your reputation gains across all characters.\nShows them in a sortable window."
+	if got := Extract(text, FirstLineMode); got != "Tracks your reputation gains across all characters." {
+		t.Errorf("Extract(FirstLineMode) = %q", got)
+	}
+	if got := Extract(text, SummaryMode); got != "Tracks your reputation gains across all characters. Shows them in a sortable window." {
+		t.Errorf("Extract(SummaryMode) = %q", got)
+	}
+}
@@ -0,0 +1,100 @@
+// Package diagnostics serves net/http/pprof and logs runtime statistics, to see what a long scrape that seems to have
+// stalled is doing: where its goroutines are blocked, how much memory it holds and how often it collects garbage.
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// LogInterval is how often runtime statistics are logged
+const LogInterval = time.Minute
+
+// Handler returns a handler serving the net/http/pprof profiles under /debug/pprof/.
+// Registered on its own mux, so importing net/http/pprof doesn't expose them on any other server.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// Start serves Handler on addr and logs runtime statistics every interval, in the background until ctx is done.
+// Returns the address served, the port chosen if addr's is 0, or an error if addr can't be listened on.
+func Start(ctx context.Context, addr string, interval time.Duration) (net.Addr, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	srv := &http.Server{Handler: Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Warn("pprof server stopped", "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	go logStats(ctx, interval)
+
+	slog.Info("serving pprof", "url", "http://"+listener.Addr().String()+"/debug/pprof/", "stats-interval", interval)
+	return listener.Addr(), nil
+}
+
+// Stats are the runtime statistics logged
+type Stats struct {
+	Goroutines    int
+	HeapAlloc     uint64        // bytes of live and not yet collected heap objects
+	HeapSys       uint64        // bytes of heap obtained from the OS
+	NumGC         uint32        // completed collections
+	GCPauseTotal  time.Duration // stopping the world for all collections
+	LastGCPause   time.Duration
+	GCCPUFraction float64 // of the CPU time available used by the collector since the program started
+}
+
+// ReadStats returns the current runtime statistics. Briefly stops the world, like runtime.ReadMemStats.
+func ReadStats() Stats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	stats := Stats{
+		Goroutines:    runtime.NumGoroutine(),
+		HeapAlloc:     mem.HeapAlloc,
+		HeapSys:       mem.HeapSys,
+		NumGC:         mem.NumGC,
+		GCPauseTotal:  time.Duration(mem.PauseTotalNs),
+		GCCPUFraction: mem.GCCPUFraction,
+	}
+	if mem.NumGC > 0 {
+		stats.LastGCPause = time.Duration(mem.PauseNs[(mem.NumGC+255)%256])
+	}
+	return stats
+}
+
+// logStats logs ReadStats every interval until ctx is done
+func logStats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			stats := ReadStats()
+			slog.Info("runtime statistics", "goroutines", stats.Goroutines,
+				"heap-alloc-bytes", stats.HeapAlloc, "heap-sys-bytes", stats.HeapSys,
+				"gc-count", stats.NumGC, "gc-pause-total", stats.GCPauseTotal, "gc-last-pause", stats.LastGCPause,
+				"gc-cpu-fraction", fmt.Sprintf("%.4f", stats.GCCPUFraction))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package diagnostics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr, err := Start(ctx, "127.0.0.1:0", time.Hour)
+	if err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+	resp, err := http.Get("http://" + addr.String() + "/debug/pprof/goroutine?debug=1")
+	if err != nil {
+		t.Fatalf("GET goroutine profile unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "goroutine profile:") {
+		t.Errorf("goroutine profile = %d %.100s, want the profile", resp.StatusCode, body)
+	}
+
+	if _, err := Start(ctx, addr.String(), time.Hour); err == nil {
+		t.Error("Start() on an address in use, expected an error")
+	}
+
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := http.Get("http://" + addr.String() + "/debug/pprof/"); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("pprof still served after its context was cancelled")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReadStats(t *testing.T) {
+	runtime.GC()
+	stats := ReadStats()
+	if stats.Goroutines < 1 || stats.HeapSys == 0 || stats.NumGC < 1 {
+		t.Errorf("ReadStats() = %+v, want goroutines, heap and a collection", stats)
+	}
+}
@@ -0,0 +1,306 @@
+// Package gate decides whether a catalogue is fit to publish.
+//
+// The gate runs schema validation, stricter consistency checks and anomaly detection against
+// the previously published catalogue, and records a single pass/fail verdict in the run metadata.
+// Commands that ship catalogues call RequirePassed before doing so.
+package gate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/catalogue"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/validation"
+)
+
+// ErrNotPassed is returned by RequirePassed when a catalogue hasn't passed the gate
+var ErrNotPassed = errors.New("catalogue has not passed the publish gate")
+
+// Check names
+const (
+	ValidationCheck = "validation"
+	StrictCheck     = "strict"
+	AnomalyCheck    = "anomaly"
+)
+
+// Thresholds bound how much a catalogue may shrink between runs before it's considered anomalous.
+// Values are fractions of the previous catalogue, e.g. 0.1 is 10%.
+type Thresholds struct {
+	MaxTotalShrink  float64 // overall addon count
+	MaxSourceShrink float64 // addon count of any single source
+	MaxRemoved      float64 // addons present previously but missing now
+}
+
+// DefaultThresholds returns thresholds loose enough for normal churn but that catch a broken scrape
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		MaxTotalShrink:  0.10,
+		MaxSourceShrink: 0.25,
+		MaxRemoved:      0.05,
+	}
+}
+
+// CheckResult is the outcome of a single gate check
+type CheckResult struct {
+	Name     string   `json:"name"`
+	Passed   bool     `json:"passed"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Verdict is the outcome of the gate for one catalogue
+type Verdict struct {
+	Passed    bool          `json:"passed"`
+	Catalogue string        `json:"catalogue"`
+	ETag      string        `json:"etag"` // see catalogue.CatalogueETag
+	CheckedAt time.Time     `json:"checked-at"`
+	CheckList []CheckResult `json:"check-list"`
+}
+
+// Failures returns the names of the checks that failed
+func (v Verdict) Failures() []string {
+	var failed []string
+	for _, check := range v.CheckList {
+		if !check.Passed {
+			failed = append(failed, check.Name)
+		}
+	}
+	return failed
+}
+
+// Scope is the part of the WowInterface file list a partial scrape was limited to
+type Scope struct {
+	Limit      int      `json:"limit,omitempty"`      // the first this many addons, 0 for all
+	Categories []string `json:"categories,omitempty"` // addons in these categories, all if empty
+}
+
+// String returns the scope as the scrape options giving it
+func (s Scope) String() string {
+	var options []string
+	if s.Limit > 0 {
+		options = append(options, fmt.Sprintf("--limit %d", s.Limit))
+	}
+	for _, category := range s.Categories {
+		options = append(options, fmt.Sprintf("--category %q", category))
+	}
+	return strings.Join(options, " ")
+}
+
+// RunMetadata records the outcome of the last scrape
+type RunMetadata struct {
+	StartedAt  time.Time      `json:"started-at"`
+	FinishedAt time.Time      `json:"finished-at"`
+	Sources    []types.Source `json:"sources"`
+	Partial    *Scope         `json:"partial,omitempty"` // the scrape only covered some addons, nil for a full scrape
+	Gate       *Verdict       `json:"gate"`
+}
+
+// Run checks the catalogue JSON at path against previous (nil on a first run)
+func Run(path string, previous *types.Catalogue, thresholds Thresholds) (Verdict, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("failed to read catalogue %s: %w", path, err)
+	}
+
+	verdict := Verdict{
+		Catalogue: path,
+		CheckedAt: time.Now().UTC(),
+	}
+
+	validationResult := CheckResult{Name: ValidationCheck, Passed: true}
+	if err := validation.ValidateCatalogueJSON(data); err != nil {
+		validationResult.Passed = false
+		var validationErr *validation.ValidationError
+		if errors.As(err, &validationErr) {
+			for _, violation := range validationErr.Violations {
+				validationResult.Problems = append(validationResult.Problems, violation.Error())
+			}
+		} else {
+			validationResult.Problems = []string{err.Error()}
+		}
+	}
+	verdict.CheckList = append(verdict.CheckList, validationResult)
+
+	// The remaining checks need a well-formed catalogue
+	var current types.Catalogue
+	if err := json.Unmarshal(data, &current); err != nil {
+		verdict.CheckList = append(verdict.CheckList, CheckResult{
+			Name:     StrictCheck,
+			Problems: []string{fmt.Sprintf("failed to parse catalogue: %v", err)},
+		})
+		return verdict, nil
+	}
+
+	verdict.ETag, err = catalogue.CatalogueETag(current)
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	verdict.CheckList = append(verdict.CheckList,
+		newCheckResult(StrictCheck, strictProblems(current, verdict.CheckedAt)),
+		newCheckResult(AnomalyCheck, anomalyProblems(previous, current, thresholds)),
+	)
+
+	verdict.Passed = len(verdict.Failures()) == 0
+	return verdict, nil
+}
+
+// RequirePassed returns ErrNotPassed unless the recorded verdict passed for the catalogue's current contents.
+// The catalogues of partial scrapes never pass, whatever their verdict.
+func RequirePassed(metadata RunMetadata, current types.Catalogue) error {
+	if metadata.Gate == nil {
+		return fmt.Errorf("%w: no verdict recorded, run scrape first", ErrNotPassed)
+	}
+
+	if metadata.Partial != nil {
+		return fmt.Errorf("%w: partial scrape (%s), scrape every addon first", ErrNotPassed, metadata.Partial)
+	}
+
+	if !metadata.Gate.Passed {
+		return fmt.Errorf("%w: failed checks: %s", ErrNotPassed, strings.Join(metadata.Gate.Failures(), ", "))
+	}
+
+	etag, err := catalogue.CatalogueETag(current)
+	if err != nil {
+		return err
+	}
+	if etag != metadata.Gate.ETag {
+		return fmt.Errorf("%w: catalogue changed since it was checked", ErrNotPassed)
+	}
+
+	return nil
+}
+
+// ReadRunMetadata reads run metadata, returning empty metadata if the file doesn't exist
+func ReadRunMetadata(path string) (RunMetadata, error) {
+	var metadata RunMetadata
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return metadata, nil
+	}
+	if err != nil {
+		return metadata, fmt.Errorf("failed to read run metadata %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return metadata, fmt.Errorf("failed to parse run metadata %s: %w", path, err)
+	}
+	return metadata, nil
+}
+
+// WriteRunMetadata writes run metadata as indented JSON
+func WriteRunMetadata(metadata RunMetadata, path string) error {
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run metadata: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write run metadata to %s: %w", path, err)
+	}
+	return nil
+}
+
+func newCheckResult(name string, problems []string) CheckResult {
+	return CheckResult{
+		Name:     name,
+		Passed:   len(problems) == 0,
+		Problems: problems,
+	}
+}
+
+// strictProblems finds problems the schema validator allows but a published catalogue shouldn't have
+func strictProblems(current types.Catalogue, now time.Time) []string {
+	var problems []string
+
+	if len(current.AddonSummaryList) == 0 {
+		problems = append(problems, "catalogue is empty")
+	}
+
+	seen := make(map[string]bool, len(current.AddonSummaryList))
+	for _, addon := range current.AddonSummaryList {
+		id := string(addon.Source) + "/" + addon.SourceID
+
+		if seen[id] {
+			problems = append(problems, fmt.Sprintf("%s: duplicate addon", id))
+		}
+		seen[id] = true
+
+		if strings.TrimSpace(addon.Label) == "" {
+			problems = append(problems, fmt.Sprintf("%s: empty label", id))
+		}
+		if addon.UpdatedDate.IsZero() {
+			problems = append(problems, fmt.Sprintf("%s: missing updated-date", id))
+		} else if addon.UpdatedDate.After(now.Add(24 * time.Hour)) {
+			problems = append(problems, fmt.Sprintf("%s: updated-date %s is in the future", id, addon.UpdatedDate.Format(time.RFC3339)))
+		}
+	}
+
+	return problems
+}
+
+// anomalyProblems compares the catalogue to the previous one, looking for signs of a broken scrape
+func anomalyProblems(previous *types.Catalogue, current types.Catalogue, thresholds Thresholds) []string {
+	if previous == nil || len(previous.AddonSummaryList) == 0 {
+		return nil
+	}
+
+	var problems []string
+
+	previousTotal := len(previous.AddonSummaryList)
+	currentTotal := len(current.AddonSummaryList)
+	if shrink := shrinkage(previousTotal, currentTotal); shrink > thresholds.MaxTotalShrink {
+		problems = append(problems, fmt.Sprintf("catalogue shrank by %.1f%% (%d to %d addons)", shrink*100, previousTotal, currentTotal))
+	}
+
+	previousBySource := countBySource(previous.AddonSummaryList)
+	currentBySource := countBySource(current.AddonSummaryList)
+	sources := make([]types.Source, 0, len(previousBySource))
+	for source := range previousBySource {
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i] < sources[j] })
+	for _, source := range sources {
+		if shrink := shrinkage(previousBySource[source], currentBySource[source]); shrink > thresholds.MaxSourceShrink {
+			problems = append(problems, fmt.Sprintf("%s shrank by %.1f%% (%d to %d addons)", source, shrink*100, previousBySource[source], currentBySource[source]))
+		}
+	}
+
+	currentIDs := make(map[string]bool, currentTotal)
+	for _, addon := range current.AddonSummaryList {
+		currentIDs[string(addon.Source)+"/"+addon.SourceID] = true
+	}
+	removed := 0
+	for _, addon := range previous.AddonSummaryList {
+		if !currentIDs[string(addon.Source)+"/"+addon.SourceID] {
+			removed++
+		}
+	}
+	if fraction := float64(removed) / float64(previousTotal); fraction > thresholds.MaxRemoved {
+		problems = append(problems, fmt.Sprintf("%d addons (%.1f%%) removed", removed, fraction*100))
+	}
+
+	return problems
+}
+
+// shrinkage returns the fraction previous shrank by to reach current, 0 if it grew
+func shrinkage(previous, current int) float64 {
+	if previous == 0 || current >= previous {
+		return 0
+	}
+	return float64(previous-current) / float64(previous)
+}
+
+func countBySource(addons []types.Addon) map[types.Source]int {
+	counts := make(map[types.Source]int)
+	for _, addon := range addons {
+		counts[addon.Source]++
+	}
+	return counts
+}
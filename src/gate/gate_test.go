@@ -0,0 +1,207 @@
+package gate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/catalogue"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func gateTestAddon(source types.Source, sourceID string) types.Addon {
+	return types.Addon{
+		GameTrackList: []types.GameTrack{types.RetailTrack},
+		Label:         "Addon " + sourceID,
+		Name:          "addon-" + sourceID,
+		Source:        source,
+		SourceID:      sourceID,
+		TagList:       []string{},
+		URL:           "https://www.wowinterface.com/downloads/info" + sourceID,
+		UpdatedDate:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func gateTestCatalogue(count int) types.Catalogue {
+	var addons []types.Addon
+	for i := 1; i <= count; i++ {
+		addons = append(addons, gateTestAddon(types.WowInterfaceSource, fmt.Sprint(i)))
+	}
+	return catalogue.NewBuilder().BuildCatalogue(addons, nil)
+}
+
+func writeTestCatalogue(t *testing.T, cat types.Catalogue) string {
+	t.Helper()
+	data, err := json.Marshal(cat)
+	if err != nil {
+		t.Fatalf("failed to marshal catalogue: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "catalogue.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("failed to write catalogue: %v", err)
+	}
+	return path
+}
+
+func checkPassed(verdict Verdict, name string) bool {
+	for _, check := range verdict.CheckList {
+		if check.Name == name {
+			return check.Passed
+		}
+	}
+	return false
+}
+
+func TestRun(t *testing.T) {
+	duplicated := gateTestCatalogue(3)
+	duplicated.AddonSummaryList = append(duplicated.AddonSummaryList, duplicated.AddonSummaryList[0])
+	duplicated.Total = len(duplicated.AddonSummaryList)
+
+	previous := gateTestCatalogue(100)
+
+	tests := []struct {
+		name       string
+		current    types.Catalogue
+		previous   *types.Catalogue
+		wantPassed bool
+		wantFailed []string
+	}{
+		{"first run", gateTestCatalogue(10), nil, true, nil},
+		{"normal churn", gateTestCatalogue(98), &previous, true, nil},
+		{"empty", gateTestCatalogue(0), nil, false, []string{StrictCheck}},
+		{"duplicate addon", duplicated, nil, false, []string{StrictCheck}},
+		{"catalogue shrank", gateTestCatalogue(50), &previous, false, []string{AnomalyCheck}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestCatalogue(t, tt.current)
+			verdict, err := Run(path, tt.previous, DefaultThresholds())
+			if err != nil {
+				t.Fatalf("Run() unexpected error: %v", err)
+			}
+
+			if verdict.Passed != tt.wantPassed {
+				t.Errorf("Passed = %v, want %v (checks: %+v)", verdict.Passed, tt.wantPassed, verdict.CheckList)
+			}
+			for _, name := range tt.wantFailed {
+				if checkPassed(verdict, name) {
+					t.Errorf("check %s passed, want failed", name)
+				}
+			}
+		})
+	}
+}
+
+func TestRun_InvalidCatalogue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalogue.json")
+	if err := os.WriteFile(path, []byte(`{"spec": {"version": 2}}`), 0644); err != nil {
+		t.Fatalf("failed to write catalogue: %v", err)
+	}
+
+	verdict, err := Run(path, nil, DefaultThresholds())
+	if err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+	if verdict.Passed {
+		t.Errorf("Passed = true, want false")
+	}
+	if checkPassed(verdict, ValidationCheck) {
+		t.Errorf("check %s passed, want failed", ValidationCheck)
+	}
+}
+
+func TestAnomalyProblems_SourceShrank(t *testing.T) {
+	var previousAddons, currentAddons []types.Addon
+	for i := 1; i <= 100; i++ {
+		previousAddons = append(previousAddons, gateTestAddon(types.WowInterfaceSource, fmt.Sprint(i)))
+		currentAddons = append(currentAddons, gateTestAddon(types.WowInterfaceSource, fmt.Sprint(i)))
+	}
+	for i := 1; i <= 8; i++ {
+		previousAddons = append(previousAddons, gateTestAddon(types.GitHubSource, fmt.Sprintf("owner/repo%d", i)))
+	}
+	// GitHub scrape lost most of its addons, overall this is only ~5.5%
+	currentAddons = append(currentAddons, gateTestAddon(types.GitHubSource, "owner/repo1"))
+
+	builder := catalogue.NewBuilder()
+	previous := builder.BuildCatalogue(previousAddons, nil)
+	current := builder.BuildCatalogue(currentAddons, nil)
+
+	thresholds := DefaultThresholds()
+	thresholds.MaxRemoved = 1 // only interested in the per-source check
+	problems := anomalyProblems(&previous, current, thresholds)
+	if len(problems) != 1 {
+		t.Fatalf("anomalyProblems() = %v, want 1 problem", problems)
+	}
+}
+
+func TestRequirePassed(t *testing.T) {
+	cat := gateTestCatalogue(3)
+	verdict, err := Run(writeTestCatalogue(t, cat), nil, DefaultThresholds())
+	if err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+
+	failed := verdict
+	failed.Passed = false
+	failed.CheckList = []CheckResult{{Name: AnomalyCheck, Problems: []string{"catalogue shrank"}}}
+
+	changed := gateTestCatalogue(4)
+
+	tests := []struct {
+		name     string
+		metadata RunMetadata
+		current  types.Catalogue
+		wantErr  bool
+	}{
+		{"passed", RunMetadata{Gate: &verdict}, cat, false},
+		{"no verdict", RunMetadata{}, cat, true},
+		{"failed", RunMetadata{Gate: &failed}, cat, true},
+		{"catalogue changed since", RunMetadata{Gate: &verdict}, changed, true},
+		{"partial scrape", RunMetadata{Gate: &verdict, Partial: &Scope{Limit: 200}}, cat, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RequirePassed(tt.metadata, tt.current)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RequirePassed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrNotPassed) {
+				t.Errorf("RequirePassed() error = %v, want %v", err, ErrNotPassed)
+			}
+		})
+	}
+}
+
+func TestRunMetadata_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run-metadata.json")
+
+	missing, err := ReadRunMetadata(path)
+	if err != nil {
+		t.Fatalf("ReadRunMetadata() unexpected error for missing file: %v", err)
+	}
+	if missing.Gate != nil {
+		t.Errorf("Gate = %+v, want nil", missing.Gate)
+	}
+
+	metadata := RunMetadata{
+		Sources: []types.Source{types.WowInterfaceSource},
+		Gate:    &Verdict{Passed: true, ETag: `"abc"`},
+	}
+	if err := WriteRunMetadata(metadata, path); err != nil {
+		t.Fatalf("WriteRunMetadata() unexpected error: %v", err)
+	}
+
+	read, err := ReadRunMetadata(path)
+	if err != nil {
+		t.Fatalf("ReadRunMetadata() unexpected error: %v", err)
+	}
+	if read.Gate == nil || !read.Gate.Passed || read.Gate.ETag != `"abc"` {
+		t.Errorf("Gate = %+v, want passed with etag \"abc\"", read.Gate)
+	}
+}
@@ -0,0 +1,19 @@
+package github
+
+import "os"
+
+const (
+	// APIHost serves the GitHub REST API. Authenticated requests get a much larger rate limit quota.
+	APIHost = "api.github.com"
+
+	// TokenEnvVar holds a GitHub token when one isn't given with --github-token
+	TokenEnvVar = "GITHUB_TOKEN"
+)
+
+// Token returns token if set, otherwise the token in the environment, if any
+func Token(token string) string {
+	if token != "" {
+		return token
+	}
+	return os.Getenv(TokenEnvVar)
+}
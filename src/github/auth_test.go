@@ -0,0 +1,13 @@
+package github
+
+import "testing"
+
+func TestToken(t *testing.T) {
+	t.Setenv(TokenEnvVar, "from-env")
+	if got := Token("from-flag"); got != "from-flag" {
+		t.Errorf("Token(from-flag) = %q, want from-flag", got)
+	}
+	if got := Token(""); got != "from-env" {
+		t.Errorf("Token(\"\") = %q, want from-env", got)
+	}
+}
@@ -0,0 +1,71 @@
+package github
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// CanonicalSourceID normalises an "owner/repo" source-id.
+// Surrounding slashes, whitespace and a ".git" suffix are removed. Casing is preserved,
+// see SourceIDMigrations for keeping it stable across runs.
+func CanonicalSourceID(fullName string) string {
+	fullName = strings.TrimSpace(fullName)
+	fullName = strings.Trim(fullName, "/")
+	fullName = strings.TrimSuffix(fullName, ".git")
+	return fullName
+}
+
+// CanonicalURL normalises a GitHub repository URL to "https://github.com/owner/repo".
+// URLs that don't look like a GitHub repository are returned trimmed but otherwise unchanged.
+func CanonicalURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	host := strings.ToLower(u.Host)
+	if host != "github.com" && host != "www.github.com" {
+		return rawURL
+	}
+
+	// owner/repo, dropping any trailing path such as /tree/master
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return rawURL
+	}
+
+	return "https://github.com/" + CanonicalSourceID(parts[0]+"/"+parts[1])
+}
+
+// SourceIDMigrations maps a case-insensitive source-id to the source-id already published.
+// GitHub treats owner/repo case-insensitively but strongbox matches source-ids exactly,
+// so a casing change between CSV revisions must not appear as a removal plus an addition.
+type SourceIDMigrations map[string]string
+
+// NewSourceIDMigrations builds migrations from the GitHub addons of a previously published catalogue
+func NewSourceIDMigrations(published []types.Addon) SourceIDMigrations {
+	migrations := make(SourceIDMigrations)
+	for _, addon := range published {
+		if addon.Source == types.GitHubSource {
+			migrations[strings.ToLower(addon.SourceID)] = addon.SourceID
+		}
+	}
+	return migrations
+}
+
+// Apply rewrites the addon's source-id and URL to the published casing, if known
+func (m SourceIDMigrations) Apply(addon *types.Addon) {
+	published, ok := m[strings.ToLower(addon.SourceID)]
+	if !ok || published == addon.SourceID {
+		return
+	}
+
+	addon.SourceID = published
+	if strings.EqualFold(addon.URL, "https://github.com/"+published) {
+		addon.URL = "https://github.com/" + published
+	}
+}
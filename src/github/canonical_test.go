@@ -0,0 +1,100 @@
+package github
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"https://github.com/owner/repo", "https://github.com/owner/repo"},
+		{"https://github.com/owner/repo/", "https://github.com/owner/repo"},
+		{"https://github.com/owner/repo.git", "https://github.com/owner/repo"},
+		{"http://www.github.com/Owner/Repo", "https://github.com/Owner/Repo"},
+		{"https://GitHub.com/owner/repo/tree/master", "https://github.com/owner/repo"},
+		{" https://github.com/owner/repo ", "https://github.com/owner/repo"},
+		{"https://gitlab.com/owner/repo.git", "https://gitlab.com/owner/repo.git"},
+		{"https://github.com/owner", "https://github.com/owner"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := CanonicalURL(tt.input); got != tt.expected {
+				t.Errorf("CanonicalURL(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCanonicalSourceID(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"owner/repo", "owner/repo"},
+		{"Owner/Repo", "Owner/Repo"},
+		{"owner/repo.git", "owner/repo"},
+		{"/owner/repo/", "owner/repo"},
+		{"  owner/repo  ", "owner/repo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := CanonicalSourceID(tt.input); got != tt.expected {
+				t.Errorf("CanonicalSourceID(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseCSV_CaseVariantsDeduplicated(t *testing.T) {
+	csvContent := `name,full_name,url,description,last_updated,flavors,downloads
+Repo,Owner/Repo,https://github.com/Owner/Repo.git,old row,2021-01-01T00:00:00+00:00,mainline,10
+Repo,owner/repo,https://github.com/owner/repo/,new row,2022-01-01T00:00:00+00:00,mainline,20
+`
+
+	addons, err := NewParser().ParseCSV(csvContent)
+	if err != nil {
+		t.Fatalf("ParseCSV failed: %v", err)
+	}
+
+	if len(addons) != 1 {
+		t.Fatalf("Expected 1 addon, got %d", len(addons))
+	}
+
+	if addons[0].Description != "new row" {
+		t.Errorf("Expected most recently updated row to win, got '%s'", addons[0].Description)
+	}
+	if addons[0].URL != "https://github.com/owner/repo" {
+		t.Errorf("Expected canonical URL, got '%s'", addons[0].URL)
+	}
+}
+
+func TestSourceIDMigrations(t *testing.T) {
+	published := []types.Addon{
+		{Source: types.GitHubSource, SourceID: "Owner/Repo", URL: "https://github.com/Owner/Repo"},
+		{Source: types.WowInterfaceSource, SourceID: "12345"},
+	}
+	migrations := NewSourceIDMigrations(published)
+
+	addons := []types.Addon{
+		{Source: types.GitHubSource, SourceID: "owner/repo", URL: "https://github.com/owner/repo", UpdatedDate: time.Now()},
+		{Source: types.GitHubSource, SourceID: "other/repo", URL: "https://github.com/other/repo"},
+	}
+	NewParser().ApplyMigrations(addons, migrations)
+
+	if addons[0].SourceID != "Owner/Repo" {
+		t.Errorf("Expected published source-id 'Owner/Repo', got '%s'", addons[0].SourceID)
+	}
+	if addons[0].URL != "https://github.com/Owner/Repo" {
+		t.Errorf("Expected URL to follow the published source-id, got '%s'", addons[0].URL)
+	}
+	if addons[1].SourceID != "other/repo" {
+		t.Errorf("Expected unpublished source-id to be unchanged, got '%s'", addons[1].SourceID)
+	}
+}
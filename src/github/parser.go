@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/gosimple/slug"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/description"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/normalise"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
 )
 
@@ -18,12 +20,19 @@ const (
 	CatalogueURL = "https://raw.githubusercontent.com/ogri-la/github-wow-addon-catalogue-go/master/addons.csv"
 )
 
-type Parser struct{}
+type Parser struct {
+	descriptionMode description.Mode
+}
 
 func NewParser() *Parser {
 	return &Parser{}
 }
 
+// SetDescriptionMode sets how descriptions are taken from READMEs, the first good line by default
+func (p *Parser) SetDescriptionMode(mode description.Mode) {
+	p.descriptionMode = mode
+}
+
 // BuildCatalogue downloads and parses the Github addon catalogue CSV
 func (p *Parser) BuildCatalogue() ([]types.Addon, error) {
 	resp, err := http.Get(CatalogueURL)
@@ -61,6 +70,7 @@ func (p *Parser) ParseCSV(csvContent string) ([]types.Addon, error) {
 	}
 
 	var addons []types.Addon
+	seen := make(map[string]int) // lowercased source-id -> index in addons
 
 	// Read rows
 	for {
@@ -78,12 +88,29 @@ func (p *Parser) ParseCSV(csvContent string) ([]types.Addon, error) {
 			continue
 		}
 
+		// The same repository may be listed more than once with different casing, keep the most recently updated
+		key := strings.ToLower(addon.SourceID)
+		if i, exists := seen[key]; exists {
+			if addon.UpdatedDate.After(addons[i].UpdatedDate) {
+				addons[i] = addon
+			}
+			continue
+		}
+		seen[key] = len(addons)
+
 		addons = append(addons, addon)
 	}
 
 	return addons, nil
 }
 
+// ApplyMigrations rewrites source-ids to the casing already published, see SourceIDMigrations
+func (p *Parser) ApplyMigrations(addons []types.Addon, migrations SourceIDMigrations) {
+	for i := range addons {
+		migrations.Apply(&addons[i])
+	}
+}
+
 func (p *Parser) parseCSVRow(record []string, headerIndex map[string]int) (types.Addon, error) {
 	getField := func(name string) string {
 		if idx, ok := headerIndex[name]; ok && idx < len(record) {
@@ -92,22 +119,22 @@ func (p *Parser) parseCSVRow(record []string, headerIndex map[string]int) (types
 		return ""
 	}
 
-	name := getField("name")
+	name := normalise.Text(getField("name"))
 	if name == "" {
 		return types.Addon{}, fmt.Errorf("name is required")
 	}
 
-	fullName := getField("full_name")
+	fullName := CanonicalSourceID(getField("full_name"))
 	if fullName == "" {
 		return types.Addon{}, fmt.Errorf("full_name is required")
 	}
 
-	url := getField("url")
+	url := CanonicalURL(getField("url"))
 	if url == "" {
 		return types.Addon{}, fmt.Errorf("url is required")
 	}
 
-	description := getField("description")
+	description := normalise.Text(getField("description"))
 
 	// Parse updated date
 	var updatedDate time.Time
@@ -152,6 +179,14 @@ func (p *Parser) parseCSVRow(record []string, headerIndex map[string]int) (types
 	// Create slugified name - replace underscores with hyphens for consistency with Clojure version
 	slugifiedName := strings.ReplaceAll(slug.Make(name), "_", "-")
 
+	// The repository's WowInterface addon, if it has one. Checked by the catalogue builder's cross-source linking.
+	var sameAs []types.AddonRef
+	if wowiID := getField("wowi_id"); wowiID != "" {
+		if _, err := strconv.Atoi(wowiID); err == nil {
+			sameAs = []types.AddonRef{{Source: types.WowInterfaceSource, SourceID: wowiID}}
+		}
+	}
+
 	addon := types.Addon{
 		CreatedDate:   nil,
 		Description:   description,
@@ -159,6 +194,7 @@ func (p *Parser) parseCSVRow(record []string, headerIndex map[string]int) (types
 		GameTrackList: gameTrackList,
 		Label:         name,
 		Name:          slugifiedName,
+		SameAs:        sameAs,
 		Source:        "github",
 		SourceID:      fullName,
 		TagList:       []string{},
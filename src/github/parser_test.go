@@ -86,6 +86,17 @@ func TestParseCSV(t *testing.T) {
 		}
 	}
 
+	// Repositories with a WowInterface ID refer to that addon
+	addon4 := addons[3]
+	expectedSameAs := []types.AddonRef{{Source: types.WowInterfaceSource, SourceID: "25680"}}
+	if len(addon4.SameAs) != 1 || addon4.SameAs[0] != expectedSameAs[0] {
+		t.Errorf("Expected same-as %v, got %v", expectedSameAs, addon4.SameAs)
+	}
+	// curse_id is not a WowInterface ID
+	if len(addon2.SameAs) != 0 {
+		t.Errorf("Expected no same-as, got %v", addon2.SameAs)
+	}
+
 	// Test fifth addon - has description with comma and high download count
 	addon5 := addons[4]
 	if addon5.Name != "chatcleaner" {
@@ -100,6 +111,29 @@ func TestParseCSV(t *testing.T) {
 	}
 }
 
+func TestParseCSV_NormalisesText(t *testing.T) {
+	csvContent := "name,full_name,url,description,last_updated\n" +
+		"Tom &amp; Jerry,owner/repo,https://github.com/owner/repo,  Donâ€™t   panic &quot;ever&quot;  ,2021-12-26T09:40:18+00:00\n"
+
+	addons, err := NewParser().ParseCSV(csvContent)
+	if err != nil {
+		t.Fatalf("ParseCSV failed: %v", err)
+	}
+	if len(addons) != 1 {
+		t.Fatalf("Expected 1 addon, got %d", len(addons))
+	}
+
+	if addons[0].Label != "Tom & Jerry" {
+		t.Errorf("Expected label 'Tom & Jerry', got '%s'", addons[0].Label)
+	}
+	if addons[0].Name != "tom-and-jerry" {
+		t.Errorf("Expected name 'tom-and-jerry', got '%s'", addons[0].Name)
+	}
+	if addons[0].Description != "Don’t panic \"ever\"" {
+		t.Errorf("Expected normalised description, got '%s'", addons[0].Description)
+	}
+}
+
 func TestGuessGameTrack(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,141 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/description"
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/normalise"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+const (
+	// RawContentHost serves repository files without counting against the GitHub API rate limit
+	RawContentHost = "https://raw.githubusercontent.com"
+
+	// DefaultReadmeInterval is the minimum delay between README requests
+	DefaultReadmeInterval = 250 * time.Millisecond
+)
+
+// readmeFilenames are tried in order, most common first
+var readmeFilenames = []string{"README.md", "readme.md", "README"}
+
+// readmeURL returns the raw URL of a file on the repository's default branch
+func readmeURL(sourceID, filename string) string {
+	return RawContentHost + "/" + sourceID + "/HEAD/" + filename
+}
+
+// MissingReadmes is the updated date of each repository found without a README, by source-id. A repository's
+// README isn't asked for again until the repository is updated.
+type MissingReadmes map[string]time.Time
+
+// ReadMissingReadmes reads the repositories written to path by Write. A missing file has no repositories.
+func ReadMissingReadmes(path string) (MissingReadmes, error) {
+	missing := MissingReadmes{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return missing, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read missing READMEs %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &missing); err != nil {
+		return nil, fmt.Errorf("failed to parse missing READMEs %s: %w", path, err)
+	}
+	return missing, nil
+}
+
+// Write writes the repositories to path as indented JSON, an object of source-id to updated date
+func (m MissingReadmes) Write(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal missing READMEs: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write missing READMEs to %s: %w", path, err)
+	}
+	return nil
+}
+
+// FillReadmeDescriptions fetches the README of each addon without a description and
+// summarises it into one. Requests go through client (and so its cache) no more often
+// than once per interval. Repositories in missing aren't fetched again until they're updated,
+// and missing is updated with those found without a README this time.
+// Addons are modified in place and the number filled is returned.
+func (p *Parser) FillReadmeDescriptions(ctx context.Context, client httpclient.HTTPClient, addons []types.Addon, missing MissingReadmes, interval time.Duration) int {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// forget repositories no longer in the catalogue
+	sourceIDs := make(map[string]bool, len(addons))
+	for _, addon := range addons {
+		sourceIDs[addon.SourceID] = true
+	}
+	for sourceID := range missing {
+		if !sourceIDs[sourceID] {
+			delete(missing, sourceID)
+		}
+	}
+
+	filled := 0
+	for i := range addons {
+		if addons[i].Description != "" {
+			continue
+		}
+		if updated, ok := missing[addons[i].SourceID]; ok && !addons[i].UpdatedDate.After(updated) {
+			continue
+		}
+
+		readme, notFound := p.fetchReadme(ctx, client, ticker, addons[i].SourceID)
+		if ctx.Err() != nil {
+			break
+		}
+		if notFound {
+			missing[addons[i].SourceID] = addons[i].UpdatedDate
+		} else {
+			delete(missing, addons[i].SourceID)
+		}
+
+		if summary := normalise.Text(description.Extract(description.StripMarkdown(readme), p.descriptionMode)); summary != "" {
+			addons[i].Description = summary
+			filled++
+		}
+	}
+
+	return filled
+}
+
+// fetchReadme returns the first README found for the repository or an empty string.
+// notFound is true only if every README filename was answered with a 404.
+func (p *Parser) fetchReadme(ctx context.Context, client httpclient.HTTPClient, ticker *time.Ticker, sourceID string) (readme string, notFound bool) {
+	notFound = true
+	for _, filename := range readmeFilenames {
+		select {
+		case <-ctx.Done():
+			return "", false
+		case <-ticker.C:
+		}
+
+		url := readmeURL(sourceID, filename)
+		resp, err := client.Get(ctx, url)
+		if err != nil {
+			slog.Debug("failed to fetch README", "url", url, "error", err)
+			notFound = false
+			continue
+		}
+		if resp.StatusCode == http.StatusOK {
+			return string(resp.Body), false
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			notFound = false
+		}
+	}
+	return "", notFound
+}
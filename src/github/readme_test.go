@@ -0,0 +1,122 @@
+package github
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestFillReadmeDescriptions(t *testing.T) {
+	client := httpclient.NewMockHTTPClient()
+	client.SetResponse(readmeURL("owner/Documented", "README.md"), &httpclient.Response{
+		StatusCode: 200,
+		Body:       []byte("# Documented\n\n[![Build](https://example.org/badge.svg)](https://example.org)\n\nTracks your **reputation** gains across all characters.\n"),
+	})
+	// lowercase readme only
+	client.SetResponse(readmeURL("owner/Lowercase", "README.md"), &httpclient.Response{StatusCode: 404})
+	client.SetResponse(readmeURL("owner/Lowercase", "readme.md"), &httpclient.Response{
+		StatusCode: 200,
+		Body:       []byte("A minimal [unit frame](https://example.org) replacement for raids.\n"),
+	})
+
+	addons := []types.Addon{
+		{SourceID: "owner/Documented"},
+		{SourceID: "owner/Described", Description: "Already has a description"},
+		{SourceID: "owner/Lowercase"},
+		{SourceID: "owner/Missing"},
+	}
+
+	parser := NewParser()
+	filled := parser.FillReadmeDescriptions(context.Background(), client, addons, MissingReadmes{}, time.Millisecond)
+
+	if filled != 2 {
+		t.Errorf("filled = %d, want 2", filled)
+	}
+
+	expected := []string{
+		"Tracks your reputation gains across all characters.",
+		"Already has a description",
+		"A minimal unit frame replacement for raids.",
+		"",
+	}
+	for i, want := range expected {
+		if addons[i].Description != want {
+			t.Errorf("addons[%d].Description = %q, want %q", i, addons[i].Description, want)
+		}
+	}
+
+	// described addons are never fetched
+	for _, url := range client.GetCalls() {
+		if url == readmeURL("owner/Described", "README.md") {
+			t.Errorf("fetched README for addon that already has a description")
+		}
+	}
+}
+
+func TestFillReadmeDescriptions_MissingReadmes(t *testing.T) {
+	pushed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := httpclient.NewMockHTTPClient()
+	for _, filename := range readmeFilenames {
+		client.SetResponse(readmeURL("owner/Undocumented", filename), &httpclient.Response{StatusCode: 404})
+	}
+	client.SetResponse(readmeURL("owner/Unavailable", "README.md"), &httpclient.Response{StatusCode: 503})
+
+	addons := []types.Addon{
+		{SourceID: "owner/Undocumented", UpdatedDate: pushed},
+		{SourceID: "owner/Unavailable", UpdatedDate: pushed},
+		{SourceID: "owner/Failing", UpdatedDate: pushed},
+	}
+	missing := MissingReadmes{"owner/Removed": pushed}
+
+	parser := NewParser()
+	parser.FillReadmeDescriptions(context.Background(), client, addons, missing, time.Millisecond)
+
+	// only a repository answering every README filename with a 404 is remembered, and removed repositories forgotten
+	want := MissingReadmes{"owner/Undocumented": pushed}
+	if !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing = %v, want %v", missing, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "missing-readmes.json")
+	if err := missing.Write(path); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+	missing, err := ReadMissingReadmes(path)
+	if err != nil || !reflect.DeepEqual(missing, want) {
+		t.Fatalf("ReadMissingReadmes() = %v, %v, want %v", missing, err, want)
+	}
+
+	fetched := func() int {
+		n := 0
+		for _, url := range client.GetCalls() {
+			if url == readmeURL("owner/Undocumented", "README.md") {
+				n++
+			}
+		}
+		return n
+	}
+
+	// the next scrape doesn't ask again
+	parser.FillReadmeDescriptions(context.Background(), client, addons, missing, time.Millisecond)
+	if n := fetched(); n != 1 {
+		t.Errorf("README of a repository without one fetched %d times, want 1", n)
+	}
+
+	// until the repository is updated
+	addons[0].UpdatedDate = pushed.Add(time.Hour)
+	client.SetResponse(readmeURL("owner/Undocumented", "README.md"), &httpclient.Response{StatusCode: 200, Body: []byte("Finally documented.\n")})
+	if filled := parser.FillReadmeDescriptions(context.Background(), client, addons, missing, time.Millisecond); filled != 1 {
+		t.Errorf("filled = %d, want 1", filled)
+	}
+	if n := fetched(); n != 2 {
+		t.Errorf("README of an updated repository fetched %d times in total, want 2", n)
+	}
+	if _, ok := missing["owner/Undocumented"]; ok {
+		t.Errorf("missing = %v, want the repository with a README forgotten", missing)
+	}
+}
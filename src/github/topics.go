@@ -0,0 +1,71 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/tags"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// DefaultTopicsInterval is the minimum delay between topics requests,
+// keeping an authenticated client within the API's 5000 requests an hour
+const DefaultTopicsInterval = 750 * time.Millisecond
+
+// topicsURL returns the API URL listing a repository's topics
+func topicsURL(sourceID string) string {
+	return "https://" + APIHost + "/repos/" + sourceID + "/topics"
+}
+
+// topicsResponse is the body of a topics request
+type topicsResponse struct {
+	Names []string `json:"names"`
+}
+
+// FillTopicTags fetches the topics of each addon's repository and sets its tags to them, normalised to the
+// tag vocabulary. Requests go through client (and so its cache) no more often than once per interval.
+// Filling stops early if the API rate limit is reached. Addons are modified in place and the number tagged is returned.
+func (p *Parser) FillTopicTags(ctx context.Context, client httpclient.HTTPClient, addons []types.Addon, interval time.Duration) int {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	tagged := 0
+	for i := range addons {
+		select {
+		case <-ctx.Done():
+			return tagged
+		case <-ticker.C:
+		}
+
+		url := topicsURL(addons[i].SourceID)
+		resp, err := client.Get(ctx, url)
+		if err != nil {
+			slog.Debug("failed to fetch topics", "url", url, "error", err)
+			continue
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusForbidden, http.StatusTooManyRequests:
+			slog.Warn("GitHub API rate limit reached, no more topics fetched", "tagged", tagged, "remaining", len(addons)-i)
+			return tagged
+		default:
+			slog.Debug("failed to fetch topics", "url", url, "status", resp.StatusCode)
+			continue
+		}
+
+		var topics topicsResponse
+		if err := json.Unmarshal(resp.Body, &topics); err != nil {
+			slog.Debug("failed to parse topics", "url", url, "error", err)
+			continue
+		}
+		if tagList := tags.Normalise(types.GitHubSource, topics.Names); len(tagList) > 0 {
+			addons[i].TagList = tagList
+			tagged++
+		}
+	}
+	return tagged
+}
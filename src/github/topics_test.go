@@ -0,0 +1,49 @@
+package github
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestFillTopicTags(t *testing.T) {
+	client := httpclient.NewMockHTTPClient()
+	client.SetResponse(topicsURL("owner/Frames"), &httpclient.Response{
+		StatusCode: 200,
+		Body:       []byte(`{"names": ["wow-addon", "unitframes", "world-of-warcraft", "weakauras"]}`),
+	})
+	client.SetResponse(topicsURL("owner/Untagged"), &httpclient.Response{StatusCode: 200, Body: []byte(`{"names": ["wow-addon"]}`)})
+	client.SetResponse(topicsURL("owner/Missing"), &httpclient.Response{StatusCode: 404})
+	client.SetResponse(topicsURL("owner/Limited"), &httpclient.Response{StatusCode: 403})
+
+	addons := []types.Addon{
+		{SourceID: "owner/Frames", TagList: []string{}},
+		{SourceID: "owner/Untagged", TagList: []string{}},
+		{SourceID: "owner/Missing", TagList: []string{}},
+		{SourceID: "owner/Limited", TagList: []string{}},
+		{SourceID: "owner/After", TagList: []string{}},
+	}
+
+	tagged := NewParser().FillTopicTags(context.Background(), client, addons, time.Millisecond)
+	if tagged != 1 {
+		t.Errorf("tagged = %d, want 1", tagged)
+	}
+
+	want := [][]string{{"unit-frames", "weakauras"}, {}, {}, {}, {}}
+	for i, tagList := range want {
+		if !reflect.DeepEqual(addons[i].TagList, tagList) {
+			t.Errorf("addons[%d].TagList = %v, want %v", i, addons[i].TagList, tagList)
+		}
+	}
+
+	// Nothing more is fetched once the rate limit is reached
+	for _, url := range client.GetCalls() {
+		if url == topicsURL("owner/After") {
+			t.Error("fetched topics after reaching the rate limit")
+		}
+	}
+}
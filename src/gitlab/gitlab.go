@@ -0,0 +1,220 @@
+// Package gitlab discovers addons published as GitLab projects tagged with a WoW addon topic
+// whose latest release has addon assets.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gosimple/slug"
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/normalise"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/release"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+const (
+	// APIURL is the GitLab REST API
+	APIURL = "https://gitlab.com/api/v4"
+
+	pageSize = 100
+)
+
+// Topics mark a project as a WoW addon
+var Topics = []string{"world-of-warcraft-addon", "wow-addon"}
+
+// Project is the part of a GitLab project used to build an addon
+type Project struct {
+	ID                int       `json:"id"`
+	Name              string    `json:"name"`
+	PathWithNamespace string    `json:"path_with_namespace"`
+	Description       string    `json:"description"`
+	WebURL            string    `json:"web_url"`
+	CreatedAt         time.Time `json:"created_at"`
+	Archived          bool      `json:"archived"`
+}
+
+// Release is the part of a GitLab release used to build an addon
+type Release struct {
+	TagName    string    `json:"tag_name"`
+	ReleasedAt time.Time `json:"released_at"`
+	Assets     struct {
+		Links []struct {
+			Name           string `json:"name"`
+			URL            string `json:"url"`
+			DirectAssetURL string `json:"direct_asset_url"`
+		} `json:"links"`
+	} `json:"assets"`
+}
+
+// ReleaseAssets returns the release's asset links. GitLab doesn't count asset downloads.
+func (r Release) ReleaseAssets() []release.Asset {
+	var assets []release.Asset
+	for _, link := range r.Assets.Links {
+		assetURL := link.DirectAssetURL
+		if assetURL == "" {
+			assetURL = link.URL
+		}
+		assets = append(assets, release.Asset{Name: link.Name, URL: assetURL})
+	}
+	return assets
+}
+
+type Parser struct{}
+
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// projectsURL returns a page of the public projects tagged with topic, oldest first so pages are stable
+func projectsURL(topic string, page int) string {
+	query := url.Values{
+		"topic":      {topic},
+		"visibility": {"public"},
+		"order_by":   {"id"},
+		"sort":       {"asc"},
+		"per_page":   {fmt.Sprint(pageSize)},
+		"page":       {fmt.Sprint(page)},
+	}
+	return APIURL + "/projects?" + query.Encode()
+}
+
+// latestReleaseURL returns the project's most recent release
+func latestReleaseURL(projectID int) string {
+	return fmt.Sprintf("%s/projects/%d/releases?per_page=1", APIURL, projectID)
+}
+
+// ParseProjects parses a page of projects
+func ParseProjects(data []byte) ([]Project, error) {
+	var projects []Project
+	if err := json.Unmarshal(data, &projects); err != nil {
+		return nil, fmt.Errorf("failed to parse GitLab projects: %w", err)
+	}
+	return projects, nil
+}
+
+// ParseLatestRelease parses a list of releases, newest first, returning the first if there is one
+func ParseLatestRelease(data []byte) (*Release, error) {
+	var releases []Release
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, fmt.Errorf("failed to parse GitLab releases: %w", err)
+	}
+	if len(releases) == 0 {
+		return nil, nil
+	}
+	return &releases[0], nil
+}
+
+// ToAddon maps a project and its latest release to an addon
+func ToAddon(project Project, latest Release, gameTracks []types.GameTrack) types.Addon {
+	label := normalise.Text(project.Name)
+	createdDate := project.CreatedAt
+	return types.Addon{
+		Archived:      project.Archived,
+		CreatedDate:   &createdDate,
+		Description:   normalise.Text(project.Description),
+		GameTrackList: gameTracks,
+		Label:         label,
+		Name:          strings.ReplaceAll(slug.Make(label), "_", "-"),
+		Source:        types.GitLabSource,
+		SourceID:      project.PathWithNamespace,
+		TagList:       []string{},
+		URL:           project.WebURL,
+		UpdatedDate:   latest.ReleasedAt,
+	}
+}
+
+// BuildCatalogue discovers projects tagged with any of Topics and returns those with an addon release as addons.
+// Requests go through client, and so its cache.
+func (p *Parser) BuildCatalogue(ctx context.Context, client httpclient.HTTPClient) ([]types.Addon, error) {
+	projects, err := p.discoverProjects(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	var addons []types.Addon
+	for _, project := range projects {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		addon, ok, err := p.buildAddon(ctx, client, project)
+		if err != nil {
+			slog.Warn("skipping GitLab project", "project", project.PathWithNamespace, "error", err)
+			continue
+		}
+		if !ok {
+			slog.Debug("GitLab project has no addon release", "project", project.PathWithNamespace)
+			continue
+		}
+		addons = append(addons, addon)
+	}
+	return addons, nil
+}
+
+// discoverProjects returns the projects tagged with any of Topics, each once
+func (p *Parser) discoverProjects(ctx context.Context, client httpclient.HTTPClient) ([]Project, error) {
+	var projects []Project
+	seen := make(map[int]bool)
+
+	for _, topic := range Topics {
+		for page := 1; ; page++ {
+			pageURL := projectsURL(topic, page)
+			resp, err := client.Get(ctx, pageURL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch GitLab projects: %w", err)
+			}
+			if resp.StatusCode != 200 {
+				return nil, fmt.Errorf("failed to fetch GitLab projects %s: status %d", pageURL, resp.StatusCode)
+			}
+
+			pageProjects, err := ParseProjects(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			for _, project := range pageProjects {
+				if !seen[project.ID] {
+					seen[project.ID] = true
+					projects = append(projects, project)
+				}
+			}
+
+			if len(pageProjects) < pageSize {
+				break
+			}
+		}
+	}
+	return projects, nil
+}
+
+// buildAddon returns the project as an addon, or false if its latest release has no addon assets
+func (p *Parser) buildAddon(ctx context.Context, client httpclient.HTTPClient, project Project) (types.Addon, bool, error) {
+	resp, err := client.Get(ctx, latestReleaseURL(project.ID))
+	if err != nil {
+		return types.Addon{}, false, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	if resp.StatusCode != 200 {
+		return types.Addon{}, false, fmt.Errorf("failed to fetch latest release: status %d", resp.StatusCode)
+	}
+
+	latest, err := ParseLatestRelease(resp.Body)
+	if err != nil || latest == nil {
+		return types.Addon{}, false, err
+	}
+
+	assets := latest.ReleaseAssets()
+	if !release.IsAddonRelease(assets) {
+		return types.Addon{}, false, nil
+	}
+
+	gameTracks, err := release.GameTracks(ctx, client, assets)
+	if err != nil {
+		return types.Addon{}, false, err
+	}
+	return ToAddon(project, *latest, gameTracks), true, nil
+}
@@ -0,0 +1,149 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// projectsPage returns a page of projects with the given IDs as a response body
+func projectsPage(t *testing.T, ids ...int) *httpclient.Response {
+	t.Helper()
+	projects := make([]Project, len(ids))
+	for i, id := range ids {
+		projects[i] = Project{ID: id, Name: "project", PathWithNamespace: "someone/project", WebURL: "https://gitlab.com/someone/project"}
+	}
+	body, err := json.Marshal(projects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &httpclient.Response{StatusCode: 200, Body: body}
+}
+
+func TestBuildCatalogue(t *testing.T) {
+	client := httpclient.NewMockHTTPClient()
+	for url, name := range map[string]string{
+		projectsURL(Topics[0], 1): "projects.json",
+		projectsURL(Topics[1], 1): "projects.json",
+		latestReleaseURL(101):     "releases--bag-sorter.json",
+		"https://gitlab.com/someone/bag-sorter/-/releases/v1.2.0/downloads/release.json": "release.json",
+	} {
+		body, err := os.ReadFile("test/fixtures/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.SetResponse(url, &httpclient.Response{StatusCode: 200, Body: body})
+	}
+	client.SetResponse(latestReleaseURL(102), &httpclient.Response{StatusCode: 200, Body: []byte("[]")})
+
+	addons, err := NewParser().BuildCatalogue(context.Background(), client)
+	if err != nil {
+		t.Fatalf("BuildCatalogue() unexpected error: %v", err)
+	}
+	if len(addons) != 1 {
+		t.Fatalf("BuildCatalogue() = %d addons, want 1", len(addons))
+	}
+
+	createdDate := time.Date(2023, 4, 1, 10, 0, 0, 0, time.UTC)
+	want := types.Addon{
+		CreatedDate:   &createdDate,
+		Description:   "Sorts your bags & bank",
+		GameTrackList: []types.GameTrack{types.ClassicCataTrack, types.RetailTrack},
+		Label:         "Bag Sorter",
+		Name:          "bag-sorter",
+		Source:        types.GitLabSource,
+		SourceID:      "someone/bag-sorter",
+		TagList:       []string{},
+		URL:           "https://gitlab.com/someone/bag-sorter",
+		UpdatedDate:   time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(addons[0], want) {
+		t.Errorf("BuildCatalogue() = %+v, want %+v", addons[0], want)
+	}
+}
+
+func TestDiscoverProjects(t *testing.T) {
+	fullPage := make([]int, pageSize)
+	for i := range fullPage {
+		fullPage[i] = i + 1
+	}
+
+	client := httpclient.NewMockHTTPClient()
+	// A full page is followed by another, a short page is the last
+	client.SetResponse(projectsURL(Topics[0], 1), projectsPage(t, fullPage...))
+	client.SetResponse(projectsURL(Topics[0], 2), projectsPage(t, pageSize+1))
+	// Projects tagged with both topics are already known
+	client.SetResponse(projectsURL(Topics[1], 1), projectsPage(t, 50, pageSize+1, pageSize+2))
+
+	projects, err := NewParser().discoverProjects(context.Background(), client)
+	if err != nil {
+		t.Fatalf("discoverProjects() unexpected error: %v", err)
+	}
+
+	var ids []int
+	for _, project := range projects {
+		ids = append(ids, project.ID)
+	}
+	wantIDs := append(fullPage, pageSize+1, pageSize+2)
+	if !reflect.DeepEqual(ids, wantIDs) {
+		t.Errorf("discoverProjects() = %d projects %v, want %d projects %v", len(ids), ids, len(wantIDs), wantIDs)
+	}
+
+	wantCalls := []string{projectsURL(Topics[0], 1), projectsURL(Topics[0], 2), projectsURL(Topics[1], 1)}
+	if calls := client.GetCalls(); !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("discoverProjects() fetched %v, want %v", calls, wantCalls)
+	}
+}
+
+func TestDiscoverProjects_Error(t *testing.T) {
+	fullPage := make([]int, pageSize)
+	for i := range fullPage {
+		fullPage[i] = i + 1
+	}
+
+	client := httpclient.NewMockHTTPClient()
+	client.SetResponse(projectsURL(Topics[0], 1), projectsPage(t, fullPage...))
+	client.SetResponse(projectsURL(Topics[0], 2), &httpclient.Response{StatusCode: 502})
+
+	// A failed page fails the scrape rather than building a catalogue missing the rest of the projects
+	if _, err := NewParser().BuildCatalogue(context.Background(), client); err == nil {
+		t.Error("BuildCatalogue() with a failed projects page, expected an error")
+	}
+}
+
+func TestBuildCatalogue_SkipsProjects(t *testing.T) {
+	cases := []struct {
+		name    string
+		release *httpclient.Response
+	}{
+		{"no releases", &httpclient.Response{StatusCode: 200, Body: []byte(`[]`)}},
+		{"no addon assets", &httpclient.Response{StatusCode: 200, Body: []byte(`[{"tag_name": "v1", "assets": {"links": [{"name": "notes.txt", "url": "https://gitlab.com/someone/project/notes.txt"}]}}]`)}},
+		{"releases unavailable", &httpclient.Response{StatusCode: 403}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := httpclient.NewMockHTTPClient()
+			client.SetResponse(projectsURL(Topics[0], 1), projectsPage(t, 1))
+			client.SetResponse(projectsURL(Topics[1], 1), projectsPage(t))
+			client.SetResponse(latestReleaseURL(1), tc.release)
+
+			addons, err := NewParser().BuildCatalogue(context.Background(), client)
+			if err != nil {
+				t.Fatalf("BuildCatalogue() unexpected error: %v", err)
+			}
+			if len(addons) != 0 {
+				t.Errorf("BuildCatalogue() = %+v, want no addons", addons)
+			}
+			if calls := client.GetCalls(); !slices.Contains(calls, latestReleaseURL(1)) {
+				t.Errorf("BuildCatalogue() fetched %v, want the latest release checked", calls)
+			}
+		})
+	}
+}
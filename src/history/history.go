@@ -0,0 +1,150 @@
+// Package history records addon download counts across scrapes and reports how they're trending.
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// Dir is the directory in the state directory snapshots are written to
+const Dir = "history"
+
+// DefaultRetention is how long snapshots are kept before being pruned
+const DefaultRetention = 90 * 24 * time.Hour
+
+// snapshotTimeFormat names snapshot files, sorting them chronologically
+const snapshotTimeFormat = "2006-01-02T150405Z"
+
+// Entry is an addon's download count at the time of a snapshot
+type Entry struct {
+	Source        types.Source `json:"source"`
+	SourceID      string       `json:"source-id"`
+	Name          string       `json:"name"`
+	DownloadCount int          `json:"download-count"`
+}
+
+// Key identifies the addon across catalogue versions, as names and labels may change
+func (e Entry) Key() string {
+	return string(e.Source) + "/" + e.SourceID
+}
+
+// Snapshot is the download counts of every addon in a catalogue that has one
+type Snapshot struct {
+	TakenAt   time.Time `json:"taken-at"`
+	Total     int       `json:"total"`
+	EntryList []Entry   `json:"entry-list"`
+}
+
+// NewSnapshot records the download counts of addons, ordered by source and source-id
+func NewSnapshot(addons []types.Addon, takenAt time.Time) Snapshot {
+	snapshot := Snapshot{TakenAt: takenAt.UTC()}
+	for _, addon := range addons {
+		if addon.DownloadCount == nil {
+			continue
+		}
+		snapshot.EntryList = append(snapshot.EntryList, Entry{
+			Source:        addon.Source,
+			SourceID:      addon.SourceID,
+			Name:          addon.Name,
+			DownloadCount: *addon.DownloadCount,
+		})
+	}
+	sort.Slice(snapshot.EntryList, func(i, j int) bool {
+		return snapshot.EntryList[i].Key() < snapshot.EntryList[j].Key()
+	})
+	snapshot.Total = len(snapshot.EntryList)
+	return snapshot
+}
+
+// Write writes a snapshot to dir, named after when it was taken
+func Write(snapshot Snapshot, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal download history: %w", err)
+	}
+
+	path := filepath.Join(dir, snapshot.TakenAt.UTC().Format(snapshotTimeFormat)+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write download history %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// snapshotFiles returns the snapshot files in dir, oldest first
+func snapshotFiles(dir string) ([]string, error) {
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history directory %s: %w", dir, err)
+	}
+
+	var paths []string
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, file.Name()))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Read reads every snapshot in dir, oldest first. A missing directory has no snapshots.
+func Read(dir string) ([]Snapshot, error) {
+	paths, err := snapshotFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read download history %s: %w", path, err)
+		}
+		var snapshot Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, fmt.Errorf("failed to parse download history %s: %w", path, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].TakenAt.Before(snapshots[j].TakenAt)
+	})
+	return snapshots, nil
+}
+
+// Prune removes snapshots in dir named as taken before cutoff, returning how many were removed
+func Prune(dir string, cutoff time.Time) (int, error) {
+	paths, err := snapshotFiles(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, path := range paths {
+		takenAt, err := time.Parse(snapshotTimeFormat, strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err != nil || !takenAt.Before(cutoff) {
+			continue // not a snapshot we named, or recent
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove download history %s: %w", path, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,129 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func snapshotAt(takenAt time.Time, counts map[string]int) Snapshot {
+	var addons []types.Addon
+	for sourceID, count := range counts {
+		addons = append(addons, types.Addon{Source: types.WowInterfaceSource, SourceID: sourceID, Name: "addon-" + sourceID, DownloadCount: intPtr(count)})
+	}
+	return NewSnapshot(addons, takenAt)
+}
+
+func TestNewSnapshot(t *testing.T) {
+	addons := []types.Addon{
+		{Source: types.WowInterfaceSource, SourceID: "2", Name: "b", DownloadCount: intPtr(20)},
+		{Source: types.GitHubSource, SourceID: "x/y", Name: "y"},
+		{Source: types.WowInterfaceSource, SourceID: "1", Name: "a", DownloadCount: intPtr(10)},
+	}
+
+	snapshot := NewSnapshot(addons, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	want := []Entry{
+		{Source: types.WowInterfaceSource, SourceID: "1", Name: "a", DownloadCount: 10},
+		{Source: types.WowInterfaceSource, SourceID: "2", Name: "b", DownloadCount: 20},
+	}
+	if !reflect.DeepEqual(snapshot.EntryList, want) || snapshot.Total != 2 {
+		t.Errorf("NewSnapshot() = %+v, want %+v", snapshot.EntryList, want)
+	}
+}
+
+func TestWriteReadPrune(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Written out of order
+	for _, day := range []int{2, 0, 1} {
+		if _, err := Write(snapshotAt(start.AddDate(0, 0, day), map[string]int{"1": day}), dir); err != nil {
+			t.Fatalf("Write() unexpected error: %v", err)
+		}
+	}
+
+	snapshots, err := Read(dir)
+	if err != nil {
+		t.Fatalf("Read() unexpected error: %v", err)
+	}
+	if len(snapshots) != 3 || !snapshots[0].TakenAt.Equal(start) || snapshots[2].EntryList[0].DownloadCount != 2 {
+		t.Errorf("Read() = %+v, want 3 snapshots oldest first", snapshots)
+	}
+
+	removed, err := Prune(dir, start.AddDate(0, 0, 1))
+	if err != nil || removed != 1 {
+		t.Errorf("Prune() = %d, %v, want 1", removed, err)
+	}
+	if snapshots, _ := Read(dir); len(snapshots) != 2 {
+		t.Errorf("Read() after Prune() = %d snapshots, want 2", len(snapshots))
+	}
+
+	if snapshots, err := Read(t.TempDir() + "/missing"); err != nil || len(snapshots) != 0 {
+		t.Errorf("Read(missing) = %v, %v, want no snapshots", snapshots, err)
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var snapshots []Snapshot
+	for day := range 10 {
+		snapshots = append(snapshots, snapshotAt(start.AddDate(0, 0, day), nil))
+	}
+
+	tests := []struct {
+		name   string
+		period time.Duration
+		want   time.Time
+	}{
+		{"a week", 7 * 24 * time.Hour, start.AddDate(0, 0, 2)},
+		{"a day", 24 * time.Hour, start.AddDate(0, 0, 8)},
+		{"longer than the history", 30 * 24 * time.Hour, start},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Baseline(snapshots, tt.period)
+			if err != nil || !got.TakenAt.Equal(tt.want) {
+				t.Errorf("Baseline() = %v, %v, want %v", got.TakenAt, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Baseline(snapshots[:1], time.Hour); err == nil {
+		t.Error("Baseline() with a single snapshot, expected an error")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	from := snapshotAt(start, map[string]int{"1": 100, "2": 100, "3": 100, "4": 100, "gone": 5})
+	to := snapshotAt(start.AddDate(0, 0, 2), map[string]int{"1": 110, "2": 300, "3": 100, "4": 40, "new": 7})
+
+	trend := Compare(from, to)
+
+	var growing, dropped []string
+	for _, change := range trend.Growing {
+		growing = append(growing, change.SourceID)
+	}
+	for _, change := range trend.Dropped {
+		dropped = append(dropped, change.SourceID)
+	}
+	if !reflect.DeepEqual(growing, []string{"2", "1"}) {
+		t.Errorf("Growing = %v, want [2 1]", growing)
+	}
+	if !reflect.DeepEqual(dropped, []string{"4"}) {
+		t.Errorf("Dropped = %v, want [4]", dropped)
+	}
+	if trend.Unchanged != 1 {
+		t.Errorf("Unchanged = %d, want 1", trend.Unchanged)
+	}
+	if got := trend.Growing[0]; got.Delta != 200 || got.Previous != 100 || got.PerDay != 100 {
+		t.Errorf("Growing[0] = %+v, want +200 at 100/day", got)
+	}
+}
@@ -0,0 +1,83 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Change is how an addon's download count changed between two snapshots
+type Change struct {
+	Entry        // as of the later snapshot
+	Previous int // download count in the earlier snapshot
+	Delta    int // DownloadCount - Previous
+	PerDay   float64
+}
+
+// Trend compares the download counts of two snapshots
+type Trend struct {
+	From      time.Time
+	To        time.Time
+	Growing   []Change // largest gain first
+	Dropped   []Change // largest drop first. Download counts only go up, so these point at problems with the source data.
+	Unchanged int
+}
+
+// Baseline returns the snapshot to compare the latest against: the newest taken at least period before the latest,
+// otherwise the oldest. Snapshots must be oldest first, as returned by Read.
+func Baseline(snapshots []Snapshot, period time.Duration) (Snapshot, error) {
+	if len(snapshots) < 2 {
+		return Snapshot{}, fmt.Errorf("not enough download history, found %d snapshots, need at least 2", len(snapshots))
+	}
+
+	latest := snapshots[len(snapshots)-1]
+	baseline := snapshots[0]
+	for _, snapshot := range snapshots[:len(snapshots)-1] {
+		if latest.TakenAt.Sub(snapshot.TakenAt) < period {
+			break
+		}
+		baseline = snapshot
+	}
+	return baseline, nil
+}
+
+// Compare returns how download counts changed going from one snapshot to another.
+// Addons in only one of the snapshots are ignored.
+func Compare(from, to Snapshot) Trend {
+	trend := Trend{From: from.TakenAt, To: to.TakenAt}
+
+	previous := make(map[string]int, len(from.EntryList))
+	for _, entry := range from.EntryList {
+		previous[entry.Key()] = entry.DownloadCount
+	}
+
+	days := to.TakenAt.Sub(from.TakenAt).Hours() / 24
+	for _, entry := range to.EntryList {
+		count, ok := previous[entry.Key()]
+		if !ok {
+			continue
+		}
+
+		change := Change{Entry: entry, Previous: count, Delta: entry.DownloadCount - count}
+		if days > 0 {
+			change.PerDay = float64(change.Delta) / days
+		}
+
+		switch {
+		case change.Delta > 0:
+			trend.Growing = append(trend.Growing, change)
+		case change.Delta < 0:
+			trend.Dropped = append(trend.Dropped, change)
+		default:
+			trend.Unchanged++
+		}
+	}
+
+	sort.SliceStable(trend.Growing, func(i, j int) bool {
+		return trend.Growing[i].Delta > trend.Growing[j].Delta
+	})
+	sort.SliceStable(trend.Dropped, func(i, j int) bool {
+		return trend.Dropped[i].Delta < trend.Dropped[j].Delta
+	})
+	return trend
+}
@@ -0,0 +1,34 @@
+package http
+
+import "net/http"
+
+// TokenTransport authenticates requests to particular hosts with bearer tokens.
+// Requests to other hosts are passed through untouched so tokens never leak.
+type TokenTransport struct {
+	tokens    map[string]string // host -> token
+	transport http.RoundTripper
+}
+
+// NewTokenTransport creates a transport adding each host's token to requests to it. Empty tokens are ignored.
+func NewTokenTransport(tokens map[string]string, transport http.RoundTripper) *TokenTransport {
+	t := &TokenTransport{tokens: make(map[string]string), transport: transport}
+	for host, token := range tokens {
+		if token != "" {
+			t.tokens[host] = token
+		}
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *TokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, ok := t.tokens[req.URL.Host]
+	if !ok {
+		return t.transport.RoundTrip(req)
+	}
+
+	// RoundTrippers mustn't modify the request they're given
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.transport.RoundTrip(req)
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+)
+
+type recordingTransport struct {
+	authorization string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.authorization = req.Header.Get("Authorization")
+	return &http.Response{StatusCode: http.StatusOK}, nil
+}
+
+func TestTokenTransport(t *testing.T) {
+	tokens := map[string]string{"api.github.com": "secret", "addons.wago.io": ""}
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://api.github.com/repos/ogri-la/strongbox", "Bearer secret"},
+		{"https://raw.githubusercontent.com/ogri-la/strongbox/HEAD/README.md", ""},
+		{"https://addons.wago.io/api/external/addons", ""}, // no token given
+		{"https://www.wowinterface.com/downloads/info1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			recorder := &recordingTransport{}
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			if _, err := NewTokenTransport(tokens, recorder).RoundTrip(req); err != nil {
+				t.Fatalf("RoundTrip() unexpected error: %v", err)
+			}
+			if recorder.authorization != tt.want {
+				t.Errorf("Authorization = %q, want %q", recorder.authorization, tt.want)
+			}
+			if req.Header.Get("Authorization") != "" {
+				t.Error("RoundTrip() modified the original request")
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrBudgetExceeded is returned for requests to a host that has already sent its budget of bytes
+var ErrBudgetExceeded = errors.New("download budget exceeded")
+
+// byteUnits are the suffixes ParseBytes accepts, binary multiples
+var byteUnits = map[string]int64{"": 1, "K": 1 << 10, "M": 1 << 20, "G": 1 << 30, "T": 1 << 40}
+
+// ParseBytes parses a number of bytes with an optional K, M, G or T suffix, e.g. "500M" or "2GB"
+func ParseBytes(s string) (int64, error) {
+	upper := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	number := strings.TrimRight(upper, "KMGT")
+	multiple, ok := byteUnits[upper[len(number):]]
+	n, err := strconv.ParseInt(number, 10, 64)
+	if !ok || err != nil || n < 0 || n > (1<<62)/multiple {
+		return 0, fmt.Errorf("invalid number of bytes %q, expected a number such as 500M", s)
+	}
+	return n * multiple, nil
+}
+
+// BandwidthTransport counts the bytes of the response bodies each host sends and refuses requests to a host once it
+// has sent maxBytesPerHost. Safe for concurrent use.
+type BandwidthTransport struct {
+	transport       http.RoundTripper
+	maxBytesPerHost int64 // 0 for no budget
+
+	mu    sync.Mutex
+	bytes map[string]int64 // since the last Reset
+}
+
+// NewBandwidthTransport creates a transport counting what transport downloads, with a budget of maxBytesPerHost
+// for each host or none if 0
+func NewBandwidthTransport(maxBytesPerHost int64, transport http.RoundTripper) *BandwidthTransport {
+	return &BandwidthTransport{
+		transport:       transport,
+		maxBytesPerHost: maxBytesPerHost,
+		bytes:           make(map[string]int64),
+	}
+}
+
+// RoundTrip implements http.RoundTripper, failing fast with ErrBudgetExceeded once the host has sent its budget
+func (t *BandwidthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if t.maxBytesPerHost > 0 && t.BytesRead()[host] >= t.maxBytesPerHost {
+		return nil, fmt.Errorf("%w for %s, it has sent %d bytes", ErrBudgetExceeded, host, t.maxBytesPerHost)
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, count: func(n int) { t.add(host, n) }}
+	return resp, nil
+}
+
+// add counts n more bytes from host, warning when they exhaust its budget
+func (t *BandwidthTransport) add(host string, n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	before := t.bytes[host]
+	t.bytes[host] += int64(n)
+	if t.maxBytesPerHost > 0 && before < t.maxBytesPerHost && t.bytes[host] >= t.maxBytesPerHost {
+		slog.Warn("host has sent its download budget, refusing further requests to it", "host", host, "max-bytes-per-host", t.maxBytesPerHost)
+	}
+}
+
+// BytesRead returns the bytes each host has sent since the last Reset
+func (t *BandwidthTransport) BytesRead() map[string]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return maps.Clone(t.bytes)
+}
+
+// Reset starts counting again, giving each host its whole budget, e.g. at the start of each scrape of the daemon
+func (t *BandwidthTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.bytes)
+}
+
+// countingBody counts the bytes read from a response body
+type countingBody struct {
+	io.ReadCloser
+	count func(n int)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.count(n)
+	}
+	return n, err
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"1024", 1024, false},
+		{"0", 0, false},
+		{"500M", 500 << 20, false},
+		{"2gb", 2 << 30, false},
+		{" 64KB ", 64 << 10, false},
+		{"1.5G", 0, true},
+		{"-1M", 0, true},
+		{"5KM", 0, true},
+		{"M", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBytes(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseBytes(%q) = %d, %v, want %d, wantErr %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBandwidthTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Repeat("x", 600))
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	transport := NewBandwidthTransport(1000, server.Client().Transport)
+	client := &http.Client{Transport: transport}
+
+	// The second response takes the host over its budget, but isn't cut short
+	for range 2 {
+		if body := get(t, transport, server.URL+"/downloads/info25078"); len(body) != 600 {
+			t.Fatalf("response = %d bytes, want all 600", len(body))
+		}
+	}
+	if got := transport.BytesRead(); got[host] != 1200 {
+		t.Errorf("BytesRead() = %v, want 1200 bytes from %s", got, host)
+	}
+
+	_, err := client.Get(server.URL + "/downloads/info25079")
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Get() over budget error = %v, want %v", err, ErrBudgetExceeded)
+	}
+	// Other hosts have budgets of their own
+	other, _ := url.Parse(server.URL)
+	other.Host = "localhost:" + other.Port()
+	if body := get(t, transport, other.String()+"/downloads/info25079"); len(body) != 600 {
+		t.Errorf("response from another host = %d bytes, want all 600", len(body))
+	}
+
+	transport.Reset()
+	if body := get(t, transport, server.URL+"/downloads/info25079"); len(body) != 600 {
+		t.Errorf("response after Reset() = %d bytes, want all 600", len(body))
+	}
+}
+
+func TestBandwidthTransport_NoBudget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "abc")
+	}))
+	defer server.Close()
+
+	transport := NewBandwidthTransport(0, server.Client().Transport)
+	for range 3 {
+		get(t, transport, server.URL)
+	}
+	if got := transport.BytesRead()[strings.TrimPrefix(server.URL, "http://")]; got != 9 {
+		t.Errorf("BytesRead() = %d, want 9", got)
+	}
+}
@@ -2,12 +2,19 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 )
 
+// DefaultMaxResponseSize is the largest response body read, well above the several megabytes of the WowInterface file lists
+const DefaultMaxResponseSize = 64 << 20
+
+// ErrResponseTooLarge is returned for a response with a body larger than the client's maximum
+var ErrResponseTooLarge = errors.New("response too large")
+
 // HTTPClient interface for mockable HTTP operations
 type HTTPClient interface {
 	Get(ctx context.Context, url string) (*Response, error)
@@ -22,53 +29,142 @@ type Response struct {
 
 // RealHTTPClient implements HTTPClient using net/http
 type RealHTTPClient struct {
-	client    *http.Client
-	userAgent string
+	client       *http.Client
+	userAgent    string
+	timeout      time.Duration
+	timeoutRules []TimeoutRule
+	maxSize      int64 // of response bodies
+	traces       tracer
+	chain        HTTPClient // get wrapped in the client's middlewares
 }
 
-// NewRealHTTPClient creates a new real HTTP client
-func NewRealHTTPClient(transport http.RoundTripper, userAgent string) *RealHTTPClient {
-	return &RealHTTPClient{
-		client: &http.Client{
-			Transport: transport,
-			Timeout:   30 * time.Second,
-		},
+// NewRealHTTPClient creates a new real HTTP client making every request through middlewares, the first outermost.
+// Requests time out after DefaultTimeout, see SetTimeouts, and bodies over DefaultMaxResponseSize fail, see SetMaxResponseSize.
+func NewRealHTTPClient(transport http.RoundTripper, userAgent string, middlewares ...Middleware) *RealHTTPClient {
+	c := &RealHTTPClient{
+		client:    &http.Client{Transport: transport},
 		userAgent: userAgent,
+		timeout:   DefaultTimeout,
+		maxSize:   DefaultMaxResponseSize,
 	}
+	c.chain = Chain(ClientFunc(c.get), middlewares...)
+	return c
+}
+
+// NewHeadClient creates a client like NewRealHTTPClient whose Get makes a HEAD request instead, returning the response
+// without a body and without following redirects, for checking links. Servers refusing HEAD requests are sent a GET
+// for the first byte instead, its body left unread.
+func NewHeadClient(transport http.RoundTripper, userAgent string, middlewares ...Middleware) *RealHTTPClient {
+	c := NewRealHTTPClient(transport, userAgent)
+	c.client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	c.chain = Chain(ClientFunc(c.head), middlewares...)
+	return c
+}
+
+// SetTimeouts sets how long each request may take: the timeout of the first of rules matching its URL, timeout if none do
+func (c *RealHTTPClient) SetTimeouts(timeout time.Duration, rules []TimeoutRule) {
+	c.timeout = timeout
+	c.timeoutRules = rules
+}
+
+// SetMaxResponseSize sets the largest response body read, requests for larger ones fail with ErrResponseTooLarge
+func (c *RealHTTPClient) SetMaxResponseSize(maxSize int64) {
+	c.maxSize = maxSize
+}
+
+// TraceStats returns where the requests made over a connection to each host spent their time, by host
+func (c *RealHTTPClient) TraceStats() map[string]HostTrace {
+	return c.traces.stats()
 }
 
 // Get performs an HTTP GET request
 func (c *RealHTTPClient) Get(ctx context.Context, url string) (*Response, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	return c.chain.Get(ctx, url)
+}
+
+func (c *RealHTTPClient) get(ctx context.Context, url string) (*Response, error) {
+	// Covers reading the body too
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout(c.timeout, c.timeoutRules, url))
+	defer cancel()
+
+	resp, err := c.do(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Don't read what will be thrown away, when the size is known up front
+	if resp.ContentLength > c.maxSize {
+		return nil, fmt.Errorf("%w: %s is %d bytes, more than %d", ErrResponseTooLarge, url, resp.ContentLength, c.maxSize)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if int64(len(body)) > c.maxSize {
+		return nil, fmt.Errorf("%w: %s is more than %d bytes", ErrResponseTooLarge, url, c.maxSize)
+	}
+
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Body:       body,
+		Headers:    firstHeaders(resp.Header),
+	}, nil
+}
+
+func (c *RealHTTPClient) head(ctx context.Context, url string) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout(c.timeout, c.timeoutRules, url))
+	defer cancel()
+
+	resp, err := c.do(ctx, http.MethodHead, url, nil)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = c.do(ctx, http.MethodGet, url, map[string]string{"Range": "bytes=0-0"})
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Headers:    firstHeaders(resp.Header),
+	}, nil
+}
+
+// do makes a request with headers, traced, returning the response with its body unread
+func (c *RealHTTPClient) do(ctx context.Context, method, url string, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("User-Agent", c.userAgent)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	ctx, traced := c.traces.withTrace(ctx, req.URL.Host, url)
+	defer traced()
+	req = req.WithContext(ctx)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch '%s': %w", url, err)
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
+	return resp, nil
+}
 
+// firstHeaders returns the first value of each header
+func firstHeaders(header http.Header) map[string]string {
 	headers := make(map[string]string)
-	for k, v := range resp.Header {
+	for k, v := range header {
 		if len(v) > 0 {
 			headers[k] = v[0]
 		}
 	}
-
-	return &Response{
-		StatusCode: resp.StatusCode,
-		Body:       body,
-		Headers:    headers,
-	}, nil
+	return headers
 }
 
 // MockHTTPClient implements HTTPClient for testing
@@ -4,6 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -153,3 +158,59 @@ func TestMockHTTPClient_OverrideResponse(t *testing.T) {
 		t.Errorf("New response body = %s, want 'not found'", string(resp.Body))
 	}
 }
+
+func TestRealHTTPClient_SetMaxResponseSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			w.(http.Flusher).Flush() // no Content-Length, the size is only found by reading
+		}
+		io.WriteString(w, strings.Repeat("x", 100))
+	}))
+	defer server.Close()
+
+	client := NewRealHTTPClient(server.Client().Transport, "test")
+	client.SetMaxResponseSize(100)
+	if resp, err := client.Get(context.Background(), server.URL+"/fixed"); err != nil || len(resp.Body) != 100 {
+		t.Errorf("Get() of a response at the limit = %v, %v, want all of it", resp, err)
+	}
+
+	client.SetMaxResponseSize(99)
+	for _, path := range []string{"/fixed", "/chunked"} {
+		if _, err := client.Get(context.Background(), server.URL+path); !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("Get(%s) over the limit error = %v, want %v", path, err, ErrResponseTooLarge)
+		}
+	}
+}
+
+func TestNewHeadClient(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path+" "+r.Header.Get("Range"))
+		switch {
+		case r.URL.Path == "/moved":
+			http.Redirect(w, r, "/file.zip", http.StatusFound)
+		case r.URL.Path == "/no-head" && r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		default:
+			io.WriteString(w, strings.Repeat("x", 100))
+		}
+	}))
+	defer server.Close()
+
+	client := NewHeadClient(server.Client().Transport, "test")
+	client.SetMaxResponseSize(10) // bodies are never read
+
+	resp, err := client.Get(context.Background(), server.URL+"/moved")
+	if err != nil || resp.StatusCode != http.StatusFound || resp.Headers["Location"] != "/file.zip" {
+		t.Errorf("Get() of a redirect = %+v, %v, want the redirect itself", resp, err)
+	}
+	resp, err = client.Get(context.Background(), server.URL+"/no-head")
+	if err != nil || resp.StatusCode != http.StatusOK || len(resp.Body) != 0 {
+		t.Errorf("Get() refused a HEAD request = %+v, %v, want the status of a GET without its body", resp, err)
+	}
+
+	want := []string{"HEAD /moved ", "HEAD /no-head ", "GET /no-head bytes=0-0"}
+	if !reflect.DeepEqual(methods, want) {
+		t.Errorf("requests = %q, want %q", methods, want)
+	}
+}
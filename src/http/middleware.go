@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Middleware adds behaviour to the requests made through an HTTPClient, such as retries, logging or rate limiting
+type Middleware func(next HTTPClient) HTTPClient
+
+// ClientFunc is an HTTPClient implemented by a function
+type ClientFunc func(ctx context.Context, url string) (*Response, error)
+
+// Get implements HTTPClient
+func (f ClientFunc) Get(ctx context.Context, url string) (*Response, error) {
+	return f(ctx, url)
+}
+
+// Chain wraps client in middlewares. The first middleware is the outermost: it sees requests first and responses last.
+func Chain(client HTTPClient, middlewares ...Middleware) HTTPClient {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		client = middlewares[i](client)
+	}
+	return client
+}
+
+// Logging logs each request at debug level along with its status and how long it took
+func Logging() Middleware {
+	return func(next HTTPClient) HTTPClient {
+		return ClientFunc(func(ctx context.Context, url string) (*Response, error) {
+			started := time.Now()
+			resp, err := next.Get(ctx, url)
+			elapsed := time.Since(started).Round(time.Millisecond)
+			if err != nil {
+				slog.Debug("request failed", "url", url, "elapsed", elapsed, "error", err)
+				return resp, err
+			}
+			slog.Debug("request", "url", url, "status", resp.StatusCode, "elapsed", elapsed)
+			return resp, nil
+		})
+	}
+}
+
+// RateLimit spaces requests at least interval apart, however many workers share the client
+func RateLimit(interval time.Duration) Middleware {
+	limiter := &rateLimiter{interval: interval}
+	return func(next HTTPClient) HTTPClient {
+		return ClientFunc(func(ctx context.Context, url string) (*Response, error) {
+			if err := limiter.wait(ctx); err != nil {
+				return nil, err
+			}
+			return next.Get(ctx, url)
+		})
+	}
+}
+
+type rateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time // earliest the next request may be made
+}
+
+// wait blocks until the caller's turn to make a request or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	turn := l.next
+	if turn.Before(now) {
+		turn = now
+	}
+	l.next = turn.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(turn)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
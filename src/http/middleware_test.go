@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// tagging returns a middleware noting its name on the way in and out of each request
+func tagging(name string, log *[]string) Middleware {
+	return func(next HTTPClient) HTTPClient {
+		return ClientFunc(func(ctx context.Context, url string) (*Response, error) {
+			*log = append(*log, name+" in")
+			resp, err := next.Get(ctx, url)
+			*log = append(*log, name+" out")
+			return resp, err
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	var log []string
+	mock := NewMockHTTPClient()
+	mock.SetResponse("https://example.org", &Response{StatusCode: 200})
+
+	client := Chain(mock, tagging("outer", &log), tagging("inner", &log))
+	if _, err := client.Get(context.Background(), "https://example.org"); err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+
+	want := []string{"outer in", "inner in", "inner out", "outer out"}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("middleware order = %v, want %v", log, want)
+	}
+	if calls := mock.GetCalls(); len(calls) != 1 {
+		t.Errorf("client called %d times, want 1", len(calls))
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	mock := NewMockHTTPClient()
+	mock.SetResponse("https://example.org", &Response{StatusCode: 200})
+	interval := 20 * time.Millisecond
+	client := Chain(mock, RateLimit(interval))
+
+	started := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get(context.Background(), "https://example.org"); err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(started); elapsed < 2*interval {
+		t.Errorf("3 requests took %v, want at least %v", elapsed, 2*interval)
+	}
+
+	// Waiting for a turn stops with the context
+	slow := Chain(mock, RateLimit(time.Hour))
+	slow.Get(context.Background(), "https://example.org")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := slow.Get(ctx, "https://example.org"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() waiting for its turn = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
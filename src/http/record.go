@@ -0,0 +1,196 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/cache"
+)
+
+// ErrNotRecorded is returned by ReplayTransport for requests without a recording
+var ErrNotRecorded = errors.New("no recording of URL")
+
+// sensitiveHeaders are left out of recordings, they authenticate or identify the builder
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// sensitiveParams are query parameters whose values are replaced in recordings
+var sensitiveParams = []string{"access_token", "api_key", "apikey", "key", "token"}
+
+// Recording is a request and the response it got, as saved by RecordingTransport
+type Recording struct {
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	StatusCode int               `json:"status-code"`
+	Headers    map[string]string `json:"headers"` // response headers
+	Body       string            `json:"body"`
+}
+
+// RecordingTransport saves a sanitised recording of each response to a URL matching one of its patterns
+// (see cache.MatchURL) to a directory, to be replayed by ReplayTransport in tests.
+// Responses are passed on unchanged. Recordings replace earlier recordings of the same URL.
+type RecordingTransport struct {
+	dir       string
+	patterns  []string
+	transport http.RoundTripper
+}
+
+// NewRecordingTransport creates a transport recording responses to URLs matching any of patterns into dir
+func NewRecordingTransport(dir string, patterns []string, transport http.RoundTripper) (*RecordingTransport, error) {
+	for _, pattern := range patterns {
+		if err := cache.ValidatePattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create fixtures directory: %w", err)
+	}
+	return &RecordingTransport{dir: dir, patterns: patterns, transport: transport}, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil || !t.matches(req.URL) {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	recording := Recording{
+		Method:     req.Method,
+		URL:        sanitiseURL(req.URL),
+		StatusCode: resp.StatusCode,
+		Headers:    make(map[string]string),
+		Body:       string(body),
+	}
+	for k, v := range resp.Header {
+		if len(v) > 0 && !isSensitiveHeader(k) {
+			recording.Headers[k] = v[0]
+		}
+	}
+	if err := writeRecording(filepath.Join(t.dir, RecordingName(recording.URL)), recording); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *RecordingTransport) matches(u *url.URL) bool {
+	for _, pattern := range t.patterns {
+		if cache.MatchURL(pattern, u) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeRecording(path string, recording Recording) error {
+	data, err := json.MarshalIndent(recording, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recording of %s: %w", recording.URL, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write recording of %s: %w", recording.URL, err)
+	}
+	return nil
+}
+
+func isSensitiveHeader(name string) bool {
+	for _, sensitive := range sensitiveHeaders {
+		if strings.EqualFold(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitiseURL returns u with the values of credential-like query parameters redacted
+func sanitiseURL(u *url.URL) string {
+	query := u.Query()
+	redacted := false
+	for param := range query {
+		for _, sensitive := range sensitiveParams {
+			if strings.EqualFold(param, sensitive) {
+				query.Set(param, "REDACTED")
+				redacted = true
+			}
+		}
+	}
+
+	sanitised := *u
+	sanitised.User = nil
+	if redacted {
+		sanitised.RawQuery = query.Encode()
+	}
+	return sanitised.String()
+}
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// RecordingName returns the file a recording of rawURL is saved as, e.g.
+// "api.mmoui.com-v4-game-WOW-filelist.json.json" for https://api.mmoui.com/v4/game/WOW/filelist.json
+func RecordingName(rawURL string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(rawURL, "https://"), "http://")
+	name = strings.Trim(unsafeFilenameChars.ReplaceAllString(name, "-"), "-")
+	return name + ".json"
+}
+
+// ReplayTransport serves the recordings made by RecordingTransport instead of making requests.
+// Requests without a recording fail with ErrNotRecorded.
+type ReplayTransport struct {
+	dir string
+}
+
+// NewReplayTransport creates a transport replaying the recordings in dir
+func NewReplayTransport(dir string) *ReplayTransport {
+	return &ReplayTransport{dir: dir}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rawURL := sanitiseURL(req.URL)
+	data, err := os.ReadFile(filepath.Join(t.dir, RecordingName(rawURL)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotRecorded, rawURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording of %s: %w", rawURL, err)
+	}
+
+	var recording Recording
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return nil, fmt.Errorf("failed to parse recording of %s: %w", rawURL, err)
+	}
+	if recording.Method != req.Method {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, req.Method, rawURL)
+	}
+
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", recording.StatusCode, http.StatusText(recording.StatusCode)),
+		StatusCode:    recording.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(strings.NewReader(recording.Body)),
+		ContentLength: int64(len(recording.Body)),
+		Request:       req,
+	}
+	for k, v := range recording.Headers {
+		resp.Header.Set(k, v)
+	}
+	return resp, nil
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordingTransport_Replay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("<h1>" + r.URL.Path + "</h1>"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorder, err := NewRecordingTransport(dir, []string{"addons/*"}, http.DefaultTransport)
+	if err != nil {
+		t.Fatalf("NewRecordingTransport() unexpected error: %v", err)
+	}
+	client := NewRealHTTPClient(recorder, "test")
+	ctx := context.Background()
+
+	projectURL := server.URL + "/addons/opie?token=secret"
+	recorded, err := client.Get(ctx, projectURL)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if string(recorded.Body) != "<h1>/addons/opie</h1>" {
+		t.Errorf("Get() body = %s, want the response passed on unchanged", recorded.Body)
+	}
+	if _, err := client.Get(ctx, server.URL+"/framexml/"); err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+
+	// Only the matching URL is recorded, without credentials
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("recorded %d files, want 1", len(entries))
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("recording contains credentials: %s", data)
+	}
+
+	replay := NewRealHTTPClient(NewReplayTransport(dir), "test")
+	replayed, err := replay.Get(ctx, projectURL)
+	if err != nil {
+		t.Fatalf("replayed Get() unexpected error: %v", err)
+	}
+	if replayed.StatusCode != http.StatusOK || string(replayed.Body) != string(recorded.Body) {
+		t.Errorf("replayed Get() = %d %s, want %d %s", replayed.StatusCode, replayed.Body, recorded.StatusCode, recorded.Body)
+	}
+	if replayed.Headers["Content-Type"] != "text/html" {
+		t.Errorf("replayed Content-Type = %q, want text/html", replayed.Headers["Content-Type"])
+	}
+
+	if _, err := replay.Get(ctx, server.URL+"/framexml/"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("replayed Get() of an unrecorded URL = %v, want %v", err, ErrNotRecorded)
+	}
+}
+
+func TestRecordingName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://api.mmoui.com/v4/game/WOW/filelist.json", "api.mmoui.com-v4-game-WOW-filelist.json.json"},
+		{"https://www.wowinterface.com/downloads/info8149", "www.wowinterface.com-downloads-info8149.json"},
+		{"https://www.wowinterface.com/downloads/index.php?cid=44&page=1", "www.wowinterface.com-downloads-index.php-cid-44-page-1.json"},
+	}
+
+	for _, tt := range tests {
+		if got := RecordingName(tt.url); got != tt.want {
+			t.Errorf("RecordingName(%s) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestNewRecordingTransport_InvalidPattern(t *testing.T) {
+	if _, err := NewRecordingTransport(t.TempDir(), []string{"[bad"}, http.DefaultTransport); err == nil {
+		t.Error("NewRecordingTransport() expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrUnexpectedContent is returned for a response that isn't the kind of content expected of its URL
+var ErrUnexpectedContent = errors.New("unexpected content")
+
+// ContentKind is what a response body looks like, as far as parsing it is concerned
+type ContentKind string
+
+const (
+	ContentHTML   ContentKind = "html"
+	ContentJSON   ContentKind = "json"
+	ContentText   ContentKind = "text"   // text that isn't a whole HTML document or JSON, such as a fragment of a page
+	ContentBinary ContentKind = "binary" // archives, images and the like
+)
+
+// Sniff guesses what body is from its first bytes, as net/http.DetectContentType does, telling JSON apart from text
+func Sniff(body []byte) ContentKind {
+	body = bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")) // a byte order mark hides what follows from DetectContentType
+	if trimmed := bytes.TrimLeft(body, " \t\r\n"); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return ContentJSON
+	}
+	contentType := http.DetectContentType(body)
+	switch {
+	case strings.HasPrefix(contentType, "text/html"):
+		return ContentHTML
+	case strings.HasPrefix(contentType, "text/"):
+		return ContentText
+	default:
+		return ContentBinary
+	}
+}
+
+// ExpectContent returns an error if body isn't want. HTML is only expected not to be JSON or binary, pages and
+// fragments of them don't always start the way Sniff recognises HTML.
+func ExpectContent(body []byte, want ContentKind) error {
+	got := Sniff(body)
+	if got == want || (want == ContentHTML && got == ContentText) {
+		return nil
+	}
+	return fmt.Errorf("%w: expected %s, got %s", ErrUnexpectedContent, want, got)
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want ContentKind
+	}{
+		{"page", "<!DOCTYPE html>\n<html><head><title>WoWInterface</title></head></html>", ContentHTML},
+		{"page with a byte order mark", "\xef\xbb\xbf<html><body></body></html>", ContentHTML},
+		{"object", `{"UID": "25078"}`, ContentJSON},
+		{"indented list", "\n  [{\"UID\": \"25078\"}]", ContentJSON},
+		{"fragment", `<span class="author">Fizzlemizz</span>`, ContentText},
+		{"plain text", "Service Unavailable", ContentText},
+		{"empty", "", ContentText},
+		{"zip", "PK\x03\x04\x14\x00\x00\x00\x08\x00", ContentBinary},
+		{"gzip", "\x1f\x8b\x08\x00\x00\x00\x00\x00", ContentBinary},
+	}
+	for _, tt := range tests {
+		if got := Sniff([]byte(tt.body)); got != tt.want {
+			t.Errorf("Sniff(%s) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExpectContent(t *testing.T) {
+	tests := []struct {
+		body    string
+		want    ContentKind
+		wantErr bool
+	}{
+		{`{"UID": "25078"}`, ContentJSON, false},
+		{"<html><body>Bad Gateway</body></html>", ContentJSON, true},
+		{"<html><body></body></html>", ContentHTML, false},
+		{`<div class="addon">`, ContentHTML, false},
+		{`[]`, ContentHTML, true},
+		{"PK\x03\x04\x14\x00", ContentHTML, true},
+	}
+	for _, tt := range tests {
+		err := ExpectContent([]byte(tt.body), tt.want)
+		if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrUnexpectedContent)) {
+			t.Errorf("ExpectContent(%q, %s) = %v, wantErr %v", tt.body, tt.want, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/cache"
+)
+
+// DefaultTimeout is how long a request may take, including reading the response, unless a TimeoutRule says otherwise
+const DefaultTimeout = 30 * time.Second
+
+// TimeoutRule gives requests to URLs matching Pattern Timeout to finish instead of the default timeout.
+// Pattern is matched as for cache.TTLRule.
+type TimeoutRule struct {
+	Pattern string
+	Timeout time.Duration
+}
+
+// DefaultTimeoutRules give the file lists, several megabytes each, longer than the small detail pages
+var DefaultTimeoutRules = []TimeoutRule{
+	{Pattern: "filelist.json", Timeout: 2 * time.Minute},
+}
+
+// String returns the rule as PATTERN=TIMEOUT, as parsed by ParseTimeoutRule
+func (r TimeoutRule) String() string {
+	return r.Pattern + "=" + r.Timeout.String()
+}
+
+// ParseTimeoutRule parses a rule written as PATTERN=TIMEOUT, e.g. "filelist.json=2m"
+func ParseTimeoutRule(s string) (TimeoutRule, error) {
+	pattern, timeoutStr, found := strings.Cut(s, "=")
+	pattern = strings.TrimSpace(pattern)
+	if !found || pattern == "" {
+		return TimeoutRule{}, fmt.Errorf("invalid timeout rule %q, expected PATTERN=TIMEOUT", s)
+	}
+	if err := cache.ValidatePattern(pattern); err != nil {
+		return TimeoutRule{}, fmt.Errorf("invalid timeout rule %q: %w", s, err)
+	}
+	timeout, err := time.ParseDuration(strings.TrimSpace(timeoutStr))
+	if err != nil || timeout <= 0 {
+		return TimeoutRule{}, fmt.Errorf("invalid timeout in timeout rule %q, expected a positive duration such as 2m", s)
+	}
+	return TimeoutRule{Pattern: pattern, Timeout: timeout}, nil
+}
+
+// requestTimeout returns the timeout of the first rule matching rawURL, timeout if none do
+func requestTimeout(timeout time.Duration, rules []TimeoutRule, rawURL string) time.Duration {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return timeout
+	}
+	for _, rule := range rules {
+		if cache.MatchURL(rule.Pattern, u) {
+			return rule.Timeout
+		}
+	}
+	return timeout
+}
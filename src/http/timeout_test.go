@@ -0,0 +1,54 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseTimeoutRule(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    TimeoutRule
+		wantErr bool
+	}{
+		{"filelist.json=2m", TimeoutRule{Pattern: "filelist.json", Timeout: 2 * time.Minute}, false},
+		{" downloads/info* = 45s ", TimeoutRule{Pattern: "downloads/info*", Timeout: 45 * time.Second}, false},
+		{"filelist.json", TimeoutRule{}, true},
+		{"filelist.json=0s", TimeoutRule{}, true},
+		{"filelist.json=2 minutes", TimeoutRule{}, true},
+		{"[=2m", TimeoutRule{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimeoutRule(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseTimeoutRule(%q) = %v, %v, want %v, wantErr %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	for _, rule := range DefaultTimeoutRules {
+		if parsed, err := ParseTimeoutRule(rule.String()); err != nil || parsed != rule {
+			t.Errorf("ParseTimeoutRule(%q) = %v, %v, want %v", rule.String(), parsed, err, rule)
+		}
+	}
+}
+
+func TestRealHTTPClient_SetTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewRealHTTPClient(server.Client().Transport, "test")
+	client.SetTimeouts(10*time.Millisecond, []TimeoutRule{{Pattern: "filelist.json", Timeout: time.Second}})
+
+	if _, err := client.Get(context.Background(), server.URL+"/filedetails/25078.json"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() of a slow detail = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if resp, err := client.Get(context.Background(), server.URL+"/v4/game/WoW/filelist.json"); err != nil || resp.StatusCode != 200 {
+		t.Errorf("Get() of a slow filelist = %v, %v, want it given longer", resp, err)
+	}
+}
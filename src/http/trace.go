@@ -0,0 +1,128 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"maps"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// HostTrace totals where the requests made to a host spent their time, as seen by net/http/httptrace.
+// Only requests made over a connection are counted, not those served from a cache.
+type HostTrace struct {
+	Requests        int64         // requests made over a connection
+	ReusedConns     int64         // requests made over a connection left open by an earlier request
+	DNSLookups      int64         // lookups of the host's addresses
+	DNS             time.Duration // total time spent on lookups
+	Connects        int64         // new connections
+	Connect         time.Duration // total time spent connecting
+	TLSHandshakes   int64
+	TLS             time.Duration // total time spent on TLS handshakes
+	Responses       int64         // requests answered, failed requests aren't
+	TimeToFirstByte time.Duration // total time from asking for a connection to the first byte of each response
+}
+
+// requestTrace is the trace of a single request. Connections may be dialled in parallel, hence the lock.
+type requestTrace struct {
+	mu                                        sync.Mutex
+	started, dnsStart, connectStart, tlsStart time.Time
+	dialling                                  bool // parallel attempts to connect are timed from the first
+	gotConn, reused, gotFirstByte             bool
+	dnsLookups, connects, tlsHandshakes       int
+	dns, connect, tls, firstByte              time.Duration
+}
+
+// clientTrace returns hooks recording into t
+func (t *requestTrace) clientTrace() *httptrace.ClientTrace {
+	lock := func(f func()) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		f()
+	}
+	return &httptrace.ClientTrace{
+		GetConn:  func(string) { lock(func() { t.started = time.Now() }) },
+		DNSStart: func(httptrace.DNSStartInfo) { lock(func() { t.dnsStart = time.Now() }) },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			lock(func() { t.dns += time.Since(t.dnsStart); t.dnsLookups++ })
+		},
+		ConnectStart: func(string, string) {
+			lock(func() {
+				if !t.dialling {
+					t.connectStart, t.dialling = time.Now(), true
+				}
+			})
+		},
+		ConnectDone: func(_, _ string, err error) {
+			lock(func() {
+				if err == nil && t.connects == 0 {
+					t.connect = time.Since(t.connectStart)
+					t.connects++
+				}
+			})
+		},
+		TLSHandshakeStart: func() { lock(func() { t.tlsStart = time.Now() }) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			lock(func() { t.tls += time.Since(t.tlsStart); t.tlsHandshakes++ })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			lock(func() { t.gotConn, t.reused = true, info.Reused })
+		},
+		GotFirstResponseByte: func() {
+			lock(func() { t.firstByte, t.gotFirstByte = time.Since(t.started), true })
+		},
+	}
+}
+
+// tracer totals the traces of requests by host. Safe for concurrent use.
+type tracer struct {
+	mu    sync.Mutex
+	hosts map[string]HostTrace
+}
+
+// withTrace traces the request made with the returned context, call done once the response is read
+func (tr *tracer) withTrace(ctx context.Context, host, url string) (context.Context, func()) {
+	trace := &requestTrace{}
+	ctx = httptrace.WithClientTrace(ctx, trace.clientTrace())
+	return ctx, func() {
+		trace.mu.Lock()
+		defer trace.mu.Unlock()
+		if !trace.gotConn {
+			return // served without a connection
+		}
+		slog.Debug("request trace", "url", url, "reused-connection", trace.reused,
+			"dns", trace.dns.Round(time.Millisecond), "connect", trace.connect.Round(time.Millisecond),
+			"tls", trace.tls.Round(time.Millisecond), "time-to-first-byte", trace.firstByte.Round(time.Millisecond))
+
+		tr.mu.Lock()
+		defer tr.mu.Unlock()
+		if tr.hosts == nil {
+			tr.hosts = make(map[string]HostTrace)
+		}
+		total := tr.hosts[host]
+		total.Requests++
+		if trace.reused {
+			total.ReusedConns++
+		}
+		total.DNSLookups += int64(trace.dnsLookups)
+		total.DNS += trace.dns
+		total.Connects += int64(trace.connects)
+		total.Connect += trace.connect
+		total.TLSHandshakes += int64(trace.tlsHandshakes)
+		total.TLS += trace.tls
+		if trace.gotFirstByte {
+			total.Responses++
+			total.TimeToFirstByte += trace.firstByte
+		}
+		tr.hosts[host] = total
+	}
+}
+
+// stats returns the totals of each host
+func (tr *tracer) stats() map[string]HostTrace {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return maps.Clone(tr.hosts)
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestRealHTTPClient_TraceStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	client := NewRealHTTPClient(server.Client().Transport, "test")
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get(context.Background(), server.URL+"/downloads/info25078"); err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+	}
+
+	trace := client.TraceStats()[serverURL.Host]
+	if trace.Requests != 3 || trace.ReusedConns != 2 || trace.Connects != 1 || trace.Responses != 3 {
+		t.Errorf("TraceStats()[%s] = %+v, want 3 requests over one connection", serverURL.Host, trace)
+	}
+	if trace.TimeToFirstByte <= 0 {
+		t.Errorf("TraceStats()[%s].TimeToFirstByte = %v, want the time taken", serverURL.Host, trace.TimeToFirstByte)
+	}
+
+	// Responses served without a connection aren't traced
+	replay := NewRealHTTPClient(NewReplayTransport(t.TempDir()), "test")
+	replay.Get(context.Background(), server.URL+"/downloads/info25078")
+	if stats := replay.TraceStats(); len(stats) != 0 {
+		t.Errorf("TraceStats() without a connection = %+v, want none", stats)
+	}
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// proxySchemes are the proxy URL schemes net/http can make requests through
+var proxySchemes = []string{"http", "https", "socks5"}
+
+// TransportConfig sets how requests reach the network
+type TransportConfig struct {
+	Proxy     *url.URL          // proxy requests are made through, $HTTPS_PROXY, $HTTP_PROXY and $NO_PROXY are used if nil
+	Resolve   map[string]string // host -> address connected to instead of the addresses the host's name resolves to
+	DNSServer string            // host:port of the DNS server names are resolved with, the system's resolver if empty
+
+	MaxIdleConns        int           // idle connections kept open across all hosts, 0 for no limit
+	MaxIdleConnsPerHost int           // idle connections kept open to each host
+	IdleConnTimeout     time.Duration // how long an idle connection is kept open, 0 for no limit
+}
+
+// DefaultTransportConfig keeps enough connections open for several workers to reuse them, without a proxy unless
+// the environment sets one
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
+// ParseProxy parses the URL of a proxy, e.g. http://proxy.example.org:3128 or socks5://127.0.0.1:1080
+func ParseProxy(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", s)
+	}
+	if !slices.Contains(proxySchemes, u.Scheme) {
+		return nil, fmt.Errorf("unsupported proxy scheme %q, must be one of: %s", u.Scheme, strings.Join(proxySchemes, ", "))
+	}
+	return u, nil
+}
+
+// ParseResolve parses a host override as HOST=ADDRESS, e.g. api.mmoui.com=203.0.113.7
+func ParseResolve(s string) (string, string, error) {
+	host, addr, ok := strings.Cut(s, "=")
+	if !ok || host == "" || net.ParseIP(addr) == nil {
+		return "", "", fmt.Errorf("invalid host override %q, expected HOST=IP", s)
+	}
+	return strings.ToLower(host), addr, nil
+}
+
+// ParseDNSServer parses the address of a DNS server as IP or IP:PORT, port 53 if not given
+func ParseDNSServer(s string) (string, error) {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		host, port = strings.Trim(s, "[]"), "53"
+	}
+	if net.ParseIP(host) == nil {
+		return "", fmt.Errorf("invalid DNS server %q, expected IP or IP:PORT", s)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// NewTransport returns a transport reaching the network as config says
+func NewTransport(config TransportConfig) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if config.DNSServer != "" {
+		dialer.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, config.DNSServer)
+			},
+		}
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if config.Proxy != nil {
+		proxy = http.ProxyURL(config.Proxy)
+	}
+
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			// The proxy's host is overridden too, as it's the one connected to
+			if host, port, err := net.SplitHostPort(addr); err == nil {
+				if override, ok := config.Resolve[strings.ToLower(host)]; ok {
+					addr = net.JoinHostPort(override, port)
+				}
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+		ForceAttemptHTTP2:   true, // as without a DialContext
+		MaxIdleConns:        config.MaxIdleConns,
+		MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
+		IdleConnTimeout:     config.IdleConnTimeout,
+	}
+}
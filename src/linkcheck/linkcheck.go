@@ -0,0 +1,172 @@
+// Package linkcheck checks the release download URLs of a releases manifest are still alive, following redirects
+// to find where they lead.
+package linkcheck
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/catalogue"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// MaxRedirects is the most redirects followed from a link before it's taken as broken
+const MaxRedirects = 5
+
+// Status is the outcome of checking a link
+type Status string
+
+const (
+	Alive      Status = "alive"
+	Redirected Status = "redirected" // alive, at the end of one or more redirects
+	Dead       Status = "dead"       // a client error, such as 404, or too many redirects
+	Failed     Status = "failed"     // a server or network error, the link may be alive once the host recovers
+)
+
+// KnownStatuses are every status, in the order they're reported
+var KnownStatuses = []Status{Dead, Failed, Redirected, Alive}
+
+// Link is the download URL of an addon's latest release for a game track
+type Link struct {
+	Source    types.Source
+	SourceID  string
+	GameTrack types.GameTrack
+	URL       string
+}
+
+// Result is what checking a link found
+type Result struct {
+	Link
+	Status     Status
+	StatusCode int    // of the last response, 0 if there wasn't one
+	Target     string // where the link's redirects led, empty if it wasn't redirected
+	Error      string // why there wasn't a response
+}
+
+// Links returns the download URL of each release in releases, ordered by source, source-id and game track
+func Links(releases catalogue.Releases) []Link {
+	var links []Link
+	for source, addons := range releases.ReleaseMap {
+		for sourceID, tracks := range addons {
+			for track, release := range tracks {
+				if release.DownloadURL != "" {
+					links = append(links, Link{Source: source, SourceID: sourceID, GameTrack: track, URL: release.DownloadURL})
+				}
+			}
+		}
+	}
+	slices.SortFunc(links, func(a, b Link) int {
+		return cmp.Or(cmp.Compare(a.Source, b.Source), cmp.Compare(a.SourceID, b.SourceID), cmp.Compare(a.GameTrack, b.GameTrack))
+	})
+	return links
+}
+
+// Sample returns n of links picked at random with seed, in the order of links. All of them if n is 0 or more than
+// there are.
+func Sample(links []Link, n int, seed uint64) []Link {
+	if n <= 0 || n >= len(links) {
+		return links
+	}
+	picked := rand.New(rand.NewPCG(seed, seed)).Perm(len(links))[:n]
+	slices.Sort(picked)
+	sample := make([]Link, n)
+	for i, index := range picked {
+		sample[i] = links[index]
+	}
+	return sample
+}
+
+// Check checks links with workers concurrent requests made by client, which is expected to make HEAD requests that
+// don't follow redirects (see http.NewHeadClient). Results are in the order of links.
+func Check(ctx context.Context, client http.HTTPClient, links []Link, workers int) []Result {
+	results := make([]Result, len(links))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = checkLink(ctx, client, links[i])
+			}
+		}()
+	}
+	for i := range links {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// checkLink requests link, following its redirects
+func checkLink(ctx context.Context, client http.HTTPClient, link Link) Result {
+	result := Result{Link: link}
+	current := link.URL
+	for redirects := 0; ; redirects++ {
+		resp, err := client.Get(ctx, current)
+		if err != nil {
+			result.Status, result.Error = Failed, err.Error()
+			return result
+		}
+		result.StatusCode = resp.StatusCode
+
+		location := resp.Headers["Location"]
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+			result.Status = statusOf(resp.StatusCode, redirects > 0)
+			return result
+		}
+		if redirects == MaxRedirects {
+			result.Status, result.Error = Dead, fmt.Sprintf("more than %d redirects", MaxRedirects)
+			return result
+		}
+		next, err := resolve(current, location)
+		if err != nil {
+			result.Status, result.Error = Dead, err.Error()
+			return result
+		}
+		current, result.Target = next, next
+	}
+}
+
+// statusOf returns the status of a link whose last response had statusCode
+func statusOf(statusCode int, redirected bool) Status {
+	switch {
+	case statusCode >= 500 || statusCode == 429:
+		return Failed
+	case statusCode >= 300:
+		return Dead
+	case redirected:
+		return Redirected
+	default:
+		return Alive
+	}
+}
+
+// resolve returns the URL location points to, relative to base
+func resolve(base, location string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse URL: %w", err)
+	}
+	locationURL, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse redirect location %q: %w", location, err)
+	}
+	return baseURL.ResolveReference(locationURL).String(), nil
+}
+
+// Counts returns the number of results with each status
+func Counts(results []Result) map[Status]int {
+	counts := make(map[Status]int)
+	for _, result := range results {
+		counts[result.Status]++
+	}
+	return counts
+}
@@ -0,0 +1,105 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/catalogue"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestLinks(t *testing.T) {
+	releases := catalogue.Releases{ReleaseMap: map[types.Source]map[string]map[types.GameTrack]types.Release{
+		types.WowInterfaceSource: {
+			"2": {types.RetailTrack: {DownloadURL: "https://example.org/2.zip"}},
+			"1": {types.RetailTrack: {DownloadURL: "https://example.org/1.zip"}, types.ClassicTrack: {DownloadURL: "https://example.org/1-classic.zip"}},
+			"3": {types.RetailTrack: {}},
+		},
+		types.GitHubSource: {"owner/repo": {types.RetailTrack: {DownloadURL: "https://example.org/repo.zip"}}},
+	}}
+
+	var got []string
+	for _, link := range Links(releases) {
+		got = append(got, link.URL)
+	}
+	want := []string{"https://example.org/repo.zip", "https://example.org/1-classic.zip", "https://example.org/1.zip", "https://example.org/2.zip"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Links() = %v, want %v", got, want)
+	}
+}
+
+func TestSample(t *testing.T) {
+	links := make([]Link, 10)
+	for i := range links {
+		links[i].SourceID = string(rune('a' + i))
+	}
+
+	sample := Sample(links, 3, 1)
+	if len(sample) != 3 {
+		t.Fatalf("Sample() = %d links, want 3", len(sample))
+	}
+	if !reflect.DeepEqual(sample, Sample(links, 3, 1)) {
+		t.Error("Sample() with the same seed picked different links")
+	}
+	for i := 1; i < len(sample); i++ {
+		if sample[i-1].SourceID >= sample[i].SourceID {
+			t.Errorf("Sample() = %v, want links in their original order", sample)
+		}
+	}
+	for _, n := range []int{0, 10, 11} {
+		if got := Sample(links, n, 1); len(got) != len(links) {
+			t.Errorf("Sample(%d) = %d links, want all %d", n, len(got), len(links))
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	client := http.NewMockHTTPClient()
+	client.SetResponse("https://example.org/alive.zip", &http.Response{StatusCode: 200})
+	client.SetResponse("https://example.org/gone.zip", &http.Response{StatusCode: 404})
+	client.SetResponse("https://example.org/busy.zip", &http.Response{StatusCode: 503})
+	client.SetError("https://example.org/offline.zip", errors.New("connection refused"))
+	client.SetResponse("https://example.org/getfile?id=1", &http.Response{StatusCode: 302, Headers: map[string]string{"Location": "/files/1.zip"}})
+	client.SetResponse("https://example.org/files/1.zip", &http.Response{StatusCode: 200})
+	client.SetResponse("https://example.org/moved?id=2", &http.Response{StatusCode: 301, Headers: map[string]string{"Location": "https://cdn.example.org/2.zip"}})
+	client.SetResponse("https://cdn.example.org/2.zip", &http.Response{StatusCode: 404})
+	client.SetResponse("https://example.org/loop", &http.Response{StatusCode: 302, Headers: map[string]string{"Location": "/loop"}})
+
+	tests := []struct {
+		url        string
+		status     Status
+		statusCode int
+		target     string
+	}{
+		{"https://example.org/alive.zip", Alive, 200, ""},
+		{"https://example.org/gone.zip", Dead, 404, ""},
+		{"https://example.org/busy.zip", Failed, 503, ""},
+		{"https://example.org/offline.zip", Failed, 0, ""},
+		{"https://example.org/getfile?id=1", Redirected, 200, "https://example.org/files/1.zip"},
+		{"https://example.org/moved?id=2", Dead, 404, "https://cdn.example.org/2.zip"},
+		{"https://example.org/loop", Dead, 302, "https://example.org/loop"},
+	}
+	links := make([]Link, len(tests))
+	for i, tt := range tests {
+		links[i] = Link{Source: types.WowInterfaceSource, SourceID: tt.url, URL: tt.url}
+	}
+
+	results := Check(context.Background(), client, links, 1)
+	for i, tt := range tests {
+		result := results[i]
+		if result.URL != tt.url || result.Status != tt.status || result.StatusCode != tt.statusCode || result.Target != tt.target {
+			t.Errorf("Check(%s) = %s %d %q, want %s %d %q", tt.url, result.Status, result.StatusCode, result.Target, tt.status, tt.statusCode, tt.target)
+		}
+	}
+	if results[3].Error == "" || results[6].Error == "" {
+		t.Errorf("Check() errors = %q, %q, want why the links failed", results[3].Error, results[6].Error)
+	}
+
+	want := map[Status]int{Alive: 1, Redirected: 1, Dead: 3, Failed: 2}
+	if got := Counts(results); !reflect.DeepEqual(got, want) {
+		t.Errorf("Counts() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,154 @@
+// Package lint finds addons in a catalogue whose data looks poor: the catalogue is valid, but users would notice.
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/description"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/normalise"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// Check is a quality heuristic addons are checked against
+type Check string
+
+const (
+	EmptyDescription Check = "empty-description"
+	PoorDescription  Check = "poor-description" // BBCode, a version number, a date or too short to say anything
+	OldCreatedDate   Check = "old-created-date" // before World of Warcraft was released
+	MissingTags      Check = "missing-tags"
+	NoGameTracks     Check = "no-game-tracks"
+	EncodingError    Check = "encoding-error" // in the label: invalid UTF-8, replacement characters or mojibake
+)
+
+// KnownChecks are every check, in the order they're reported
+var KnownChecks = []Check{EmptyDescription, PoorDescription, OldCreatedDate, MissingTags, NoGameTracks, EncodingError}
+
+// WoWRelease is the earliest an addon can have been created
+var WoWRelease = time.Date(2004, 11, 23, 0, 0, 0, 0, time.UTC)
+
+// DefaultThresholds are the percentages of addons that may fail each check before the catalogue fails.
+// Many addons never describe or tag themselves, encoding errors are always a bug of the builder.
+var DefaultThresholds = map[Check]float64{
+	EmptyDescription: 10,
+	PoorDescription:  20,
+	OldCreatedDate:   1,
+	MissingTags:      50,
+	NoGameTracks:     1,
+	EncodingError:    0,
+}
+
+// Finding is an addon failing a check
+type Finding struct {
+	Check    Check
+	Source   types.Source
+	SourceID string
+	Label    string
+	Detail   string // what failed the check, e.g. the description
+}
+
+// Report is the outcome of linting a catalogue
+type Report struct {
+	Addons   int
+	Counts   map[Check]int // addons failing each check
+	Findings []Finding     // by check, in the order of the catalogue
+}
+
+// Percent returns the percentage of addons failing check
+func (r Report) Percent(check Check) float64 {
+	if r.Addons == 0 {
+		return 0
+	}
+	return float64(r.Counts[check]) * 100 / float64(r.Addons)
+}
+
+// Exceeded returns the checks failed by more addons than thresholds allow, in the order of KnownChecks.
+// Checks without a threshold are never exceeded.
+func (r Report) Exceeded(thresholds map[Check]float64) []Check {
+	var exceeded []Check
+	for _, check := range KnownChecks {
+		if threshold, ok := thresholds[check]; ok && r.Percent(check) > threshold {
+			exceeded = append(exceeded, check)
+		}
+	}
+	return exceeded
+}
+
+// ParseThreshold parses a threshold written as CHECK=PERCENT, e.g. "missing-tags=25" or "missing-tags=25%"
+func ParseThreshold(s string) (Check, float64, error) {
+	checkStr, percentStr, found := strings.Cut(s, "=")
+	check := Check(strings.TrimSpace(checkStr))
+	if !found || !slices.Contains(KnownChecks, check) {
+		return "", 0, fmt.Errorf("invalid threshold %q, expected CHECK=PERCENT where CHECK is one of: %s", s, joinChecks(KnownChecks))
+	}
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(percentStr), "%"), 64)
+	if err != nil || percent < 0 || percent > 100 {
+		return "", 0, fmt.Errorf("invalid percentage in threshold %q, expected 0 to 100", s)
+	}
+	return check, percent, nil
+}
+
+// joinChecks lists checks for people
+func joinChecks(checks []Check) string {
+	names := make([]string, len(checks))
+	for i, check := range checks {
+		names[i] = string(check)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Lint checks each addon against every check
+func Lint(addons []types.Addon) Report {
+	report := Report{Addons: len(addons), Counts: make(map[Check]int)}
+	findings := make(map[Check][]Finding)
+	for _, addon := range addons {
+		for _, check := range KnownChecks {
+			detail, failed := run(check, addon)
+			if !failed {
+				continue
+			}
+			report.Counts[check]++
+			findings[check] = append(findings[check], Finding{
+				Check:    check,
+				Source:   addon.Source,
+				SourceID: addon.SourceID,
+				Label:    addon.Label,
+				Detail:   detail,
+			})
+		}
+	}
+	for _, check := range KnownChecks {
+		report.Findings = append(report.Findings, findings[check]...)
+	}
+	return report
+}
+
+// run returns whether addon fails check, and what failed it
+func run(check Check, addon types.Addon) (string, bool) {
+	switch check {
+	case EmptyDescription:
+		return "", strings.TrimSpace(addon.Description) == ""
+	case PoorDescription:
+		d := addon.Description
+		if strings.TrimSpace(d) == "" {
+			return "", false // reported as empty
+		}
+		return d, description.StripBBCode(d) != d || description.IsLowQuality(d)
+	case OldCreatedDate:
+		if addon.CreatedDate == nil {
+			return "", false
+		}
+		return addon.CreatedDate.Format(time.DateOnly), addon.CreatedDate.Before(WoWRelease)
+	case MissingTags:
+		return "", len(addon.TagList) == 0
+	case NoGameTracks:
+		return "", len(addon.GameTrackList) == 0
+	case EncodingError:
+		return addon.Label, normalise.HasEncodingErrors(addon.Label)
+	}
+	return "", false
+}
@@ -0,0 +1,101 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func addon(sourceID, label, description string) types.Addon {
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	return types.Addon{
+		Source:        types.WowInterfaceSource,
+		SourceID:      sourceID,
+		Label:         label,
+		Description:   description,
+		CreatedDate:   &created,
+		TagList:       []string{"bags"},
+		GameTrackList: []types.GameTrack{types.RetailTrack},
+	}
+}
+
+func TestLint(t *testing.T) {
+	good := addon("1", "AdiBags", "Adirelle's bag addon, sorting items into sections")
+	empty := addon("2", "Empty", "")
+	bbcode := addon("3", "Markup", "[b]Sorts[/b] your bags into sections for you")
+	version := addon("4", "Versioned", "1.2.3 fixed the sorting of bags")
+	old := addon("5", "Old", "An addon older than the game itself, surely")
+	veryOld := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	old.CreatedDate = &veryOld
+	untagged := addon("6", "Untagged", "Shows where each of your alts is logged out")
+	untagged.TagList = nil
+	trackless := addon("7", "Trackless", "Shows where each of your alts is logged out")
+	trackless.GameTrackList = []types.GameTrack{}
+	mojibake := addon("8", "CafÃ©", "Adds a café to every city in the game world")
+
+	report := Lint([]types.Addon{good, empty, bbcode, version, old, untagged, trackless, mojibake})
+
+	if report.Addons != 8 {
+		t.Errorf("Addons = %d, want 8", report.Addons)
+	}
+	wantCounts := map[Check]int{EmptyDescription: 1, PoorDescription: 2, OldCreatedDate: 1, MissingTags: 1, NoGameTracks: 1, EncodingError: 1}
+	if !reflect.DeepEqual(report.Counts, wantCounts) {
+		t.Errorf("Counts = %v, want %v", report.Counts, wantCounts)
+	}
+
+	var got []string
+	for _, finding := range report.Findings {
+		got = append(got, string(finding.Check)+" "+finding.SourceID)
+	}
+	want := []string{"empty-description 2", "poor-description 3", "poor-description 4", "old-created-date 5", "missing-tags 6", "no-game-tracks 7", "encoding-error 8"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Findings = %v, want %v", got, want)
+	}
+	if finding := report.Findings[3]; finding.Detail != "1970-01-01" {
+		t.Errorf("old-created-date Detail = %q, want the created date", finding.Detail)
+	}
+}
+
+func TestReport_Exceeded(t *testing.T) {
+	report := Report{Addons: 200, Counts: map[Check]int{MissingTags: 20, EncodingError: 1, NoGameTracks: 2}}
+
+	if got := report.Percent(MissingTags); got != 10 {
+		t.Errorf("Percent(%s) = %v, want 10", MissingTags, got)
+	}
+	// At the threshold is fine, only above it fails
+	thresholds := map[Check]float64{MissingTags: 10, EncodingError: 0, NoGameTracks: 0.5}
+	if got, want := report.Exceeded(thresholds), []Check{NoGameTracks, EncodingError}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Exceeded() = %v, want %v", got, want)
+	}
+	if got := report.Exceeded(nil); got != nil {
+		t.Errorf("Exceeded(nil) = %v, want none without thresholds", got)
+	}
+	if got := (Report{}).Percent(MissingTags); got != 0 {
+		t.Errorf("Percent() of an empty catalogue = %v, want 0", got)
+	}
+}
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantCheck   Check
+		wantPercent float64
+		wantErr     bool
+	}{
+		{"missing-tags=25", MissingTags, 25, false},
+		{"encoding-error=0%", EncodingError, 0, false},
+		{" poor-description = 12.5 ", PoorDescription, 12.5, false},
+		{"missing-tags", "", 0, true},
+		{"spelling=5", "", 0, true},
+		{"missing-tags=101", "", 0, true},
+		{"missing-tags=lots", "", 0, true},
+	}
+	for _, tt := range tests {
+		check, percent, err := ParseThreshold(tt.input)
+		if (err != nil) != tt.wantErr || check != tt.wantCheck || percent != tt.wantPercent {
+			t.Errorf("ParseThreshold(%q) = %s, %v, %v, want %s, %v, wantErr %v", tt.input, check, percent, err, tt.wantCheck, tt.wantPercent, tt.wantErr)
+		}
+	}
+}
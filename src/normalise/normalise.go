@@ -0,0 +1,96 @@
+// Package normalise cleans up scraped text: HTML entities, mis-decoded characters,
+// unicode normalisation and stray whitespace.
+package normalise
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// mojibakeMarkers are what the lead bytes of UTF-8 sequences look like when decoded as Windows-1252,
+// e.g. "Ã©" for "é" and "â€™" for "’"
+var mojibakeMarkers = []string{"Ã", "Â", "â€"}
+
+// Text normalises a single line of scraped text such as a label, description or tag.
+// Invalid UTF-8 is decoded as Windows-1252 (the WowInterface site's actual encoding),
+// HTML entities are decoded, common mojibake is repaired, the text is normalised to NFC
+// and runs of whitespace are collapsed to single spaces.
+func Text(s string) string {
+	if s == "" {
+		return s
+	}
+	s = UTF8(s)
+	s = unescape(s)
+	s = repairMojibake(s)
+	s = norm.NFC.String(s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// UTF8 returns s unchanged if it's valid UTF-8, otherwise each invalid byte is decoded as Windows-1252
+func UTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		if r == utf8.RuneError && size == 1 {
+			r = charmap.Windows1252.DecodeByte(s[0])
+		}
+		b.WriteRune(r)
+		s = s[size:]
+	}
+	return b.String()
+}
+
+// HasEncodingErrors returns true if s isn't valid UTF-8, holds a replacement character (U+FFFD) or is mojibake
+// that Text would repair
+func HasEncodingErrors(s string) bool {
+	return !utf8.ValidString(s) || strings.ContainsRune(s, utf8.RuneError) || repairMojibake(s) != s
+}
+
+// unescape decodes HTML entities, including entities that were escaped twice ("&amp;quot;")
+func unescape(s string) string {
+	for range 2 {
+		if !strings.Contains(s, "&") {
+			break
+		}
+		s = html.UnescapeString(s)
+	}
+	return s
+}
+
+// repairMojibake reverses UTF-8 text having been decoded as Windows-1252.
+// The text is only replaced when every character re-encodes and the result is valid UTF-8,
+// so text that merely contains an "Ã" is left alone.
+func repairMojibake(s string) string {
+	found := false
+	for _, marker := range mojibakeMarkers {
+		if strings.Contains(s, marker) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return s
+	}
+
+	encoded := make([]byte, 0, len(s))
+	for _, r := range s {
+		b, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			return s
+		}
+		encoded = append(encoded, b)
+	}
+	if !utf8.Valid(encoded) {
+		return s
+	}
+	return string(encoded)
+}
@@ -0,0 +1,52 @@
+package normalise
+
+import "testing"
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"unchanged", "Better Vendor Price", "Better Vendor Price"},
+		{"entities decoded", "Tom &amp; Jerry&#39;s &quot;Addon&quot;", `Tom & Jerry's "Addon"`},
+		{"double escaped entities", "Tom &amp;amp; Jerry", "Tom & Jerry"},
+		{"nbsp collapsed", "Bags&nbsp;&nbsp;Plus", "Bags Plus"},
+		{"whitespace collapsed", "  Bags \t\n Plus  ", "Bags Plus"},
+		{"latin-1 bytes decoded", "Caf\xe9 D\xe9j\xe0 Vu", "Café Déjà Vu"},
+		{"windows-1252 quotes decoded", "Don\x92t", "Don’t"},
+		{"mojibake repaired", "CafÃ© DÃ©jÃ\u00a0Vu", "Café DéjàVu"},
+		{"mojibake quote repaired", "Donâ€™t panic", "Don’t panic"},
+		{"legitimate Ã kept", "Ã is a letter", "Ã is a letter"},
+		{"nfc", "Cafe\u0301", "Caf\u00e9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Text(tt.input); got != tt.expected {
+				t.Errorf("Text(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasEncodingErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"Better Vendor Price", false},
+		{"Café Déjà Vu", false},
+		{"Ã is a letter", false},
+		{"Caf\xe9", true},
+		{"Caf�", true},
+		{"CafÃ©", true},
+		{"Donâ€™t panic", true},
+	}
+	for _, tt := range tests {
+		if got := HasEncodingErrors(tt.input); got != tt.want {
+			t.Errorf("HasEncodingErrors(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Package notify tells maintainers how an unattended scrape went by posting a summary to a webhook,
+// so a failing weekly run is noticed before the published catalogue goes stale.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// Format is the shape of the webhook's request body
+type Format string
+
+const (
+	JSONFormat    Format = "json"    // the summary as JSON, for anything that can parse it
+	DiscordFormat Format = "discord" // a Discord webhook message
+	MatrixFormat  Format = "matrix"  // a Matrix hookshot generic webhook message
+)
+
+var KnownFormats = []Format{JSONFormat, DiscordFormat, MatrixFormat}
+
+// discordMaxContent is the most characters a Discord message may have
+const discordMaxContent = 2000
+
+// Status is how a scrape ended
+type Status string
+
+const (
+	Finished Status = "finished"
+	Failed   Status = "failed"
+)
+
+// Summary is what's known about a scrape when it ends. A scrape that failed early has no catalogue counts.
+type Summary struct {
+	Status       Status               `json:"status"`
+	Error        string               `json:"error,omitempty"`
+	Sources      []types.Source       `json:"sources"`
+	StartedAt    time.Time            `json:"started-at"`
+	FinishedAt   time.Time            `json:"finished-at"`
+	Total        int                  `json:"total"`                   // addons in the full catalogue
+	SourceTotals map[types.Source]int `json:"source-totals,omitempty"` // addons in the full catalogue per source
+	Added        int                  `json:"added"`                   // addons added since the previous scrape
+	Updated      int                  `json:"updated"`
+	Removed      int                  `json:"removed"`
+	Failures     int                  `json:"failures"`              // URLs that couldn't be fetched or parsed
+	GatePassed   *bool                `json:"gate-passed,omitempty"` // nil if the publish gate didn't run
+}
+
+// Text describes the summary in a sentence or two, for chat messages
+func (s Summary) Text() string {
+	duration := s.FinishedAt.Sub(s.StartedAt).Round(time.Second)
+	if s.Status == Failed {
+		return fmt.Sprintf("strongbox catalogue scrape failed after %s: %s", duration, s.Error)
+	}
+
+	var sources []string
+	for source, total := range s.SourceTotals {
+		sources = append(sources, fmt.Sprintf("%s %d", source, total))
+	}
+	sort.Strings(sources)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "strongbox catalogue scrape finished in %s: %d addons", duration, s.Total)
+	if len(sources) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(sources, ", "))
+	}
+	fmt.Fprintf(&b, ", %d added, %d updated, %d removed since the last scrape, %d URLs failed.", s.Added, s.Updated, s.Removed, s.Failures)
+	if s.GatePassed != nil && !*s.GatePassed {
+		b.WriteString(" The catalogue failed the publish gate and won't be published.")
+	}
+	return b.String()
+}
+
+// Payload returns the webhook request body for the summary in format
+func Payload(format Format, summary Summary) ([]byte, error) {
+	var body any
+	switch format {
+	case JSONFormat:
+		body = summary
+	case DiscordFormat:
+		text := summary.Text()
+		if runes := []rune(text); len(runes) > discordMaxContent {
+			text = string(runes[:discordMaxContent-3]) + "..."
+		}
+		body = map[string]string{"content": text}
+	case MatrixFormat:
+		body = map[string]string{"text": summary.Text()}
+	default:
+		return nil, fmt.Errorf("unknown notification format: %s", format)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	return data, nil
+}
+
+// Notifier posts summaries to a webhook
+type Notifier struct {
+	URL    string
+	Format Format
+	client *http.Client
+}
+
+// New returns a notifier posting to webhookURL in format
+func New(webhookURL string, format Format) *Notifier {
+	return &Notifier{URL: webhookURL, Format: format, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Notify posts the summary to the webhook
+func (n *Notifier) Notify(ctx context.Context, summary Summary) error {
+	body, err := Payload(n.Format, summary)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// Webhook URLs hold their secret, keep it out of the logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to post notification: status %d", resp.StatusCode)
+	}
+	return nil
+}
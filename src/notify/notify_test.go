@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func testSummary() Summary {
+	started := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	return Summary{
+		Status:       Finished,
+		Sources:      []types.Source{types.WowInterfaceSource, types.GitHubSource},
+		StartedAt:    started,
+		FinishedAt:   started.Add(90 * time.Minute),
+		Total:        3,
+		SourceTotals: map[types.Source]int{types.WowInterfaceSource: 2, types.GitHubSource: 1},
+		Added:        1,
+		Updated:      2,
+		Failures:     4,
+	}
+}
+
+func TestSummary_Text(t *testing.T) {
+	passed, failed := true, false
+	finished := testSummary()
+	finished.GatePassed = &passed
+	rejected := testSummary()
+	rejected.GatePassed = &failed
+	aborted := testSummary()
+	aborted.Status, aborted.Error = Failed, "context deadline exceeded"
+
+	tests := []struct {
+		name    string
+		summary Summary
+		want    string
+	}{
+		{"finished", finished, "strongbox catalogue scrape finished in 1h30m0s: 3 addons (github 1, wowinterface 2), 1 added, 2 updated, 0 removed since the last scrape, 4 URLs failed."},
+		{"failed the gate", rejected, "strongbox catalogue scrape finished in 1h30m0s: 3 addons (github 1, wowinterface 2), 1 added, 2 updated, 0 removed since the last scrape, 4 URLs failed. The catalogue failed the publish gate and won't be published."},
+		{"failed", aborted, "strongbox catalogue scrape failed after 1h30m0s: context deadline exceeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.summary.Text(); got != tt.want {
+				t.Errorf("Text() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayload(t *testing.T) {
+	summary := testSummary()
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{DiscordFormat, `{"content":"` + summary.Text() + `"}`},
+		{MatrixFormat, `{"text":"` + summary.Text() + `"}`},
+	}
+	for _, tt := range tests {
+		got, err := Payload(tt.format, summary)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("Payload(%s) = %s, %v, want %s", tt.format, got, err, tt.want)
+		}
+	}
+
+	data, err := Payload(JSONFormat, summary)
+	if err != nil {
+		t.Fatalf("Payload(json) unexpected error: %v", err)
+	}
+	var decoded Summary
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Total != 3 || decoded.SourceTotals[types.GitHubSource] != 1 {
+		t.Errorf("Payload(json) = %s, %v, want the summary", data, err)
+	}
+
+	// Discord rejects long messages
+	long := summary
+	long.Status, long.Error = Failed, strings.Repeat("é", 3000)
+	data, err = Payload(DiscordFormat, long)
+	if err != nil {
+		t.Fatalf("Payload(discord) unexpected error: %v", err)
+	}
+	var message map[string]string
+	if err := json.Unmarshal(data, &message); err != nil || len([]rune(message["content"])) != discordMaxContent {
+		t.Errorf("Payload(discord) of a long summary has %d characters, want %d", len([]rune(message["content"])), discordMaxContent)
+	}
+
+	if _, err := Payload("slack", summary); err == nil {
+		t.Error("Payload() of an unknown format, expected an error")
+	}
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	var body string
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		if r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	summary := testSummary()
+	if err := New(server.URL+"/hook", MatrixFormat).Notify(context.Background(), summary); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+	if want := `{"text":"` + summary.Text() + `"}`; body != want {
+		t.Errorf("Notify() posted %s, want %s", body, want)
+	}
+
+	status = http.StatusNotFound
+	if err := New(server.URL+"/hook", JSONFormat).Notify(context.Background(), summary); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("Notify() to a missing webhook error = %v, want status 404", err)
+	}
+
+	// The webhook URL, and so its secret, isn't in errors
+	err := New("http://127.0.0.1:1/api/webhooks/1/secret", DiscordFormat).Notify(context.Background(), summary)
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("Notify() to an unreachable webhook error = %v, want an error without the URL", err)
+	}
+}
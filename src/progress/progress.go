@@ -0,0 +1,163 @@
+// Package progress tracks and reports the progress of a scrape: URLs discovered, completed and failed,
+// throughput and an estimate of the time remaining.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Snapshot is the progress of a scrape at a point in time
+type Snapshot struct {
+	Discovered int64 // completed + pending
+	Completed  int64 // including failed
+	Failed     int64
+	Pending    int64 // queued or in flight
+	Elapsed    time.Duration
+	Rate       float64       // completed URLs per second
+	ETA        time.Duration // zero until a rate is known
+}
+
+// Tracker counts completed URLs. Pending work is read from the caller's queue when a snapshot is taken.
+// Safe for concurrent use.
+type Tracker struct {
+	start     time.Time
+	now       func() time.Time
+	pending   func() int
+	completed atomic.Int64
+	failed    atomic.Int64
+}
+
+// NewTracker creates a tracker starting now. pending returns the number of URLs queued or in flight.
+func NewTracker(pending func() int) *Tracker {
+	return &Tracker{
+		start:   time.Now(),
+		now:     time.Now,
+		pending: pending,
+	}
+}
+
+// Done records a completed URL, failed if err is non-nil
+func (t *Tracker) Done(err error) {
+	t.completed.Add(1)
+	if err != nil {
+		t.failed.Add(1)
+	}
+}
+
+// Snapshot returns the current progress
+func (t *Tracker) Snapshot() Snapshot {
+	s := Snapshot{
+		Completed: t.completed.Load(),
+		Failed:    t.failed.Load(),
+		Pending:   int64(t.pending()),
+		Elapsed:   t.now().Sub(t.start),
+	}
+	s.Discovered = s.Completed + s.Pending
+
+	if seconds := s.Elapsed.Seconds(); seconds > 0 {
+		s.Rate = float64(s.Completed) / seconds
+	}
+	if s.Rate > 0 {
+		s.ETA = time.Duration(float64(s.Pending) / s.Rate * float64(time.Second))
+	}
+	return s
+}
+
+// Renderer displays progress snapshots
+type Renderer interface {
+	Render(s Snapshot)
+	Finish(s Snapshot)
+}
+
+// Report renders the tracker's progress every interval until stop is closed, then renders a final snapshot
+func Report(stop <-chan struct{}, tracker *Tracker, interval time.Duration, renderer Renderer) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			renderer.Render(tracker.Snapshot())
+		case <-stop:
+			renderer.Finish(tracker.Snapshot())
+			return
+		}
+	}
+}
+
+// LogRenderer renders progress as structured log entries
+type LogRenderer struct{}
+
+// Render logs a snapshot while there is work pending
+func (LogRenderer) Render(s Snapshot) {
+	if s.Pending == 0 {
+		return
+	}
+	slog.Info("scrape progress",
+		"discovered", s.Discovered,
+		"completed", s.Completed,
+		"failed", s.Failed,
+		"pending", s.Pending,
+		"rate", fmt.Sprintf("%.1f/s", s.Rate),
+		"eta", s.ETA.Round(time.Second))
+}
+
+// Finish logs the final snapshot
+func (LogRenderer) Finish(s Snapshot) {
+	slog.Info("scrape finished",
+		"completed", s.Completed,
+		"failed", s.Failed,
+		"elapsed", s.Elapsed.Round(time.Second),
+		"rate", fmt.Sprintf("%.1f/s", s.Rate))
+}
+
+// BarRenderer renders progress as a single, continually redrawn terminal line
+type BarRenderer struct {
+	w     io.Writer
+	width int
+}
+
+// NewBarRenderer creates a progress bar writing to w, typically a terminal
+func NewBarRenderer(w io.Writer) *BarRenderer {
+	return &BarRenderer{w: w, width: 30}
+}
+
+// Render redraws the progress bar
+func (b *BarRenderer) Render(s Snapshot) {
+	fmt.Fprintf(b.w, "\r\033[K%s", b.line(s))
+}
+
+// Finish draws the final progress bar and moves to a new line
+func (b *BarRenderer) Finish(s Snapshot) {
+	fmt.Fprintf(b.w, "\r\033[K%s\n", b.line(s))
+}
+
+func (b *BarRenderer) line(s Snapshot) string {
+	filled := 0
+	if s.Discovered > 0 {
+		filled = int(int64(b.width) * s.Completed / s.Discovered)
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", b.width-filled)
+
+	eta := "--"
+	if s.ETA > 0 {
+		eta = s.ETA.Round(time.Second).String()
+	}
+
+	return fmt.Sprintf("[%s] %d/%d  %d failed  %.1f/s  ETA %s", bar, s.Completed, s.Discovered, s.Failed, s.Rate, eta)
+}
+
+// IsTerminal returns true if f is an interactive terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
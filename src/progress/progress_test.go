@@ -0,0 +1,74 @@
+package progress
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracker_Snapshot(t *testing.T) {
+	pending := 30
+	tracker := NewTracker(func() int { return pending })
+	now := tracker.start.Add(10 * time.Second)
+	tracker.now = func() time.Time { return now }
+
+	for i := 0; i < 20; i++ {
+		tracker.Done(nil)
+	}
+	tracker.Done(errors.New("failed"))
+	tracker.Done(errors.New("failed"))
+
+	s := tracker.Snapshot()
+
+	if s.Completed != 22 {
+		t.Errorf("Completed = %d, want 22", s.Completed)
+	}
+	if s.Failed != 2 {
+		t.Errorf("Failed = %d, want 2", s.Failed)
+	}
+	if s.Discovered != 52 {
+		t.Errorf("Discovered = %d, want 52", s.Discovered)
+	}
+	if s.Rate != 2.2 {
+		t.Errorf("Rate = %v, want 2.2", s.Rate)
+	}
+	// 30 pending at 2.2/s
+	if want := 13636363636 * time.Nanosecond; s.ETA.Round(time.Millisecond) != want.Round(time.Millisecond) {
+		t.Errorf("ETA = %v, want %v", s.ETA, want)
+	}
+}
+
+func TestTracker_Snapshot_NoRate(t *testing.T) {
+	tracker := NewTracker(func() int { return 5 })
+	tracker.now = func() time.Time { return tracker.start }
+
+	s := tracker.Snapshot()
+	if s.Rate != 0 || s.ETA != 0 {
+		t.Errorf("Rate, ETA = %v, %v, want 0, 0", s.Rate, s.ETA)
+	}
+}
+
+func TestBarRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	bar := NewBarRenderer(&buf)
+
+	bar.Render(Snapshot{Discovered: 100, Completed: 50, Failed: 1, Pending: 50, Rate: 5, ETA: 10 * time.Second})
+	line := buf.String()
+
+	if !strings.Contains(line, "["+strings.Repeat("=", 15)+strings.Repeat(" ", 15)+"]") {
+		t.Errorf("bar not half full: %q", line)
+	}
+	for _, want := range []string{"50/100", "1 failed", "5.0/s", "ETA 10s"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("line %q missing %q", line, want)
+		}
+	}
+
+	buf.Reset()
+	bar.Finish(Snapshot{Discovered: 100, Completed: 100})
+	if !strings.HasSuffix(buf.String(), "\n") {
+		t.Errorf("Finish() = %q, want trailing newline", buf.String())
+	}
+}
@@ -0,0 +1,183 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	// GitHubAPIURL serves the GitHub REST API
+	GitHubAPIURL = "https://api.github.com"
+
+	// GitHubUploadURL receives release assets
+	GitHubUploadURL = "https://uploads.github.com"
+)
+
+// GitHubRelease publishes catalogues as the assets of a GitHub release, created if it doesn't exist
+type GitHubRelease struct {
+	Repo      string // owner/name
+	Tag       string // tag of the release, created on the default branch if missing
+	Name      string // title of the release, left alone if empty
+	APIURL    string
+	UploadURL string
+
+	token  string
+	client *http.Client
+}
+
+// NewGitHubRelease returns a target publishing to the release tagged tag in repo, authenticated with token
+func NewGitHubRelease(repo, tag, token string) *GitHubRelease {
+	return &GitHubRelease{
+		Repo:      repo,
+		Tag:       tag,
+		APIURL:    GitHubAPIURL,
+		UploadURL: GitHubUploadURL,
+		token:     token,
+		client:    &http.Client{Timeout: 5 * time.Minute}, // the full catalogue is tens of megabytes
+	}
+}
+
+// githubRelease is the part of a release the API returns that's needed to update it
+type githubRelease struct {
+	ID     int64         `json:"id"`
+	Name   string        `json:"name"`
+	Assets []githubAsset `json:"assets"`
+}
+
+// githubAsset is a file attached to a release
+type githubAsset struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Digest string `json:"digest"` // "sha256:<hex>", empty for assets uploaded before GitHub recorded digests
+}
+
+// githubError is the body of a failed API request
+type githubError struct {
+	Message string `json:"message"`
+}
+
+// errNotFound is returned by request for a 404 response
+var errNotFound = errors.New("not found")
+
+// Publish uploads files as assets of the release, replacing assets of the same name with different contents
+func (r *GitHubRelease) Publish(ctx context.Context, files []string) error {
+	if r.token == "" {
+		return errors.New("publishing to GitHub requires a token")
+	}
+
+	release, err := r.release(ctx)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]githubAsset, len(release.Assets))
+	for _, asset := range release.Assets {
+		existing[asset.Name] = asset
+	}
+
+	var counts result
+	for _, file := range files {
+		data, digest, err := readFile(file)
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Base(file)
+		if asset, ok := existing[name]; ok {
+			if asset.Digest == "sha256:"+digest {
+				slog.Debug("release asset unchanged", "asset", name)
+				counts.unchanged++
+				continue
+			}
+			deleteURL := r.APIURL + "/repos/" + r.Repo + "/releases/assets/" + strconv.FormatInt(asset.ID, 10)
+			if err := r.request(ctx, http.MethodDelete, deleteURL, "", nil, nil); err != nil {
+				return fmt.Errorf("failed to replace release asset %s: %w", name, err)
+			}
+		}
+
+		uploadURL := r.UploadURL + "/repos/" + r.Repo + "/releases/" + strconv.FormatInt(release.ID, 10) + "/assets?name=" + url.QueryEscape(name)
+		if err := r.request(ctx, http.MethodPost, uploadURL, contentType(file), data, nil); err != nil {
+			return fmt.Errorf("failed to upload release asset %s: %w", name, err)
+		}
+		slog.Info("uploaded release asset", "asset", name, "bytes", len(data))
+		counts.uploaded++
+	}
+
+	slog.Info("published catalogues to GitHub release", "repo", r.Repo, "tag", r.Tag, "uploaded", counts.uploaded, "unchanged", counts.unchanged)
+	return nil
+}
+
+// release returns the release tagged r.Tag, creating it, or renaming it to r.Name, as needed
+func (r *GitHubRelease) release(ctx context.Context) (githubRelease, error) {
+	var release githubRelease
+	err := r.request(ctx, http.MethodGet, r.APIURL+"/repos/"+r.Repo+"/releases/tags/"+url.PathEscape(r.Tag), "", nil, &release)
+	switch {
+	case errors.Is(err, errNotFound):
+		body, _ := json.Marshal(map[string]string{"tag_name": r.Tag, "name": r.Name})
+		if err := r.request(ctx, http.MethodPost, r.APIURL+"/repos/"+r.Repo+"/releases", "application/json", body, &release); err != nil {
+			return release, fmt.Errorf("failed to create release %s of %s: %w", r.Tag, r.Repo, err)
+		}
+		slog.Info("created GitHub release", "repo", r.Repo, "tag", r.Tag)
+
+	case err != nil:
+		return release, fmt.Errorf("failed to fetch release %s of %s: %w", r.Tag, r.Repo, err)
+
+	case r.Name != "" && release.Name != r.Name:
+		body, _ := json.Marshal(map[string]string{"name": r.Name})
+		releaseURL := r.APIURL + "/repos/" + r.Repo + "/releases/" + strconv.FormatInt(release.ID, 10)
+		if err := r.request(ctx, http.MethodPatch, releaseURL, "application/json", body, nil); err != nil {
+			return release, fmt.Errorf("failed to rename release %s of %s: %w", r.Tag, r.Repo, err)
+		}
+	}
+	return release, nil
+}
+
+// request makes an authenticated API request, decoding a JSON response into out if it isn't nil
+func (r *GitHubRelease) request(ctx context.Context, method, requestURL, contentType string, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+r.token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to %s %s: %w", method, requestURL, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr githubError
+		_ = json.Unmarshal(data, &apiErr)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, apiErr.Message)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package publish
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitHub serves the release endpoints publishing uses, for a single repo
+type fakeGitHub struct {
+	mu       sync.Mutex
+	release  *githubRelease
+	assets   map[string][]byte // name -> contents
+	assetIDs map[string]int64
+	requests []string // "METHOD path" of each request
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"message": "Bad credentials"}`)
+		return
+	}
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/ogri-la/strongbox-catalogue/releases/tags/catalogue":
+		if f.release == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(f.current())
+
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/ogri-la/strongbox-catalogue/releases":
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		f.release = &githubRelease{ID: 1, Name: body["name"]}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(f.current())
+
+	case r.Method == http.MethodPatch && r.URL.Path == "/repos/ogri-la/strongbox-catalogue/releases/1":
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		f.release.Name = body["name"]
+		json.NewEncoder(w).Encode(f.current())
+
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/repos/ogri-la/strongbox-catalogue/releases/assets/"):
+		for _, asset := range f.release.Assets {
+			if r.URL.Path == fmt.Sprintf("/repos/ogri-la/strongbox-catalogue/releases/assets/%d", asset.ID) {
+				delete(f.assets, asset.Name)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/ogri-la/strongbox-catalogue/releases/1/assets":
+		name := r.URL.Query().Get("name")
+		if _, ok := f.assets[name]; ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message": "Validation Failed"}`)
+			return
+		}
+		f.assets[name], _ = io.ReadAll(r.Body)
+		f.assetIDs[name] = int64(len(f.requests))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// current returns the release with its assets as the API lists them
+func (f *fakeGitHub) current() githubRelease {
+	release := *f.release
+	release.Assets = nil
+	for name, data := range f.assets {
+		digest := sha256.Sum256(data)
+		release.Assets = append(release.Assets, githubAsset{ID: f.assetIDs[name], Name: name, Digest: "sha256:" + hex.EncodeToString(digest[:])})
+	}
+	f.release.Assets = release.Assets
+	return release
+}
+
+func newFakeGitHub() *fakeGitHub {
+	return &fakeGitHub{assets: map[string][]byte{}, assetIDs: map[string]int64{}}
+}
+
+func newTestRelease(t *testing.T, fake *fakeGitHub) *GitHubRelease {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	target := NewGitHubRelease("ogri-la/strongbox-catalogue", "catalogue", "token")
+	target.Name = "Catalogue 2024-01-02"
+	target.APIURL, target.UploadURL = server.URL, server.URL
+	return target
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) []string {
+	t.Helper()
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestGitHubRelease_Publish(t *testing.T) {
+	dir := t.TempDir()
+	fake := newFakeGitHub()
+	target := newTestRelease(t, fake)
+	files := writeFiles(t, dir, map[string]string{
+		"full-catalogue.json":  `{"total": 2}`,
+		"short-catalogue.json": `{"total": 1}`,
+	})
+
+	// The release is created and each file uploaded
+	if err := target.Publish(context.Background(), files); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+	if fake.release == nil || fake.release.Name != "Catalogue 2024-01-02" {
+		t.Fatalf("release = %+v, want one named Catalogue 2024-01-02", fake.release)
+	}
+	if len(fake.assets) != 2 || string(fake.assets["full-catalogue.json"]) != `{"total": 2}` {
+		t.Errorf("assets = %v, want both catalogues", fake.assets)
+	}
+
+	// Publishing the same files again only looks the release up
+	fake.requests = nil
+	if err := target.Publish(context.Background(), files); err != nil {
+		t.Fatalf("Publish() again unexpected error: %v", err)
+	}
+	if len(fake.requests) != 1 {
+		t.Errorf("Publish() of unchanged files made requests %v, want just the lookup", fake.requests)
+	}
+
+	// A changed file replaces its asset and a new release name is applied
+	writeFiles(t, dir, map[string]string{"short-catalogue.json": `{"total": 3}`})
+	target.Name = "Catalogue 2024-01-09"
+	fake.requests = nil
+	if err := target.Publish(context.Background(), files); err != nil {
+		t.Fatalf("Publish() of a changed file unexpected error: %v", err)
+	}
+	if string(fake.assets["short-catalogue.json"]) != `{"total": 3}` {
+		t.Errorf("short-catalogue.json asset = %s, want the changed file", fake.assets["short-catalogue.json"])
+	}
+	if fake.release.Name != "Catalogue 2024-01-09" {
+		t.Errorf("release name = %s, want Catalogue 2024-01-09", fake.release.Name)
+	}
+	if len(fake.requests) != 4 {
+		t.Errorf("Publish() of a changed file made requests %v, want a lookup, rename, delete and upload", fake.requests)
+	}
+}
+
+func TestGitHubRelease_Publish_Errors(t *testing.T) {
+	dir := t.TempDir()
+	files := writeFiles(t, dir, map[string]string{"full-catalogue.json": `{}`})
+
+	target := newTestRelease(t, newFakeGitHub())
+	target.token = "wrong"
+	if err := target.Publish(context.Background(), files); err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("Publish() with a bad token error = %v, want Bad credentials", err)
+	}
+
+	target.token = ""
+	if err := target.Publish(context.Background(), files); err == nil {
+		t.Error("Publish() without a token, expected an error")
+	}
+
+	target = newTestRelease(t, newFakeGitHub())
+	if err := target.Publish(context.Background(), []string{filepath.Join(dir, "missing.json")}); err == nil {
+		t.Error("Publish() of a missing file, expected an error")
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := map[string]string{
+		"full-catalogue.json":         "application/json",
+		"catalogue.sha256":            "text/plain; charset=utf-8",
+		"full-catalogue.json.minisig": "text/plain; charset=utf-8",
+		"catalogue.db":                "application/octet-stream",
+	}
+	for path, want := range tests {
+		if got := contentType(path); got != want {
+			t.Errorf("contentType(%s) = %s, want %s", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Package publish uploads the catalogues of a scrape to where strongbox users download them from.
+//
+// Targets replace files published earlier under the same name and leave unchanged files alone,
+// so a failed or repeated publish can simply be run again.
+package publish
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Target is somewhere catalogues are published
+type Target interface {
+	// Publish uploads each file under its base name
+	Publish(ctx context.Context, files []string) error
+}
+
+// result counts what a publish did
+type result struct {
+	uploaded  int
+	unchanged int
+}
+
+// contentType returns the media type a file is served with
+func contentType(path string) string {
+	switch filepath.Ext(path) {
+	case ".json":
+		return "application/json"
+	case ".sha256", ".minisig":
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// readFile returns the contents of the file at path and their hex sha256 digest
+func readFile(path string) ([]byte, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s to publish: %w", path, err)
+	}
+	return data, sha256Hex(data), nil
+}
+
+// sha256Hex returns the hex sha256 digest of data
+func sha256Hex(data []byte) string {
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,252 @@
+package publish
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// S3Scheme prefixes targets naming an S3 bucket, e.g. s3://bucket/prefix
+	S3Scheme = "s3://"
+
+	// DefaultS3Region is the region requests are signed for when none is configured
+	DefaultS3Region = "us-east-1"
+
+	// s3DigestHeader holds the sha256 of an object's uncompressed contents, to skip unchanged uploads
+	s3DigestHeader = "X-Amz-Meta-Sha256"
+)
+
+// S3Credentials sign requests to S3
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // for temporary credentials, optional
+}
+
+// S3CredentialsFromEnv returns the credentials in the standard AWS environment variables
+func S3CredentialsFromEnv() S3Credentials {
+	return S3Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// ParseS3URL returns the bucket and key prefix of an s3://bucket/prefix target
+func ParseS3URL(target string) (string, string, error) {
+	rest, ok := strings.CutPrefix(target, S3Scheme)
+	bucket, prefix, _ := strings.Cut(rest, "/")
+	if !ok || bucket == "" {
+		return "", "", fmt.Errorf("invalid S3 target %q, expected s3://bucket/prefix", target)
+	}
+	return bucket, strings.Trim(prefix, "/"), nil
+}
+
+// S3 publishes catalogues as objects in an S3 bucket, or one of an S3-compatible object store.
+// Text files are stored gzip encoded.
+type S3 struct {
+	Bucket       string
+	Prefix       string // key prefix of uploaded objects, without a trailing slash, optional
+	Region       string
+	Endpoint     string // URL of an S3-compatible store, AWS if empty. objects are addressed path-style
+	CacheControl string // Cache-Control of uploaded objects, optional
+
+	credentials S3Credentials
+	client      *http.Client
+	now         func() time.Time
+}
+
+// NewS3 returns a target publishing to bucket under prefix, signing requests with credentials
+func NewS3(bucket, prefix string, credentials S3Credentials) *S3 {
+	return &S3{
+		Bucket:      bucket,
+		Prefix:      prefix,
+		Region:      DefaultS3Region,
+		credentials: credentials,
+		client:      &http.Client{Timeout: 5 * time.Minute},
+		now:         time.Now,
+	}
+}
+
+// objectURL returns the URL of the object at key
+func (s *S3) objectURL(key string) string {
+	escaped := (&url.URL{Path: "/" + key}).EscapedPath()
+	if s.Endpoint == "" {
+		return "https://" + s.Bucket + ".s3." + s.Region + ".amazonaws.com" + escaped
+	}
+	return strings.TrimSuffix(s.Endpoint, "/") + "/" + s.Bucket + escaped
+}
+
+// Publish uploads files as objects named after them, skipping objects already holding the same contents
+func (s *S3) Publish(ctx context.Context, files []string) error {
+	if s.credentials.AccessKeyID == "" || s.credentials.SecretAccessKey == "" {
+		return errors.New("publishing to S3 requires $AWS_ACCESS_KEY_ID and $AWS_SECRET_ACCESS_KEY")
+	}
+
+	var counts result
+	for _, file := range files {
+		data, digest, err := readFile(file)
+		if err != nil {
+			return err
+		}
+
+		key := path.Join(s.Prefix, filepath.Base(file))
+		resp, err := s.request(ctx, http.MethodHead, key, nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to check object %s: %w", key, err)
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			if resp.Header.Get(s3DigestHeader) == digest {
+				slog.Debug("object unchanged", "key", key)
+				counts.unchanged++
+				continue
+			}
+		case http.StatusNotFound, http.StatusForbidden:
+			// Without s3:ListBucket a missing object is forbidden rather than not found, the upload fails if
+			// the credentials really can't write it
+		default:
+			return fmt.Errorf("failed to check object %s: status %d", key, resp.StatusCode)
+		}
+
+		header := http.Header{}
+		header.Set("Content-Type", contentType(file))
+		header.Set(s3DigestHeader, digest)
+		if s.CacheControl != "" {
+			header.Set("Cache-Control", s.CacheControl)
+		}
+		body := data
+		if compressible(file) {
+			if body, err = gzipBytes(data); err != nil {
+				return fmt.Errorf("failed to compress %s: %w", file, err)
+			}
+			header.Set("Content-Encoding", "gzip")
+		}
+
+		resp, err = s.request(ctx, http.MethodPut, key, header, body)
+		if err != nil {
+			return fmt.Errorf("failed to upload object %s: %w", key, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("failed to upload object %s: status %d", key, resp.StatusCode)
+		}
+		slog.Info("uploaded object", "key", key, "bytes", len(body))
+		counts.uploaded++
+	}
+
+	slog.Info("published catalogues to S3", "bucket", s.Bucket, "prefix", s.Prefix, "uploaded", counts.uploaded, "unchanged", counts.unchanged)
+	return nil
+}
+
+// request makes a signed request for the object at key, leaving its status to the caller. The response body is
+// discarded.
+func (s *S3) request(ctx context.Context, method, key string, header http.Header, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	signV4(req, body, s.credentials, s.Region, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s %s: %w", method, req.URL, err)
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return resp, nil
+}
+
+// compressible reports whether a file is text worth storing gzip encoded
+func compressible(path string) bool {
+	return !strings.HasPrefix(contentType(path), "application/octet-stream")
+}
+
+// gzipBytes compresses data. The gzip header is left empty so the same data always compresses the same.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// signV4 signs req with AWS Signature Version 4, covering the host and every header set on req
+func signV4(req *http.Request, body []byte, credentials S3Credentials, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	for _, part := range []string{region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+credentials.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
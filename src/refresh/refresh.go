@@ -0,0 +1,91 @@
+// Package refresh remembers when the details of each addon were last fetched, so later scrapes can leave addons
+// refreshed recently alone whatever the HTTP cache holds.
+package refresh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Times is when the details of each addon were last fetched, by source-id. Safe for concurrent use.
+type Times struct {
+	mu    sync.Mutex
+	times map[string]time.Time
+}
+
+// NewTimes creates times of no addons
+func NewTimes() *Times {
+	return &Times{times: make(map[string]time.Time)}
+}
+
+// Read reads the times written to path by Write. A missing file has no times.
+func Read(path string) (*Times, error) {
+	t := NewTimes()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read refresh times %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &t.times); err != nil {
+		return nil, fmt.Errorf("failed to parse refresh times %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// Set records the details of the addon with sourceID being fetched at
+func (t *Times) Set(sourceID string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.times[sourceID] = at
+}
+
+// Fresh reports whether the details of the addon with sourceID were fetched less than maxAge before now
+func (t *Times) Fresh(sourceID string, maxAge time.Duration, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	at, ok := t.times[sourceID]
+	return ok && now.Sub(at) < maxAge
+}
+
+// Retain forgets every addon but those with the given source-ids, e.g. those no longer in the catalogue
+func (t *Times) Retain(sourceIDs []string) {
+	keep := make(map[string]bool, len(sourceIDs))
+	for _, sourceID := range sourceIDs {
+		keep[sourceID] = true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for sourceID := range t.times {
+		if !keep[sourceID] {
+			delete(t.times, sourceID)
+		}
+	}
+}
+
+// Len returns the number of addons with a time
+func (t *Times) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.times)
+}
+
+// Write writes the times to path as indented JSON, an object of source-id to time
+func (t *Times) Write(path string) error {
+	t.mu.Lock()
+	data, err := json.MarshalIndent(t.times, "", "  ")
+	t.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal refresh times: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write refresh times to %s: %w", path, err)
+	}
+	return nil
+}
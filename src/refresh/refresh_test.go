@@ -0,0 +1,58 @@
+package refresh
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTimes_Fresh(t *testing.T) {
+	times := NewTimes()
+	fetched := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	times.Set("8149", fetched)
+
+	tests := []struct {
+		sourceID string
+		now      time.Time
+		want     bool
+	}{
+		{"8149", fetched.Add(time.Hour), true},
+		{"8149", fetched.Add(7 * 24 * time.Hour), false}, // due a refresh
+		{"23145", fetched.Add(time.Hour), false},
+	}
+	for _, tt := range tests {
+		if got := times.Fresh(tt.sourceID, 7*24*time.Hour, tt.now); got != tt.want {
+			t.Errorf("Fresh(%s, 7d, %v) = %v, want %v", tt.sourceID, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestTimes_WriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "refreshed.json")
+
+	// Missing file
+	times, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() of a missing file unexpected error: %v", err)
+	}
+	if times.Len() != 0 {
+		t.Errorf("Read() of a missing file has %d times, want none", times.Len())
+	}
+
+	fetched := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	times.Set("8149", fetched)
+	times.Set("23145", fetched)
+	times.Set("removed", fetched)
+	times.Retain([]string{"8149", "23145"})
+	if err := times.Write(path); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+
+	read, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() unexpected error: %v", err)
+	}
+	if read.Len() != 2 || !read.Fresh("8149", time.Hour, fetched) || read.Fresh("removed", time.Hour, fetched) {
+		t.Errorf("Read() = %v, want the retained times", read.times)
+	}
+}
@@ -0,0 +1,163 @@
+// Package release works out which game tracks an addon release supports from its assets,
+// for sources that distribute addons as release assets built by the BigWigs packager or similar:
+// a release.json describing each zip, or zips named after the flavor they're for.
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// JSONName is the asset describing a release's zips and the flavors they support
+const JSONName = "release.json"
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name          string
+	URL           string
+	DownloadCount int
+}
+
+// releaseJSON is the release.json written by the BigWigs packager
+type releaseJSON struct {
+	Releases []struct {
+		Filename string `json:"filename"`
+		NoLib    bool   `json:"nolib"`
+		Metadata []struct {
+			Flavor string `json:"flavor"`
+		} `json:"metadata"`
+	} `json:"releases"`
+}
+
+// FlavorGameTrack maps a packager flavor or a zip name suffix to a game track, empty if it isn't one
+func FlavorGameTrack(flavor string) types.GameTrack {
+	switch strings.ToLower(strings.TrimSpace(flavor)) {
+	case "mainline", "retail":
+		return types.RetailTrack
+	case "classic", "vanilla":
+		return types.ClassicTrack
+	case "bcc", "tbc":
+		return types.ClassicTBCTrack
+	case "wrath", "wotlk":
+		return types.ClassicWotLKTrack
+	case "cata", "cataclysm":
+		return types.ClassicCataTrack
+	case "mists", "mop":
+		return types.ClassicMistsTrack
+	default:
+		return ""
+	}
+}
+
+// IsAddonRelease returns true if the assets include a release.json or a zip
+func IsAddonRelease(assets []Asset) bool {
+	for _, asset := range assets {
+		if asset.Name == JSONName || isZip(asset.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseJSON returns the game tracks supported by the zips described in a release.json, sorted
+func ParseJSON(data []byte) ([]types.GameTrack, error) {
+	var parsed releaseJSON
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", JSONName, err)
+	}
+
+	var tracks []types.GameTrack
+	for _, release := range parsed.Releases {
+		if release.NoLib {
+			continue // same flavors as the release with libraries
+		}
+		for _, metadata := range release.Metadata {
+			if track := FlavorGameTrack(metadata.Flavor); track != "" {
+				tracks = append(tracks, track)
+			}
+		}
+	}
+	return sortTracks(tracks), nil
+}
+
+// ZipGameTracks returns the game tracks of zips named after their flavor, e.g. "Addon-1.2-classic.zip", sorted.
+// An unsuffixed zip alongside suffixed ones is the retail build. A lone unsuffixed zip could be for anything,
+// so no game tracks are returned and the addon is left for classification.
+func ZipGameTracks(assets []Asset) []types.GameTrack {
+	var tracks []types.GameTrack
+	unsuffixed := false
+	for _, asset := range assets {
+		if !isZip(asset.Name) {
+			continue
+		}
+		name := strings.ToLower(asset.Name)
+		name = strings.TrimSuffix(name, path.Ext(name))
+		name = strings.TrimSuffix(name, "-nolib")
+
+		track := types.GameTrack("")
+		if i := strings.LastIndexAny(name, "-_"); i >= 0 {
+			track = FlavorGameTrack(name[i+1:])
+		}
+		if track == "" {
+			unsuffixed = true
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+
+	if unsuffixed && len(tracks) > 0 {
+		tracks = append(tracks, types.RetailTrack)
+	}
+	return sortTracks(tracks)
+}
+
+// GameTracks returns the game tracks supported by a release, from its release.json if it has one
+// (fetched with client) otherwise from the names of its zips
+func GameTracks(ctx context.Context, client httpclient.HTTPClient, assets []Asset) ([]types.GameTrack, error) {
+	for _, asset := range assets {
+		if asset.Name != JSONName {
+			continue
+		}
+
+		resp, err := client.Get(ctx, asset.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", asset.URL, err)
+		}
+		if resp.StatusCode != 200 {
+			return nil, fmt.Errorf("failed to fetch %s: status %d", asset.URL, resp.StatusCode)
+		}
+		return ParseJSON(resp.Body)
+	}
+	return ZipGameTracks(assets), nil
+}
+
+// DownloadCount sums the download counts of a release's zips
+func DownloadCount(assets []Asset) int {
+	total := 0
+	for _, asset := range assets {
+		if isZip(asset.Name) {
+			total += asset.DownloadCount
+		}
+	}
+	return total
+}
+
+func isZip(name string) bool {
+	return strings.EqualFold(path.Ext(name), ".zip")
+}
+
+// sortTracks sorts and removes duplicate game tracks, matching the other sources' ordering
+func sortTracks(tracks []types.GameTrack) []types.GameTrack {
+	if tracks == nil {
+		return []types.GameTrack{}
+	}
+	slices.Sort(tracks)
+	return slices.Compact(tracks)
+}
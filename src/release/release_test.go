@@ -0,0 +1,69 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestZipGameTracks(t *testing.T) {
+	tests := []struct {
+		name     string
+		assets   []string
+		expected []types.GameTrack
+	}{
+		{"lone unsuffixed zip", []string{"Addon-1.0.zip"}, []types.GameTrack{}},
+		{"flavored zips", []string{"Addon-1.0.zip", "Addon-1.0-classic.zip", "Addon-1.0-cata.zip"}, []types.GameTrack{types.ClassicTrack, types.ClassicCataTrack, types.RetailTrack}},
+		{"nolib zips", []string{"Addon-1.0-bcc.zip", "Addon-1.0-bcc-nolib.zip"}, []types.GameTrack{types.ClassicTBCTrack}},
+		{"underscore suffix", []string{"Addon_Mainline.ZIP"}, []types.GameTrack{types.RetailTrack}},
+		{"not zips", []string{"Addon-1.0-classic.tar.gz", "checksums.txt"}, []types.GameTrack{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assets []Asset
+			for _, name := range tt.assets {
+				assets = append(assets, Asset{Name: name})
+			}
+			if got := ZipGameTracks(assets); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ZipGameTracks(%v) = %v, want %v", tt.assets, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	data := []byte(`{"releases": [
+		{"filename": "Addon.zip", "nolib": false, "metadata": [{"flavor": "mainline"}, {"flavor": "classic"}, {"flavor": "unknown"}]},
+		{"filename": "Addon-nolib.zip", "nolib": true, "metadata": [{"flavor": "wrath"}]}
+	]}`)
+
+	got, err := ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() unexpected error: %v", err)
+	}
+	if want := []types.GameTrack{types.ClassicTrack, types.RetailTrack}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseJSON() = %v, want %v", got, want)
+	}
+
+	if _, err := ParseJSON([]byte("not json")); err == nil {
+		t.Error("ParseJSON(not json) expected an error")
+	}
+}
+
+func TestIsAddonRelease(t *testing.T) {
+	if IsAddonRelease([]Asset{{Name: "source.tar.gz"}}) {
+		t.Error("IsAddonRelease(source.tar.gz) = true, want false")
+	}
+	if !IsAddonRelease([]Asset{{Name: JSONName}}) {
+		t.Error("IsAddonRelease(release.json) = false, want true")
+	}
+}
+
+func TestDownloadCount(t *testing.T) {
+	assets := []Asset{{Name: "a.zip", DownloadCount: 10}, {Name: "b-classic.zip", DownloadCount: 5}, {Name: "release.json", DownloadCount: 99}}
+	if got := DownloadCount(assets); got != 15 {
+		t.Errorf("DownloadCount() = %d, want 15", got)
+	}
+}
@@ -0,0 +1,339 @@
+// Package report summarises a scrape run: what was fetched, what failed and why, and what was left out of the catalogue.
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/cache"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/catalogue"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/circuit"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// Keys used in the HTTP error breakdown for failures without a status code
+const (
+	NetworkError = "network-error"
+	CircuitOpen  = "circuit-open"
+	NotCached    = "not-cached" // offline and missing from the cache
+
+	BudgetExceeded   = "budget-exceeded"    // the host had sent its --max-bytes-per-host
+	ResponseTooLarge = "response-too-large" // over --max-response-size
+)
+
+// Reasons addons are skipped
+const (
+	MissingUpdatedDate = "missing updated-date" // no source reported when the addon was last updated
+	Blocklisted        = "blocklisted"
+	Removed            = "removed"          // taken down from the source
+	PendingApproval    = "pending approval" // not yet approved for listing by the source
+)
+
+// Failure is a URL that couldn't be processed
+type Failure struct {
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// SkippedAddon is an addon that was found but left out of the catalogue
+type SkippedAddon struct {
+	Source   types.Source `json:"source"`
+	SourceID string       `json:"source-id"`
+	Reason   string       `json:"reason"`
+}
+
+// CacheSummary counts responses served from the HTTP cache
+type CacheSummary struct {
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	HitRatio float64 `json:"hit-ratio"` // hits / (hits + misses), 0 when nothing was requested
+}
+
+// NewCacheSummary creates a cache summary from hit and miss counts
+func NewCacheSummary(hits, misses int64) *CacheSummary {
+	summary := &CacheSummary{Hits: hits, Misses: misses}
+	if total := hits + misses; total > 0 {
+		summary.HitRatio = float64(hits) / float64(total)
+	}
+	return summary
+}
+
+// HostSummary is where the requests made over a connection to a host spent their time, on average, and how much
+// the host sent
+type HostSummary struct {
+	Bytes           int64   `json:"bytes"` // of response bodies, compressed responses once decompressed
+	Requests        int64   `json:"requests"`
+	ReusedConns     int64   `json:"reused-connections"`
+	DNSMillis       float64 `json:"dns-ms"`                // per lookup
+	ConnectMillis   float64 `json:"connect-ms"`            // per new connection
+	TLSMillis       float64 `json:"tls-ms"`                // per handshake
+	FirstByteMillis float64 `json:"time-to-first-byte-ms"` // per response
+}
+
+// NewHostSummaries averages the traces of the requests made to each host and totals the bytes each sent, either may be nil
+func NewHostSummaries(traces map[string]http.HostTrace, bytes map[string]int64) map[string]HostSummary {
+	average := func(total time.Duration, count int64) float64 {
+		if count == 0 {
+			return 0
+		}
+		return float64(total.Microseconds()) / 1000 / float64(count)
+	}
+	summaries := make(map[string]HostSummary, len(traces))
+	for host, trace := range traces {
+		summaries[host] = HostSummary{
+			Requests:        trace.Requests,
+			ReusedConns:     trace.ReusedConns,
+			DNSMillis:       average(trace.DNS, trace.DNSLookups),
+			ConnectMillis:   average(trace.Connect, trace.Connects),
+			TLSMillis:       average(trace.TLS, trace.TLSHandshakes),
+			FirstByteMillis: average(trace.TimeToFirstByte, trace.Responses),
+		}
+	}
+	for host, n := range bytes {
+		summary := summaries[host]
+		summary.Bytes = n
+		summaries[host] = summary
+	}
+	return summaries
+}
+
+// ScrapeReport summarises a scrape run
+type ScrapeReport struct {
+	StartedAt          time.Time            `json:"started-at"`
+	FinishedAt         time.Time            `json:"finished-at"`
+	DurationSeconds    float64              `json:"duration-seconds"`
+	Sources            []types.Source       `json:"sources"`
+	URLsFetched        int64                `json:"urls-fetched"`
+	DeadLetterSkips    int64                `json:"dead-letter-skips"` // URLs not fetched because they kept failing in earlier scrapes
+	Cache              *CacheSummary        `json:"cache,omitempty"`   // nil when the HTTP client doesn't cache
+	HTTPErrors         map[string]int       `json:"http-errors"`       // status code or error kind -> count
+	FetchFailures      []Failure            `json:"fetch-failures"`
+	ParseFailures      []Failure            `json:"parse-failures"`
+	SkippedAddons      []SkippedAddon       `json:"skipped-addons"`
+	AddonsPerSource    map[types.Source]int `json:"addons-per-source"`
+	UnclassifiedAddons map[types.Source]int `json:"unclassified-addons"` // addons without a game track, see scrape --unknown-game-tracks
+	AppliedOverrides   []string             `json:"applied-overrides"`   // source/source-id
+	StaleOverrides     []string             `json:"stale-overrides"`     // addon gone or the source now agrees with the override
+
+	Hosts map[string]HostSummary `json:"hosts,omitempty"` // requests made over the network by host, nil when the HTTP client doesn't trace them
+}
+
+// Collector gathers the events of a scrape as it happens. Safe for concurrent use.
+type Collector struct {
+	mu            sync.Mutex
+	urlsFetched   int64
+	deadLettered  int64
+	httpErrors    map[string]int
+	fetchFailures []Failure
+	parseFailures []Failure
+	skipped       []SkippedAddon
+}
+
+// NewCollector creates an empty collector
+func NewCollector() *Collector {
+	return &Collector{
+		httpErrors: make(map[string]int),
+	}
+}
+
+// Fetched records a URL being requested
+func (c *Collector) Fetched() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.urlsFetched++
+}
+
+// DeadLetterSkipped records a URL not requested because it kept failing in earlier scrapes
+func (c *Collector) DeadLetterSkipped() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deadLettered++
+}
+
+// FetchFailed records a request for url that failed with err, or with a non-200 statusCode when err is nil
+func (c *Collector) FetchFailed(url string, statusCode int, err error) {
+	key := strconv.Itoa(statusCode)
+	switch {
+	case errors.Is(err, circuit.ErrOpen):
+		key = CircuitOpen
+	case errors.Is(err, cache.ErrNotCached):
+		key = NotCached
+	case errors.Is(err, http.ErrBudgetExceeded):
+		key = BudgetExceeded
+	case errors.Is(err, http.ErrResponseTooLarge):
+		key = ResponseTooLarge
+	case err != nil:
+		key = NetworkError
+	}
+
+	failure := Failure{URL: url, Error: fmt.Sprintf("status %d", statusCode)}
+	if err != nil {
+		failure.Error = err.Error()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.httpErrors[key]++
+	c.fetchFailures = append(c.fetchFailures, failure)
+}
+
+// ParseFailed records a response that couldn't be parsed
+func (c *Collector) ParseFailed(url string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.parseFailures = append(c.parseFailures, Failure{URL: url, Error: err.Error()})
+}
+
+// Failures returns every URL that couldn't be fetched or parsed so far, sorted by URL
+func (c *Collector) Failures() []Failure {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	failures := append(append([]Failure{}, c.fetchFailures...), c.parseFailures...)
+	sortFailures(failures)
+	return failures
+}
+
+// Skipped records an addon left out of the catalogue
+func (c *Collector) Skipped(source types.Source, sourceID string, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.skipped = append(c.skipped, SkippedAddon{Source: source, SourceID: sourceID, Reason: reason})
+}
+
+// Report returns what has been collected so far along with the number of addons per source in addons.
+// Lists are sorted so reports of the same run compare equal.
+func (c *Collector) Report(addons []types.Addon) ScrapeReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := ScrapeReport{
+		URLsFetched:        c.urlsFetched,
+		DeadLetterSkips:    c.deadLettered,
+		HTTPErrors:         make(map[string]int, len(c.httpErrors)),
+		FetchFailures:      append([]Failure{}, c.fetchFailures...),
+		ParseFailures:      append([]Failure{}, c.parseFailures...),
+		SkippedAddons:      append([]SkippedAddon{}, c.skipped...),
+		AddonsPerSource:    make(map[types.Source]int),
+		UnclassifiedAddons: make(map[types.Source]int),
+	}
+	for key, count := range c.httpErrors {
+		report.HTTPErrors[key] = count
+	}
+	for _, addon := range addons {
+		report.AddonsPerSource[addon.Source]++
+		if len(addon.GameTrackList) == 0 {
+			report.UnclassifiedAddons[addon.Source]++
+		}
+	}
+
+	sortFailures(report.FetchFailures)
+	sortFailures(report.ParseFailures)
+	sort.Slice(report.SkippedAddons, func(i, j int) bool {
+		a, b := report.SkippedAddons[i], report.SkippedAddons[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.SourceID < b.SourceID
+	})
+
+	return report
+}
+
+func sortFailures(failures []Failure) {
+	sort.SliceStable(failures, func(i, j int) bool {
+		return failures[i].URL < failures[j].URL
+	})
+}
+
+// Read reads a scrape report
+func Read(path string) (ScrapeReport, error) {
+	var report ScrapeReport
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, fmt.Errorf("failed to read scrape report %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("failed to parse scrape report %s: %w", path, err)
+	}
+	return report, nil
+}
+
+// Write writes a scrape report as indented JSON
+func Write(report ScrapeReport, path string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal scrape report: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write scrape report to %s: %w", path, err)
+	}
+	return nil
+}
+
+// WriteFailures writes a list of failed URLs as indented JSON, for a later run to retry
+func WriteFailures(failures []Failure, path string) error {
+	if failures == nil {
+		failures = []Failure{}
+	}
+	data, err := json.MarshalIndent(failures, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal failed URLs: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write failed URLs to %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadFailures reads a list of failed URLs written by WriteFailures
+func ReadFailures(path string) ([]Failure, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read failed URLs %s: %w", path, err)
+	}
+
+	var failures []Failure
+	if err := json.Unmarshal(data, &failures); err != nil {
+		return nil, fmt.Errorf("failed to parse failed URLs %s: %w", path, err)
+	}
+	return failures, nil
+}
+
+// FormatBytes formats a byte count for people, e.g. "1.5 MiB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// SkipReason returns why the catalogue builder left out the addon described by addonDataList
+func SkipReason(addonDataList []types.AddonData) string {
+	switch catalogue.MergedStatus(addonDataList) {
+	case types.RemovedStatus:
+		return Removed
+	case types.PendingStatus:
+		return PendingApproval
+	default:
+		// No page or API response gave a date the addon was last updated
+		return MissingUpdatedDate
+	}
+}
@@ -0,0 +1,188 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/cache"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/circuit"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestCollector_Report(t *testing.T) {
+	c := NewCollector()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Fetched()
+		}()
+	}
+	wg.Wait()
+
+	c.FetchFailed("https://example.org/404-b", 404, nil)
+	c.FetchFailed("https://example.org/404-a", 404, nil)
+	c.FetchFailed("https://example.org/503", 503, nil)
+	c.FetchFailed("https://example.org/reset", 0, errors.New("connection reset"))
+	c.FetchFailed("https://example.org/open", 0, fmt.Errorf("failed to get: %w", circuit.ErrOpen))
+	c.FetchFailed("https://example.org/offline", 0, fmt.Errorf("failed to get: %w", cache.ErrNotCached))
+	c.FetchFailed("https://example.org/budget", 0, fmt.Errorf("failed to get: %w", http.ErrBudgetExceeded))
+	c.FetchFailed("https://example.org/zip", 0, fmt.Errorf("failed to get: %w", http.ErrResponseTooLarge))
+	c.ParseFailed("https://example.org/b", errors.New("bad json"))
+	c.ParseFailed("https://example.org/a", errors.New("bad html"))
+	c.DeadLetterSkipped()
+	c.Skipped(types.WowInterfaceSource, "2", MissingUpdatedDate)
+	c.Skipped(types.WowInterfaceSource, "1", MissingUpdatedDate)
+
+	addons := []types.Addon{
+		{Source: types.WowInterfaceSource, SourceID: "3", GameTrackList: []types.GameTrack{}},
+		{Source: types.WowInterfaceSource, SourceID: "4", GameTrackList: []types.GameTrack{types.RetailTrack}},
+		{Source: types.GitHubSource, SourceID: "foo/bar", GameTrackList: []types.GameTrack{types.ClassicTrack}},
+	}
+	report := c.Report(addons)
+
+	if report.URLsFetched != 10 {
+		t.Errorf("URLsFetched = %d, want 10", report.URLsFetched)
+	}
+
+	if report.DeadLetterSkips != 1 {
+		t.Errorf("DeadLetterSkips = %d, want 1", report.DeadLetterSkips)
+	}
+
+	wantErrors := map[string]int{"404": 2, "503": 1, NetworkError: 1, CircuitOpen: 1, NotCached: 1, BudgetExceeded: 1, ResponseTooLarge: 1}
+	if !reflect.DeepEqual(report.HTTPErrors, wantErrors) {
+		t.Errorf("HTTPErrors = %v, want %v", report.HTTPErrors, wantErrors)
+	}
+
+	wantFetchFailures := []Failure{
+		{URL: "https://example.org/404-a", Error: "status 404"},
+		{URL: "https://example.org/404-b", Error: "status 404"},
+		{URL: "https://example.org/503", Error: "status 503"},
+		{URL: "https://example.org/budget", Error: "failed to get: " + http.ErrBudgetExceeded.Error()},
+		{URL: "https://example.org/offline", Error: "failed to get: " + cache.ErrNotCached.Error()},
+		{URL: "https://example.org/open", Error: "failed to get: " + circuit.ErrOpen.Error()},
+		{URL: "https://example.org/reset", Error: "connection reset"},
+		{URL: "https://example.org/zip", Error: "failed to get: " + http.ErrResponseTooLarge.Error()},
+	}
+	if !reflect.DeepEqual(report.FetchFailures, wantFetchFailures) {
+		t.Errorf("FetchFailures = %v, want %v", report.FetchFailures, wantFetchFailures)
+	}
+	if failures := c.Failures(); len(failures) != 10 || failures[0].URL != "https://example.org/404-a" || failures[1].URL != "https://example.org/404-b" {
+		t.Errorf("Failures() = %v, want all 10 fetch and parse failures sorted by URL", failures)
+	}
+
+	wantFailures := []Failure{
+		{URL: "https://example.org/a", Error: "bad html"},
+		{URL: "https://example.org/b", Error: "bad json"},
+	}
+	if !reflect.DeepEqual(report.ParseFailures, wantFailures) {
+		t.Errorf("ParseFailures = %v, want %v", report.ParseFailures, wantFailures)
+	}
+
+	wantSkipped := []SkippedAddon{
+		{Source: types.WowInterfaceSource, SourceID: "1", Reason: MissingUpdatedDate},
+		{Source: types.WowInterfaceSource, SourceID: "2", Reason: MissingUpdatedDate},
+	}
+	if !reflect.DeepEqual(report.SkippedAddons, wantSkipped) {
+		t.Errorf("SkippedAddons = %v, want %v", report.SkippedAddons, wantSkipped)
+	}
+
+	wantPerSource := map[types.Source]int{types.WowInterfaceSource: 2, types.GitHubSource: 1}
+	if !reflect.DeepEqual(report.AddonsPerSource, wantPerSource) {
+		t.Errorf("AddonsPerSource = %v, want %v", report.AddonsPerSource, wantPerSource)
+	}
+
+	wantUnclassified := map[types.Source]int{types.WowInterfaceSource: 1}
+	if !reflect.DeepEqual(report.UnclassifiedAddons, wantUnclassified) {
+		t.Errorf("UnclassifiedAddons = %v, want %v", report.UnclassifiedAddons, wantUnclassified)
+	}
+}
+
+func TestNewCacheSummary(t *testing.T) {
+	tests := []struct {
+		hits, misses int64
+		want         float64
+	}{
+		{0, 0, 0},
+		{3, 1, 0.75},
+		{0, 5, 0},
+	}
+
+	for _, tt := range tests {
+		if got := NewCacheSummary(tt.hits, tt.misses).HitRatio; got != tt.want {
+			t.Errorf("NewCacheSummary(%d, %d).HitRatio = %v, want %v", tt.hits, tt.misses, got, tt.want)
+		}
+	}
+}
+
+func TestNewHostSummaries(t *testing.T) {
+	summaries := NewHostSummaries(map[string]http.HostTrace{
+		"www.wowinterface.com": {Requests: 4, ReusedConns: 3, DNSLookups: 1, DNS: 20 * time.Millisecond, Connects: 1, Connect: 30 * time.Millisecond,
+			TLSHandshakes: 1, TLS: 50 * time.Millisecond, Responses: 4, TimeToFirstByte: 800 * time.Millisecond},
+		"api.mmoui.com": {Requests: 1},
+	}, map[string]int64{"www.wowinterface.com": 1 << 20, "cdn.wowinterface.com": 512})
+	want := map[string]HostSummary{
+		"www.wowinterface.com": {Bytes: 1 << 20, Requests: 4, ReusedConns: 3, DNSMillis: 20, ConnectMillis: 30, TLSMillis: 50, FirstByteMillis: 200},
+		"api.mmoui.com":        {Requests: 1},
+		"cdn.wowinterface.com": {Bytes: 512},
+	}
+	if !reflect.DeepEqual(summaries, want) {
+		t.Errorf("NewHostSummaries() = %+v, want %+v", summaries, want)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scrape-report.json")
+
+	report := NewCollector().Report(nil)
+	report.Sources = []types.Source{types.WowInterfaceSource}
+	report.Cache = NewCacheSummary(1, 1)
+
+	if err := Write(report, path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	got, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, report) {
+		t.Errorf("Read() = %+v, want %+v", got, report)
+	}
+}
+
+func TestWriteReadFailures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "failed-urls.json")
+
+	// An empty list replaces the failures of a previous run
+	if err := WriteFailures(nil, path); err != nil {
+		t.Fatalf("WriteFailures() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	if string(data) != "[]" {
+		t.Errorf("WriteFailures(nil) wrote %s, want []", data)
+	}
+
+	failures := []Failure{{URL: "https://example.org/a", Error: "status 404"}}
+	if err := WriteFailures(failures, path); err != nil {
+		t.Fatalf("WriteFailures() error = %v", err)
+	}
+	got, err := ReadFailures(path)
+	if err != nil {
+		t.Fatalf("ReadFailures() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, failures) {
+		t.Errorf("ReadFailures() = %v, want %v", got, failures)
+	}
+}
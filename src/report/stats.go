@@ -0,0 +1,114 @@
+package report
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// SourceStats gathers what scraping a source took: the URLs of each kind processed and the time spent fetching and
+// parsing them, summed across workers. Safe for concurrent use.
+type SourceStats struct {
+	source  types.Source
+	started time.Time
+
+	mu       sync.Mutex
+	kinds    map[string]*kindStats
+	complete int
+	partial  int
+	graded   bool // complete and partial are known
+}
+
+// kindStats are the stats of a kind of URL
+type kindStats struct {
+	urls  int
+	fetch time.Duration
+	parse time.Duration
+}
+
+// NewSourceStats starts gathering the stats of scraping source
+func NewSourceStats(source types.Source) *SourceStats {
+	return &SourceStats{source: source, started: time.Now(), kinds: make(map[string]*kindStats)}
+}
+
+// kind returns the stats of kind, the caller holds the lock
+func (s *SourceStats) kind(kind string) *kindStats {
+	stats, ok := s.kinds[kind]
+	if !ok {
+		stats = &kindStats{}
+		s.kinds[kind] = stats
+	}
+	return stats
+}
+
+// Fetched records a URL of kind being fetched, successfully or not, in elapsed
+func (s *SourceStats) Fetched(kind string, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.kind(kind)
+	stats.urls++
+	stats.fetch += elapsed
+}
+
+// Parsed records a URL of kind being parsed in elapsed
+func (s *SourceStats) Parsed(kind string, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kind(kind).parse += elapsed
+}
+
+// Graded records how many addons were built from all the data expected of them and how many from only some of it,
+// e.g. without their page because it failed to fetch
+func (s *SourceStats) Graded(complete, partial int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.complete, s.partial, s.graded = complete, partial, true
+}
+
+// Middleware records each request made through it as Fetched, its kind given by kindOf its URL
+func (s *SourceStats) Middleware(kindOf func(url string) string) http.Middleware {
+	return func(next http.HTTPClient) http.HTTPClient {
+		return http.ClientFunc(func(ctx context.Context, url string) (*http.Response, error) {
+			started := time.Now()
+			resp, err := next.Get(ctx, url)
+			s.Fetched(kindOf(url), time.Since(started))
+			return resp, err
+		})
+	}
+}
+
+// Log logs the stats along with the number of addons scraped, with a group of the URLs, fetch time and parse time
+// of each kind of URL
+func (s *SourceStats) Log(addons int) {
+	slog.Info("scrape statistics", s.attrs(addons)...)
+}
+
+// attrs returns the stats as slog attributes
+func (s *SourceStats) attrs(addons int) []any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var fetch, parse time.Duration
+	var groups []any
+	for _, kind := range slices.Sorted(maps.Keys(s.kinds)) {
+		stats := s.kinds[kind]
+		fetch += stats.fetch
+		parse += stats.parse
+		groups = append(groups, slog.Group(kind, "urls", stats.urls,
+			"fetch", stats.fetch.Round(time.Millisecond), "parse", stats.parse.Round(time.Millisecond)))
+	}
+
+	attrs := []any{"source", s.source, "addons", addons}
+	if s.graded {
+		attrs = append(attrs, "complete", s.complete, "partial", s.partial)
+	}
+	attrs = append(attrs, "elapsed", time.Since(s.started).Round(time.Millisecond),
+		"fetch", fetch.Round(time.Millisecond), "parse", parse.Round(time.Millisecond))
+	return append(attrs, groups...)
+}
@@ -0,0 +1,55 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestSourceStats(t *testing.T) {
+	stats := NewSourceStats(types.WowInterfaceSource)
+	stats.Fetched("api-detail", 2*time.Second)
+	stats.Fetched("api-detail", time.Second)
+	stats.Parsed("api-detail", 100*time.Millisecond)
+	stats.Fetched("addon-detail", 5*time.Second)
+	stats.Parsed("addon-detail", 2*time.Second)
+	stats.Graded(1, 1)
+
+	var out bytes.Buffer
+	slog.New(slog.NewTextHandler(&out, nil)).Info("scrape statistics", stats.attrs(2)...)
+
+	for _, want := range []string{
+		"source=wowinterface addons=2 complete=1 partial=1 ",
+		" fetch=8s parse=2.1s ",
+		"addon-detail.urls=1 addon-detail.fetch=5s addon-detail.parse=2s api-detail.urls=2 api-detail.fetch=3s api-detail.parse=100ms",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("attrs() logged %s, want it to contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestSourceStats_Middleware(t *testing.T) {
+	client := http.NewMockHTTPClient()
+	client.SetResponse("https://example.org/a", &http.Response{StatusCode: 200})
+	stats := NewSourceStats(types.GitHubSource)
+	kindOf := func(url string) string { return strings.TrimPrefix(url, "https://example.org/") }
+	wrapped := http.Chain(client, stats.Middleware(kindOf))
+
+	wrapped.Get(context.Background(), "https://example.org/a")
+	wrapped.Get(context.Background(), "https://example.org/a")
+	wrapped.Get(context.Background(), "https://example.org/b") // fails, still fetched
+
+	if stats.kinds["a"].urls != 2 || stats.kinds["b"].urls != 1 {
+		t.Errorf("urls = %d, %d, want 2, 1", stats.kinds["a"].urls, stats.kinds["b"].urls)
+	}
+	if stats.graded {
+		t.Error("graded without Graded(), want complete and partial left out")
+	}
+}
@@ -0,0 +1,77 @@
+package retry
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+)
+
+// Rate limit headers sent by the GitHub API (and others following its lead), in canonical form
+const (
+	rateLimitRemainingHeader = "X-Ratelimit-Remaining"
+	rateLimitResetHeader     = "X-Ratelimit-Reset" // unix seconds
+)
+
+// quotaPauses holds requests to hosts whose rate limit quota is exhausted until the quota resets.
+// It's shared by every worker so they all pause rather than each using up a request to find out.
+var quotaPauses = &pauses{until: make(map[string]time.Time)}
+
+type pauses struct {
+	mu    sync.Mutex
+	until map[string]time.Time // host -> quota reset
+}
+
+// pause holds requests to host until reset
+func (p *pauses) pause(host string, reset time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if reset.After(p.until[host]) {
+		p.until[host] = reset
+	}
+}
+
+// wait blocks until requests to host may be made again or ctx is done
+func (p *pauses) wait(ctx context.Context, host string) error {
+	p.mu.Lock()
+	until := p.until[host]
+	p.mu.Unlock()
+
+	delay := time.Until(until)
+	if delay <= 0 {
+		return nil
+	}
+
+	slog.Warn("rate limit quota exhausted, pausing requests", "host", host, "until", until.Format(time.RFC3339), "delay", delay.Round(time.Second))
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// quotaReset returns when the rate limit quota resets if the response says it's exhausted
+func quotaReset(resp *http.Response) (time.Time, bool) {
+	if resp == nil || resp.Headers[rateLimitRemainingHeader] != "0" {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseInt(resp.Headers[rateLimitResetHeader], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0), true
+}
+
+// hostOf returns the host of rawURL, empty if it can't be parsed
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
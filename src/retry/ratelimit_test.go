@@ -0,0 +1,70 @@
+package retry
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+)
+
+// mockClientQuotaExhausted rejects the first request for an exhausted quota that resets at reset
+type mockClientQuotaExhausted struct {
+	reset time.Time
+	calls []time.Time
+}
+
+func (m *mockClientQuotaExhausted) Get(ctx context.Context, url string) (*http.Response, error) {
+	m.calls = append(m.calls, time.Now())
+	if len(m.calls) == 1 {
+		return &http.Response{StatusCode: 403, Headers: map[string]string{
+			"X-Ratelimit-Remaining": "0",
+			"X-Ratelimit-Reset":     strconv.FormatInt(m.reset.Unix(), 10),
+		}}, nil
+	}
+	return &http.Response{StatusCode: 200}, nil
+}
+
+func TestWithRetry_QuotaExhausted(t *testing.T) {
+	reset := time.Now().Add(2 * time.Second).Truncate(time.Second)
+	client := &mockClientQuotaExhausted{reset: reset}
+	config := Config{MaxAttempts: 1, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, MaxRateLimitWait: time.Minute}
+
+	// A 403 for the quota is waited out, even with a single attempt
+	resp, err := WithRetry(context.Background(), client, "https://api.github.com/quota-exhausted", config)
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("WithRetry() = %v, %v, want 200", resp, err)
+	}
+	if len(client.calls) != 2 || client.calls[1].Before(reset) {
+		t.Errorf("WithRetry() retried at %v, want after the quota reset at %v", client.calls, reset)
+	}
+}
+
+func TestWithRetry_QuotaResetTooFarAhead(t *testing.T) {
+	client := &mockClientQuotaExhausted{reset: time.Now().Add(2 * time.Hour)}
+	config := Config{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, MaxRateLimitWait: time.Minute}
+
+	resp, err := WithRetry(context.Background(), client, "https://api.github.com/quota-far-ahead", config)
+	if err != nil || resp.StatusCode != 403 {
+		t.Fatalf("WithRetry() = %v, %v, want the 403", resp, err)
+	}
+	if len(client.calls) != 1 {
+		t.Errorf("calls = %d, want 1", len(client.calls))
+	}
+}
+
+func TestPauses(t *testing.T) {
+	p := &pauses{until: make(map[string]time.Time)}
+	p.pause("api.github.com", time.Now().Add(time.Hour))
+	p.pause("api.github.com", time.Now()) // an earlier reset doesn't shorten the pause
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.wait(ctx, "api.github.com"); err == nil {
+		t.Error("wait() on a paused host, expected the context to expire")
+	}
+	if err := p.wait(context.Background(), "www.wowinterface.com"); err != nil {
+		t.Errorf("wait() on another host unexpected error: %v", err)
+	}
+}
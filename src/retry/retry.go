@@ -2,11 +2,15 @@ package retry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	nethttp "net/http"
 	"strconv"
 	"time"
 
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/cache"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/circuit"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
 )
 
@@ -15,6 +19,10 @@ type Config struct {
 	MaxAttempts  int
 	InitialDelay time.Duration
 	MaxDelay     time.Duration
+
+	// MaxRateLimitWait is the longest requests are paused waiting for an exhausted rate limit quota to reset.
+	// Responses with a later reset are returned as-is, zero never waits.
+	MaxRateLimitWait time.Duration
 }
 
 // DefaultConfig returns sensible defaults matching the Clojure version
@@ -23,11 +31,33 @@ func DefaultConfig() Config {
 		MaxAttempts:  3,
 		InitialDelay: 1 * time.Second,
 		MaxDelay:     8 * time.Second,
+
+		MaxRateLimitWait: time.Hour,
 	}
 }
 
 // shouldRetry determines if we should retry based on the response or error
 func shouldRetry(resp *http.Response, err error) bool {
+	// Host's circuit breaker is open: retrying within the cool-down would only fail again
+	if errors.Is(err, circuit.ErrOpen) {
+		return false
+	}
+
+	// Host has sent its download budget, it won't be given more on the next attempt
+	if errors.Is(err, http.ErrBudgetExceeded) {
+		return false
+	}
+
+	// The response will be as large on the next attempt
+	if errors.Is(err, http.ErrResponseTooLarge) {
+		return false
+	}
+
+	// Offline and not cached: it won't be cached on the next attempt either
+	if errors.Is(err, cache.ErrNotCached) {
+		return false
+	}
+
 	// Network errors: retry
 	if err != nil {
 		return true
@@ -49,18 +79,14 @@ func shouldRetry(resp *http.Response, err error) bool {
 
 // getRetryDelay calculates the delay for the next retry
 func getRetryDelay(resp *http.Response, attempt int, config Config) time.Duration {
-	// Check for Retry-After header on 429 responses
-	if resp != nil && resp.StatusCode == 429 {
-		if retryAfter := resp.Headers["Retry-After"]; retryAfter != "" {
-			// Try parsing as seconds
-			if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
-				delay := time.Duration(seconds) * time.Second
-				// Cap at max delay
-				if delay > config.MaxDelay {
-					return config.MaxDelay
-				}
-				return delay
+	// Rate limited or down for maintenance: the server may say when to come back
+	if resp != nil && (resp.StatusCode == 429 || resp.StatusCode == 503) {
+		if delay, ok := retryAfter(resp.Headers["Retry-After"], time.Now()); ok {
+			// Cap at max delay
+			if delay > config.MaxDelay {
+				return config.MaxDelay
 			}
+			return delay
 		}
 	}
 
@@ -75,10 +101,31 @@ func getRetryDelay(resp *http.Response, attempt int, config Config) time.Duratio
 	return delay
 }
 
-// WithRetry wraps an HTTP GET call with retry logic and exponential backoff
+// retryAfter returns the delay a Retry-After header value asks for, given as seconds or as an HTTP-date.
+// Returns false for an invalid value or one that has already passed.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, seconds > 0
+	}
+	date, err := nethttp.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	delay := date.Sub(now)
+	return delay, delay > 0
+}
+
+// WithRetry wraps an HTTP GET call with retry logic and exponential backoff.
+// Requests to a host whose rate limit quota is exhausted are paused until it resets (up to MaxRateLimitWait),
+// including those made by other callers, instead of failing.
 func WithRetry(ctx context.Context, client http.HTTPClient, url string, config Config) (*http.Response, error) {
 	var lastErr error
 	var lastResp *http.Response
+	host := hostOf(url)
+	quotaWaits := 0
 
 	for attempt := 1; attempt <= config.MaxAttempts; attempt++ {
 		// Log retry attempts after the first one
@@ -86,8 +133,23 @@ func WithRetry(ctx context.Context, client http.HTTPClient, url string, config C
 			slog.Warn("retrying request", "url", url, "attempt", attempt, "max_attempts", config.MaxAttempts)
 		}
 
+		if err := quotaPauses.wait(ctx, host); err != nil {
+			return nil, err
+		}
+
 		resp, err := client.Get(ctx, url)
 
+		// Quota used up: this response may be fine, but hold everyone's next request until it resets
+		quotaExhausted := false
+		if reset, ok := quotaReset(resp); ok && config.MaxRateLimitWait > 0 && time.Until(reset) > 0 {
+			if time.Until(reset) > config.MaxRateLimitWait {
+				slog.Warn("rate limit quota resets too far ahead to wait for", "url", url, "reset", reset.Format(time.RFC3339), "max-wait", config.MaxRateLimitWait)
+			} else {
+				quotaPauses.pause(host, reset)
+				quotaExhausted = resp.StatusCode == 403 || resp.StatusCode == 429
+			}
+		}
+
 		// Success case
 		if err == nil && resp.StatusCode == 200 {
 			return resp, nil
@@ -97,6 +159,14 @@ func WithRetry(ctx context.Context, client http.HTTPClient, url string, config C
 		lastResp = resp
 		lastErr = err
 
+		// Rejected for the quota: the retry waits for the reset above and isn't counted as an attempt
+		if quotaExhausted && quotaWaits < config.MaxAttempts {
+			quotaWaits++
+			slog.Info("rate limited, retrying once the quota resets", "url", url)
+			attempt--
+			continue
+		}
+
 		// Check if we should retry
 		if !shouldRetry(resp, err) {
 			// Don't retry 4xx errors (except 429 which is handled above)
@@ -145,3 +215,12 @@ func getRetryReason(resp *http.Response, err error) string {
 	}
 	return "unknown"
 }
+
+// Middleware retries the requests made through a client as WithRetry does
+func Middleware(config Config) http.Middleware {
+	return func(next http.HTTPClient) http.HTTPClient {
+		return http.ClientFunc(func(ctx context.Context, url string) (*http.Response, error) {
+			return WithRetry(ctx, next, url, config)
+		})
+	}
+}
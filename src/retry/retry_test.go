@@ -3,9 +3,13 @@ package retry
 import (
 	"context"
 	"errors"
+	"fmt"
+	nethttp "net/http"
 	"testing"
 	"time"
 
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/cache"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/circuit"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
 )
 
@@ -227,6 +231,9 @@ func TestShouldRetry(t *testing.T) {
 		{"Bad gateway 502", 502, nil, true},
 		{"Service unavailable 503", 503, nil, true},
 		{"Network error", 0, errors.New("network error"), true},
+		{"Not cached offline", 0, fmt.Errorf("failed to get: %w", cache.ErrNotCached), false},
+		{"Download budget exceeded", 0, fmt.Errorf("failed to get: %w", http.ErrBudgetExceeded), false},
+		{"Response too large", 0, fmt.Errorf("failed to get: %w", http.ErrResponseTooLarge), false},
 	}
 
 	for _, tt := range tests {
@@ -309,3 +316,88 @@ func TestGetRetryDelay_RetryAfterCapped(t *testing.T) {
 		t.Errorf("getRetryDelay() with large Retry-After = %v, want %v (capped)", delay, expected)
 	}
 }
+
+func TestGetRetryDelay_ServiceUnavailable(t *testing.T) {
+	config := Config{
+		InitialDelay: 1 * time.Second,
+		MaxDelay:     time.Minute,
+	}
+
+	// Down for maintenance until a given date, rounded to the second by the header format
+	until := time.Now().Add(30 * time.Second).UTC()
+	resp := &http.Response{
+		StatusCode: 503,
+		Headers:    map[string]string{"Retry-After": until.Format(nethttp.TimeFormat)},
+	}
+	if delay := getRetryDelay(resp, 1, config); delay < 28*time.Second || delay > 30*time.Second {
+		t.Errorf("getRetryDelay() with a Retry-After date = %v, want about 30s", delay)
+	}
+
+	// Other server errors back off as usual
+	resp.StatusCode = 500
+	if delay := getRetryDelay(resp, 1, config); delay != config.InitialDelay {
+		t.Errorf("getRetryDelay() of a 500 = %v, want %v", delay, config.InitialDelay)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{"0", 0, false},
+		{"Sun, 01 Jun 2025 12:05:00 GMT", 5 * time.Minute, true},
+		{"Sunday, 01-Jun-25 12:00:30 GMT", 30 * time.Second, true}, // RFC 850
+		{"Sun Jun  1 12:00:10 2025", 10 * time.Second, true},       // ANSI C asctime
+		{"Sun, 01 Jun 2025 11:00:00 GMT", 0, false},                // already passed
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.value, now)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestWithRetry_CircuitOpen(t *testing.T) {
+	client := http.NewMockHTTPClient()
+	client.SetError("http://example.com", fmt.Errorf("failed to fetch: %w", circuit.ErrOpen))
+
+	config := Config{
+		MaxAttempts:  3,
+		InitialDelay: 10 * time.Millisecond,
+		MaxDelay:     100 * time.Millisecond,
+	}
+
+	_, err := WithRetry(context.Background(), client, "http://example.com", config)
+	if !errors.Is(err, circuit.ErrOpen) {
+		t.Errorf("WithRetry() error = %v, want %v", err, circuit.ErrOpen)
+	}
+
+	if calls := len(client.GetCalls()); calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	callCount := 0
+	mock := &mockClientWithCounter{counter: &callCount, mock: http.NewMockHTTPClient()}
+	mock.mock.SetResponse("http://example.com", &http.Response{StatusCode: 200})
+
+	config := Config{MaxAttempts: 3, InitialDelay: 10 * time.Millisecond, MaxDelay: 100 * time.Millisecond}
+	client := http.Chain(mock, Middleware(config))
+
+	resp, err := client.Get(context.Background(), "http://example.com")
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if resp.StatusCode != 200 || callCount != 2 {
+		t.Errorf("Get() = %d after %d calls, want 200 after 2", resp.StatusCode, callCount)
+	}
+}
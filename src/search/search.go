@@ -0,0 +1,208 @@
+// Package search finds addons in a catalogue with a rough query, forgiving case, accents, word order and small typos,
+// so maintainers can check an addon made it into the output without opening the catalogue.
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Match is an addon matching a query
+type Match struct {
+	Addon  types.Addon
+	Score  float64  // higher is better
+	Fields []string // fields a query word was found in, best first
+}
+
+// field is a searchable field of an addon
+type field struct {
+	name   string
+	weight float64 // how much a match in the field counts
+	text   func(types.Addon) string
+}
+
+var fields = []field{
+	{"label", 1.0, func(a types.Addon) string { return a.Label }},
+	{"name", 1.0, func(a types.Addon) string { return a.Name }},
+	{"source-id", 1.0, func(a types.Addon) string { return a.SourceID }},
+	{"tag-list", 0.7, func(a types.Addon) string { return strings.Join(a.TagList, " ") }},
+	{"description", 0.4, func(a types.Addon) string { return a.Description }},
+}
+
+// How well a query word matches a word of a field
+const (
+	exactWord     = 1.0
+	prefixWord    = 0.8
+	substringWord = 0.6
+	typoWord      = 0.5
+)
+
+// Search returns the addons matching every word of query, best matches first.
+// Equally good matches are ordered by download count, then label.
+func Search(addons []types.Addon, query string) []Match {
+	queryWords := words(query)
+	if len(queryWords) == 0 {
+		return nil
+	}
+	phrase := strings.Join(queryWords, " ")
+
+	var matches []Match
+	for _, addon := range addons {
+		if match, ok := score(addon, queryWords, phrase); ok {
+			matches = append(matches, match)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if downloads(a.Addon) != downloads(b.Addon) {
+			return downloads(a.Addon) > downloads(b.Addon)
+		}
+		return a.Addon.Label < b.Addon.Label
+	})
+	return matches
+}
+
+// score scores addon against the words of a query, false if any word isn't found
+func score(addon types.Addon, queryWords []string, phrase string) (Match, bool) {
+	fieldWords := make([][]string, len(fields))
+	for i, f := range fields {
+		fieldWords[i] = words(f.text(addon))
+	}
+
+	match := Match{Addon: addon}
+	fieldScores := make(map[string]float64)
+	for _, queryWord := range queryWords {
+		best, bestField := 0.0, ""
+		for i, f := range fields {
+			for _, word := range fieldWords[i] {
+				if s := wordScore(queryWord, word) * f.weight; s > best {
+					best, bestField = s, f.name
+				}
+			}
+		}
+		if best == 0 {
+			return Match{}, false
+		}
+		match.Score += best
+		fieldScores[bestField] += best
+	}
+
+	// The whole query being the addon's label or name is what's usually being looked for
+	for _, text := range []string{addon.Label, addon.Name} {
+		whole := strings.Join(words(text), " ")
+		switch {
+		case whole == phrase:
+			match.Score += 2
+		case strings.HasPrefix(whole, phrase):
+			match.Score += 1
+		default:
+			continue
+		}
+		break
+	}
+
+	for name := range fieldScores {
+		match.Fields = append(match.Fields, name)
+	}
+	sort.Slice(match.Fields, func(i, j int) bool {
+		a, b := match.Fields[i], match.Fields[j]
+		if fieldScores[a] != fieldScores[b] {
+			return fieldScores[a] > fieldScores[b]
+		}
+		return a < b
+	})
+	return match, true
+}
+
+// wordScore scores how well a query word matches a word, 0 if it doesn't
+func wordScore(query, word string) float64 {
+	switch {
+	case query == word:
+		return exactWord
+	case strings.HasPrefix(word, query):
+		return prefixWord
+	case len(query) >= 3 && strings.Contains(word, query):
+		return substringWord
+	case withinTypos(query, word):
+		return typoWord
+	}
+	return 0
+}
+
+// withinTypos reports whether two words differ by a typo or two, allowing more in longer words
+func withinTypos(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	allowed := 0
+	switch n := min(len(ra), len(rb)); {
+	case n >= 8:
+		allowed = 2
+	case n >= 4:
+		allowed = 1
+	}
+	if allowed == 0 || abs(len(ra)-len(rb)) > allowed {
+		return false
+	}
+	return distance(ra, rb) <= allowed
+}
+
+// distance returns the edit distance between a and b, counting swapped neighbouring letters as one edit
+// (the optimal string alignment distance)
+func distance(a, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// words splits s into lowercase words without accents, e.g. "Bagnon: Nécessaire" into "bagnon" and "necessaire"
+func words(s string) []string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue // accents, separated from their letter by NFD
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return strings.FieldsFunc(b.String(), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func downloads(addon types.Addon) int {
+	if addon.DownloadCount == nil {
+		return 0
+	}
+	return *addon.DownloadCount
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,71 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func testAddons() []types.Addon {
+	downloads := func(n int) *int { return &n }
+	return []types.Addon{
+		{Source: types.WowInterfaceSource, SourceID: "4815", Name: "bagnon", Label: "Bagnon", Description: "Single window inventory", TagList: []string{"bags"}, DownloadCount: downloads(900000)},
+		{Source: types.WowInterfaceSource, SourceID: "12345", Name: "bagnon-facade", Label: "Bagnon Facade", Description: "Skins for Bagnon", TagList: []string{"bags"}, DownloadCount: downloads(1000)},
+		{Source: types.WowInterfaceSource, SourceID: "23145", Name: "adibags", Label: "AdiBags", Description: "Sorts your inventory into sections", TagList: []string{"bags"}, DownloadCount: downloads(500000)},
+		{Source: types.GitHubSource, SourceID: "owner/necessaire", Name: "necessaire", Label: "Nécessaire", Description: "Vendor junk automatically", TagList: []string{"misc"}},
+		{Source: types.WowInterfaceSource, SourceID: "999", Name: "details", Label: "Details! Damage Meter", Description: "Combat statistics", TagList: []string{"combat"}, DownloadCount: downloads(2000000)},
+	}
+}
+
+func labels(matches []Match) []string {
+	var labels []string
+	for _, match := range matches {
+		labels = append(labels, match.Addon.Label)
+	}
+	return labels
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// The addon named by the query first, then others mentioning it
+		{"bagnon", []string{"Bagnon", "Bagnon Facade"}},
+		{"BAGNON", []string{"Bagnon", "Bagnon Facade"}},
+		{"facade bagnon", []string{"Bagnon Facade"}},
+		// Accents and typos are forgiven
+		{"necessaire", []string{"Nécessaire"}},
+		{"bagnno", []string{"Bagnon", "Bagnon Facade"}},
+		{"damage metre", []string{"Details! Damage Meter"}},
+		// Tags and descriptions count for less than labels, ties go to the most downloaded
+		{"bags", []string{"Bagnon", "AdiBags", "Bagnon Facade"}},
+		{"inventory", []string{"Bagnon", "AdiBags"}},
+		{"23145", []string{"AdiBags"}},
+		{"bagnon combat", nil},
+		{"", nil},
+		{"!!", nil},
+	}
+	for _, tt := range tests {
+		if got := labels(Search(testAddons(), tt.query)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearch_Fields(t *testing.T) {
+	matches := Search(testAddons(), "adibags sections")
+	if len(matches) != 1 {
+		t.Fatalf("Search() = %v, want AdiBags", labels(matches))
+	}
+	if want := []string{"label", "description"}; !reflect.DeepEqual(matches[0].Fields, want) {
+		t.Errorf("Fields = %v, want %v", matches[0].Fields, want)
+	}
+}
+
+func TestWords(t *testing.T) {
+	if got, want := words("Details! Damage-Meter: Nécessaire 2"), []string{"details", "damage", "meter", "necessaire", "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("words() = %v, want %v", got, want)
+	}
+}
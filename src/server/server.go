@@ -0,0 +1,220 @@
+// Package server serves generated catalogues over HTTP so they can be self-hosted without a separate web server.
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/signing"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/state"
+)
+
+// DefaultCacheMaxAge is how long clients may reuse a catalogue before revalidating it with its ETag
+const DefaultCacheMaxAge = 5 * time.Minute
+
+// published are the files of a state directory that are served: the catalogues, the changes feed and the manifest,
+// each with its signature. None of the scrape's run state is.
+var published = func() map[string]bool {
+	names := []string{state.FullCatalogueFile, state.ShortCatalogueFile, state.ChangesFile, signing.ManifestFile}
+	for _, name := range state.SourceCatalogueFiles {
+		names = append(names, name)
+	}
+	files := make(map[string]bool, 2*len(names))
+	for _, name := range names {
+		files[name] = true
+		files[name+signing.SignatureExt] = true
+	}
+	return files
+}()
+
+// file is a served file held in memory with its precomputed ETag and gzip encoding
+type file struct {
+	modTime time.Time
+	size    int64
+	etag    string
+	data    []byte
+	gzipped []byte
+}
+
+// Handler serves the published files of a state directory, e.g. /short-catalogue.json, and /healthz.
+// Files are read on first request and again whenever they change on disk.
+type Handler struct {
+	stateDir    string
+	cacheMaxAge time.Duration
+
+	mu    sync.Mutex
+	files map[string]*file
+}
+
+// NewHandler creates a handler serving the published files in stateDir
+func NewHandler(stateDir string, cacheMaxAge time.Duration) *Handler {
+	return &Handler{
+		stateDir:    stateDir,
+		cacheMaxAge: cacheMaxAge,
+		files:       make(map[string]*file),
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.URL.Path == "/healthz" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		fmt.Fprintln(w, "ok")
+		return
+	}
+
+	// Only published files, nothing that could escape the state directory or give away its run state
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	if !published[name] {
+		http.NotFound(w, r)
+		return
+	}
+
+	f, err := h.load(name)
+	if os.IsNotExist(err) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to load file", "file", name, "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "application/json")
+	if filepath.Ext(name) != ".json" {
+		header.Set("Content-Type", "text/plain; charset=utf-8") // the manifest and signatures
+	}
+	header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.cacheMaxAge.Seconds())))
+	header.Set("ETag", f.etag)
+	header.Set("Last-Modified", f.modTime.UTC().Format(http.TimeFormat))
+	header.Set("Vary", "Accept-Encoding")
+
+	if etagMatches(r.Header.Get("If-None-Match"), f.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body := f.data
+	if acceptsGzip(r) {
+		header.Set("Content-Encoding", "gzip")
+		body = f.gzipped
+	}
+	header.Set("Content-Length", fmt.Sprint(len(body)))
+
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(body)
+}
+
+// load returns the named file, re-reading it if it changed since it was last loaded
+func (h *Handler) load(name string) (*file, error) {
+	path := filepath.Join(h.stateDir, name)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if f, ok := h.files[name]; ok && f.modTime.Equal(info.ModTime()) && f.size == info.Size() {
+		return f, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress %s: %w", name, err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress %s: %w", name, err)
+	}
+
+	sum := sha256.Sum256(data)
+	f := &file{
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		etag:    `"` + hex.EncodeToString(sum[:]) + `"`,
+		data:    data,
+		gzipped: buf.Bytes(),
+	}
+	h.files[name] = f
+	slog.Debug("loaded file", "file", name, "bytes", len(data), "etag", f.etag)
+	return f, nil
+}
+
+// etagMatches returns true if an If-None-Match header value matches etag
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip returns true if the client accepts a gzip encoded response
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		encoding, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.TrimSpace(encoding) == "gzip" && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// ListenAndServe serves handler on addr until ctx is cancelled, then shuts down gracefully
+func ListenAndServe(ctx context.Context, addr string, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errChan:
+		return fmt.Errorf("failed to serve on %s: %w", addr, err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testCatalogue = `{"spec": {"version": 2}, "datestamp": "2024-01-01", "total": 0, "addon-summary-list": []}`
+
+func newTestHandler(t *testing.T) (*Handler, string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "short-catalogue.json"), []byte(testCatalogue), 0644); err != nil {
+		t.Fatalf("failed to write catalogue: %v", err)
+	}
+	return NewHandler(dir, DefaultCacheMaxAge), dir
+}
+
+func TestHandler_ServesCatalogue(t *testing.T) {
+	handler, _ := newTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/short-catalogue.json", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if body := rec.Body.String(); body != testCatalogue {
+		t.Errorf("body = %q, want %q", body, testCatalogue)
+	}
+	if rec.Header().Get("ETag") == "" {
+		t.Errorf("missing ETag header")
+	}
+	if got, want := rec.Header().Get("Cache-Control"), "public, max-age=300"; got != want {
+		t.Errorf("Cache-Control = %q, want %q", got, want)
+	}
+	if rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("Content-Encoding = %q, want none", rec.Header().Get("Content-Encoding"))
+	}
+}
+
+func TestHandler_Gzip(t *testing.T) {
+	handler, _ := newTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/short-catalogue.json", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error: %v", err)
+	}
+	body, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("failed to decompress body: %v", err)
+	}
+	if string(body) != testCatalogue {
+		t.Errorf("body = %q, want %q", body, testCatalogue)
+	}
+}
+
+func TestHandler_ETag(t *testing.T) {
+	handler, dir := newTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/short-catalogue.json", nil))
+	etag := rec.Header().Get("ETag")
+
+	req := httptest.NewRequest(http.MethodGet, "/short-catalogue.json", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+
+	// a changed file gets a new ETag
+	path := filepath.Join(dir, "short-catalogue.json")
+	if err := os.WriteFile(path, []byte(`{"changed": true}`), 0644); err != nil {
+		t.Fatalf("failed to write catalogue: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("failed to touch catalogue: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status after change = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec.Header().Get("ETag") == etag {
+		t.Errorf("ETag unchanged after file changed")
+	}
+}
+
+func TestHandler_NotFound(t *testing.T) {
+	handler, _ := newTestHandler(t)
+
+	for _, path := range []string{"/", "/missing.json", "/../secret.json", "/sub/short-catalogue.json", "/short-catalogue.txt"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s status = %d, want %d", path, rec.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestHandler_Healthz(t *testing.T) {
+	handler, _ := newTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestHandler_OnlyPublishedFiles(t *testing.T) {
+	handler, dir := newTestHandler(t)
+	for _, name := range []string{"run-metadata.json", "failed-urls.json", "dead-letters.json", "short-catalogue.json.minisig", "catalogue.sha256"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		path        string
+		wantStatus  int
+		contentType string
+	}{
+		{"/run-metadata.json", http.StatusNotFound, ""},
+		{"/failed-urls.json", http.StatusNotFound, ""},
+		{"/dead-letters.json", http.StatusNotFound, ""},
+		{"/short-catalogue.json.minisig", http.StatusOK, "text/plain; charset=utf-8"},
+		{"/catalogue.sha256", http.StatusOK, "text/plain; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s status = %d, want %d", tt.path, rec.Code, tt.wantStatus)
+		}
+		if tt.contentType != "" && rec.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("%s Content-Type = %q, want %q", tt.path, rec.Header().Get("Content-Type"), tt.contentType)
+		}
+	}
+}
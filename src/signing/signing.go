@@ -0,0 +1,244 @@
+// Package signing lets strongbox check the catalogues it downloads: a sha256 manifest of the files published and
+// minisign signatures made with an Ed25519 key.
+//
+// Signatures are minisign's original (non-prehashed) "Ed" signatures, which `minisign -V` verifies.
+// Only unencrypted minisign secret keys are read, as generated by `minisign -G -W`.
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ManifestFile is the name of the manifest written alongside catalogues
+const ManifestFile = "catalogue.sha256"
+
+// SignatureExt is appended to the name of a file to get the name of its signature
+const SignatureExt = ".minisig"
+
+// WriteManifest writes the sha256 digest of each file to path in the format of `sha256sum`, so `sha256sum -c` checks them.
+// Files are listed relative to the manifest's directory, sorted.
+func WriteManifest(path string, files []string) error {
+	dir := filepath.Dir(path)
+	var lines []string
+	for _, file := range files {
+		digest, err := fileDigest(file)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, file)
+		if err != nil {
+			return fmt.Errorf("failed to list %s in manifest %s: %w", file, path, err)
+		}
+		lines = append(lines, digest+"  "+filepath.ToSlash(name)+"\n")
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i][66:] < lines[j][66:] })
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "")), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest %s: %w", path, err)
+	}
+	return nil
+}
+
+// fileDigest returns the hex sha256 digest of the file at path
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// minisign key and signature layout
+var (
+	signatureAlgorithm = []byte("Ed")
+	prehashedAlgorithm = []byte("ED")
+	unencryptedKDF     = []byte{0, 0}
+)
+
+const (
+	keyIDSize        = 8
+	secretKeySize    = 2 + 2 + 2 + 32 + 8 + 8 + keyIDSize + ed25519.PrivateKeySize + 32
+	publicKeySize    = 2 + keyIDSize + ed25519.PublicKeySize
+	rawSignatureSize = 2 + keyIDSize + ed25519.SignatureSize
+)
+
+// SecretKey signs files
+type SecretKey struct {
+	id  [keyIDSize]byte
+	key ed25519.PrivateKey
+}
+
+// PublicKey verifies the signatures of files
+type PublicKey struct {
+	id  [keyIDSize]byte
+	key ed25519.PublicKey
+}
+
+// ID returns the key ID as minisign shows it, e.g. "ABCDEF0123456789"
+func (k PublicKey) ID() string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(k.id[:]))
+}
+
+// ReadSecretKey reads an unencrypted minisign secret key file
+func ReadSecretKey(path string) (SecretKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SecretKey{}, fmt.Errorf("failed to read secret key: %w", err)
+	}
+	raw, err := decodeKeyFile(data)
+	if err != nil {
+		return SecretKey{}, fmt.Errorf("failed to read secret key %s: %w", path, err)
+	}
+	if len(raw) != secretKeySize || !bytes.Equal(raw[:2], signatureAlgorithm) {
+		return SecretKey{}, fmt.Errorf("failed to read secret key %s: not a minisign secret key", path)
+	}
+	if !bytes.Equal(raw[2:4], unencryptedKDF) {
+		return SecretKey{}, fmt.Errorf("failed to read secret key %s: encrypted keys aren't supported, generate one with `minisign -G -W`", path)
+	}
+
+	keynum := raw[2+2+2+32+8+8:]
+	var key SecretKey
+	copy(key.id[:], keynum[:keyIDSize])
+	key.key = ed25519.PrivateKey(bytes.Clone(keynum[keyIDSize : keyIDSize+ed25519.PrivateKeySize]))
+	return key, nil
+}
+
+// ParsePublicKey reads a minisign public key, either the contents of its file or the base64 line alone
+func ParsePublicKey(text string) (PublicKey, error) {
+	raw, err := decodeKeyFile([]byte(text))
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("failed to read public key: %w", err)
+	}
+	if len(raw) != publicKeySize || !bytes.Equal(raw[:2], signatureAlgorithm) {
+		return PublicKey{}, errors.New("failed to read public key: not a minisign public key")
+	}
+	var key PublicKey
+	copy(key.id[:], raw[2:2+keyIDSize])
+	key.key = ed25519.PublicKey(bytes.Clone(raw[2+keyIDSize:]))
+	return key, nil
+}
+
+// ReadPublicKey reads a minisign public key from a file, or from the key itself if it isn't a file
+func ReadPublicKey(pathOrKey string) (PublicKey, error) {
+	data, err := os.ReadFile(pathOrKey)
+	if errors.Is(err, os.ErrNotExist) {
+		return ParsePublicKey(pathOrKey)
+	}
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("failed to read public key: %w", err)
+	}
+	return ParsePublicKey(string(data))
+}
+
+// decodeKeyFile returns the base64 decoded key of a key file, skipping its "untrusted comment:" line
+func decodeKeyFile(data []byte) ([]byte, error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "untrusted comment:") {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64: %w", err)
+		}
+		return raw, nil
+	}
+	return nil, errors.New("no key found")
+}
+
+// Sign returns the minisign signature of content, trusting the name it's published under and when it was signed
+func (k SecretKey) Sign(name string, content []byte, now time.Time) []byte {
+	signature := ed25519.Sign(k.key, content)
+	trustedComment := fmt.Sprintf("timestamp:%d\tfile:%s", now.Unix(), name)
+	globalSignature := ed25519.Sign(k.key, append(bytes.Clone(signature), trustedComment...))
+
+	raw := slices.Concat(signatureAlgorithm, k.id[:], signature)
+	var b strings.Builder
+	fmt.Fprintf(&b, "untrusted comment: signature from strongbox-catalogue-builder secret key\n")
+	fmt.Fprintf(&b, "%s\n", base64.StdEncoding.EncodeToString(raw))
+	fmt.Fprintf(&b, "trusted comment: %s\n", trustedComment)
+	fmt.Fprintf(&b, "%s\n", base64.StdEncoding.EncodeToString(globalSignature))
+	return []byte(b.String())
+}
+
+// SignFile writes the signature of the file at path to path + SignatureExt
+func (k SecretKey) SignFile(path string, now time.Time) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s to sign: %w", path, err)
+	}
+	signature := k.Sign(filepath.Base(path), content, now)
+	if err := os.WriteFile(path+SignatureExt, signature, 0644); err != nil {
+		return fmt.Errorf("failed to write signature of %s: %w", path, err)
+	}
+	return nil
+}
+
+// Verify checks signature is k's minisign signature of content, returning its trusted comment
+func (k PublicKey) Verify(content, signature []byte) (string, error) {
+	lines := strings.Split(strings.TrimRight(string(signature), "\n"), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return "", errors.New("not a minisign signature")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(raw) != rawSignatureSize {
+		return "", errors.New("not a minisign signature")
+	}
+	if bytes.Equal(raw[:2], prehashedAlgorithm) {
+		return "", errors.New("prehashed signatures aren't supported, sign with `minisign -S -l`")
+	}
+	if !bytes.Equal(raw[:2], signatureAlgorithm) {
+		return "", errors.New("not a minisign signature")
+	}
+	if !bytes.Equal(raw[2:2+keyIDSize], k.id[:]) {
+		return "", fmt.Errorf("signed with a different key than %s", k.ID())
+	}
+	sig := raw[2+keyIDSize:]
+	if !ed25519.Verify(k.key, content, sig) {
+		return "", errors.New("signature doesn't match")
+	}
+
+	trustedComment := strings.TrimPrefix(lines[2], "trusted comment: ")
+	globalSignature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || !ed25519.Verify(k.key, append(bytes.Clone(sig), trustedComment...), globalSignature) {
+		return "", errors.New("trusted comment signature doesn't match")
+	}
+	return trustedComment, nil
+}
+
+// VerifyFile checks the signature at path + SignatureExt is k's signature of the file at path
+func (k PublicKey) VerifyFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	signature, err := os.ReadFile(path + SignatureExt)
+	if err != nil {
+		return fmt.Errorf("failed to read signature of %s: %w", path, err)
+	}
+	if _, err := k.Verify(content, signature); err != nil {
+		return fmt.Errorf("invalid signature of %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestKey writes an unencrypted minisign secret key to dir, returning its path and the matching public key file contents
+func writeTestKey(t *testing.T, dir string, kdf string) (string, string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	id := make([]byte, keyIDSize)
+	if _, err := rand.Read(id); err != nil {
+		t.Fatalf("failed to generate key id: %v", err)
+	}
+
+	var raw []byte
+	raw = append(raw, "Ed"...)
+	raw = append(raw, kdf...)
+	raw = append(raw, "B2"...)
+	raw = append(raw, make([]byte, 32+8+8)...) // salt, opslimit and memlimit
+	raw = append(raw, id...)
+	raw = append(raw, priv...)
+	raw = append(raw, make([]byte, 32)...) // checksum, not checked
+	path := filepath.Join(dir, "test.key")
+	secret := "untrusted comment: minisign secret key\n" + base64.StdEncoding.EncodeToString(raw) + "\n"
+	if err := os.WriteFile(path, []byte(secret), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	public := "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), id...), pub...)) + "\n"
+	return path, public
+}
+
+func TestWriteManifest(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"short-catalogue.json": "short",
+		"full-catalogue.json":  "full",
+	}
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		paths = append(paths, path)
+	}
+
+	manifestPath := filepath.Join(dir, ManifestFile)
+	if err := WriteManifest(manifestPath, paths); err != nil {
+		t.Fatalf("WriteManifest() unexpected error: %v", err)
+	}
+	got, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to read manifest: %v", err)
+	}
+
+	// Sorted by name, each with the digest of its file
+	lines := strings.Split(strings.TrimSuffix(string(got), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "  full-catalogue.json") || !strings.HasSuffix(lines[1], "  short-catalogue.json") {
+		t.Fatalf("WriteManifest() = %q, want full-catalogue.json then short-catalogue.json", got)
+	}
+	for _, line := range lines {
+		digest, name, _ := strings.Cut(line, "  ")
+		wantDigest, err := fileDigest(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("fileDigest() unexpected error: %v", err)
+		}
+		if digest != wantDigest {
+			t.Errorf("manifest digest of %s = %s, want %s", name, digest, wantDigest)
+		}
+	}
+
+	if err := WriteManifest(manifestPath, []string{filepath.Join(dir, "missing.json")}); err == nil {
+		t.Error("WriteManifest() with a missing file, expected an error")
+	}
+}
+
+func TestFileDigest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalogue.json")
+	if err := os.WriteFile(path, []byte("abc"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" // sha256("abc")
+	if got, err := fileDigest(path); err != nil || got != want {
+		t.Errorf("fileDigest() = %s, %v, want %s", got, err, want)
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	keyPath, publicKeyFile := writeTestKey(t, dir, "\x00\x00")
+	secretKey, err := ReadSecretKey(keyPath)
+	if err != nil {
+		t.Fatalf("ReadSecretKey() unexpected error: %v", err)
+	}
+	publicKey, err := ParsePublicKey(publicKeyFile)
+	if err != nil {
+		t.Fatalf("ParsePublicKey() unexpected error: %v", err)
+	}
+
+	content := []byte(`{"spec": {"version": 2}}`)
+	signedAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	signature := secretKey.Sign("full-catalogue.json", content, signedAt)
+
+	trustedComment, err := publicKey.Verify(content, signature)
+	if err != nil {
+		t.Fatalf("Verify() unexpected error: %v", err)
+	}
+	if want := "timestamp:1704153600\tfile:full-catalogue.json"; trustedComment != want {
+		t.Errorf("Verify() trusted comment = %q, want %q", trustedComment, want)
+	}
+
+	_, otherPublicKeyFile := writeTestKey(t, t.TempDir(), "\x00\x00")
+	otherKey, err := ParsePublicKey(otherPublicKeyFile)
+	if err != nil {
+		t.Fatalf("ParsePublicKey() unexpected error: %v", err)
+	}
+	lines := strings.Split(string(signature), "\n")
+	tamperedComment := strings.Join([]string{lines[0], lines[1], "trusted comment: timestamp:0\tfile:other.json", lines[3]}, "\n")
+
+	tests := []struct {
+		name      string
+		key       PublicKey
+		content   string
+		signature string
+	}{
+		{"changed content", publicKey, `{"spec": {"version": 3}}`, string(signature)},
+		{"different key", otherKey, string(content), string(signature)},
+		{"changed trusted comment", publicKey, string(content), tamperedComment},
+		{"not a signature", publicKey, string(content), "untrusted comment: nope\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.key.Verify([]byte(tt.content), []byte(tt.signature)); err == nil {
+				t.Error("Verify() expected an error")
+			}
+		})
+	}
+}
+
+func TestSignFile(t *testing.T) {
+	dir := t.TempDir()
+	keyPath, publicKeyFile := writeTestKey(t, dir, "\x00\x00")
+	secretKey, err := ReadSecretKey(keyPath)
+	if err != nil {
+		t.Fatalf("ReadSecretKey() unexpected error: %v", err)
+	}
+	publicKeyPath := filepath.Join(dir, "test.pub")
+	if err := os.WriteFile(publicKeyPath, []byte(publicKeyFile), 0644); err != nil {
+		t.Fatalf("failed to write public key: %v", err)
+	}
+
+	path := filepath.Join(dir, "short-catalogue.json")
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatalf("failed to write catalogue: %v", err)
+	}
+	if err := secretKey.SignFile(path, time.Now()); err != nil {
+		t.Fatalf("SignFile() unexpected error: %v", err)
+	}
+
+	// The public key can be given as a file or the key itself
+	publicKeyLine := strings.Split(publicKeyFile, "\n")[1]
+	for _, pathOrKey := range []string{publicKeyPath, publicKeyLine} {
+		publicKey, err := ReadPublicKey(pathOrKey)
+		if err != nil {
+			t.Fatalf("ReadPublicKey(%s) unexpected error: %v", pathOrKey, err)
+		}
+		if err := publicKey.VerifyFile(path); err != nil {
+			t.Errorf("VerifyFile() unexpected error: %v", err)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("[]"), 0644); err != nil {
+		t.Fatalf("failed to write catalogue: %v", err)
+	}
+	publicKey, _ := ReadPublicKey(publicKeyPath)
+	if err := publicKey.VerifyFile(path); err == nil {
+		t.Error("VerifyFile() of a changed file, expected an error")
+	}
+	if err := publicKey.VerifyFile(filepath.Join(dir, "unsigned.json")); err == nil {
+		t.Error("VerifyFile() of an unsigned file, expected an error")
+	}
+}
+
+func TestReadSecretKey_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	encrypted, _ := writeTestKey(t, dir, "Sc")
+	notAKey := filepath.Join(dir, "not-a-key")
+	if err := os.WriteFile(notAKey, []byte("untrusted comment: x\nRWQ=\n"), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	for _, path := range []string{encrypted, notAKey, filepath.Join(dir, "missing.key")} {
+		if _, err := ReadSecretKey(path); err == nil {
+			t.Errorf("ReadSecretKey(%s) expected an error", path)
+		}
+	}
+}
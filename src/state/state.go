@@ -0,0 +1,214 @@
+// Package state is the layout of the state directory a scrape writes its catalogues and run state to, and the
+// writes shared by the commands that read them back.
+package state
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/catalogue"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/signing"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/validation"
+)
+
+// DefaultDir is where scrape writes catalogues and write reads them back from
+const DefaultDir = "state"
+
+// PartialDir is the subdirectory of the state directory the catalogues and run state of partial scrapes are written
+// to, leaving those of the last full scrape for the next scrape to compare and merge with
+const PartialDir = "partial"
+
+const (
+	// FullCatalogueFile holds every addon of the last scrape
+	FullCatalogueFile = "full-catalogue.json"
+
+	// ShortCatalogueFile holds the maintained addons of the last scrape
+	ShortCatalogueFile = "short-catalogue.json"
+
+	// RunMetadataFile records the outcome of the last scrape, including the publish gate verdict
+	RunMetadataFile = "run-metadata.json"
+
+	// PassedCatalogueFile is the full catalogue of the last scrape that passed the publish gate, what the gate
+	// compares the next scrape's catalogue to
+	PassedCatalogueFile = "passed-catalogue.json"
+
+	// ChangesFile lists addons added, updated or removed by each scrape
+	ChangesFile = "changes.json"
+
+	// ChangelogsFile lists the latest changelog of each addon, written by scrape --include-changelogs
+	ChangelogsFile = "changelogs.json"
+
+	// ReleasesFile lists the latest release of each addon per game track, written by scrape --releases
+	ReleasesFile = "releases.json"
+
+	// AuthorsFile lists the WowInterface addons of each author, written by scrape --authors
+	AuthorsFile = "authors.json"
+
+	// ScrapeReportFile summarises what the last scrape fetched, what failed and what was skipped
+	ScrapeReportFile = "scrape-report.json"
+
+	// FailedURLsFile lists the URLs the last scrape couldn't fetch or parse, for a targeted retry
+	FailedURLsFile = "failed-urls.json"
+
+	// RefreshedFile records when the details of each WowInterface addon were last fetched, for --min-refresh-age
+	RefreshedFile = "refreshed.json"
+
+	// DeadLettersFile lists the WowInterface addon pages that kept failing, skipped by scrapes until their cooldown
+	// is over
+	DeadLettersFile = "dead-letters.json"
+
+	// MissingReadmesFile lists the GitHub repositories found without a README, not asked for one again until updated
+	MissingReadmesFile = "missing-readmes.json"
+)
+
+// Files are the run state files, not catalogues
+var Files = []string{RunMetadataFile, ScrapeReportFile, ChangesFile, ChangelogsFile, ReleasesFile, AuthorsFile, FailedURLsFile, DeadLettersFile, RefreshedFile, MissingReadmesFile}
+
+// SourceCatalogueFiles are the catalogues scrape writes of each source's addons
+var SourceCatalogueFiles = map[types.Source]string{
+	types.WowInterfaceSource: "wowinterface-catalogue.json",
+	types.GitHubSource:       "github-catalogue.json",
+	types.GitLabSource:       "gitlab-catalogue.json",
+	types.CodebergSource:     "codeberg-catalogue.json",
+	types.WagoSource:         "wago-catalogue.json",
+	types.TownlongYakSource:  "townlong-yak-catalogue.json",
+}
+
+// ReadPreviousCatalogue reads the catalogue about to be replaced, returning nil if there isn't one
+func ReadPreviousCatalogue(path string) *types.Catalogue {
+	previous, err := catalogue.ReadCatalogue(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("failed to read previous catalogue, changes feed will not list changes", "file", path, "error", err)
+		}
+		return nil
+	}
+	return &previous
+}
+
+// WriteCatalogue writes a catalogue to path as JSON, compact or indented, replacing path only once what was written
+// validates
+func WriteCatalogue(cat types.Catalogue, path string, compact bool) error {
+	writeJSON := catalogue.WriteJSON
+	if compact {
+		writeJSON = catalogue.WriteCompactJSON
+	}
+
+	validate := func(written string) error {
+		if err := validation.ValidateCatalogueFile(written); err != nil {
+			slog.Error("catalogue validation failed after write", "file", path, "error", err)
+			return fmt.Errorf("catalogue validation failed: %w", err)
+		}
+		slog.Info("catalogue validated", "file", path)
+		return nil
+	}
+	if err := writeFile(path, func(w io.Writer) error { return writeJSON(w, cat) }, validate); err != nil {
+		return err
+	}
+	slog.Info("wrote catalogue", "file", path, "addons", cat.Total)
+	return nil
+}
+
+// WriteFile streams write's output into a temporary file beside path and renames it into place, so whatever is
+// reading path, such as the daemon's server, never sees it half-written
+func WriteFile(path string, write func(io.Writer) error) error {
+	return writeFile(path, write, nil)
+}
+
+// writeFile is WriteFile, checking the temporary file with check, if given, before it replaces path
+func writeFile(path string, write func(io.Writer) error, check func(written string) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer os.Remove(f.Name()) // no-op once renamed
+
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write catalogue to %s: %w", path, err)
+	}
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write catalogue to %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write catalogue to %s: %w", path, err)
+	}
+
+	if check != nil {
+		if err := check(f.Name()); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to write catalogue to %s: %w", path, err)
+	}
+	return nil
+}
+
+// UpdateChangesFeed adds the changes between the previous and current catalogue to the feed at path
+func UpdateChangesFeed(builder *catalogue.Builder, previous *types.Catalogue, current types.Catalogue, path string) error {
+	feed, err := catalogue.ReadChangesFeed(path)
+	if err != nil {
+		return err
+	}
+
+	updatedFeed, err := builder.UpdateChangesFeed(feed, previous, current, catalogue.DefaultChangesRetention)
+	if err != nil {
+		return fmt.Errorf("failed to update changes feed: %w", err)
+	}
+
+	if updatedFeed.ETag == feed.ETag {
+		slog.Info("catalogue unchanged, changes feed not updated", "file", path, "etag", feed.ETag)
+		return nil
+	}
+
+	if err := catalogue.WriteChangesFeed(updatedFeed, path); err != nil {
+		return err
+	}
+	slog.Info("wrote changes feed", "file", path, "changes", updatedFeed.Total, "etag", updatedFeed.ETag)
+	return nil
+}
+
+// ReadSignKey reads the minisign secret key catalogues are signed with, nil if path is empty
+func ReadSignKey(path string) (*signing.SecretKey, error) {
+	if path == "" {
+		return nil, nil
+	}
+	key, err := signing.ReadSecretKey(path)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// SealCatalogues writes the sha256 manifest of files to manifestPath if asked to,
+// then signs each file, and the manifest, if there's a key
+func SealCatalogues(files []string, manifestPath string, manifest bool, key *signing.SecretKey) error {
+	if manifest {
+		if err := signing.WriteManifest(manifestPath, files); err != nil {
+			return err
+		}
+		slog.Info("wrote manifest", "file", manifestPath, "files", len(files))
+		files = append(slices.Clone(files), manifestPath)
+	}
+	if key == nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	for _, file := range files {
+		if err := key.SignFile(file, now); err != nil {
+			return err
+		}
+	}
+	slog.Info("signed catalogues", "files", len(files))
+	return nil
+}
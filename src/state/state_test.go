@@ -0,0 +1,43 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/catalogue"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestWriteCatalogue(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FullCatalogueFile)
+
+	var valid types.Catalogue
+	valid.Spec.Version = types.DefaultSpecVersion
+	valid.Datestamp = "2024-01-01"
+	valid.AddonSummaryList = []types.Addon{}
+	if err := WriteCatalogue(valid, path, false); err != nil {
+		t.Fatalf("WriteCatalogue() unexpected error: %v", err)
+	}
+
+	// An invalid catalogue never replaces the one being served
+	if err := WriteCatalogue(types.Catalogue{}, path, false); err == nil {
+		t.Fatal("WriteCatalogue() of an invalid catalogue expected an error")
+	}
+	written, err := catalogue.ReadCatalogue(path)
+	if err != nil {
+		t.Fatalf("ReadCatalogue() unexpected error: %v", err)
+	}
+	if written.Datestamp != valid.Datestamp {
+		t.Errorf("catalogue datestamp = %q, want the valid catalogue's %q", written.Datestamp, valid.Datestamp)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() unexpected error: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("state directory has %d files, want just the catalogue, no temporary files", len(files))
+	}
+}
@@ -0,0 +1,132 @@
+// Package tags normalises addon tags from every source to a shared vocabulary, so the same kind of addon is
+// tagged the same way whichever source it was found in.
+package tags
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// Vocabulary is every canonical tag, sorted.
+// It starts with the tags WowInterface's categories convert to, other sources are mapped onto it with aliases.
+var Vocabulary = []string{
+	"achievements", "action-bars", "arena", "art", "auction-house", "audio",
+	"bags", "bank", "battle-pets", "battlegrounds", "beta-version-addons", "buffs",
+	"carbonite", "chat", "class", "class-compilations", "classic", "combat", "companions", "compilations", "coords", "core",
+	"data", "data-broker", "death-knight", "debuffs", "demon-hunter", "developer-utilities", "discontinued-and-outdated-mods",
+	"dps", "dps-compilations", "druid",
+	"enhancements",
+	"friends", "fubar",
+	"garrisons", "generic-compilations", "graphical-compilations", "group", "guild", "guild-compilations",
+	"healer-compilations", "healers", "hud-designs", "hunter",
+	"info", "info-panel-plugins", "inventory",
+	"layouts", "leveling", "libraries",
+	"mage", "mail", "map", "mini-games", "minimalistic-compilations", "minimap", "misc", "miscellaneous", "monk", "mounts",
+	"nui", "nui+-full-version",
+	"ouf",
+	"paladin", "patches", "pets", "plug-in-bars", "plug-ins", "plugins", "priest", "pvp",
+	"quests",
+	"raid-frames", "rofl", "rogue", "role-play", "role-specific",
+	"shaman",
+	"tank", "tank-compilations", "the-burning-crusade-classic", "titan-panel", "tooltip", "tradeskill", "tradeskill-mods",
+	"ui", "ui-replacements", "unit-frame-panels", "unit-frames", "utilities", "utility",
+	"vendors",
+	"warlock", "warrior", "weakauras", "wow-tools",
+}
+
+// aliases map other spellings of a tag to the canonical tag
+var aliases = map[string]string{
+	"actionbar":      "action-bars",
+	"actionbars":     "action-bars",
+	"action-bar":     "action-bars",
+	"ah":             "auction-house",
+	"auction":        "auction-house",
+	"auctions":       "auction-house",
+	"bag":            "bags",
+	"battlepets":     "battle-pets",
+	"buff":           "buffs",
+	"cooldowns":      "buffs",
+	"debuff":         "debuffs",
+	"dk":             "death-knight",
+	"dh":             "demon-hunter",
+	"healing":        "healers",
+	"leveling-up":    "leveling",
+	"levelling":      "leveling",
+	"lib":            "libraries",
+	"library":        "libraries",
+	"maps":           "map",
+	"music":          "audio",
+	"pet-battles":    "battle-pets",
+	"professions":    "tradeskill",
+	"quest":          "quests",
+	"questing":       "quests",
+	"raid-frame":     "raid-frames",
+	"raidframes":     "raid-frames",
+	"roleplay":       "role-play",
+	"rp":             "role-play",
+	"sounds":         "audio",
+	"tooltips":       "tooltip",
+	"tradeskills":    "tradeskill",
+	"unit-frame":     "unit-frames",
+	"unitframe":      "unit-frames",
+	"unitframes":     "unit-frames",
+	"user-interface": "ui",
+	"weakaura":       "weakauras",
+}
+
+// stopWords are tags too general to tell addons apart, they're dropped
+var stopWords = []string{
+	"addon", "addons", "mod", "mods", "warcraft", "world-of-warcraft", "world-of-warcraft-addon", "wow", "wow-addon", "wow-addons",
+}
+
+// Hook rewrites a tag from a particular source before it's normalised. Returning an empty string drops the tag.
+type Hook func(tag string) string
+
+// sourceHooks adjust the tags of sources with conventions of their own
+var sourceHooks = map[types.Source]Hook{
+	types.GitHubSource:   trimGamePrefix,
+	types.GitLabSource:   trimGamePrefix,
+	types.CodebergSource: trimGamePrefix,
+}
+
+// trimGamePrefix drops the "wow-" forge topics are often prefixed with, e.g. "wow-unitframes"
+func trimGamePrefix(tag string) string {
+	if IsKnown(tag) {
+		return tag
+	}
+	return strings.TrimPrefix(tag, "wow-")
+}
+
+// Normalise returns the tags of an addon from source in the shared vocabulary, sorted and without duplicates.
+// Tags are lowercased and hyphenated, passed through the source's hook, mapped through aliases and stop words are dropped.
+// Tags outside the vocabulary are kept, see IsKnown.
+func Normalise(source types.Source, tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalised := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		tag = strings.Join(strings.FieldsFunc(tag, func(r rune) bool { return r == ' ' || r == '_' || r == '-' }), "-")
+		if hook, ok := sourceHooks[source]; ok {
+			tag = hook(tag)
+		}
+		if canonical, ok := aliases[tag]; ok {
+			tag = canonical
+		}
+		if tag == "" || slices.Contains(stopWords, tag) || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalised = append(normalised, tag)
+	}
+	sort.Strings(normalised)
+	return normalised
+}
+
+// IsKnown returns true if tag is in the vocabulary
+func IsKnown(tag string) bool {
+	_, found := slices.BinarySearch(Vocabulary, tag)
+	return found
+}
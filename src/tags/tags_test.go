@@ -0,0 +1,54 @@
+package tags
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func TestNormalise(t *testing.T) {
+	tests := []struct {
+		name   string
+		source types.Source
+		tags   []string
+		want   []string
+	}{
+		{"empty", types.WowInterfaceSource, nil, []string{}},
+		{"canonical", types.WowInterfaceSource, []string{"unit-frames", "bags"}, []string{"bags", "unit-frames"}},
+		{"aliases", types.WowInterfaceSource, []string{"unitframes", "Unit Frames", "unit_frame"}, []string{"unit-frames"}},
+		{"stop words", types.GitHubSource, []string{"wow-addon", "World of Warcraft", "addon", "bags"}, []string{"bags"}},
+		{"source hook", types.GitHubSource, []string{"wow-unitframes", "wow-quest"}, []string{"quests", "unit-frames"}},
+		{"hook only applies to its sources", types.WowInterfaceSource, []string{"wow-unitframes"}, []string{"wow-unitframes"}},
+		{"hook keeps known tags", types.GitHubSource, []string{"wow-tools"}, []string{"wow-tools"}},
+		{"unknown tags kept", types.GitLabSource, []string{"wow-dragonflight"}, []string{"dragonflight"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalise(tt.source, tt.tags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Normalise(%s, %v) = %v, want %v", tt.source, tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVocabulary(t *testing.T) {
+	if !slices.IsSorted(Vocabulary) {
+		t.Error("Vocabulary isn't sorted")
+	}
+	for alias, canonical := range aliases {
+		if !IsKnown(canonical) {
+			t.Errorf("alias %q maps to %q, which isn't in the vocabulary", alias, canonical)
+		}
+		if IsKnown(alias) {
+			t.Errorf("alias %q is also in the vocabulary", alias)
+		}
+	}
+	for _, tag := range Vocabulary {
+		if got := Normalise(types.WowInterfaceSource, []string{tag}); !reflect.DeepEqual(got, []string{tag}) {
+			t.Errorf("Normalise(%q) = %v, want it unchanged", tag, got)
+		}
+	}
+}
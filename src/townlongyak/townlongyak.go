@@ -0,0 +1,198 @@
+// Package townlongyak scrapes the addons hosted on Townlong Yak from its public addon index and project pages.
+package townlongyak
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gosimple/slug"
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/normalise"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+const (
+	// Host serves the addon index and project pages
+	Host = "www.townlong-yak.com"
+
+	// IndexURL lists every hosted addon
+	IndexURL = "https://" + Host + "/addons/"
+)
+
+// clientGameTracks maps the client column of a project's supported-versions matrix to game tracks.
+// Checked in order, so expansion names come before the generic "classic".
+var clientGameTracks = []struct {
+	client string
+	track  types.GameTrack
+}{
+	{"mists", types.ClassicMistsTrack},
+	{"cataclysm", types.ClassicCataTrack},
+	{"wrath", types.ClassicWotLKTrack},
+	{"burning crusade", types.ClassicTBCTrack},
+	{"classic", types.ClassicTrack},
+	{"retail", types.RetailTrack},
+	{"mainline", types.RetailTrack},
+}
+
+type Parser struct{}
+
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// ParseIndex returns the project page URLs linked from the addon index, each once and in page order
+func ParseIndex(content []byte) ([]string, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Townlong Yak index: %w", err)
+	}
+
+	base, _ := url.Parse(IndexURL)
+	var projectURLs []string
+	seen := make(map[string]bool)
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		u, err := base.Parse(href)
+		if err != nil || u.Host != Host {
+			return
+		}
+		if projectSlug(u) == "" {
+			return
+		}
+		u.RawQuery, u.Fragment = "", ""
+		if !seen[u.String()] {
+			seen[u.String()] = true
+			projectURLs = append(projectURLs, u.String())
+		}
+	})
+	return projectURLs, nil
+}
+
+// projectSlug returns the project of a /addons/<project> URL, or an empty string for any other page
+func projectSlug(u *url.URL) string {
+	rest, ok := strings.CutPrefix(u.Path, "/addons/")
+	if !ok {
+		return ""
+	}
+	rest = strings.TrimSuffix(rest, "/")
+	if rest == "" || strings.Contains(rest, "/") {
+		return ""
+	}
+	return rest
+}
+
+// ParseProject parses a project page into addon data.
+// Game tracks come from the supported-versions matrix, clients marked unsupported are ignored.
+func ParseProject(projectURL string, content []byte) (types.AddonData, error) {
+	u, err := url.Parse(projectURL)
+	if err != nil || projectSlug(u) == "" {
+		return types.AddonData{}, fmt.Errorf("not a Townlong Yak project page: %s", projectURL)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(content))
+	if err != nil {
+		return types.AddonData{}, fmt.Errorf("failed to parse Townlong Yak project page %s: %w", projectURL, err)
+	}
+
+	label := normalise.Text(doc.Find("h1").First().Text())
+	if label == "" {
+		return types.AddonData{}, fmt.Errorf("no project name found on %s", projectURL)
+	}
+	description, _ := doc.Find(`meta[name="description"]`).Attr("content")
+
+	addonData := types.AddonData{
+		Source:       types.TownlongYakSource,
+		SourceID:     projectSlug(u),
+		Kind:         types.WebDetailData, // ranked with the other addon pages when merged
+		Name:         strings.ReplaceAll(slug.Make(label), "_", "-"),
+		Label:        label,
+		Description:  normalise.Text(description),
+		URL:          projectURL,
+		GameTrackSet: parseGameTracks(doc),
+	}
+
+	if datetime, ok := doc.Find(".release time[datetime]").First().Attr("datetime"); ok {
+		updated, err := time.Parse(time.RFC3339, datetime)
+		if err != nil {
+			return types.AddonData{}, fmt.Errorf("failed to parse release date of %s: %w", projectURL, err)
+		}
+		updated = updated.UTC()
+		addonData.UpdatedDate = &updated
+	}
+	return addonData, nil
+}
+
+// parseGameTracks reads the supported-versions matrix, a row per client with the supported interface version or "no"
+func parseGameTracks(doc *goquery.Document) map[types.GameTrack]bool {
+	gameTracks := make(map[types.GameTrack]bool)
+	doc.Find("table.versions tr").Each(func(_ int, row *goquery.Selection) {
+		cells := row.Find("td")
+		if cells.Length() < 2 {
+			return // header
+		}
+		supported := cells.Eq(1)
+		if supported.HasClass("no") || strings.TrimSpace(supported.Text()) == "" {
+			return
+		}
+
+		client := strings.ToLower(normalise.Text(cells.Eq(0).Text()))
+		for _, ct := range clientGameTracks {
+			if strings.Contains(client, ct.client) {
+				gameTracks[ct.track] = true
+				return
+			}
+		}
+		slog.Debug("unknown Townlong Yak client", "client", client)
+	})
+	return gameTracks
+}
+
+// Scrape fetches the addon index and every project page it links to, returning the addon data of each project.
+// Requests go through client, and so its cache.
+func (p *Parser) Scrape(ctx context.Context, client httpclient.HTTPClient) ([]types.AddonData, error) {
+	content, err := fetch(ctx, client, IndexURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Townlong Yak index: %w", err)
+	}
+	projectURLs, err := ParseIndex(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var addonDataList []types.AddonData
+	for _, projectURL := range projectURLs {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		content, err := fetch(ctx, client, projectURL)
+		if err != nil {
+			slog.Warn("skipping Townlong Yak project", "url", projectURL, "error", err)
+			continue
+		}
+		addonData, err := ParseProject(projectURL, content)
+		if err != nil {
+			slog.Warn("skipping Townlong Yak project", "url", projectURL, "error", err)
+			continue
+		}
+		addonDataList = append(addonDataList, addonData)
+	}
+	return addonDataList, nil
+}
+
+func fetch(ctx context.Context, client httpclient.HTTPClient, pageURL string) ([]byte, error) {
+	resp, err := client.Get(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to fetch %s: status %d", pageURL, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,144 @@
+package townlongyak
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+func readFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("test", "fixtures", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseIndex(t *testing.T) {
+	projectURLs, err := ParseIndex(readFile(t, "index.html"))
+	if err != nil {
+		t.Fatalf("ParseIndex() unexpected error: %v", err)
+	}
+
+	// Other pages, other sites and project subpages are ignored, projects linked twice are listed once
+	want := []string{
+		"https://www.townlong-yak.com/addons/opie",
+		"https://www.townlong-yak.com/addons/handynotes-treasures",
+		"https://www.townlong-yak.com/addons/missing",
+	}
+	if !reflect.DeepEqual(projectURLs, want) {
+		t.Errorf("ParseIndex() = %v, want %v", projectURLs, want)
+	}
+}
+
+func TestParseProject(t *testing.T) {
+	projectURL := "https://www.townlong-yak.com/addons/handynotes-treasures"
+	addonData, err := ParseProject(projectURL, readFile(t, "project--handynotes-treasures.html"))
+	if err != nil {
+		t.Fatalf("ParseProject() unexpected error: %v", err)
+	}
+
+	updatedDate := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	want := types.AddonData{
+		Source:      types.TownlongYakSource,
+		SourceID:    "handynotes-treasures",
+		Kind:        types.WebDetailData,
+		Name:        "handynotes-treasures",
+		Label:       "HandyNotes: Treasures",
+		Description: "Treasure & rare locations for HandyNotes",
+		UpdatedDate: &updatedDate,
+		URL:         projectURL,
+		GameTrackSet: map[types.GameTrack]bool{
+			types.RetailTrack:       true,
+			types.ClassicMistsTrack: true,
+			types.ClassicTrack:      true,
+		},
+	}
+	if !reflect.DeepEqual(addonData, want) {
+		t.Errorf("ParseProject() = %+v, want %+v", addonData, want)
+	}
+}
+
+func TestParseProject_NotAProject(t *testing.T) {
+	for _, rawURL := range []string{
+		"https://www.townlong-yak.com/addons/",
+		"https://www.townlong-yak.com/addons/opie/changelog",
+		"https://www.townlong-yak.com/framexml/",
+	} {
+		if _, err := ParseProject(rawURL, readFile(t, "project--opie.html")); err == nil {
+			t.Errorf("ParseProject(%s) expected an error", rawURL)
+		}
+	}
+}
+
+func TestScrape(t *testing.T) {
+	client := httpclient.NewMockHTTPClient()
+	client.SetResponse(IndexURL, &httpclient.Response{StatusCode: 200, Body: readFile(t, "index.html")})
+	client.SetResponse("https://www.townlong-yak.com/addons/opie", &httpclient.Response{StatusCode: 200, Body: readFile(t, "project--opie.html")})
+	client.SetResponse("https://www.townlong-yak.com/addons/handynotes-treasures", &httpclient.Response{StatusCode: 200, Body: readFile(t, "project--handynotes-treasures.html")})
+	client.SetResponse("https://www.townlong-yak.com/addons/missing", &httpclient.Response{StatusCode: 404})
+
+	addonDataList, err := NewParser().Scrape(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+
+	// The missing project is skipped
+	if len(addonDataList) != 2 {
+		t.Fatalf("Scrape() = %d projects, want 2", len(addonDataList))
+	}
+	opie := addonDataList[0]
+	if opie.SourceID != "opie" || !opie.GameTrackSet[types.RetailTrack] || len(opie.GameTrackSet) != 1 {
+		t.Errorf("Scrape() first project = %+v, want opie on retail only", opie)
+	}
+	if want := time.Date(2025, 6, 10, 6, 30, 0, 0, time.UTC); opie.UpdatedDate == nil || !opie.UpdatedDate.Equal(want) {
+		t.Errorf("Scrape() opie updated date = %v, want %v", opie.UpdatedDate, want)
+	}
+}
+
+func TestScrape_IndexError(t *testing.T) {
+	client := httpclient.NewMockHTTPClient()
+	client.SetResponse(IndexURL, &httpclient.Response{StatusCode: 503})
+
+	if _, err := NewParser().Scrape(context.Background(), client); err == nil {
+		t.Error("Scrape() with a failed index, expected an error")
+	}
+}
+
+func TestScrape_SkipsProjects(t *testing.T) {
+	index := `<a href="/addons/good">Good</a> <a href="/addons/broken">Broken</a> <a href="/addons/good#files">Good</a>`
+	good := `<h1>Good</h1><table class="versions"><tr><td>Retail</td><td>110105</td></tr></table>`
+	cases := []struct {
+		name   string
+		broken *httpclient.Response
+	}{
+		{"gone", &httpclient.Response{StatusCode: 410}},
+		{"no name", &httpclient.Response{StatusCode: 200, Body: []byte(`<p>Under construction</p>`)}},
+		{"bad release date", &httpclient.Response{StatusCode: 200, Body: []byte(`<h1>Broken</h1><div class="release"><time datetime="yesterday">yesterday</time></div>`)}},
+	}
+	for _, tc := range cases {
+		client := httpclient.NewMockHTTPClient()
+		client.SetResponse(IndexURL, &httpclient.Response{StatusCode: 200, Body: []byte(index)})
+		client.SetResponse("https://www.townlong-yak.com/addons/good", &httpclient.Response{StatusCode: 200, Body: []byte(good)})
+		client.SetResponse("https://www.townlong-yak.com/addons/broken", tc.broken)
+
+		addonDataList, err := NewParser().Scrape(context.Background(), client)
+		if err != nil {
+			t.Fatalf("Scrape() with a project page %s, unexpected error: %v", tc.name, err)
+		}
+		// The other projects are still scraped, and a project linked twice is fetched once
+		if len(addonDataList) != 1 || addonDataList[0].SourceID != "good" {
+			t.Errorf("Scrape() with a project page %s = %+v, want just the good project", tc.name, addonDataList)
+		}
+		if calls := client.GetCalls(); len(calls) != 3 {
+			t.Errorf("Scrape() with a project page %s fetched %v, want the index and each project once", tc.name, calls)
+		}
+	}
+}
@@ -25,49 +25,143 @@ type Source string
 const (
 	WowInterfaceSource Source = "wowinterface"
 	GitHubSource       Source = "github"
+	GitLabSource       Source = "gitlab"
+	CodebergSource     Source = "codeberg"
+	WagoSource         Source = "wago"
+	TownlongYakSource  Source = "townlong-yak"
+)
+
+var AllSources = []Source{WowInterfaceSource, GitHubSource, GitLabSource, CodebergSource, WagoSource, TownlongYakSource}
+
+// AddonStatus is the state of an addon on its source
+type AddonStatus string
+
+const (
+	ActiveStatus    AddonStatus = "active"
+	RemovedStatus   AddonStatus = "removed"   // taken down, by its author or the source
+	PendingStatus   AddonStatus = "pending"   // waiting for approval or review before it's listed
+	AbandonedStatus AddonStatus = "abandoned" // still available but given up by its author
 )
 
 // Addon represents a WoW addon
 // Note: keep fields alphabetised for deterministic JSON output
 type Addon struct {
-	CreatedDate   *time.Time  `json:"created-date,omitempty"`
-	Description   string      `json:"description,omitempty"`
-	DownloadCount *int        `json:"download-count,omitempty"`
-	GameTrackList []GameTrack `json:"game-track-list"`
-	Label         string      `json:"label"`
-	Name          string      `json:"name"`
-	Source        Source      `json:"source"`
-	SourceID      string      `json:"source-id"`
-	TagList       []string    `json:"tag-list,omitempty"`
-	URL           string      `json:"url"`
-	UpdatedDate   time.Time   `json:"updated-date"`
+	Archived             bool         `json:"archived,omitempty"`
+	Author               string       `json:"author,omitempty"`    // spec version 3 only
+	Changelog            string       `json:"changelog,omitempty"` // latest changelog, only kept with scrape --include-changelogs
+	CreatedDate          *time.Time   `json:"created-date,omitempty"`
+	DependencyList       []Dependency `json:"dependency-list,omitempty"` // only kept with scrape --with-dependencies
+	Description          string       `json:"description,omitempty"`
+	DownloadCount        *int         `json:"download-count,omitempty"`
+	FavoriteCount        *int         `json:"favorite-count,omitempty"` // only kept with scrape --extended-fields
+	FolderList           []string     `json:"folder-list,omitempty"`    // addon folders the download unpacks to, spec version 3 only
+	GameTrackList        []GameTrack  `json:"game-track-list"`
+	ImageURL             string       `json:"image-url,omitempty"` // first screenshot, only kept with scrape --include-images
+	Label                string       `json:"label"`
+	MonthlyDownloadCount *int         `json:"monthly-download-count,omitempty"` // only kept with scrape --extended-fields
+	Name                 string       `json:"name"`
+	Popularity           *float64     `json:"popularity,omitempty"`   // percentile of download-count within the source, 0 to 1, only kept with scrape --popularity
+	ReleaseList          []Release    `json:"release-list,omitempty"` // latest release per game track, spec version 3 only
+	SameAs               []AddonRef   `json:"same-as,omitempty"`      // the same addon published to other sources
+	Source               Source       `json:"source"`
+	SourceID             string       `json:"source-id"`
+	TagList              []string     `json:"tag-list,omitempty"`
+	UpdatedDate          time.Time    `json:"updated-date"`
+	URL                  string       `json:"url"`
+}
+
+// AddonRef identifies an addon within a source
+type AddonRef struct {
+	Source   Source `json:"source"`
+	SourceID string `json:"source-id"`
 }
 
+// DataKind is the kind of page or API response addon data was parsed from, deciding its priority when merged
+type DataKind string
+
+const (
+	ListingData     DataKind = "listing"      // a category listing page
+	WebDetailData   DataKind = "web-detail"   // an addon's page
+	APIFileListData DataKind = "api-filelist" // an addon's entry in the API's filelist
+	APIDetailData   DataKind = "api-detail"   // the API's details of an addon
+)
+
+// KnownDataKinds are every kind of addon data
+var KnownDataKinds = []DataKind{ListingData, WebDetailData, APIFileListData, APIDetailData}
+
 // AddonData represents parsed addon data that may be incomplete
 type AddonData struct {
-	Source           Source                 `json:"source"`
-	SourceID         string                 `json:"source-id"`
-	Filename         string                 `json:"filename"`
-	Name             string                 `json:"name,omitempty"`
-	Label            string                 `json:"label,omitempty"`
-	Description      string                 `json:"description,omitempty"`
-	UpdatedDate      *time.Time             `json:"updated-date,omitempty"`
-	CreatedDate      *time.Time             `json:"created-date,omitempty"`
-	DownloadCount    *int                   `json:"download-count,omitempty"`
-	GameTrackSet     map[GameTrack]bool     `json:"game-track-set,omitempty"`
-	TagSet           map[string]bool        `json:"tag-set,omitempty"`
-	URL              string                 `json:"url,omitempty"`
-	LatestReleaseSet []Release              `json:"latest-release-set,omitempty"`
-	WoWI             map[string]interface{} `json:"wowi,omitempty"` // WowInterface specific data
+	Source               Source                 `json:"source"`
+	SourceID             string                 `json:"source-id"`
+	Kind                 DataKind               `json:"kind"`
+	APIVersion           string                 `json:"api-version,omitempty"` // of API data, e.g. v4
+	Name                 string                 `json:"name,omitempty"`
+	Label                string                 `json:"label,omitempty"`
+	Author               string                 `json:"author,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Changelog            string                 `json:"changelog,omitempty"`
+	UpdatedDate          *time.Time             `json:"updated-date,omitempty"`
+	CreatedDate          *time.Time             `json:"created-date,omitempty"`
+	DownloadCount        *int                   `json:"download-count,omitempty"`
+	FavoriteCount        *int                   `json:"favorite-count,omitempty"`
+	MonthlyDownloadCount *int                   `json:"monthly-download-count,omitempty"`
+	GameTrackSet         map[GameTrack]bool     `json:"game-track-set,omitempty"`
+	TagSet               map[string]bool        `json:"tag-set,omitempty"`
+	URL                  string                 `json:"url,omitempty"`
+	Archived             bool                   `json:"archived,omitempty"` // found in a legacy/archived section
+	Status               AddonStatus            `json:"status,omitempty"`   // empty if the source doesn't say, taken as active
+	LatestReleaseSet     []Release              `json:"latest-release-set,omitempty"`
+	ImageList            []Image                `json:"image-list,omitempty"`         // screenshots, in the order the source lists them
+	FolderList           []string               `json:"folder-list,omitempty"`        // addon folders the download unpacks to
+	InterfaceVersions    map[GameTrack][]int    `json:"interface-versions,omitempty"` // interface versions supported per game track, e.g. 110005 for 11.0.5
+	DependencyList       []Dependency           `json:"dependency-list,omitempty"`    // files the addon needs or can use
+	WoWI                 map[string]interface{} `json:"wowi,omitempty"`               // WowInterface specific data
+}
+
+// Filename names the file the data is kept in, e.g. api-detail-v4.json, one per kind and API version
+func (d AddonData) Filename() string {
+	if d.APIVersion == "" {
+		return string(d.Kind) + ".json"
+	}
+	return string(d.Kind) + "-" + d.APIVersion + ".json"
 }
 
 // Release represents a downloadable release
+// Note: keep fields alphabetised for deterministic JSON output
 type Release struct {
-	DownloadURL string    `json:"download-url"`
-	Version     string    `json:"version,omitempty"`
-	GameTrack   GameTrack `json:"game-track,omitempty"`
+	Checksum      string    `json:"checksum,omitempty"` // MD5 hex digest of the download, when the source reports it
+	DownloadURL   string    `json:"download-url"`
+	GameTrack     GameTrack `json:"game-track,omitempty"`
+	InterfaceList []int     `json:"interface-list,omitempty"` // interface versions supported, e.g. 110005 for 11.0.5
+	Size          int64     `json:"size,omitempty"`           // bytes, when the source reports it
+	Version       string    `json:"version,omitempty"`
+}
+
+// Dependency is another file an addon needs, or an optional file it can use
+// Note: keep fields alphabetised for deterministic JSON output
+type Dependency struct {
+	Label    string `json:"label"`
+	Required bool   `json:"required,omitempty"`  // false for optional files
+	SourceID string `json:"source-id,omitempty"` // when the file is another addon in the same source
+	URL      string `json:"url"`
+}
+
+// Image is a screenshot of an addon
+type Image struct {
+	URL         string `json:"url"`
+	ThumbURL    string `json:"thumb-url,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
+// Catalogue spec versions. Version 3 adds each addon's author, latest releases and addon folders.
+const (
+	SpecVersion2       = 2
+	SpecVersion3       = 3
+	DefaultSpecVersion = SpecVersion2
+)
+
+var KnownSpecVersions = []int{SpecVersion2, SpecVersion3}
+
 // Catalogue represents the output catalogue structure
 type Catalogue struct {
 	Spec struct {
@@ -85,9 +179,19 @@ type DownloadResult struct {
 	Error    error
 }
 
+// AuthorData is what a single page says about an addon author, merged with other pages into an author's entry
+type AuthorData struct {
+	AuthorID  string   `json:"author-id"`
+	Name      string   `json:"name,omitempty"`
+	SourceIDs []string `json:"source-ids,omitempty"` // addons by the author
+	URL       string   `json:"url"`                  // page listing the author's addons
+}
+
 // ParseResult represents the result of parsing downloaded content
 type ParseResult struct {
-	AddonData    []AddonData `json:"addon-data,omitempty"`
-	DownloadURLs []string    `json:"download-urls,omitempty"`
-	Error        error       `json:"-"`
+	AddonData    []AddonData          `json:"addon-data,omitempty"`
+	AuthorData   []AuthorData         `json:"author-data,omitempty"`
+	DownloadURLs []string             `json:"download-urls,omitempty"`
+	UpdatedDates map[string]time.Time `json:"updated-dates,omitempty"` // download URL -> last known update of its content
+	Error        error                `json:"-"`
 }
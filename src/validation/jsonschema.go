@@ -0,0 +1,9 @@
+package validation
+
+import _ "embed"
+
+// CatalogueJSONSchema is a JSON Schema (draft 2020-12) document describing the catalogue format,
+// for consumers that validate catalogues independently. It's kept in step with the validator by tests.
+//
+//go:embed catalogue.schema.json
+var CatalogueJSONSchema []byte
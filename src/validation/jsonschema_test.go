@@ -0,0 +1,116 @@
+package validation
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+type testSchemaDef struct {
+	Required   []string                   `json:"required"`
+	Properties map[string]json.RawMessage `json:"properties"`
+	Enum       []string                   `json:"enum"`
+}
+
+type testSchema struct {
+	testSchemaDef
+	Defs map[string]testSchemaDef `json:"$defs"`
+}
+
+func loadTestSchema(t *testing.T) testSchema {
+	t.Helper()
+	var schema testSchema
+	if err := json.Unmarshal(CatalogueJSONSchema, &schema); err != nil {
+		t.Fatalf("CatalogueJSONSchema is not valid JSON: %v", err)
+	}
+	return schema
+}
+
+// jsonFields returns the JSON field names of a struct
+func jsonFields(v any) []string {
+	var fields []string
+	typ := reflect.TypeOf(v)
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, name)
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+func sortedProperties(def testSchemaDef) []string {
+	var names []string
+	for name := range def.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestCatalogueJSONSchema_MatchesTypes(t *testing.T) {
+	schema := loadTestSchema(t)
+
+	if got, want := sortedProperties(schema.Defs["addon"]), jsonFields(types.Addon{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("addon properties = %v, want %v", got, want)
+	}
+	if got, want := sortedProperties(schema.Defs["addon-ref"]), jsonFields(types.AddonRef{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("addon-ref properties = %v, want %v", got, want)
+	}
+	if got, want := sortedProperties(schema.Defs["release"]), jsonFields(types.Release{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("release properties = %v, want %v", got, want)
+	}
+	if got, want := sortedProperties(schema.testSchemaDef), jsonFields(types.Catalogue{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("catalogue properties = %v, want %v", got, want)
+	}
+
+	if !reflect.DeepEqual(schema.Defs["source"].Enum, ValidSources) {
+		t.Errorf("source enum = %v, want %v", schema.Defs["source"].Enum, ValidSources)
+	}
+	if !reflect.DeepEqual(schema.Defs["game-track"].Enum, ValidGameTracks) {
+		t.Errorf("game-track enum = %v, want %v", schema.Defs["game-track"].Enum, ValidGameTracks)
+	}
+}
+
+func TestCatalogueJSONSchema_MatchesValidator(t *testing.T) {
+	schema := loadTestSchema(t)
+
+	// Removing any field the schema requires must fail validation, and only those
+	valid := map[string]any{
+		"source":          "wowinterface",
+		"source-id":       "1",
+		"name":            "addon",
+		"label":           "Addon",
+		"updated-date":    "2024-01-01T00:00:00Z",
+		"url":             "https://www.wowinterface.com/downloads/info1",
+		"game-track-list": []any{"retail"},
+		"description":     "An addon",
+		"tag-list":        []any{"bags"},
+	}
+	for field := range valid {
+		addon := make(map[string]any)
+		for k, v := range valid {
+			if k != field {
+				addon[k] = v
+			}
+		}
+		catalogue := map[string]any{
+			"spec":               map[string]any{"version": 2},
+			"datestamp":          "2024-01-01",
+			"total":              1,
+			"addon-summary-list": []any{addon},
+		}
+
+		err := SimpleValidateCatalogue(catalogue)
+		required := slices.Contains(schema.Defs["addon"].Required, field)
+		if required && err == nil {
+			t.Errorf("schema requires %s but the validator accepts an addon without it", field)
+		}
+		if !required && err != nil {
+			t.Errorf("schema doesn't require %s but the validator rejects an addon without it: %v", field, err)
+		}
+	}
+}
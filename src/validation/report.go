@@ -0,0 +1,188 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+)
+
+// FileResult is the outcome of validating a single catalogue file
+type FileResult struct {
+	File          string      `json:"file"`
+	Valid         bool        `json:"valid"`
+	Error         string      `json:"error,omitempty"` // the file couldn't be read or parsed
+	ViolationList []Violation `json:"violation-list,omitempty"`
+}
+
+// NewFileResult creates the result of validating file, where err is the error validation returned
+func NewFileResult(file string, err error) FileResult {
+	result := FileResult{File: file, Valid: err == nil}
+
+	var validationErr *ValidationError
+	switch {
+	case err == nil:
+	case errors.As(err, &validationErr):
+		result.ViolationList = validationErr.Violations
+	default:
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Report is the outcome of validating a set of catalogue files, for CI pipelines to parse
+type Report struct {
+	Total          int          `json:"total"`
+	Passed         int          `json:"passed"`
+	Failed         int          `json:"failed"`
+	ViolationCount int          `json:"violation-count"`
+	FileList       []FileResult `json:"file-list"`
+}
+
+// NewReport summarises the results of validating files
+func NewReport(results []FileResult) Report {
+	report := Report{Total: len(results), FileList: results}
+	for _, result := range results {
+		if result.Valid {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.ViolationCount += len(result.ViolationList)
+	}
+	if report.FileList == nil {
+		report.FileList = []FileResult{}
+	}
+	return report
+}
+
+// WriteJSON writes the report as indented JSON
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("failed to write validation report: %w", err)
+	}
+	return nil
+}
+
+// SARIF 2.1.0, just enough for code scanning tools to annotate failures.
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+
+	// unreadableRule is the rule reported for files that couldn't be read or parsed
+	unreadableRule = "unreadable"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// arrayIndex matches the indices in a violation's field, so "game-track-list[1]" is reported as "game-track-list"
+var arrayIndex = regexp.MustCompile(`\[\d+\]`)
+
+// WriteSARIF writes the report as a SARIF log with one result per violation
+func (r Report) WriteSARIF(w io.Writer) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "strongbox-catalogue-builder",
+			InformationURI: "https://github.com/ogri-la/strongbox-catalogue-builder-go",
+		}},
+		Results: []sarifResult{},
+	}
+
+	rules := make(map[string]bool)
+	addResult := func(file, ruleID, message, path string) {
+		rules[ruleID] = true
+		location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: file}}}
+		if path != "" {
+			location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: path}}
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    ruleID,
+			Level:     "error",
+			Message:   sarifMessage{Text: message},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	for _, result := range r.FileList {
+		if result.Error != "" {
+			addResult(result.File, unreadableRule, result.Error, "")
+		}
+		for _, violation := range result.ViolationList {
+			addResult(result.File, arrayIndex.ReplaceAllString(violation.Field, ""), violation.Error(), violation.Path())
+		}
+	}
+
+	ruleIDs := make([]string, 0, len(rules))
+	for id := range rules {
+		ruleIDs = append(ruleIDs, id)
+	}
+	sort.Strings(ruleIDs)
+	run.Tool.Driver.Rules = []sarifRule{}
+	for _, id := range ruleIDs {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: id})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}); err != nil {
+		return fmt.Errorf("failed to write SARIF report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func testResults() []FileResult {
+	violations := &ValidationError{Violations: []Violation{
+		{Index: 2, SourceID: "123", Field: "game-track-list[0]", Message: "must be a valid game track"},
+		{Index: -1, Field: "datestamp", Message: "is required and must be a string"},
+	}}
+	return []FileResult{
+		NewFileResult("state/full-catalogue.json", nil),
+		NewFileResult("state/short-catalogue.json", violations),
+		NewFileResult("state/missing.json", errors.New("failed to read file")),
+	}
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	report := NewReport(testResults())
+	if report.Total != 3 || report.Passed != 1 || report.Failed != 2 || report.ViolationCount != 2 {
+		t.Errorf("NewReport() = %+v, want 3 total, 1 passed, 2 failed, 2 violations", report)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() unexpected error: %v", err)
+	}
+
+	var got Report
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("WriteJSON() wrote invalid JSON: %v", err)
+	}
+	if got.FileList[1].ViolationList[0].SourceID != "123" {
+		t.Errorf("ViolationList[0].SourceID = %q, want 123", got.FileList[1].ViolationList[0].SourceID)
+	}
+	if got.FileList[2].Error != "failed to read file" {
+		t.Errorf("FileList[2].Error = %q, want \"failed to read file\"", got.FileList[2].Error)
+	}
+}
+
+func TestReport_WriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewReport(testResults()).WriteSARIF(&buf); err != nil {
+		t.Fatalf("WriteSARIF() unexpected error: %v", err)
+	}
+
+	var got sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("WriteSARIF() wrote invalid JSON: %v", err)
+	}
+	if got.Version != "2.1.0" || len(got.Runs) != 1 {
+		t.Fatalf("WriteSARIF() version = %s with %d runs, want 2.1.0 with 1 run", got.Version, len(got.Runs))
+	}
+
+	run := got.Runs[0]
+	wantRules := []string{"datestamp", "game-track-list", unreadableRule}
+	if len(run.Tool.Driver.Rules) != len(wantRules) {
+		t.Fatalf("rules = %v, want %v", run.Tool.Driver.Rules, wantRules)
+	}
+	for i, rule := range run.Tool.Driver.Rules {
+		if rule.ID != wantRules[i] {
+			t.Errorf("rules[%d] = %s, want %s", i, rule.ID, wantRules[i])
+		}
+	}
+
+	if len(run.Results) != 3 {
+		t.Fatalf("results = %d, want 3", len(run.Results))
+	}
+	first := run.Results[0]
+	if first.RuleID != "game-track-list" || first.Locations[0].PhysicalLocation.ArtifactLocation.URI != "state/short-catalogue.json" {
+		t.Errorf("results[0] = %+v", first)
+	}
+	if name := first.Locations[0].LogicalLocations[0].FullyQualifiedName; name != "addon-summary-list[2].game-track-list[0]" {
+		t.Errorf("results[0] logical location = %s, want addon-summary-list[2].game-track-list[0]", name)
+	}
+}
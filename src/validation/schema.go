@@ -2,6 +2,7 @@ package validation
 
 import (
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/Oudwins/zog"
@@ -22,6 +23,10 @@ var ValidGameTracks = []string{
 var ValidSources = []string{
 	string(types.WowInterfaceSource),
 	string(types.GitHubSource),
+	string(types.GitLabSource),
+	string(types.CodebergSource),
+	string(types.WagoSource),
+	string(types.TownlongYakSource),
 }
 
 // isValidSource checks if a string is a valid source
@@ -102,7 +107,7 @@ func isValidDateStringPtr(val *string, ctx zog.Ctx) bool {
 
 // AddonSchema validates an Addon structure (using PascalCase field names)
 var AddonSchema = zog.Struct(zog.Schema{
-	"Source":        zog.String().Required().OneOf(ValidSources, zog.Message("source must be one of: wowinterface, github")),
+	"Source":        zog.String().Required().OneOf(ValidSources, zog.Message("source must be one of: "+strings.Join(ValidSources, ", "))),
 	"SourceId":      zog.String().Required().Min(1, zog.Message("source-id must be a non-empty string")),
 	"Name":          zog.String().Required().Min(1, zog.Message("name must be a non-empty string")),
 	"Label":         zog.String().Required().Min(1, zog.Message("label must be a non-empty string")),
@@ -1,175 +1,407 @@
 package validation
 
 import (
+	"encoding/hex"
 	"fmt"
+	"strings"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
 )
 
-// SimpleValidateCatalogue validates a catalogue using simple custom logic
-func SimpleValidateCatalogue(data map[string]any) error {
-	// Validate spec
-	spec, ok := data["spec"].(map[string]any)
-	if !ok {
-		return fmt.Errorf("validation failed: spec is required and must be an object")
+// Violation is a single problem found in a catalogue
+type Violation struct {
+	Index    int    `json:"index"`               // index of the addon in addon-summary-list, -1 for catalogue-level problems
+	SourceID string `json:"source-id,omitempty"` // source-id of the addon, if it has one
+	Field    string `json:"field"`               // e.g. "url" or "game-track-list[1]"
+	Message  string `json:"message"`             // e.g. "must be a valid URL"
+}
+
+// Path returns the location of the problem within the catalogue, e.g. "addon-summary-list[3].url"
+func (v Violation) Path() string {
+	if v.Index < 0 {
+		return v.Field
 	}
+	return fmt.Sprintf("addon-summary-list[%d].%s", v.Index, v.Field)
+}
 
-	version, ok := spec["version"]
-	if !ok {
-		return fmt.Errorf("validation failed: spec.version is required")
+func (v Violation) Error() string {
+	msg := v.Path() + " " + v.Message
+	if v.SourceID != "" {
+		msg += fmt.Sprintf(" (source-id %q)", v.SourceID)
 	}
+	return msg
+}
 
-	versionInt, ok := getInt(version)
-	if !ok || versionInt < 1 {
-		return fmt.Errorf("validation failed: spec.version must be an integer >= 1")
+// ValidationError holds every violation found in a catalogue
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Error()
+	}
+	if len(messages) == 1 {
+		return "validation failed: " + messages[0]
 	}
+	return fmt.Sprintf("validation failed: %d problems: %s", len(messages), strings.Join(messages, "; "))
+}
 
-	// Validate datestamp
-	datestamp, ok := data["datestamp"].(string)
-	if !ok {
-		return fmt.Errorf("validation failed: datestamp is required and must be a string")
+// Unwrap returns each violation so the error can be inspected with errors.Is and errors.As
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Violations))
+	for i, v := range e.Violations {
+		errs[i] = v
 	}
+	return errs
+}
+
+// violations collects problems as a catalogue is validated
+type violations []Violation
 
-	if !isValidDateString(datestamp) {
-		return fmt.Errorf("validation failed: datestamp must be a valid date string (RFC3339 or YYYY-MM-DD)")
+func (vs *violations) catalogue(field, format string, args ...any) {
+	*vs = append(*vs, Violation{Index: -1, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// SimpleValidateCatalogue validates a catalogue using simple custom logic.
+// Every problem is collected rather than stopping at the first, the returned error is a *ValidationError.
+func SimpleValidateCatalogue(data map[string]any) error {
+	var found violations
+
+	// Validate spec, the version selects the rules addons are validated with
+	specVersion := 0
+	if spec, ok := data["spec"].(map[string]any); !ok {
+		found.catalogue("spec", "is required and must be an object")
+	} else if version, ok := spec["version"]; !ok {
+		found.catalogue("spec.version", "is required")
+	} else if versionInt, ok := getInt(version); !ok || versionInt < 1 {
+		found.catalogue("spec.version", "must be an integer >= 1")
+	} else {
+		specVersion = versionInt
+	}
+
+	// Validate datestamp
+	if datestamp, ok := data["datestamp"].(string); !ok {
+		found.catalogue("datestamp", "is required and must be a string")
+	} else if !isValidDateString(datestamp) {
+		found.catalogue("datestamp", "must be a valid date string (RFC3339 or YYYY-MM-DD)")
 	}
 
 	// Validate total
-	total, ok := getInt(data["total"])
-	if !ok || total < 0 {
-		return fmt.Errorf("validation failed: total is required and must be a non-negative integer")
+	total, totalOK := getInt(data["total"])
+	if !totalOK || total < 0 {
+		found.catalogue("total", "is required and must be a non-negative integer")
 	}
 
 	// Validate addon-summary-list
 	addonListRaw, ok := data["addon-summary-list"]
 	if !ok {
-		return fmt.Errorf("validation failed: addon-summary-list is required")
+		found.catalogue("addon-summary-list", "is required")
+		return found.err()
 	}
 
 	addonList, ok := addonListRaw.([]any)
 	if !ok {
-		return fmt.Errorf("validation failed: addon-summary-list must be an array")
+		found.catalogue("addon-summary-list", "must be an array")
+		return found.err()
 	}
 
 	// Validate total matches addon count
-	if total != len(addonList) {
-		return fmt.Errorf("validation failed: total (%d) must equal the number of addons in addon-summary-list (%d)", total, len(addonList))
+	if totalOK && total >= 0 && total != len(addonList) {
+		found.catalogue("total", "(%d) must equal the number of addons in addon-summary-list (%d)", total, len(addonList))
 	}
 
 	// Validate each addon
 	for i, addonRaw := range addonList {
 		addon, ok := addonRaw.(map[string]any)
 		if !ok {
-			return fmt.Errorf("validation failed: addon-summary-list[%d] must be an object", i)
+			found.catalogue(fmt.Sprintf("addon-summary-list[%d]", i), "must be an object")
+			continue
+		}
+		validateAddon(addon, i, specVersion, &found)
+	}
+
+	validateUnique(addonList, &found)
+
+	return found.err()
+}
+
+// validateUnique finds addons listed more than once, by source and source-id or by URL.
+// Duplicates break matching installed addons to catalogue entries in strongbox.
+func validateUnique(addonList []any, found *violations) {
+	seenIDs := make(map[string]int)
+	seenURLs := make(map[string]int)
+
+	for i, addonRaw := range addonList {
+		addon, ok := addonRaw.(map[string]any)
+		if !ok {
+			continue
+		}
+		source, _ := addon["source"].(string)
+		sourceID, _ := addon["source-id"].(string)
+		add := func(field, format string, args ...any) {
+			*found = append(*found, Violation{Index: i, SourceID: sourceID, Field: field, Message: fmt.Sprintf(format, args...)})
 		}
 
-		if err := validateAddon(addon, i); err != nil {
-			return err
+		if sourceID != "" {
+			key := source + "/" + sourceID
+			if first, ok := seenIDs[key]; ok {
+				add("source-id", "duplicates addon-summary-list[%d]", first)
+			} else {
+				seenIDs[key] = i
+			}
+		}
+
+		if urlStr, ok := addon["url"].(string); ok && urlStr != "" {
+			if first, ok := seenURLs[urlStr]; ok {
+				add("url", "duplicates the url of addon-summary-list[%d]", first)
+			} else {
+				seenURLs[urlStr] = i
+			}
 		}
 	}
+}
 
-	return nil
+func (vs violations) err() error {
+	if len(vs) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: vs}
 }
 
-func validateAddon(addon map[string]any, index int) error {
-	prefix := fmt.Sprintf("addon-summary-list[%d]", index)
+func validateAddon(addon map[string]any, index int, specVersion int, found *violations) {
+	sourceID, hasSourceID := addon["source-id"].(string)
+	add := func(field, format string, args ...any) {
+		*found = append(*found, Violation{Index: index, SourceID: sourceID, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
 
 	// Required fields
-	source, ok := addon["source"].(string)
-	if !ok {
-		return fmt.Errorf("validation failed: %s.source is required and must be a string", prefix)
+	if source, ok := addon["source"].(string); !ok {
+		add("source", "is required and must be a string")
+	} else if !isValidSource(source) {
+		add("source", "must be one of: wowinterface, github")
 	}
 
-	if !isValidSource(source) {
-		return fmt.Errorf("validation failed: %s.source must be one of: wowinterface, github", prefix)
+	if !hasSourceID {
+		add("source-id", "is required and must be a string")
+	} else if len(sourceID) == 0 {
+		add("source-id", "must be a non-empty string")
 	}
 
-	sourceID, ok := addon["source-id"].(string)
-	if !ok {
-		return fmt.Errorf("validation failed: %s.source-id is required and must be a string", prefix)
+	if name, ok := addon["name"].(string); !ok {
+		add("name", "is required and must be a string")
+	} else if len(name) == 0 {
+		add("name", "must be a non-empty string")
 	}
 
-	if len(sourceID) == 0 {
-		return fmt.Errorf("validation failed: %s.source-id must be a non-empty string", prefix)
+	if label, ok := addon["label"].(string); !ok {
+		add("label", "is required and must be a string")
+	} else if len(label) == 0 {
+		add("label", "must be a non-empty string")
 	}
 
-	name, ok := addon["name"].(string)
-	if !ok {
-		return fmt.Errorf("validation failed: %s.name is required and must be a string", prefix)
+	if updatedDate, ok := addon["updated-date"].(string); !ok {
+		add("updated-date", "is required and must be a string")
+	} else if !isValidDateString(updatedDate) {
+		add("updated-date", "must be a valid RFC3339 or YYYY-MM-DD timestamp")
 	}
 
-	if len(name) == 0 {
-		return fmt.Errorf("validation failed: %s.name must be a non-empty string", prefix)
+	if urlStr, ok := addon["url"].(string); !ok {
+		add("url", "is required and must be a string")
+	} else if !isValidURL(urlStr) {
+		add("url", "must be a valid URL")
 	}
 
-	label, ok := addon["label"].(string)
-	if !ok {
-		return fmt.Errorf("validation failed: %s.label is required and must be a string", prefix)
+	if gameTrackList, ok := addon["game-track-list"]; !ok {
+		add("game-track-list", "is required")
+	} else if gameTrackList != nil {
+		// game-track-list must be present but can be null or [] (both mean unclassified)
+		if gameTrackArr, ok := gameTrackList.([]any); !ok {
+			add("game-track-list", "must be an array")
+		} else {
+			for j, track := range gameTrackArr {
+				trackStr, ok := track.(string)
+				if !ok {
+					add(fmt.Sprintf("game-track-list[%d]", j), "must be a string")
+				} else if !isValidGameTrack(trackStr) {
+					add(fmt.Sprintf("game-track-list[%d]", j), "must be a valid game track")
+				}
+			}
+		}
 	}
 
-	if len(label) == 0 {
-		return fmt.Errorf("validation failed: %s.label must be a non-empty string", prefix)
+	// Optional fields
+	if createdDate, ok := addon["created-date"].(string); ok {
+		if !isValidDateString(createdDate) {
+			add("created-date", "must be a valid RFC3339 or YYYY-MM-DD timestamp")
+		}
 	}
 
-	updatedDate, ok := addon["updated-date"].(string)
-	if !ok {
-		return fmt.Errorf("validation failed: %s.updated-date is required and must be a string", prefix)
+	if imageURL, ok := addon["image-url"]; ok && !isValidURL(imageURL) {
+		add("image-url", "must be a valid URL")
 	}
 
-	if !isValidDateString(updatedDate) {
-		return fmt.Errorf("validation failed: %s.updated-date must be a valid RFC3339 or YYYY-MM-DD timestamp", prefix)
+	if archived, ok := addon["archived"]; ok {
+		if _, ok := archived.(bool); !ok {
+			add("archived", "must be a boolean")
+		}
 	}
 
-	urlStr, ok := addon["url"].(string)
-	if !ok {
-		return fmt.Errorf("validation failed: %s.url is required and must be a string", prefix)
+	if sameAs, ok := addon["same-as"]; ok {
+		if refs, ok := sameAs.([]any); !ok {
+			add("same-as", "must be an array")
+		} else {
+			for j, refRaw := range refs {
+				ref, ok := refRaw.(map[string]any)
+				if !ok {
+					add(fmt.Sprintf("same-as[%d]", j), "must be an object")
+					continue
+				}
+				if !isValidSource(ref["source"]) {
+					add(fmt.Sprintf("same-as[%d].source", j), "must be one of: wowinterface, github")
+				}
+				if refID, ok := ref["source-id"].(string); !ok || refID == "" {
+					add(fmt.Sprintf("same-as[%d].source-id", j), "must be a non-empty string")
+				}
+			}
+		}
 	}
 
-	if !isValidURL(urlStr) {
-		return fmt.Errorf("validation failed: %s.url must be a valid URL", prefix)
+	if dependencyList, ok := addon["dependency-list"]; ok {
+		dependencies, ok := dependencyList.([]any)
+		if !ok {
+			add("dependency-list", "must be an array")
+		}
+		for j, dependencyRaw := range dependencies {
+			field := fmt.Sprintf("dependency-list[%d]", j)
+			dependency, ok := dependencyRaw.(map[string]any)
+			if !ok {
+				add(field, "must be an object")
+				continue
+			}
+			if label, ok := dependency["label"].(string); !ok || label == "" {
+				add(field+".label", "must be a non-empty string")
+			}
+			if !isValidURL(dependency["url"]) {
+				add(field+".url", "is required and must be a valid URL")
+			}
+			if required, ok := dependency["required"]; ok {
+				if _, ok := required.(bool); !ok {
+					add(field+".required", "must be a boolean")
+				}
+			}
+		}
 	}
 
-	gameTrackList, ok := addon["game-track-list"]
-	if !ok {
-		return fmt.Errorf("validation failed: %s.game-track-list is required", prefix)
+	for _, field := range []string{"download-count", "favorite-count", "monthly-download-count"} {
+		if value, ok := addon[field]; ok {
+			count, ok := getInt(value)
+			if !ok || count < 0 {
+				add(field, "must be a non-negative integer")
+			}
+		}
 	}
 
-	// game-track-list must be present but can be null or [] (both mean unclassified)
-	if gameTrackList == nil {
-		// null is acceptable - treat as empty list
-		gameTrackList = []any{}
+	if value, ok := addon["popularity"]; ok {
+		if popularity, ok := value.(float64); !ok || popularity < 0 || popularity > 1 {
+			add("popularity", "must be a number from 0 to 1")
+		}
 	}
 
-	gameTrackArr, ok := gameTrackList.([]any)
-	if !ok {
-		return fmt.Errorf("validation failed: %s.game-track-list must be an array", prefix)
-	}
+	validateSpecV3Fields(addon, specVersion, add)
+}
 
-	// Empty game-track-list is allowed - indicates addons that need classification
-	for j, track := range gameTrackArr {
-		trackStr, ok := track.(string)
-		if !ok {
-			return fmt.Errorf("validation failed: %s.game-track-list[%d] must be a string", prefix, j)
+// validateSpecV3Fields validates the fields added in spec version 3, which earlier versions must not have.
+// An unknown spec version (0) skips them, the version itself has already been reported.
+func validateSpecV3Fields(addon map[string]any, specVersion int, add func(field, format string, args ...any)) {
+	if specVersion == 0 {
+		return
+	}
+	for _, field := range []string{"author", "folder-list", "release-list"} {
+		if _, ok := addon[field]; ok && specVersion < types.SpecVersion3 {
+			add(field, "requires spec version %d", types.SpecVersion3)
 		}
+	}
+	if specVersion < types.SpecVersion3 {
+		return
+	}
 
-		if !isValidGameTrack(trackStr) {
-			return fmt.Errorf("validation failed: %s.game-track-list[%d] must be a valid game track", prefix, j)
+	if author, ok := addon["author"]; ok {
+		if _, ok := author.(string); !ok {
+			add("author", "must be a string")
 		}
 	}
 
-	// Optional fields
-	if createdDate, ok := addon["created-date"].(string); ok {
-		if !isValidDateString(createdDate) {
-			return fmt.Errorf("validation failed: %s.created-date must be a valid RFC3339 or YYYY-MM-DD timestamp", prefix)
+	if folderList, ok := addon["folder-list"]; ok {
+		folders, ok := folderList.([]any)
+		if !ok {
+			add("folder-list", "must be an array")
+		}
+		for j, folder := range folders {
+			if s, ok := folder.(string); !ok || s == "" {
+				add(fmt.Sprintf("folder-list[%d]", j), "must be a non-empty string")
+			}
 		}
 	}
 
-	if downloadCount, ok := addon["download-count"]; ok {
-		count, ok := getInt(downloadCount)
-		if !ok || count < 0 {
-			return fmt.Errorf("validation failed: %s.download-count must be a non-negative integer", prefix)
+	releaseList, ok := addon["release-list"]
+	if !ok {
+		return
+	}
+	releases, ok := releaseList.([]any)
+	if !ok {
+		add("release-list", "must be an array")
+		return
+	}
+	for j, releaseRaw := range releases {
+		field := fmt.Sprintf("release-list[%d]", j)
+		release, ok := releaseRaw.(map[string]any)
+		if !ok {
+			add(field, "must be an object")
+			continue
+		}
+		if !isValidURL(release["download-url"]) {
+			add(field+".download-url", "is required and must be a valid URL")
+		}
+		if gameTrack, ok := release["game-track"]; ok && !isValidGameTrack(gameTrack) {
+			add(field+".game-track", "must be a valid game track")
+		}
+		if version, ok := release["version"]; ok {
+			if _, ok := version.(string); !ok {
+				add(field+".version", "must be a string")
+			}
+		}
+		if interfaceList, ok := release["interface-list"]; ok {
+			versions, ok := interfaceList.([]any)
+			if !ok {
+				add(field+".interface-list", "must be an array")
+			}
+			for k, version := range versions {
+				if n, ok := getInt(version); !ok || n <= 0 {
+					add(fmt.Sprintf("%s.interface-list[%d]", field, k), "must be a positive integer")
+				}
+			}
+		}
+		if size, ok := release["size"]; ok {
+			if n, ok := getInt(size); !ok || n < 0 {
+				add(field+".size", "must be a non-negative integer")
+			}
+		}
+		if checksum, ok := release["checksum"]; ok && !isHexString(checksum) {
+			add(field+".checksum", "must be a hex digest")
 		}
 	}
+}
 
-	return nil
+// isHexString checks if a value is a non-empty string of hex digits
+func isHexString(val any) bool {
+	str, ok := val.(string)
+	if !ok || str == "" {
+		return false
+	}
+	_, err := hex.DecodeString(str)
+	return err == nil
 }
 
 func getInt(val any) (int, bool) {
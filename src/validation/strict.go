@@ -0,0 +1,104 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gosimple/slug"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/tags"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// sourceHosts are the hosts an addon's URL may point to, by source
+var sourceHosts = map[string][]string{
+	string(types.WowInterfaceSource): {"www.wowinterface.com", "wowinterface.com"},
+	string(types.GitHubSource):       {"github.com", "www.github.com"},
+	string(types.GitLabSource):       {"gitlab.com"},
+	string(types.CodebergSource):     {"codeberg.org"},
+	string(types.WagoSource):         {"addons.wago.io"},
+	string(types.TownlongYakSource):  {"www.townlong-yak.com"},
+}
+
+// maxClockSkew is how far in the future an updated-date may be before it's considered wrong
+const maxClockSkew = 24 * time.Hour
+
+// StrictValidateCatalogue validates a catalogue like SimpleValidateCatalogue and additionally rejects
+// things a catalogue can technically contain but shouldn't: names within a source that slugify to the same value,
+// descriptions with replacement characters, unknown tags, URLs that don't match the addon's source and
+// updated-dates in the future (relative to now).
+func StrictValidateCatalogue(data map[string]any, now time.Time) error {
+	var found violations
+
+	var validationErr *ValidationError
+	if err := SimpleValidateCatalogue(data); err != nil {
+		if !errors.As(err, &validationErr) {
+			return err
+		}
+		found = validationErr.Violations
+	}
+
+	addonList, _ := data["addon-summary-list"].([]any)
+	seenNames := make(map[string]int)
+
+	for i, addonRaw := range addonList {
+		addon, ok := addonRaw.(map[string]any)
+		if !ok {
+			continue
+		}
+		source, _ := addon["source"].(string)
+		sourceID, _ := addon["source-id"].(string)
+		add := func(field, format string, args ...any) {
+			found = append(found, Violation{Index: i, SourceID: sourceID, Field: field, Message: fmt.Sprintf(format, args...)})
+		}
+
+		// WoWInterface has many addons sharing a name, so this is only enforced in strict mode
+		if name, ok := addon["name"].(string); ok && name != "" {
+			key := source + "/" + slug.Make(name)
+			if first, ok := seenNames[key]; ok {
+				add("name", "%q collides with the name of addon-summary-list[%d]", name, first)
+			} else {
+				seenNames[key] = i
+			}
+		}
+
+		if description, ok := addon["description"].(string); ok && strings.ContainsRune(description, '�') {
+			add("description", "contains a replacement character (U+FFFD)")
+		}
+
+		if tagList, ok := addon["tag-list"].([]any); ok {
+			for j, tag := range tagList {
+				if tagStr, ok := tag.(string); ok && !tags.IsKnown(tagStr) {
+					add(fmt.Sprintf("tag-list[%d]", j), "%q is not a known tag", tagStr)
+				}
+			}
+		}
+
+		if hosts, ok := sourceHosts[source]; ok {
+			if urlStr, ok := addon["url"].(string); ok {
+				if parsed, err := url.Parse(urlStr); err == nil && !slices.Contains(hosts, strings.ToLower(parsed.Host)) {
+					add("url", "must be a %s URL", source)
+				}
+			}
+		}
+
+		if updatedDate, ok := addon["updated-date"].(string); ok {
+			if updated, err := parseDateString(updatedDate); err == nil && updated.After(now.Add(maxClockSkew)) {
+				add("updated-date", "is in the future")
+			}
+		}
+	}
+
+	return found.err()
+}
+
+// parseDateString parses an RFC3339 timestamp or YYYY-MM-DD date
+func parseDateString(str string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, str); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", str)
+}
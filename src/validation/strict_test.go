@@ -0,0 +1,96 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func strictTestAddon(sourceID, name string) map[string]any {
+	return map[string]any{
+		"source":          "wowinterface",
+		"source-id":       sourceID,
+		"name":            name,
+		"label":           "Addon",
+		"updated-date":    "2024-01-01T00:00:00Z",
+		"url":             "https://www.wowinterface.com/downloads/info" + sourceID,
+		"game-track-list": []any{"retail"},
+		"tag-list":        []any{"bags"},
+	}
+}
+
+func TestStrictValidateCatalogue(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	collision := strictTestAddon("6", "One!")
+	badText := strictTestAddon("3", "three")
+	badText["description"] = "Caf� menu"
+	badText["tag-list"] = []any{"bags", "not-a-tag"}
+	badURL := strictTestAddon("4", "four")
+	badURL["url"] = "https://github.com/owner/four"
+	future := strictTestAddon("5", "five")
+	future["updated-date"] = "2025-06-01T00:00:00Z"
+	githubSameName := strictTestAddon("owner/one", "one")
+	githubSameName["source"] = "github"
+	githubSameName["url"] = "https://github.com/owner/one"
+	githubSameName["tag-list"] = []any{}
+	gitlab := strictTestAddon("group/sub/one", "one")
+	gitlab["source"] = "gitlab"
+	gitlab["url"] = "https://gitlab.com/group/sub/one"
+	gitlab["tag-list"] = []any{}
+	codeberg := strictTestAddon("owner/one", "one")
+	codeberg["source"] = "codeberg"
+	codeberg["url"] = "https://codeberg.org/owner/one"
+	codeberg["tag-list"] = []any{}
+	wago := strictTestAddon("aN5Kx1Ge", "one")
+	wago["source"] = "wago"
+	wago["url"] = "https://addons.wago.io/addons/one"
+	wago["tag-list"] = []any{}
+	townlongYak := strictTestAddon("opie", "one")
+	townlongYak["source"] = "townlong-yak"
+	townlongYak["url"] = "https://www.townlong-yak.com/addons/opie"
+	townlongYak["tag-list"] = []any{}
+
+	addons := []any{
+		strictTestAddon("1", "one"),
+		collision,
+		strictTestAddon("2", "one"),
+		badText,
+		badURL,
+		future,
+		githubSameName, // same name in another source is fine
+		gitlab,
+		codeberg,
+		wago,
+		townlongYak,
+	}
+	catalogue := map[string]any{
+		"spec":               map[string]any{"version": 2},
+		"datestamp":          "2025-01-01",
+		"total":              len(addons),
+		"addon-summary-list": addons,
+	}
+
+	if err := SimpleValidateCatalogue(catalogue); err != nil {
+		t.Fatalf("SimpleValidateCatalogue() unexpected error: %v", err)
+	}
+
+	err := StrictValidateCatalogue(catalogue, now)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("StrictValidateCatalogue() error = %v, want a *ValidationError", err)
+	}
+
+	want := []Violation{
+		{Index: 1, SourceID: "6", Field: "name", Message: `"One!" collides with the name of addon-summary-list[0]`},
+		{Index: 2, SourceID: "2", Field: "name", Message: `"one" collides with the name of addon-summary-list[0]`},
+		{Index: 3, SourceID: "3", Field: "description", Message: "contains a replacement character (U+FFFD)"},
+		{Index: 3, SourceID: "3", Field: "tag-list[1]", Message: `"not-a-tag" is not a known tag`},
+		{Index: 4, SourceID: "4", Field: "url", Message: "must be a wowinterface URL"},
+		{Index: 5, SourceID: "5", Field: "updated-date", Message: "is in the future"},
+	}
+	if !reflect.DeepEqual(validationErr.Violations, want) {
+		t.Errorf("Violations =\n%+v\nwant\n%+v", validationErr.Violations, want)
+	}
+}
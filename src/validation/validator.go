@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 )
 
 // ValidateCatalogueFile validates a catalogue JSON file
@@ -16,6 +17,21 @@ func ValidateCatalogueFile(filePath string) error {
 	return ValidateCatalogueJSON(data)
 }
 
+// StrictValidateCatalogueFile validates a catalogue JSON file with StrictValidateCatalogue
+func StrictValidateCatalogueFile(filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var catalogueData map[string]any
+	if err := json.Unmarshal(data, &catalogueData); err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	return StrictValidateCatalogue(catalogueData, time.Now())
+}
+
 // ValidateCatalogueJSON validates catalogue JSON data
 func ValidateCatalogueJSON(data []byte) error {
 	var catalogueData map[string]any
@@ -2,8 +2,10 @@ package validation
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -33,6 +35,8 @@ func TestValidateCatalogueFile(t *testing.T) {
       "created-date": "2012-10-04T10:42:00Z",
       "download-count": 1559,
       "game-track-list": ["retail"],
+      "image-url": "https://cdn-wow.mmoui.com/preview/pvw71819.png",
+      "popularity": 0.75,
       "tag-list": ["patches", "plug-ins"],
       "url": "https://www.wowinterface.com/downloads/info21718"
     }
@@ -202,6 +206,103 @@ func TestValidateCatalogueFile(t *testing.T) {
 			wantErr:     true,
 			errContains: "download-count",
 		},
+		{
+			name: "invalid - dependency without a url",
+			catalogueJSON: `{
+  "spec": {
+    "version": 2
+  },
+  "datestamp": "2025-10-04",
+  "total": 1,
+  "addon-summary-list": [
+    {
+      "source": "wowinterface",
+      "source-id": "123",
+      "name": "test",
+      "label": "Test",
+      "updated-date": "2012-10-04T16:42:34Z",
+      "dependency-list": [{"label": "LibStub", "required": true}],
+      "game-track-list": ["retail"],
+      "url": "https://example.com"
+    }
+  ]
+}`,
+			wantErr:     true,
+			errContains: "dependency-list[0].url",
+		},
+		{
+			name: "invalid - empty image-url",
+			catalogueJSON: `{
+  "spec": {
+    "version": 2
+  },
+  "datestamp": "2025-10-04",
+  "total": 1,
+  "addon-summary-list": [
+    {
+      "source": "wowinterface",
+      "source-id": "123",
+      "name": "test",
+      "label": "Test",
+      "updated-date": "2012-10-04T16:42:34Z",
+      "game-track-list": ["retail"],
+      "image-url": "",
+      "url": "https://example.com"
+    }
+  ]
+}`,
+			wantErr:     true,
+			errContains: "image-url",
+		},
+		{
+			name: "invalid - popularity out of range",
+			catalogueJSON: `{
+  "spec": {
+    "version": 2
+  },
+  "datestamp": "2025-10-04",
+  "total": 1,
+  "addon-summary-list": [
+    {
+      "source": "wowinterface",
+      "source-id": "123",
+      "name": "test",
+      "label": "Test",
+      "updated-date": "2012-10-04T16:42:34Z",
+      "game-track-list": ["retail"],
+      "download-count": 10,
+      "popularity": 1.5,
+      "url": "https://example.com"
+    }
+  ]
+}`,
+			wantErr:     true,
+			errContains: "popularity",
+		},
+		{
+			name: "invalid - same-as missing source-id",
+			catalogueJSON: `{
+  "spec": {
+    "version": 2
+  },
+  "datestamp": "2025-10-04",
+  "total": 1,
+  "addon-summary-list": [
+    {
+      "source": "github",
+      "source-id": "owner/repo",
+      "name": "test-addon",
+      "label": "Test Addon",
+      "updated-date": "2024-08-01T19:55:21Z",
+      "game-track-list": ["retail"],
+      "same-as": [{"source": "wowinterface"}],
+      "url": "https://github.com/owner/repo"
+    }
+  ]
+}`,
+			wantErr:     true,
+			errContains: "same-as",
+		},
 		{
 			name: "invalid - missing spec version",
 			catalogueJSON: `{
@@ -315,3 +416,176 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestValidateCatalogue_CollectsAllViolations(t *testing.T) {
+	catalogueJSON := `{
+  "spec": {"version": 2},
+  "datestamp": "2025-10-04",
+  "total": 3,
+  "addon-summary-list": [
+    {
+      "source": "wowinterface",
+      "source-id": "1",
+      "name": "",
+      "label": "One",
+      "updated-date": "2024-01-01T00:00:00Z",
+      "game-track-list": ["retail", "vanilla"],
+      "url": "https://www.wowinterface.com/downloads/info1"
+    },
+    {
+      "source": "wowinterface",
+      "source-id": "2",
+      "name": "two",
+      "label": "Two",
+      "updated-date": "2024-01-01T00:00:00Z",
+      "game-track-list": ["retail"],
+      "url": "https://www.wowinterface.com/downloads/info2"
+    },
+    {
+      "source": "curseforge",
+      "name": "three",
+      "label": "Three",
+      "updated-date": "2024-01-01T00:00:00Z",
+      "game-track-list": [],
+      "url": "https://example.org/three"
+    }
+  ]
+}`
+
+	err := ValidateCatalogueJSON([]byte(catalogueJSON))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("ValidateCatalogueJSON() error = %v, want a *ValidationError", err)
+	}
+
+	want := []Violation{
+		{Index: 0, SourceID: "1", Field: "name", Message: "must be a non-empty string"},
+		{Index: 0, SourceID: "1", Field: "game-track-list[1]", Message: "must be a valid game track"},
+		{Index: 2, Field: "source", Message: "must be one of: wowinterface, github"},
+		{Index: 2, Field: "source-id", Message: "is required and must be a string"},
+	}
+	if !reflect.DeepEqual(validationErr.Violations, want) {
+		t.Errorf("Violations = %+v, want %+v", validationErr.Violations, want)
+	}
+
+	if got := want[0].Error(); got != `addon-summary-list[0].name must be a non-empty string (source-id "1")` {
+		t.Errorf("Violation.Error() = %s", got)
+	}
+	if !contains(err.Error(), "4 problems") {
+		t.Errorf("Expected error to count problems, got: %v", err)
+	}
+}
+
+func TestValidateCatalogue_Duplicates(t *testing.T) {
+	addon := func(source, sourceID, url string) map[string]any {
+		return map[string]any{
+			"source":          source,
+			"source-id":       sourceID,
+			"name":            "addon",
+			"label":           "Addon",
+			"updated-date":    "2024-01-01T00:00:00Z",
+			"url":             url,
+			"game-track-list": []any{"retail"},
+		}
+	}
+	addons := []any{
+		addon("wowinterface", "1", "https://www.wowinterface.com/downloads/info1"),
+		addon("wowinterface", "1", "https://www.wowinterface.com/downloads/info1-copy"),
+		addon("wowinterface", "2", "https://www.wowinterface.com/downloads/info1"),
+		addon("github", "1", "https://github.com/owner/one"), // same source-id in another source is fine
+	}
+	catalogue := map[string]any{
+		"spec":               map[string]any{"version": 2},
+		"datestamp":          "2024-01-01",
+		"total":              len(addons),
+		"addon-summary-list": addons,
+	}
+
+	var validationErr *ValidationError
+	if err := SimpleValidateCatalogue(catalogue); !errors.As(err, &validationErr) {
+		t.Fatalf("SimpleValidateCatalogue() error = %v, want a *ValidationError", err)
+	}
+
+	want := []Violation{
+		{Index: 1, SourceID: "1", Field: "source-id", Message: "duplicates addon-summary-list[0]"},
+		{Index: 2, SourceID: "2", Field: "url", Message: "duplicates the url of addon-summary-list[0]"},
+	}
+	if !reflect.DeepEqual(validationErr.Violations, want) {
+		t.Errorf("Violations = %+v, want %+v", validationErr.Violations, want)
+	}
+}
+
+func TestValidateCatalogue_SpecVersion3Fields(t *testing.T) {
+	addon := func(release map[string]any) map[string]any {
+		return map[string]any{
+			"source":          "wowinterface",
+			"source-id":       "1",
+			"name":            "addon",
+			"label":           "Addon",
+			"author":          "Someone",
+			"updated-date":    "2024-01-01T00:00:00Z",
+			"url":             "https://www.wowinterface.com/downloads/info1",
+			"game-track-list": []any{"retail"},
+			"folder-list":     []any{"Addon", "Addon_Options"},
+			"release-list":    []any{release},
+		}
+	}
+	withFolders := func(addon map[string]any, folders any) map[string]any {
+		addon["folder-list"] = folders
+		return addon
+	}
+	catalogue := func(version int, addon map[string]any) map[string]any {
+		return map[string]any{
+			"spec":               map[string]any{"version": version},
+			"datestamp":          "2024-01-01",
+			"total":              1,
+			"addon-summary-list": []any{addon},
+		}
+	}
+	valid := map[string]any{
+		"download-url":   "https://cdn.wowinterface.com/downloads/getfile.php?id=1",
+		"version":        "1.0",
+		"game-track":     "retail",
+		"interface-list": []any{float64(110005)},
+		"size":           float64(1024),
+		"checksum":       "77429fa58f1a4e5201e82d2d04afb4bc",
+	}
+	invalid := map[string]any{
+		"game-track":     "vanilla",
+		"interface-list": []any{float64(110005), float64(0)},
+		"size":           float64(-1),
+		"checksum":       "not-hex",
+	}
+
+	tests := []struct {
+		name      string
+		catalogue map[string]any
+		want      []string // violation fields
+	}{
+		{"v3", catalogue(3, addon(valid)), nil},
+		{"v3 fields in v2", catalogue(2, addon(valid)), []string{"author", "folder-list", "release-list"}},
+		{"invalid folders", catalogue(3, withFolders(addon(valid), []any{"Addon", ""})), []string{"folder-list[1]"}},
+		{"folders not an array", catalogue(3, withFolders(addon(valid), "Addon")), []string{"folder-list"}},
+		{"invalid release", catalogue(3, addon(invalid)), []string{
+			"release-list[0].download-url", "release-list[0].game-track", "release-list[0].interface-list[1]", "release-list[0].size", "release-list[0].checksum",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SimpleValidateCatalogue(tt.catalogue)
+			var got []string
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				for _, v := range validationErr.Violations {
+					got = append(got, v.Field)
+				}
+			} else if err != nil {
+				t.Fatalf("SimpleValidateCatalogue() error = %v, want a *ValidationError", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SimpleValidateCatalogue() violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+// Package wago scrapes addon metadata from the Wago Addons external API.
+// The API requires a key, sent as a bearer token (see APIKey and http.TokenTransport).
+package wago
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gosimple/slug"
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/normalise"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+const (
+	// APIHost serves the Wago Addons API
+	APIHost = "addons.wago.io"
+
+	// APIURL is the Wago Addons external API
+	APIURL = "https://" + APIHost + "/api/external"
+
+	// APIKeyEnvVar holds a Wago API key when one isn't given with --wago-api-key
+	APIKeyEnvVar = "WAGO_API_KEY"
+)
+
+// APIKey returns key if set, otherwise the key in the environment, if any
+func APIKey(key string) string {
+	if key != "" {
+		return key
+	}
+	return os.Getenv(APIKeyEnvVar)
+}
+
+// gameFlavors maps Wago's game flavors to game tracks
+var gameFlavors = map[string]types.GameTrack{
+	"retail":  types.RetailTrack,
+	"classic": types.ClassicTrack,
+	"bc":      types.ClassicTBCTrack,
+	"wotlk":   types.ClassicWotLKTrack,
+	"cata":    types.ClassicCataTrack,
+	"mop":     types.ClassicMistsTrack,
+}
+
+// Release is the latest release of an addon for a game flavor
+type Release struct {
+	Label     string    `json:"label"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Addon is the part of a Wago addon used to build a catalogue addon
+type Addon struct {
+	ID            string             `json:"id"`
+	Slug          string             `json:"slug"`
+	DisplayName   string             `json:"display_name"`
+	Summary       string             `json:"summary"`
+	WebsiteURL    string             `json:"website_url"`
+	CreatedAt     *time.Time         `json:"created_at"`
+	DownloadCount *int               `json:"download_count"`
+	RecentRelease map[string]Release `json:"recent_release"` // game flavor -> latest release
+}
+
+// page is a page of addons
+type page struct {
+	Data []Addon `json:"data"`
+	Meta struct {
+		CurrentPage int `json:"current_page"`
+		LastPage    int `json:"last_page"`
+	} `json:"meta"`
+}
+
+type Parser struct{}
+
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// addonsURL returns a page of addons
+func addonsURL(page int) string {
+	return fmt.Sprintf("%s/addons?page=%d", APIURL, page)
+}
+
+// ParsePage parses a page of addons, returning them and the number of the last page
+func ParsePage(data []byte) ([]Addon, int, error) {
+	var parsed page
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse Wago addons: %w", err)
+	}
+	return parsed.Data, parsed.Meta.LastPage, nil
+}
+
+// ToAddon maps a Wago addon to an addon, returning false if it has no releases for a known game flavor
+func ToAddon(addon Addon) (types.Addon, bool) {
+	var gameTracks []types.GameTrack
+	var updatedDate time.Time
+	for flavor, release := range addon.RecentRelease {
+		track, ok := gameFlavors[strings.ToLower(flavor)]
+		if !ok {
+			continue
+		}
+		gameTracks = append(gameTracks, track)
+		if release.CreatedAt.After(updatedDate) {
+			updatedDate = release.CreatedAt
+		}
+	}
+	if len(gameTracks) == 0 {
+		return types.Addon{}, false
+	}
+	sort.Slice(gameTracks, func(i, j int) bool {
+		return gameTracks[i] < gameTracks[j]
+	})
+
+	label := normalise.Text(addon.DisplayName)
+	name := addon.Slug
+	if name == "" {
+		name = strings.ReplaceAll(slug.Make(label), "_", "-")
+	}
+
+	return types.Addon{
+		CreatedDate:   addon.CreatedAt,
+		Description:   normalise.Text(addon.Summary),
+		DownloadCount: addon.DownloadCount,
+		GameTrackList: gameTracks,
+		Label:         label,
+		Name:          name,
+		Source:        types.WagoSource,
+		SourceID:      addon.ID,
+		TagList:       []string{},
+		URL:           addon.WebsiteURL,
+		UpdatedDate:   updatedDate,
+	}, true
+}
+
+// BuildCatalogue fetches every page of addons and returns those with releases as addons.
+// Requests go through client, and so its cache, and must be authenticated with an API key.
+func (p *Parser) BuildCatalogue(ctx context.Context, client httpclient.HTTPClient) ([]types.Addon, error) {
+	var addons []types.Addon
+	for pageNum, lastPage := 1, 1; pageNum <= lastPage; pageNum++ {
+		pageURL := addonsURL(pageNum)
+		resp, err := client.Get(ctx, pageURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch Wago addons: %w", err)
+		}
+		if resp.StatusCode == 401 || resp.StatusCode == 403 {
+			return nil, fmt.Errorf("failed to fetch Wago addons: status %d, check the API key", resp.StatusCode)
+		}
+		if resp.StatusCode != 200 {
+			return nil, fmt.Errorf("failed to fetch Wago addons %s: status %d", pageURL, resp.StatusCode)
+		}
+
+		pageAddons, last, err := ParsePage(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		lastPage = last
+
+		for _, wagoAddon := range pageAddons {
+			if addon, ok := ToAddon(wagoAddon); ok {
+				addons = append(addons, addon)
+			}
+		}
+	}
+	return addons, nil
+}
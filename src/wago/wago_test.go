@@ -0,0 +1,123 @@
+package wago
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	httpclient "github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// addonsPage returns page pageNum of lastPage, of an addon released for each of flavors
+func addonsPage(pageNum, lastPage int, flavors ...string) *httpclient.Response {
+	addon := Addon{ID: fmt.Sprintf("addon-%d", pageNum), DisplayName: "Addon", RecentRelease: map[string]Release{}}
+	for _, flavor := range flavors {
+		addon.RecentRelease[flavor] = Release{Label: "v1"}
+	}
+	var p page
+	p.Data = []Addon{addon}
+	p.Meta.CurrentPage, p.Meta.LastPage = pageNum, lastPage
+	body, _ := json.Marshal(p)
+	return &httpclient.Response{StatusCode: 200, Body: body}
+}
+
+func TestBuildCatalogue(t *testing.T) {
+	client := httpclient.NewMockHTTPClient()
+	for pageNum := 1; pageNum <= 2; pageNum++ {
+		body, err := os.ReadFile(fmt.Sprintf("test/fixtures/addons--page-%d.json", pageNum))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.SetResponse(addonsURL(pageNum), &httpclient.Response{StatusCode: 200, Body: body})
+	}
+
+	addons, err := NewParser().BuildCatalogue(context.Background(), client)
+	if err != nil {
+		t.Fatalf("BuildCatalogue() unexpected error: %v", err)
+	}
+
+	// Addons without releases are skipped
+	if len(addons) != 1 {
+		t.Fatalf("BuildCatalogue() = %d addons, want 1", len(addons))
+	}
+
+	createdDate := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	downloadCount := 1234
+	want := types.Addon{
+		CreatedDate:   &createdDate,
+		Description:   "Sorts your bags & bank",
+		DownloadCount: &downloadCount,
+		GameTrackList: []types.GameTrack{types.ClassicCataTrack, types.ClassicWotLKTrack, types.RetailTrack},
+		Label:         "Bag Sorter",
+		Name:          "bag-sorter",
+		Source:        types.WagoSource,
+		SourceID:      "aN5Kx1Ge",
+		TagList:       []string{},
+		URL:           "https://addons.wago.io/addons/bag-sorter",
+		UpdatedDate:   time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(addons[0], want) {
+		t.Errorf("BuildCatalogue() = %+v, want %+v", addons[0], want)
+	}
+}
+
+func TestBuildCatalogue_LastPage(t *testing.T) {
+	client := httpclient.NewMockHTTPClient()
+	client.SetResponse(addonsURL(1), addonsPage(1, 3, "retail"))
+	client.SetResponse(addonsURL(2), addonsPage(2, 3, "classic"))
+	client.SetResponse(addonsURL(3), addonsPage(3, 3, "retail", "cata"))
+
+	addons, err := NewParser().BuildCatalogue(context.Background(), client)
+	if err != nil {
+		t.Fatalf("BuildCatalogue() unexpected error: %v", err)
+	}
+	if len(addons) != 3 {
+		t.Errorf("BuildCatalogue() = %d addons, want 3", len(addons))
+	}
+	// Nothing is fetched after the page the API says is the last
+	want := []string{addonsURL(1), addonsURL(2), addonsURL(3)}
+	if calls := client.GetCalls(); !reflect.DeepEqual(calls, want) {
+		t.Errorf("BuildCatalogue() fetched %v, want %v", calls, want)
+	}
+}
+
+func TestBuildCatalogue_Errors(t *testing.T) {
+	for _, statusCode := range []int{401, 403, 429, 500} {
+		client := httpclient.NewMockHTTPClient()
+		client.SetResponse(addonsURL(1), addonsPage(1, 2, "retail"))
+		client.SetResponse(addonsURL(2), &httpclient.Response{StatusCode: statusCode})
+
+		// A failed page fails the scrape rather than building a catalogue missing its addons
+		if addons, err := NewParser().BuildCatalogue(context.Background(), client); err == nil {
+			t.Errorf("BuildCatalogue() with a %d page = %d addons, want an error", statusCode, len(addons))
+		}
+	}
+}
+
+func TestToAddon_NoRelease(t *testing.T) {
+	cases := map[string]map[string]Release{
+		"no releases":          nil,
+		"empty releases":       {},
+		"unknown game flavors": {"plunderstorm": {Label: "v1"}, "vanilla-ptr": {Label: "v2"}},
+	}
+	for name, releases := range cases {
+		if addon, ok := ToAddon(Addon{ID: "x", DisplayName: "X", RecentRelease: releases}); ok {
+			t.Errorf("ToAddon() with %s = %+v, want it skipped", name, addon)
+		}
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	t.Setenv(APIKeyEnvVar, "from-env")
+	if got := APIKey("from-flag"); got != "from-flag" {
+		t.Errorf("APIKey(from-flag) = %q, want from-flag", got)
+	}
+	if got := APIKey(""); got != "from-env" {
+		t.Errorf("APIKey(\"\") = %q, want from-env", got)
+	}
+}
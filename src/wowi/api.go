@@ -0,0 +1,415 @@
+package wowi
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/description"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// parseAPIFileList parses the WowInterface API file list
+func (p *Parser) parseAPIFileList(content []byte) (*types.ParseResult, error) {
+	var apiData []map[string]interface{}
+	if err := json.Unmarshal(content, &apiData); err != nil {
+		return nil, fmt.Errorf("failed to parse API JSON: %w", err)
+	}
+
+	if len(apiData) == 0 {
+		return &types.ParseResult{}, nil
+	}
+
+	// Detect API version by checking field names in first item
+	isV3 := false
+	if _, hasUID := apiData[0]["UID"]; hasUID {
+		isV3 = true
+	}
+
+	var addonData []types.AddonData
+	var urls []string
+	updatedDates := make(map[string]time.Time)
+	apiVersion := APIVersionV4
+	if isV3 {
+		apiVersion = APIVersionV3
+	}
+
+	for _, item := range apiData {
+		var addon types.AddonData
+		if isV3 {
+			addon = parseAPIFileListItemV3(item)
+		} else {
+			addon = parseAPIFileListItemV4(item)
+		}
+
+		if addon.SourceID != "" {
+			addonData = append(addonData, addon)
+			// Add URLs for detail pages
+			detailURLs := p.AddonURLs(apiVersion, addon.SourceID)
+			urls = append(urls, detailURLs...)
+
+			// The filelist knows when each addon last changed, which lets the cache skip re-fetching stable addons
+			if addon.UpdatedDate != nil {
+				for _, detailURL := range detailURLs {
+					updatedDates[detailURL] = *addon.UpdatedDate
+				}
+			}
+		}
+	}
+
+	return &types.ParseResult{
+		AddonData:    addonData,
+		DownloadURLs: urls,
+		UpdatedDates: updatedDates,
+	}, nil
+}
+
+// FileListCategoryID returns the ID of the category an API file list item put an addon in, empty if it didn't say
+func FileListCategoryID(data types.AddonData) string {
+	for _, key := range []string{"categoryId", "UICATID"} {
+		switch id := data.WoWI[key].(type) {
+		case float64:
+			return strconv.Itoa(int(id))
+		case string:
+			return id
+		}
+	}
+	return ""
+}
+
+// parseAPIFileListItemV3 parses a v3 API file list item
+// v3 fields: UID, UIName, UIAuthorName, UIDate, UICATID, UICompatibility (array of objects), UIDir (addon folders), etc.
+func parseAPIFileListItemV3(item map[string]interface{}) types.AddonData {
+	addon := types.AddonData{
+		Source:       types.WowInterfaceSource,
+		Kind:         types.APIFileListData,
+		APIVersion:   string(APIVersionV3),
+		GameTrackSet: make(map[types.GameTrack]bool),
+		WoWI:         item,
+	}
+
+	// UID -> SourceID
+	if uid, ok := item["UID"].(string); ok {
+		addon.SourceID = uid
+	}
+
+	// UIName -> Label
+	if name, ok := item["UIName"].(string); ok {
+		addon.Label = name
+		addon.Name = slugify(name)
+	}
+
+	// UIAuthorName -> Author
+	if author, ok := item["UIAuthorName"].(string); ok {
+		addon.Author = author
+	}
+
+	// UIDate -> UpdatedDate
+	if date, ok := item["UIDate"].(float64); ok {
+		updateTime := time.Unix(int64(date)/1000, 0).UTC()
+		addon.UpdatedDate = &updateTime
+	}
+
+	// UICompatibility -> GameTrackSet (v3 has array of {version, name} objects)
+	if compat, ok := item["UICompatibility"].([]interface{}); ok {
+		for _, c := range compat {
+			if compatObj, ok := c.(map[string]interface{}); ok {
+				if version, ok := compatObj["version"].(string); ok {
+					if track := gameVersionToGameTrack(version); track != "" {
+						addon.GameTrackSet[track] = true
+					}
+					addInterfaceVersion(&addon, version)
+				}
+			}
+		}
+	}
+
+	addon.FolderList = folderList(item)
+
+	return addon
+}
+
+// folderList returns the addon folders in a v3 API item's UIDir, e.g. ["AdiBags", "AdiBags_Config"]
+func folderList(item map[string]interface{}) []string {
+	dirs, ok := item["UIDir"].([]interface{})
+	if !ok {
+		return nil
+	}
+	var folders []string
+	for _, dir := range dirs {
+		if folder, ok := dir.(string); ok && folder != "" {
+			folders = append(folders, folder)
+		}
+	}
+	return folders
+}
+
+// parseAPIFileListItemV4 parses a v4 API file list item
+// v4 fields: id, title, author, lastUpdate, categoryId, gameVersions (array of strings), checksum, etc.
+func parseAPIFileListItemV4(item map[string]interface{}) types.AddonData {
+	addon := types.AddonData{
+		Source:       types.WowInterfaceSource,
+		Kind:         types.APIFileListData,
+		APIVersion:   string(APIVersionV4),
+		GameTrackSet: make(map[types.GameTrack]bool),
+		WoWI:         item,
+	}
+
+	// id -> SourceID
+	if id, ok := item["id"].(float64); ok {
+		addon.SourceID = strconv.Itoa(int(id))
+	}
+
+	// title -> Label
+	if title, ok := item["title"].(string); ok {
+		addon.Label = title
+		addon.Name = slugify(title)
+	}
+
+	// author -> Author
+	if author, ok := item["author"].(string); ok {
+		addon.Author = author
+	}
+
+	// lastUpdate -> UpdatedDate
+	if lastUpdate, ok := item["lastUpdate"].(float64); ok {
+		updateTime := time.Unix(int64(lastUpdate)/1000, 0).UTC()
+		addon.UpdatedDate = &updateTime
+	}
+
+	// gameVersions -> GameTrackSet (v4 has simple string array)
+	if gameVersions, ok := item["gameVersions"].([]interface{}); ok {
+		for _, version := range gameVersions {
+			if versionStr, ok := version.(string); ok {
+				if track := gameVersionToGameTrack(versionStr); track != "" {
+					addon.GameTrackSet[track] = true
+				}
+				addInterfaceVersion(&addon, versionStr)
+			}
+		}
+	}
+
+	return addon
+}
+
+// parseAPIDetail parses WowInterface API addon detail (supports both v3 and v4)
+func (p *Parser) parseAPIDetail(content []byte) (*types.ParseResult, error) {
+	var apiData []map[string]interface{}
+	if err := json.Unmarshal(content, &apiData); err != nil {
+		return nil, fmt.Errorf("failed to parse API JSON: %w", err)
+	}
+
+	if len(apiData) == 0 {
+		return &types.ParseResult{}, nil
+	}
+
+	item := apiData[0] // API returns array but should only have one item
+
+	// Detect API version
+	isV3 := false
+	if _, hasUID := item["UID"]; hasUID {
+		isV3 = true
+	}
+
+	var addon types.AddonData
+	if isV3 {
+		addon = parseAPIDetailItemV3(item)
+	} else {
+		addon = parseAPIDetailItemV4(item, p.descriptionMode)
+	}
+
+	return &types.ParseResult{
+		AddonData: []types.AddonData{addon},
+	}, nil
+}
+
+// parseAPIDetailItemV3 parses a v3 API detail item
+// v3 detail fields: UID, UIName, UIMD5, UIFileName, UIDownload, UIDescription, UIChangeLog, UIDir, etc.
+func parseAPIDetailItemV3(item map[string]interface{}) types.AddonData {
+	addon := types.AddonData{
+		Source:     types.WowInterfaceSource,
+		Kind:       types.APIDetailData,
+		APIVersion: string(APIVersionV3),
+		WoWI:       item,
+	}
+
+	// UID -> SourceID
+	if uid, ok := item["UID"].(string); ok {
+		addon.SourceID = uid
+	}
+
+	// UIName -> Label
+	if name, ok := item["UIName"].(string); ok {
+		addon.Label = name
+		addon.Name = slugify(name)
+	}
+
+	// UIAuthorName -> Author
+	if author, ok := item["UIAuthorName"].(string); ok {
+		addon.Author = author
+	}
+
+	// UIDownload, UIVersion, UIMD5 -> latest release
+	if downloadURL, ok := item["UIDownload"].(string); ok && downloadURL != "" {
+		release := types.Release{DownloadURL: downloadURL}
+		release.Version, _ = item["UIVersion"].(string)
+		release.Checksum, _ = item["UIMD5"].(string)
+		addon.LatestReleaseSet = []types.Release{release}
+	}
+
+	addon.FolderList = folderList(item)
+
+	return addon
+}
+
+// parseAPIDetailItemV4 parses a v4 API detail item
+// v4 detail fields: id, title, checksum, fileName, downloadUri, description, changeLog, images, etc.
+func parseAPIDetailItemV4(item map[string]interface{}, mode description.Mode) types.AddonData {
+	addon := types.AddonData{
+		Source:       types.WowInterfaceSource,
+		Kind:         types.APIDetailData,
+		APIVersion:   string(APIVersionV4),
+		GameTrackSet: make(map[types.GameTrack]bool),
+		TagSet:       make(map[string]bool),
+		WoWI:         item,
+	}
+
+	// id -> SourceID
+	if id, ok := item["id"].(float64); ok {
+		idStr := strconv.Itoa(int(id))
+		addon.SourceID = idStr
+		addon.URL = fmt.Sprintf("https://www.wowinterface.com/downloads/info%s", idStr)
+	}
+
+	// title -> Label
+	if title, ok := item["title"].(string); ok {
+		addon.Label = title
+		addon.Name = slugify(title)
+	}
+
+	// author -> Author
+	if author, ok := item["author"].(string); ok {
+		addon.Author = author
+	}
+
+	// description
+	if desc, ok := item["description"].(string); ok {
+		addon.Description = description.Extract(desc, mode)
+	}
+
+	// images -> ImageList
+	if images, ok := item["images"].([]interface{}); ok {
+		for _, imageRaw := range images {
+			image, ok := imageRaw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			imageURL, _ := image["imageUrl"].(string)
+			if imageURL == "" {
+				continue
+			}
+			thumbURL, _ := image["thumbUrl"].(string)
+			imageDescription, _ := image["description"].(string)
+			addon.ImageList = append(addon.ImageList, types.Image{
+				URL:         absoluteURL(imageURL),
+				ThumbURL:    absoluteURL(thumbURL),
+				Description: strings.TrimSpace(imageDescription),
+			})
+		}
+	}
+
+	// changeLog (BBCode) -> Changelog
+	if changeLog, ok := item["changeLog"].(string); ok {
+		addon.Changelog = description.Changelog(description.StripBBCode(changeLog))
+	}
+
+	// downloads -> DownloadCount
+	if downloads, ok := item["downloads"].(float64); ok {
+		count := int(downloads)
+		addon.DownloadCount = &count
+	}
+
+	// favorites -> FavoriteCount, downloadsMonthly -> MonthlyDownloadCount
+	if favorites, ok := item["favorites"].(float64); ok {
+		count := int(favorites)
+		addon.FavoriteCount = &count
+	}
+	if downloadsMonthly, ok := item["downloadsMonthly"].(float64); ok {
+		count := int(downloadsMonthly)
+		addon.MonthlyDownloadCount = &count
+	}
+
+	// lastUpdate (milliseconds since epoch) -> UpdatedDate
+	if lastUpdate, ok := item["lastUpdate"].(float64); ok {
+		timestamp := time.Unix(0, int64(lastUpdate)*int64(time.Millisecond)).UTC()
+		addon.UpdatedDate = &timestamp
+	}
+
+	// downloadUri, version, checksum -> latest release.
+	// The game track isn't known from the API, the web detail page has that.
+	if downloadURI, ok := item["downloadUri"].(string); ok && downloadURI != "" {
+		release := types.Release{DownloadURL: downloadURI}
+		release.Version, _ = item["version"].(string)
+		release.Checksum, _ = item["checksum"].(string)
+		// not currently part of v4 detail responses but used if present
+		if size, ok := item["size"].(float64); ok {
+			release.Size = int64(size)
+		}
+		addon.LatestReleaseSet = []types.Release{release}
+	}
+
+	// categoryId -> tags, via the category name as for category listings
+	if categoryID, ok := item["categoryId"].(float64); ok {
+		if category, known := CategoryName(strconv.Itoa(int(categoryID))); known {
+			for _, tag := range categoryToTagsWithMaps(category) {
+				if tag != "" {
+					addon.TagSet[tag] = true
+				}
+			}
+		}
+	}
+
+	return addon
+}
+
+// interfaceVersion converts a game version to the interface version addons declare in their TOC files,
+// e.g. "11.0.5" to 110005 and "1.13.2" to 11302. Returns false for anything that isn't a game version.
+func interfaceVersion(version string) (int, bool) {
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, false
+	}
+	interfaceVersion := 0
+	for i, multiplier := range []int{10000, 100, 1} {
+		if i >= len(parts) {
+			break
+		}
+		n, err := strconv.Atoi(parts[i])
+		if err != nil || n < 0 || (i > 0 && n > 99) {
+			return 0, false
+		}
+		interfaceVersion += n * multiplier
+	}
+	if interfaceVersion == 0 {
+		return 0, false
+	}
+	return interfaceVersion, true
+}
+
+// addInterfaceVersion records the interface version of gameVersion against its game track, once
+func addInterfaceVersion(addon *types.AddonData, gameVersion string) {
+	version, ok := interfaceVersion(gameVersion)
+	if !ok {
+		return
+	}
+	track := gameVersionToGameTrack(gameVersion)
+	if addon.InterfaceVersions == nil {
+		addon.InterfaceVersions = make(map[types.GameTrack][]int)
+	}
+	if !slices.Contains(addon.InterfaceVersions[track], version) {
+		addon.InterfaceVersions[track] = append(addon.InterfaceVersions[track], version)
+	}
+}
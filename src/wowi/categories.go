@@ -0,0 +1,130 @@
+package wowi
+
+import (
+	"sort"
+	"strings"
+)
+
+// categoryNames maps WowInterface category IDs to the category names used on the website.
+// The API only gives an addon's categoryId, the names are needed to derive tags the same
+// way as for addons scraped from category listings.
+// Taken from the category links on the downloads landing page (test/fixtures/wowinterface--landing.html).
+var categoryNames = map[string]string{
+	"17":  "Graphic UI Mods",
+	"18":  "Character Advancement",
+	"19":  "Action Bar Mods",
+	"20":  "Bags, Bank, Inventory",
+	"21":  "Unit Mods",
+	"22":  "Buff, Debuff, Spell",
+	"24":  "Map, Coords, Compasses",
+	"25":  "Combat Mods",
+	"26":  "Data Mods",
+	"27":  "Miscellaneous",
+	"33":  "Plug-Ins & Patches",
+	"34":  "Beta-version AddOns",
+	"35":  "Developer Utilities",
+	"39":  "Class & Role Specific",
+	"40":  "TradeSkill Mods",
+	"44":  "Discontinued and Outdated Mods",
+	"45":  "Raid Mods",
+	"53":  "Libraries",
+	"55":  "Chat Mods",
+	"56":  "Druid",
+	"57":  "Hunter",
+	"58":  "Mage",
+	"59":  "Paladin",
+	"60":  "Priest",
+	"61":  "Rogue",
+	"62":  "Shaman",
+	"63":  "Warlock",
+	"64":  "Warrior",
+	"86":  "FuBar",
+	"88":  "WoW Tools & Utilities",
+	"94":  "Auction House & Vendors",
+	"95":  "Group, Guild & Friends",
+	"96":  "PvP, Arena, BattleGrounds",
+	"97":  "Mail",
+	"98":  "ToolTip",
+	"99":  "Titan Panel",
+	"100": "Mini Games, ROFL",
+	"102": "Generic Compilations",
+	"103": "Guild Compilations",
+	"104": "Graphical Compilations",
+	"106": "Minimalistic Compilations",
+	"107": "Class Compilations",
+	"108": "Data Broker",
+	"109": "Info, Plug-in Bars",
+	"111": "Other",
+	"112": "Casting Bars, Cooldowns",
+	"113": "Suites",
+	"114": "RolePlay, Music Mods",
+	"120": "Death Knight",
+	"125": "nUI",
+	"126": "oUF",
+	"127": "oUF: Layouts",
+	"128": "oUF: Plugins",
+	"129": "oUF: Core",
+	"130": "nUI: Core",
+	"131": "nUI: Art",
+	"132": "nUI: Layouts",
+	"133": "nUI: HUD Designs",
+	"134": "nUI: Unit Frame Panels",
+	"135": "nUI: Info Panel Plugins",
+	"136": "nUI: Enhancements",
+	"137": "nUI+ Full Version",
+	"138": "Carbonite",
+	"141": "Healer Compilations",
+	"142": "DPS Compilations",
+	"143": "Tank Compilations",
+	"146": "Mounts & Pets",
+	"147": "UI Media",
+	"149": "DPS",
+	"150": "Healers",
+	"151": "Tank",
+	"152": "Monk",
+	"154": "Utility Mods",
+	"155": "Garrisons",
+	"157": "Demon Hunter",
+	"160": "Classic - General",
+	"161": "The Burning Crusade Classic",
+}
+
+// CategoryName returns the name of a WowInterface category ID
+func CategoryName(categoryID string) (string, bool) {
+	name, ok := categoryNames[categoryID]
+	return name, ok
+}
+
+// CategoryID returns the ID of a WowInterface category by its name, ignoring case
+func CategoryID(name string) (string, bool) {
+	for id, categoryName := range categoryNames {
+		if strings.EqualFold(categoryName, strings.TrimSpace(name)) {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// KnownTags returns every tag a WowInterface category can be converted to, sorted
+func KnownTags() []string {
+	seen := make(map[string]bool)
+	for _, name := range categoryNames {
+		for _, tag := range categoryToTagsWithMaps(name) {
+			seen[tag] = true
+		}
+	}
+	for _, tagMap := range []map[string][]string{wowiReplacements, wowiSupplements} {
+		for _, tags := range tagMap {
+			for _, tag := range tags {
+				seen[tag] = true
+			}
+		}
+	}
+
+	tags := make([]string, 0, len(seen))
+	for tag := range seen {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -0,0 +1,160 @@
+package wowi
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// URLClassifier determines the type of a WowInterface URL
+type URLClassifier struct{}
+
+// NewURLClassifier creates a new URL classifier
+func NewURLClassifier() *URLClassifier {
+	return &URLClassifier{}
+}
+
+// URLType represents different types of WowInterface URLs
+type URLType int
+
+const (
+	URLTypeUnknown URLType = iota
+	URLTypeCategoryGroup
+	URLTypeCategoryListing
+	URLTypeAddonDetail
+	URLTypeAPIFileList
+	URLTypeAPIDetail
+	URLTypeAuthorPage
+	URLTypeDownloadFile // an addon's zip file or the page starting its download, never parsed
+)
+
+// urlTypeNames name each URLType in logs
+var urlTypeNames = map[URLType]string{
+	URLTypeUnknown:         "unknown",
+	URLTypeCategoryGroup:   "category-group",
+	URLTypeCategoryListing: "category-listing",
+	URLTypeAddonDetail:     "addon-detail",
+	URLTypeAPIFileList:     "api-filelist",
+	URLTypeAPIDetail:       "api-detail",
+	URLTypeAuthorPage:      "author-page",
+	URLTypeDownloadFile:    "download-file",
+}
+
+// String returns the name of the URL type, e.g. "api-detail"
+func (t URLType) String() string {
+	if name, ok := urlTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("URLType(%d)", int(t))
+}
+
+// Hosts of the routes, lower case
+var (
+	siteHosts = []string{"www.wowinterface.com", "wowinterface.com"}
+	fileHosts = []string{"www.wowinterface.com", "wowinterface.com", "cdn.wowinterface.com"}
+	apiHosts  = []string{"api.mmoui.com"}
+)
+
+// route matches the URLs of a type of page: one of its hosts, its whole path and the query parameters it requires.
+// The first group of the path, or the idParam query parameter, is the addon the page describes.
+type route struct {
+	urlType URLType
+	hosts   []string
+	path    *regexp.Regexp
+	query   []string // parameters that must be given a value
+	idParam string
+}
+
+// routes are tried in order, the first matching classifies a URL
+var routes = []route{
+	{urlType: URLTypeAPIFileList, hosts: apiHosts, path: regexp.MustCompile(`^/v[34]/game/WOW/filelist\.json$`)},
+	{urlType: URLTypeAPIDetail, hosts: apiHosts, path: regexp.MustCompile(`^/v[34]/game/WOW/filedetails/(\d+)\.json$`)},
+	{urlType: URLTypeAuthorPage, hosts: siteHosts, path: authorPageRegex},
+	{urlType: URLTypeAddonDetail, hosts: siteHosts, path: regexp.MustCompile(`^/downloads/info(\d+)(-[^/]*\.html)?$`)},
+	{urlType: URLTypeAddonDetail, hosts: siteHosts, path: regexp.MustCompile(`^/downloads/fileinfo\.php$`), query: []string{"id"}, idParam: "id"},
+	{urlType: URLTypeDownloadFile, hosts: fileHosts, path: regexp.MustCompile(`^/downloads/getfile\.php$`), query: []string{"id"}},
+	{urlType: URLTypeDownloadFile, hosts: siteHosts, path: regexp.MustCompile(`^/downloads/landing\.php$`), query: []string{"fileid"}},
+	{urlType: URLTypeCategoryListing, hosts: siteHosts, path: regexp.MustCompile(`^/downloads/index\.php$`), query: []string{"cid", "page"}},
+}
+
+// match returns the addon u describes, if the route says, and whether the route matches u at all
+func (r route) match(u *url.URL) (string, bool) {
+	if !slices.Contains(r.hosts, strings.ToLower(u.Hostname())) {
+		return "", false
+	}
+	groups := r.path.FindStringSubmatch(u.Path)
+	if groups == nil {
+		return "", false
+	}
+	query := u.Query()
+	for _, param := range r.query {
+		if query.Get(param) == "" {
+			return "", false
+		}
+	}
+	if r.idParam != "" {
+		return query.Get(r.idParam), true
+	}
+	if len(groups) > 1 {
+		return groups[1], true
+	}
+	return "", true
+}
+
+// classify returns the type of page rawURL is and the addon it describes, if any
+func classify(rawURL string) (URLType, string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return URLTypeUnknown, ""
+	}
+	for _, r := range routes {
+		if sourceID, ok := r.match(u); ok {
+			return r.urlType, sourceID
+		}
+	}
+
+	// Category group pages, no longer used for discovery
+	for _, page := range CategoryGroupPages {
+		if slices.Contains(siteHosts, strings.ToLower(u.Hostname())) && u.Path == page && u.RawQuery == "" {
+			return URLTypeCategoryGroup, ""
+		}
+	}
+	return URLTypeUnknown, ""
+}
+
+// ClassifyURL determines what type of page a URL represents
+func (c *URLClassifier) ClassifyURL(rawURL string) URLType {
+	urlType, _ := classify(rawURL)
+	return urlType
+}
+
+var sourceIDRegex = regexp.MustCompile(`id=(\d+)`)
+var sourceIDFromURLRegex = regexp.MustCompile(`info(\d+)`)
+var authorPageRegex = regexp.MustCompile(`^/downloads/author-(\d+)\.html$`)
+
+func extractSourceIDFromHref(href string) string {
+	matches := sourceIDRegex.FindStringSubmatch(href)
+	if len(matches) > 1 {
+		return matches[1]
+	}
+	return ""
+}
+
+func extractSourceIDFromURL(url string) string {
+	matches := sourceIDFromURLRegex.FindStringSubmatch(url)
+	if len(matches) > 1 {
+		return matches[1]
+	}
+	return ""
+}
+
+// SourceIDFromURL returns the addon an HTML detail page or API detail URL describes, or an empty string for any other URL
+func SourceIDFromURL(rawURL string) string {
+	switch urlType, sourceID := classify(rawURL); urlType {
+	case URLTypeAddonDetail, URLTypeAPIDetail:
+		return sourceID
+	}
+	return ""
+}
@@ -1,5 +1,7 @@
 package wowi
 
+import "fmt"
+
 const (
 	Host = "https://www.wowinterface.com"
 
@@ -40,8 +42,57 @@ func GetAPIFileList(version APIVersion) string {
 // Kept for URL classification only
 var CategoryGroupPages = []string{}
 
+// ArchivedCategoryIDs are WowInterface categories holding legacy/archived addons.
+// Addons in these categories are typically absent from the API filelist.
+var ArchivedCategoryIDs = []string{
+	"44", // Discontinued and Outdated Mods
+}
+
+// IsArchivedCategory returns true if the category ID is an archived section
+func IsArchivedCategory(categoryID string) bool {
+	for _, id := range ArchivedCategoryIDs {
+		if id == categoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// ArchivedStartingURLs returns the first listing page of each archived section
+// Pagination URLs are discovered while parsing each listing page
+func ArchivedStartingURLs() []string {
+	var urls []string
+	for _, categoryID := range ArchivedCategoryIDs {
+		urls = append(urls, categoryListingURL(categoryID))
+	}
+	return urls
+}
+
+// categoryListingURL returns the first page of a category listing, sorted by most recently updated
+func categoryListingURL(categoryID string) string {
+	return Host + "/downloads/index.php?cid=" + categoryID + "&sb=dec_date&so=desc&pt=f&page=1"
+}
+
 // StartingURLs returns the initial URL to begin scraping
 // Addons are discovered from the API filelist, then HTML detail pages are scraped for each
 func StartingURLs(apiVersion APIVersion) []string {
 	return []string{GetAPIFileList(apiVersion)}
 }
+
+// AddonURLs returns the URLs describing a single addon, its HTML detail page and its API detail
+func AddonURLs(apiVersion APIVersion, sourceID string) []string {
+	return []string{
+		fmt.Sprintf("%s/downloads/info%s", Host, sourceID),
+		APIDetailURL(apiVersion, sourceID),
+	}
+}
+
+// APIDetailURL returns the URL of the API's details of a single addon
+func APIDetailURL(apiVersion APIVersion, sourceID string) string {
+	return fmt.Sprintf("%s/filedetails/%s.json", GetAPIHost(apiVersion), sourceID)
+}
+
+// AuthorURL returns the page listing the addons of an author
+func AuthorURL(authorID string) string {
+	return fmt.Sprintf("%s/downloads/author-%s.html", Host, authorID)
+}
@@ -3,9 +3,15 @@ package wowi
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/description"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/tags"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
 )
 
@@ -53,6 +59,10 @@ func TestParseAddonDetailPage_MultipleDownloadsTabber(t *testing.T) {
 		t.Errorf("Label = %s, want 'Broker Played Time'", addon.Label)
 	}
 
+	if addon.Author != "LudiusMaximus, Phanx" {
+		t.Errorf("Author = %q, want 'LudiusMaximus, Phanx'", addon.Author)
+	}
+
 	// Verify game tracks
 	expectedTracks := map[types.GameTrack]bool{
 		types.RetailTrack:       true,
@@ -73,7 +83,10 @@ func TestParseAddonDetailPage_MultipleDownloadsTabber(t *testing.T) {
 
 	// Verify downloads
 	if len(addon.LatestReleaseSet) == 0 {
-		t.Error("Expected download releases, got none")
+		t.Fatal("Expected download releases, got none")
+	}
+	if addon.LatestReleaseSet[0].Version != "10.2.6.0" {
+		t.Errorf("Version = %q, want 10.2.6.0", addon.LatestReleaseSet[0].Version)
 	}
 
 	// Check that addon has retail game track (from Compatibility field, not download links)
@@ -204,9 +217,15 @@ func TestParseAddonDetailPage_MultipleDownloadsNoTabber(t *testing.T) {
 		t.Errorf("Label = %s, want Skillet-Classic", addon.Label)
 	}
 
-	// This should have multiple downloads
-	if len(addon.LatestReleaseSet) < 1 {
-		t.Errorf("Expected releases, got %d", len(addon.LatestReleaseSet))
+	// The main file has the page's version, the classic files are versioned by their filename
+	wantVersions := []string{"1.83", "1.47-beta1-bcc", "1.83-cata"}
+	if len(addon.LatestReleaseSet) != len(wantVersions) {
+		t.Fatalf("Expected %d releases, got %d", len(wantVersions), len(addon.LatestReleaseSet))
+	}
+	for i, release := range addon.LatestReleaseSet {
+		if release.Version != wantVersions[i] {
+			t.Errorf("LatestReleaseSet[%d].Version = %q, want %q", i, release.Version, wantVersions[i])
+		}
 	}
 
 	// Game tracks come from Compatibility field, not download count
@@ -268,19 +287,23 @@ func TestParseAddonDetailPage_RemovedByAuthorRequest(t *testing.T) {
 
 	url := "https://www.wowinterface.com/downloads/info24906-AtlasWorldMapClassic.html"
 
-	// This should be detected as a dead page and return an error or empty result
 	result, err := parser.parseAddonDetail(url, content)
+	if err != nil {
+		t.Fatalf("parseAddonDetail() unexpected error: %v", err)
+	}
+	if len(result.AddonData) != 1 {
+		t.Fatalf("parseAddonDetail() returned %d addons, want 1", len(result.AddonData))
+	}
 
-	// Either we get an error or empty results for removed addons
-	if err == nil && len(result.AddonData) > 0 {
-		// If we do get data, verify it's marked as unavailable somehow
-		addon := result.AddonData[0]
-
-		// We should detect that this addon is not available
-		// The Clojure version has a `dead-page?` function
-		if len(addon.LatestReleaseSet) > 0 {
-			t.Error("Expected no releases for removed addon")
-		}
+	addon := result.AddonData[0]
+	if addon.Status != types.RemovedStatus {
+		t.Errorf("Status = %q, want %q", addon.Status, types.RemovedStatus)
+	}
+	if addon.SourceID != "24906" {
+		t.Errorf("SourceID = %q, want 24906", addon.SourceID)
+	}
+	if len(addon.LatestReleaseSet) > 0 {
+		t.Error("Expected no releases for removed addon")
 	}
 }
 
@@ -313,6 +336,16 @@ func TestParseAddonDetailPage_UnknownCompatibility(t *testing.T) {
 	if !addon.GameTrackSet[types.RetailTrack] {
 		t.Error("Expected retail track as default for unknown compatibility")
 	}
+
+	// Unless unknown game tracks are to be left unknown
+	parser.SetUnknownGameTracks(true)
+	result, err = parser.parseAddonDetail(url, content)
+	if err != nil {
+		t.Fatalf("Failed to parse addon detail: %v", err)
+	}
+	if tracks := result.AddonData[0].GameTrackSet; len(tracks) != 0 {
+		t.Errorf("GameTrackSet = %v, want none when unknown game tracks are left unknown", tracks)
+	}
 }
 
 func TestWoWIDateFormatting(t *testing.T) {
@@ -483,6 +516,43 @@ func TestParseCategoryListing(t *testing.T) {
 	}
 }
 
+func TestParseCategoryListing_Archived(t *testing.T) {
+	parser := NewParser()
+
+	content, err := loadFixture("wowinterface--listing.html")
+	if err != nil {
+		t.Fatalf("Failed to load fixture: %v", err)
+	}
+
+	// Same listing markup, but served from the "Discontinued and Outdated Mods" section
+	url := "https://www.wowinterface.com/downloads/index.php?cid=44&sb=dec_date&so=desc&pt=f&page=1"
+	result, err := parser.parseCategoryListing(url, content)
+	if err != nil {
+		t.Fatalf("Failed to parse category listing: %v", err)
+	}
+
+	if len(result.AddonData) == 0 {
+		t.Fatal("Expected addon data from category listing, got none")
+	}
+
+	for i, addon := range result.AddonData {
+		if !addon.Archived {
+			t.Errorf("Addon %d (%s) not flagged as archived", i, addon.SourceID)
+		}
+	}
+
+	// Non-archived listings must not flag addons
+	result, err = parser.parseCategoryListing("https://www.wowinterface.com/downloads/index.php?cid=160&page=1", content)
+	if err != nil {
+		t.Fatalf("Failed to parse category listing: %v", err)
+	}
+	for i, addon := range result.AddonData {
+		if addon.Archived {
+			t.Errorf("Addon %d (%s) unexpectedly flagged as archived", i, addon.SourceID)
+		}
+	}
+}
+
 // Test API fixtures
 func TestParseAPIDetail_Addon21651(t *testing.T) {
 	parser := NewParser()
@@ -583,6 +653,46 @@ func TestParseAPIDetail_Addon25078(t *testing.T) {
 		t.Errorf("DownloadCount = %v, want 83214", addon.DownloadCount)
 	}
 
+	// categoryId 20 is "Bags, Bank, Inventory"
+	for _, tag := range []string{"bags", "bank", "inventory"} {
+		if !addon.TagSet[tag] {
+			t.Errorf("TagSet missing %q, got %v", tag, addon.TagSet)
+		}
+	}
+
+	// Check latest release
+	if len(addon.LatestReleaseSet) != 1 {
+		t.Fatalf("Expected 1 release, got %d", len(addon.LatestReleaseSet))
+	}
+	release := addon.LatestReleaseSet[0]
+	expectedRelease := types.Release{
+		DownloadURL: "https://cdn.wowinterface.com/downloads/getfile.php?id=25078&d=1754440820&minion",
+		Version:     "v1.22.0",
+		Checksum:    "77429fa58f1a4e5201e82d2d04afb4bc",
+	}
+	if !reflect.DeepEqual(release, expectedRelease) {
+		t.Errorf("Release = %+v, want %+v", release, expectedRelease)
+	}
+
+	// Screenshots, in API order
+	if len(addon.ImageList) != 5 {
+		t.Fatalf("Expected 5 images, got %d", len(addon.ImageList))
+	}
+	expectedImage := types.Image{
+		URL:         "https://cdn-wow.mmoui.com/preview/pvw71819.png",
+		ThumbURL:    "https://cdn-wow.mmoui.com/preview/tiny/pvw71819.png",
+		Description: "With optional AHDB data",
+	}
+	if addon.ImageList[0] != expectedImage {
+		t.Errorf("ImageList[0] = %+v, want %+v", addon.ImageList[0], expectedImage)
+	}
+
+	// BBCode changelog reduced to plain text
+	expectedChangelog := "Better Vendor Price\nv1.22.0 (2025-08-06)\nFull Changelog Previous Releases\n\n- Switching packager; Changes for MoP and Retail 11.2.0 - report any issue"
+	if addon.Changelog != expectedChangelog {
+		t.Errorf("Changelog = %q, want %q", addon.Changelog, expectedChangelog)
+	}
+
 	// Check timestamp in UTC (2025-08-06T05:20:20Z)
 	if addon.UpdatedDate == nil {
 		t.Error("Expected UpdatedDate, got nil")
@@ -715,6 +825,24 @@ func TestParseAddonDetail_Addon25078_HTML(t *testing.T) {
 	if len(addon.LatestReleaseSet) == 0 {
 		t.Error("Expected releases, got none")
 	}
+
+	// Gallery screenshots, without the "View 5 Screenshots" link
+	if len(addon.ImageList) != 5 {
+		t.Fatalf("Expected 5 images, got %d: %+v", len(addon.ImageList), addon.ImageList)
+	}
+	expectedImage := types.Image{
+		URL:         "https://cdn-wow.mmoui.com/preview/pvw71819.png",
+		ThumbURL:    "https://cdn-wow.mmoui.com/preview/pvw71819_thumb.png",
+		Description: "With optional AHDB data",
+	}
+	if addon.ImageList[0] != expectedImage {
+		t.Errorf("ImageList[0] = %+v, want %+v", addon.ImageList[0], expectedImage)
+	}
+
+	// Changelog tab, not the description
+	if !strings.HasPrefix(addon.Changelog, "Better Vendor Price\nv1.22.0 (2025-08-06)") {
+		t.Errorf("Changelog = %q, want it to start with the latest version", addon.Changelog)
+	}
 }
 
 func TestParseAddonDetail_Addon24637_MultiGameTracks(t *testing.T) {
@@ -844,3 +972,362 @@ func TestParseAddonDetail_Addon24657_HTML(t *testing.T) {
 		t.Logf("Found %d tags from HTML", len(addon.TagSet))
 	}
 }
+
+func TestCategoryNames_MatchLanding(t *testing.T) {
+	content, err := loadFixture("wowinterface--landing.html")
+	if err != nil {
+		t.Fatalf("Failed to load fixture: %v", err)
+	}
+	landing := string(content)
+
+	for categoryID, name := range categoryNames {
+		if !strings.Contains(landing, "cat"+categoryID+".html") {
+			t.Errorf("category %s (%s) not linked from landing page", categoryID, name)
+		}
+		if len(categoryToTagsWithMaps(name)) == 0 {
+			t.Errorf("category %s (%s) produces no tags", categoryID, name)
+		}
+	}
+}
+
+func TestKnownTags_InVocabulary(t *testing.T) {
+	for _, tag := range KnownTags() {
+		if !tags.IsKnown(tag) {
+			t.Errorf("WowInterface tag %q isn't in the tag vocabulary", tag)
+		}
+	}
+}
+
+func TestPageStatus(t *testing.T) {
+	tests := []struct {
+		html string
+		want types.AddonStatus
+	}{
+		{`<div id="description">A bag addon.</div>`, types.ActiveStatus},
+		{`<td class="panelsurround"><div class="panel">Removed per author's request.</div></td>`, types.RemovedStatus},
+		{`<div class="panel">This file is Pending Approval by the site staff.</div>`, types.PendingStatus},
+		{`<div class="panel">This file is pending author review.</div>`, types.PendingStatus},
+		{`<div id="description">This addon has been abandoned by its author.</div>`, types.AbandonedStatus},
+		// Comments quoting a status message don't count
+		{`<div id="description">A bag addon.</div><div id="comments_t">Is this abandoned by the author?</div>`, types.ActiveStatus},
+	}
+
+	for _, tt := range tests {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", tt.html, err)
+		}
+		if got := pageStatus(doc); got != tt.want {
+			t.Errorf("pageStatus(%s) = %q, want %q", tt.html, got, tt.want)
+		}
+	}
+}
+
+func TestExtractVersion(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Version: v1.3", "v1.3"},
+		{"  Version: 10.2.6.0 ", "10.2.6.0"},
+		{"Version: N/A", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := extractVersion(tt.text); got != tt.want {
+			t.Errorf("extractVersion(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParse_DescriptionSummaries(t *testing.T) {
+	parser := NewParser()
+	parser.SetDescriptionMode(description.SummaryMode)
+
+	tests := []struct {
+		fixture string
+		url     string
+		want    string
+	}{
+		{
+			"wowinterface--addon-detail--multiple-downloads--tabber.html",
+			"https://www.wowinterface.com/downloads/info8149-BrokerPlayedTime.html",
+			"DataBroker plugin to track played time across all your characters. It differs from similar addons in that it only tracks played time; it does not track other things like experience or money.",
+		},
+		{
+			"wowinterface--addon-detail--single-download--supports-all.html",
+			"https://www.wowinterface.com/downloads/info9999-Mapcoords.html",
+			"Mapcoords displays your current coordinates on the minimap.",
+		},
+		{
+			"addon-21651.html",
+			"https://www.wowinterface.com/downloads/info21651",
+			"This tiny addon celebrates you with an olympic cheer and a print-to-screen when one of your auction items sells. Gold is good!",
+		},
+		// BBCode is stripped from API descriptions
+		{
+			"api-21651.json",
+			"https://api.mmoui.com/v4/game/WOW/filedetails/21651.json",
+			"This tiny addon celebrates you with an olympic cheer and a print-to-screen when one of your auction items sells. Gold is good!",
+		},
+		{
+			"api-25078.json",
+			"https://api.mmoui.com/v4/game/WOW/filedetails/25078.json",
+			"Better Vendor Price WoW Classic and BfA",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			content, err := loadFixture(tt.fixture)
+			if err != nil {
+				t.Fatalf("Failed to load fixture: %v", err)
+			}
+			result, err := parser.Parse(tt.url, content)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			if len(result.AddonData) != 1 {
+				t.Fatalf("Parse() returned %d addons, want 1", len(result.AddonData))
+			}
+			if got := result.AddonData[0].Description; got != tt.want {
+				t.Errorf("Description = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDependencies(t *testing.T) {
+	page := `<div id="other_t">
+<div class="divline"><div class="title">Required Dependencies (1)</div></div>
+<table><tr>
+<td class="alt1"><a href="/downloads/info5547-LibStub.html"><i class="fa fa-download"></i></a>&nbsp;<a href="/downloads/info5547-LibStub.html">LibStub</a></td>
+</tr></table>
+<div class="divline"><div class="title">Optional Files (2)</div></div>
+<table><tr>
+<td class="alt1"><a href="/downloads/getfile.php?id=25287&amp;aid=1"><i class="fa fa-download"></i></a>&nbsp;<a href="/downloads/getfile.php?id=25287&amp;aid=1">Skillet Themes</a></td>
+</tr><tr>
+<td class="alt2"><a href="/downloads/info5547-LibStub.html">LibStub</a></td>
+</tr></table>
+<div class="divline"><div class="title">Archived Files (1)</div></div>
+<table><tr>
+<td class="alt1"><a href="/downloads/getfile.php?id=25287&amp;aid=2">Skillet-Classic</a></td>
+</tr></table>
+</div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("failed to parse page: %v", err)
+	}
+
+	want := []types.Dependency{
+		{Label: "LibStub", Required: true, SourceID: "5547", URL: "https://www.wowinterface.com/downloads/info5547-LibStub.html"},
+		{Label: "Skillet Themes", URL: "https://www.wowinterface.com/downloads/getfile.php?id=25287&aid=1"},
+	}
+	if got := parseDependencies(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDependencies() = %+v, want %+v", got, want)
+	}
+
+	// Pages without dependencies or optional files list none
+	content, err := loadFixture("wowinterface--addon-detail--multiple-downloads--no-tabber.html")
+	if err != nil {
+		t.Fatalf("Failed to load fixture: %v", err)
+	}
+	result, err := NewParser().parseAddonDetail("https://www.wowinterface.com/downloads/info25287-Skillet-Classic.html", content)
+	if err != nil {
+		t.Fatalf("parseAddonDetail() unexpected error: %v", err)
+	}
+	if deps := result.AddonData[0].DependencyList; len(deps) != 0 {
+		t.Errorf("DependencyList = %+v, want none", deps)
+	}
+}
+
+func TestParse_Authors(t *testing.T) {
+	tests := []struct {
+		fixture string
+		url     string
+		want    []types.AuthorData
+	}{
+		{
+			"wowinterface--addon-detail--multiple-downloads--no-tabber.html",
+			"https://www.wowinterface.com/downloads/info25287-Skillet-Classic.html",
+			[]types.AuthorData{
+				{AuthorID: "11524", Name: "bsmorgan", SourceIDs: []string{"25287"}, URL: "https://www.wowinterface.com/downloads/author-11524.html"},
+			},
+		},
+		{
+			// several authors, listed without "[More]" links
+			"wowinterface--addon-detail--multiple-downloads--tabber.html",
+			"https://www.wowinterface.com/downloads/info24971-PlayedTime.html",
+			[]types.AuthorData{
+				{AuthorID: "337867", Name: "LudiusMaximus", SourceIDs: []string{"24971"}, URL: "https://www.wowinterface.com/downloads/author-337867.html"},
+				{AuthorID: "28751", Name: "Phanx", SourceIDs: []string{"24971"}, URL: "https://www.wowinterface.com/downloads/author-28751.html"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			content, err := loadFixture(tt.fixture)
+			if err != nil {
+				t.Fatalf("Failed to load fixture: %v", err)
+			}
+
+			parser := NewParser()
+			result, err := parser.Parse(tt.url, content)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.AuthorData, tt.want) {
+				t.Errorf("AuthorData = %+v, want %+v", result.AuthorData, tt.want)
+			}
+			if len(result.DownloadURLs) != 0 {
+				t.Errorf("DownloadURLs = %v, want none unless following author pages", result.DownloadURLs)
+			}
+
+			parser.SetFollowAuthorPages(true)
+			result, err = parser.Parse(tt.url, content)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			var wantURLs []string
+			for _, author := range tt.want {
+				wantURLs = append(wantURLs, author.URL)
+			}
+			if !reflect.DeepEqual(result.DownloadURLs, wantURLs) {
+				t.Errorf("DownloadURLs = %v, want %v", result.DownloadURLs, wantURLs)
+			}
+		})
+	}
+}
+
+func TestParse_AuthorPage(t *testing.T) {
+	page := `<table>
+<tr><td><a href="/downloads/info25078-BetterVendorPrice.html">Better Vendor Price</a></td><td><a href="/downloads/getfile.php?id=25078"><img></a></td></tr>
+<tr><td><a href="https://www.wowinterface.com/downloads/info25012-NeatMinimap.html">Neat Minimap</a></td></tr>
+<tr><td><a href="/downloads/info25078-BetterVendorPrice.html#comments">Comments</a></td></tr>
+</table>`
+
+	result, err := NewParser().Parse("https://www.wowinterface.com/downloads/author-341732.html", []byte(page))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	want := []types.AuthorData{
+		{AuthorID: "341732", SourceIDs: []string{"25078", "25012"}, URL: "https://www.wowinterface.com/downloads/author-341732.html"},
+	}
+	if !reflect.DeepEqual(result.AuthorData, want) {
+		t.Errorf("AuthorData = %+v, want %+v", result.AuthorData, want)
+	}
+	if len(result.AddonData) != 0 {
+		t.Errorf("AddonData = %+v, want none", result.AddonData)
+	}
+}
+
+func TestFilenameGameTrack(t *testing.T) {
+	tests := []struct {
+		href string
+		want types.GameTrack
+	}{
+		{"/downloads/dlfile3678/Skillet-Classic-1.47-beta1-bcc.zip?1661862057", types.ClassicTBCTrack},
+		{"/downloads/dlfile5448/Skillet-Classic-1.83-cata.zip?1712349222", types.ClassicCataTrack},
+		{"/downloads/dlfile1/Addon_v2.0_Wrath.zip", types.ClassicWotLKTrack},
+		{"/downloads/dlfile1/Addon-2.0-mop.zip", types.ClassicMistsTrack},
+		{"/downloads/dlfile1/Addon-Classic.zip", types.ClassicTrack},
+		// the addon's name isn't its game track
+		{"/downloads/dlfile5449/Skillet-Classic-1.20.zip", ""},
+		{"/downloads/dlfile1/ClassicCastbars-2.0-retail.zip", types.RetailTrack},
+		// only files have names
+		{"/downloads/landing.php?fileid=25287", ""},
+	}
+
+	for _, tt := range tests {
+		if got := filenameGameTrack(tt.href); got != tt.want {
+			t.Errorf("filenameGameTrack(%q) = %q, want %q", tt.href, got, tt.want)
+		}
+	}
+}
+
+func TestParse_FilenameGameTracks(t *testing.T) {
+	tests := []struct {
+		fixture    string
+		url        string
+		wantTracks []types.GameTrack // of each release
+	}{
+		{
+			// additional files without icon classes or titles naming their game track
+			"wowinterface--addon-detail--multiple-downloads--no-icon-classes.html",
+			"https://www.wowinterface.com/downloads/info25287-Skillet-Classic.html",
+			[]types.GameTrack{types.RetailTrack, types.ClassicTBCTrack, types.ClassicWotLKTrack, ""},
+		},
+		{
+			// icon classes win over filenames, "-cata" is the wrath file
+			"wowinterface--addon-detail--multiple-downloads--no-tabber.html",
+			"https://www.wowinterface.com/downloads/info25287-Skillet-Classic.html",
+			[]types.GameTrack{types.RetailTrack, types.ClassicTBCTrack, types.ClassicWotLKTrack},
+		},
+		{
+			// a single download for every game track isn't narrowed to one
+			"wowinterface--addon-detail--single-download--supports-all.html",
+			"https://www.wowinterface.com/downloads/info11551-MapCoords.html",
+			[]types.GameTrack{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			content, err := loadFixture(tt.fixture)
+			if err != nil {
+				t.Fatalf("Failed to load fixture: %v", err)
+			}
+			result, err := NewParser().parseAddonDetail(tt.url, content)
+			if err != nil {
+				t.Fatalf("parseAddonDetail() unexpected error: %v", err)
+			}
+
+			var tracks []types.GameTrack
+			for _, release := range result.AddonData[0].LatestReleaseSet {
+				tracks = append(tracks, release.GameTrack)
+			}
+			if !reflect.DeepEqual(tracks, tt.wantTracks) {
+				t.Errorf("release game tracks = %q, want %q", tracks, tt.wantTracks)
+			}
+		})
+	}
+}
+
+// Every kind of data the parser describes addons with must be known to the builder, or it's silently merged first
+func TestParse_DataKinds(t *testing.T) {
+	parser := NewParser()
+	addonData := []types.AddonData{
+		parseAPIFileListItemV3(map[string]interface{}{}),
+		parseAPIFileListItemV4(map[string]interface{}{}),
+		parseAPIDetailItemV3(map[string]interface{}{}),
+		parseAPIDetailItemV4(map[string]interface{}{}, description.FirstLineMode),
+	}
+	pages := map[string]string{
+		"https://www.wowinterface.com/downloads/index.php?cid=160&sb=dec_date&so=desc&pt=f&page=1": "wowinterface--listing.html",
+		"https://www.wowinterface.com/downloads/info8149-BrokerPlayedTime.html":                    "wowinterface--addon-detail--multiple-downloads--tabber.html",
+	}
+	for url, fixture := range pages {
+		content, err := loadFixture(fixture)
+		if err != nil {
+			t.Fatalf("Failed to load fixture: %v", err)
+		}
+		result, err := parser.Parse(url, content)
+		if err != nil {
+			t.Fatalf("Parse(%s) unexpected error: %v", url, err)
+		}
+		addonData = append(addonData, result.AddonData...)
+	}
+
+	seen := make(map[string]bool)
+	for _, data := range addonData {
+		if !slices.Contains(types.KnownDataKinds, data.Kind) {
+			t.Errorf("Kind %q of %s is unknown", data.Kind, data.Filename())
+		}
+		seen[data.Filename()] = true
+	}
+	if len(seen) != 6 {
+		t.Errorf("parsed files = %v, want the listing, web detail and both versions of the API filelist and detail", seen)
+	}
+}
@@ -0,0 +1,122 @@
+package wowi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// gameTrackRulesJSON holds the patterns game tracks are recognised by, see gameTrackRules
+//
+//go:embed gametracks.json
+var gameTrackRulesJSON []byte
+
+// gameTrackRule matches lowercased text mentioning a game track
+type gameTrackRule struct {
+	Name  string          `json:"name"`
+	Track types.GameTrack `json:"track"`
+	Any   []string        `json:"any,omitempty"`  // text contains at least one of these
+	All   []string        `json:"all,omitempty"`  // text contains every one of these
+	None  []string        `json:"none,omitempty"` // text contains none of these
+}
+
+// matches returns true if text, already lowercased, satisfies the rule
+func (r gameTrackRule) matches(text string) bool {
+	contains := func(s string) bool { return strings.Contains(text, s) }
+	if len(r.Any) > 0 && !slices.ContainsFunc(r.Any, contains) {
+		return false
+	}
+	for _, s := range r.All {
+		if !contains(s) {
+			return false
+		}
+	}
+	return !slices.ContainsFunc(r.None, contains)
+}
+
+// versionPrefix maps game versions starting with Prefix to a classic track, other versions are retail
+type versionPrefix struct {
+	Prefix string          `json:"prefix"`
+	Track  types.GameTrack `json:"track"`
+}
+
+// gameTrackRules recognise the game tracks mentioned in compatibility text, category names and game versions.
+// New tracks and variants are supported by editing gametracks.json.
+var gameTrackRules = mustLoadGameTrackRules(gameTrackRulesJSON)
+
+// gameTrackRuleSet is the content of gametracks.json
+type gameTrackRuleSet struct {
+	TextRules       []gameTrackRule `json:"text-rules"`     // every matching rule adds its track, in rule order
+	CategoryRules   []gameTrackRule `json:"category-rules"` // the first matching rule gives the track
+	VersionPrefixes []versionPrefix `json:"version-prefixes"`
+}
+
+// mustLoadGameTrackRules parses the embedded rules, panicking if they're broken or name an unknown track
+func mustLoadGameTrackRules(data []byte) gameTrackRuleSet {
+	var rules gameTrackRuleSet
+	if err := json.Unmarshal(data, &rules); err != nil {
+		panic(fmt.Sprintf("failed to parse game track rules: %v", err))
+	}
+	var tracks []types.GameTrack
+	for _, rule := range slices.Concat(rules.TextRules, rules.CategoryRules) {
+		if len(rule.Any) == 0 && len(rule.All) == 0 {
+			panic(fmt.Sprintf("game track rule %q matches everything", rule.Name))
+		}
+		tracks = append(tracks, rule.Track)
+	}
+	for _, prefix := range rules.VersionPrefixes {
+		tracks = append(tracks, prefix.Track)
+	}
+	for _, track := range tracks {
+		if !slices.Contains(types.AllGameTracks, track) {
+			panic(fmt.Sprintf("unknown game track in game track rules: %q", track))
+		}
+	}
+	return rules
+}
+
+// parseGameTracks returns the game tracks mentioned in text, e.g. "Compatible with Retail, Classic & TBC"
+func parseGameTracks(text string) []types.GameTrack {
+	var tracks []types.GameTrack
+	text = strings.ToLower(text)
+	for _, rule := range gameTrackRules.TextRules {
+		if rule.matches(text) && !slices.Contains(tracks, rule.Track) {
+			tracks = append(tracks, rule.Track)
+		}
+	}
+	return tracks
+}
+
+// parseGameTrackFromText returns the first game track mentioned in text
+func parseGameTrackFromText(text string) types.GameTrack {
+	tracks := parseGameTracks(text)
+	if len(tracks) > 0 {
+		return tracks[0]
+	}
+	return ""
+}
+
+// parseGameTracksFromCategory returns the game track of a WowInterface category, if it's specific to one
+func parseGameTracksFromCategory(category string) []types.GameTrack {
+	categoryLower := strings.ToLower(category)
+	for _, rule := range gameTrackRules.CategoryRules {
+		if rule.matches(categoryLower) {
+			return []types.GameTrack{rule.Track}
+		}
+	}
+	return nil
+}
+
+// gameVersionToGameTrack returns the game track of a game version like "1.15.2", retail if it isn't a classic version
+func gameVersionToGameTrack(version string) types.GameTrack {
+	for _, prefix := range gameTrackRules.VersionPrefixes {
+		if strings.HasPrefix(version, prefix.Prefix) {
+			return prefix.Track
+		}
+	}
+	return types.RetailTrack
+}
@@ -0,0 +1,104 @@
+package wowi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// TestGameTrackRules covers every rule in gametracks.json with text it should and shouldn't match
+func TestGameTrackRules(t *testing.T) {
+	tests := map[string]struct {
+		matches   []string
+		unmatched []string
+	}{
+		"retail":            {[]string{"WoW Retail", "The War Within (11.0.5)", "Dragonflight patch (10.2.5)"}, []string{"Classic (1.15.2)"}},
+		"mists classic":     {[]string{"Mists of Pandaria Classic (5.5.0)", "Pandaria Classic"}, []string{"Classic (1.15.2)"}},
+		"cataclysm classic": {[]string{"Cataclysm Classic (4.4.2)", "Cata"}, []string{"Wrath Classic"}},
+		"wrath classic":     {[]string{"WotLK Classic", "Lich King", "WOTLK Patch (3.4.3)"}, []string{"Cataclysm Classic"}},
+		"titan reforged, a wrath classic variant": {[]string{"Titan Reforged (3.80.0)"}, []string{"Titan Panel"}},
+		"burning crusade classic":                 {[]string{"The Burning Crusade Classic (2.5.4)", "TBC"}, []string{"Classic (1.13.7)"}},
+		"classic without an expansion":            {[]string{"Classic (1.13.7)"}, []string{"TBC Classic", "Lich King Classic", "Retail & Cata"}},
+		"classic listed alongside an expansion":   {[]string{"Cata & Classic", "TBC, Classic"}, []string{"Retail & TBC Classic", "Classic"}},
+		"retail, classic and tbc":                 {[]string{"Retail, Classic & TBC Classic"}, []string{"Retail & Classic"}},
+		"classic era variants":                    {[]string{"Season of Discovery", "Hardcore (1.15.4)"}, []string{"TBC Anniversary (2.5.5)"}},
+		"tbc classic category":                    {[]string{"The Burning Crusade Classic"}, []string{"Classic - General"}},
+		"wrath classic category":                  {[]string{"WotLK Classic"}, []string{"Classic - General"}},
+		"cataclysm classic category":              {[]string{"Cataclysm Classic"}, []string{"Classic - General"}},
+		"general classic category":                {[]string{"Classic - General"}, []string{"Cataclysm Classic"}},
+		"wow classic category":                    {[]string{"AddOns for WoW Classic"}, []string{"Classic - General"}},
+	}
+
+	for _, rule := range append(gameTrackRules.TextRules, gameTrackRules.CategoryRules...) {
+		t.Run(rule.Name, func(t *testing.T) {
+			tt, ok := tests[rule.Name]
+			if !ok {
+				t.Fatalf("no test cases for game track rule %q", rule.Name)
+			}
+			for _, text := range tt.matches {
+				if !rule.matches(strings.ToLower(text)) {
+					t.Errorf("rule %q doesn't match %q", rule.Name, text)
+				}
+			}
+			for _, text := range tt.unmatched {
+				if rule.matches(strings.ToLower(text)) {
+					t.Errorf("rule %q matches %q", rule.Name, text)
+				}
+			}
+		})
+	}
+}
+
+func TestParseGameTracks_Variants(t *testing.T) {
+	tests := []struct {
+		text string
+		want []types.GameTrack
+	}{
+		{"Titan Reforged (3.80.0)", []types.GameTrack{types.ClassicWotLKTrack}},
+		{"Classic Era (1.15.4)", []types.GameTrack{types.ClassicTrack}},
+		{"Season of Discovery", []types.GameTrack{types.ClassicTrack}},
+		{"Mists of Pandaria Classic (5.5.0)", []types.GameTrack{types.ClassicMistsTrack}},
+		{"The War Within (11.0.5)", []types.GameTrack{types.RetailTrack}},
+	}
+
+	for _, tt := range tests {
+		if got := parseGameTracks(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseGameTracks(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestGameTrackRules_VersionPrefixes(t *testing.T) {
+	for _, prefix := range gameTrackRules.VersionPrefixes {
+		if got := gameVersionToGameTrack(prefix.Prefix + "0"); got != prefix.Track {
+			t.Errorf("gameVersionToGameTrack(%q) = %s, want %s", prefix.Prefix+"0", got, prefix.Track)
+		}
+	}
+	if got := gameVersionToGameTrack("3.80.0"); got != types.ClassicWotLKTrack {
+		t.Errorf("gameVersionToGameTrack(3.80.0) = %s, want %s", got, types.ClassicWotLKTrack)
+	}
+}
+
+func TestMustLoadGameTrackRules(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"invalid json", `{`},
+		{"unknown track", `{"text-rules": [{"name": "x", "track": "classic-titan", "any": ["titan"]}]}`},
+		{"matches everything", `{"category-rules": [{"name": "x", "track": "retail"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("mustLoadGameTrackRules(%s) expected a panic", tt.data)
+				}
+			}()
+			mustLoadGameTrackRules([]byte(tt.data))
+		})
+	}
+}
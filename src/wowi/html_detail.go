@@ -0,0 +1,420 @@
+package wowi
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/description"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/normalise"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// parseAddonDetail extracts detailed addon information from an addon detail page
+func (p *Parser) parseAddonDetail(rawURL string, content []byte) (*types.ParseResult, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	addon := types.AddonData{
+		Source: types.WowInterfaceSource,
+		Kind:   types.WebDetailData,
+		URL:    rawURL,
+		Status: pageStatus(doc),
+		WoWI:   make(map[string]interface{}),
+	}
+
+	// Extract source ID from URL
+	if sourceID := SourceIDFromURL(rawURL); sourceID != "" {
+		addon.SourceID = sourceID
+	} else {
+		return nil, fmt.Errorf("could not extract source ID from URL: %s", rawURL)
+	}
+
+	// Removed and pending addons get a message in place of their page, there's nothing else to parse.
+	// The builder leaves them out of the catalogue.
+	if addon.Status == types.RemovedStatus || addon.Status == types.PendingStatus {
+		addon.WoWI = nil
+		return &types.ParseResult{
+			AddonData: []types.AddonData{addon},
+		}, nil
+	}
+
+	// Extract title from meta tag
+	doc.Find("meta[property='og:title']").Each(func(i int, s *goquery.Selection) {
+		if title, exists := s.Attr("content"); exists {
+			addon.Label = strings.TrimSpace(title)
+			addon.Name = slugify(addon.Label)
+		}
+	})
+
+	// Extract description
+	doc.Find("div.postmessage").First().Each(func(i int, s *goquery.Selection) {
+		addon.Description = description.Extract(s.Text(), p.descriptionMode)
+	})
+
+	// Extract authors, listed after "by:" as links to their member pages
+	var authors []string
+	doc.Find("#author a[href*='member.php']").Each(func(i int, s *goquery.Selection) {
+		if author := strings.TrimSpace(s.Text()); author != "" {
+			authors = append(authors, author)
+		}
+	})
+	addon.Author = strings.Join(authors, ", ")
+	authorData := parseAuthors(doc, addon.SourceID)
+
+	// Extract screenshots from the gallery, skipping the "View N Screenshots" link that repeats the first
+	doc.Find("a.lightbox[rel='filepics']:has(img)").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		if href == "" {
+			return
+		}
+		thumb, _ := s.Find("img").Attr("src")
+		addon.ImageList = append(addon.ImageList, types.Image{
+			URL:         absoluteURL(href),
+			ThumbURL:    absoluteURL(thumb),
+			Description: strings.TrimSpace(s.AttrOr("title", "")),
+		})
+	})
+
+	// Extract changelog from the changelog tab
+	doc.Find("#changelog_t div.postmessage").First().Each(func(i int, s *goquery.Selection) {
+		addon.Changelog = description.Changelog(s.Text())
+	})
+
+	// Extract created date from info table
+	doc.Find("td:contains('Created:')").Next().Each(func(i int, s *goquery.Selection) {
+		dateStr := strings.TrimSpace(s.Text())
+		if dateStr != "" {
+			if parsedTime, err := parseWoWIDate(dateStr); err == nil {
+				addon.CreatedDate = &parsedTime
+			}
+		}
+	})
+
+	// Extract categories first - we'll use them for game track inference and tags
+	categorySet := make(map[string]bool)
+
+	// Look for categories in the info table
+	doc.Find("td:contains('Categories:')").Next().Each(func(i int, s *goquery.Selection) {
+		s.Find("a").Each(func(j int, link *goquery.Selection) {
+			category := strings.TrimSpace(link.Text())
+			if category != "" {
+				categorySet[category] = true
+			}
+		})
+	})
+
+	// Also check selected dropdown options as fallback
+	doc.Find("select option[selected]").Each(func(i int, s *goquery.Selection) {
+		category := strings.TrimSpace(s.Text())
+		if category != "" && !strings.HasPrefix(category, "Choose") {
+			// Clean up category text (remove leading dashes and spaces)
+			category = strings.TrimLeft(category, "- ")
+			categorySet[category] = true
+		}
+	})
+
+	// Convert categories to tags (like Clojure version does)
+	// Use replacement/supplement maps first, then split if no replacement
+	addon.TagSet = make(map[string]bool)
+	for category := range categorySet {
+		tags := categoryToTagsWithMaps(category)
+		for _, tag := range tags {
+			if tag != "" {
+				addon.TagSet[tag] = true
+			}
+		}
+	}
+
+	// Extract game tracks from compatibility info
+	addon.GameTrackSet = make(map[types.GameTrack]bool)
+
+	// Check #multitoc element for basic compatibility
+	doc.Find("#multitoc").Each(func(i int, s *goquery.Selection) {
+		compatText := s.Text()
+		tracks := parseGameTracks(compatText)
+		for _, track := range tracks {
+			addon.GameTrackSet[track] = true
+		}
+	})
+
+	// Also check detailed compatibility table
+	doc.Find("td:contains('Compatibility:')").Next().Each(func(i int, s *goquery.Selection) {
+		s.Find("div").Each(func(j int, div *goquery.Selection) {
+			compatText := div.Text()
+			tracks := parseGameTracks(compatText)
+			for _, track := range tracks {
+				addon.GameTrackSet[track] = true
+			}
+		})
+	})
+
+	// NOTE: We do NOT infer game tracks from categories because:
+	// 1. Categories like "Classic - General" appear in dropdowns for ALL addons
+	// 2. Only the explicit Compatibility field indicates actual game version support
+	// 3. Inferring from categories causes false positives (retail addons marked as classic)
+
+	// Extract latest releases and detect game tracks from download sections
+	var releases []types.Release
+	pageVersion := extractVersion(doc.Find("#version").First().Text())
+
+	// Count download buttons to determine if this is a multi-version addon
+	downloadButtonCount := doc.Find(".infobox div#downloadbutton").Length()
+	isMultiVersion := downloadButtonCount > 1
+
+	// Find all download sections - each has an #iconnew (or #icon) div followed by a #download div
+	// The #iconnew div has a class that indicates the game version (tbc, wotlk, cata, etc.)
+	// Try both #iconnew (multi-version addons) and #icon (simple addons)
+	iconSelector := ".infobox div#iconnew, .infobox div#icon"
+	doc.Find(iconSelector).Each(func(i int, iconDiv *goquery.Selection) {
+		// Get the game track from the icon div class
+		var gameTrack types.GameTrack
+		if classAttr, exists := iconDiv.Attr("class"); exists {
+			switch {
+			case strings.Contains(classAttr, "cata"):
+				gameTrack = types.ClassicCataTrack
+			case strings.Contains(classAttr, "mists"):
+				gameTrack = types.ClassicMistsTrack
+			case strings.Contains(classAttr, "wotlk"):
+				gameTrack = types.ClassicWotLKTrack
+			case strings.Contains(classAttr, "tbc"):
+				gameTrack = types.ClassicTBCTrack
+			}
+		}
+
+		// For multi-version addons, we can trust the download link title
+		// because each version has its own download button with accurate labels
+		if isMultiVersion && gameTrack == "" {
+			iconDiv.Find("a").Each(func(j int, a *goquery.Selection) {
+				if title, exists := a.Attr("title"); exists {
+					titleLower := strings.ToLower(title)
+					if strings.Contains(titleLower, "wow classic") && !strings.Contains(titleLower, "burning crusade") &&
+						!strings.Contains(titleLower, "wrath") && !strings.Contains(titleLower, "cataclysm") {
+						gameTrack = types.ClassicTrack
+					} else if strings.Contains(titleLower, "wow retail") {
+						gameTrack = types.RetailTrack
+					}
+				}
+			})
+		}
+		// Note: For single-version addons, we do NOT use the title because it's unreliable.
+		// WoWInterface often shows "WoW Retail" even for classic-only addons.
+
+		// Find the adjacent download div to get the actual download link
+		downloadDiv := iconDiv.NextAll().Filter("#download").First()
+		downloadDiv.Find("a").Each(func(j int, a *goquery.Selection) {
+			if href, exists := a.Attr("href"); exists && strings.Contains(href, "downloads") {
+				// Neither the icon nor the title said, the file may be named after its game track
+				gameTrack := gameTrack
+				if gameTrack == "" {
+					gameTrack = filenameGameTrack(href)
+				}
+
+				// Add game track to addon's supported tracks
+				if gameTrack != "" {
+					addon.GameTrackSet[gameTrack] = true
+				}
+
+				release := types.Release{
+					DownloadURL: Host + href,
+					GameTrack:   gameTrack,
+					Version:     downloadVersion(iconDiv.Parent(), href, pageVersion),
+				}
+				releases = append(releases, release)
+			}
+		})
+	})
+
+	addon.LatestReleaseSet = releases
+	addon.DependencyList = parseDependencies(doc)
+
+	// Default to retail if no game tracks found, unless they're to be left unknown
+	if len(addon.GameTrackSet) == 0 && !p.unknownGameTracks {
+		addon.GameTrackSet = map[types.GameTrack]bool{types.RetailTrack: true}
+	}
+
+	result := &types.ParseResult{
+		AddonData:  []types.AddonData{addon},
+		AuthorData: authorData,
+	}
+	if p.followAuthorPages {
+		for _, author := range authorData {
+			result.DownloadURLs = append(result.DownloadURLs, author.URL)
+		}
+	}
+	return result, nil
+}
+
+// parseAuthors returns the authors of the addon sourceID, listed after "by:" as links to their member pages
+func parseAuthors(doc *goquery.Document, sourceID string) []types.AuthorData {
+	var authors []types.AuthorData
+	doc.Find("#author a[href*='member.php']").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		u, err := url.Parse(href)
+		if err != nil {
+			return
+		}
+		authorID := u.Query().Get("userid")
+		if authorID == "" {
+			return
+		}
+		authors = append(authors, types.AuthorData{
+			AuthorID:  authorID,
+			Name:      strings.TrimSpace(s.Text()),
+			SourceIDs: []string{sourceID},
+			URL:       AuthorURL(authorID),
+		})
+	})
+	return authors
+}
+
+// filenameVersionRegex matches the version in a download's filename, e.g. "1.47-beta1-bcc" in "Skillet-Classic-1.47-beta1-bcc.zip"
+var filenameVersionRegex = regexp.MustCompile(`(?:^|[-_ ])(v?\d+(?:\.\d+)+[\w.-]*)\.zip$`)
+
+// downloadVersion returns the version of the file downloaded from href in a download section.
+// The page's version is that of the main file, additional files for other game tracks are versioned by their filename.
+func downloadVersion(section *goquery.Selection, href, pageVersion string) string {
+	if version := extractVersion(section.Find("#version").First().Text()); version != "" {
+		return version
+	}
+	if !strings.Contains(href, "/dlfile") {
+		return pageVersion
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if m := filenameVersionRegex.FindStringSubmatch(path.Base(u.Path)); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// filenameGameTracks are the suffixes files for a particular game track are named with, e.g. "-bcc" in "Skillet-Classic-1.47-beta1-bcc.zip"
+var filenameGameTracks = map[string]types.GameTrack{
+	"retail":   types.RetailTrack,
+	"mainline": types.RetailTrack,
+	"classic":  types.ClassicTrack,
+	"vanilla":  types.ClassicTrack,
+	"era":      types.ClassicTrack,
+	"bcc":      types.ClassicTBCTrack,
+	"tbc":      types.ClassicTBCTrack,
+	"wrath":    types.ClassicWotLKTrack,
+	"wotlk":    types.ClassicWotLKTrack,
+	"cata":     types.ClassicCataTrack,
+	"mists":    types.ClassicMistsTrack,
+	"mop":      types.ClassicMistsTrack,
+}
+
+// filenameGameTrack returns the game track the file downloaded from href is named after, or an empty string.
+// Only the words after the version are considered when there is one, "Skillet-Classic-1.20.zip" names the addon, not its track.
+// The last word naming a track wins.
+func filenameGameTrack(href string) types.GameTrack {
+	if !strings.Contains(href, "/dlfile") {
+		return ""
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	filename := strings.ToLower(path.Base(u.Path))
+	if m := filenameVersionRegex.FindStringSubmatch(filename); m != nil {
+		filename = m[1]
+	}
+	words := strings.FieldsFunc(strings.TrimSuffix(filename, ".zip"), func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == ' '
+	})
+	for i := len(words) - 1; i >= 0; i-- {
+		if track, ok := filenameGameTracks[words[i]]; ok {
+			return track
+		}
+	}
+	return ""
+}
+
+// dependencySections are the titles of the sections of an addon page listing other files, and whether they're required
+var dependencySections = []struct {
+	title    string // prefix, lowercase
+	required bool
+}{
+	{"required dependencies", true},
+	{"dependencies", true},
+	{"optional files", false},
+}
+
+// parseDependencies returns the files listed in the dependency and optional files sections of an addon page.
+// Each section is a "div.divline" title followed by links to the files, up to the next section.
+func parseDependencies(doc *goquery.Document) []types.Dependency {
+	var dependencies []types.Dependency
+	seen := make(map[string]bool)
+	doc.Find("div.divline").Each(func(_ int, divline *goquery.Selection) {
+		title := strings.ToLower(normalise.Text(divline.Find("div.title").Text()))
+		required, ok := false, false
+		for _, section := range dependencySections {
+			if strings.HasPrefix(title, section.title) {
+				required, ok = section.required, true
+				break
+			}
+		}
+		if !ok {
+			return
+		}
+
+		divline.NextUntil("div.divline").Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+			label := normalise.Text(a.Text())
+			href, _ := a.Attr("href")
+			if label == "" || !strings.Contains(href, "/downloads/") {
+				return // icon links repeat the named link
+			}
+			link := href
+			if strings.HasPrefix(link, "/") {
+				link = Host + link
+			}
+			if seen[link] {
+				return
+			}
+			seen[link] = true
+			dependencies = append(dependencies, types.Dependency{
+				Label:    label,
+				Required: required,
+				SourceID: SourceIDFromURL(link),
+				URL:      link,
+			})
+		})
+	})
+	return dependencies
+}
+
+// statusMessages are shown by WowInterface on the pages of addons that aren't active, checked in order
+var statusMessages = []struct {
+	message string // lowercase
+	status  types.AddonStatus
+}{
+	{"removed per author's request", types.RemovedStatus},
+	{"this file has been removed", types.RemovedStatus},
+	{"file no longer available", types.RemovedStatus},
+	{"pending approval", types.PendingStatus},
+	{"awaiting approval", types.PendingStatus},
+	{"pending author review", types.PendingStatus},
+	{"abandoned by its author", types.AbandonedStatus},
+	{"abandoned by the author", types.AbandonedStatus},
+}
+
+// pageStatus classifies an addon detail page by the status message it shows, if any.
+// User comments are ignored, they quote these messages often enough.
+func pageStatus(doc *goquery.Document) types.AddonStatus {
+	page := goquery.CloneDocument(doc)
+	page.Find("#comments_t").Remove()
+	pageText := strings.ToLower(page.Text())
+	for _, sm := range statusMessages {
+		if strings.Contains(pageText, sm.message) {
+			return sm.status
+		}
+	}
+	return types.ActiveStatus
+}
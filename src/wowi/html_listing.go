@@ -0,0 +1,148 @@
+package wowi
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
+)
+
+// parseCategoryGroup extracts category links from a category group page
+func (p *Parser) parseCategoryGroup(content []byte) (*types.ParseResult, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var urls []string
+
+	doc.Find("div#colleft div.subcats div.subtitle a").Each(func(i int, s *goquery.Selection) {
+		href, exists := s.Attr("href")
+		if !exists {
+			return
+		}
+
+		// Check if this is another category group page
+		isGroupPage := false
+		for _, page := range CategoryGroupPages {
+			if strings.Contains(href, page) {
+				urls = append(urls, Host+href)
+				isGroupPage = true
+				break
+			}
+		}
+
+		if !isGroupPage {
+			// Convert to listing page URL with sorting
+			if catID := extractCategoryID(href); catID != "" {
+				urls = append(urls, categoryListingURL(catID))
+			}
+		}
+	})
+
+	return &types.ParseResult{
+		DownloadURLs: urls,
+	}, nil
+}
+
+// parseCategoryListing extracts addon data and pagination URLs from a listing page
+func (p *Parser) parseCategoryListing(rawURL string, content []byte) (*types.ParseResult, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var addonData []types.AddonData
+	var urls []string
+
+	// Addons listed in an archived section are flagged so they can be kept out of the short catalogue
+	archived := false
+	if u, err := url.Parse(rawURL); err == nil {
+		archived = IsArchivedCategory(u.Query().Get("cid"))
+	}
+
+	// Extract pagination URLs
+	doc.Find(".pagenav td.alt1 a").Each(func(i int, s *goquery.Selection) {
+		href, exists := s.Attr("href")
+		if exists && !strings.Contains(href, "http") {
+			urls = append(urls, Host+href)
+		}
+	})
+
+	// Extract addon information
+	doc.Find("#filepage div.file").Each(func(i int, s *goquery.Selection) {
+		addon := types.AddonData{
+			Source:   types.WowInterfaceSource,
+			Kind:     types.ListingData,
+			Archived: archived,
+			WoWI:     make(map[string]interface{}),
+		}
+
+		// Extract title and source ID
+		s.Find("a[href*='fileinfo']").Each(func(j int, link *goquery.Selection) {
+			href, exists := link.Attr("href")
+			if exists {
+				if sourceID := extractSourceIDFromHref(href); sourceID != "" {
+					addon.SourceID = sourceID
+					addon.Label = strings.TrimSpace(link.Text())
+					addon.Name = slugify(addon.Label)
+					addon.URL = Host + "/downloads/info" + sourceID
+					urls = append(urls, addon.URL) // Add detail page URL
+				}
+			}
+		})
+
+		// Extract updated date
+		s.Find("div.updated").Each(func(j int, date *goquery.Selection) {
+			if dateStr := extractUpdatedDate(date.Text()); dateStr != "" {
+				if parsedDate, err := parseWoWIDate(dateStr); err == nil {
+					addon.UpdatedDate = &parsedDate
+				}
+			}
+		})
+
+		// Extract download count
+		s.Find("div.downloads").Each(func(j int, downloads *goquery.Selection) {
+			if count := extractDownloadCount(downloads.Text()); count > 0 {
+				addon.DownloadCount = &count
+			}
+		})
+
+		if addon.SourceID != "" {
+			addonData = append(addonData, addon)
+		}
+	})
+
+	return &types.ParseResult{
+		AddonData:    addonData,
+		DownloadURLs: urls,
+	}, nil
+}
+
+// parseAuthorPage extracts the addons listed on an author's page
+func (p *Parser) parseAuthorPage(rawURL string, content []byte) (*types.ParseResult, error) {
+	urlType, authorID := classify(rawURL)
+	if urlType != URLTypeAuthorPage {
+		return nil, fmt.Errorf("could not extract author ID from URL: %s", rawURL)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	author := types.AuthorData{AuthorID: authorID, URL: AuthorURL(authorID)}
+	doc.Find("a[href*='/downloads/info']").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		if sourceID := extractSourceIDFromURL(href); sourceID != "" && !slices.Contains(author.SourceIDs, sourceID) {
+			author.SourceIDs = append(author.SourceIDs, sourceID)
+		}
+	})
+
+	return &types.ParseResult{
+		AuthorData: []types.AuthorData{author},
+	}, nil
+}
@@ -1,9 +1,11 @@
 package wowi
 
 import (
-	"strings"
+	"errors"
+	"reflect"
 	"testing"
 
+	"github.com/ogri-la/strongbox-catalogue-builder-go/src/http"
 	"github.com/ogri-la/strongbox-catalogue-builder-go/src/types"
 )
 
@@ -30,6 +32,11 @@ func TestURLClassifier_ClassifyURL(t *testing.T) {
 			url:      "https://www.wowinterface.com/downloads/info12345",
 			expected: URLTypeAddonDetail,
 		},
+		{
+			name:     "Author page",
+			url:      "https://www.wowinterface.com/downloads/author-341732.html",
+			expected: URLTypeAuthorPage,
+		},
 		{
 			name:     "Category group page (deprecated)",
 			url:      "https://www.wowinterface.com/addons.php",
@@ -45,6 +52,91 @@ func TestURLClassifier_ClassifyURL(t *testing.T) {
 			url:      "https://example.com/unknown",
 			expected: URLTypeUnknown,
 		},
+		{
+			name:     "API v3 file list",
+			url:      APIFileListV3,
+			expected: URLTypeAPIFileList,
+		},
+		{
+			name:     "API file list on another host",
+			url:      "https://example.com/v4/game/WOW/filelist.json",
+			expected: URLTypeUnknown,
+		},
+		{
+			name:     "API detail with a name",
+			url:      "https://api.mmoui.com/v4/game/WOW/filedetails/addon.json",
+			expected: URLTypeUnknown,
+		},
+		{
+			name:     "Addon detail page with a name",
+			url:      "https://www.wowinterface.com/downloads/info5547-LibStub.html",
+			expected: URLTypeAddonDetail,
+		},
+		{
+			name:     "Addon detail page without www, over http",
+			url:      "http://WoWInterface.com/downloads/info5547",
+			expected: URLTypeAddonDetail,
+		},
+		{
+			name:     "Addon detail page on another host",
+			url:      "https://example.com/downloads/info5547",
+			expected: URLTypeUnknown,
+		},
+		{
+			name:     "Addon detail page below another path",
+			url:      "https://www.wowinterface.com/forums/downloads/info5547",
+			expected: URLTypeUnknown,
+		},
+		{
+			name:     "Old addon detail page",
+			url:      "https://www.wowinterface.com/downloads/fileinfo.php?id=5547",
+			expected: URLTypeAddonDetail,
+		},
+		{
+			name:     "Old addon detail page without an id",
+			url:      "https://www.wowinterface.com/downloads/fileinfo.php",
+			expected: URLTypeUnknown,
+		},
+		{
+			name:     "Download",
+			url:      "https://cdn.wowinterface.com/downloads/getfile.php?id=25078&d=1754440820&minion",
+			expected: URLTypeDownloadFile,
+		},
+		{
+			name:     "Download of an optional file",
+			url:      "https://www.wowinterface.com/downloads/getfile.php?id=25287&aid=1",
+			expected: URLTypeDownloadFile,
+		},
+		{
+			name:     "Download without an id",
+			url:      "https://cdn.wowinterface.com/downloads/getfile.php",
+			expected: URLTypeUnknown,
+		},
+		{
+			name:     "Download landing page",
+			url:      "https://www.wowinterface.com/downloads/landing.php?fileid=25078",
+			expected: URLTypeDownloadFile,
+		},
+		{
+			name:     "Download landing page without a file",
+			url:      "https://www.wowinterface.com/downloads/landing.php?id=25078",
+			expected: URLTypeUnknown,
+		},
+		{
+			name:     "Category listing sorted",
+			url:      categoryListingURL("44"),
+			expected: URLTypeCategoryListing,
+		},
+		{
+			name:     "Category listing without a category",
+			url:      "https://www.wowinterface.com/downloads/index.php?page=2",
+			expected: URLTypeUnknown,
+		},
+		{
+			name:     "Forum thread page",
+			url:      "https://www.wowinterface.com/forums/showthread.php?t=1&page=2",
+			expected: URLTypeUnknown,
+		},
 	}
 
 	for _, tt := range tests {
@@ -57,6 +149,39 @@ func TestURLClassifier_ClassifyURL(t *testing.T) {
 	}
 }
 
+func TestPageHandlers(t *testing.T) {
+	for urlType := URLTypeCategoryGroup; urlType <= URLTypeAuthorPage; urlType++ {
+		if _, ok := pageHandlers[urlType]; !ok {
+			t.Errorf("no page handler registered for URL type %d", urlType)
+		}
+	}
+	if _, err := NewParser().Parse("https://example.com/unknown", nil); err == nil {
+		t.Error("Parse() of an unknown URL type expected an error")
+	}
+	if _, err := NewParser().Parse("https://cdn.wowinterface.com/downloads/getfile.php?id=25078", []byte("<html></html>")); err == nil {
+		t.Error("Parse() of a download expected an error")
+	}
+}
+
+func TestParse_UnexpectedContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		content string
+	}{
+		{"error page in place of JSON", GetAPIHost(APIVersionV4) + "/filedetails/25078.json", "<html><body><h1>502 Bad Gateway</h1></body></html>"},
+		{"zip in place of a page", "https://www.wowinterface.com/downloads/info25078", "PK\x03\x04\x14\x00\x00\x00\x08\x00"},
+		{"JSON in place of a page", "https://www.wowinterface.com/downloads/info25078", `{"error": "not found"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewParser().Parse(tt.url, []byte(tt.content)); !errors.Is(err, http.ErrUnexpectedContent) {
+				t.Errorf("Parse() error = %v, want %v", err, http.ErrUnexpectedContent)
+			}
+		})
+	}
+}
+
 func TestExtractSourceIDFromHref(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -128,6 +253,33 @@ func TestExtractSourceIDFromURL(t *testing.T) {
 	}
 }
 
+func TestSourceIDFromURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{"Addon detail page", "https://www.wowinterface.com/downloads/info23145", "23145"},
+		{"API v4 detail", APIHostV4 + "/filedetails/8149.json", "8149"},
+		{"API v3 detail", APIHostV3 + "/filedetails/8149.json", "8149"},
+		{"API filelist", APIFileListV4, ""},
+		{"Category listing", categoryListingURL("44"), ""},
+		{"Addon detail page with a name", "https://www.wowinterface.com/downloads/info5547-LibStub.html", "5547"},
+		{"Old addon detail page", "https://www.wowinterface.com/downloads/fileinfo.php?id=5547", "5547"},
+		{"Download", "https://cdn.wowinterface.com/downloads/getfile.php?id=25078", ""},
+		{"Author page", "https://www.wowinterface.com/downloads/author-341732.html", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := SourceIDFromURL(tt.url)
+			if result != tt.expected {
+				t.Errorf("SourceIDFromURL(%s) = %s, want %s", tt.url, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestParseWoWIDate(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -437,10 +589,95 @@ func TestParseAPIFileList(t *testing.T) {
 		t.Errorf("First addon Name = %s, want adibags", addon1.Name)
 	}
 
+	wantInterfaces := map[types.GameTrack][]int{types.RetailTrack: {100205}, types.ClassicTrack: {11302}}
+	if !reflect.DeepEqual(addon1.InterfaceVersions, wantInterfaces) {
+		t.Errorf("First addon InterfaceVersions = %v, want %v", addon1.InterfaceVersions, wantInterfaces)
+	}
+
 	// Check that URLs were generated
 	if len(result.DownloadURLs) == 0 {
 		t.Error("parseAPIFileList() generated no download URLs")
 	}
+
+	// Every generated URL carries the addon's last update for cache TTL hints
+	for _, downloadURL := range result.DownloadURLs {
+		if _, ok := result.UpdatedDates[downloadURL]; !ok {
+			t.Errorf("parseAPIFileList() missing updated date for %s", downloadURL)
+		}
+	}
+}
+
+func TestParseAPIFileList_APIOnly(t *testing.T) {
+	parser := NewParser()
+	parser.SetAPIOnly(true)
+
+	result, err := parser.parseAPIFileList([]byte(`[{"id": 23145, "title": "AdiBags", "lastUpdate": 1640995200}]`))
+	if err != nil {
+		t.Fatalf("parseAPIFileList() unexpected error: %v", err)
+	}
+	want := []string{APIHostV4 + "/filedetails/23145.json"}
+	if !reflect.DeepEqual(result.DownloadURLs, want) {
+		t.Errorf("parseAPIFileList() download URLs = %v, want only the API detail %v", result.DownloadURLs, want)
+	}
+}
+
+func TestParseAPIFileList_V3Folders(t *testing.T) {
+	parser := NewParser()
+
+	jsonData := `[
+		{
+			"UID": "23145",
+			"UIName": "AdiBags",
+			"UIDate": 1640995200000,
+			"UIDir": ["AdiBags", "AdiBags_Config"]
+		},
+		{
+			"UID": "12345",
+			"UIName": "Deadly Boss Mods",
+			"UIDate": 1640995300000
+		}
+	]`
+
+	result, err := parser.parseAPIFileList([]byte(jsonData))
+	if err != nil {
+		t.Fatalf("parseAPIFileList() unexpected error: %v", err)
+	}
+	if len(result.AddonData) != 2 {
+		t.Fatalf("parseAPIFileList() returned %d addons, want 2", len(result.AddonData))
+	}
+
+	want := []string{"AdiBags", "AdiBags_Config"}
+	if got := result.AddonData[0].FolderList; !reflect.DeepEqual(got, want) {
+		t.Errorf("FolderList = %v, want %v", got, want)
+	}
+	if got := result.AddonData[1].FolderList; got != nil {
+		t.Errorf("FolderList without UIDir = %v, want nil", got)
+	}
+}
+
+func TestFileListCategoryID(t *testing.T) {
+	parser := NewParser()
+	jsonData := `[{"id": 1, "title": "One", "categoryId": 21}, {"id": 2, "title": "Two"}]`
+	result, err := parser.parseAPIFileList([]byte(jsonData))
+	if err != nil {
+		t.Fatalf("parseAPIFileList() unexpected error: %v", err)
+	}
+	if got := FileListCategoryID(result.AddonData[0]); got != "21" {
+		t.Errorf("FileListCategoryID() = %q, want %q", got, "21")
+	}
+	if got := FileListCategoryID(result.AddonData[1]); got != "" {
+		t.Errorf("FileListCategoryID() without a category = %q, want none", got)
+	}
+	if got := FileListCategoryID(types.AddonData{WoWI: map[string]interface{}{"UICATID": "21"}}); got != "21" {
+		t.Errorf("FileListCategoryID(v3) = %q, want %q", got, "21")
+	}
+
+	if id, ok := CategoryID("unit mods"); !ok || id != "21" {
+		t.Errorf("CategoryID(unit mods) = %q, %v, want 21", id, ok)
+	}
+	if _, ok := CategoryID("Not A Category"); ok {
+		t.Error("CategoryID(Not A Category) = found, want not found")
+	}
 }
 
 func TestParseAPIDetail(t *testing.T) {
@@ -491,9 +728,9 @@ func TestParseAPIDetail(t *testing.T) {
 		t.Errorf("Source = %s, want %s", addon.Source, types.WowInterfaceSource)
 	}
 
-	// Filename depends on API version detected
-	if addon.Filename != "api-detail-v4.json" && addon.Filename != "api-detail-v3.json" {
-		t.Errorf("Filename = %s, want api-detail-v4.json or api-detail-v3.json", addon.Filename)
+	// The API version depends on the one detected
+	if addon.Kind != types.APIDetailData || (addon.APIVersion != "v4" && addon.APIVersion != "v3") {
+		t.Errorf("Kind, APIVersion = %s, %s, want api-detail, v4 or v3", addon.Kind, addon.APIVersion)
 	}
 
 	// Check that WoWI data was stored
@@ -505,6 +742,47 @@ func TestParseAPIDetail(t *testing.T) {
 	if author, ok := addon.WoWI["author"].(string); !ok || author != "MooreaTv" {
 		t.Errorf("WoWI author = %v, want MooreaTv", addon.WoWI["author"])
 	}
+	if addon.Author != "MooreaTv" {
+		t.Errorf("Author = %q, want MooreaTv", addon.Author)
+	}
+	if addon.FavoriteCount == nil || *addon.FavoriteCount != 188 {
+		t.Errorf("FavoriteCount = %v, want 188", addon.FavoriteCount)
+	}
+	if addon.MonthlyDownloadCount == nil || *addon.MonthlyDownloadCount != 33 {
+		t.Errorf("MonthlyDownloadCount = %v, want 33", addon.MonthlyDownloadCount)
+	}
+}
+
+func TestParseAPIDetail_V3Release(t *testing.T) {
+	parser := NewParser()
+
+	jsonData := `[{
+		"UID": "25078",
+		"UIName": "Better Vendor Price",
+		"UIVersion": "v1.22.0",
+		"UIMD5": "77429fa58f1a4e5201e82d2d04afb4bc",
+		"UIFileName": "BetterVendorPrice-v1.22.0.zip",
+		"UIDownload": "https://cdn.wowinterface.com/downloads/getfile.php?id=25078"
+	}]`
+
+	result, err := parser.parseAPIDetail([]byte(jsonData))
+	if err != nil {
+		t.Fatalf("parseAPIDetail() unexpected error: %v", err)
+	}
+
+	addon := result.AddonData[0]
+	if len(addon.LatestReleaseSet) != 1 {
+		t.Fatalf("LatestReleaseSet has %d releases, want 1", len(addon.LatestReleaseSet))
+	}
+
+	expected := types.Release{
+		DownloadURL: "https://cdn.wowinterface.com/downloads/getfile.php?id=25078",
+		Version:     "v1.22.0",
+		Checksum:    "77429fa58f1a4e5201e82d2d04afb4bc",
+	}
+	if !reflect.DeepEqual(addon.LatestReleaseSet[0], expected) {
+		t.Errorf("Release = %+v, want %+v", addon.LatestReleaseSet[0], expected)
+	}
 }
 
 func TestParseAPIDetail_EmptyArray(t *testing.T) {
@@ -533,362 +811,51 @@ func TestParseAPIDetail_InvalidJSON(t *testing.T) {
 	}
 }
 
-func TestCleanDescription(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected string
-	}{
-		{
-			name:     "Empty string",
-			input:    "",
-			expected: "",
-		},
-		{
-			name:     "Simple description",
-			input:    "A simple addon that does cool things.",
-			expected: "A simple addon that does cool things.",
-		},
-		{
-			name:     "Multi-line with decorative separator",
-			input:    "==========\nThis addon helps you.\nMore details here.",
-			expected: "This addon helps you.",
-		},
-		{
-			name:     "Skip 'About' prefix",
-			input:    "About: This is an awesome addon.\nIt does things.",
-			expected: "It does things.",
-		},
-		{
-			name:     "Skip 'Description' prefix",
-			input:    "Description\nHelps manage your inventory.",
-			expected: "Helps manage your inventory.",
-		},
-		{
-			name:     "Skip 'What is it' phrase",
-			input:    "What is it?\nA utility addon for tracking quests.",
-			expected: "A utility addon for tracking quests.",
-		},
-		{
-			name:     "Skip donation message",
-			input:    "Donate via PayPal!\nThis addon improves your UI.",
-			expected: "This addon improves your UI.",
-		},
-		{
-			name:     "Skip greeting",
-			input:    "Hello!\nWelcome to my addon.",
-			expected: "Welcome to my addon.",
-		},
-		{
-			name:     "Skip 'Discontinued' warning",
-			input:    "Discontinued: No longer maintained\nShows damage meters.",
-			expected: "Shows damage meters.",
-		},
-		{
-			name:     "Skip multiple leading lines",
-			input:    "===\nIntro:\nHello there!\nThis addon tracks achievements.",
-			expected: "This addon tracks achievements.",
-		},
-		{
-			name:     "Truncate long description",
-			input:    strings.Repeat("A", 1500),
-			expected: strings.Repeat("A", 1000),
-		},
-		{
-			name:     "Skip 'Overview:' line",
-			input:    "Overview:\nManage your bags efficiently.",
-			expected: "Manage your bags efficiently.",
-		},
-		{
-			name:     "Don't skip 'aboutface' (not a prefix match)",
-			input:    "aboutface is a military command.",
-			expected: "aboutface is a military command.",
-		},
-		{
-			name:     "All lines are decorative",
-			input:    "===\n---\n***",
-			expected: "",
-		},
-		{
-			name:     "Skip 'Important' message",
-			input:    "Important: Read the documentation\nProvides DPS tracking.",
-			expected: "Provides DPS tracking.",
-		},
+func TestNormaliseAddonData(t *testing.T) {
+	addon := types.AddonData{
+		Label:       "Caf\xe9 &amp; Bar",
+		Name:        "caf-amp-bar",
+		Description: "  Donâ€™t   panic  ",
+		TagSet:      map[string]bool{" bags ": true},
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := cleanDescription(tt.input)
-			if result != tt.expected {
-				t.Errorf("cleanDescription() = %q, want %q", result, tt.expected)
-			}
-		})
-	}
-}
+	normaliseAddonData(&addon)
 
-func TestIsPureNonAlphanumeric(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected bool
-	}{
-		{
-			name:     "Empty string",
-			input:    "",
-			expected: true,
-		},
-		{
-			name:     "Only equals signs",
-			input:    "========",
-			expected: true,
-		},
-		{
-			name:     "Only dashes",
-			input:    "--------",
-			expected: true,
-		},
-		{
-			name:     "Mixed punctuation",
-			input:    "=== *** ---",
-			expected: true,
-		},
-		{
-			name:     "Contains letters",
-			input:    "=== text ===",
-			expected: false,
-		},
-		{
-			name:     "Contains numbers",
-			input:    "--- 123 ---",
-			expected: false,
-		},
-		{
-			name:     "Single letter",
-			input:    "a",
-			expected: false,
-		},
+	if addon.Label != "Café & Bar" {
+		t.Errorf("Label = %q, want %q", addon.Label, "Café & Bar")
 	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := isPureNonAlphanumeric(tt.input)
-			if result != tt.expected {
-				t.Errorf("isPureNonAlphanumeric(%q) = %v, want %v", tt.input, result, tt.expected)
-			}
-		})
+	if addon.Name != "caf-bar" {
+		t.Errorf("Name = %q, want %q", addon.Name, "caf-bar")
 	}
-}
-
-func TestIsLowQualityDescription(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected bool
-	}{
-		{
-			name:     "Too short - single word",
-			input:    "null",
-			expected: true,
-		},
-		{
-			name:     "Too short - few chars",
-			input:    "1.3",
-			expected: true,
-		},
-		{
-			name:     "Version number with update",
-			input:    "10.1.5 UPDATE:",
-			expected: true,
-		},
-		{
-			name:     "Version number simple",
-			input:    "1.0 release",
-			expected: true,
-		},
-		{
-			name:     "Version with v prefix",
-			input:    "v1.2.3 fixes",
-			expected: true,
-		},
-		{
-			name:     "Date MM/DD/YYYY",
-			input:    "05/03/2025 - Updated",
-			expected: true,
-		},
-		{
-			name:     "Date YYYY-MM-DD",
-			input:    "2025-05-03 - New version",
-			expected: true,
-		},
-		{
-			name:     "Null exact match",
-			input:    "null",
-			expected: true,
-		},
-		{
-			name:     "Update prefix",
-			input:    "UPDATE: Fixed bugs",
-			expected: true,
-		},
-		{
-			name:     "No spaces (single word)",
-			input:    "SomeLongSingleWord",
-			expected: true,
-		},
-		{
-			name:     "Good quality - normal description",
-			input:    "This addon helps you manage your inventory efficiently.",
-			expected: false,
-		},
-		{
-			name:     "Good quality - minimum length with spaces",
-			input:    "Tracks your DPS",
-			expected: false,
-		},
-		{
-			name:     "Good quality - sentence",
-			input:    "A simple raid frame addon.",
-			expected: false,
-		},
-		{
-			name:     "Number in middle is ok",
-			input:    "This is version 2.0 of the addon.",
-			expected: false,
-		},
-		{
-			name:     "AddonName by AuthorName pattern",
-			input:    "BigWigs by Funkydude",
-			expected: true,
-		},
-		{
-			name:     "'by' in longer description is ok",
-			input:    "This addon was created by the author to solve problems.",
-			expected: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := isLowQualityDescription(tt.input)
-			if result != tt.expected {
-				t.Errorf("isLowQualityDescription(%q) = %v, want %v", tt.input, result, tt.expected)
-			}
-		})
-	}
-}
-
-func TestCleanDescriptionWithQualityFilter(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected string
-	}{
-		{
-			name:     "Skip version, use next line",
-			input:    "1.3\nThis addon provides raid utilities.",
-			expected: "This addon provides raid utilities.",
-		},
-		{
-			name:     "Skip multiple bad lines",
-			input:    "null\n10.1.5 UPDATE:\nManages your action bars effectively.",
-			expected: "Manages your action bars effectively.",
-		},
-		{
-			name:     "Skip date, use description",
-			input:    "05/03/2025 - V3.1\nProvides enhanced tooltips.",
-			expected: "Provides enhanced tooltips.",
-		},
-		{
-			name:     "Fallback to short line if nothing better",
-			input:    "hud",
-			expected: "hud",
-		},
-		{
-			name:     "Don't use 'null' as fallback",
-			input:    "null",
-			expected: "",
-		},
-		{
-			name:     "Don't use 'undefined' as fallback",
-			input:    "===\nundefined",
-			expected: "",
-		},
-		{
-			name:     "Use good line even if bad lines exist later",
-			input:    "Tracks gathering nodes on your map.\n1.0\nv2.3",
-			expected: "Tracks gathering nodes on your map.",
-		},
+	if addon.Description != "Don’t panic" {
+		t.Errorf("Description = %q, want %q", addon.Description, "Don’t panic")
 	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := cleanDescription(tt.input)
-			if result != tt.expected {
-				t.Errorf("cleanDescription() = %q, want %q", result, tt.expected)
-			}
-		})
+	if !addon.TagSet["bags"] || len(addon.TagSet) != 1 {
+		t.Errorf("TagSet = %v, want [bags]", addon.TagSet)
 	}
 }
 
-func TestShouldSkipLeadingLine(t *testing.T) {
+func TestInterfaceVersion(t *testing.T) {
 	tests := []struct {
-		name     string
-		input    string
-		expected bool
+		version string
+		want    int
+		wantOK  bool
 	}{
-		{
-			name:     "About prefix",
-			input:    "About this addon",
-			expected: true,
-		},
-		{
-			name:     "Description prefix",
-			input:    "Description: Tracks DPS",
-			expected: true,
-		},
-		{
-			name:     "Donate message",
-			input:    "Donate to support development",
-			expected: true,
-		},
-		{
-			name:     "Hello greeting",
-			input:    "Hello, welcome!",
-			expected: true,
-		},
-		{
-			name:     "What is it question",
-			input:    "What is it?",
-			expected: true,
-		},
-		{
-			name:     "Discontinued notice",
-			input:    "Discontinued - no updates",
-			expected: true,
-		},
-		{
-			name:     "Not a prefix (word boundary)",
-			input:    "aboutface command",
-			expected: false,
-		},
-		{
-			name:     "Normal content",
-			input:    "This addon helps you manage inventory",
-			expected: false,
-		},
-		{
-			name:     "Contains but doesn't start with skip word",
-			input:    "An overview of features",
-			expected: false,
-		},
+		{"11.0.5", 110005, true},
+		{"10.2.5", 100205, true},
+		{"1.13.2", 11302, true},
+		{"3.4", 30400, true},
+		{"", 0, false},
+		{"11", 0, false},
+		{"11.0.5.1", 0, false},
+		{"11.x", 0, false},
+		{"1.100.0", 0, false},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := shouldSkipLeadingLine(tt.input)
-			if result != tt.expected {
-				t.Errorf("shouldSkipLeadingLine(%q) = %v, want %v", tt.input, result, tt.expected)
-			}
-		})
+		got, ok := interfaceVersion(tt.version)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("interfaceVersion(%q) = %d, %v, want %d, %v", tt.version, got, ok, tt.want, tt.wantOK)
+		}
 	}
 }
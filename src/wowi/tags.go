@@ -1,5 +1,7 @@
 package wowi
 
+import "strings"
+
 // WoWInterface-specific category replacement map
 // Categories that are replaced entirely with specific tags
 var wowiReplacements = map[string][]string{
@@ -65,3 +67,38 @@ func categoryToTagsWithMaps(category string) []string {
 
 	return tagList
 }
+
+// categoryToTags converts a WowInterface category string to one or more tags
+// Following the Clojure implementation:
+// 1. Split on " & ", ", ", or ": " to handle compound categories
+// 2. For each part: lowercase, trim, and replace spaces with hyphens
+func categoryToTags(category string) []string {
+	if category == "" {
+		return nil
+	}
+
+	// Split on " & ", ", ", or ": " (matching Clojure regex: ( & |, |: )+)
+	var parts []string
+	current := category
+
+	// Replace separators with a unique delimiter, then split
+	current = strings.ReplaceAll(current, " & ", "|||")
+	current = strings.ReplaceAll(current, ", ", "|||")
+	current = strings.ReplaceAll(current, ": ", "|||")
+	parts = strings.Split(current, "|||")
+
+	// Convert each part to a tag
+	var tags []string
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		// Lowercase and replace spaces with hyphens
+		tag := strings.ToLower(part)
+		tag = strings.ReplaceAll(tag, " ", "-")
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
@@ -0,0 +1,90 @@
+package wowi
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var categoryIDRegex = regexp.MustCompile(`\d+`)
+var downloadCountRegex = regexp.MustCompile(`\d+`)
+
+// extractVersion returns the version in text like "Version: v1.3", or an empty string if there isn't one
+func extractVersion(text string) string {
+	version := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), "Version:"))
+	if strings.EqualFold(version, "N/A") {
+		return ""
+	}
+	return version
+}
+
+// absoluteURL makes a protocol-relative URL ("//cdn-wow.mmoui.com/...") absolute
+func absoluteURL(rawURL string) string {
+	if strings.HasPrefix(rawURL, "//") {
+		return "https:" + rawURL
+	}
+	return rawURL
+}
+
+func extractCategoryID(href string) string {
+	return categoryIDRegex.FindString(href)
+}
+
+func extractUpdatedDate(text string) string {
+	if strings.HasPrefix(text, "Updated ") {
+		return strings.TrimSpace(strings.TrimPrefix(text, "Updated "))
+	}
+	return ""
+}
+
+func extractDownloadCount(text string) int {
+	countStr := downloadCountRegex.FindString(text)
+	if count, err := strconv.Atoi(countStr); err == nil {
+		return count
+	}
+	return 0
+}
+
+func parseWoWIDate(dateStr string) (time.Time, error) {
+	// WowInterface uses format: "09-07-18 01:27 PM"
+	t, err := time.Parse("01-02-06 03:04 PM", dateStr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+func slugify(s string) string {
+	// Create a clean, readable slug suitable for identifying addons
+	// 1. Lowercase
+	// 2. Split on any non-alphanumeric characters (spaces, punctuation, symbols)
+	// 3. Filter out empty parts
+	// 4. Join with hyphens
+	// 5. Trim to 250 characters
+
+	// Lowercase
+	s = strings.ToLower(s)
+
+	// Split on any non-alphanumeric character (keeps only letters and numbers)
+	re := regexp.MustCompile(`[^a-z0-9]+`)
+	parts := re.Split(s, -1)
+
+	// Filter out empty parts
+	var filtered []string
+	for _, part := range parts {
+		if part != "" {
+			filtered = append(filtered, part)
+		}
+	}
+
+	// Join with hyphen
+	result := strings.Join(filtered, "-")
+
+	// Trim to 250 characters
+	if len(result) > 250 {
+		result = result[:250]
+	}
+
+	return result
+}